@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The forge reports its rate limit in response headers: GitLab sends RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset, GitHub and Gitea the same with an X- prefix. The
+// transport keeps the latest report for the session and counts the requests sent to that host
+// since, so the remaining quota shown in the MR list footer is an estimate between reports. Shared
+// instances limit requests per minute, and the detail requests of a page of MRs add up quickly.
+const apiQuotaLowFraction = 0.1 // Remaining share of the limit shown as a warning
+
+// apiQuota is the rate limit of the forge as last reported
+type apiQuota struct {
+	host      string
+	limit     int
+	remaining int       // Reported remaining minus the requests sent since
+	reset     time.Time // When the window resets; zero if not reported
+}
+
+// forgeQuota is updated by quotaTransport
+var forgeQuota struct {
+	mu    sync.Mutex
+	quota apiQuota
+	known bool
+}
+
+// quotaTransport records the rate limit headers of forge responses
+type quotaTransport struct {
+	base http.RoundTripper
+}
+
+func (t quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	forgeQuota.mu.Lock()
+	if forgeQuota.known && forgeQuota.quota.host == req.URL.Host && forgeQuota.quota.remaining > 0 {
+		forgeQuota.quota.remaining--
+	}
+	forgeQuota.mu.Unlock()
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if quota, ok := parseAPIQuota(resp.Header, resp.StatusCode, time.Now()); ok {
+		quota.host = req.URL.Host
+		forgeQuota.mu.Lock()
+		forgeQuota.quota = quota
+		forgeQuota.known = true
+		forgeQuota.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// parseAPIQuota reads the rate limit headers of a response. A 429 response with Retry-After
+// means the quota is used up until then.
+func parseAPIQuota(h http.Header, status int, now time.Time) (apiQuota, bool) {
+	header := func(name string) string {
+		if v := h.Get(name); v != "" {
+			return v
+		}
+		return h.Get("X-" + name)
+	}
+	limit, err := strconv.Atoi(header("RateLimit-Limit"))
+	if err != nil || limit <= 0 {
+		return apiQuota{}, false
+	}
+	quota := apiQuota{limit: limit, remaining: limit}
+	if remaining, err := strconv.Atoi(header("RateLimit-Remaining")); err == nil {
+		quota.remaining = max(remaining, 0)
+	}
+	if reset, err := strconv.ParseInt(header("RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		quota.reset = time.Unix(reset, 0)
+	}
+	if status == http.StatusTooManyRequests {
+		quota.remaining = 0
+		if wait, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+			quota.reset = now.Add(time.Duration(wait) * time.Second)
+		}
+	}
+	return quota, true
+}
+
+// currentAPIQuota returns the estimated quota, or false before the forge reported one and once
+// its window has reset
+func currentAPIQuota(now time.Time) (apiQuota, bool) {
+	forgeQuota.mu.Lock()
+	defer forgeQuota.mu.Unlock()
+	q := forgeQuota.quota
+	if !forgeQuota.known || (!q.reset.IsZero() && now.After(q.reset)) {
+		return apiQuota{}, false
+	}
+	return q, true
+}
+
+// low reports whether the remaining quota is below apiQuotaLowFraction of the limit
+func (q apiQuota) low() bool {
+	return float64(q.remaining) < float64(q.limit)*apiQuotaLowFraction
+}
+
+// renderAPIQuota returns the quota for a footer, e.g. "API 1834/2000", or a warning when it is
+// low; empty while unknown
+func renderAPIQuota() string {
+	now := time.Now()
+	q, ok := currentAPIQuota(now)
+	if !ok {
+		return ""
+	}
+	if !q.low() {
+		return helpStyle.Render(fmt.Sprintf("API %d/%d", q.remaining, q.limit))
+	}
+	text := fmt.Sprintf("⚠ API quota low: %d/%d", q.remaining, q.limit)
+	if !q.reset.IsZero() {
+		text += fmt.Sprintf(", resets in %ds", int(q.reset.Sub(now).Seconds())+1)
+	}
+	return lipgloss.NewStyle().Foreground(currentTheme.Warning).Bold(true).Render(text)
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// releaseStepNames maps release steps to stable identifiers used by the API
+var releaseStepNames = map[ReleaseStep]string{
+	ReleaseStepIdle:             "idle",
+	ReleaseStepGitFetch:         "git_fetch",
+	ReleaseStepCheckoutRoot:     "checkout_root",
+	ReleaseStepMergeBranches:    "merge_branches",
+	ReleaseStepCheckoutEnv:      "checkout_env",
+	ReleaseStepCopyContent:      "copy_content",
+	ReleaseStepCommit:           "commit",
+	ReleaseStepPushBranches:     "push_branches",
+	ReleaseStepWaitForMR:        "wait_for_mr",
+	ReleaseStepPushAndCreateMR:  "push_and_create_mr",
+	ReleaseStepWaitForRootPush:  "wait_for_root_push",
+	ReleaseStepPushRootBranches: "push_root_branches",
+	ReleaseStepSwitchToRoot:     "switch_to_root",
+	ReleaseStepDeployPipeline:   "deploy_pipeline",
+	ReleaseStepComplete:         "complete",
+}
+
+// apiServer exposes release operations over HTTP for dashboards and bots
+type apiServer struct {
+	token          string
+	spectatorToken string // Read-only token for following the release (see spectatorRoutes); optional
+	syncToken      string // Token of history sync clients (see syncRoutes); optional
+	webhookSecret  string // Enables /api/webhooks/* when set
+	replays        webhookReplays
+	creds          *Credentials
+	projectID      int
+
+	mu       sync.Mutex
+	run      *releaseRun // Current or last release started through the API
+	starting bool        // A plan is being resolved by startRun; no other release may start meanwhile
+}
+
+// releaseRunStatus is the API representation of a release started through the API
+type releaseRunStatus struct {
+	ID          string      `json:"id"`
+	Plan        ReleasePlan `json:"plan"`
+	Status      string      `json:"status"` // "running", "completed" or "failed"
+	Error       string      `json:"error,omitempty"`
+	Step        string      `json:"step"`
+	Completed   int         `json:"completed_sub_steps"`
+	Total       int         `json:"total_sub_steps"`
+	MRURL       string      `json:"created_mr_url,omitempty"`
+	Tag         string      `json:"tag,omitempty"`
+	RequestedBy string      `json:"requested_by,omitempty"` // Webhook user who triggered the release
+	StartedAt   time.Time   `json:"started_at"`
+	FinishedAt  *time.Time  `json:"finished_at,omitempty"`
+}
+
+// releaseRun tracks a release started through the API
+type releaseRun struct {
+	mu          sync.Mutex
+	status      releaseRunStatus
+	output      []string                       // Plain-text output lines for replay
+	subscribers map[chan releaseEvent]struct{} // nil once the release has finished
+	notify      func(releaseRunStatus)         // Milestone callback for webhook-triggered releases (optional)
+}
+
+// releaseEvent is a server-sent event emitted during a release
+type releaseEvent struct {
+	Type string      // "output", "progress" or "done"
+	Data interface{} // JSON-encoded as the event data
+}
+
+// spectatorRoutes are the routes the spectator token may use: the status and the live output of
+// the release, so a teammate can follow a risky release without being able to start one, and the
+// calendar feed, so team calendars subscribe without the token that starts releases
+var spectatorRoutes = map[string]bool{
+	"/api/release":        true,
+	"/api/release/events": true,
+	"/api/calendar.ics":   true,
+}
+
+// syncRoutes are the routes the sync token may use: pushing releases to the history and reading
+// the team activity feed (see history_sync.go), so every teammate's config holds a token that
+// cannot start releases
+var syncRoutes = map[string]bool{
+	"POST /api/history": true,
+	"GET /api/activity": true,
+}
+
+// newAPIServer creates the API server for the given credentials and project
+func newAPIServer(token, spectatorToken, syncToken, webhookSecret string, creds *Credentials, projectID int) *apiServer {
+	return &apiServer{token: token, spectatorToken: spectatorToken, syncToken: syncToken, webhookSecret: webhookSecret, creds: creds, projectID: projectID}
+}
+
+// handler returns the HTTP handler with all routes behind token authentication.
+// Webhook routes verify their own signatures instead and are only served when a webhook secret is set.
+func (s *apiServer) handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /api/mrs", s.handleListMRs)
+	api.HandleFunc("GET /api/release", s.handleGetRelease)
+	api.HandleFunc("POST /api/release", s.handleStartRelease)
+	api.HandleFunc("GET /api/release/events", s.handleReleaseEvents)
+	api.HandleFunc("GET /api/history", s.handleListHistory)
+	api.HandleFunc("POST /api/history", s.handleImportHistory)
+	api.HandleFunc("GET /api/activity", s.handleListActivity)
+	api.HandleFunc("GET /api/history/{ref}", s.handleGetHistory)
+	api.HandleFunc("GET /api/history/{ref}/logs", s.handleGetHistoryLogs)
+	api.HandleFunc("GET /metrics", s.handleMetrics)
+	api.HandleFunc("GET /api/calendar.ics", s.handleCalendar)
+
+	if s.webhookSecret == "" {
+		return s.authenticate(api)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", s.authenticate(api))
+	mux.HandleFunc("POST /api/webhooks/slack", s.handleSlackWebhook)
+	mux.HandleFunc("POST /api/webhooks/gitlab", s.handleGitLabWebhook)
+	mux.HandleFunc("POST /api/webhooks/release", s.handleReleaseWebhook)
+	return mux
+}
+
+// authenticate rejects requests without the server token. The spectator token is accepted for
+// reading spectatorRoutes only, the sync token for syncRoutes only.
+// The token is read from "Authorization: Bearer <token>" or, for EventSource clients
+// that cannot set headers, from the access_token query parameter.
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("access_token")
+		}
+		if s.spectatorToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.spectatorToken)) == 1 {
+			if r.Method != http.MethodGet || !spectatorRoutes[r.URL.Path] {
+				writeAPIError(w, http.StatusForbidden, "the spectator token is read-only")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.syncToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.syncToken)) == 1 {
+			if !syncRoutes[r.Method+" "+r.URL.Path] {
+				writeAPIError(w, http.StatusForbidden, "the sync token only syncs the history")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleListMRs returns open MRs of the project
+func (s *apiServer) handleListMRs(w http.ResponseWriter, r *http.Request) {
+	client := NewForge(*s.creds)
+	mrs, err := client.GetProjectMergeRequests(s.projectID)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	filter, err := loadMRFilter(s.projectID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	mrs = filter.apply(mrs)
+	client.LoadMergeRequestDetails(mrs)
+	writeAPIJSON(w, http.StatusOK, mrs)
+}
+
+// handleGetRelease returns the status of the current or last API release
+func (s *apiServer) handleGetRelease(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	run := s.run
+	s.mu.Unlock()
+	if run == nil {
+		writeAPIError(w, http.StatusNotFound, "no release has been started")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, run.snapshot())
+}
+
+// handleStartRelease validates a release plan and starts it in the background
+func (s *apiServer) handleStartRelease(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ReleasePlan
+		ForceLock bool `json:"force_lock"` // Override the release lock of the environment
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid plan: "+err.Error())
+		return
+	}
+
+	run, status, err := s.startRun(req.ReleasePlan, req.ForceLock, "", nil)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusAccepted, run.snapshot())
+}
+
+// startRun validates a plan and starts the release in the background.
+// notify, if set, is called when the release starts, when the MR is created and when it finishes.
+// The release lock of the environment is taken, or overridden with forceLock.
+// On failure the returned HTTP status tells why the plan was not started.
+func (s *apiServer) startRun(plan ReleasePlan, forceLock bool, requestedBy string, notify func(releaseRunStatus)) (*releaseRun, int, error) {
+	// Only one release at a time: the working copy and release state file are shared. The plan is
+	// resolved outside of the lock, as it calls the forge; starting keeps other releases out.
+	s.mu.Lock()
+	if s.starting || s.run != nil && s.run.snapshot().Status == "running" {
+		s.mu.Unlock()
+		return nil, http.StatusConflict, errors.New("a release is already running")
+	}
+	s.starting = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.starting = false
+		s.mu.Unlock()
+	}()
+
+	if existing, err := LoadReleaseState(firstTabID); err == nil && existing != nil {
+		return nil, http.StatusConflict, errors.New("an unfinished release exists; retry or abort it in the TUI first")
+	}
+
+	workDir, err := prepareReleaseWorkDir(nil, plan.Environment)
+	if err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	client := NewForge(*s.creds)
+	state, err := resolveReleasePlan(plan, client, s.projectID, workDir)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+
+	if releaseLocksEnabled() {
+		held, err := acquireReleaseLock(state, releaseLockHolder(s.creds)+" (relix serve)", forceLock)
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("release lock: %w", err)
+		}
+		if held != nil {
+			return nil, http.StatusConflict, errors.New(held.describe(state.Environment.Name))
+		}
+	}
+
+	run := &releaseRun{
+		status: releaseRunStatus{
+			ID:          generateReleaseID(),
+			Plan:        plan,
+			Status:      "running",
+			Step:        releaseStepNames[state.CurrentStep],
+			RequestedBy: requestedBy,
+			StartedAt:   time.Now(),
+		},
+		subscribers: make(map[chan releaseEvent]struct{}),
+		notify:      notify,
+	}
+	s.mu.Lock()
+	s.run = run
+	s.mu.Unlock()
+
+	if notify != nil {
+		notify(run.status)
+	}
+	go run.execute(s.creds, state)
+
+	return run, 0, nil
+}
+
+// handleReleaseEvents streams output and progress of the current release as server-sent events.
+// Output produced before the client connected is replayed first.
+func (s *apiServer) handleReleaseEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	run := s.run
+	s.mu.Unlock()
+	if run == nil {
+		writeAPIError(w, http.StatusNotFound, "no release has been started")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	backlog, events := run.subscribe()
+	defer run.unsubscribe(events)
+
+	for _, ev := range backlog {
+		writeSSE(w, ev)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSE(w, ev)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleListHistory returns the release history index, filtered by query parameters
+func (s *apiServer) handleListHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	env, status, failure, since, until := q.Get("env"), q.Get("status"), q.Get("failure"), q.Get("since"), q.Get("until")
+	entries, err := loadFilteredHistory(&historyFilter{env: &env, status: &status, failure: &failure, since: &since, until: &until})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit, err := strconv.Atoi(q.Get("limit")); err == nil && limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	writeAPIJSON(w, http.StatusOK, entries)
+}
+
+// handleImportHistory saves a release pushed by a teammate's relix to the history (see history_sync.go)
+func (s *apiServer) handleImportHistory(w http.ResponseWriter, r *http.Request) {
+	var entry ReleaseHistoryEntry
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&entry); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid release: "+err.Error())
+		return
+	}
+	if entry.ReleasedBy == "" || entry.DateTime.IsZero() {
+		writeAPIError(w, http.StatusBadRequest, "the release needs released_by and datetime")
+		return
+	}
+	id, err := ImportHistoryEntry(&entry)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+// handleListActivity returns the latest releases of the history for the team activity feed,
+// newest first (default 20, see the limit query parameter)
+func (s *apiServer) handleListActivity(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+		limit = min(n, 100)
+	}
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	activity := []teamActivity{}
+	for _, e := range index[:min(limit, len(index))] {
+		item := teamActivity{ID: e.ID, ReleasedBy: e.ReleasedBy, Environment: e.Environment, Tag: e.Tag, Status: e.Status, DateTime: e.DateTime}
+		if detail, err := LoadHistoryDetail(e.ID); err == nil {
+			item.Project, item.Annotation = detail.Project, detail.Annotation
+		}
+		activity = append(activity, item)
+	}
+	writeAPIJSON(w, http.StatusOK, activity)
+}
+
+// handleGetHistory returns a single history entry by ID or tag
+func (s *apiServer) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	entry, err := findHistoryEntry(r.PathValue("ref"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, entry)
+}
+
+// handleGetHistoryLogs streams the terminal output of a history entry as plain text
+func (s *apiServer) handleGetHistoryLogs(w http.ResponseWriter, r *http.Request) {
+	entry, err := findHistoryEntry(r.PathValue("ref"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	forEachHistoryLogLine(entry, func(line string) {
+		fmt.Fprintln(w, ansi.Strip(line))
+	})
+}
+
+// execute runs the release headlessly and publishes its output and progress
+func (run *releaseRun) execute(creds *Credentials, state *ReleaseState) {
+	err := runHeadlessRelease(creds, state,
+		func(line string) {
+			line = ansi.Strip(line)
+			run.mu.Lock()
+			defer run.mu.Unlock()
+			run.output = append(run.output, line)
+			if len(run.output) > maxOutputLines {
+				run.output = run.output[len(run.output)-maxOutputLines:]
+			}
+			run.publishLocked(releaseEvent{Type: "output", Data: map[string]string{"line": line}})
+		},
+		func(st ReleaseState) {
+			run.mu.Lock()
+			run.status.Step = releaseStepNames[st.CurrentStep]
+			run.status.Completed = st.CompletedSubSteps
+			run.status.Total = st.TotalSubSteps
+			mrCreated := run.status.MRURL == "" && st.CreatedMRURL != ""
+			run.status.MRURL = st.CreatedMRURL
+			run.status.Tag = st.TagName
+			run.publishLocked(releaseEvent{Type: "progress", Data: run.status})
+			status := run.status
+			run.mu.Unlock()
+			// Outside the lock: a callback may read the run, and a slow one must not stall its output
+			if mrCreated && run.notify != nil {
+				run.notify(status)
+			}
+		},
+	)
+
+	run.mu.Lock()
+	now := time.Now()
+	run.status.FinishedAt = &now
+	if err != nil {
+		run.status.Status = "failed"
+		run.status.Error = err.Error()
+	} else {
+		run.status.Status = "completed"
+	}
+	run.publishLocked(releaseEvent{Type: "done", Data: run.status})
+
+	// Close subscriber channels so event streams end
+	for ch := range run.subscribers {
+		close(ch)
+	}
+	run.subscribers = nil
+	status := run.status
+	run.mu.Unlock()
+
+	if run.notify != nil {
+		run.notify(status)
+	}
+}
+
+// snapshot returns a copy of the run status
+func (run *releaseRun) snapshot() releaseRunStatus {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.status
+}
+
+// subscribe returns the events to replay and a channel for new events.
+// The channel is closed when the release finishes.
+func (run *releaseRun) subscribe() ([]releaseEvent, chan releaseEvent) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	var backlog []releaseEvent
+	for _, line := range run.output {
+		backlog = append(backlog, releaseEvent{Type: "output", Data: map[string]string{"line": line}})
+	}
+	backlog = append(backlog, releaseEvent{Type: "progress", Data: run.status})
+
+	ch := make(chan releaseEvent, 256)
+	if run.subscribers == nil {
+		// Release already finished: replay final status and end the stream
+		backlog = append(backlog, releaseEvent{Type: "done", Data: run.status})
+		close(ch)
+		return backlog, ch
+	}
+	run.subscribers[ch] = struct{}{}
+	return backlog, ch
+}
+
+// unsubscribe removes a subscriber channel
+func (run *releaseRun) unsubscribe(ch chan releaseEvent) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	delete(run.subscribers, ch)
+}
+
+// publishLocked sends an event to all subscribers, dropping it for clients that fall behind.
+// Caller must hold run.mu.
+func (run *releaseRun) publishLocked(ev releaseEvent) {
+	for ch := range run.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// writeSSE writes a single server-sent event
+func writeSSE(w http.ResponseWriter, ev releaseEvent) {
+	data, _ := json.Marshal(ev.Data)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+}
+
+// writeAPIJSON writes v as a JSON response
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes a JSON error response
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeAPIJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// apiRequest sends a request with the token to the handler of an API server and returns the response
+func apiRequest(t *testing.T, s *apiServer, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleListHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, state := range []*ReleaseState{
+		{Environment: Environment{Name: "test"}, Version: "1.0.0"},
+		{Environment: Environment{Name: "prod"}, Version: "1.0.0"},
+	} {
+		if _, err := SaveReleaseHistory(0, "acme/shop", state, "completed", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := newAPIServer("secret", "", "", "", &Credentials{}, 1)
+	rec := apiRequest(t, s, "GET", "/api/history?env=PROD&failure=")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var entries []HistoryIndexEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Environment != "prod" {
+		t.Errorf("entries %+v, want the prod release", entries)
+	}
+
+	if rec := apiRequest(t, s, "GET", "/api/history?since=yesterday"); rec.Code != http.StatusBadRequest {
+		t.Errorf("an invalid date: status %d, want 400", rec.Code)
+	}
+}
+
+func TestReleaseRunNotify(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", "")
+	directory := projectDirectory
+	t.Cleanup(func() { projectDirectory = directory })
+	stop, err := startDemo("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	creds, project, err := loadCLISession(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newAPIServer("secret", "", "", "", creds, project)
+
+	// The callback reads the run back, as a broadcaster of its status would
+	done := make(chan releaseRunStatus, 1)
+	notify := func(status releaseRunStatus) {
+		s.mu.Lock()
+		run := s.run
+		s.mu.Unlock()
+		if current := run.snapshot(); current.Status != "running" {
+			done <- current
+		}
+	}
+	plan := ReleasePlan{Environment: "develop", Version: "1.0.0", MRIIDs: []int{12}}
+	if _, code, err := s.startRun(plan, false, "test", notify); err != nil {
+		t.Fatalf("start: status %d: %v", code, err)
+	}
+
+	select {
+	case status := <-done:
+		if status.Status != "completed" {
+			t.Errorf("the release %s: %s", status.Status, status.Error)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("the release did not finish; the callback may be blocked on the run")
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookMaxSkew is how far a signed webhook timestamp may be from now, to reject replays
+const webhookMaxSkew = 5 * time.Minute
+
+// webhookCommand is the comment prefix that triggers a release from a GitLab MR
+const webhookCommand = "/relix"
+
+// webhookReplays remembers the webhook deliveries accepted within webhookMaxSkew, so a captured
+// request sent again while its timestamp is still accepted is rejected
+type webhookReplays struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // Delivery key → when its timestamp expires
+}
+
+// check records a delivery sent at the given time, failing if it was accepted before
+func (r *webhookReplays) check(key string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for k, expires := range r.seen {
+		if now.After(expires) {
+			delete(r.seen, k)
+		}
+	}
+	if _, ok := r.seen[key]; ok {
+		return errors.New("request already delivered")
+	}
+	if r.seen == nil {
+		r.seen = make(map[string]time.Time)
+	}
+	r.seen[key] = at.Add(webhookMaxSkew)
+	return nil
+}
+
+// webhookReporter posts release progress back to where a webhook came from
+type webhookReporter interface {
+	Report(text string, status *releaseRunStatus) error
+}
+
+// slackReporter posts to the response_url of a Slack slash command
+type slackReporter struct {
+	responseURL string
+}
+
+func (r slackReporter) Report(text string, status *releaseRunStatus) error {
+	return postWebhookJSON(r.responseURL, map[string]string{"response_type": "in_channel", "text": text})
+}
+
+// gitlabNoteReporter comments on the MR where the release was requested
+type gitlabNoteReporter struct {
+	client    Forge
+	projectID int
+	mrIID     int
+}
+
+func (r gitlabNoteReporter) Report(text string, status *releaseRunStatus) error {
+	_, err := r.client.CreateMergeRequestNote(r.projectID, r.mrIID, text)
+	recordAudit(auditMRNote, fmt.Sprintf("project %d !%d", r.projectID, r.mrIID), text, err)
+	return err
+}
+
+// callbackReporter posts the message and release status as JSON to a callback URL
+type callbackReporter struct {
+	callbackURL string
+}
+
+func (r callbackReporter) Report(text string, status *releaseRunStatus) error {
+	return postWebhookJSON(r.callbackURL, map[string]interface{}{"text": text, "release": status})
+}
+
+// handleSlackWebhook starts a release plan from a Slack slash command ("/relix <plan> [version]").
+// Requests are verified with Slack's signing secret scheme.
+func (s *apiServer) handleSlackWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := readWebhookBody(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ts := r.Header.Get("X-Slack-Request-Timestamp")
+	if err := s.verifyWebhook(ts, "v0:"+ts+":", body, strings.TrimPrefix(r.Header.Get("X-Slack-Signature"), "v0=")); err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid form body")
+		return
+	}
+
+	// Slack shows the immediate response only to the caller; progress goes to the channel
+	name, version := parseWebhookCommand(form.Get("text"))
+	if err := checkWebhookUser("@"+form.Get("user_name"), form.Get("user_id"), form.Get("user_name")); err != nil {
+		writeAPIJSON(w, http.StatusOK, map[string]string{"response_type": "ephemeral", "text": err.Error()})
+		return
+	}
+	reporter := slackReporter{responseURL: form.Get("response_url")}
+	if err := s.triggerWebhookPlan(name, version, "@"+form.Get("user_name"), reporter); err != nil {
+		writeAPIJSON(w, http.StatusOK, map[string]string{"response_type": "ephemeral", "text": err.Error()})
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]string{
+		"response_type": "ephemeral",
+		"text":          fmt.Sprintf("Starting release plan `%s`…", name),
+	})
+}
+
+// handleGitLabWebhook starts a release plan from an MR comment ("/relix <plan> [version]").
+// GitLab sends the secret token configured on the webhook as X-Gitlab-Token. The token is the same
+// for every delivery, so replays are told apart by the comment: each comment starts at most one
+// release, and only while it is recent.
+func (s *apiServer) handleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(s.webhookSecret)) != 1 {
+		writeAPIError(w, http.StatusUnauthorized, "invalid or missing token")
+		return
+	}
+
+	var event struct {
+		ObjectKind string `json:"object_kind"`
+		ProjectID  int    `json:"project_id"`
+		User       struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		ObjectAttributes struct {
+			ID           int    `json:"id"`
+			Note         string `json:"note"`
+			NoteableType string `json:"noteable_type"`
+			CreatedAt    string `json:"created_at"`
+		} `json:"object_attributes"`
+		MergeRequest struct {
+			IID int `json:"iid"`
+		} `json:"merge_request"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&event); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid event: "+err.Error())
+		return
+	}
+
+	// GitLab delivers every comment; only MR comments of this project that start with the command count
+	note := strings.TrimSpace(event.ObjectAttributes.Note)
+	if event.ObjectKind != "note" || event.ObjectAttributes.NoteableType != "MergeRequest" ||
+		event.ProjectID != s.projectID || !strings.HasPrefix(note, webhookCommand+" ") {
+		writeAPIJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+		return
+	}
+
+	created, err := parseGitLabTime(event.ObjectAttributes.CreatedAt)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid comment time: "+err.Error())
+		return
+	}
+	if age := time.Since(created); age > webhookMaxSkew || age < -webhookMaxSkew {
+		writeAPIError(w, http.StatusUnauthorized, "comment too old")
+		return
+	}
+	if err := s.replays.check(fmt.Sprintf("gitlab-note:%d", event.ObjectAttributes.ID), created); err != nil {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	name, version := parseWebhookCommand(strings.TrimPrefix(note, webhookCommand))
+	reporter := gitlabNoteReporter{
+		client:    NewForge(*s.creds),
+		projectID: s.projectID,
+		mrIID:     event.MergeRequest.IID,
+	}
+	if err := checkWebhookUser("@"+event.User.Username, event.User.Username); err != nil {
+		reporter.Report(err.Error(), nil)
+		writeAPIError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err := s.triggerWebhookPlan(name, version, "@"+event.User.Username, reporter); err != nil {
+		reporter.Report(err.Error(), nil)
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// handleReleaseWebhook starts a release plan from a generic signed JSON payload:
+// {"plan": "<name>", "version": "...", "requested_by": "...", "callback_url": "..."}.
+// The signature is "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>" in X-Relix-Signature,
+// with the Unix timestamp in X-Relix-Timestamp.
+func (s *apiServer) handleReleaseWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := readWebhookBody(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ts := r.Header.Get("X-Relix-Timestamp")
+	if err := s.verifyWebhook(ts, ts+".", body, strings.TrimPrefix(r.Header.Get("X-Relix-Signature"), "sha256=")); err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var payload struct {
+		Plan        string `json:"plan"`
+		Version     string `json:"version"`
+		RequestedBy string `json:"requested_by"`
+		CallbackURL string `json:"callback_url"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	var reporter webhookReporter
+	if payload.CallbackURL != "" {
+		reporter = callbackReporter{callbackURL: payload.CallbackURL}
+	}
+	if err := s.triggerWebhookPlan(payload.Plan, payload.Version, payload.RequestedBy, reporter); err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// triggerWebhookPlan looks up a pre-approved plan and starts it in the background.
+// Starting can take a while (every MR is checked in GitLab), longer than chat platforms
+// wait for a response, so only the plan lookup errors are returned; the rest is reported.
+func (s *apiServer) triggerWebhookPlan(name, version, requestedBy string, reporter webhookReporter) error {
+	plan, err := webhookPlan(name, version)
+	if err != nil {
+		return err
+	}
+
+	notifier := newWebhookNotifier(name, reporter)
+	go func() {
+		if _, _, err := s.startRun(plan, false, requestedBy, notifier.update); err != nil {
+			notifier.post(fmt.Sprintf("Release plan `%s` could not start: %v", name, err), nil)
+			notifier.close()
+		}
+	}()
+	return nil
+}
+
+// webhookPlan returns the pre-approved plan with the given name from the config.
+// A plan without a version takes it from the webhook; a plan with one cannot be overridden.
+func webhookPlan(name, version string) (ReleasePlan, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return ReleasePlan{}, fmt.Errorf("load config: %w", err)
+	}
+
+	plan, ok := config.WebhookPlans[name]
+	if !ok {
+		names := make([]string, 0, len(config.WebhookPlans))
+		for n := range config.WebhookPlans {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return ReleasePlan{}, errors.New("no release plans are approved for webhooks")
+		}
+		return ReleasePlan{}, fmt.Errorf("unknown release plan %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	switch {
+	case plan.Version == "" && version == "":
+		return ReleasePlan{}, fmt.Errorf("release plan %q needs a version, e.g. \"%s %s 1.2.3\"", name, webhookCommand, name)
+	case plan.Version != "" && version != "" && version != plan.Version:
+		return ReleasePlan{}, fmt.Errorf("release plan %q is approved for version %s only", name, plan.Version)
+	case plan.Version == "":
+		plan.Version = version
+	}
+	return plan, nil
+}
+
+// checkWebhookUser fails unless one of the names of the user who sent a command or comment is in
+// webhook_users. who names the user in the error.
+func checkWebhookUser(who string, names ...string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	for _, allowed := range config.WebhookUsers {
+		allowed = strings.TrimPrefix(allowed, "@")
+		for _, name := range names {
+			if name != "" && strings.EqualFold(name, allowed) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is not allowed to start release plans (see webhook_users in the config)", who)
+}
+
+// parseGitLabTime parses a timestamp of a GitLab webhook, which older GitLab versions send as
+// "2006-01-02 15:04:05 UTC"
+func parseGitLabTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02 15:04:05 MST", s)
+}
+
+// parseWebhookCommand splits command text "<plan> [version]"
+func parseWebhookCommand(text string) (name, version string) {
+	fields := strings.Fields(text)
+	if len(fields) > 0 {
+		name = fields[0]
+	}
+	if len(fields) > 1 {
+		version = fields[1]
+	}
+	return name, version
+}
+
+// readWebhookBody reads the raw request body, which signatures are computed over
+func readWebhookBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	return body, nil
+}
+
+// verifyWebhook checks the signature of a webhook request and that it was not delivered before
+func (s *apiServer) verifyWebhook(ts, prefix string, body []byte, signature string) error {
+	at, err := verifyWebhookSignature(s.webhookSecret, ts, prefix, body, signature)
+	if err != nil {
+		return err
+	}
+	// The signature covers the timestamp and the body, so it identifies the delivery
+	return s.replays.check(signature, at)
+}
+
+// verifyWebhookSignature checks a hex HMAC-SHA256 signature of prefix+body
+// and that the Unix timestamp ts is recent, returning its time
+func verifyWebhookSignature(secret, ts, prefix string, body []byte, signature string) (time.Time, error) {
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("missing or invalid timestamp")
+	}
+	at := time.Unix(sec, 0)
+	if skew := time.Since(at); skew > webhookMaxSkew || skew < -webhookMaxSkew {
+		return time.Time{}, errors.New("timestamp too old")
+	}
+
+	expected := signWebhookPayload(secret, prefix, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return time.Time{}, errors.New("invalid signature")
+	}
+	return at, nil
+}
+
+// signWebhookPayload returns the hex HMAC-SHA256 of prefix followed by body
+func signWebhookPayload(secret, prefix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(prefix))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhookJSON posts v as JSON to a webhook response URL
+func postWebhookJSON(target string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(target, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook response error: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// webhookNotifier delivers progress messages of one webhook release in order, without blocking the release
+type webhookNotifier struct {
+	plan     string
+	reporter webhookReporter
+	queue    chan webhookMessage
+}
+
+// webhookMessage is a queued progress message
+type webhookMessage struct {
+	text   string
+	status *releaseRunStatus
+}
+
+// newWebhookNotifier starts delivering messages to reporter; a nil reporter discards them
+func newWebhookNotifier(plan string, reporter webhookReporter) *webhookNotifier {
+	n := &webhookNotifier{plan: plan, reporter: reporter, queue: make(chan webhookMessage, 8)}
+	go func() {
+		for msg := range n.queue {
+			if n.reporter != nil {
+				// Delivery failures must not affect the release; there is nowhere else to report them
+				n.reporter.Report(msg.text, msg.status)
+			}
+		}
+	}()
+	return n
+}
+
+// post queues a message, dropping it if delivery has fallen far behind
+func (n *webhookNotifier) post(text string, status *releaseRunStatus) {
+	select {
+	case n.queue <- webhookMessage{text: text, status: status}:
+	default:
+	}
+}
+
+// close stops delivery after the queued messages
+func (n *webhookNotifier) close() {
+	close(n.queue)
+}
+
+// update reports release milestones: started, MR created, completed or failed
+func (n *webhookNotifier) update(status releaseRunStatus) {
+	switch {
+	case status.Status == "running" && status.MRURL == "":
+		who := ""
+		if status.RequestedBy != "" {
+			who = " by " + status.RequestedBy
+		}
+		n.post(fmt.Sprintf("Release plan `%s` started%s: %s %s with %d MR(s)",
+			n.plan, who, strings.ToUpper(status.Plan.Environment), status.Plan.Version, len(status.Plan.MRIIDs)), &status)
+	case status.Status == "completed":
+		text := fmt.Sprintf("Release plan `%s` completed", n.plan)
+		if status.Tag != "" {
+			text += ", tagged " + status.Tag
+		}
+		n.post(text, &status)
+		n.close()
+	case status.Status == "failed":
+		n.post(fmt.Sprintf("Release plan `%s` failed at step %s: %s. Retry or abort it in the TUI.",
+			n.plan, status.Step, status.Error), &status)
+		n.close()
+	default:
+		n.post(fmt.Sprintf("Release plan `%s`: MR created %s", n.plan, status.MRURL), &status)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// webhookRequest sends a webhook request to the handler of an API server and returns the response
+func webhookRequest(s *apiServer, target, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReleaseWebhookReplay(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := newAPIServer("secret", "", "", "hook-secret", &Credentials{}, 1)
+	body := `{"plan": "hotfix", "version": "1.2.4"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header := map[string]string{
+		"X-Relix-Timestamp": ts,
+		"X-Relix-Signature": "sha256=" + signWebhookPayload("hook-secret", ts+".", []byte(body)),
+	}
+
+	// No plans are approved, so the first delivery is refused after it is verified
+	if rec := webhookRequest(s, "/api/webhooks/release", body, header); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("first delivery: status %d: %s", rec.Code, rec.Body)
+	}
+	rec := webhookRequest(s, "/api/webhooks/release", body, header)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "request already delivered") {
+		t.Errorf("replayed delivery: status %d: %s", rec.Code, rec.Body)
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-2*webhookMaxSkew).Unix(), 10)
+	header = map[string]string{
+		"X-Relix-Timestamp": old,
+		"X-Relix-Signature": "sha256=" + signWebhookPayload("hook-secret", old+".", []byte(body)),
+	}
+	if rec := webhookRequest(s, "/api/webhooks/release", body, header); rec.Code != http.StatusUnauthorized {
+		t.Errorf("old delivery: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestWebhookReplaysExpire(t *testing.T) {
+	var r webhookReplays
+	if err := r.check("a", time.Now().Add(-2*webhookMaxSkew)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.check("b", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.check("a", time.Now()); err != nil {
+		t.Errorf("an expired delivery is still remembered: %v", err)
+	}
+	if err := r.check("b", time.Now()); err == nil {
+		t.Error("a recent delivery is accepted twice")
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	config, _ := LoadConfig()
+	config.WebhookUsers = []string{"@maria"}
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	s := newAPIServer("secret", "", "", "hook-secret", &Credentials{}, 1)
+	comment := func(id int, user string, created time.Time) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"object_kind": "note", "project_id": 1, "user": {"username": %q},
+			"object_attributes": {"id": %d, "note": "/relix hotfix 1.2.4", "noteable_type": "MergeRequest", "created_at": %q},
+			"merge_request": {"iid": 7}}`, user, id, created.UTC().Format("2006-01-02 15:04:05 UTC"))
+		return webhookRequest(s, "/api/webhooks/gitlab", body, map[string]string{"X-Gitlab-Token": "hook-secret"})
+	}
+
+	if rec := comment(1, "eve", time.Now()); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "@eve is not allowed") {
+		t.Errorf("a comment by another user: status %d: %s", rec.Code, rec.Body)
+	}
+	// No plans are approved, so Maria's comment is refused after her name is checked
+	if rec := comment(2, "Maria", time.Now()); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("a comment by an allowed user: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := comment(2, "Maria", time.Now()); rec.Code != http.StatusConflict {
+		t.Errorf("a replayed comment: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := comment(3, "Maria", time.Now().Add(-time.Hour)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("an old comment: status %d: %s", rec.Code, rec.Body)
+	}
+}
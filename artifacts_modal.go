@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// artifactJobsMsg carries the jobs of the release pipeline that have artifacts
+type artifactJobsMsg struct {
+	jobs []PipelineJob
+	err  error
+}
+
+// artifactDownloadMsg reports a finished artifacts download
+type artifactDownloadMsg struct {
+	job  PipelineJob
+	path string
+	err  error
+}
+
+// canDownloadArtifacts reports whether the release has a pipeline whose artifacts can be listed
+func (m model) canDownloadArtifacts() bool {
+	return m.releaseState != nil && m.creds != nil && m.creds.Forge == forgeGitLab &&
+		m.pipelineStatus != nil && m.pipelineStatus.PipelineID != 0
+}
+
+// openArtifactsModal shows the artifacts modal and loads the jobs of the release pipeline
+func (m model) openArtifactsModal() (tea.Model, tea.Cmd) {
+	if !m.canDownloadArtifacts() {
+		return m, nil
+	}
+	m.showArtifactsModal = true
+	m.artifactsLoading = true
+	m.artifactsErr = nil
+	m.artifactJobs = nil
+	m.artifactsIndex = 0
+
+	creds := *m.creds
+	projectID := m.releaseState.ProjectID
+	pipelineID := m.pipelineStatus.PipelineID
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		jobs, err := NewGitLabClient(creds.GitLabURL, creds.Token).GetPipelineJobs(projectID, pipelineID)
+		if err != nil {
+			return artifactJobsMsg{err: err}
+		}
+		var withArtifacts []PipelineJob
+		for _, job := range jobs {
+			if job.ArtifactsFile != nil {
+				withArtifacts = append(withArtifacts, job)
+			}
+		}
+		return artifactJobsMsg{jobs: withArtifacts}
+	})
+}
+
+// closeArtifactsModal closes the artifacts modal and clears its state
+func (m *model) closeArtifactsModal() {
+	m.showArtifactsModal = false
+	m.artifactsLoading = false
+	m.artifactsErr = nil
+	m.artifactJobs = nil
+	m.artifactsIndex = 0
+}
+
+// handleArtifactJobs fills the artifacts modal with the loaded jobs
+func (m *model) handleArtifactJobs(msg artifactJobsMsg) {
+	if !m.showArtifactsModal {
+		return
+	}
+	m.artifactsLoading = false
+	m.artifactsErr = msg.err
+	m.artifactJobs = msg.jobs
+}
+
+// updateArtifactsModal handles key events for the artifacts modal
+func (m model) updateArtifactsModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+q", "q", "esc":
+		m.closeArtifactsModal()
+		return m, nil
+	case "down", "j":
+		if m.artifactsIndex < len(m.artifactJobs)-1 {
+			m.artifactsIndex++
+		}
+		return m, nil
+	case "up", "k":
+		if m.artifactsIndex > 0 {
+			m.artifactsIndex--
+		}
+		return m, nil
+	case "enter":
+		return m.downloadSelectedArtifacts()
+	}
+	return m, nil
+}
+
+// downloadSelectedArtifacts starts downloading the artifacts of the selected job
+func (m model) downloadSelectedArtifacts() (tea.Model, tea.Cmd) {
+	if m.artifactsIndex < 0 || m.artifactsIndex >= len(m.artifactJobs) || m.releaseState == nil || m.creds == nil {
+		return m, nil
+	}
+	job := m.artifactJobs[m.artifactsIndex]
+	m.closeArtifactsModal()
+	m.appendReleaseOutput(fmt.Sprintf("Downloading artifacts of %s...", releaseOrangeStyle.Render(job.Name)))
+
+	creds := *m.creds
+	projectID := m.releaseState.ProjectID
+	return m, func() tea.Msg {
+		path, err := downloadJobArtifacts(creds, projectID, job)
+		return artifactDownloadMsg{job: job, path: path, err: err}
+	}
+}
+
+// handleArtifactDownload reports a finished download in the release output
+func (m *model) handleArtifactDownload(msg artifactDownloadMsg) {
+	if m.releaseState == nil {
+		return
+	}
+	if msg.err != nil {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render(
+			fmt.Sprintf("WARNING: artifacts of %s: %v", msg.job.Name, msg.err)))
+		return
+	}
+	m.appendReleaseOutput("Artifacts saved to " + msg.path)
+}
+
+// artifactsDir returns the configured download directory, ~/Downloads by default
+func artifactsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if config, err := LoadConfig(); err == nil && config.ArtifactsDir != "" {
+		return expandHome(config.ArtifactsDir)
+	}
+	return filepath.Join(home, "Downloads"), nil
+}
+
+// downloadJobArtifacts saves the artifacts archive of a job as {job}-{id}-artifacts.zip in the
+// download directory. The archive is written to a temporary file first, so an interrupted
+// download leaves no broken zip behind.
+func downloadJobArtifacts(creds Credentials, projectID int, job PipelineJob) (string, error) {
+	dir, err := artifactsDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := strings.NewReplacer("/", "-", "\\", "-", " ", "-", ":", "-").Replace(job.Name)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d-artifacts.zip", name, job.ID))
+
+	tmp, err := os.CreateTemp(dir, ".relix-artifacts-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = NewGitLabClient(creds.GitLabURL, creds.Token).DownloadJobArtifacts(projectID, job.ID, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// overlayArtifactsModal renders the artifacts modal
+func (m model) overlayArtifactsModal(background string) string {
+	var sb strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render("Download Artifacts")
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.artifactsLoading:
+		sb.WriteString(m.spinner.View() + " Loading jobs...")
+		sb.WriteString("\n")
+	case m.artifactsErr != nil:
+		sb.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Error).Render(m.artifactsErr.Error()))
+		sb.WriteString("\n")
+	case len(m.artifactJobs) == 0:
+		sb.WriteString(helpStyle.Render("No jobs with artifacts in this pipeline"))
+		sb.WriteString("\n")
+	default:
+		for i, job := range m.artifactJobs {
+			label := fmt.Sprintf("%s (%s, %s)", job.Name, job.Stage, humanize.Bytes(uint64(job.ArtifactsFile.Size)))
+			var line string
+			if i == m.artifactsIndex {
+				line = commandItemSelectedStyle.Render("▸ " + label)
+			} else {
+				line = commandItemStyle.Render("  " + label)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("j/k: nav • enter: download • C+q: close"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 80,
+		Style:    commandMenuStyle,
+	}
+
+	modalContent := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modalContent, background, m.width, m.height)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const auditFileName = "audit.log"
+
+// Audit actions
+const (
+	auditGitPush           = "git.push"
+	auditGitTag            = "git.tag"
+	auditMRCreate          = "mr.create"
+	auditMRNote            = "mr.note"
+	auditMRNoteUpdate      = "mr.note_update"
+	auditMRApprove         = "mr.approve"
+	auditMRRebase          = "mr.rebase"
+	auditMRDraft           = "mr.draft"
+	auditMRMergeTrain      = "mr.merge_train"
+	auditMRMerge           = "mr.merge"
+	auditMRBlocklist       = "mr.blocklist"
+	auditMRLabel           = "mr.label"
+	auditMRMilestone       = "mr.milestone"
+	auditReleaseNotes      = "release_notes.publish"
+	auditReleaseEvidence   = "release_evidence.attach"
+	auditRollback          = "release.rollback"
+	auditReleasePromote    = "release.promote"
+	auditReleaseLock       = "release.lock"
+	auditPipelineTrigger   = "pipeline.trigger"
+	auditDeploymentCreate  = "deployment.create"
+	auditCredentialsSave   = "credentials.save"
+	auditCredentialsDelete = "credentials.delete"
+)
+
+// auditEntry is one line of ~/.relix/audit.log. Entries form a hash chain: Hash is the SHA-256 of
+// the entry encoded without it, and Prev is the hash of the entry before, so editing, removing or
+// reordering lines breaks the chain from there on (see verifyAuditLog).
+type auditEntry struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`              // OS account running relix
+	Account string    `json:"account,omitempty"` // Forge account (login email) of the credentials in use
+	Forge   string    `json:"forge,omitempty"`   // Forge URL of the credentials in use
+	Action  string    `json:"action"`
+	Target  string    `json:"target,omitempty"` // What was changed: repository directory, project, page, ...
+	Details string    `json:"details,omitempty"`
+	Error   string    `json:"error,omitempty"` // Set if the action failed
+	Prev    string    `json:"prev"`
+	Hash    string    `json:"hash,omitempty"`
+}
+
+var (
+	auditMu    sync.Mutex
+	auditCreds Credentials // Credentials last loaded or saved, naming the acting account
+)
+
+// setAuditAccount records the credentials whose account performs the following actions
+func setAuditAccount(creds Credentials) {
+	auditMu.Lock()
+	auditCreds = creds
+	auditMu.Unlock()
+}
+
+// auditAccount returns the forge account (login email) performing the actions, "" before sign-in
+func auditAccount() string {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	return auditCreds.Email
+}
+
+// getAuditLogPath returns the path to the audit log
+func getAuditLogPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, auditFileName), nil
+}
+
+// recordAudit appends an action to the audit log. Auditing is best effort: a log that cannot be
+// written does not stop the action, which has already happened.
+func recordAudit(action, target, details string, actionErr error) {
+	entry := auditEntry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Target:  redactSecrets(target),
+		Details: redactSecrets(details),
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	if actionErr != nil {
+		entry.Error = redactSecrets(actionErr.Error())
+	}
+	appendAuditEntry(entry)
+}
+
+// appendAuditEntry chains the entry to the last one in the log and appends it
+func appendAuditEntry(entry auditEntry) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	entry.Account = auditCreds.Email
+	entry.Forge = auditCreds.GitLabURL
+
+	path, err := getAuditLogPath()
+	if err != nil {
+		return err
+	}
+	// The file is only ever opened for appending; the last entry is read back from it, so
+	// entries written by another relix process (e.g. relix serve) stay in the chain. The file is
+	// locked until the entry is written, so two processes cannot chain to the same last entry.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	last, err := lastAuditEntry(f)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.Prev = last.Hash
+	} else {
+		entry.Seq = 1
+	}
+	entry.Hash = ""
+	entry.Hash = auditHash(entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// lastAuditEntry reads the last line of the log, or nil if it is empty
+func lastAuditEntry(f *os.File) (*auditEntry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	const tail = 64 << 10 // Entries are far smaller
+	offset := max(info.Size()-tail, 0)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	line := lines[len(lines)-1]
+	if line == "" {
+		return nil, nil
+	}
+	var entry auditEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return nil, fmt.Errorf("audit log: last entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// auditHash returns the hex SHA-256 of the entry encoded without its hash
+func auditHash(entry auditEntry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readAuditLog returns the entries of the log in order, and an error for the first line that is
+// not an entry. A missing log has no entries.
+func readAuditLog() ([]auditEntry, error) {
+	path, err := getAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		// Unknown fields would not be covered by the hash, so they count as tampering too
+		var entry auditEntry
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&entry); err != nil {
+			return entries, fmt.Errorf("line %d is not an audit entry: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// verifyAuditLog checks the hash chain of the entries and returns an error naming the first
+// entry that was changed, or that follows a removed or reordered one
+func verifyAuditLog(entries []auditEntry) error {
+	prev := ""
+	for i, entry := range entries {
+		if entry.Seq != i+1 {
+			return fmt.Errorf("entry %d has sequence number %d: entries were removed or reordered", i+1, entry.Seq)
+		}
+		if entry.Prev != prev {
+			return fmt.Errorf("entry %d does not follow entry %d: entries were removed or reordered", entry.Seq, entry.Seq-1)
+		}
+		if auditHash(entry) != entry.Hash {
+			return fmt.Errorf("entry %d was modified", entry.Seq)
+		}
+		prev = entry.Hash
+	}
+	return nil
+}
+
+// auditGitCommand records a git command that changes the remote or tags, identified by its
+// subcommand; other commands only change the local work tree and are not audited
+func auditGitCommand(workDir, command string, err error) {
+	for _, part := range strings.FieldsFunc(command, func(r rune) bool { return r == '&' || r == ';' || r == '|' }) {
+		switch commandSpanName(part) {
+		case "git push":
+			recordAudit(auditGitPush, workDir, command, err)
+			return
+		case "git tag":
+			recordAudit(auditGitTag, workDir, command, err)
+			return
+		}
+	}
+}
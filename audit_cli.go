@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// auditCommand groups the audit log subcommands
+var auditCommand = &cliCommand{
+	Name:        "audit",
+	Summary:     "Review and verify the audit log of changes made by relix",
+	Description: "Every git push and tag, merge request and comment creation, release notes page and credential change made by relix is appended to ~/.relix/audit.log with its time, OS user and forge account. The entries are hash-chained, so edits and removals can be detected.",
+	Sub: []*cliCommand{
+		auditListCommand,
+		auditVerifyCommand,
+	},
+}
+
+var auditListCommand = &cliCommand{
+	Name:        "list",
+	Summary:     "List audit log entries, oldest first",
+	Description: "Prints one line per audited action with its sequence number, time, user, account, action, target and outcome. Filters can be combined.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix audit list",
+		"relix audit list --action git.push --since 2026-01-01",
+		"relix audit list --format json",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		action := fs.String("action", "", "Only entries of `action` (e.g. git.push, mr.create, credentials.save)")
+		since := fs.String("since", "", "Only entries on or after `date` (YYYY-MM-DD)")
+		format := fs.String("format", "table", "Output `format`: table or json")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			var sinceTime time.Time
+			if *since != "" {
+				var err error
+				if sinceTime, err = time.ParseInLocation("2006-01-02", *since, time.Local); err != nil {
+					return fmt.Errorf("invalid since date %q (expected YYYY-MM-DD)", *since)
+				}
+			}
+			entries, err := readAuditLog()
+			if err != nil {
+				return err
+			}
+			filtered := make([]auditEntry, 0, len(entries))
+			for _, e := range entries {
+				if *action != "" && e.Action != *action {
+					continue
+				}
+				if !sinceTime.IsZero() && e.Time.Before(sinceTime) {
+					continue
+				}
+				filtered = append(filtered, e)
+			}
+
+			switch *format {
+			case "table":
+				tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(tw, "SEQ\tTIME\tUSER\tACCOUNT\tACTION\tTARGET\tDETAILS\tRESULT")
+				for _, e := range filtered {
+					result := "ok"
+					if e.Error != "" {
+						result = "failed: " + e.Error
+					}
+					fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Seq, formatAuditTime(e.Time),
+						e.User, e.Account, e.Action, e.Target, firstLine(e.Details), firstLine(result))
+				}
+				return tw.Flush()
+			case "json":
+				return writeJSON(os.Stdout, filtered)
+			default:
+				return fmt.Errorf("unknown format %q (expected table or json)", *format)
+			}
+		}
+	},
+}
+
+var auditVerifyCommand = &cliCommand{
+	Name:        "verify",
+	Summary:     "Check that the audit log was not modified",
+	Description: "Recomputes the hash chain of the audit log and fails at the first entry that was edited, removed or reordered. The hash of the last entry is printed; recording it elsewhere (e.g. in a compliance ticket) also makes later truncation of the log detectable.",
+	Examples:    []string{"relix audit verify"},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			entries, err := readAuditLog()
+			if err != nil {
+				return err
+			}
+			if err := verifyAuditLog(entries); err != nil {
+				return fmt.Errorf("audit log verification failed: %w", err)
+			}
+			if len(entries) == 0 {
+				fmt.Println("Audit log is empty")
+				return nil
+			}
+			last := entries[len(entries)-1]
+			fmt.Printf("Audit log OK: %d entries, last at %s\nLast hash: %s\n", len(entries), last.Time.Local().Format(time.RFC3339), last.Hash)
+			return nil
+		}
+	},
+}
+
+// firstLine returns the first line of s, marking that more followed
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
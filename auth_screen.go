@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/zalando/go-keyring"
 )
 
 // viewLoading renders the initial loading screen
@@ -61,25 +63,119 @@ func checkStoredCredentials() tea.Cmd {
 	return func() tea.Msg {
 		creds, err := LoadCredentials()
 		if err != nil {
-			return checkCredsMsg{creds: nil}
+			if errors.Is(err, keyring.ErrNotFound) {
+				err = nil
+			}
+			return checkCredsMsg{creds: nil, keyringErr: err}
+		}
+		msg := checkCredsMsg{creds: creds}
+		if releaseState, err := LoadReleaseState(firstTabID); err == nil {
+			msg.releaseState = releaseState
+		}
+		msg.tabStates = tabReleaseStates()
+		if config, err := LoadConfig(); err == nil && config.SelectedProjectID != 0 {
+			msg.project = &Project{
+				ID:                config.SelectedProjectID,
+				Name:              config.SelectedProjectShortName,
+				PathWithNamespace: config.SelectedProjectPath,
+				NameWithNamespace: config.SelectedProjectName,
+			}
+		}
+		return msg
+	}
+}
+
+// startSession goes on from the stored credentials: resumes the releases in progress, or opens
+// the home (or startup) screen; without credentials, the auth screen
+func (m *model) startSession(msg checkCredsMsg) tea.Cmd {
+	// No credentials - show auth screen, with the reason if the keyring could not be read
+	if msg.creds == nil {
+		m.moveToScreen(screenAuth)
+		m.keyringError = ""
+		if msg.keyringErr != nil {
+			m.keyringError = msg.keyringErr.Error()
+		}
+		return nil
+	}
+
+	m.creds = msg.creds
+	m.selectedProject = msg.project
+	pollCmd := tea.Batch(m.startBackgroundPolling(), m.useProjectRepoSettings())
+	if len(msg.tabStates) > 0 {
+		// Releases of other tabs are resumed in tabs of their own
+		pollCmd = tea.Batch(pollCmd, m.restoreTabs(msg.tabStates))
+	}
+
+	// Resume the release in progress, if any
+	if msg.releaseState != nil {
+		m.initListScreen()
+		m.updateListSize()
+		return tea.Batch(m.resumeRelease(msg.releaseState), pollCmd)
+	}
+	m.moveToScreen(screenHome)
+	return tea.Batch(pollCmd, m.openStartupScreen())
+}
+
+// loadStartupConfig reads the theme and environments from config.
+// It runs alongside checkStoredCredentials, so the loading screen is drawn
+// without waiting for the disk or the OS keyring.
+func loadStartupConfig() tea.Cmd {
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			config = nil
+		}
+		envs := envsFromConfig(defaultEnvironments())
+		if config != nil && len(config.Environments) > 0 {
+			envs = envsFromConfig(config.Environments)
 		}
-		return checkCredsMsg{creds: creds}
+		msg := startupConfigMsg{theme: selectedThemeColors(config), environments: envs, graphics: resolveGraphics("")}
+		if config != nil {
+			msg.graphics = resolveGraphics(config.TerminalGraphics)
+			msg.terminal = config.Terminal
+			msg.releaseWindows = config.ReleaseWindows
+			msg.pinnedProjects = config.PinnedProjects
+			msg.reduceMotion = config.ReduceMotion
+			msg.hideFieldHints = config.HideFieldHints
+			msg.workspace = config.ActiveWorkspace
+		}
+		return msg
 	}
 }
 
-// validateCredentialsCmd validates credentials against GitLab API
+// applyStartupConfig applies the theme and environments read at startup and restyles
+// the components created with the default theme
+func (m *model) applyStartupConfig(msg startupConfigMsg) {
+	applyTermPolicy(newRenderPolicy(termProbe, msg.terminal))
+	currentTheme = msg.theme
+	rebuildStyles()
+	m.environments = msg.environments
+	m.releaseWindows = msg.releaseWindows
+	m.pinnedProjects = msg.pinnedProjects
+	termGraphics = msg.graphics
+	reduceMotion = msg.reduceMotion
+	m.hideFieldHints = msg.hideFieldHints
+	m.activeWorkspace = msg.workspace
+	m.applySpinnerTheme()
+	m.inputs = initAuthInputs()
+	m.updateTextareaTheme()
+	m.startupConfigLoaded = true
+}
+
+// validateCredentialsCmd detects the forge behind the URL and validates credentials against its API
 func validateCredentialsCmd(creds Credentials) tea.Cmd {
 	return func() tea.Msg {
+		creds.Forge = detectForge(creds.GitLabURL)
 		if err := ValidateCredentials(creds); err != nil {
-			return authResultMsg{err: err}
+			return authResultMsg{creds: creds, err: err}
 		}
 
 		// Save credentials on successful validation
 		if err := SaveCredentials(creds); err != nil {
-			return authResultMsg{err: err}
+			return authResultMsg{creds: creds, err: err}
 		}
 
-		return authResultMsg{err: nil}
+		return authResultMsg{creds: creds, err: nil}
 	}
 }
 
@@ -111,8 +207,7 @@ func (m model) updateAuth(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 			// Basic validation
 			if creds.GitLabURL == "" || creds.Email == "" || creds.Token == "" {
-				m.errorMsg = "All fields are required"
-				m.screen = screenError
+				m.showError("All fields are required", errorContext{})
 				return m, nil
 			}
 
@@ -158,16 +253,23 @@ func (m model) viewAuth() string {
 	var formBuilder strings.Builder
 
 	// Form title
-	formBuilder.WriteString(formTitleStyle.Render("GitLab Authentication"))
+	formBuilder.WriteString(formTitleStyle.Render("Forge Authentication"))
 	formBuilder.WriteString("\n")
 
 	// Input fields
-	labels := []string{"GitLab URL", "Email", "Personal Access Token"}
+	labels := []string{"Forge URL", "Email", "Personal Access Token"}
 	for i, input := range m.inputs {
 		formBuilder.WriteString(inputLabelStyle.Render(labels[i]))
 		formBuilder.WriteString("\n")
 		formBuilder.WriteString(input.View())
-		formBuilder.WriteString("\n\n")
+		formBuilder.WriteString("\n")
+		if i == m.focusIndex {
+			if hint := m.renderFieldHint(m.authFieldHint(), 40); hint != "" {
+				formBuilder.WriteString(hint)
+				formBuilder.WriteString("\n")
+			}
+		}
+		formBuilder.WriteString("\n")
 	}
 
 	// Submit button
@@ -208,7 +310,7 @@ func (m model) viewAuth() string {
 
 		// Build content with title and vertical centering for loading
 		var b strings.Builder
-		b.WriteString(formTitleStyle.Render("GitLab Authentication"))
+		b.WriteString(formTitleStyle.Render("Forge Authentication"))
 		b.WriteString("\n")
 
 		// Adjust for title height (title + newline = 2 lines)
@@ -240,10 +342,20 @@ func (m model) viewAuth() string {
 		PaddingLeft(horizontalPadding).
 		Render(formContent)
 
+	// Why the stored credentials could not be read (locked or missing keyring)
+	if m.keyringError != "" && !m.loading {
+		warningWidth := min(max(formWidth, 60), m.width)
+		warning := settingsErrorStyle.Width(warningWidth).Render(m.keyringError)
+		centeredForm += "\n\n" + lipgloss.NewStyle().
+			Width(m.width).
+			Align(lipgloss.Center).
+			Render(warning)
+	}
+
 	// Help footer (centered) - hide during loading
 	var help string
 	if !m.loading {
-		helpText := "tab/↓/↑: nav • enter: submit/next • C+c: quit"
+		helpText := "tab/↓/↑: nav • enter: submit/next • " + m.fieldHintsHelp() + " • C+c: quit"
 		help = helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 	}
 
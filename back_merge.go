@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// A release to a higher environment (e.g. a hotfix straight to prod) can carry MRs the lower
+// environments never got; the next release of those starts from the base branch and silently
+// drops them. After a release completes, relix checks that the MRs of the latest release to each
+// environment are in the lower ones, and offers to open back-merge MRs from the release's source
+// branch into the lower environment branches missing them. The dashboard flags the same drift.
+//
+// Environment branches receive a squash or a copy of the release content, so an MR counts as in a
+// lower environment when its head commit is an ancestor of the environment branch or of the tag of
+// the environment's latest release. The check uses the remote branches and tags of the local clone
+// as of its last fetch; commits the clone does not know are not flagged.
+
+// backMergeGap is a lower environment missing MRs of the latest release to a higher one
+type backMergeGap struct {
+	release  *ReleaseHistoryEntry // Latest release to the higher environment
+	env      Environment          // Lower environment
+	branches []string             // MR branches of the release missing from env
+}
+
+// backMergeCheckMsg carries the gaps left by the release just completed
+type backMergeCheckMsg struct {
+	projectID int
+	gaps      []backMergeGap
+}
+
+// backMergeCreatedMsg reports the back-merge MRs created
+type backMergeCreatedMsg struct {
+	urls []string
+	err  error
+}
+
+// latestProjectReleases returns the latest completed release of the project to each environment,
+// by lowercase environment name. Rollbacks restore older content and are skipped. index is newest
+// first.
+func latestProjectReleases(project string, index []HistoryIndexEntry) map[string]*ReleaseHistoryEntry {
+	latest := make(map[string]*ReleaseHistoryEntry)
+	for _, e := range index {
+		env := strings.ToLower(e.Environment)
+		if e.Status != "completed" || e.RollbackOf != "" || latest[env] != nil {
+			continue
+		}
+		detail, err := LoadHistoryDetail(e.ID)
+		if err != nil || detail.Project != project {
+			continue
+		}
+		latest[env] = detail
+	}
+	return latest
+}
+
+// gitCommitExists reports whether the clone has the commit
+func gitCommitExists(workDir, commit string) bool {
+	cmd := exec.Command("git", "cat-file", "-e", commit+"^{commit}")
+	cmd.Dir = workDir
+	return cmd.Run() == nil
+}
+
+// findBackMergeGaps checks the latest release of the project to each environment against the
+// environments below it. envs are ordered from the lowest environment to the highest.
+func findBackMergeGaps(workDir, project string, envs []Environment, index []HistoryIndexEntry) []backMergeGap {
+	latest := latestProjectReleases(project, index)
+	var gaps []backMergeGap
+	for j := 1; j < len(envs); j++ {
+		release := latest[strings.ToLower(envs[j].Name)]
+		if release == nil {
+			continue
+		}
+		for _, lower := range envs[:j] {
+			branch := "origin/" + lower.BranchName
+			if GetBranchCommitID(workDir, branch) == "" {
+				continue
+			}
+			revs := []string{branch}
+			if lowerRelease := latest[strings.ToLower(lower.Name)]; lowerRelease != nil {
+				revs = append(revs, "refs/tags/"+historyGitTag(lowerRelease.HistoryIndexEntry))
+			}
+
+			var missing []string
+			for k, sha := range release.MRCommitSHAs {
+				if sha == "" || k >= len(release.MRBranches) || !gitCommitExists(workDir, sha) {
+					continue
+				}
+				found := false
+				for _, rev := range revs {
+					if found = isGitAncestor(workDir, sha, rev); found {
+						break
+					}
+				}
+				if !found {
+					missing = append(missing, release.MRBranches[k])
+				}
+			}
+			if len(missing) > 0 {
+				gaps = append(gaps, backMergeGap{release: release, env: lower, branches: missing})
+			}
+		}
+	}
+	return gaps
+}
+
+// checkBackMerges checks the release just completed to env against the lower environments
+func (m *model) checkBackMerges(state *ReleaseState) tea.Cmd {
+	if m.selectedProject == nil {
+		return nil
+	}
+	project := *m.selectedProject
+	envs := append([]Environment{}, m.environments...)
+	workDir, env := state.WorkDir, state.Environment.Name
+	return func() tea.Msg {
+		index, err := LoadHistoryIndex()
+		if err != nil {
+			return nil
+		}
+		var gaps []backMergeGap
+		for _, gap := range findBackMergeGaps(workDir, project.PathWithNamespace, envs, index) {
+			if strings.EqualFold(gap.release.Environment, env) {
+				gaps = append(gaps, gap)
+			}
+		}
+		if len(gaps) == 0 {
+			return nil
+		}
+		return backMergeCheckMsg{projectID: project.ID, gaps: gaps}
+	}
+}
+
+// handleBackMergeCheck shows the gaps left by the release just completed
+func (m *model) handleBackMergeCheck(msg backMergeCheckMsg) {
+	m.backMergeGaps = msg.gaps
+	m.backMergeProjectID = msg.projectID
+	m.backMergeIndex = 0
+	m.showBackMerge = true
+}
+
+// closeBackMerge closes the back-merge modal and clears its state
+func (m *model) closeBackMerge() {
+	m.showBackMerge = false
+	m.backMergeGaps = nil
+	m.backMergeIndex = 0
+}
+
+// updateBackMerge handles keys of the back-merge modal
+func (m model) updateBackMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "c":
+		return m.createBackMerges()
+	case "esc", "q", "ctrl+q":
+		m.closeBackMerge()
+	case "enter":
+		if m.backMergeIndex == 0 {
+			return m.createBackMerges()
+		}
+		m.closeBackMerge()
+	case "tab", "left", "right", "h", "l":
+		m.backMergeIndex = 1 - m.backMergeIndex
+	}
+	return m, nil
+}
+
+// createBackMerges opens an MR from the release's source branch into each lower environment
+// branch missing its MRs
+func (m model) createBackMerges() (tea.Model, tea.Cmd) {
+	gaps, projectID := m.backMergeGaps, m.backMergeProjectID
+	m.closeBackMerge()
+	if m.creds == nil || len(gaps) == 0 {
+		return m, nil
+	}
+	creds := *m.creds
+	return m, func() tea.Msg {
+		client := NewForge(creds)
+		var urls []string
+		for _, gap := range gaps {
+			if gap.release.SourceBranch == "" {
+				return backMergeCreatedMsg{urls: urls, err: fmt.Errorf("release %s has no source branch", gap.release.Tag)}
+			}
+			title := fmt.Sprintf("Back-merge %s into %s", historyGitTag(gap.release.HistoryIndexEntry), gap.env.BranchName)
+			description := fmt.Sprintf("Brings the MRs released to %s and missing from %s back into %s:\n\n- %s",
+				strings.ToUpper(gap.release.Environment), strings.ToUpper(gap.env.Name), gap.env.BranchName,
+				strings.Join(gap.branches, "\n- "))
+			mr, err := client.CreateMergeRequest(projectID, gap.release.SourceBranch, gap.env.BranchName, title, description)
+			recordAudit(auditMRCreate, fmt.Sprintf("project %d", projectID), fmt.Sprintf("%s -> %s: %s", gap.release.SourceBranch, gap.env.BranchName, title), err)
+			if err != nil {
+				return backMergeCreatedMsg{urls: urls, err: fmt.Errorf("back-merge into %s: %w", gap.env.BranchName, err)}
+			}
+			urls = append(urls, mr.WebURL)
+		}
+		return backMergeCreatedMsg{urls: urls}
+	}
+}
+
+// handleBackMergeCreated reports the back-merge MRs created
+func (m *model) handleBackMergeCreated(msg backMergeCreatedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = msg.err.Error()
+		if len(msg.urls) > 0 {
+			m.errorModalMsg += "\n\nCreated: " + strings.Join(msg.urls, ", ")
+		}
+		return nil
+	}
+	if len(msg.urls) == 1 {
+		return m.showToast("Back-merge MR created")
+	}
+	return m.showToast(fmt.Sprintf("%d back-merge MRs created", len(msg.urls)))
+}
+
+// overlayBackMerge renders the back-merge modal over the screen
+func (m model) overlayBackMerge(background string) string {
+	if len(m.backMergeGaps) == 0 {
+		return background
+	}
+	release := m.backMergeGaps[0].release
+
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("Back-merge Needed"))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "%s released to %s MRs the lower environments do not have:\n\n",
+		historyGitTag(release.HistoryIndexEntry), strings.ToUpper(release.Environment))
+	for _, gap := range m.backMergeGaps {
+		fmt.Fprintf(&sb, "%s (%s): %s\n",
+			getEnvBranchStyle(gap.env.Name).Render(strings.ToUpper(gap.env.Name)), gap.env.BranchName,
+			strings.Join(gap.branches, ", "))
+	}
+	fmt.Fprintf(&sb, "\nCreate MRs from %s into these branches?\n\n", release.SourceBranch)
+
+	createBtn, dismissBtn := buttonActiveStyle.Render("Create MRs"), buttonStyle.Render("Dismiss")
+	if m.backMergeIndex != 0 {
+		createBtn, dismissBtn = buttonStyle.Render("Create MRs"), buttonActiveStyle.Render("Dismiss")
+	}
+	sb.WriteString(fmt.Sprintf("     %s       %s", createBtn, dismissBtn))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 80,
+		Style:    errorBoxStyle,
+	}
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
+
+// dashboardBackMergeLines flags the environments missing MRs of a higher one's latest release
+func (m model) dashboardBackMergeLines() []string {
+	var lines []string
+	warning := lipgloss.NewStyle().Foreground(currentTheme.Warning)
+	for _, gap := range m.dashboard.backMerges {
+		mrs := "1 MR"
+		if len(gap.branches) > 1 {
+			mrs = fmt.Sprintf("%d MRs", len(gap.branches))
+		}
+		lines = append(lines, warning.Render(fmt.Sprintf("! %s %s: %s not in %s",
+			strings.ToUpper(gap.release.Environment), gap.release.Tag, mrs, strings.ToUpper(gap.env.Name))))
+	}
+	return lines
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Periodic work runs in tick loops: a tick message starts a tea.Cmd doing the network call off the
+// UI thread, and the result message re-arms the tick. A loop never has more than one call in flight,
+// so a slow GitLab delays the next check instead of queueing checks behind each other.
+const (
+	pipelinePollInterval = 7 * time.Second  // Release pipeline while waiting for the root push
+	mrPollInterval       = 60 * time.Second // Open MR list while it is shown
+	tokenPollInterval    = time.Hour        // GitLab token validity and expiry
+	tokenExpiryWarning   = 7 * 24 * time.Hour
+	mrFullSyncInterval   = 15 * time.Minute // Full MR list refresh catching up on what the deltas missed
+	mrSyncOverlap        = time.Minute      // Delta refreshes reach back this far, for clock skew with the forge
+	planWatchInterval    = 30 * time.Second // Selected MRs while the release plan is prepared (see plan_watch.go)
+)
+
+// waitPollInterval waits a pipelinePollInterval in the waits of release steps, and returns early
+// with the cause once ctx is done (the release was aborted)
+func waitPollInterval(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-time.After(pipelinePollInterval):
+		return nil
+	}
+}
+
+// startBackgroundPolling starts the MR, token and dashboard loops for the current credentials.
+// Loops started for earlier credentials stop at their next tick.
+func (m *model) startBackgroundPolling() tea.Cmd {
+	m.backgroundPollGen++
+	m.mrPollInFlight = false
+	m.tokenExpiresAt = nil
+	m.tokenCheckErr = nil
+	m.dashboard = dashboardState{}
+	return tea.Batch(m.mrPollTick(), m.checkToken(), m.dashboardPollTick(), m.loadDashboard())
+}
+
+// stopBackgroundPolling stops the MR, token and dashboard loops (on logout)
+func (m *model) stopBackgroundPolling() {
+	m.backgroundPollGen++
+	m.mrPollInFlight = false
+	m.tokenExpiresAt = nil
+	m.tokenCheckErr = nil
+	m.dashboard = dashboardState{}
+}
+
+// mrPollTick returns a command that triggers an MR list refresh after mrPollInterval
+func (m *model) mrPollTick() tea.Cmd {
+	gen := m.backgroundPollGen
+	return tea.Tick(mrPollInterval, func(t time.Time) tea.Msg {
+		return mrPollTickMsg{gen: gen}
+	})
+}
+
+// handleMRPollTick refreshes the MR list in the background if it is shown and idle, and re-arms the tick.
+// While the user is filtering the list is left alone, as replacing the items would reset the filter.
+func (m *model) handleMRPollTick(msg mrPollTickMsg) tea.Cmd {
+	if msg.gen != m.backgroundPollGen || m.creds == nil {
+		return nil
+	}
+	tick := m.mrPollTick()
+	if m.screen != screenMain || !m.mrsLoaded || m.loadingMRs || m.mrsCached || m.mrPollInFlight ||
+		m.list.FilterState() != list.Unfiltered {
+		return tick
+	}
+	m.mrPollInFlight = true
+	return tea.Batch(tick, m.refreshMRs())
+}
+
+// refreshMRs fetches the open MRs like fetchMRs, for a background refresh. Between full refreshes
+// only the MRs updated since the last one are fetched and merged into the list, so MRs that did not
+// change keep their loaded details; forges that cannot list MRs by update time are refreshed in full.
+// Credentials, project and listed MRs are read before the command runs.
+func (m *model) refreshMRs() tea.Cmd {
+	creds := *m.creds
+	projectID := 0
+	if m.selectedProject != nil {
+		projectID = m.selectedProject.ID
+	}
+	pages := max(m.mrPages, 1) // Pages loaded with "load more" are refreshed too
+	delta := projectID != 0 && !m.mrsSyncedAt.IsZero() && time.Since(m.mrsFullSyncAt) < mrFullSyncInterval
+	since := m.mrsSyncedAt.Add(-mrSyncOverlap)
+	listed := slices.Clone(m.mrsAll)
+	listedPages, listedPage := m.mrPages, m.mrPage
+
+	return func() tea.Msg {
+		client := NewForge(creds)
+		started := time.Now()
+		if delta {
+			if changed, err := client.GetProjectMergeRequestsUpdatedSince(projectID, since); err == nil {
+				mrs := mergeMRDelta(listed, changed)
+				saveCachedMRs(creds.GitLabURL, projectID, mrs)
+				return fetchMRsMsg{projectID: projectID, mrs: mrs, background: true, pages: listedPages, page: listedPage, syncedAt: started}
+			}
+			// Unsupported or failed: refresh in full
+		}
+
+		var mrs []*MergeRequestDetails
+		var err error
+		msg := fetchMRsMsg{projectID: projectID, background: true, pages: 1, page: listPage{Total: -1}, syncedAt: started, fullSync: true}
+		if projectID != 0 {
+			for page := 1; page <= pages; page++ {
+				var pageMRs []*MergeRequestDetails
+				var info listPage
+				if pageMRs, info, err = client.GetProjectMergeRequestsPage(projectID, page); err != nil {
+					break
+				}
+				mrs = appendMRPage(mrs, pageMRs)
+				msg.pages, msg.page = page, info
+				if !info.HasMore {
+					break
+				}
+			}
+			if err == nil {
+				saveCachedMRs(creds.GitLabURL, projectID, mrs)
+			}
+		} else {
+			mrs, err = client.GetOpenMergeRequests()
+		}
+		msg.mrs, msg.err = mrs, err
+		return msg
+	}
+}
+
+// trackMRSync remembers when the fetched MR list was synced, for the next delta refresh
+func (m *model) trackMRSync(msg fetchMRsMsg) {
+	if msg.projectID == 0 {
+		return
+	}
+	m.mrsSyncedAt = msg.syncedAt
+	if msg.fullSync {
+		m.mrsFullSyncAt = msg.syncedAt
+	}
+}
+
+// mergeMRDelta applies the MRs changed since the last refresh to the listed ones: changed open MRs
+// replace their listed copy, new ones are added and MRs no longer open are removed. MRs reported
+// again without a change (the sync overlap) stay as listed.
+func mergeMRDelta(listed, changed []*MergeRequestDetails) []*MergeRequestDetails {
+	updates := make(map[int]*MergeRequestDetails, len(changed))
+	for _, mr := range changed {
+		updates[mr.ID] = mr
+	}
+	merged := make([]*MergeRequestDetails, 0, len(listed)+len(changed))
+	for _, mr := range listed {
+		update, ok := updates[mr.ID]
+		if !ok {
+			merged = append(merged, mr)
+			continue
+		}
+		delete(updates, mr.ID)
+		switch {
+		case update.State != "opened":
+		case !mr.UpdatedAt.IsZero() && update.UpdatedAt.Equal(mr.UpdatedAt):
+			merged = append(merged, mr)
+		default:
+			merged = append(merged, update)
+		}
+	}
+	for _, mr := range changed {
+		if updates[mr.ID] != nil && mr.State == "opened" {
+			merged = append(merged, mr)
+		}
+	}
+	return merged
+}
+
+// tokenPollTick returns a command that triggers a token check after tokenPollInterval
+func (m *model) tokenPollTick() tea.Cmd {
+	gen := m.backgroundPollGen
+	return tea.Tick(tokenPollInterval, func(t time.Time) tea.Msg {
+		return tokenPollTickMsg{gen: gen}
+	})
+}
+
+// checkToken fetches the expiry of the GitLab token
+func (m *model) checkToken() tea.Cmd {
+	if m.creds == nil {
+		return nil
+	}
+	gen := m.backgroundPollGen
+	creds := *m.creds
+
+	return func() tea.Msg {
+		expiresAt, err := NewForge(creds).GetTokenExpiry()
+		return tokenStatusMsg{gen: gen, expiresAt: expiresAt, err: err}
+	}
+}
+
+// handleTokenStatus stores the result of a token check and re-arms the tick.
+// Network errors keep the previous result; only a rejected token is reported.
+func (m *model) handleTokenStatus(msg tokenStatusMsg) tea.Cmd {
+	if msg.gen != m.backgroundPollGen {
+		return nil
+	}
+	if msg.err == nil {
+		m.tokenExpiresAt = msg.expiresAt
+		m.tokenCheckErr = nil
+	} else if strings.HasPrefix(msg.err.Error(), "invalid token") {
+		m.tokenCheckErr = msg.err
+	}
+	// The token check runs on every screen, so it also notices the forge going away and coming back
+	flushCmd := m.trackOffline(msg.err)
+	m.updateMRListTitle()
+	return tea.Batch(flushCmd, m.tokenPollTick())
+}
+
+// tokenWarning returns a warning about an invalid or soon expiring GitLab token, or ""
+func (m model) tokenWarning() string {
+	if m.tokenCheckErr != nil {
+		return "GitLab token was rejected: log in again with / → logout"
+	}
+	if m.tokenExpiresAt == nil {
+		return ""
+	}
+	left := time.Until(*m.tokenExpiresAt)
+	if left > tokenExpiryWarning {
+		return ""
+	}
+	date := formatDate(*m.tokenExpiresAt)
+	if left <= 0 {
+		return fmt.Sprintf("GitLab token expired on %s", date)
+	}
+	return fmt.Sprintf("GitLab token expires on %s", date)
+}
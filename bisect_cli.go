@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// bisectCommand lists the MRs released between a good and a bad release and drives git bisect
+// between their tags, naming the MR of the first bad commit
+var bisectCommand = &cliCommand{
+	Name:        "bisect",
+	Summary:     "Find the MR that broke an environment between two releases",
+	Description: "Lists the MRs released to the environment of the bad release after the good one, up to the bad one. With --start or --run, git bisect runs between the tags of the two releases in the project directory; --run bisects automatically with a test command and names the MR of the first bad commit. The releases are given by history ID or tag, as for \"relix history show\".",
+	Usage:       "[options] <good id|tag> <bad id|tag>",
+	Examples: []string{
+		"relix bisect 1.2-v1 1.3-v2",
+		"relix bisect --run 'make test' stage-1.2-v1 stage-1.3-v2",
+		"relix bisect --start 1.2-v1 1.3-v2",
+		"relix bisect --commit 4f2a9c1 1.2-v1 1.3-v2",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		start := fs.Bool("start", false, "Start git bisect between the release tags and leave marking commits to you")
+		run := fs.String("run", "", "Bisect automatically: `command` exits 0 for good commits and 1-127 (but 125) for bad ones")
+		commit := fs.String("commit", "", "Name the MR that introduced `commit` instead of bisecting")
+		projectDir := fs.String("project-directory", "", "Project root directory `path`")
+		return func(args []string) error {
+			if len(args) != 2 || (*start && *run != "") {
+				return errCLIUsage
+			}
+			rng, err := loadBisectRange(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if err := setProjectDirectory(*projectDir); err != nil {
+				return err
+			}
+
+			if *commit != "" {
+				workDir, err := projectWorkDir(nil)
+				if err != nil {
+					return err
+				}
+				fmt.Println(rng.culprit(workDir, *commit))
+				return nil
+			}
+
+			writeBisectRange(os.Stdout, rng)
+			if !*start && *run == "" {
+				return nil
+			}
+
+			workDir, err := prepareReleaseWorkDir(nil, "")
+			if err != nil {
+				return err
+			}
+			goodTag, badTag := historyGitTag(rng.good.HistoryIndexEntry), historyGitTag(rng.bad.HistoryIndexEntry)
+			fmt.Println()
+			if err := runBisectGit(workDir, "fetch", "--tags"); err != nil {
+				return err
+			}
+			if err := runBisectGit(workDir, "bisect", "start", badTag, goodTag); err != nil {
+				return err
+			}
+
+			if *start {
+				fmt.Printf("\nBisecting in %s. Mark commits with \"git bisect good\" or \"git bisect bad\",\n", workDir)
+				fmt.Printf("name the MR of the first bad commit with \"relix bisect --commit <sha> %s %s\"\n", args[0], args[1])
+				fmt.Println("and end with \"git bisect reset\".")
+				return nil
+			}
+
+			runErr := runBisectGit(workDir, "bisect", "run", "sh", "-c", *run)
+			culprit, _ := gitOutput(workDir, "rev-parse", "--verify", "-q", "refs/bisect/bad")
+			// The first bad commit is only known if the run narrowed it down to one
+			if out, _ := gitOutput(workDir, "bisect", "log"); !strings.Contains(out, "# first bad commit:") {
+				culprit = ""
+			}
+			if err := runBisectGit(workDir, "bisect", "reset"); err != nil && runErr == nil {
+				runErr = err
+			}
+			if culprit == "" {
+				if runErr == nil {
+					runErr = errors.New("git bisect did not find the first bad commit")
+				}
+				return runErr
+			}
+			fmt.Println()
+			fmt.Println(rng.culprit(workDir, culprit))
+			return nil
+		}
+	},
+}
+
+// bisectRange is a good release, a bad one and the releases of the bad one's environment between them
+type bisectRange struct {
+	good     *ReleaseHistoryEntry
+	bad      *ReleaseHistoryEntry
+	releases []*ReleaseHistoryEntry // Completed releases after good up to bad, oldest first
+}
+
+// loadBisectRange loads the releases between a good and a bad release from history
+func loadBisectRange(goodRef, badRef string) (*bisectRange, error) {
+	good, err := findHistoryEntry(goodRef)
+	if err != nil {
+		return nil, err
+	}
+	bad, err := findHistoryEntry(badRef)
+	if err != nil {
+		return nil, err
+	}
+	if !good.DateTime.Before(bad.DateTime) {
+		return nil, fmt.Errorf("the good release %s must be older than the bad release %s",
+			historyGitTag(good.HistoryIndexEntry), historyGitTag(bad.HistoryIndexEntry))
+	}
+
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		return nil, fmt.Errorf("load history index: %w", err)
+	}
+	rng := &bisectRange{good: good, bad: bad}
+	for _, e := range slices.Backward(index) {
+		if e.ID != bad.ID && (e.Status != "completed" || !strings.EqualFold(e.Environment, bad.Environment)) {
+			continue
+		}
+		if !e.DateTime.After(good.DateTime) || e.DateTime.After(bad.DateTime) {
+			continue
+		}
+		detail, err := LoadHistoryDetail(e.ID)
+		if err != nil {
+			return nil, err
+		}
+		rng.releases = append(rng.releases, detail)
+	}
+	return rng, nil
+}
+
+// introduced reports whether the i-th MR of a release in the range is new since the good release
+func (r *bisectRange) introduced(e *ReleaseHistoryEntry, i int) bool {
+	return !slices.Contains(r.good.MRBranches, e.MRBranches[i])
+}
+
+// writeBisectRange prints the MRs introduced between the good and the bad release, by release
+func writeBisectRange(w io.Writer, r *bisectRange) {
+	fmt.Fprintf(w, "Releases to %s after %s up to %s:\n", r.bad.Environment,
+		historyGitTag(r.good.HistoryIndexEntry), historyGitTag(r.bad.HistoryIndexEntry))
+	mrs := 0
+	for _, e := range r.releases {
+		fmt.Fprintf(w, "\n%s  %s\n", historyGitTag(e.HistoryIndexEntry), formatDateTime(e.DateTime))
+		for i, branch := range e.MRBranches {
+			if !r.introduced(e, i) {
+				continue
+			}
+			mrs++
+			line := "  - " + branch
+			if i < len(e.MRIIDs) {
+				line = fmt.Sprintf("  - !%d %s", e.MRIIDs[i], branch)
+			}
+			if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+				line += "  " + e.MRURLs[i]
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	fmt.Fprintf(w, "\n%d %s in %d %s\n", mrs, plural(mrs, "MR", "MRs"), len(r.releases), plural(len(r.releases), "release", "releases"))
+}
+
+// mergeSubjectBranch matches the branch merged by a merge commit of a release source branch
+var mergeSubjectBranch = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '(?:origin/)?([^']+)'`)
+
+// culprit describes the MR that introduced commit: the first MR of the range, in release and merge
+// order, whose head at release time contains it, or the MR merged by a merge commit
+func (r *bisectRange) culprit(workDir, commit string) string {
+	sha, err := gitOutput(workDir, "rev-parse", "--verify", "-q", commit+"^{commit}")
+	if err != nil {
+		return fmt.Sprintf("Commit %s is not in the working copy", commit)
+	}
+	subject, _ := gitOutput(workDir, "log", "-1", "--format=%s", sha)
+
+	describe := func(e *ReleaseHistoryEntry, i int) string {
+		s := fmt.Sprintf("First bad commit %s (%s) came with %s", sha[:min(len(sha), 10)], subject, e.MRBranches[i])
+		if i < len(e.MRIIDs) {
+			s += fmt.Sprintf(" (!%d)", e.MRIIDs[i])
+		}
+		s += " in " + historyGitTag(e.HistoryIndexEntry)
+		if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+			s += "\n" + e.MRURLs[i]
+		}
+		return s
+	}
+
+	goodTag := historyGitTag(r.good.HistoryIndexEntry)
+	if !isGitAncestor(workDir, sha, goodTag) {
+		for _, e := range r.releases {
+			for i := range e.MRBranches {
+				if r.introduced(e, i) && i < len(e.MRCommitSHAs) && e.MRCommitSHAs[i] != "" && isGitAncestor(workDir, sha, e.MRCommitSHAs[i]) {
+					return describe(e, i)
+				}
+			}
+		}
+	}
+	if m := mergeSubjectBranch.FindStringSubmatch(subject); m != nil {
+		for _, e := range r.releases {
+			if i := slices.Index(e.MRBranches, m[1]); i >= 0 {
+				return describe(e, i)
+			}
+		}
+	}
+	return fmt.Sprintf("First bad commit %s (%s) is not part of an MR released between %s and %s",
+		sha[:min(len(sha), 10)], subject, goodTag, historyGitTag(r.bad.HistoryIndexEntry))
+}
+
+// isGitAncestor reports whether commit is an ancestor of (or equal to) rev
+func isGitAncestor(workDir, commit, rev string) bool {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", commit, rev)
+	cmd.Dir = workDir
+	return cmd.Run() == nil
+}
+
+// gitOutput runs a git command in workDir and returns its trimmed output
+func gitOutput(workDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// runBisectGit runs a git command in workDir with its output on the terminal
+func runBisectGit(workDir string, args ...string) error {
+	fmt.Println("$ git " + strings.Join(args, " "))
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
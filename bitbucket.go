@@ -0,0 +1,842 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BitbucketClient handles Bitbucket Server / Data Center REST API requests.
+// Projects are repositories; pipelines are the build statuses CI reports for a commit.
+type BitbucketClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// bitbucketRepos caches repositories by ID, shared by all clients
+var bitbucketRepos sync.Map
+
+// bitbucketPipelines maps the pipeline IDs made up from build statuses to their commit. It is kept
+// in the cache directory, so a release resumed after a restart still finds its pipeline.
+var bitbucketPipelines struct {
+	sync.Mutex
+	loaded  bool
+	baseURL string
+	entries map[int]bitbucketPipeline
+}
+
+// bitbucketPipeline is the commit of a made-up pipeline ID
+type bitbucketPipeline struct {
+	ProjectID int       `json:"project_id"`
+	SHA       string    `json:"sha"`
+	SeenAt    time.Time `json:"seen_at"`
+}
+
+const (
+	bitbucketPipelinesCacheName = "bitbucket-pipelines.json"
+	bitbucketPipelinesKept      = 1000 // Pipelines remembered, the most recently seen first
+)
+
+// bitbucketPRIDBase spreads pull request IDs, which repeat across repositories, into global MR IDs
+const bitbucketPRIDBase = 1_000_000
+
+// NewBitbucketClient creates a new Bitbucket Data Center API client for the instance at baseURL
+func NewBitbucketClient(baseURL, token string) *BitbucketClient {
+	registerForgeHost(baseURL)
+	return &BitbucketClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Transport: gitlabTransport},
+	}
+}
+
+// bitbucketRepo is a repository as returned by the API
+type bitbucketRepo struct {
+	ID       int    `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"` // Bitbucket 8.0 and later
+	Project  struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// path returns the API path of the repository
+func (r bitbucketRepo) path() string {
+	return "/projects/" + url.PathEscape(r.Project.Key) + "/repos/" + url.PathEscape(r.Slug)
+}
+
+// bitbucketRef is a branch of a pull request
+type bitbucketRef struct {
+	ID           string        `json:"id"`
+	DisplayID    string        `json:"displayId"`
+	LatestCommit string        `json:"latestCommit"`
+	Repository   bitbucketRepo `json:"repository"`
+}
+
+// bitbucketPull is a pull request as returned by the API
+type bitbucketPull struct {
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	State       string       `json:"state"` // OPEN, MERGED or DECLINED
+	Draft       bool         `json:"draft"`
+	CreatedDate int64        `json:"createdDate"` // Milliseconds
+	UpdatedDate int64        `json:"updatedDate"` // Milliseconds
+	FromRef     bitbucketRef `json:"fromRef"`
+	ToRef       bitbucketRef `json:"toRef"`
+	Author      struct {
+		User struct {
+			ID          int    `json:"id"`
+			Name        string `json:"name"`
+			DisplayName string `json:"displayName"`
+		} `json:"user"`
+	} `json:"author"`
+	Properties struct {
+		CommentCount  int `json:"commentCount"`
+		OpenTaskCount int `json:"openTaskCount"`
+		MergeResult   struct {
+			Outcome string `json:"outcome"`
+		} `json:"mergeResult"`
+		MergeCommit struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// mergeRequest converts the pull request to the GitLab-shaped MergeRequest used across relix
+func (p bitbucketPull) mergeRequest() MergeRequest {
+	mr := MergeRequest{
+		ID:                          p.ToRef.Repository.ID*bitbucketPRIDBase + p.ID,
+		IID:                         p.ID,
+		Title:                       p.Title,
+		Description:                 p.Description,
+		SourceBranch:                p.FromRef.DisplayID,
+		TargetBranch:                p.ToRef.DisplayID,
+		CreatedAt:                   time.UnixMilli(p.CreatedDate),
+		UpdatedAt:                   time.UnixMilli(p.UpdatedDate),
+		Draft:                       p.Draft,
+		UserNotesCount:              p.Properties.CommentCount,
+		HasConflicts:                p.Properties.MergeResult.Outcome == "CONFLICTED",
+		BlockingDiscussionsResolved: p.Properties.OpenTaskCount == 0,
+		SHA:                         p.FromRef.LatestCommit,
+		MergeCommitSHA:              p.Properties.MergeCommit.ID,
+	}
+	switch p.Properties.MergeResult.Outcome {
+	case "CLEAN":
+		mr.MergeStatus = "can_be_merged"
+	case "CONFLICTED":
+		mr.MergeStatus = "cannot_be_merged"
+	}
+	switch p.State {
+	case "OPEN":
+		mr.State = "opened"
+	case "MERGED":
+		mr.State = "merged"
+	default:
+		mr.State = "closed"
+	}
+	if len(p.Links.Self) > 0 {
+		mr.WebURL = p.Links.Self[0].Href
+	}
+	mr.Author.ID = p.Author.User.ID
+	mr.Author.Username = p.Author.User.Name
+	mr.Author.Name = p.Author.User.DisplayName
+	return mr
+}
+
+// bitbucketBuild is a build status reported by CI for a commit
+type bitbucketBuild struct {
+	State     string `json:"state"` // SUCCESSFUL, FAILED, INPROGRESS, or UNKNOWN/CANCELLED on newer versions
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	DateAdded int64  `json:"dateAdded"`
+}
+
+// bitbucketStatus maps a build state to the GitLab pipeline/job status names the observer counts
+func bitbucketStatus(state string) string {
+	switch state {
+	case "SUCCESSFUL":
+		return "success"
+	case "FAILED":
+		return "failed"
+	case "CANCELLED":
+		return "canceled"
+	case "INPROGRESS":
+		return "running"
+	}
+	return "created"
+}
+
+// do sends a request to path (relative to the REST root, e.g. /api/1.0/...) and decodes a 2xx
+// JSON response into out. It returns the response for its headers; the body is already closed.
+func (c *BitbucketClient) do(method, path string, payload, out interface{}) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+"/rest"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return resp, fmt.Errorf("invalid token: authentication failed")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		var apiErr struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && len(apiErr.Errors) > 0 {
+			return resp, fmt.Errorf("Bitbucket API error: status %d, %s", resp.StatusCode, apiErr.Errors[0].Message)
+		}
+		return resp, fmt.Errorf("Bitbucket API error: status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// listRepos fetches all repositories the user can read and caches them
+func (c *BitbucketClient) listRepos() ([]bitbucketRepo, error) {
+	var repos []bitbucketRepo
+	start := 0
+	for {
+		var page struct {
+			Values        []bitbucketRepo `json:"values"`
+			IsLastPage    bool            `json:"isLastPage"`
+			NextPageStart int             `json:"nextPageStart"`
+		}
+		path := fmt.Sprintf("/api/1.0/repos?permission=REPO_READ&limit=1000&start=%d", start)
+		if _, err := c.do("GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, r := range page.Values {
+			bitbucketRepos.Store(r.ID, r)
+		}
+		repos = append(repos, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			return repos, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// repo returns a repository by ID. The API cannot look repositories up by ID, so they are listed.
+func (c *BitbucketClient) repo(projectID int) (bitbucketRepo, error) {
+	if r, ok := bitbucketRepos.Load(projectID); ok {
+		return r.(bitbucketRepo), nil
+	}
+	if _, err := c.listRepos(); err != nil {
+		return bitbucketRepo{}, err
+	}
+	if r, ok := bitbucketRepos.Load(projectID); ok {
+		return r.(bitbucketRepo), nil
+	}
+	return bitbucketRepo{}, fmt.Errorf("repository %d not found", projectID)
+}
+
+// GetUserEmails retrieves the authenticated user's email; the user is named in the X-AUSERNAME header
+func (c *BitbucketClient) GetUserEmails() ([]string, error) {
+	resp, err := c.do("GET", "/api/1.0/application-properties", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	username := resp.Header.Get("X-AUSERNAME")
+	if username == "" {
+		return nil, fmt.Errorf("invalid token: authentication failed")
+	}
+
+	var user struct {
+		EmailAddress string `json:"emailAddress"`
+	}
+	if _, err := c.do("GET", "/api/1.0/users/"+url.PathEscape(username), nil, &user); err != nil {
+		return nil, err
+	}
+	if user.EmailAddress == "" {
+		return nil, nil
+	}
+	return []string{user.EmailAddress}, nil
+}
+
+// GetTokenExpiry returns nil: the API lists a user's tokens but cannot tell which one is in use
+func (c *BitbucketClient) GetTokenExpiry() (*time.Time, error) {
+	return nil, nil
+}
+
+// GetProjects fetches the first page of repositories the user has access to
+func (c *BitbucketClient) GetProjects() ([]Project, error) {
+	projects, _, err := c.GetProjectsPage(1, projectQuery{})
+	return projects, err
+}
+
+// GetProjectsPage fetches a page of repositories the user has access to. Bitbucket does not
+// report the number of repositories and cannot list starred or owned ones; archived ones are dropped.
+func (c *BitbucketClient) GetProjectsPage(page int, query projectQuery) ([]Project, listPage, error) {
+	if query.Scope != projectScopeAll {
+		return nil, listPage{Total: -1}, fmt.Errorf("Bitbucket cannot list %s repositories", query.Scope)
+	}
+	var result struct {
+		Values     []bitbucketRepo `json:"values"`
+		IsLastPage bool            `json:"isLastPage"`
+	}
+	path := fmt.Sprintf("/api/1.0/repos?permission=REPO_READ&limit=%d&start=%d", listPageSize, (page-1)*listPageSize)
+	if query.Archived {
+		path += "&archived=ALL"
+	}
+	if _, err := c.do("GET", path, nil, &result); err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+
+	projects := make([]Project, 0, len(result.Values))
+	for _, r := range result.Values {
+		bitbucketRepos.Store(r.ID, r)
+		if r.Archived && !query.Archived {
+			continue
+		}
+		project := Project{
+			ID:                r.ID,
+			Name:              r.Name,
+			NameWithNamespace: r.Project.Name + " / " + r.Name,
+			Path:              r.Slug,
+			PathWithNamespace: r.Project.Key + "/" + r.Slug,
+		}
+		if len(r.Links.Self) > 0 {
+			project.WebURL = r.Links.Self[0].Href
+		}
+		projects = append(projects, project)
+	}
+	return projects, listPage{Total: -1, HasMore: !result.IsLastPage}, nil
+}
+
+// newBitbucketPullList wraps listed pull requests; lists carry everything but diff stats
+func newBitbucketPullList(pulls []bitbucketPull) []*MergeRequestDetails {
+	result := make([]*MergeRequestDetails, len(pulls))
+	for i, p := range pulls {
+		result[i] = &MergeRequestDetails{MergeRequest: p.mergeRequest()}
+	}
+	return result
+}
+
+// GetOpenMergeRequests fetches open pull requests the user authored, reviews or participates in
+func (c *BitbucketClient) GetOpenMergeRequests() ([]*MergeRequestDetails, error) {
+	var page struct {
+		Values []bitbucketPull `json:"values"`
+	}
+	if _, err := c.do("GET", "/api/1.0/dashboard/pull-requests?state=OPEN&limit=100", nil, &page); err != nil {
+		return nil, err
+	}
+	return newBitbucketPullList(page.Values), nil
+}
+
+// GetProjectMergeRequests fetches the first page of open pull requests of a repository
+func (c *BitbucketClient) GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error) {
+	mrs, _, err := c.GetProjectMergeRequestsPage(projectID, 1)
+	return mrs, err
+}
+
+// GetProjectMergeRequestsPage fetches a page of open pull requests of a repository
+func (c *BitbucketClient) GetProjectMergeRequestsPage(projectID, page int) ([]*MergeRequestDetails, listPage, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	var result struct {
+		Values     []bitbucketPull `json:"values"`
+		IsLastPage bool            `json:"isLastPage"`
+	}
+	path := fmt.Sprintf("/api/1.0%s/pull-requests?state=OPEN&limit=%d&start=%d", repo.path(), listPageSize, (page-1)*listPageSize)
+	if _, err := c.do("GET", path, nil, &result); err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	return newBitbucketPullList(result.Values), listPage{Total: -1, HasMore: !result.IsLastPage}, nil
+}
+
+// GetProjectMergeRequestsUpdatedSince is not offered: pull requests are not listed by update time
+func (c *BitbucketClient) GetProjectMergeRequestsUpdatedSince(projectID int, since time.Time) ([]*MergeRequestDetails, error) {
+	return nil, errMRSyncUnsupported
+}
+
+// LoadMergeRequestDetails fetches details for every pull request of a list that does not have them yet
+func (c *BitbucketClient) LoadMergeRequestDetails(mrs []*MergeRequestDetails) {
+	for i, mr := range mrs {
+		if mr.DetailsLoaded {
+			continue
+		}
+		if details, err := c.GetMergeRequestDetails(mr.MergeRequest); err == nil {
+			mrs[i] = details
+		}
+	}
+}
+
+// GetMergeRequestDetails fetches the changed files and commits of a pull request; its repository
+// is taken from the web URL. Line counts are not reported by the API.
+func (c *BitbucketClient) GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDetails, error) {
+	details := &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}
+
+	// URL format: https://bitbucket.example.com/projects/KEY/repos/slug/pull-requests/123
+	idx := strings.Index(mr.WebURL, "/projects/")
+	end := strings.Index(mr.WebURL, "/pull-requests/")
+	if idx == -1 || end < idx {
+		return details, nil
+	}
+	prPath := "/api/1.0" + mr.WebURL[idx:end] + fmt.Sprintf("/pull-requests/%d", mr.IID)
+
+	var page struct {
+		Size       int  `json:"size"`
+		IsLastPage bool `json:"isLastPage"`
+	}
+	if _, err := c.do("GET", prPath+"/changes?limit=1000", nil, &page); err == nil {
+		details.ChangesCount = fmt.Sprintf("%d", page.Size)
+		if !page.IsLastPage {
+			details.ChangesCount += "+"
+		}
+	}
+	if _, err := c.do("GET", prPath+"/commits?limit=1000", nil, &page); err == nil {
+		details.CommitsCount = page.Size
+	}
+	return details, nil
+}
+
+// getPull fetches a pull request of a repository by ID, filling in the merge commit of merged
+// pull requests from their activity on versions that do not report it
+func (c *BitbucketClient) getPull(projectID, number int) (*bitbucketPull, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	prPath := fmt.Sprintf("/api/1.0%s/pull-requests/%d", repo.path(), number)
+	var pull bitbucketPull
+	if _, err := c.do("GET", prPath, nil, &pull); err != nil {
+		return nil, err
+	}
+
+	if pull.State == "MERGED" && pull.Properties.MergeCommit.ID == "" {
+		var activities struct {
+			Values []struct {
+				Action string `json:"action"`
+				Commit struct {
+					ID string `json:"id"`
+				} `json:"commit"`
+			} `json:"values"`
+		}
+		if _, err := c.do("GET", prPath+"/activities?limit=50", nil, &activities); err == nil {
+			for _, a := range activities.Values {
+				if a.Action == "MERGED" {
+					pull.Properties.MergeCommit.ID = a.Commit.ID
+					break
+				}
+			}
+		}
+	}
+	return &pull, nil
+}
+
+// GetMergeRequestByIID fetches a pull request by its ID
+func (c *BitbucketClient) GetMergeRequestByIID(projectID, mrIID int) (*MergeRequestDetails, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetMergeRequestDetails(pull.mergeRequest())
+}
+
+// GetMergeRequestBySourceBranch fetches the newest pull request from a branch (including merged ones)
+func (c *BitbucketClient) GetMergeRequestBySourceBranch(projectID int, sourceBranch string) (*MergeRequestDetails, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Values []bitbucketPull `json:"values"`
+	}
+	path := "/api/1.0" + repo.path() + "/pull-requests?state=ALL&direction=OUTGOING&order=NEWEST&limit=1&at=" +
+		url.QueryEscape("refs/heads/"+sourceBranch)
+	if _, err := c.do("GET", path, nil, &page); err != nil {
+		return nil, err
+	}
+	if len(page.Values) == 0 {
+		return nil, fmt.Errorf("no MR found for branch %s", sourceBranch)
+	}
+	return c.GetMergeRequestByIID(projectID, page.Values[0].ID)
+}
+
+// GetMergeRequestStatus fetches a pull request to check if it's merged
+func (c *BitbucketClient) GetMergeRequestStatus(projectID, mrIID int) (*MergeRequest, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// CreateMergeRequest opens a pull request between two branches of the repository
+func (c *BitbucketClient) CreateMergeRequest(projectID int, sourceBranch, targetBranch, title, description string) (*MergeRequest, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	ref := func(branch string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": "refs/heads/" + branch,
+			"repository": map[string]interface{}{
+				"slug":    repo.Slug,
+				"project": map[string]string{"key": repo.Project.Key},
+			},
+		}
+	}
+	payload := map[string]interface{}{
+		"title":       title,
+		"description": description,
+		"fromRef":     ref(sourceBranch),
+		"toRef":       ref(targetBranch),
+	}
+	var pull bitbucketPull
+	if _, err := c.do("POST", "/api/1.0"+repo.path()+"/pull-requests", payload, &pull); err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// GetMergeRequestChangedFiles lists the paths of files changed by a pull request
+func (c *BitbucketClient) GetMergeRequestChangedFiles(projectID, mrIID int) ([]string, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	start := 0
+	for page := 1; page <= maxChangedFilePages; page++ {
+		var changes struct {
+			Values []struct {
+				Path struct {
+					ToString string `json:"toString"`
+				} `json:"path"`
+				SrcPath struct {
+					ToString string `json:"toString"`
+				} `json:"srcPath"`
+			} `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+		path := fmt.Sprintf("/api/1.0%s/pull-requests/%d/changes?limit=%d&start=%d", repo.path(), mrIID, listPageSize, start)
+		if _, err := c.do("GET", path, nil, &changes); err != nil {
+			return nil, err
+		}
+		for _, v := range changes.Values {
+			files = appendChangedFile(files, v.Path.ToString, v.SrcPath.ToString)
+		}
+		if changes.IsLastPage {
+			break
+		}
+		start = changes.NextPageStart
+	}
+	return files, nil
+}
+
+// CreateMergeRequestNote adds a comment to a pull request and returns its ID
+func (c *BitbucketClient) CreateMergeRequestNote(projectID, mrIID int, body string) (int, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var comment struct {
+		ID int `json:"id"`
+	}
+	_, err = c.do("POST", fmt.Sprintf("/api/1.0%s/pull-requests/%d/comments", repo.path(), mrIID), map[string]string{"text": body}, &comment)
+	return comment.ID, err
+}
+
+// UpdateMergeRequestNote replaces the text of a pull request comment. Bitbucket rejects edits
+// without the comment's current version, so it is fetched first.
+func (c *BitbucketClient) UpdateMergeRequestNote(projectID, mrIID, noteID int, body string) error {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/1.0%s/pull-requests/%d/comments/%d", repo.path(), mrIID, noteID)
+	var comment struct {
+		Version int `json:"version"`
+	}
+	if _, err := c.do("GET", path, nil, &comment); err != nil {
+		return err
+	}
+	_, err = c.do("PUT", path, map[string]interface{}{"text": body, "version": comment.Version}, nil)
+	return err
+}
+
+// ApproveMergeRequest approves a pull request as the token's user
+func (c *BitbucketClient) ApproveMergeRequest(projectID, mrIID int) error {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("POST", fmt.Sprintf("/api/1.0%s/pull-requests/%d/approve", repo.path(), mrIID), nil, nil)
+	return err
+}
+
+// RebaseMergeRequest is not offered: rebasing needs the pull request's version and a server
+// with the rebase plugin endpoint
+func (c *BitbucketClient) RebaseMergeRequest(projectID, mrIID int) error {
+	return errMRActionUnsupported
+}
+
+// SetMergeRequestDraft is not offered: Data Center versions before 8.18 have no draft pull requests
+func (c *BitbucketClient) SetMergeRequestDraft(projectID, mrIID int, title string, draft bool) (string, error) {
+	return title, errMRActionUnsupported
+}
+
+// AddMergeRequestLabels is not offered: Bitbucket pull requests have no labels
+func (c *BitbucketClient) AddMergeRequestLabels(projectID, mrIID int, labels []string) error {
+	return errMRActionUnsupported
+}
+
+// FindMilestone is not offered: Bitbucket has no milestones
+func (c *BitbucketClient) FindMilestone(projectID int, title string) (int, error) {
+	return 0, errMRActionUnsupported
+}
+
+// SetMergeRequestMilestone is not offered: Bitbucket has no milestones
+func (c *BitbucketClient) SetMergeRequestMilestone(projectID, mrIID, milestoneID int) error {
+	return errMRActionUnsupported
+}
+
+// GetMergeRequestPipelines fetches the builds of a pull request's source commit
+func (c *BitbucketClient) GetMergeRequestPipelines(projectID, mrIID int) ([]Pipeline, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPipelinesByCommit(projectID, pull.FromRef.LatestCommit)
+}
+
+// builds fetches the build statuses of a commit, one per build key (the latest wins)
+func (c *BitbucketClient) builds(sha string) ([]bitbucketBuild, error) {
+	var page struct {
+		Values []bitbucketBuild `json:"values"`
+	}
+	if _, err := c.do("GET", "/build-status/1.0/commits/"+url.PathEscape(sha)+"?limit=100", nil, &page); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]int) // Key -> index in result
+	var result []bitbucketBuild
+	for _, b := range page.Values {
+		if i, ok := latest[b.Key]; ok {
+			if b.DateAdded > result[i].DateAdded {
+				result[i] = b
+			}
+			continue
+		}
+		latest[b.Key] = len(result)
+		result = append(result, b)
+	}
+	return result, nil
+}
+
+// GetPipelinesByCommit returns the builds of a commit as one pipeline, or none if CI has not reported yet.
+// Its ID is derived from the newest report, so it changes when CI reports again.
+func (c *BitbucketClient) GetPipelinesByCommit(projectID int, sha string) ([]Pipeline, error) {
+	builds, err := c.builds(sha)
+	if err != nil || len(builds) == 0 {
+		return nil, err
+	}
+
+	var newest int64
+	status := "success"
+	for _, b := range builds {
+		newest = max(newest, b.DateAdded)
+		switch s := bitbucketStatus(b.State); {
+		case s == "failed" || s == "canceled":
+			status = "failed"
+		case s != "success" && status == "success":
+			status = "running"
+		}
+	}
+	pipelineID := c.pipelineID(projectID, sha, int(newest%(1<<31)))
+
+	pipeline := Pipeline{ID: pipelineID, Status: status}
+	if repo, err := c.repo(projectID); err == nil && len(repo.Links.Self) > 0 {
+		pipeline.WebURL = strings.TrimSuffix(repo.Links.Self[0].Href, "/browse") + "/commits/" + sha
+	}
+	return []Pipeline{pipeline}, nil
+}
+
+// loadPipelines reads the remembered pipelines of the instance; the caller holds bitbucketPipelines
+func (c *BitbucketClient) loadPipelines() {
+	if bitbucketPipelines.loaded && bitbucketPipelines.baseURL == c.baseURL {
+		return
+	}
+	bitbucketPipelines.loaded, bitbucketPipelines.baseURL = true, c.baseURL
+	bitbucketPipelines.entries = make(map[int]bitbucketPipeline)
+	loadStaleCache(bitbucketPipelinesCacheName, c.baseURL, &bitbucketPipelines.entries)
+}
+
+// pipelineID returns the pipeline ID of a commit's builds, starting from the one derived from their
+// newest report. An ID already taken by another commit is moved on to the next free one.
+func (c *BitbucketClient) pipelineID(projectID int, sha string, derived int) int {
+	bitbucketPipelines.Lock()
+	defer bitbucketPipelines.Unlock()
+	c.loadPipelines()
+
+	entries := bitbucketPipelines.entries
+	id := derived
+	for {
+		entry, ok := entries[id]
+		if !ok {
+			break
+		}
+		if entry.ProjectID == projectID && entry.SHA == sha {
+			entry.SeenAt = time.Now() // Saved with the next new pipeline; polling writes nothing
+			entries[id] = entry
+			return id
+		}
+		id = (id + 1) % (1 << 31)
+	}
+	entries[id] = bitbucketPipeline{ProjectID: projectID, SHA: sha, SeenAt: time.Now()}
+	if len(entries) > bitbucketPipelinesKept {
+		ids := slices.SortedFunc(maps.Keys(entries), func(a, b int) int {
+			return entries[b].SeenAt.Compare(entries[a].SeenAt)
+		})
+		for _, old := range ids[bitbucketPipelinesKept:] {
+			delete(entries, old)
+		}
+	}
+	_ = saveCache(bitbucketPipelinesCacheName, c.baseURL, entries) // Failing to save only loses the IDs on restart
+	return id
+}
+
+// pipelineSHA returns the commit of a pipeline ID made up by pipelineID
+func (c *BitbucketClient) pipelineSHA(projectID, pipelineID int) (string, bool) {
+	bitbucketPipelines.Lock()
+	defer bitbucketPipelines.Unlock()
+	c.loadPipelines()
+
+	entry, ok := bitbucketPipelines.entries[pipelineID]
+	if !ok || entry.ProjectID != projectID {
+		return "", false
+	}
+	return entry.SHA, true
+}
+
+// GetRunningPipelines is not supported: builds are reported per commit, not listed per repository
+func (c *BitbucketClient) GetRunningPipelines(projectID int) ([]Pipeline, error) {
+	return nil, errRunningPipelinesUnsupported
+}
+
+// GetPipelineJobs returns the builds of the pipeline's commit as jobs
+func (c *BitbucketClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error) {
+	sha, ok := c.pipelineSHA(projectID, pipelineID)
+	if !ok {
+		return nil, fmt.Errorf("unknown pipeline %d", pipelineID)
+	}
+	builds, err := c.builds(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]PipelineJob, len(builds))
+	for i, b := range builds {
+		name := b.Name
+		if name == "" {
+			name = b.Key
+		}
+		jobs[i] = PipelineJob{ID: i + 1, Name: name, Status: bitbucketStatus(b.State), WebURL: b.URL}
+	}
+	return jobs, nil
+}
+
+// GetRepositoryFile returns a file of the repository's default branch, nil if it does not exist
+func (c *BitbucketClient) GetRepositoryFile(projectID int, path string) ([]byte, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/rest/api/1.0%s/raw/%s", c.baseURL, repo.path(), escapeFilePath(path))
+	return getRawFile(c.client, url, map[string]string{"Authorization": "Bearer " + c.token}, "Bitbucket")
+}
+
+// GetTags returns the tags of a repository whose name starts with prefix, newest first
+func (c *BitbucketClient) GetTags(projectID int, prefix string) ([]Tag, error) {
+	repo, err := c.repo(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	start := 0
+	for page := 1; page <= maxTagPages; page++ {
+		var result struct {
+			Values []struct {
+				DisplayID    string `json:"displayId"`
+				LatestCommit string `json:"latestCommit"`
+			} `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+		path := fmt.Sprintf("/api/1.0%s/tags?orderBy=MODIFICATION&filterText=%s&limit=%d&start=%d", repo.path(), url.QueryEscape(prefix), listPageSize, start)
+		if _, err := c.do("GET", path, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, t := range result.Values {
+			if strings.HasPrefix(t.DisplayID, prefix) { // filterText matches anywhere in the name
+				tags = append(tags, Tag{Name: t.DisplayID, Commit: t.LatestCommit})
+			}
+		}
+		if result.IsLastPage {
+			break
+		}
+		start = result.NextPageStart
+	}
+	return tags, nil
+}
+
+// CreateRelease is not supported: Bitbucket Data Center has no releases
+func (c *BitbucketClient) CreateRelease(projectID int, tag, name, description string) (string, error) {
+	return "", errReleasesUnsupported
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Bulk labels and milestones: "t" adds a label to, or sets a milestone of, several MRs at once:
+// on the MR list the selected MRs (the highlighted one if none is selected), on the history
+// detail screen the MRs of the release, with "released-{version}" suggested, e.g. to tag what
+// shipped. MRs are updated a few at a time; the modal shows the progress and the error of every
+// MR that failed, and "r" retries those.
+
+// bulkEditBatch is the number of MRs updated at once
+const bulkEditBatch = 4
+
+// bulkEditResult is the outcome of updating one MR
+type bulkEditResult struct {
+	iid int
+	err error
+}
+
+// bulkEditBatchMsg reports a batch of updated MRs
+type bulkEditBatchMsg struct {
+	run         int
+	milestoneID int // Milestone found before the first batch
+	results     []bulkEditResult
+	err         error // The run failed as a whole, e.g. the milestone does not exist
+}
+
+// openBulkEdit opens the bulk edit modal for MRs of the selected project, with label suggested
+func (m *model) openBulkEdit(iids []int, label string) tea.Cmd {
+	if len(iids) == 0 || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	m.closeAllModals()
+	if _, ok := NewForge(*m.creds).(*BitbucketClient); ok {
+		m.showErrorModal = true
+		m.errorModalMsg = "Bitbucket pull requests have no labels or milestones"
+		return nil
+	}
+	ti := textinput.New()
+	ti.Placeholder = "label"
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	ti.CharLimit = 255
+	ti.Width = 40
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.SetValue(label)
+	m.bulkEditInput = ti
+	m.bulkEditIIDs = iids
+	m.bulkEditTotal = len(iids)
+	m.bulkEditMilestone = false
+	m.bulkEditRun++
+	m.bulkEditRunning = false
+	m.bulkEditStarted = false
+	m.bulkEditResults = nil
+	m.bulkEditError = ""
+	m.showBulkEdit = true
+	return m.bulkEditInput.Focus()
+}
+
+// bulkEditListMRs opens the bulk edit modal for the selected MRs of the list, or the highlighted
+// one if none is selected
+func (m *model) bulkEditListMRs() tea.Cmd {
+	var iids []int
+	for iid, selected := range m.selectedMRs {
+		if selected {
+			iids = append(iids, iid)
+		}
+	}
+	slices.Sort(iids)
+	if len(iids) == 0 {
+		if item, ok := m.list.SelectedItem().(mrListItem); ok && item.MR() != nil {
+			iids = []int{item.MR().IID}
+		}
+	}
+	return m.openBulkEdit(iids, "")
+}
+
+// bulkEditReleaseMRs opens the bulk edit modal for the MRs of the release shown in the history
+// detail screen
+func (m *model) bulkEditReleaseMRs() tea.Cmd {
+	entry := m.historySelected
+	if entry == nil {
+		return nil
+	}
+	if len(entry.MRIIDs) == 0 {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "This release has no MR numbers saved; edit its MRs from the MR list"
+		return nil
+	}
+	return m.openBulkEdit(slices.Clone(entry.MRIIDs), "released-"+entry.Version)
+}
+
+// updateBulkEdit handles keys of the bulk edit modal
+func (m model) updateBulkEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.bulkEditRunning {
+		return m, nil // Results are shown once the run ends
+	}
+	if m.bulkEditStarted {
+		switch msg.String() {
+		case "r":
+			var failed []int
+			for _, result := range m.bulkEditResults {
+				if result.err != nil {
+					failed = append(failed, result.iid)
+				}
+			}
+			if len(failed) > 0 {
+				m.bulkEditIIDs = failed
+				return m.startBulkEdit()
+			}
+		case "enter", "esc", "q", "ctrl+q":
+			m.showBulkEdit = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "ctrl+q":
+		m.showBulkEdit = false
+		return m, nil
+	case "tab", "shift+tab":
+		m.bulkEditMilestone = !m.bulkEditMilestone
+		m.bulkEditInput.Placeholder = "label"
+		if m.bulkEditMilestone {
+			m.bulkEditInput.Placeholder = "milestone title"
+		}
+		m.bulkEditError = ""
+		return m, nil
+	case "enter":
+		if strings.TrimSpace(m.bulkEditInput.Value()) == "" {
+			m.bulkEditError = "Enter a label or a milestone title"
+			return m, nil
+		}
+		return m.startBulkEdit()
+	}
+	var cmd tea.Cmd
+	m.bulkEditInput, cmd = m.bulkEditInput.Update(msg)
+	m.bulkEditError = ""
+	return m, cmd
+}
+
+// startBulkEdit starts updating the MRs of the modal
+func (m model) startBulkEdit() (tea.Model, tea.Cmd) {
+	if m.creds == nil || m.selectedProject == nil {
+		return m, nil
+	}
+	m.bulkEditRun++
+	m.bulkEditRunning = true
+	m.bulkEditStarted = true
+	m.bulkEditError = ""
+	// Earlier results are kept for the MRs not retried
+	for _, iid := range m.bulkEditIIDs {
+		m.bulkEditResults = slices.DeleteFunc(m.bulkEditResults, func(r bulkEditResult) bool { return r.iid == iid })
+	}
+	m.bulkEditMilestoneID = 0
+	m.bulkEditPending = slices.Clone(m.bulkEditIIDs)
+	return m, tea.Batch(m.spinner.Tick, m.nextBulkEditBatch())
+}
+
+// nextBulkEditBatch updates the next few pending MRs concurrently
+func (m *model) nextBulkEditBatch() tea.Cmd {
+	n := min(bulkEditBatch, len(m.bulkEditPending))
+	batch := slices.Clone(m.bulkEditPending[:n])
+	m.bulkEditPending = m.bulkEditPending[n:]
+
+	client := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	value := strings.TrimSpace(m.bulkEditInput.Value())
+	milestone, milestoneID, run := m.bulkEditMilestone, m.bulkEditMilestoneID, m.bulkEditRun
+	return func() tea.Msg {
+		msg := bulkEditBatchMsg{run: run, milestoneID: milestoneID}
+		if milestone && milestoneID == 0 {
+			if msg.milestoneID, msg.err = client.FindMilestone(projectID, value); msg.err != nil {
+				return msg
+			}
+		}
+
+		results := make(chan bulkEditResult, len(batch))
+		for _, iid := range batch {
+			go func() {
+				target := fmt.Sprintf("project %d !%d", projectID, iid)
+				var err error
+				if milestone {
+					err = client.SetMergeRequestMilestone(projectID, iid, msg.milestoneID)
+					recordAudit(auditMRMilestone, target, value, err)
+				} else {
+					err = client.AddMergeRequestLabels(projectID, iid, []string{value})
+					recordAudit(auditMRLabel, target, value, err)
+				}
+				results <- bulkEditResult{iid: iid, err: err}
+			}()
+		}
+		for range batch {
+			msg.results = append(msg.results, <-results)
+		}
+		return msg
+	}
+}
+
+// handleBulkEditBatch records a batch of updated MRs and starts the next one
+func (m *model) handleBulkEditBatch(msg bulkEditBatchMsg) tea.Cmd {
+	if msg.run != m.bulkEditRun || !m.bulkEditRunning {
+		return nil
+	}
+	if msg.err != nil {
+		m.bulkEditRunning = false
+		m.bulkEditStarted = false // Back to the input, e.g. to fix the milestone title
+		m.bulkEditError = msg.err.Error()
+		return m.bulkEditInput.Focus()
+	}
+	m.bulkEditMilestoneID = msg.milestoneID
+	m.bulkEditResults = append(m.bulkEditResults, msg.results...)
+	slices.SortFunc(m.bulkEditResults, func(a, b bulkEditResult) int { return a.iid - b.iid })
+	if len(m.bulkEditPending) > 0 {
+		return m.nextBulkEditBatch()
+	}
+
+	m.bulkEditRunning = false
+	failed := 0
+	for _, result := range m.bulkEditResults {
+		if result.err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return m.showToast(fmt.Sprintf("Updated %d MRs", len(m.bulkEditResults)))
+	}
+	return nil
+}
+
+// overlayBulkEdit renders the bulk edit modal
+func (m model) overlayBulkEdit(background string) string {
+	var sb strings.Builder
+	what := "Add a label to"
+	if m.bulkEditMilestone {
+		what = "Set the milestone of"
+	}
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render(fmt.Sprintf("%s %d MRs", what, m.bulkEditTotal)))
+	sb.WriteString("\n\n")
+
+	if !m.bulkEditStarted {
+		label, milestone := buttonActiveStyle.Render("Label"), buttonStyle.Render("Milestone")
+		if m.bulkEditMilestone {
+			label, milestone = buttonStyle.Render("Label"), buttonActiveStyle.Render("Milestone")
+		}
+		sb.WriteString(label + "  " + milestone + "\n\n")
+		sb.WriteString(m.bulkEditInput.View())
+		if m.bulkEditError != "" {
+			sb.WriteString("\n\n")
+			sb.WriteString(settingsErrorStyle.Render(m.bulkEditError))
+		}
+		sb.WriteString("\n\n")
+		sb.WriteString(helpStyle.Render("enter: apply • tab: label/milestone • esc: cancel"))
+	} else {
+		value := strings.TrimSpace(m.bulkEditInput.Value())
+		failed := 0
+		for _, result := range m.bulkEditResults {
+			if result.err != nil {
+				failed++
+			}
+		}
+		if m.bulkEditRunning {
+			fmt.Fprintf(&sb, "%s %s %d/%d\n\n", m.spinner.View(), helpStyle.Render(value), len(m.bulkEditResults), m.bulkEditTotal)
+		} else {
+			fmt.Fprintf(&sb, "%s: %d updated, %d failed\n\n", value, len(m.bulkEditResults)-failed, failed)
+		}
+		ok := lipgloss.NewStyle().Foreground(currentTheme.Success)
+		bad := lipgloss.NewStyle().Foreground(currentTheme.Error)
+		for _, result := range m.bulkEditResults {
+			if result.err != nil {
+				sb.WriteString(bad.Render(truncateWithEllipsis(fmt.Sprintf("✗ !%d %v", result.iid, result.err), 64)) + "\n")
+			} else {
+				sb.WriteString(ok.Render(fmt.Sprintf("✓ !%d", result.iid)) + "\n")
+			}
+		}
+		sb.WriteString("\n")
+		switch {
+		case m.bulkEditRunning:
+			sb.WriteString(helpStyle.Render("Working…"))
+		case failed > 0:
+			sb.WriteString(helpStyle.Render("r: retry failed • enter: close"))
+		default:
+			sb.WriteString(helpStyle.Render("enter: close"))
+		}
+	}
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 70, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 80,
+		Style:    commandMenuStyle,
+	}
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cacheDirName = "cache"
+	cacheTTL     = 24 * time.Hour // Older cache entries are not shown
+)
+
+// cacheFile is the on-disk format of a cached GitLab response
+type cacheFile struct {
+	GitLabURL string          `json:"gitlab_url"` // Instance the data came from; other instances ignore it
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// getCachePath returns the path of a cache file, creating the cache directory if needed
+func getCachePath(name string) (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, cacheDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// projectsCacheName is the cache file of the project list shown with the query
+func projectsCacheName(query projectQuery) string {
+	name := "projects"
+	if query.Scope != projectScopeAll {
+		name += "-" + query.Scope
+	}
+	if query.Archived {
+		name += "-archived"
+	}
+	return name + ".json"
+}
+
+// mrsCacheName is the cache file of a project's open MRs
+func mrsCacheName(projectID int) string {
+	return fmt.Sprintf("mrs-%d.json", projectID)
+}
+
+// loadCache decodes a cache entry into v. It returns false if the entry is missing,
+// expired, unreadable or from another GitLab instance.
+func loadCache(name, gitlabURL string, v interface{}) bool {
+	fetchedAt, ok := loadStaleCache(name, gitlabURL, v)
+	return ok && time.Since(fetchedAt) <= cacheTTL
+}
+
+// loadStaleCache decodes a cache entry into v however old it is, for offline mode, and returns
+// when it was fetched. It returns false if the entry is missing, unreadable or from another GitLab instance.
+func loadStaleCache(name, gitlabURL string, v interface{}) (time.Time, bool) {
+	path, err := getCachePath(name)
+	if err != nil {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.GitLabURL != gitlabURL {
+		return time.Time{}, false
+	}
+	if json.Unmarshal(file.Data, v) != nil {
+		return time.Time{}, false
+	}
+	return file.FetchedAt, true
+}
+
+// saveCache stores v as a cache entry
+func saveCache(name, gitlabURL string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	file, err := json.Marshal(cacheFile{GitLabURL: gitlabURL, FetchedAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	path, err := getCachePath(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, file, 0o600)
+}
+
+// clearCache removes all cached GitLab data (on logout)
+func clearCache() error {
+	dir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dir, cacheDirName))
+}
+
+// loadCachedProjects returns the cached project list
+func loadCachedProjects(gitlabURL string, query projectQuery) ([]Project, bool) {
+	var projects []Project
+	if !loadCache(projectsCacheName(query), gitlabURL, &projects) {
+		return nil, false
+	}
+	return projects, true
+}
+
+// loadCachedMRs returns the cached open MRs of a project. Their details are loaded lazily, as for fetched lists.
+func loadCachedMRs(gitlabURL string, projectID int) ([]*MergeRequestDetails, bool) {
+	var mrs []*MergeRequestDetails
+	if !loadCache(mrsCacheName(projectID), gitlabURL, &mrs) {
+		return nil, false
+	}
+	return mrs, true
+}
+
+// saveCachedMRs stores the open MRs of a project
+func saveCachedMRs(gitlabURL string, projectID int, mrs []*MergeRequestDetails) error {
+	return saveCache(mrsCacheName(projectID), gitlabURL, mrs)
+}
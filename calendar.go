@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCalendarWeeks is how far ahead release windows are listed in the calendar feed
+const defaultCalendarWeeks = 8
+
+// releaseWindowDays maps the weekday names accepted in release windows
+var releaseWindowDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// upcomingRelease is one occurrence of a release window
+type upcomingRelease struct {
+	Environment string
+	Title       string
+	Start       time.Time
+	End         time.Time
+}
+
+// validate checks the days, time and duration of a release window
+func (w ReleaseWindow) validate() error {
+	if w.Environment == "" {
+		return fmt.Errorf("release window without environment")
+	}
+	if len(w.Days) == 0 {
+		return fmt.Errorf("release window %s: no days", w.Environment)
+	}
+	for _, day := range w.Days {
+		if _, ok := releaseWindowDays[strings.ToLower(day)[:min(3, len(day))]]; !ok {
+			return fmt.Errorf("release window %s: unknown day %q", w.Environment, day)
+		}
+	}
+	if _, err := time.Parse("15:04", w.Time); err != nil {
+		return fmt.Errorf("release window %s: invalid time %q (expected HH:MM)", w.Environment, w.Time)
+	}
+	if _, err := w.duration(); err != nil {
+		return fmt.Errorf("release window %s: invalid duration %q", w.Environment, w.Duration)
+	}
+	return nil
+}
+
+// duration returns the length of the window, one hour by default
+func (w ReleaseWindow) duration() (time.Duration, error) {
+	if w.Duration == "" {
+		return time.Hour, nil
+	}
+	return time.ParseDuration(w.Duration)
+}
+
+// title returns the event title of the window
+func (w ReleaseWindow) title() string {
+	if w.Title != "" {
+		return w.Title
+	}
+	return strings.ToUpper(w.Environment) + " release window"
+}
+
+// occurrences returns the windows that end after from and start before until, in local time
+func (w ReleaseWindow) occurrences(from, until time.Time) []upcomingRelease {
+	if w.validate() != nil {
+		return nil
+	}
+	clock, _ := time.Parse("15:04", w.Time)
+	length, _ := w.duration()
+	days := map[time.Weekday]bool{}
+	for _, day := range w.Days {
+		days[releaseWindowDays[strings.ToLower(day)[:3]]] = true
+	}
+
+	var result []upcomingRelease
+	first := from.Add(-length)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.Local)
+	for ; day.Before(until); day = day.AddDate(0, 0, 1) {
+		if !days[day.Weekday()] {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+		end := start.Add(length)
+		if end.After(from) && start.Before(until) {
+			result = append(result, upcomingRelease{Environment: w.Environment, Title: w.title(), Start: start, End: end})
+		}
+	}
+	return result
+}
+
+// upcomingReleases returns the next release windows from now, soonest first
+func upcomingReleases(windows []ReleaseWindow, now time.Time, weeks, limit int) []upcomingRelease {
+	var result []upcomingRelease
+	until := now.AddDate(0, 0, 7*weeks)
+	for _, w := range windows {
+		result = append(result, w.occurrences(now, until)...)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// writeReleaseCalendar writes an iCalendar feed with the release windows of the next weeks and
+// the given releases from history
+func writeReleaseCalendar(w io.Writer, windows []ReleaseWindow, history []HistoryIndexEntry, now time.Time, weeks int) error {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString(foldICSLine(fmt.Sprintf(format, args...)))
+	}
+	stamp := icsTime(now)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//relix//release calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Releases")
+
+	for _, r := range upcomingReleases(windows, now, weeks, 0) {
+		line("BEGIN:VEVENT")
+		line("UID:window-%s-%d@relix", strings.ToLower(r.Environment), r.Start.Unix())
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART:%s", icsTime(r.Start))
+		line("DTEND:%s", icsTime(r.End))
+		line("SUMMARY:%s", escapeICSText(r.Title))
+		line("CATEGORIES:%s", escapeICSText(strings.ToUpper(r.Environment)))
+		line("TRANSP:OPAQUE")
+		line("END:VEVENT")
+	}
+
+	for _, e := range history {
+		summary := fmt.Sprintf("%s release %s", strings.ToUpper(e.Environment), e.Version)
+		if e.Status != "completed" {
+			summary += " (" + e.Status + ")"
+		}
+		line("BEGIN:VEVENT")
+		line("UID:release-%s@relix", e.ID)
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART:%s", icsTime(e.DateTime))
+		line("SUMMARY:%s", escapeICSText(summary))
+		line("DESCRIPTION:%s", escapeICSText(fmt.Sprintf("Tag: %s\nMerge requests: %d\nStatus: %s", e.Tag, e.MRCount, e.Status)))
+		line("CATEGORIES:%s", escapeICSText(strings.ToUpper(e.Environment)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsTime formats a time in UTC as an iCalendar DATE-TIME
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText escapes an iCalendar TEXT value
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine ends a content line with CRLF, folding it into 75-octet lines as RFC 5545 requires
+func foldICSLine(s string) string {
+	var b strings.Builder
+	for len(s) > 75 {
+		cut := 75
+		for cut > 0 && s[cut]&0xC0 == 0x80 { // Do not split UTF-8 sequences
+			cut--
+		}
+		b.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+	}
+	b.WriteString(s + "\r\n")
+	return b.String()
+}
+
+// handleCalendar serves the release calendar as an iCalendar feed. Calendar apps cannot send
+// headers, so subscriptions pass the token as access_token.
+func (s *apiServer) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	config, err := LoadConfig()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	weeks := defaultCalendarWeeks
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		if weeks, err = strconv.Atoi(v); err != nil || weeks < 0 {
+			writeAPIError(w, http.StatusBadRequest, "invalid weeks")
+			return
+		}
+	}
+	history, err := LoadHistoryIndex()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	writeReleaseCalendar(w, config.ReleaseWindows, history, time.Now(), weeks)
+}
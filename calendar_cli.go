@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// calendarCommand exports release windows and past releases as an iCalendar file
+var calendarCommand = &cliCommand{
+	Name:        "calendar",
+	Summary:     "Export release windows and past releases as an iCalendar (.ics) file",
+	Description: "Writes the release windows of the coming weeks (release_windows in the config) and the releases from history as calendar events, so they can be imported into team calendars. \"relix serve\" serves the same feed at /api/calendar.ics for calendar subscriptions.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix calendar > releases.ics",
+		"relix calendar --env prod --weeks 4 --output prod.ics",
+		"relix calendar --since 2026-01-01 --status completed",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		filter := registerHistoryFilterFlags(fs)
+		weeks := fs.Int("weeks", defaultCalendarWeeks, "List release windows of the next `n` weeks")
+		output := fs.String("output", "", "Write to `file` instead of stdout")
+		return func(args []string) error {
+			if len(args) > 0 || *weeks < 0 {
+				return errCLIUsage
+			}
+			config, err := LoadConfig()
+			if err != nil {
+				return err
+			}
+			history, err := loadFilteredHistory(filter)
+			if err != nil {
+				return err
+			}
+
+			var windows []ReleaseWindow
+			for _, w := range config.ReleaseWindows {
+				if err := w.validate(); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %v\n", err)
+					continue
+				}
+				if *filter.env != "" && !strings.EqualFold(w.Environment, *filter.env) {
+					continue
+				}
+				windows = append(windows, w)
+			}
+
+			w := io.Writer(os.Stdout)
+			if *output != "" {
+				f, err := os.Create(*output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return writeReleaseCalendar(w, windows, history, time.Now(), *weeks)
+		}
+	},
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cliCommand describes a non-interactive subcommand (e.g. "relix history list")
+type cliCommand struct {
+	Name        string
+	Summary     string      // One-line description shown in command lists
+	Description string      // Longer explanation for --help and man pages (optional)
+	Usage       string      // Argument synopsis without the command path (e.g. "[options] <id>")
+	Examples    []string    // Full example invocations
+	Env         []cliEnvVar // Environment variables the command reads
+	Sub         []*cliCommand
+
+	// Setup registers the command's flags and returns the action to run after parsing.
+	// Commands with subcommands leave it nil.
+	Setup func(fs *flag.FlagSet) func(args []string) error
+}
+
+// cliEnvVar documents an environment variable read by a command
+type cliEnvVar struct {
+	Name        string
+	Description string
+}
+
+// cliOption is a flag of a command, prepared for help and man page output
+type cliOption struct {
+	Name        string // Flag name without dashes
+	Arg         string // Argument placeholder, empty for boolean flags
+	Description string // Usage text including the default value
+}
+
+// errCLIUsage signals that the command was invoked incorrectly; usage is printed instead of the error
+var errCLIUsage = errors.New("invalid usage")
+
+// cliCommands is the registry of top-level subcommands
+var cliCommands = []*cliCommand{
+	historyCommand,
+	bisectCommand,
+	auditCommand,
+	calendarCommand,
+	pluginsCommand,
+	releaseCommand,
+	serveCommand,
+	spectateCommand,
+	terminalCommand,
+}
+
+// findCLICommand returns the top-level subcommand with the given name, or nil
+func findCLICommand(name string) *cliCommand {
+	for _, c := range cliCommands {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// runCLI executes a subcommand with the given arguments and returns the process exit code
+func runCLI(cmd *cliCommand, path string, args []string) int {
+	path = path + " " + cmd.Name
+
+	if len(cmd.Sub) > 0 {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			printCLIUsage(os.Stdout, cmd, path)
+			if len(args) == 0 {
+				return 2
+			}
+			return 0
+		}
+		for _, sub := range cmd.Sub {
+			if sub.Name == args[0] {
+				return runCLI(sub, path, args[1:])
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q for %s\n\n", args[0], path)
+		printCLIUsage(os.Stderr, cmd, path)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run := cmd.Setup(fs)
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printCLIUsage(os.Stdout, cmd, path)
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		printCLIUsage(os.Stderr, cmd, path)
+		return 2
+	}
+
+	if err := run(fs.Args()); err != nil {
+		if errors.Is(err, errCLIUsage) {
+			printCLIUsage(os.Stderr, cmd, path)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printCLIUsage prints usage, subcommands, options, environment and examples for a command
+func printCLIUsage(w io.Writer, cmd *cliCommand, path string) {
+	fmt.Fprintf(w, "%s\n\n", cmd.Summary)
+	if cmd.Description != "" {
+		fmt.Fprintf(w, "%s\n\n", strings.Join(wrapText(cmd.Description, 80), "\n"))
+	}
+
+	if len(cmd.Sub) > 0 {
+		fmt.Fprintf(w, "Usage: %s <command> [options]\n\n", path)
+		fmt.Fprintf(w, "Commands:\n")
+		for _, sub := range cmd.Sub {
+			fmt.Fprintf(w, "  %-10s %s\n", sub.Name, sub.Summary)
+		}
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for details.\n", path)
+		return
+	}
+
+	usage := path
+	if cmd.Usage != "" {
+		usage += " " + cmd.Usage
+	}
+	fmt.Fprintf(w, "Usage: %s\n", usage)
+
+	if options := cliCommandOptions(cmd); len(options) > 0 {
+		fmt.Fprintf(w, "\nOptions:\n")
+		for _, opt := range options {
+			flagText := "--" + opt.Name
+			if opt.Arg != "" {
+				flagText += " <" + opt.Arg + ">"
+			}
+			fmt.Fprintf(w, "  %-28s %s\n", flagText, opt.Description)
+		}
+	}
+
+	if len(cmd.Env) > 0 {
+		fmt.Fprintf(w, "\nEnvironment:\n")
+		for _, env := range cmd.Env {
+			fmt.Fprintf(w, "  %-28s %s\n", env.Name, env.Description)
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintf(w, "\nExamples:\n")
+		for _, ex := range cmd.Examples {
+			fmt.Fprintf(w, "  %s\n", ex)
+		}
+	}
+}
+
+// cliCommandOptions returns the flags a leaf command registers, in alphabetical order
+func cliCommandOptions(cmd *cliCommand) []cliOption {
+	if cmd.Setup == nil {
+		return nil
+	}
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	cmd.Setup(fs)
+
+	var options []cliOption
+	fs.VisitAll(func(f *flag.Flag) {
+		arg, desc := flag.UnquoteUsage(f)
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			if _, err := strconv.ParseFloat(f.DefValue, 64); err == nil || f.DefValue == "true" {
+				desc += fmt.Sprintf(" (default %s)", f.DefValue)
+			} else {
+				desc += fmt.Sprintf(" (default %q)", f.DefValue)
+			}
+		}
+		options = append(options, cliOption{Name: f.Name, Arg: arg, Description: desc})
+	})
+	return options
+}
+
+// loadCLISession loads GitLab credentials and resolves the project ID,
+// falling back to the project selected in the TUI when projectID is 0
+func loadCLISession(projectID int) (*Credentials, int, error) {
+	creds, err := LoadCredentials()
+	if err != nil {
+		return nil, 0, fmt.Errorf("no GitLab credentials, run relix to log in first: %w", err)
+	}
+
+	if projectID == 0 {
+		if config, err := LoadConfig(); err == nil {
+			projectID = config.SelectedProjectID
+		}
+	}
+	if projectID == 0 {
+		return nil, 0, errors.New("no project selected (use --project or select one in the TUI)")
+	}
+	return creds, projectID, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Command history: the command lines run from the command menu, e.g. "set theme nord" or
+// "open !42", are saved most recent first in ~/.relix/command_history.json and offered at the top
+// of the menu, so a command used again is one keystroke away.
+
+const commandHistoryFileName = "command_history.json"
+
+// commandHistoryLimit is the number of command lines kept
+const commandHistoryLimit = 20
+
+// commandHistoryShown is the number of recent command lines listed while nothing is typed
+const commandHistoryShown = 5
+
+// getCommandHistoryPath returns the path of the command history file
+func getCommandHistoryPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, commandHistoryFileName), nil
+}
+
+// loadCommandHistory returns the saved command lines, most recent first
+func loadCommandHistory() []string {
+	path, err := getCommandHistoryPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil
+	}
+	return lines
+}
+
+// addCommandHistory moves the command line to the top of the history and returns the history
+func addCommandHistory(history []string, line string) []string {
+	history = slices.DeleteFunc(slices.Clone(history), func(l string) bool { return l == line })
+	history = append([]string{line}, history...)
+	if len(history) > commandHistoryLimit {
+		history = history[:commandHistoryLimit]
+	}
+
+	if path, err := getCommandHistoryPath(); err == nil {
+		if data, err := json.MarshalIndent(history, "", "  "); err == nil {
+			os.WriteFile(path, data, 0o644)
+		}
+	}
+	return history
+}
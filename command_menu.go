@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,113 +11,370 @@ import (
 
 // commandItem represents a command in the menu
 type commandItem struct {
-	name string
-	desc string
+	name    string
+	desc    string
+	arg     string                 // Placeholder of the argument, e.g. "<query>"; empty for commands without one
+	suggest func(m model) []string // Argument values offered while it is typed, may be nil
+	recent  bool                   // A command line from the history, run as is
 }
 
+// commandMenuVisible is the number of entries the command menu shows at once
+const commandMenuVisible = 8
+
 // commands is the list of available commands
 var commands = []commandItem{
 	{name: "project", desc: "Select GitLab project to filter MRs"},
+	{name: "goto project", arg: "<query>", desc: "Switch to the project matching the query", suggest: projectSuggestions},
+	{name: "open", arg: "!<iid>", desc: "Open a merge request of the selected project in the browser"},
+	{name: "set theme", arg: "<name>", desc: "Switch to another theme", suggest: themeSuggestions},
+	{name: "workspace", arg: "<name>", desc: "Switch workspace: projects, environments, notifications and theme", suggest: workspaceSuggestions},
+	{name: "tab", desc: "Open a release tab for another release (Alt+N; Alt+1…9 switch tabs)"},
+	{name: "close tab", desc: "Close the current release tab (Alt+W)"},
+	{name: "shell", desc: "Open a shell in the release working copy (! on the release screen; Ctrl+Z suspends)"},
+	{name: "screenshot", desc: "Save the current screen to a file (ANSI, HTML or SVG, see screenshot_format)"},
 	{name: "settings", desc: "Configure application settings"},
 	{name: "logout", desc: "Clear your current gitlab credentials to auth again"},
 }
 
+// menuCommands returns the built-in commands followed by the custom ones and those added by plugins
+func menuCommands() []commandItem {
+	items := append(append([]commandItem{}, commands...), customCommandItems()...)
+	return append(items, pluginCommandItems()...)
+}
+
+// commandMenuItems returns the menu entries for the typed command line: recent command lines and
+// all commands while nothing is typed, the argument values of a command whose name is typed in
+// full, and the commands and recent lines matching the text otherwise
+func (m model) commandMenuItems() []commandItem {
+	input := m.commandInput
+	if strings.TrimSpace(input) == "" {
+		var items []commandItem
+		for _, line := range m.commandHistory[:min(len(m.commandHistory), commandHistoryShown)] {
+			items = append(items, commandItem{name: line, desc: "recent", recent: true})
+		}
+		return append(items, menuCommands()...)
+	}
+
+	for _, cmd := range menuCommands() {
+		arg, ok := strings.CutPrefix(input, cmd.name+" ")
+		if cmd.arg == "" || !ok {
+			continue
+		}
+		var items []commandItem
+		if cmd.suggest != nil {
+			values := cmd.suggest(m)
+			if arg = strings.TrimSpace(arg); arg == "" {
+				for _, value := range values {
+					items = append(items, commandItem{name: cmd.name + " " + value})
+				}
+			}
+			for _, rank := range fuzzyFilter(arg, values) {
+				items = append(items, commandItem{name: cmd.name + " " + values[rank.Index]})
+			}
+		}
+		return items
+	}
+
+	var items []commandItem
+	for _, line := range m.commandHistory {
+		if strings.Contains(line, input) {
+			items = append(items, commandItem{name: line, desc: "recent", recent: true})
+		}
+	}
+	all := menuCommands()
+	names := make([]string, len(all))
+	for i, cmd := range all {
+		names[i] = cmd.name
+	}
+	for _, rank := range fuzzyFilter(input, names) {
+		items = append(items, all[rank.Index])
+	}
+	return items
+}
+
 // updateCommandMenu handles key events when command menu is open
 func (m model) updateCommandMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "ctrl+q", "q", "esc":
+	case "ctrl+q", "esc":
 		m.showCommandMenu = false
 		return m, nil
 
-	case "up", "k":
+	case "up", "ctrl+p":
 		if m.commandMenuIndex > 0 {
 			m.commandMenuIndex--
 		}
 		return m, nil
 
-	case "down", "j":
-		if m.commandMenuIndex < len(commands)-1 {
+	case "down", "ctrl+n":
+		if m.commandMenuIndex < len(m.commandMenuItems())-1 {
 			m.commandMenuIndex++
 		}
 		return m, nil
 
+	case "tab":
+		// Complete the command line with the highlighted entry
+		items := m.commandMenuItems()
+		if m.commandMenuIndex < len(items) {
+			m.commandInput = items[m.commandMenuIndex].name
+			if items[m.commandMenuIndex].arg != "" {
+				m.commandInput += " "
+			}
+			m.commandMenuIndex = 0
+		}
+		return m, nil
+
 	case "enter":
-		return m.executeCommand(commands[m.commandMenuIndex].name)
+		items := m.commandMenuItems()
+		if m.commandMenuIndex < len(items) {
+			item := items[m.commandMenuIndex]
+			if item.arg != "" {
+				// Prompt for the argument inline
+				m.commandInput = item.name + " "
+				m.commandMenuIndex = 0
+				return m, nil
+			}
+			return m.runCommandLine(item.name)
+		}
+		if strings.TrimSpace(m.commandInput) != "" {
+			return m.runCommandLine(m.commandInput)
+		}
+		return m, nil
+
+	case "backspace":
+		if len(m.commandInput) > 0 {
+			runes := []rune(m.commandInput)
+			m.commandInput = string(runes[:len(runes)-1])
+			m.commandMenuIndex = 0
+		}
+		return m, nil
+
+	default:
+		switch msg.Type {
+		case tea.KeySpace:
+			m.commandInput += " "
+		case tea.KeyRunes:
+			m.commandInput += string(msg.Runes)
+		default:
+			return m, nil
+		}
+		m.commandMenuIndex = 0
+		return m, nil
+	}
+}
+
+// runCommandLine runs a typed or recent command line and adds it to the history. A command that
+// takes an argument but got none prompts for it.
+func (m model) runCommandLine(line string) (tea.Model, tea.Cmd) {
+	line = strings.Join(strings.Fields(line), " ")
+	for _, cmd := range menuCommands() {
+		if cmd.arg != "" && (line == cmd.name || strings.HasPrefix(line, cmd.name+" ")) {
+			arg := strings.TrimSpace(strings.TrimPrefix(line, cmd.name))
+			if arg == "" {
+				m.commandInput = cmd.name + " "
+				m.commandMenuIndex = 0
+				return m, nil
+			}
+			m.commandHistory = addCommandHistory(m.commandHistory, line)
+			return m.executeCommandArg(cmd.name, arg)
+		}
+		if cmd.arg == "" && line == cmd.name {
+			m.commandHistory = addCommandHistory(m.commandHistory, line)
+			return m.executeCommand(cmd.name)
+		}
 	}
+	m.closeAllModals()
+	m.showErrorModal = true
+	m.errorModalMsg = fmt.Sprintf("Unknown command: %s", line)
+	return m, nil
+}
+
+// commandOpenMRMsg carries the merge request opened by "open !<iid>"
+type commandOpenMRMsg struct {
+	iid int
+	url string
+	err error
+}
+
+// executeCommandArg executes a command with its argument
+func (m model) executeCommandArg(name, arg string) (tea.Model, tea.Cmd) {
+	m.closeAllModals()
+	switch name {
+	case "goto project":
+		// Switch right away on a single match among the listed projects, pick from the matches otherwise
+		m.projectFilter = arg
+		m.filterProjects()
+		if m.projectsLoaded && len(m.projectMatches) == 1 {
+			cmd := m.selectProject(m.projectMatches[0].project)
+			return m, cmd
+		}
+		m.showProjectSelector = true
+		if !m.projectsLoaded {
+			return m, m.loadProjects()
+		}
+		return m, nil
+
+	case "open":
+		iid, err := strconv.Atoi(strings.TrimPrefix(arg, "!"))
+		if err != nil || iid <= 0 {
+			m.showErrorModal = true
+			m.errorModalMsg = fmt.Sprintf("open: %q is not a merge request number, e.g. !42", arg)
+			return m, nil
+		}
+		if m.creds == nil || m.selectedProject == nil {
+			m.showErrorModal = true
+			m.errorModalMsg = "open: select a project first"
+			return m, nil
+		}
+		creds := *m.creds
+		projectID := m.selectedProject.ID
+		return m, func() tea.Msg {
+			mr, err := NewForge(creds).GetMergeRequestByIID(projectID, iid)
+			if err != nil {
+				return commandOpenMRMsg{iid: iid, err: err}
+			}
+			return commandOpenMRMsg{iid: iid, url: mr.WebURL}
+		}
+
+	case "set theme":
+		config, err := LoadConfig()
+		if err != nil {
+			m.showErrorModal = true
+			m.errorModalMsg = fmt.Sprintf("set theme: %v", err)
+			return m, nil
+		}
+		for _, tc := range config.Themes {
+			if strings.EqualFold(tc.Name, arg) {
+				applyTheme(tc)
+				m.updateTextareaTheme()
+				m.applySpinnerTheme()
+				config.SelectedTheme = tc.Name
+				SaveConfig(config)
+				return m, nil
+			}
+		}
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("set theme: no theme named %q", arg)
+		return m, nil
+
+	case "workspace":
+		return m.switchWorkspace(arg)
 
+	default:
+		if c, ok := findCustomCommand(name); ok {
+			return m.runCustomCommand(c, arg)
+		}
+	}
 	return m, nil
 }
 
+// handleCommandOpenMR opens the merge request found by "open !<iid>" in the browser
+func (m *model) handleCommandOpenMR(msg commandOpenMRMsg) tea.Cmd {
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Failed to open !%d: %v", msg.iid, msg.err)
+		return nil
+	}
+	return openInBrowser(msg.url)
+}
+
+// projectSuggestions returns the listed projects for "goto project"
+func projectSuggestions(m model) []string {
+	paths := make([]string, len(m.projects))
+	for i, p := range m.projects {
+		paths[i] = p.PathWithNamespace
+	}
+	return paths
+}
+
+// themeSuggestions returns the theme names for "set theme"
+func themeSuggestions(m model) []string {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(config.Themes))
+	for i, tc := range config.Themes {
+		names[i] = tc.Name
+	}
+	return names
+}
+
 // executeCommand executes the selected command
 func (m model) executeCommand(name string) (tea.Model, tea.Cmd) {
 	switch name {
 	case "project":
 		m.closeAllModals()
 		m.showProjectSelector = true
-		m.projectSelectorIndex = 0
 		m.projectFilter = ""
+		m.filterProjects()
 
 		// Load projects if not already loaded
 		if !m.projectsLoaded {
-			m.loadingProjects = true
-			return m, tea.Batch(m.spinner.Tick, m.fetchProjects())
+			return m, m.loadProjects()
 		}
 		return m, nil
 
+	case "tab":
+		cmd := m.openTab()
+		return m, cmd
+
+	case "close tab":
+		cmd := m.closeTab()
+		return m, cmd
+
+	case "shell":
+		cmd := m.openShell()
+		return m, cmd
+
+	case "screenshot":
+		m.closeAllModals()
+		return m, m.takeScreenshot()
+
 	case "settings":
-		m.settingsPreviousScreen = m.screen
 		m.closeAllModals()
-		m.settingsTab = 0
-		m.settingsFocusIndex = 0
-		// Load current settings
-		if config, err := LoadConfig(); err == nil {
-			m.settingsExcludePatterns.SetValue(config.ExcludePatterns)
-			pipelineRegex := config.PipelineJobsRegex
-			if pipelineRegex == "" {
-				pipelineRegex = defaultPipelineJobsRegex
-			}
-			m.settingsPipelineRegex.SetValue(pipelineRegex)
-			// Load base branch
-			baseBranch := config.BaseBranch
-			if baseBranch == "" {
-				baseBranch = "root"
-			}
-			m.settingsBaseBranch.SetValue(baseBranch)
-			// Load environment settings
-			envs := config.Environments
-			if len(envs) == 0 {
-				envs = defaultEnvironments()
-			}
-			for i := 0; i < 4 && i < len(envs); i++ {
-				m.settingsEnvNames[i].SetValue(strings.ToUpper(envs[i].Name))
-				m.settingsEnvBranches[i].SetValue(envs[i].BranchName)
-			}
-		}
-		(&m).updateTextareaTheme()
-		m.screen = screenSettings
-		(&m).initSettingsViewport()
-		return m, m.settingsBaseBranch.Focus()
+		cmd := m.openSettings()
+		return m, cmd
 
 	case "logout":
 		m.closeAllModals()
-		// Delete credentials from keyring
+		if n := m.parkedReleaseInProgress(); n != 0 {
+			m.showErrorModal = true
+			m.errorModalMsg = fmt.Sprintf("The release in tab %d is not finished: complete or abort it before logging out", n)
+			return m, nil
+		}
+		m.closeParkedTabs()
+		// Delete credentials from keyring, data cached for them and actions queued under them
 		DeleteCredentials()
+		clearCache()
+		clearQueuedActions()
+		m.offline, m.queueFlushed = false, false
+		m.stopBackgroundPolling()
 
 		// Clear project from config
 		SaveSelectedProject(nil)
 
 		// Reset to auth screen
-		m.screen = screenAuth
+		m.moveToScreen(screenAuth)
 		m.inputs = initAuthInputs()
 		m.focusIndex = 0
 		m.creds = nil
 		m.ready = false
 		m.selectedProject = nil
 		m.projects = nil
+		m.projectMatches = nil
 		m.projectsLoaded = false
 		m.mrsLoaded = false
 
 		return m, nil
+
+	default:
+		if c, ok := findCustomCommand(name); ok {
+			return m.runCustomCommand(c, "")
+		}
+		if strings.Contains(name, ":") {
+			return m.runPluginCommand(name)
+		}
 	}
 
 	return m, nil
@@ -130,7 +388,26 @@ func (m model) overlayCommandMenu(background string) string {
 	b.WriteString(commandMenuTitleStyle.Render("Commands"))
 	b.WriteString("\n")
 
-	for i, cmd := range commands {
+	// Command line with the placeholder of a command waiting for its argument
+	input := m.commandInput + "█"
+	waiting := false
+	for _, cmd := range menuCommands() {
+		if cmd.arg != "" && m.commandInput == cmd.name+" " {
+			input += helpStyle.Render(cmd.arg)
+			waiting = true
+		}
+	}
+	b.WriteString(commandItemStyle.Render("/ ") + input)
+	b.WriteString("\n\n")
+
+	items := m.commandMenuItems()
+	if len(items) == 0 && !waiting {
+		b.WriteString(commandDescStyle.Render("  enter: run the command line"))
+		b.WriteString("\n")
+	}
+	start := max(0, m.commandMenuIndex-commandMenuVisible+1)
+	for i := start; i < len(items) && i < start+commandMenuVisible; i++ {
+		cmd := items[i]
 		var nameStyle lipgloss.Style
 		prefix := "  "
 		if i == m.commandMenuIndex {
@@ -140,15 +417,21 @@ func (m model) overlayCommandMenu(background string) string {
 			nameStyle = commandItemStyle
 		}
 
-		b.WriteString(nameStyle.Render(fmt.Sprintf("%s%s", prefix, cmd.name)))
-		b.WriteString("\n")
-		b.WriteString(commandDescStyle.Render("    " + cmd.desc))
+		name := cmd.name
+		if cmd.arg != "" {
+			name += " " + cmd.arg
+		}
+		b.WriteString(nameStyle.Render(fmt.Sprintf("%s%s", prefix, name)))
 		b.WriteString("\n")
+		if cmd.desc != "" {
+			b.WriteString(commandDescStyle.Render("    " + cmd.desc))
+			b.WriteString("\n")
+		}
 	}
 
 	// Help footer
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("j/k: nav • enter: select • C+q: close"))
+	b.WriteString(helpStyle.Render("↑/↓: nav • tab: complete • enter: run • C+q: close"))
 
 	config := ModalConfig{
 		Width:    ModalWidth{Value: 50, Percent: true},
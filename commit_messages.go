@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Commit message templates make the commits of a release traceable in git log: merge_commit_template
+// is the message of each merge of an MR branch into the source branch, and squash_commit_template the
+// body of the release commit on the environment branch. The release commit title stays
+// "release:<version> <env> v<N>", as the latest released version is read from it.
+
+// commitMessageMR is an MR in commit message templates
+type commitMessageMR struct {
+	IID    int
+	Title  string // Branch name for releases started before titles were kept
+	Author string // Username, may be empty
+	Branch string
+	URL    string
+}
+
+// commitMessageData is passed to commit message templates. Merge templates get the merged MR's
+// fields at the top level, release commit templates get all MRs in .MRs.
+type commitMessageData struct {
+	commitMessageMR
+	MRs          []commitMessageMR
+	Version      string
+	VNumber      int    // Release commit only
+	Tag          string // Release commit only
+	Environment  string
+	EnvBranch    string
+	SourceBranch string
+	StitchedBy   string // Forge account running the release
+}
+
+// releaseCommitMRs returns the MRs of a release for commit message templates
+func releaseCommitMRs(state *ReleaseState) []commitMessageMR {
+	mrs := make([]commitMessageMR, len(state.MRBranches))
+	for i, branch := range state.MRBranches {
+		mrs[i] = commitMessageMR{Branch: branch, Title: branch}
+		if i < len(state.SelectedMRIIDs) {
+			mrs[i].IID = state.SelectedMRIIDs[i]
+		}
+		if i < len(state.MRTitles) && state.MRTitles[i] != "" {
+			mrs[i].Title = state.MRTitles[i]
+		}
+		if i < len(state.MRAuthors) {
+			mrs[i].Author = state.MRAuthors[i]
+		}
+		if i < len(state.MRURLs) {
+			mrs[i].URL = state.MRURLs[i]
+		}
+	}
+	return mrs
+}
+
+// newCommitMessageData returns the template data of a release
+func newCommitMessageData(state *ReleaseState, stitchedBy string) commitMessageData {
+	return commitMessageData{
+		MRs:          releaseCommitMRs(state),
+		Version:      state.Version,
+		Environment:  state.Environment.Name,
+		EnvBranch:    state.Environment.BranchName,
+		SourceBranch: state.SourceBranch,
+		StitchedBy:   stitchedBy,
+	}
+}
+
+// renderCommitMessage renders a commit message template. Trailing whitespace is dropped, and an
+// empty message is an error, as git would abort the commit.
+func renderCommitMessage(name, text string, data commitMessageData) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	message := strings.TrimRight(b.String(), " \t\n")
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("%s: message is empty", name)
+	}
+	return message, nil
+}
+
+// mergeCommitMessage returns the message of the merge of the MR at index into the source branch
+func mergeCommitMessage(text string, state *ReleaseState, index int, stitchedBy string) (string, error) {
+	data := newCommitMessageData(state, stitchedBy)
+	data.commitMessageMR = data.MRs[index]
+	return renderCommitMessage("merge_commit_template", text, data)
+}
+
+// releaseCommitBody returns the body of the release commit with the v-number
+func releaseCommitBody(text string, state *ReleaseState, vNumber int, stitchedBy string) (string, error) {
+	data := newCommitMessageData(state, stitchedBy)
+	data.VNumber = vNumber
+	data.Tag = state.tagName(vNumber)
+	return renderCommitMessage("squash_commit_template", text, data)
+}
@@ -35,13 +35,14 @@ func getConfigPath() (string, error) {
 	return filepath.Join(dir, configFileName), nil
 }
 
-// getReleaseStatePath returns the path to the release state file
-func getReleaseStatePath() (string, error) {
+// getReleaseStatePath returns the path to the release state file of a tab: release.json for
+// the first tab, release-2.json and so on for the others
+func getReleaseStatePath(tab int) (string, error) {
 	dir, err := getConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, releaseFileName), nil
+	return filepath.Join(dir, tabFileName(releaseFileName, tab)), nil
 }
 
 // LoadConfig loads the application configuration from file
@@ -91,17 +92,41 @@ func LoadConfig() (*AppConfig, error) {
 
 // SaveConfig saves the application configuration to file
 func SaveConfig(config *AppConfig) error {
-	path, err := getConfigPath()
+	data, err := encodeConfig(config)
 	if err != nil {
 		return err
 	}
+	return writeConfigFile(data)
+}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+// encodeConfig returns the content of the config file of config
+func encodeConfig(config *AppConfig) ([]byte, error) {
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// readConfigFile returns the content of the config file, nil if there is none
+func readConfigFile() ([]byte, error) {
+	path, err := getConfigPath()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
+	return data, err
+}
 
-	return os.WriteFile(path, data, 0o644)
+// writeConfigFile replaces the content of the config file
+func writeConfigFile(data []byte) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+	liveRedactor.Store(nil) // Secrets and redact_patterns of the config may have changed (see redaction.go)
+	err = os.WriteFile(path, data, 0o644)
+	liveForgeTimeouts.Store(nil) // http_timeouts are read again from the written file (see http_timeouts.go)
+	return err
 }
 
 // SaveSelectedProject saves the selected project to config
@@ -126,9 +151,41 @@ func SaveSelectedProject(project *Project) error {
 	return SaveConfig(config)
 }
 
-// LoadReleaseState loads the release state from file
-func LoadReleaseState() (*ReleaseState, error) {
-	path, err := getReleaseStatePath()
+// TogglePinnedProject pins the project to the home dashboard, or unpins it, and returns the pinned projects
+func TogglePinnedProject(project Project) ([]Project, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		config = &AppConfig{}
+	}
+
+	pinned := make([]Project, 0, len(config.PinnedProjects)+1)
+	for _, p := range config.PinnedProjects {
+		if p.ID != project.ID {
+			pinned = append(pinned, p)
+		}
+	}
+	if len(pinned) == len(config.PinnedProjects) {
+		pinned = append(pinned, project)
+	}
+	config.PinnedProjects = pinned
+
+	return pinned, SaveConfig(config)
+}
+
+// SaveProjectQuery saves the project selector list options to config
+func SaveProjectQuery(query projectQuery) error {
+	config, err := LoadConfig()
+	if err != nil {
+		config = &AppConfig{}
+	}
+	config.ProjectsScope = query.Scope
+	config.ProjectsIncludeArchived = query.Archived
+	return SaveConfig(config)
+}
+
+// LoadReleaseState loads the release state of a tab from file
+func LoadReleaseState(tab int) (*ReleaseState, error) {
+	path, err := getReleaseStatePath(tab)
 	if err != nil {
 		return nil, err
 	}
@@ -148,9 +205,9 @@ func LoadReleaseState() (*ReleaseState, error) {
 	return &state, nil
 }
 
-// SaveReleaseState saves the release state to file
-func SaveReleaseState(state *ReleaseState) error {
-	path, err := getReleaseStatePath()
+// SaveReleaseState saves the release state of a tab to file
+func SaveReleaseState(tab int, state *ReleaseState) error {
+	path, err := getReleaseStatePath(tab)
 	if err != nil {
 		return err
 	}
@@ -162,9 +219,10 @@ func SaveReleaseState(state *ReleaseState) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
-// ClearReleaseState removes the release state file
-func ClearReleaseState() error {
-	path, err := getReleaseStatePath()
+// ClearReleaseState removes the release state file and the spilled release output of a tab
+func ClearReleaseState(tab int) error {
+	clearReleaseOutputLog(tab)
+	path, err := getReleaseStatePath(tab)
 	if err != nil {
 		return err
 	}
@@ -175,9 +233,10 @@ func ClearReleaseState() error {
 	return err
 }
 
-// getBaseBranch loads config and returns the configured base branch with "root" fallback
+// getBaseBranch loads config and returns the configured base branch with "root" fallback.
+// The settings of the active project apply.
 func getBaseBranch() string {
-	config, err := LoadConfig()
+	config, err := loadActiveConfig()
 	if err != nil || config.BaseBranch == "" {
 		return "root"
 	}
@@ -194,9 +253,10 @@ func defaultEnvironments() []EnvConfig {
 	}
 }
 
-// getEnvironments loads config and converts EnvConfig to runtime Environment slice
+// getEnvironments loads config and converts EnvConfig to runtime Environment slice.
+// The settings of the active project apply.
 func getEnvironments() []Environment {
-	config, err := LoadConfig()
+	config, err := loadActiveConfig()
 	if err != nil || len(config.Environments) == 0 {
 		// Fallback to defaults
 		return envsFromConfig(defaultEnvironments())
@@ -209,8 +269,22 @@ func envsFromConfig(configs []EnvConfig) []Environment {
 	envs := make([]Environment, len(configs))
 	for i, ec := range configs {
 		envs[i] = Environment{
-			Name:       strings.ToUpper(ec.Name),
-			BranchName: ec.BranchName,
+			Name:          strings.ToUpper(ec.Name),
+			BranchName:    ec.BranchName,
+			TagPrefix:     ec.TagPrefix,
+			VersionSuffix: ec.VersionSuffix,
+			BuildMetadata: ec.BuildMetadata,
+			Rollout:       ec.Rollout,
+			ConfirmPhrase: ec.ConfirmPhrase,
+			PushStrategy:  ec.PushStrategy,
+			Variables:     ec.Variables,
+
+			ReleaseCandidates: ec.ReleaseCandidates,
+			DeployPipeline:    ec.DeployPipeline,
+			GitLabEnvironment: ec.GitLabEnvironment,
+		}
+		if ec.ReleaseCandidates && ec.VersionSuffix == "" {
+			envs[i].VersionSuffix = defaultCandidateSuffix
 		}
 	}
 	return envs
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Saving the settings rewrites the whole config file, so hand edits to it can be lost: fields
+// relix does not know are dropped, and a file edited since the settings were opened is
+// overwritten. Before writing, the settings screen shows the change as a unified diff of the file
+// and writes it only once confirmed. A config file that cannot be parsed is never overwritten.
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// diffLine is a line of a line diff: ' ' kept, '-' removed or '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// splitLines splits text into lines, without the empty one after a trailing newline
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// diffLines returns the line diff turning a into b. The lines around the common prefix and
+// suffix are matched by their longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	for _, s := range a[:prefix] {
+		lines = append(lines, diffLine{' ', s})
+	}
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, diffLine{' ', x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', x[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, diffLine{'-', x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, diffLine{'+', y[j]})
+	}
+	for _, s := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', s})
+	}
+	return lines
+}
+
+// unifiedDiff returns the unified diff turning a into b, without the file header; nil if they
+// are equal
+func unifiedDiff(a, b []byte) []string {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	// Line numbers in a and b before each diff line
+	aLine, bLine := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	for k, l := range lines {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if l.op != '+' {
+			aLine[k+1]++
+		}
+		if l.op != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	var out []string
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+		// A hunk runs from the context before this change to the context after the last change
+		// that is at most two contexts away from the one before
+		start, end := max(0, k-diffContext), k
+		for last := k; last < len(lines); last++ {
+			if lines[last].op != ' ' {
+				end = last
+			} else if last-end > 2*diffContext {
+				break
+			}
+		}
+		end = min(len(lines), end+diffContext+1)
+
+		aStart, bStart := aLine[start]+1, bLine[start]+1
+		aCount, bCount := aLine[end]-aLine[start], bLine[end]-bLine[start]
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", aStart, aCount, bStart, bCount))
+		for _, l := range lines[start:end] {
+			out = append(out, string(l.op)+l.text)
+		}
+		k = end
+	}
+	return out
+}
+
+// reviewSettings shows how saving the settings changes the config file, or closes the settings
+// if it does not change
+func (m model) reviewSettings() (tea.Model, tea.Cmd) {
+	current, err := readConfigFile()
+	if err != nil {
+		m.settingsError = "Config: " + err.Error()
+		return m, nil
+	}
+	// A config file that does not parse is left for its author to fix
+	config, err := LoadConfig()
+	if err != nil {
+		m.settingsError = "Config file cannot be read, fix it by hand first: " + err.Error()
+		return m, nil
+	}
+	m.applySettings(config)
+	updated, err := encodeConfig(config)
+	if err != nil {
+		m.settingsError = "Config: " + err.Error()
+		return m, nil
+	}
+	if bytes.Equal(current, updated) {
+		return m.closeSavedSettings(false)
+	}
+
+	m.closeAllModals()
+	m.settingsDiff = unifiedDiff(current, updated)
+	m.settingsDiffBase = current
+	m.settingsDiffConfig = updated
+	m.settingsDiffOffset = 0
+	m.settingsDiffIndex = 0
+	m.showSettingsDiff = true
+	return m, nil
+}
+
+// saveReviewedSettings writes the reviewed config file, unless it changed since it was diffed
+func (m model) saveReviewedSettings() (tea.Model, tea.Cmd) {
+	base, updated := m.settingsDiffBase, m.settingsDiffConfig
+	m.closeSettingsDiff()
+
+	current, err := readConfigFile()
+	if err == nil && !bytes.Equal(current, base) {
+		err = fmt.Errorf("the file changed since the diff was shown; save again to review the new changes")
+	}
+	if err != nil {
+		m.settingsError = "Config: " + err.Error()
+		return m, nil
+	}
+	// Keyring tab: the stored credentials are moved over first so they are not lost
+	if backend := m.settingsKeyringChoice(); backend != configuredKeyringBackend() {
+		if err := moveCredentials(configuredKeyringBackend(), backend, m.creds); err != nil {
+			m.settingsError = "Keyring: " + err.Error()
+			return m, nil
+		}
+	}
+	if err := writeConfigFile(updated); err != nil {
+		m.settingsError = "Config: " + err.Error()
+		return m, nil
+	}
+	return m.closeSavedSettings(true)
+}
+
+// closeSettingsDiff closes the config diff of the settings
+func (m *model) closeSettingsDiff() {
+	m.showSettingsDiff = false
+	m.settingsDiff = nil
+	m.settingsDiffBase = nil
+	m.settingsDiffConfig = nil
+}
+
+// settingsDiffHeight returns the number of diff lines the modal shows at once
+func (m model) settingsDiffHeight() int {
+	return max(3, m.height-14)
+}
+
+// updateSettingsDiff handles keys of the config diff of the settings
+func (m model) updateSettingsDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxOffset := max(0, len(m.settingsDiff)-m.settingsDiffHeight())
+	switch msg.String() {
+	case "y", "Y":
+		return m.saveReviewedSettings()
+	case "n", "N", "esc", "ctrl+q":
+		m.closeSettingsDiff()
+	case "enter":
+		if m.settingsDiffIndex == 0 {
+			return m.saveReviewedSettings()
+		}
+		m.closeSettingsDiff()
+	case "tab", "left", "right", "h", "l":
+		m.settingsDiffIndex = 1 - m.settingsDiffIndex
+	case "j", "down":
+		m.settingsDiffOffset = min(maxOffset, m.settingsDiffOffset+1)
+	case "k", "up":
+		m.settingsDiffOffset = max(0, m.settingsDiffOffset-1)
+	case "d", "pgdown":
+		m.settingsDiffOffset = min(maxOffset, m.settingsDiffOffset+m.settingsDiffHeight()/2)
+	case "u", "pgup":
+		m.settingsDiffOffset = max(0, m.settingsDiffOffset-m.settingsDiffHeight()/2)
+	}
+	return m, nil
+}
+
+// overlaySettingsDiff renders the config diff of the settings over the screen
+func (m model) overlaySettingsDiff(background string) string {
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 80, Percent: true},
+		MinWidth: 50,
+		MaxWidth: 120,
+		Style:    errorBoxStyle,
+	}
+	width := min(max(config.MinWidth, m.width*config.Width.Value/100), config.MaxWidth, m.width-4) - config.Style.GetHorizontalFrameSize()
+
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("Save Config?"))
+	sb.WriteString("\n\n")
+	path, _ := getConfigPath()
+	sb.WriteString(fmt.Sprintf("Saving the settings changes %s:\n\n", path))
+
+	added := lipgloss.NewStyle().Foreground(currentTheme.Success)
+	removed := lipgloss.NewStyle().Foreground(currentTheme.Error)
+	hunk := lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	end := min(len(m.settingsDiff), m.settingsDiffOffset+m.settingsDiffHeight())
+	for _, line := range m.settingsDiff[m.settingsDiffOffset:end] {
+		line = truncateWithEllipsis(line, width)
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			line = hunk.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = added.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removed.Render(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+	if len(m.settingsDiff) > end || m.settingsDiffOffset > 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Notion).Render(
+			fmt.Sprintf("lines %d-%d of %d • j/k/d/u: scroll", m.settingsDiffOffset+1, end, len(m.settingsDiff))) + "\n")
+	}
+	sb.WriteString("\n")
+
+	saveBtn, cancelBtn := buttonActiveStyle.Render("Save"), buttonStyle.Render("Cancel")
+	if m.settingsDiffIndex != 0 {
+		saveBtn, cancelBtn = buttonStyle.Render("Save"), buttonActiveStyle.Render("Cancel")
+	}
+	sb.WriteString(fmt.Sprintf("     %s       %s", saveBtn, cancelBtn))
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
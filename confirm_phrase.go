@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Confirmation phrases: an environment with confirm_phrase (e.g. "ship it" or "{project}") asks
+// for the phrase to be typed before its release starts, so a release is not started on the wrong
+// environment by pressing Enter out of habit. The phrase is compared ignoring case and surrounding
+// spaces. Headless and API releases are started explicitly and are not asked.
+
+// confirmPhrase returns the phrase to type before releasing to the selected environment, with
+// {project} and {env} expanded; empty if the environment needs none
+func (m model) confirmPhrase() string {
+	if m.selectedEnv == nil || strings.TrimSpace(m.selectedEnv.ConfirmPhrase) == "" {
+		return ""
+	}
+	project := ""
+	if m.selectedProject != nil {
+		project = m.selectedProject.Name
+	}
+	return strings.NewReplacer("{project}", project, "{env}", strings.ToLower(m.selectedEnv.Name)).
+		Replace(strings.TrimSpace(m.selectedEnv.ConfirmPhrase))
+}
+
+// openConfirmPhrase asks for the confirmation phrase of the selected environment
+func (m *model) openConfirmPhrase() tea.Cmd {
+	ti := textinput.New()
+	ti.Placeholder = m.confirmPhrase()
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	ti.CharLimit = 200
+	ti.Width = 40
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	m.confirmPhraseInput = ti
+	m.confirmPhraseError = ""
+	m.showConfirmPhrase = true
+	return m.confirmPhraseInput.Focus()
+}
+
+// updateConfirmPhrase handles keys of the confirmation phrase modal
+func (m model) updateConfirmPhrase(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+q":
+		m.showConfirmPhrase = false
+		return m, nil
+	case "enter":
+		typed := strings.TrimSpace(m.confirmPhraseInput.Value())
+		if !strings.EqualFold(typed, m.confirmPhrase()) {
+			m.confirmPhraseError = "The phrase does not match"
+			return m, nil
+		}
+		m.showConfirmPhrase = false
+		return m.askPipelineInputs()
+	}
+	var cmd tea.Cmd
+	m.confirmPhraseInput, cmd = m.confirmPhraseInput.Update(msg)
+	m.confirmPhraseError = ""
+	return m, cmd
+}
+
+// overlayConfirmPhrase renders the confirmation phrase modal
+func (m model) overlayConfirmPhrase(background string) string {
+	if m.selectedEnv == nil {
+		return background
+	}
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("Release to " + m.selectedEnv.Name + "?"))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "Type %s to start the release.\n\n", lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Warning).Render(m.confirmPhrase()))
+	sb.WriteString(m.confirmPhraseInput.View())
+	if m.confirmPhraseError != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(settingsErrorStyle.Render(m.confirmPhraseError))
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(helpStyle.Render("enter: release • esc: cancel"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 70,
+		Style:    errorBoxStyle,
+	}
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -33,8 +32,11 @@ func (m *model) initConfirmViewport() {
 func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+q":
-		// Go back to root merge screen, restore button index based on selection
-		m.screen = screenRootMerge
+		// Go back to rollout or root merge screen, restore button index based on selection
+		m.goBack()
+		if m.screen == screenRollout {
+			return m, m.initRolloutInputs()
+		}
 		if m.rootMergeSelection {
 			m.rootMergeButtonIndex = 0
 		} else {
@@ -46,8 +48,13 @@ func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.sourceBranchRemoteStatus == "checking" {
 			return m, nil
 		}
-		// Start the release process
-		return m.startRelease()
+		// Environments with a confirmation phrase ask for it first
+		if m.confirmPhrase() != "" {
+			cmd := m.openConfirmPhrase()
+			return m, cmd
+		}
+		// Start the release process, once the deployment pipeline inputs are given
+		return m.askPipelineInputs()
 	}
 
 	// Handle viewport scrolling
@@ -298,7 +305,9 @@ func (m model) renderConfirmMarkdown(width int) string {
 	version := m.versionInput.Value()
 	envName := ""
 	envBranch := ""
+	var env Environment
 	if m.selectedEnv != nil {
+		env = *m.selectedEnv
 		envName = m.selectedEnv.Name
 		envBranch = m.selectedEnv.BranchName
 	}
@@ -311,7 +320,7 @@ func (m model) renderConfirmMarkdown(width int) string {
 
 	// Get next v-number for display
 	vNumber := 1
-	if workDir, err := FindProjectRoot(); err == nil && envBranch != "" {
+	if workDir, err := projectWorkDir(m.selectedProject); err == nil && envBranch != "" {
 		if n, err := GetNextVersionNumber(workDir, envBranch, version); err == nil {
 			vNumber = n
 		}
@@ -335,7 +344,7 @@ func (m model) renderConfirmMarkdown(width int) string {
 	envBranchNB := strings.ReplaceAll(envBranch, "-", nbHyphen)
 
 	// Build tag name
-	tagName := fmt.Sprintf("%s-%s-v%d", strings.ToLower(envName), version, vNumber)
+	tagName := ReleaseTagName(env, version, vNumber)
 
 	// Build step 4-5 and subsequent numbering based on env merge mode
 	step4And5 := ""
@@ -371,6 +380,21 @@ func (m model) renderConfirmMarkdown(width int) string {
 			mrStepNum+1, mrStepNum+2, sourceBranch, tagName)
 	}
 
+	// Rollout and impact of the release, above the warning
+	summary := ""
+	if r := m.rollout(); r != nil {
+		summary = rolloutMarkdown(r) + "\n\n"
+	}
+	if warning := xlMRWarning(m.selectedMRDetails()); warning != "" {
+		summary += warning + "\n\n"
+	}
+	if warning := m.scheduleWarning(); warning != "" {
+		summary += warning + "\n\n"
+	}
+	if m.releaseImpact != nil {
+		summary += m.releaseImpact.markdown() + "\n"
+	}
+
 	markdown := fmt.Sprintf(`[ We are ready ]()to release **%s v%d** of selected MRs to **%s** environment!
 
 This release will go through the following steps:
@@ -389,7 +413,7 @@ This release will go through the following steps:
 
 %s
 
-*ATTENTION!* ~~If there are existing local branches under mentioned names~~ *%s* ~~or~~ *release/rpb‑%s‑%s*~~, then they will be removed and recreated with pointer at current root or remote source branch and current environment branch respectively~~
+%s*ATTENTION!* ~~If there are existing local branches under mentioned names~~ *%s* ~~or~~ *release/rpb‑%s‑%s*~~, then they will be removed and recreated with pointer at current root or remote source branch and current environment branch respectively~~
 
 If you agree, press enter and release it.
 `,
@@ -400,18 +424,16 @@ If you agree, press enter and release it.
 		pushStepNum, version, envBranch,
 		mrStepNum, version, envBranch, envBranch,
 		step8And9,
+		summary,
 		sourceBranchNB, versionNB, envBranchNB,
 	)
 
-	style := styles.DarkStyleConfig
+	style := markdownStyle()
 	envColor := string(currentTheme.Warning) // default
 	if m.selectedEnv != nil {
 		envColor = getEnvBranchColor(m.selectedEnv.Name)
 	}
-	style.Document.StylePrimitive.Color = stringPtr(string(currentTheme.Foreground))
 	style.Strong.Color = stringPtr(envColor)
-	style.Code.BackgroundColor = stringPtr(string(currentTheme.Muted))
-	style.Code.Color = stringPtr(string(currentTheme.MutedForeground))
 	style.Emph.Color = stringPtr(string(currentTheme.Error))
 	style.Emph.Italic = boolPtr(false)
 	style.Strikethrough.Color = stringPtr(string(currentTheme.Error))
@@ -423,6 +445,7 @@ If you agree, press enter and release it.
 
 	renderer, err := glamour.NewTermRenderer(
 		glamour.WithStyles(style),
+		glamour.WithColorProfile(termPolicy.colors),
 		glamour.WithWordWrap(width),
 		glamour.WithPreservedNewLines(),
 	)
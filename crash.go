@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const crashesDirName = "crashes"
+
+// lastCrashReport is the path of the report written for the panic that stopped the program
+var lastCrashReport string
+
+// crashGuard wraps the model to write a crash report when Init, Update, View or a command
+// panics. The panic is then re-raised, so Bubble Tea restores the terminal and stops the program.
+type crashGuard struct {
+	model tea.Model
+}
+
+func (g crashGuard) Init() tea.Cmd {
+	defer g.recover()
+	return guardCmd(g.model.Init(), g.model)
+}
+
+func (g crashGuard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer g.recover()
+	next, cmd := g.model.Update(msg)
+	return crashGuard{next}, guardCmd(cmd, next)
+}
+
+func (g crashGuard) View() string {
+	defer g.recover()
+	return g.model.View()
+}
+
+// recover reports a panic of the model and re-raises it
+func (g crashGuard) recover() {
+	if r := recover(); r != nil {
+		reportCrash(r, g.model)
+		panic(r)
+	}
+}
+
+// guardCmd wraps a command, and the commands of a batch it returns, to report their panics
+// with the model they were started from
+func guardCmd(cmd tea.Cmd, m tea.Model) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer func() {
+			if r := recover(); r != nil {
+				reportCrash(r, m)
+				panic(r)
+			}
+		}()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c, m)
+			}
+			return guarded
+		}
+		return msg
+	}
+}
+
+// crashState describes what the user was doing, without credentials or release output
+func crashState(m tea.Model) map[string]string {
+	state := map[string]string{}
+	mm, ok := m.(model)
+	if !ok {
+		return state
+	}
+	state["screen"] = strconv.Itoa(int(mm.screen))
+	state["size"] = fmt.Sprintf("%dx%d", mm.width, mm.height)
+	if mm.creds != nil {
+		state["forge"] = forgeName(*mm.creds)
+	}
+	if mm.selectedProject != nil {
+		state["project"] = mm.selectedProject.PathWithNamespace
+	}
+	if rs := mm.releaseState; rs != nil {
+		state["release.environment"] = rs.Environment.Name
+		state["release.version"] = rs.Version
+		state["release.step"] = releaseStepNames[rs.CurrentStep]
+		state["release.mr_count"] = strconv.Itoa(len(rs.MRBranches))
+		if rs.LastError != nil {
+			state["release.last_error"] = redactSecrets(rs.LastError.Message)
+		}
+	}
+	return state
+}
+
+// reportCrash writes a crash report to ~/.relix/crashes and submits it to Sentry if configured.
+// Called from a deferred recover, so the stack still shows where the panic happened.
+func reportCrash(r any, m tea.Model) {
+	now := time.Now()
+	stack := debug.Stack()
+	state := crashState(m)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Relix v%s crashed at %s\n", AppVersion, now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "%s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "panic: %v\n\nState:\n", r)
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s: %s\n", key, state[key])
+	}
+	fmt.Fprintf(&b, "\n%s", stack)
+
+	if dir, err := getConfigDir(); err == nil {
+		dir = filepath.Join(dir, crashesDirName)
+		path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+		if os.MkdirAll(dir, 0o755) == nil && os.WriteFile(path, []byte(redactSecrets(b.String())), 0o600) == nil {
+			lastCrashReport = path
+		}
+	}
+
+	if config, err := LoadConfig(); err == nil && config.SentryDSN != "" {
+		sendSentryEvent(config.SentryDSN, r, state, now)
+	}
+}
+
+// sendSentryEvent submits the panic to a Sentry DSN (https://key@host/project) with the store API
+func sendSentryEvent(dsn string, r any, state map[string]string, now time.Time) error {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.Host == "" {
+		return fmt.Errorf("invalid Sentry DSN")
+	}
+	projectID := strings.TrimPrefix(u.Path, "/")
+	prefix := ""
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		prefix, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, projectID)
+
+	// Frames oldest first, as Sentry expects
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var sentryFrames []map[string]any
+	for {
+		frame, more := frames.Next()
+		sentryFrames = append([]map[string]any{{
+			"function": frame.Function,
+			"filename": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(frame.Function, "main."),
+		}}, sentryFrames...)
+		if !more {
+			break
+		}
+	}
+
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+	event := map[string]any{
+		"event_id":  hex.EncodeToString(eventID),
+		"timestamp": now.UTC().Format(time.RFC3339),
+		"platform":  "go",
+		"level":     "fatal",
+		"release":   "relix@" + AppVersion,
+		"contexts": map[string]any{
+			"os":      map[string]any{"name": runtime.GOOS},
+			"runtime": map[string]any{"name": "go", "version": runtime.Version()},
+		},
+		"extra": state,
+		"exception": map[string]any{"values": []map[string]any{{
+			"type":       "panic",
+			"value":      fmt.Sprint(r),
+			"stacktrace": map[string]any{"frames": sentryFrames},
+		}}},
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=relix/%s, sentry_key=%s", AppVersion, u.User.Username()))
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Sentry returned %s", resp.Status)
+	}
+	return nil
+}
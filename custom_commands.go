@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Custom commands are command menu entries defined in the config ("commands"), turning the menu
+// into a launcher for team-specific release chores. The run field is a text/template rendered with
+// the selected project and the current release, then run by the shell in the same directory and
+// environment as the shell opened with "!". Commands with show_output run in the background and
+// show their output in an overlay; the others take over the terminal until they finish.
+const (
+	customCommandTimeout     = 10 * time.Minute
+	customCommandOutputLines = 30 // Last lines of the output shown in the overlay
+)
+
+// customCommandData is the data of a custom command template
+type customCommandData struct {
+	Project      string // Path of the selected project, e.g. "group/app"
+	ProjectID    int
+	WorkDir      string // Directory the command runs in
+	Arg          string // Argument typed after the name, for commands with arg
+	MRs          []int  // IIDs of the release's MRs, or of the MRs selected in the list
+	Environment  string // The release's environment; empty without a release
+	EnvBranch    string
+	Version      string
+	Tag          string
+	SourceBranch string
+}
+
+// customCommandFuncs are the functions available in custom command templates
+var customCommandFuncs = template.FuncMap{
+	"quote": shellQuote,
+}
+
+// customCommands returns the custom commands of the config. Entries without a name or command,
+// named like a built-in command or namespaced like plugin commands are skipped.
+func customCommands() []CustomCommand {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+	var valid []CustomCommand
+	for _, c := range config.Commands {
+		builtin := slices.ContainsFunc(commands, func(item commandItem) bool { return item.name == c.Name })
+		if c.Name == "" || strings.TrimSpace(c.Run) == "" || builtin || strings.Contains(c.Name, ":") {
+			continue
+		}
+		valid = append(valid, c)
+	}
+	return valid
+}
+
+// findCustomCommand returns the custom command with the given name
+func findCustomCommand(name string) (CustomCommand, bool) {
+	for _, c := range customCommands() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CustomCommand{}, false
+}
+
+// customCommandItems returns the command menu entries of the custom commands
+func customCommandItems() []commandItem {
+	var items []commandItem
+	for _, c := range customCommands() {
+		desc := c.Description
+		if desc == "" {
+			desc = "Custom command: " + c.Run
+		}
+		items = append(items, commandItem{name: c.Name, desc: desc, arg: c.Arg})
+	}
+	return items
+}
+
+// customCommandData returns the template data of a custom command run in dir
+func (m model) customCommandData(dir, arg string) customCommandData {
+	data := customCommandData{WorkDir: dir, Arg: arg}
+	if m.selectedProject != nil {
+		data.Project = m.selectedProject.PathWithNamespace
+		data.ProjectID = m.selectedProject.ID
+	}
+	if state := m.releaseState; state != nil {
+		data.MRs = slices.Clone(state.SelectedMRIIDs)
+		data.Environment = state.Environment.Name
+		data.EnvBranch = state.Environment.BranchName
+		data.Version = state.Version
+		data.Tag = state.TagName
+		data.SourceBranch = state.SourceBranch
+	} else {
+		for iid, selected := range m.selectedMRs {
+			if selected {
+				data.MRs = append(data.MRs, iid)
+			}
+		}
+		slices.Sort(data.MRs)
+	}
+	return data
+}
+
+// renderCustomCommand renders the run template of a custom command
+func renderCustomCommand(c CustomCommand, data customCommandData) (string, error) {
+	tmpl, err := template.New(c.Name).Funcs(customCommandFuncs).Option("missingkey=error").Parse(c.Run)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// customCommandShell returns the shell invocation running script
+func customCommandShell(ctx context.Context, script string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd.exe", "/C", script)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
+
+// customCommandMsg carries the result of a custom command
+type customCommandMsg struct {
+	name     string
+	output   string
+	terminal bool // Ran in the terminal, which showed the output already
+	err      error
+}
+
+// runCustomCommand runs a custom command of the command menu. Not while a release step runs, as
+// the command could race its git commands like a shell would.
+func (m model) runCustomCommand(c CustomCommand, arg string) (tea.Model, tea.Cmd) {
+	m.closeAllModals()
+	if m.releaseRunning {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Wait for the running release step to finish before running %s", c.Name)
+		return m, nil
+	}
+	dir, err := m.shellWorkDir()
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Cannot run %s: %v", c.Name, err)
+		return m, nil
+	}
+	script, err := renderCustomCommand(c, m.customCommandData(dir, arg))
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("%s: invalid run template: %v", c.Name, err)
+		return m, nil
+	}
+	env := os.Environ()
+	if m.releaseState != nil {
+		env = releaseEnviron(releaseVariables(m.releaseState))
+	}
+
+	if !c.ShowOutput {
+		cmd := customCommandShell(context.Background(), script)
+		cmd.Dir = dir
+		cmd.Env = env
+		banner := fmt.Sprintf("relix: %s\n$ %s\n", c.Name, script)
+		return m, tea.Exec(&shellCommand{cmd: cmd, banner: banner, wait: true}, func(err error) tea.Msg {
+			return customCommandMsg{name: c.Name, terminal: true, err: err}
+		})
+	}
+
+	name := c.Name
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), customCommandTimeout)
+		defer cancel()
+		cmd := customCommandShell(ctx, script)
+		cmd.Dir = dir
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", customCommandTimeout)
+		}
+		return customCommandMsg{name: name, output: string(output), err: err}
+	}
+}
+
+// handleCustomCommand shows the output of a custom command, or why it failed
+func (m *model) handleCustomCommand(msg customCommandMsg) {
+	output := strings.TrimRight(msg.output, "\n")
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("%s failed: %v", msg.name, msg.err)
+		var exitErr *exec.ExitError
+		if errors.As(msg.err, &exitErr) && output != "" {
+			m.errorModalMsg += "\n\n" + GetLastNLines(output, 5)
+		}
+		return
+	}
+	if msg.terminal {
+		return
+	}
+	if output == "" {
+		output = "Done, no output"
+	}
+	m.closeAllModals()
+	m.showPluginResult = true
+	m.pluginResultTitle = msg.name
+	m.pluginResultText = GetLastNLines(output, customCommandOutputLines)
+}
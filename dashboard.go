@@ -0,0 +1,470 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// The home screen is a dashboard of widgets loaded in the background, each with its own spinner:
+// open MR counts and running pipelines of the pinned projects (the selected project while none
+// are pinned), the latest release per environment from the local history with the back-merges the
+// selected project misses (see back_merge.go), the scheduled releases from the release windows,
+// and with history sync the team activity feed (see history_sync.go). The widgets refresh every
+// dashboardPollInterval while the home screen is shown.
+
+const (
+	dashboardPollInterval   = 60 * time.Second
+	dashboardMaxPipelines   = 6  // Running pipelines listed; the rest are counted
+	dashboardWidgetMinWidth = 38 // Narrower screens stack the widgets in one column
+	dashboardMaxWidth       = 110
+)
+
+// projectMRCount is the number of open MRs of a dashboard project
+type projectMRCount struct {
+	project Project
+	count   int
+	more    bool // The forge did not report a total: count is the first page and more exist
+	err     error
+}
+
+// projectPipeline is a running pipeline of a dashboard project
+type projectPipeline struct {
+	project  Project
+	pipeline Pipeline
+}
+
+// dashboardState holds the widget data of the home dashboard
+type dashboardState struct {
+	mrCounts   []projectMRCount
+	mrsLoading bool
+	mrsLoaded  bool
+
+	pipelines        []projectPipeline
+	pipelinesErr     error
+	pipelinesLoading bool
+	pipelinesLoaded  bool
+
+	releases        map[string]HistoryIndexEntry // Latest completed release by lowercase environment name
+	releasesErr     error
+	releasesLoading bool
+	releasesLoaded  bool
+	backMerges      []backMergeGap // Lower environments missing MRs of the selected project's releases
+	failures        []failureCount // Releases aborted lately by post-mortem category (see post_mortem.go)
+
+	activity        []teamActivity // Latest releases of the team from the history sync server
+	activityErr     error
+	activityEnabled bool // History sync is configured; the widget is hidden without it
+	activityLoading bool
+	activityLoaded  bool
+}
+
+// loading reports whether any widget is being loaded (keeps the spinner ticking)
+func (d dashboardState) loading() bool {
+	return d.mrsLoading || d.pipelinesLoading || d.releasesLoading || d.activityLoading
+}
+
+// dashboardMRsMsg carries the open MR counts of the dashboard projects
+type dashboardMRsMsg struct {
+	gen    int
+	counts []projectMRCount
+}
+
+// dashboardPipelinesMsg carries the running pipelines of the dashboard projects
+type dashboardPipelinesMsg struct {
+	gen       int
+	pipelines []projectPipeline
+	err       error
+}
+
+// dashboardReleasesMsg carries the latest release per environment
+type dashboardReleasesMsg struct {
+	gen        int
+	releases   map[string]HistoryIndexEntry
+	backMerges []backMergeGap
+	failures   []failureCount
+	err        error
+}
+
+// dashboardActivityMsg carries the team activity feed
+type dashboardActivityMsg struct {
+	gen      int
+	activity []teamActivity
+	enabled  bool
+	err      error
+}
+
+// dashboardPollTickMsg triggers a refresh of the dashboard
+type dashboardPollTickMsg struct {
+	gen int
+}
+
+// isPinned reports whether the project is pinned to the dashboard
+func (m model) isPinned(projectID int) bool {
+	for _, p := range m.pinnedProjects {
+		if p.ID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// dashboardProjects returns the projects the MR and pipeline widgets show
+func (m model) dashboardProjects() []Project {
+	if len(m.pinnedProjects) > 0 {
+		return m.pinnedProjects
+	}
+	if m.selectedProject != nil {
+		return []Project{*m.selectedProject}
+	}
+	return nil
+}
+
+// loadDashboard loads the widgets that are not already loading, each in its own command
+func (m *model) loadDashboard() tea.Cmd {
+	if m.creds == nil {
+		return nil
+	}
+	gen := m.backgroundPollGen
+	creds := *m.creds
+	projects := m.dashboardProjects()
+	var cmds []tea.Cmd
+
+	if !m.dashboard.mrsLoading && len(projects) > 0 {
+		m.dashboard.mrsLoading = true
+		cmds = append(cmds, func() tea.Msg {
+			client := NewForge(creds)
+			counts := make([]projectMRCount, len(projects))
+			for i, project := range projects {
+				mrs, page, err := client.GetProjectMergeRequestsPage(project.ID, 1)
+				counts[i] = projectMRCount{project: project, count: page.Total, err: err}
+				if err == nil && page.Total < 0 {
+					counts[i].count, counts[i].more = len(mrs), page.HasMore
+				}
+			}
+			return dashboardMRsMsg{gen: gen, counts: counts}
+		})
+	}
+
+	if !m.dashboard.pipelinesLoading && len(projects) > 0 {
+		m.dashboard.pipelinesLoading = true
+		cmds = append(cmds, func() tea.Msg {
+			client := NewForge(creds)
+			var running []projectPipeline
+			for _, project := range projects {
+				pipelines, err := client.GetRunningPipelines(project.ID)
+				if err != nil {
+					return dashboardPipelinesMsg{gen: gen, pipelines: running, err: err}
+				}
+				for _, p := range pipelines {
+					running = append(running, projectPipeline{project: project, pipeline: p})
+				}
+			}
+			return dashboardPipelinesMsg{gen: gen, pipelines: running}
+		})
+	}
+
+	if !m.dashboard.releasesLoading {
+		m.dashboard.releasesLoading = true
+		selected := m.selectedProject
+		envs := append([]Environment{}, m.environments...)
+		cmds = append(cmds, func() tea.Msg {
+			entries, err := LoadHistoryIndex()
+			latest := make(map[string]HistoryIndexEntry)
+			for _, e := range entries {
+				env := strings.ToLower(e.Environment)
+				if e.Status == "completed" && e.DateTime.After(latest[env].DateTime) {
+					latest[env] = e
+				}
+			}
+			var backMerges []backMergeGap
+			if selected != nil && err == nil {
+				if workDir, dirErr := projectWorkDir(selected); dirErr == nil {
+					backMerges = findBackMergeGaps(workDir, selected.PathWithNamespace, envs, entries)
+				}
+			}
+			failures := countFailures(entries, time.Now().Add(-postMortemWindow))
+			return dashboardReleasesMsg{gen: gen, releases: latest, backMerges: backMerges, failures: failures, err: err}
+		})
+	}
+
+	if !m.dashboard.activityLoading {
+		m.dashboard.activityLoading = true
+		cmds = append(cmds, func() tea.Msg {
+			activity, enabled, err := loadTeamActivity()
+			return dashboardActivityMsg{gen: gen, activity: activity, enabled: enabled, err: err}
+		})
+	}
+
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(append(cmds, m.spinner.Tick)...)
+}
+
+// dashboardPollTick returns a command that triggers a dashboard refresh after dashboardPollInterval
+func (m *model) dashboardPollTick() tea.Cmd {
+	gen := m.backgroundPollGen
+	return tea.Tick(dashboardPollInterval, func(time.Time) tea.Msg {
+		return dashboardPollTickMsg{gen: gen}
+	})
+}
+
+// handleDashboardPollTick refreshes the dashboard if the home screen is shown, and re-arms the tick
+func (m *model) handleDashboardPollTick(msg dashboardPollTickMsg) tea.Cmd {
+	if msg.gen != m.backgroundPollGen || m.creds == nil {
+		return nil
+	}
+	if m.screen != screenHome {
+		return m.dashboardPollTick()
+	}
+	return tea.Batch(m.dashboardPollTick(), m.loadDashboard())
+}
+
+// handleDashboardMsg stores the result of a widget load
+func (m *model) handleDashboardMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case dashboardMRsMsg:
+		if msg.gen != m.backgroundPollGen {
+			return nil
+		}
+		m.dashboard.mrCounts, m.dashboard.mrsLoading, m.dashboard.mrsLoaded = msg.counts, false, true
+		// The forge is reachable if any project answered
+		var err error
+		for _, c := range msg.counts {
+			if err = c.err; err == nil {
+				break
+			}
+		}
+		return m.trackOffline(err)
+
+	case dashboardPipelinesMsg:
+		if msg.gen != m.backgroundPollGen {
+			return nil
+		}
+		m.dashboard.pipelines, m.dashboard.pipelinesErr = msg.pipelines, msg.err
+		m.dashboard.pipelinesLoading, m.dashboard.pipelinesLoaded = false, true
+		if errors.Is(msg.err, errRunningPipelinesUnsupported) {
+			return nil
+		}
+		return m.trackOffline(msg.err)
+
+	case dashboardReleasesMsg:
+		if msg.gen != m.backgroundPollGen {
+			return nil
+		}
+		m.dashboard.releases, m.dashboard.releasesErr, m.dashboard.backMerges = msg.releases, msg.err, msg.backMerges
+		m.dashboard.failures = msg.failures
+		m.dashboard.releasesLoading, m.dashboard.releasesLoaded = false, true
+
+	case dashboardActivityMsg:
+		if msg.gen != m.backgroundPollGen {
+			return nil
+		}
+		m.dashboard.activity, m.dashboard.activityErr, m.dashboard.activityEnabled = msg.activity, msg.err, msg.enabled
+		m.dashboard.activityLoading, m.dashboard.activityLoaded = false, true
+	}
+	return nil
+}
+
+// renderDashboard lays the widgets out in two columns, or one on narrow screens
+func (m model) renderDashboard(width int) string {
+	widgets := []string{
+		m.renderDashboardWidget("Open MRs", m.dashboard.mrsLoading, m.dashboardMRLines()),
+		m.renderDashboardWidget("Running pipelines", m.dashboard.pipelinesLoading, m.dashboardPipelineLines()),
+		m.renderDashboardWidget("Latest releases", m.dashboard.releasesLoading, m.dashboardReleaseLines()),
+		m.renderDashboardWidget("Scheduled releases", false, m.dashboardScheduleLines()),
+		m.renderDashboardWidget("Failures", m.dashboard.releasesLoading, m.dashboardFailureLines()),
+	}
+	if m.dashboard.activityEnabled {
+		widgets = append(widgets, m.renderDashboardWidget("Team activity", m.dashboard.activityLoading, m.dashboardActivityLines()))
+	}
+
+	width = min(width, dashboardMaxWidth)
+	columns := 2
+	if width < 2*dashboardWidgetMinWidth+1 {
+		columns = 1
+	}
+	widgetWidth := (width - (columns - 1)) / columns
+
+	var rows []string
+	for i := 0; i < len(widgets); i += columns {
+		var row []string
+		height := 0
+		for _, w := range widgets[i:min(i+columns, len(widgets))] {
+			height = max(height, lipgloss.Height(lipgloss.NewStyle().Width(widgetWidth-4).Render(w)))
+		}
+		for j, w := range widgets[i:min(i+columns, len(widgets))] {
+			if j > 0 {
+				row = append(row, " ")
+			}
+			row = append(row, lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(currentTheme.Notion).
+				Padding(0, 1).
+				Width(widgetWidth-2).
+				Height(height).
+				Render(w))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderDashboardWidget renders a widget title, with a spinner while it loads, above its lines
+func (m model) renderDashboardWidget(title string, loading bool, lines []string) string {
+	header := homeMenuKeyStyle.Render(title)
+	if loading {
+		header += " " + homeVersionStyle.Render(m.spinner.View())
+	}
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+// dashboardMRLines lists the open MR counts per project
+func (m model) dashboardMRLines() []string {
+	if len(m.dashboardProjects()) == 0 {
+		return []string{homeVersionStyle.Render("Pin projects in the project selector (C+t)")}
+	}
+	if !m.dashboard.mrsLoaded {
+		return []string{homeVersionStyle.Render("Loading…")}
+	}
+	var lines []string
+	for _, c := range m.dashboard.mrCounts {
+		count := fmt.Sprintf("%d", c.count)
+		if c.more {
+			count += "+"
+		}
+		if c.err != nil {
+			count = "unavailable"
+			if isNetworkError(c.err) {
+				count = "unreachable"
+			}
+		}
+		lines = append(lines, homeMenuItemStyle.Render(c.project.NameWithNamespace)+" "+homeVersionStyle.Render(count))
+	}
+	return lines
+}
+
+// dashboardPipelineLines lists the running pipelines, newest first
+func (m model) dashboardPipelineLines() []string {
+	switch {
+	case len(m.dashboardProjects()) == 0:
+		return []string{homeVersionStyle.Render("Pin projects in the project selector (C+t)")}
+	case !m.dashboard.pipelinesLoaded:
+		return []string{homeVersionStyle.Render("Loading…")}
+	case errors.Is(m.dashboard.pipelinesErr, errRunningPipelinesUnsupported):
+		return []string{homeVersionStyle.Render("Not listed by " + forgeName(*m.creds))}
+	case m.dashboard.pipelinesErr != nil && isNetworkError(m.dashboard.pipelinesErr):
+		return []string{homeVersionStyle.Render("Unreachable")}
+	case m.dashboard.pipelinesErr != nil:
+		return []string{settingsErrorStyle.Render(m.dashboard.pipelinesErr.Error())}
+	case len(m.dashboard.pipelines) == 0:
+		return []string{homeVersionStyle.Render("None running")}
+	}
+
+	pipelines := append([]projectPipeline(nil), m.dashboard.pipelines...)
+	sort.SliceStable(pipelines, func(i, j int) bool { return pipelines[i].pipeline.ID > pipelines[j].pipeline.ID })
+	var lines []string
+	for _, p := range pipelines[:min(len(pipelines), dashboardMaxPipelines)] {
+		line := homeMenuItemStyle.Render(fmt.Sprintf("#%d", p.pipeline.ID))
+		if len(m.dashboardProjects()) > 1 {
+			line += " " + homeVersionStyle.Render(p.project.Name)
+		}
+		if p.pipeline.Ref != "" {
+			line += " " + homeMenuItemStyle.Render(p.pipeline.Ref)
+		}
+		lines = append(lines, line)
+	}
+	if rest := len(pipelines) - dashboardMaxPipelines; rest > 0 {
+		lines = append(lines, homeVersionStyle.Render(fmt.Sprintf("and %d more", rest)))
+	}
+	return lines
+}
+
+// dashboardReleaseLines lists the latest completed release of each environment
+func (m model) dashboardReleaseLines() []string {
+	if !m.dashboard.releasesLoaded {
+		return []string{homeVersionStyle.Render("Loading…")}
+	}
+	if m.dashboard.releasesErr != nil {
+		return []string{settingsErrorStyle.Render(m.dashboard.releasesErr.Error())}
+	}
+
+	nameWidth := 0
+	for _, env := range m.environments {
+		nameWidth = max(nameWidth, len(env.Name))
+	}
+	var lines []string
+	for _, env := range m.environments {
+		name := getEnvBranchStyle(env.Name).Render(fmt.Sprintf("%-*s", nameWidth, strings.ToUpper(env.Name)))
+		release, ok := m.dashboard.releases[strings.ToLower(env.Name)]
+		if !ok {
+			lines = append(lines, name+" "+homeVersionStyle.Render("never released"))
+			continue
+		}
+		lines = append(lines, name+" "+homeMenuItemStyle.Render(release.Tag)+" "+homeVersionStyle.Render(humanize.Time(release.DateTime)))
+	}
+	return append(lines, m.dashboardBackMergeLines()...)
+}
+
+// dashboardActivityLines lists the latest releases of the team: the user's own dimmed, those whose
+// annotation mentions the user highlighted
+func (m model) dashboardActivityLines() []string {
+	switch {
+	case m.dashboard.activityErr != nil && isNetworkError(m.dashboard.activityErr):
+		return []string{homeVersionStyle.Render("Unreachable")}
+	case m.dashboard.activityErr != nil:
+		return []string{settingsErrorStyle.Render(m.dashboard.activityErr.Error())}
+	case len(m.dashboard.activity) == 0:
+		return []string{homeVersionStyle.Render("No releases yet")}
+	}
+
+	account := ""
+	if m.creds != nil {
+		account = m.creds.Email
+	}
+	mention := lipgloss.NewStyle().Foreground(currentTheme.Accent).Bold(true)
+	var lines []string
+	for _, a := range m.dashboard.activity {
+		switch {
+		case a.mentions(account):
+			lines = append(lines, mention.Render("@ "+a.text()))
+		case account != "" && strings.EqualFold(a.ReleasedBy, account):
+			lines = append(lines, homeVersionStyle.Render(a.text()))
+		default:
+			lines = append(lines, homeMenuItemStyle.Render(a.text()))
+		}
+	}
+	return lines
+}
+
+// dashboardScheduleLines lists the next release windows
+func (m model) dashboardScheduleLines() []string {
+	upcoming := upcomingReleases(m.releaseWindows, time.Now(), 1, 3)
+	if len(upcoming) == 0 {
+		return []string{homeVersionStyle.Render("No release windows configured")}
+	}
+
+	nameWidth := 0
+	for _, r := range upcoming {
+		nameWidth = max(nameWidth, len(r.Environment))
+	}
+	var lines []string
+	for _, r := range upcoming {
+		relative := humanize.Time(r.Start)
+		if !r.Start.After(time.Now()) {
+			relative = "now"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			getEnvBranchStyle(r.Environment).Render(fmt.Sprintf("%-*s", nameWidth, strings.ToUpper(r.Environment))),
+			homeMenuItemStyle.Render(formatDateTime(r.Start)),
+			homeVersionStyle.Render("("+relative+")")))
+	}
+	return lines
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Demo mode: "relix --demo" runs the whole app against an embedded fake GitLab (see
+// demo_forge.go) serving canned projects, MRs and pipelines, and a throwaway git fixture: a bare
+// origin per project and a clone of it with the environment branches and a branch per MR. Config,
+// credentials and release history go to a temporary home, so evaluating relix touches neither the
+// user's settings nor a real forge. "--fixture <file>" reads the projects and MRs from a JSON file
+// instead of the built-in ones, for scripted end-to-end runs.
+
+// demoEmail is the account of the demo credentials
+const demoEmail = "demo@relix.local"
+
+// demoFixture describes the projects of the fake forge
+type demoFixture struct {
+	Projects []demoProject `json:"projects"`
+}
+
+// demoProject is a project of the fixture with its open MRs
+type demoProject struct {
+	ID        int               `json:"id"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Files     map[string]string `json:"files,omitempty"` // Content of the base branch (default a README)
+	MRs       []demoMR          `json:"merge_requests"`
+}
+
+// demoMR is an open MR of the fixture. Its branch is created off the base branch with the files
+// it changes committed on it.
+type demoMR struct {
+	IID          int               `json:"iid"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description,omitempty"`
+	Author       string            `json:"author"`
+	SourceBranch string            `json:"source_branch"`
+	TargetBranch string            `json:"target_branch,omitempty"` // Default develop
+	Files        map[string]string `json:"files"`
+	Pipeline     string            `json:"pipeline,omitempty"` // Status of its pipeline (default success)
+	Draft        bool              `json:"draft,omitempty"`
+	Discussions  int               `json:"discussions,omitempty"` // Review threads, the first one unresolved
+}
+
+// path returns the path with namespace of the project
+func (p demoProject) path() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// defaultDemoFixture is the fixture of "relix --demo"
+var defaultDemoFixture = demoFixture{Projects: []demoProject{
+	{
+		ID: 1, Namespace: "acme", Name: "shop",
+		Files: map[string]string{
+			"README.md":      "# Shop\n\nThe storefront of Acme.\n",
+			"src/cart.txt":   "cart: empty\n",
+			"src/prices.txt": "apple: 1.00\npear: 1.20\n",
+			".gitlab-ci.yml": "test:\n  script: make test\n",
+		},
+		MRs: []demoMR{
+			{IID: 12, Title: "Add discount codes to the cart", Author: "alice", SourceBranch: "feature/discount-codes",
+				Description: "Customers can enter a **discount code** at checkout.\n\n- Validates codes\n- Shows the saving in the cart",
+				Files:       map[string]string{"src/cart.txt": "cart: empty\ndiscounts: enabled\n"}, Discussions: 2},
+			{IID: 15, Title: "Raise pear prices", Author: "bob", SourceBranch: "feature/pear-prices",
+				Description: "Pears cost more this season.",
+				Files:       map[string]string{"src/prices.txt": "apple: 1.00\npear: 1.45\n"}},
+			{IID: 17, Title: "Draft: Redesign the checkout page", Author: "carol", SourceBranch: "feature/checkout-redesign",
+				Description: "Work in progress.", Draft: true, Pipeline: "running",
+				Files: map[string]string{"src/checkout.txt": "layout: two columns\n"}},
+			{IID: 18, Title: "Fix the cart total rounding", Author: "alice", SourceBranch: "fix/cart-rounding",
+				Description: "Totals are rounded to cents once, at the end.", Pipeline: "failed",
+				Files: map[string]string{"src/rounding.txt": "round: once\n"}},
+		},
+	},
+	{
+		ID: 2, Namespace: "acme", Name: "blog",
+		MRs: []demoMR{
+			{IID: 3, Title: "Add an RSS feed", Author: "dave", SourceBranch: "feature/rss",
+				Description: "Readers can follow the blog in their feed reader.",
+				Files:       map[string]string{"feed.xml": "<rss version=\"2.0\"></rss>\n"}},
+		},
+	},
+}}
+
+// loadDemoFixture reads a fixture file, or returns the built-in fixture without one
+func loadDemoFixture(path string) (*demoFixture, error) {
+	if path == "" {
+		fixture := defaultDemoFixture
+		return &fixture, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture demoFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(fixture.Projects) == 0 {
+		return nil, fmt.Errorf("%s: no projects", path)
+	}
+	for i, p := range fixture.Projects {
+		if p.ID == 0 || p.Name == "" {
+			return nil, fmt.Errorf("%s: project %d needs an id and a name", path, i+1)
+		}
+		for _, mr := range p.MRs {
+			if mr.IID == 0 || mr.SourceBranch == "" {
+				return nil, fmt.Errorf("%s: MRs of %s need an iid and a source_branch", path, p.Name)
+			}
+		}
+	}
+	return &fixture, nil
+}
+
+// startDemo sets up demo mode: the temporary home with its config and credentials, the git
+// fixture and the fake forge. It returns the function removing all of it.
+func startDemo(fixturePath string) (func(), error) {
+	fixture, err := loadDemoFixture(fixturePath)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "relix-demo-")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(home, 0o755); err != nil {
+		cleanup()
+		return nil, err
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	baseURL := "http://" + listener.Addr().String()
+	server := &http.Server{Handler: newDemoForge(fixture, baseURL)}
+	go server.Serve(listener)
+	stop := func() {
+		server.Close()
+		cleanup()
+	}
+
+	projectDirs := make(map[string]string)
+	for _, p := range fixture.Projects {
+		clone, err := createDemoRepository(filepath.Join(dir, "repos", p.Namespace), p)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("git fixture of %s: %w", p.path(), err)
+		}
+		projectDirs[p.path()] = clone
+	}
+
+	config, _ := LoadConfig()
+	config.KeyringBackend = keyringFile
+	config.ProjectDirs = projectDirs
+	config.DisableUpdateCheck = true
+	config.DisableProjectDetection = true
+	first := fixture.Projects[0]
+	config.SelectedProjectID = first.ID
+	config.SelectedProjectShortName = first.Name
+	config.SelectedProjectPath = first.path()
+	config.SelectedProjectName = first.Namespace + " / " + first.Name
+	if err := SaveConfig(config); err != nil {
+		stop()
+		return nil, err
+	}
+	if err := SaveCredentials(Credentials{GitLabURL: baseURL, Email: demoEmail, Token: "demo"}); err != nil {
+		stop()
+		return nil, err
+	}
+	projectDirectory = projectDirs[first.path()]
+	return stop, nil
+}
+
+// runDemoGit runs a git command of the fixture in dir
+func runDemoGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=relix demo", "GIT_AUTHOR_EMAIL="+demoEmail,
+		"GIT_COMMITTER_NAME=relix demo", "GIT_COMMITTER_EMAIL="+demoEmail)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeDemoFiles writes files into a clone and commits them
+func writeDemoFiles(clone string, files map[string]string, message string) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		full := filepath.Join(clone, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, []byte(files[path]), 0o644); err != nil {
+			return err
+		}
+	}
+	if err := runDemoGit(clone, "add", "-A"); err != nil {
+		return err
+	}
+	return runDemoGit(clone, "commit", "-q", "--allow-empty", "-m", message)
+}
+
+// createDemoRepository creates the bare origin of a project and a clone of it with the base and
+// environment branches and a branch per MR, and returns the clone
+func createDemoRepository(dir string, p demoProject) (string, error) {
+	origin := filepath.Join(dir, p.Name+".git")
+	clone := filepath.Join(dir, p.Name)
+	if err := os.MkdirAll(origin, 0o755); err != nil {
+		return "", err
+	}
+	steps := [][]string{
+		{"init", "-q", "--bare", origin},
+		// Releases push with GitLab CI variables as push options
+		{"-C", origin, "config", "receive.advertisePushOptions", "true"},
+		{"clone", "-q", origin, clone},
+	}
+	for _, args := range steps {
+		if err := runDemoGit(dir, args...); err != nil {
+			return "", err
+		}
+	}
+	for _, args := range [][]string{
+		{"config", "user.name", "relix demo"},
+		{"config", "user.email", demoEmail},
+		{"checkout", "-q", "-b", "root"},
+	} {
+		if err := runDemoGit(clone, args...); err != nil {
+			return "", err
+		}
+	}
+
+	files := p.Files
+	if len(files) == 0 {
+		files = map[string]string{"README.md": "# " + p.Name + "\n"}
+	}
+	if err := writeDemoFiles(clone, files, "Initial commit"); err != nil {
+		return "", err
+	}
+	branches := []string{"root"}
+	for _, env := range defaultEnvironments() {
+		if err := runDemoGit(clone, "branch", env.BranchName); err != nil {
+			return "", err
+		}
+		branches = append(branches, env.BranchName)
+	}
+	for _, mr := range p.MRs {
+		if err := runDemoGit(clone, "checkout", "-q", "-b", mr.SourceBranch, "root"); err != nil {
+			return "", err
+		}
+		if err := writeDemoFiles(clone, mr.Files, mr.Title); err != nil {
+			return "", err
+		}
+		branches = append(branches, mr.SourceBranch)
+	}
+	if err := runDemoGit(clone, "checkout", "-q", "root"); err != nil {
+		return "", err
+	}
+	if err := runDemoGit(clone, append([]string{"push", "-q", "-u", "origin"}, branches...)...); err != nil {
+		return "", err
+	}
+	return clone, nil
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The fake GitLab of demo mode serves the endpoints of the GitLab API relix uses from the fixture.
+// MRs the release creates are kept in memory and merged by the fake forge shortly after they are
+// created, with a pipeline that succeeds a little later, so a demo release runs to the end without
+// anyone merging on a forge. Web links of projects and MRs open a placeholder page.
+
+const (
+	demoMergeDelay    = 5 * time.Second  // Until a created MR is merged
+	demoPipelineDelay = 10 * time.Second // Until the pipeline of a merged MR succeeds
+)
+
+// demoForge is the HTTP handler of the fake GitLab
+type demoForge struct {
+	baseURL  string
+	projects []demoProject
+
+	mu      sync.Mutex
+	created map[int][]*demoCreatedMR // Release MRs by project ID
+	nextID  int                      // ID of the next created MR or note
+}
+
+// demoCreatedMR is an MR created through the fake forge
+type demoCreatedMR struct {
+	MergeRequest
+	createdAt time.Time
+}
+
+// newDemoForge creates the fake GitLab serving a fixture at baseURL
+func newDemoForge(fixture *demoFixture, baseURL string) *demoForge {
+	return &demoForge{
+		baseURL:  baseURL,
+		projects: fixture.Projects,
+		created:  make(map[int][]*demoCreatedMR),
+		nextID:   1000,
+	}
+}
+
+// ServeHTTP routes a request of the GitLab API
+func (f *demoForge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, ok := strings.CutPrefix(r.URL.EscapedPath(), "/api/v4/")
+	if !ok {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<h1>relix demo</h1><p>%s is served by the fake GitLab of relix --demo.</p>", r.URL.Path)
+		return
+	}
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	for i, part := range parts {
+		if unescaped, err := neturl.PathUnescape(part); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	switch {
+	case route == "user/emails":
+		writeDemoJSON(w, http.StatusOK, []map[string]string{{"email": demoEmail}})
+	case route == "personal_access_tokens/self":
+		writeDemoJSON(w, http.StatusOK, map[string]interface{}{"expires_at": nil})
+	case route == "merge_requests":
+		var mrs []MergeRequest
+		for _, p := range f.projects {
+			mrs = append(mrs, f.projectMRs(p, "opened")...)
+		}
+		writeDemoList(w, mrs)
+	case route == "projects":
+		projects := make([]Project, len(f.projects))
+		for i, p := range f.projects {
+			projects[i] = f.project(p)
+		}
+		writeDemoList(w, projects)
+	case parts[0] == "projects" && len(parts) >= 2:
+		p, ok := f.findProject(parts[1])
+		if !ok {
+			writeDemoJSON(w, http.StatusNotFound, map[string]string{"message": "404 Project Not Found"})
+			return
+		}
+		f.serveProject(w, r, p, parts[2:])
+	default:
+		writeDemoJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not Found"})
+	}
+}
+
+// serveProject serves the endpoints below a project
+func (f *demoForge) serveProject(w http.ResponseWriter, r *http.Request, p demoProject, parts []string) {
+	query := r.URL.Query()
+	resource := strings.Join(parts, "/")
+	switch {
+	case resource == "":
+		project := f.project(p)
+		writeDemoJSON(w, http.StatusOK, map[string]interface{}{
+			"id": project.ID, "name": project.Name, "path": project.Path, "path_with_namespace": project.PathWithNamespace,
+			"name_with_namespace": project.NameWithNamespace, "web_url": project.WebURL, "default_branch": "root",
+		})
+	case resource == "merge_requests" && r.Method == http.MethodPost:
+		f.createMR(w, r, p)
+	case resource == "merge_requests":
+		state := query.Get("state")
+		if state == "" || query.Has("updated_after") {
+			state = "all"
+		}
+		mrs := f.projectMRs(p, state)
+		if branch := query.Get("source_branch"); branch != "" {
+			var matching []MergeRequest
+			for _, mr := range mrs {
+				if mr.SourceBranch == branch {
+					matching = append(matching, mr)
+				}
+			}
+			mrs = matching
+		}
+		writeDemoList(w, mrs)
+	case parts[0] == "merge_requests" && len(parts) >= 2:
+		iid, _ := strconv.Atoi(parts[1])
+		mr, ok := f.findMR(p, iid)
+		if !ok {
+			writeDemoJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+			return
+		}
+		f.serveMR(w, r, p, mr, parts[2:])
+	case parts[0] == "pipelines" && len(parts) == 1:
+		// Pipelines of merged release MRs by their merge commit; none are running
+		var pipelines []Pipeline
+		if sha := query.Get("sha"); sha != "" {
+			for _, mr := range f.projectMRs(p, "merged") {
+				if mr.MergeCommitSHA == sha {
+					pipelines = append(pipelines, f.pipeline(p, mr))
+				}
+			}
+		}
+		writeDemoList(w, pipelines)
+	case parts[0] == "pipelines" && len(parts) == 3 && parts[2] == "jobs":
+		id, _ := strconv.Atoi(parts[1])
+		writeDemoJSON(w, http.StatusOK, f.jobs(p, id))
+	case resource == "deployments" && r.Method == http.MethodPost:
+		writeDemoJSON(w, http.StatusCreated, Deployment{ID: f.newID(), Status: "success", CreatedAt: time.Now()})
+	case resource == "milestones":
+		// Every milestone asked for exists, so bulk milestone edits succeed
+		writeDemoList(w, []map[string]interface{}{{"id": p.ID*100 + 1, "title": query.Get("title")}})
+	case resource == "deployments", resource == "repository/tags":
+		writeDemoList(w, []interface{}{})
+	default:
+		// Repository files (.restitcher.yaml, hook scripts) and everything else are not found
+		writeDemoJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+	}
+}
+
+// serveMR serves the endpoints below an MR
+func (f *demoForge) serveMR(w http.ResponseWriter, r *http.Request, p demoProject, mr MergeRequest, parts []string) {
+	resource := strings.Join(parts, "/")
+	switch {
+	case resource == "" && r.Method == http.MethodPut:
+		var update struct {
+			Title string `json:"title"`
+		}
+		json.NewDecoder(r.Body).Decode(&update)
+		if update.Title != "" {
+			mr.Title = update.Title
+		}
+		writeDemoJSON(w, http.StatusOK, mr)
+	case resource == "":
+		pipeline := f.pipeline(p, mr)
+		details := gitlabMergeRequest{MergeRequest: mr, DivergedCommitsCount: new(int), HeadPipeline: &pipeline}
+		writeDemoJSON(w, http.StatusOK, details)
+	case resource == "commits":
+		writeDemoJSON(w, http.StatusOK, []map[string]string{{"id": mr.SHA, "title": mr.Title}})
+	case resource == "discussions":
+		writeDemoJSON(w, http.StatusOK, f.discussions(p, mr.IID))
+	case resource == "diffs":
+		var diffs []map[string]string
+		if fixture, ok := f.fixtureMR(p, mr.IID); ok {
+			for path := range fixture.Files {
+				diffs = append(diffs, map[string]string{"new_path": path, "old_path": path})
+			}
+		}
+		writeDemoList(w, diffs)
+	case resource == "pipelines":
+		writeDemoJSON(w, http.StatusOK, []Pipeline{f.pipeline(p, mr)})
+	case resource == "notes" && r.Method == http.MethodPost:
+		writeDemoJSON(w, http.StatusCreated, map[string]int{"id": f.newID()})
+	case parts[0] == "notes" && r.Method == http.MethodPut:
+		writeDemoJSON(w, http.StatusOK, map[string]string{})
+	case resource == "notes", resource == "award_emoji":
+		writeDemoList(w, []interface{}{})
+	case resource == "approve":
+		writeDemoJSON(w, http.StatusCreated, map[string]string{})
+	case resource == "approvals":
+		writeDemoJSON(w, http.StatusOK, gitlabApprovals{})
+	case resource == "rebase":
+		writeDemoJSON(w, http.StatusAccepted, map[string]bool{"rebase_in_progress": true})
+	default:
+		writeDemoJSON(w, http.StatusNotFound, map[string]string{"message": "404 Not found"})
+	}
+}
+
+// createMR creates a release MR
+func (f *demoForge) createMR(w http.ResponseWriter, r *http.Request, p demoProject) {
+	var payload struct {
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.SourceBranch == "" || payload.TargetBranch == "" {
+		writeDemoJSON(w, http.StatusBadRequest, map[string]string{"message": "source_branch and target_branch are required"})
+		return
+	}
+
+	f.mu.Lock()
+	iid := 100 + len(f.created[p.ID]) + 1
+	for _, mr := range p.MRs {
+		iid = max(iid, mr.IID+1)
+	}
+	f.nextID++
+	mr := &demoCreatedMR{createdAt: time.Now()}
+	mr.ID, mr.IID = f.nextID, iid
+	mr.Title, mr.Description = payload.Title, payload.Description
+	mr.SourceBranch, mr.TargetBranch = payload.SourceBranch, payload.TargetBranch
+	mr.Author.Username, mr.Author.Name = "demo", "relix demo"
+	f.created[p.ID] = append(f.created[p.ID], mr)
+	f.mu.Unlock()
+
+	writeDemoJSON(w, http.StatusCreated, f.createdState(p, mr))
+}
+
+// createdState returns a created MR as it is now: open until demoMergeDelay passed, then merged
+func (f *demoForge) createdState(p demoProject, created *demoCreatedMR) MergeRequest {
+	mr := created.MergeRequest
+	mr.CreatedAt, mr.UpdatedAt = created.createdAt, created.createdAt
+	mr.WebURL = fmt.Sprintf("%s/%s/-/merge_requests/%d", f.baseURL, p.path(), mr.IID)
+	mr.SHA = demoSHA(p.ID, mr.IID, "head")
+	mr.State, mr.MergeStatus = "opened", "can_be_merged"
+	if time.Since(created.createdAt) >= demoMergeDelay {
+		mr.State = "merged"
+		mr.UpdatedAt = created.createdAt.Add(demoMergeDelay)
+		mr.MergeCommitSHA = demoSHA(p.ID, mr.IID, "merge")
+	}
+	return mr
+}
+
+// projectMRs returns the MRs of a project in a state ("opened", "merged" or "all"), the open
+// fixture MRs first
+func (f *demoForge) projectMRs(p demoProject, state string) []MergeRequest {
+	var mrs []MergeRequest
+	if state != "merged" {
+		for _, fixture := range p.MRs {
+			mrs = append(mrs, f.fixtureState(p, fixture))
+		}
+	}
+	f.mu.Lock()
+	created := f.created[p.ID]
+	f.mu.Unlock()
+	for i := len(created) - 1; i >= 0; i-- {
+		if mr := f.createdState(p, created[i]); state == "all" || mr.State == state {
+			mrs = append(mrs, mr)
+		}
+	}
+	return mrs
+}
+
+// fixtureState returns an MR of the fixture as the GitLab API reports it
+func (f *demoForge) fixtureState(p demoProject, fixture demoMR) MergeRequest {
+	var mr MergeRequest
+	mr.ID, mr.IID = p.ID*1000+fixture.IID, fixture.IID
+	mr.Title, mr.Description, mr.Draft = fixture.Title, fixture.Description, fixture.Draft
+	mr.SourceBranch, mr.TargetBranch = fixture.SourceBranch, fixture.TargetBranch
+	if mr.TargetBranch == "" {
+		mr.TargetBranch = "develop"
+	}
+	mr.State, mr.MergeStatus = "opened", "can_be_merged"
+	mr.Author.ID, mr.Author.Username, mr.Author.Name = fixture.IID, fixture.Author, fixture.Author
+	mr.WebURL = fmt.Sprintf("%s/%s/-/merge_requests/%d", f.baseURL, p.path(), fixture.IID)
+	mr.ChangesCount = strconv.Itoa(len(fixture.Files))
+	mr.UserNotesCount = fixture.Discussions
+	mr.BlockingDiscussionsResolved = fixture.Discussions == 0
+	mr.SHA = demoSHA(p.ID, fixture.IID, "head")
+	// Spread over the last days, so the list has an order
+	mr.CreatedAt = time.Now().Add(-time.Duration(fixture.IID) * 7 * time.Hour).Truncate(time.Second)
+	mr.UpdatedAt = mr.CreatedAt.Add(time.Hour)
+	return mr
+}
+
+// findProject finds a project by ID or path
+func (f *demoForge) findProject(id string) (demoProject, bool) {
+	for _, p := range f.projects {
+		if strconv.Itoa(p.ID) == id || p.path() == id {
+			return p, true
+		}
+	}
+	return demoProject{}, false
+}
+
+// fixtureMR finds an MR of the fixture
+func (f *demoForge) fixtureMR(p demoProject, iid int) (demoMR, bool) {
+	for _, mr := range p.MRs {
+		if mr.IID == iid {
+			return mr, true
+		}
+	}
+	return demoMR{}, false
+}
+
+// findMR finds an MR of the fixture or a created one
+func (f *demoForge) findMR(p demoProject, iid int) (MergeRequest, bool) {
+	for _, mr := range f.projectMRs(p, "all") {
+		if mr.IID == iid {
+			return mr, true
+		}
+	}
+	return MergeRequest{}, false
+}
+
+// project returns a project of the fixture as the GitLab API reports it
+func (f *demoForge) project(p demoProject) Project {
+	return Project{
+		ID:                p.ID,
+		Name:              p.Name,
+		NameWithNamespace: p.Namespace + " / " + p.Name,
+		Path:              p.Name,
+		PathWithNamespace: p.path(),
+		WebURL:            f.baseURL + "/" + p.path(),
+	}
+}
+
+// pipeline returns the pipeline of an MR: the status set in the fixture for its MRs, running
+// until demoPipelineDelay after the merge for created ones
+func (f *demoForge) pipeline(p demoProject, mr MergeRequest) Pipeline {
+	status := "success"
+	if fixture, ok := f.fixtureMR(p, mr.IID); ok {
+		if fixture.Pipeline != "" {
+			status = fixture.Pipeline
+		}
+	} else if mr.State != "merged" || time.Since(mr.UpdatedAt) < demoPipelineDelay {
+		status = "running"
+	}
+	id := p.ID*100000 + mr.IID
+	return Pipeline{ID: id, Status: status, Ref: mr.SourceBranch, WebURL: fmt.Sprintf("%s/%s/-/pipelines/%d", f.baseURL, p.path(), id)}
+}
+
+// jobs returns the jobs of a pipeline, all in the state of the pipeline
+func (f *demoForge) jobs(p demoProject, pipelineID int) []PipelineJob {
+	mr, ok := f.findMR(p, pipelineID-p.ID*100000)
+	if !ok {
+		return []PipelineJob{}
+	}
+	status := f.pipeline(p, mr).Status
+	var jobs []PipelineJob
+	for i, job := range []struct{ name, stage string }{{"build", "build"}, {"test", "test"}, {"deploy", "deploy"}} {
+		id := pipelineID*10 + i
+		jobs = append(jobs, PipelineJob{ID: id, Name: job.name, Stage: job.stage, Status: status,
+			WebURL: fmt.Sprintf("%s/%s/-/jobs/%d", f.baseURL, p.path(), id)})
+	}
+	return jobs
+}
+
+// discussions returns the review threads of an MR, the first one unresolved
+func (f *demoForge) discussions(p demoProject, iid int) []interface{} {
+	fixture, _ := f.fixtureMR(p, iid)
+	threads := make([]interface{}, fixture.Discussions)
+	for i := range threads {
+		threads[i] = map[string]interface{}{
+			"id":    fmt.Sprintf("demo-%d-%d", iid, i),
+			"notes": []map[string]interface{}{{"body": "Looks good", "resolvable": true, "resolved": i > 0}},
+		}
+	}
+	return threads
+}
+
+// newID returns a new ID for a created MR, note or deployment
+func (f *demoForge) newID() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	return f.nextID
+}
+
+// demoSHA returns a stable fake commit SHA
+func demoSHA(projectID, iid int, kind string) string {
+	return fmt.Sprintf("%040x", fmt.Sprintf("%d-%d-%s", projectID, iid, kind))[:40]
+}
+
+// writeDemoJSON writes a JSON response
+func writeDemoJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeDemoList writes a list as a single page with its X-Total header
+func writeDemoList[T any](w http.ResponseWriter, items []T) {
+	if items == nil {
+		items = []T{}
+	}
+	w.Header().Set("X-Total", strconv.Itoa(len(items)))
+	writeDemoJSON(w, http.StatusOK, items)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/exp/golden"
+	"github.com/muesli/termenv"
+)
+
+// demoTestModel wraps the model of a demo run and sends its view after every message, so a test
+// can wait for a screen
+type demoTestModel struct {
+	model tea.Model
+	views chan string
+}
+
+func (d demoTestModel) Init() tea.Cmd {
+	return d.model.Init()
+}
+
+func (d demoTestModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := d.model.Update(msg)
+	d.model = next
+	select {
+	case d.views <- ansi.Strip(next.View()):
+	default: // The test is not waiting for this view
+	}
+	return d, cmd
+}
+
+func (d demoTestModel) View() string {
+	return d.model.View()
+}
+
+// demoRun is relix running in demo mode without a terminal
+type demoRun struct {
+	t       *testing.T
+	program *tea.Program
+	views   chan string
+	done    chan struct{}
+}
+
+// startDemoRun starts relix in demo mode with the built-in fixture in a terminal of width x height
+func startDemoRun(t *testing.T, width, height int) *demoRun {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", "")
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	directory := projectDirectory
+	t.Cleanup(func() { projectDirectory = directory })
+	stop, err := startDemo("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	rebuildStyles()
+
+	run := &demoRun{t: t, views: make(chan string), done: make(chan struct{})}
+	run.program = tea.NewProgram(demoTestModel{model: NewModel(), views: run.views},
+		tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	go func() {
+		defer close(run.done)
+		run.program.Run()
+	}()
+	t.Cleanup(func() {
+		run.program.Quit()
+		<-run.done
+	})
+	run.program.Send(setProgramMsg{program: run.program})
+	run.program.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	return run
+}
+
+// waitFor waits for a view containing all of texts and returns it
+func (r *demoRun) waitFor(texts ...string) string {
+	r.t.Helper()
+	timeout := time.After(10 * time.Second)
+	var last string
+	for {
+		select {
+		case view := <-r.views:
+			last = view
+			found := true
+			for _, text := range texts {
+				found = found && strings.Contains(view, text)
+			}
+			if found {
+				return view
+			}
+		case <-timeout:
+			r.t.Fatalf("no view shows %q, the last one:\n%s", texts, last)
+		}
+	}
+}
+
+// press sends keys to relix
+func (r *demoRun) press(keys ...string) {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "space":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		}
+		r.program.Send(msg)
+	}
+}
+
+// demoDate matches the dates of the fake forge, which are relative to now
+var demoDate = regexp.MustCompile(`\d{2}\.\d{2}\.\d{4} \d{2}:\d{2}`)
+
+// requireDemoView compares a view with its golden file (go test -run TestDemo -update rewrites them)
+func requireDemoView(t *testing.T, view string) {
+	t.Helper()
+	golden.RequireEqual(t, []byte(demoDate.ReplaceAllString(view, "DD.MM.YYYY hh:mm")))
+}
+
+func TestDemoRelease(t *testing.T) {
+	run := startDemoRun(t, 120, 40)
+	view := run.waitFor("acme / shop 4", "None running", "No aborted releases")
+	t.Run("home", func(t *testing.T) { requireDemoView(t, view) })
+
+	run.press("r")
+	view = run.waitFor("Open MRs (4)", "Pipeline: success", "Shows the saving in the cart")
+	t.Run("merge requests", func(t *testing.T) { requireDemoView(t, view) })
+
+	// Release the first two MRs to DEVELOP as 1.0.0, with the default squash and root merge
+	run.press("space", "j", "space", "enter")
+	run.waitFor("Select environment to release")
+	run.press("enter")
+	run.waitFor("Suggested: 1.0.0")
+	run.press("tab", "enter")
+	run.waitFor("That is new branch")
+	run.press("enter")
+	run.waitFor("will be merged 2 new commits")
+	run.press("enter")
+	run.waitFor("Yes, merge it")
+	run.press("enter")
+	view = run.waitFor("Impact: 2 files changed", "Release it")
+	t.Run("release plan", func(t *testing.T) { requireDemoView(t, view) })
+
+	run.press("enter")
+	run.waitFor("SUCCESSFULLY COMPOSED", "Create MR to DEVELOP")
+	run.press("enter")
+	run.waitFor("Merge request created", "Push root branches")
+	run.press("enter")
+	run.waitFor("SUCCESSFULLY COMPLETED")
+
+	cmd := exec.Command("git", "ls-remote", "--tags", "origin", "develop-1.0.0-v1")
+	cmd.Dir = projectDirectory
+	if out, err := cmd.Output(); err != nil || !strings.Contains(string(out), "develop-1.0.0-v1") {
+		t.Errorf("the release tag is not pushed: %s %v", out, err)
+	}
+}
+
+func TestLoadDemoFixture(t *testing.T) {
+	fixture, err := loadDemoFixture("")
+	if err != nil || len(fixture.Projects) != len(defaultDemoFixture.Projects) {
+		t.Fatalf("the built-in fixture: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"valid", `{"projects": [{"id": 7, "namespace": "team", "name": "api", "merge_requests": [{"iid": 1, "source_branch": "feature/x"}]}]}`, ""},
+		{"no projects", `{"projects": []}`, "no projects"},
+		{"project without an id", `{"projects": [{"name": "api"}]}`, "project 1 needs an id and a name"},
+		{"MR without a branch", `{"projects": [{"id": 7, "name": "api", "merge_requests": [{"iid": 1}]}]}`, "MRs of api need an iid and a source_branch"},
+		{"not JSON", `projects: []`, "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixture.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadDemoFixture(path)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("error %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
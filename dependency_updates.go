@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Dependency updates: MRs opened by Renovate or Dependabot, recognized by their author or source
+// branch, make up much of routine stitching. They are marked on the MR list, and "D" selects them
+// all as a group, like "i" does for an iteration, after checking in the project's clone that they
+// merge together: starting from the base branch, each update is merged in memory (git merge-tree,
+// git 2.38+) on top of the ones before it. Updates conflicting with the others are left
+// unselected and listed, so the rest can go out while the bot rebases them. Drafts, blocked MRs
+// and MRs the include_mr hook keeps out are left unselected; MRs selected before stay selected.
+
+// dependencyBots are the prefixes of the usernames and source branches of dependency update bots,
+// e.g. "renovate[bot]", "dependabot/npm_and_yarn/lodash-4.17.21"
+var dependencyBots = []string{"renovate", "dependabot"}
+
+// isDependencyUpdate reports whether an MR was opened by a dependency update bot
+func isDependencyUpdate(mr *MergeRequestDetails) bool {
+	user := strings.ToLower(mr.Author.Username)
+	branch := strings.ToLower(mr.SourceBranch)
+	for _, bot := range dependencyBots {
+		if strings.HasPrefix(user, bot) || strings.HasPrefix(branch, bot+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyConflict is a dependency update that does not merge on top of the ones before it
+type dependencyConflict struct {
+	iid    int
+	branch string
+	files  []string // Conflicting files
+	reason string   // Why it could not be merged at all, e.g. a branch of a fork
+}
+
+// dependencyUpdatesMsg carries the dependency updates of a project to select
+type dependencyUpdatesMsg struct {
+	projectID int
+	iids      []int // Updates merging together, in list order
+	conflicts []dependencyConflict
+	checkErr  error // The pre-check could not run; iids are then not checked
+	err       error
+}
+
+// selectDependencyUpdates returns the command picking the listed dependency updates of the
+// project that merge together
+func (m *model) selectDependencyUpdates() tea.Cmd {
+	if m.dependencyLoading || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	var updates []*MergeRequestDetails
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok && !mr.MR().Draft && isDependencyUpdate(mr.MR()) {
+			updates = append(updates, mr.MR())
+		}
+	}
+	if len(updates) == 0 {
+		return m.showToast("No dependency updates are listed")
+	}
+	m.dependencyLoading = true
+	m.updateMRListTitle()
+
+	client := NewForge(*m.creds)
+	project := *m.selectedProject
+	base := getBaseBranch()
+	return func() tea.Msg {
+		msg := dependencyUpdatesMsg{projectID: project.ID}
+		hooks, err := loadScriptHooks(client, project.ID, nil)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		var eligible []*MergeRequestDetails
+		for _, mr := range updates {
+			if _, blocked := blockedMRReason(project.ID, mr.IID); blocked {
+				continue
+			}
+			if ok, _, err := hooks.includeMR(mr); err == nil && !ok {
+				continue
+			}
+			eligible = append(eligible, mr)
+		}
+		if len(eligible) == 0 {
+			msg.err = errors.New("the blocklist or the include_mr hook keeps all dependency updates out")
+			return msg
+		}
+
+		workDir, err := projectWorkDir(&project)
+		if err == nil {
+			msg.iids, msg.conflicts, err = checkCombinedMerge(workDir, base, eligible)
+		}
+		if err != nil {
+			msg.checkErr = err
+			msg.iids = nil
+			for _, mr := range eligible {
+				msg.iids = append(msg.iids, mr.IID)
+			}
+		}
+		return msg
+	}
+}
+
+// checkCombinedMerge merges the MRs one by one on top of the base branch without touching the
+// work tree, and returns those merging cleanly and those that conflict. The merges are commits
+// no ref points to; git gc removes them.
+func checkCombinedMerge(workDir, base string, mrs []*MergeRequestDetails) ([]int, []dependencyConflict, error) {
+	// Explicit refspecs also update the remote-tracking branches of single-branch clones
+	refspec := func(branch string) string {
+		return "+refs/heads/" + branch + ":refs/remotes/origin/" + branch
+	}
+	fetch := func(refspecs ...string) error {
+		cmd := exec.Command("git", append([]string{"fetch", "--quiet", "origin"}, refspecs...)...)
+		cmd.Dir = workDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.New(strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	refspecs := []string{refspec(base)}
+	for _, mr := range mrs {
+		refspecs = append(refspecs, refspec(mr.SourceBranch))
+	}
+	fetchFailed := make(map[int]string)
+	if err := fetch(refspecs...); err != nil {
+		// Fetch one by one to find the branches missing from origin, e.g. of forks
+		if err := fetch(refspec(base)); err != nil {
+			return nil, nil, fmt.Errorf("fetch %s: %w", base, err)
+		}
+		for _, mr := range mrs {
+			if err := fetch(refspec(mr.SourceBranch)); err != nil {
+				fetchFailed[mr.IID] = "cannot be fetched: " + err.Error()
+			}
+		}
+	}
+
+	head, err := gitOutput(workDir, "rev-parse", "--verify", "refs/remotes/origin/"+base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("base branch %s not found in origin", base)
+	}
+
+	var merged []int
+	var conflicts []dependencyConflict
+	for _, mr := range mrs {
+		if reason, failed := fetchFailed[mr.IID]; failed {
+			conflicts = append(conflicts, dependencyConflict{iid: mr.IID, branch: mr.SourceBranch, reason: reason})
+			continue
+		}
+		branch := "refs/remotes/origin/" + mr.SourceBranch
+		cmd := exec.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", head, branch)
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			tree := strings.TrimSpace(string(out))
+			commit, err := gitOutput(workDir, "-c", "user.name=relix", "-c", "user.email=relix@localhost",
+				"commit-tree", tree, "-p", head, "-p", branch, "-m", "relix dependency update pre-check")
+			if err != nil {
+				return nil, nil, fmt.Errorf("git commit-tree: %w", err)
+			}
+			head = commit
+			merged = append(merged, mr.IID)
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			// Conflicts: the tree comes first, the conflicting files follow
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			conflicts = append(conflicts, dependencyConflict{iid: mr.IID, branch: mr.SourceBranch, files: lines[1:]})
+		default:
+			stderr := ""
+			if exitErr != nil {
+				stderr = strings.TrimSpace(string(exitErr.Stderr))
+			}
+			return nil, nil, fmt.Errorf("git merge-tree (git 2.38 or later is needed): %s", stderr)
+		}
+	}
+	return merged, conflicts, nil
+}
+
+// handleDependencyUpdates selects the dependency updates of the listed project and reports those
+// left out
+func (m *model) handleDependencyUpdates(msg dependencyUpdatesMsg) {
+	m.dependencyLoading = false
+	if m.selectedProject == nil || m.selectedProject.ID != msg.projectID {
+		return
+	}
+	if msg.err != nil {
+		m.updateMRListTitle()
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot select the dependency updates: " + msg.err.Error()
+		return
+	}
+	listed := make(map[int]bool, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok {
+			listed[mr.MR().IID] = true
+		}
+	}
+	selected := 0
+	for _, iid := range msg.iids {
+		if listed[iid] {
+			m.selectedMRs[iid] = true
+			selected++
+		}
+	}
+	m.saveSelection()
+
+	m.dependencySelected = fmt.Sprintf("%d MRs", selected)
+	switch {
+	case msg.checkErr != nil:
+		m.dependencySelected += ", not checked"
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Selected %d dependency updates without checking that they merge together: %v", selected, msg.checkErr)
+	case len(msg.conflicts) > 0:
+		m.dependencySelected += fmt.Sprintf(", %d conflicting", len(msg.conflicts))
+		var b strings.Builder
+		fmt.Fprintf(&b, "Selected %d dependency updates. Left out those conflicting with the ones before them:\n", selected)
+		for _, c := range msg.conflicts {
+			detail := c.reason
+			if detail == "" {
+				detail = strings.Join(c.files, ", ")
+			}
+			fmt.Fprintf(&b, "\n!%d %s: %s", c.iid, c.branch, detail)
+		}
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = b.String()
+	}
+	m.updateMRListTitle()
+}
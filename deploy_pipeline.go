@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// A deployment pipeline covers setups where deploying lives in a separate repository: once the
+// release is tagged and pushed, relix starts a pipeline of the deployment project with the release
+// variables and the inputs given before the release (see pipeline_inputs.go), and completes the
+// release only when that pipeline succeeds. A failed, canceled or timed out pipeline fails the
+// step, and retrying it starts a new pipeline. The started pipeline is kept in the release state,
+// so a release resumed after a crash waits for it instead.
+
+// defaultDeployPipelineTimeout bounds the wait for a deployment pipeline without a timeout
+const defaultDeployPipelineTimeout = time.Hour
+
+// deployPipelineStartedMsg reports the pipeline started by the deployment step, to save it
+type deployPipelineStartedMsg struct {
+	pipeline Pipeline
+}
+
+// timeout returns how long the release waits for the pipeline
+func (c DeployPipelineConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultDeployPipelineTimeout
+}
+
+// pipelineFinished reports whether a pipeline with the status will not change any more.
+// Pipelines waiting for a manual job are not finished: the deployment may be approved there.
+func pipelineFinished(status string) bool {
+	switch status {
+	case "success", "failed", "canceled", "skipped":
+		return true
+	}
+	return false
+}
+
+// releaseStepPrinter returns the function printing progress lines of a step waiting on GitLab, to
+// the sender or to output without one, after printing the header as the step's command
+func releaseStepPrinter(sender messageSender, output *strings.Builder, header string) func(string) {
+	if sender != nil {
+		sender.Send(releaseCommandStartMsg{command: header})
+	} else {
+		output.WriteString("$ " + header + "\n")
+	}
+	return func(line string) {
+		if sender != nil {
+			sender.Send(releaseOutputMsg{line: line})
+		} else {
+			output.WriteString(line + "\n")
+		}
+	}
+}
+
+// runDeployPipeline starts the deployment pipeline of a release, or takes the one it started
+// before if that is still running, and waits for it to succeed. Progress goes to the sender, or
+// to the returned output without one.
+func runDeployPipeline(ctx context.Context, creds Credentials, state *ReleaseState, sender messageSender) (string, error) {
+	cfg := state.Environment.DeployPipeline
+	client, ok := NewForge(creds).(*GitLabClient)
+	if !ok {
+		return "", fmt.Errorf("deployment pipelines need GitLab credentials")
+	}
+
+	var output strings.Builder
+	printLine := releaseStepPrinter(sender, &output, "deploy pipeline "+cfg.Project)
+
+	var pipeline *Pipeline
+	if state.DeployPipelineID != 0 {
+		running, err := client.GetPipeline(cfg.Project, state.DeployPipelineID)
+		if err != nil {
+			return output.String(), fmt.Errorf("failed to get deployment pipeline #%d: %w", state.DeployPipelineID, err)
+		}
+		if running.Status == "success" {
+			printLine(fmt.Sprintf("Pipeline #%d already succeeded: %s", running.ID, running.WebURL))
+			return output.String(), nil
+		}
+		if !pipelineFinished(running.Status) {
+			printLine(fmt.Sprintf("Waiting for pipeline #%d started before: %s", running.ID, running.WebURL))
+			pipeline = running
+		}
+	}
+
+	if pipeline == nil {
+		ref := cfg.Ref
+		if ref == "" {
+			branch, err := client.GetDefaultBranch(cfg.Project)
+			if err != nil {
+				return output.String(), fmt.Errorf("failed to get the default branch of %s: %w", cfg.Project, err)
+			}
+			ref = branch
+		}
+		for _, secret := range variableSecrets(cfg.Variables) {
+			registerSecret(secret)
+		}
+		vars := maps.Clone(cfg.Variables)
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		inputs := make(map[string]any)
+		for _, v := range state.PipelineInputs {
+			if v.Secret {
+				registerSecret(v.Value) // A resumed release has not registered them yet
+			}
+			if v.Spec {
+				inputs[v.Name] = v.typed()
+			} else {
+				vars[v.Name] = v.Value
+			}
+		}
+		// The environment's variables give way to the configured ones and the inputs; the RELIX_*
+		// ones cannot be overridden
+		for key, value := range releaseVariables(state) {
+			if _, set := vars[key]; !set || strings.HasPrefix(key, "RELIX_") {
+				vars[key] = value
+			}
+		}
+
+		var err error
+		if cfg.TriggerToken != "" {
+			pipeline, err = client.TriggerPipeline(cfg.Project, ref, cfg.TriggerToken, vars, inputs)
+		} else {
+			pipeline, err = client.CreatePipeline(cfg.Project, ref, vars, inputs)
+		}
+		recordAudit(auditPipelineTrigger, cfg.Project+"@"+ref, fmt.Sprintf("%s %s", state.Environment.Name, state.Version), err)
+		if err != nil {
+			return output.String(), fmt.Errorf("failed to start the deployment pipeline: %w", err)
+		}
+		printLine(fmt.Sprintf("Started pipeline #%d for %s: %s", pipeline.ID, ref, pipeline.WebURL))
+		if sender != nil {
+			sender.Send(deployPipelineStartedMsg{pipeline: *pipeline})
+		}
+	}
+
+	deadline := time.Now().Add(cfg.timeout())
+	status := pipeline.Status
+	printLine("Status: " + status)
+	for !pipelineFinished(status) {
+		if time.Now().After(deadline) {
+			return output.String(), fmt.Errorf("deployment pipeline #%d did not finish in %s (status %s)", pipeline.ID, cfg.timeout(), status)
+		}
+		if err := waitPollInterval(ctx); err != nil {
+			return output.String(), err
+		}
+		current, err := client.GetPipeline(cfg.Project, pipeline.ID)
+		if err != nil {
+			if isNetworkError(err) {
+				continue // Keep waiting through short outages; the deadline still applies
+			}
+			return output.String(), fmt.Errorf("failed to get deployment pipeline #%d: %w", pipeline.ID, err)
+		}
+		if current.Status != status {
+			status = current.Status
+			printLine("Status: " + status)
+		}
+	}
+
+	if status != "success" {
+		return output.String(), fmt.Errorf("deployment pipeline #%d %s: %s", pipeline.ID, status, pipeline.WebURL)
+	}
+	return output.String(), nil
+}
+
+// handleDeployPipelineStarted saves the started deployment pipeline in the release state
+func (m *model) handleDeployPipelineStarted(msg deployPipelineStartedMsg) {
+	if m.releaseState == nil {
+		return
+	}
+	m.releaseState.DeployPipelineID = msg.pipeline.ID
+	m.releaseState.DeployPipelineURL = msg.pipeline.WebURL
+	SaveReleaseState(m.tabID, m.releaseState)
+}
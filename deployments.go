@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// With gitlab_deployments in the config, GitLab's environment pages reflect what relix shipped:
+// a completed release is recorded as a successful deployment of its tag to the GitLab environment
+// of the release environment, and the environment screen shows the latest deployment of each
+// environment, including those made outside relix.
+
+// gitlabEnvironmentName returns the GitLab environment of an environment
+func gitlabEnvironmentName(env Environment) string {
+	if env.GitLabEnvironment != "" {
+		return env.GitLabEnvironment
+	}
+	return strings.ToLower(env.Name)
+}
+
+// deploymentsClient returns the GitLab client for deployments, or nil if they are off or the
+// forge is not GitLab
+func deploymentsClient(creds Credentials) *GitLabClient {
+	config, err := LoadConfig()
+	if err != nil || !config.GitLabDeployments {
+		return nil
+	}
+	client, _ := NewForge(creds).(*GitLabClient)
+	return client
+}
+
+// deploymentRecordedMsg reports the deployment recorded for a completed release
+type deploymentRecordedMsg struct {
+	environment string
+	ref         string
+	err         error
+}
+
+// envDeploymentsMsg carries the latest deployment of each environment, by environment name
+type envDeploymentsMsg struct {
+	projectID   int
+	deployments map[string]*Deployment
+}
+
+// recordDeployment returns a command recording the completed release as a GitLab deployment of
+// its tag, or nil without a release
+func (m *model) recordDeployment() tea.Cmd {
+	if m.releaseState == nil || m.creds == nil {
+		return nil
+	}
+	state := *m.releaseState
+	creds := *m.creds
+
+	return func() tea.Msg {
+		client := deploymentsClient(creds)
+		if client == nil || state.TagName == "" {
+			return nil
+		}
+		environment := gitlabEnvironmentName(state.Environment)
+		msg := deploymentRecordedMsg{environment: environment, ref: state.TagName}
+		sha := GetBranchCommitID(state.WorkDir, state.TagName+"^{commit}")
+		if sha == "" {
+			msg.err = fmt.Errorf("tag %s not found", state.TagName)
+			return msg
+		}
+		_, msg.err = client.CreateDeployment(state.ProjectID, environment, state.TagName, sha, true)
+		recordAudit(auditDeploymentCreate, fmt.Sprintf("project %d %s", state.ProjectID, environment), state.TagName, msg.err)
+		return msg
+	}
+}
+
+// handleDeploymentRecorded reports the recorded deployment in the release output
+func (m *model) handleDeploymentRecorded(msg deploymentRecordedMsg) {
+	if m.releaseState == nil {
+		return
+	}
+	if msg.err != nil {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render(
+			fmt.Sprintf("WARNING: recording the GitLab deployment to %s failed: %s", msg.environment, msg.err)))
+		return
+	}
+	m.appendReleaseOutput(fmt.Sprintf("GitLab deployment recorded: %s to %s", msg.ref, msg.environment))
+}
+
+// loadEnvDeployments returns a command fetching the latest GitLab deployment of each environment
+func (m model) loadEnvDeployments() tea.Cmd {
+	if m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	creds := *m.creds
+	projectID := m.selectedProject.ID
+	envs := m.environments
+
+	return func() tea.Msg {
+		client := deploymentsClient(creds)
+		if client == nil {
+			return nil
+		}
+		msg := envDeploymentsMsg{projectID: projectID, deployments: make(map[string]*Deployment)}
+		for _, env := range envs {
+			// Failed lookups leave the environment without a deployment
+			if deployment, err := client.GetLastDeployment(projectID, gitlabEnvironmentName(env)); err == nil && deployment != nil {
+				msg.deployments[env.Name] = deployment
+			}
+		}
+		return msg
+	}
+}
+
+// handleEnvDeployments stores the fetched deployments if the project is still selected
+func (m *model) handleEnvDeployments(msg envDeploymentsMsg) {
+	if m.selectedProject == nil || m.selectedProject.ID != msg.projectID {
+		return
+	}
+	m.envDeployments = msg.deployments
+}
+
+// deploymentSummary describes a deployment, e.g. "prod-1.4.0-v2 · 12.10.2026 14:05 · @jane"
+func deploymentSummary(d *Deployment) string {
+	parts := []string{d.Ref}
+	if !d.CreatedAt.IsZero() {
+		parts = append(parts, formatDateTime(d.CreatedAt))
+	}
+	if d.User.Username != "" {
+		parts = append(parts, "@"+d.User.Username)
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Long texts are written in the user's editor rather than a one-line input: "e" on the release
+// screen edits the release MR description before it is created, "n" the release notes text, and
+// "e" on the history detail screen the release's annotation, and "m" in the MR quick actions menu
+// a comment on the MR and "b" the reason for blocking it. The text goes through a temporary
+// file; relix waits while the editor runs and reads the file back when it exits.
+
+// editTarget is the text being edited
+type editTarget int
+
+const (
+	editMRDescription editTarget = iota
+	editReleaseNotes
+	editHistoryAnnotation
+	editMRComment
+	editMRBlockReason
+)
+
+// editTextMsg asks to open the editor on text, once it is ready (the MR description is rendered
+// from its template first)
+type editTextMsg struct {
+	target editTarget
+	text   string
+	err    error
+}
+
+// editorFinishedMsg carries the edited text back. The text is untouched if err is set.
+type editorFinishedMsg struct {
+	target editTarget
+	text   string
+	err    error
+}
+
+// userEditor returns the command line of the user's editor: $VISUAL, $EDITOR or the platform default
+func userEditor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editText leaves the TUI for the editor on a temporary copy of text. Not while a step runs,
+// as the release waits for the editor to exit. The result goes to the tab that asked for it.
+func (m *model) editText(target editTarget, text string) tea.Cmd {
+	m.closeAllModals()
+	if m.releaseRunning {
+		m.showErrorModal = true
+		m.errorModalMsg = "Wait for the running release step to finish before editing"
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "relix-*.md")
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot open the editor: " + err.Error()
+		return nil
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot open the editor: " + err.Error()
+		return nil
+	}
+
+	editor := userEditor()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	tab := m.tabID
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		msg := editorFinishedMsg{target: target, err: err}
+		if err == nil {
+			data, readErr := os.ReadFile(path)
+			msg.text, msg.err = strings.TrimSpace(string(data)), readErr
+		}
+		return tabMsg{tab: tab, msg: msg}
+	})
+}
+
+// editMRDescription renders the release MR description to edit, unless it was edited before
+func (m *model) editMRDescription() tea.Cmd {
+	if m.releaseState == nil || m.releaseState.CurrentStep != ReleaseStepWaitForMR || m.creds == nil {
+		return nil
+	}
+	if m.releaseState.MRDescription != "" {
+		return m.editText(editMRDescription, m.releaseState.MRDescription)
+	}
+	state := *m.releaseState
+	client := NewForge(*m.creds)
+	project := ""
+	if m.selectedProject != nil {
+		project = m.selectedProject.PathWithNamespace
+	}
+	return func() tea.Msg {
+		vNumber, _ := releaseVNumber(&state)
+		body, err := releaseMRDescription(client, &state, project, vNumber)
+		return editTextMsg{target: editMRDescription, text: body, err: err}
+	}
+}
+
+// handleEditText opens the editor on the prepared text
+func (m *model) handleEditText(msg editTextMsg) tea.Cmd {
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot edit: " + msg.err.Error()
+		return nil
+	}
+	return m.editText(msg.target, msg.text)
+}
+
+// handleEditorFinished stores the edited text. An emptied MR description falls back to the template,
+// an empty comment is not posted and an MR is not blocked without a reason.
+func (m *model) handleEditorFinished(msg editorFinishedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Editor failed: " + msg.err.Error()
+		return nil
+	}
+
+	switch msg.target {
+	case editMRDescription, editReleaseNotes:
+		if m.releaseState == nil {
+			return nil
+		}
+		what := "MR description"
+		if msg.target == editMRDescription {
+			m.releaseState.MRDescription = msg.text
+		} else {
+			m.releaseState.ReleaseNotes = msg.text
+			what = "release notes"
+		}
+		SaveReleaseState(m.tabID, m.releaseState)
+		m.appendReleaseOutput("")
+		if msg.text == "" {
+			m.appendReleaseOutput(commandLogStyle.Render("Cleared the " + what))
+		} else {
+			m.appendReleaseOutput(commandLogStyle.Render(fmt.Sprintf("Edited the %s (%d lines)", what, strings.Count(msg.text, "\n")+1)))
+		}
+
+	case editHistoryAnnotation:
+		if m.historySelected == nil {
+			return nil
+		}
+		if err := SaveHistoryAnnotation(m.historySelected.ID, msg.text); err != nil {
+			m.closeAllModals()
+			m.showErrorModal = true
+			m.errorModalMsg = "Cannot save the annotation: " + err.Error()
+			return nil
+		}
+		m.historySelected.Annotation = msg.text
+		// Teammates see the annotation in the activity feed
+		return syncHistory(m.historySelected.ID)
+
+	case editMRComment:
+		return m.postMRComment(msg.text)
+
+	case editMRBlockReason:
+		return m.toggleMRBlocklist(msg.text)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// emailNotifier mails an HTML release summary through an SMTP server.
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS when the server offers it.
+type emailNotifier struct {
+	addr     string // host:port
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// emailTimeout bounds connecting to and talking with the SMTP server
+const emailTimeout = 30 * time.Second
+
+// emailSummaryTemplate renders the summary; inline styles since mail clients drop style sheets
+var emailSummaryTemplate = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<html><body style="font-family: sans-serif; font-size: 14px">
+<h2>{{.Emoji}} {{.Title}}</h2>
+{{if .Event.Reason}}<p>{{.Event.Reason}}</p>
+{{end}}<table cellpadding="4" style="border-collapse: collapse">
+<tr><td><b>Status</b></td><td>{{.Event.Kind}}</td></tr>
+{{if .Event.Project}}<tr><td><b>Project</b></td><td>{{.Event.Project}}</td></tr>
+{{end}}<tr><td><b>Environment</b></td><td>{{.Event.Environment}} ({{.Event.EnvBranch}})</td></tr>
+<tr><td><b>Version</b></td><td>{{.Event.Version}}</td></tr>
+{{if .Event.Tag}}<tr><td><b>Tag</b></td><td>{{.Event.Tag}}</td></tr>
+{{end}}{{with .Event.Rollout}}<tr><td><b>Rollout</b></td><td>{{.}}</td></tr>
+{{end}}{{if .Event.ReleaseMRURL}}<tr><td><b>Release MR</b></td><td><a href="{{.Event.ReleaseMRURL}}">{{.Event.ReleaseMRURL}}</a></td></tr>
+{{end}}</table>
+<h3>Merge requests ({{len .MRs}})</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse">
+<tr><th>#</th><th>Branch</th><th>MR</th></tr>
+{{range $i, $mr := .MRs}}<tr><td>{{inc $i}}</td><td><code>{{$mr.Branch}}</code></td><td>{{if $mr.URL}}<a href="{{$mr.URL}}">{{$mr.URL}}</a>{{end}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+func (e emailNotifier) send(event ReleaseEvent) error {
+	if e.addr == "" || e.from == "" || len(e.to) == 0 {
+		return fmt.Errorf("smtp, from and to are required")
+	}
+
+	type mr struct{ Branch, URL string }
+	data := struct {
+		Event ReleaseEvent
+		Title string
+		Emoji string
+		MRs   []mr
+	}{Event: event, Title: event.title(), Emoji: eventEmoji(event.Kind)}
+	for i, branch := range event.MRBranches {
+		item := mr{Branch: branch}
+		if i < len(event.MRURLs) {
+			item.URL = event.MRURLs[i]
+		}
+		data.MRs = append(data.MRs, item)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", data.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&msg)
+	if err := emailSummaryTemplate.Execute(qp, data); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	return e.sendMail(msg.Bytes())
+}
+
+// sendMail delivers the message to all recipients
+func (e emailNotifier) sendMail(msg []byte) error {
+	host, port, err := net.SplitHostPort(e.addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp address %q: %w", e.addr, err)
+	}
+	tlsConfig := &tls.Config{ServerName: host}
+
+	dialer := &net.Dialer{Timeout: emailTimeout}
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", e.addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", e.addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != "465" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, host)); err != nil {
+			return err
+		}
+	}
+	// From and To may carry display names ("Relix <relix@example.com>"); the envelope takes bare addresses
+	from, err := mail.ParseAddress(e.from)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range e.to {
+		to, err := mail.ParseAddress(rcpt)
+		if err != nil {
+			return fmt.Errorf("invalid to address: %w", err)
+		}
+		if err := client.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -15,7 +15,7 @@ func (m model) updateEnvMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+q":
 		// Go back to source branch input
-		m.screen = screenSourceBranch
+		m.goBack()
 		return m, nil
 	case "up", "k":
 		if m.envMergeOptionIndex > 0 {
@@ -29,7 +29,7 @@ func (m model) updateEnvMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		m.envMergeSelection = m.envMergeOptionIndex
-		m.screen = screenRootMerge
+		m.moveToScreen(screenRootMerge)
 		// Preserve previous root merge selection
 		if m.rootMergeSelection {
 			m.rootMergeButtonIndex = 0
@@ -197,7 +197,7 @@ func (m model) renderEnvMergeSidebarSection(width int, contentHeight int) string
 // calculateEnvMergeCommitCount runs git rev-list --count and sums MR commits
 func (m model) calculateEnvMergeCommitCount() tea.Cmd {
 	return func() tea.Msg {
-		workDir, err := FindProjectRoot()
+		workDir, err := projectWorkDir(m.selectedProject)
 		if err != nil {
 			return envMergeCommitCountMsg{count: 0, err: err}
 		}
@@ -233,12 +233,17 @@ func (m model) calculateEnvMergeCommitCount() tea.Cmd {
 				total += baseToEnvCount
 			}
 
-			// Then add MR commit counts
+			// Then add MR commit counts (details of MRs that were never highlighted are fetched now)
 			items := m.list.Items()
 			for _, item := range items {
 				if mr, ok := item.(mrListItem); ok {
-					if m.selectedMRs[mr.MR().IID] {
+					if !m.selectedMRs[mr.MR().IID] {
+						continue
+					}
+					if mr.MR().DetailsLoaded || m.creds == nil {
 						total += mr.MR().CommitsCount
+					} else if details, err := NewForge(*m.creds).GetMergeRequestDetails(mr.MR().MergeRequest); err == nil {
+						total += details.CommitsCount
 					}
 				}
 			}
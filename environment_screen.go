@@ -118,7 +118,7 @@ func (m model) updateEnvSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+q":
 		// Save current selection and go back to MR list
 		m.selectedEnv = &m.environments[m.envSelectIndex]
-		m.screen = screenMain
+		m.goBack()
 		return m, nil
 	case "up", "k":
 		if m.envSelectIndex > 0 {
@@ -138,8 +138,14 @@ func (m model) updateEnvSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.versionInput = initVersionInput()
 		}
 		m.versionError = ""
-		m.screen = screenVersion
-		return m, nil
+		m.versionEnterPending = false
+		m.versionSuggestion = ""
+		m.moveToScreen(screenVersion)
+		if m.versionInput.Value() == "" {
+			return m, tea.Batch(m.suggestVersion(), m.loadLatestVersions())
+		}
+		checkCmd := m.checkVersion()
+		return m, tea.Batch(checkCmd, m.loadLatestVersions())
 	}
 
 	return m, nil
@@ -176,7 +182,7 @@ func (m model) viewEnvSelect() string {
 	main := lipgloss.JoinHorizontal(lipgloss.Top, sidebar, content)
 
 	// Help footer
-	helpText := "j/k: nav • enter: select • C+q: back • /: commands • C+c: quit"
+	helpText := "j/k: nav • enter: select • C+q: back • " + m.fieldHintsHelp() + " • /: commands • C+c: quit"
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 
 	return lipgloss.JoinVertical(lipgloss.Left, main, help)
@@ -229,6 +235,10 @@ func (m model) renderEnvSelection(width int) string {
 		} else {
 			sb.WriteString(envItemStyle.Render(env.Name))
 		}
+		// Currently deployed, from GitLab deployments
+		if deployment := m.envDeployments[env.Name]; deployment != nil {
+			sb.WriteString(envHintBaseStyle.Render("  deployed " + deploymentSummary(deployment)))
+		}
 		sb.WriteString("\n")
 	}
 	sb.WriteString("\n")
@@ -241,6 +251,10 @@ func (m model) renderEnvSelection(width int) string {
 		envHintBaseStyle.Render(" -> ") +
 		getEnvHintStyle(selectedEnv.Name).Render(" "+selectedEnv.Name+" ")
 	sb.WriteString(hint)
+	if fieldHint := m.renderFieldHint(envFieldHint(selectedEnv), width); fieldHint != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(fieldHint)
+	}
 
 	return sb.String()
 }
@@ -1,32 +1,137 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// errorContext is the failed operation shown on the error screen, deciding the actions offered
+type errorContext struct {
+	operation string  // What failed, e.g. "Signing in to https://gitlab.com"; empty for invalid input
+	retry     tea.Cmd // Runs the operation again; nil if it cannot run again as is
+	settings  bool    // Settings may fix it: keyring backend, timeouts or headers
+	account   bool    // Signing in with other credentials may fix it
+}
+
+// showError shows the error screen for a failed operation
+func (m *model) showError(message string, context errorContext) {
+	m.errorMsg = message
+	m.errorContext = context
+	m.errorNotice = ""
+	m.moveToScreen(screenError)
+}
+
+// authErrorContext returns the context of a failed sign-in: it can be retried with the same
+// credentials, network and keyring errors may be fixed in settings and rejected credentials by
+// other ones
+func authErrorContext(creds Credentials, err error) errorContext {
+	var keyringErr *keyringError
+	fixedInSettings := isNetworkError(err) || errors.As(err, &keyringErr)
+	return errorContext{
+		operation: "Signing in to " + creds.GitLabURL,
+		retry:     validateCredentialsCmd(creds),
+		settings:  fixedInSettings,
+		account:   !fixedInSettings,
+	}
+}
+
 // updateError handles key events on the error screen
 func (m model) updateError(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		m.screen = screenAuth
+		m.moveToScreen(screenAuth)
 		m.errorMsg = ""
 		return m, nil
+	case "r":
+		if m.errorContext.retry == nil {
+			return m, nil
+		}
+		retry := m.errorContext.retry
+		m.moveToScreen(screenAuth)
+		m.errorMsg = ""
+		m.loading = true
+		return m, tea.Batch(m.spinner.Tick, retry)
+	case "s":
+		if !m.errorContext.settings {
+			return m, nil
+		}
+		return m.executeCommand("settings")
+	case "a":
+		if !m.errorContext.account {
+			return m, nil
+		}
+		// Start over with an empty form
+		m.moveToScreen(screenAuth)
+		m.errorMsg = ""
+		m.inputs = initAuthInputs()
+		m.focusIndex = 0
+		return m.updateFocus(), nil
+	case "c":
+		if err := copyToClipboard(m.errorDetails()); err != nil {
+			m.errorNotice = "Cannot copy: " + err.Error()
+		} else {
+			m.errorNotice = "Error details copied"
+		}
+		return m, nil
 	}
 	return m, nil
 }
 
+// errorDetails returns the full error report copied by the error screen, for bug reports and
+// support requests
+func (m model) errorDetails() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "relix v%s\n", AppVersion)
+	fmt.Fprintf(&b, "Time: %s\n", time.Now().Format(time.RFC3339))
+	if m.errorContext.operation != "" {
+		fmt.Fprintf(&b, "Operation: %s\n", m.errorContext.operation)
+	}
+	if m.creds != nil {
+		fmt.Fprintf(&b, "Forge: %s (%s)\n", m.creds.GitLabURL, forgeName(*m.creds))
+	}
+	fmt.Fprintf(&b, "Terminal: %s\n", termSummary())
+	fmt.Fprintf(&b, "Error: %s\n", m.errorMsg)
+	return b.String()
+}
+
+// errorHelp returns the key help of the actions the error offers
+func (m model) errorHelp() string {
+	actions := []string{"enter: back to form"}
+	if m.errorContext.retry != nil {
+		actions = append(actions, "r: retry")
+	}
+	if m.errorContext.settings {
+		actions = append(actions, "s: settings")
+	}
+	if m.errorContext.account {
+		actions = append(actions, "a: switch account")
+	}
+	actions = append(actions, "c: copy details", "C+c: quit")
+	return strings.Join(actions, " • ")
+}
+
 // viewError renders the error screen
 func (m model) viewError() string {
 	var b strings.Builder
 
 	b.WriteString(errorTitleStyle.Render("Error"))
 	b.WriteString("\n\n")
+	if m.errorContext.operation != "" {
+		b.WriteString(helpStyle.Render(m.errorContext.operation + " failed"))
+		b.WriteString("\n\n")
+	}
 	b.WriteString(m.errorMsg)
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("enter: back to form • C+c: quit"))
+	if m.errorNotice != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Success).Render(m.errorNotice))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(helpStyle.Render(m.errorHelp()))
 
 	// Use width-restricted modal
 	errorContent := renderModal(b.String(), ErrorModalConfig(), m.width)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Field hints explain what the focused input of the auth, environment and version screens
+// expects: the token scopes of the forge, the branch and tag an environment releases to, the
+// format of the version. They help on the first runs; F1 hides them once the screens are
+// familiar, and the choice is saved as hide_field_hints.
+
+// fieldHintsKey shows or hides the field hints
+const fieldHintsKey = "f1"
+
+// hasFieldHints reports whether the current screen shows field hints
+func (m model) hasFieldHints() bool {
+	switch m.screen {
+	case screenAuth:
+		return !m.loading
+	case screenEnvSelect, screenVersion:
+		return true
+	}
+	return false
+}
+
+// toggleFieldHints shows or hides the field hints and saves the choice
+func (m *model) toggleFieldHints() {
+	m.hideFieldHints = !m.hideFieldHints
+	config, err := LoadConfig()
+	if err != nil {
+		config = &AppConfig{}
+	}
+	config.HideFieldHints = m.hideFieldHints
+	SaveConfig(config)
+}
+
+// renderFieldHint renders a hint under an input, wrapped to width; "" while hints are hidden
+func (m model) renderFieldHint(hint string, width int) string {
+	if m.hideFieldHints || hint == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(currentTheme.Notion).
+		Italic(true).
+		Width(width).
+		Render(hint)
+}
+
+// fieldHintsHelp returns the footer entry of the hints key
+func (m model) fieldHintsHelp() string {
+	if m.hideFieldHints {
+		return "F1: show hints"
+	}
+	return "F1: hide hints"
+}
+
+// authFieldHint returns the hint of the focused auth form field
+func (m model) authFieldHint() string {
+	switch m.focusIndex {
+	case 0:
+		return "Address of your GitLab, GitHub, Gitea/Forgejo or Bitbucket Server instance. The forge is detected from it on submit."
+	case 1:
+		return "Email of your forge account. It is checked against the account's emails, so a token of someone else is refused."
+	case 2:
+		if u, err := neturl.Parse(strings.TrimSpace(m.inputs[0].Value())); err == nil && strings.EqualFold(u.Hostname(), "github.com") {
+			return "A token with the repo scope (and workflow to read Actions runs), or a fine-grained token with read/write access to pull requests."
+		}
+		return "GitLab: a personal access token with the api scope (Settings → Access Tokens); read_api is not enough to merge. Gitea: read/write repositories and issues. Bitbucket: an HTTP access token with Repository write."
+	}
+	return ""
+}
+
+// envFieldHint returns the hint of the highlighted environment: where its releases go and what
+// they ask for
+func envFieldHint(env Environment) string {
+	hint := fmt.Sprintf("Releases are merged into the %s branch and tagged like %s.",
+		env.BranchName, ReleaseTagName(env, "<version>", 1))
+	var asks []string
+	if env.Rollout {
+		asks = append(asks, "rollout metadata")
+	}
+	if env.ConfirmPhrase != "" {
+		asks = append(asks, "a confirmation phrase")
+	}
+	if len(asks) > 0 {
+		hint += " A release asks for " + strings.Join(asks, " and ") + "."
+	}
+	if env.DeployPipeline != nil {
+		hint += " After tagging, the release waits for the deployment pipeline."
+	}
+	return hint
+}
+
+// versionFieldHint returns the hint of the version input: the format of the project's version
+// scheme and where the version ends up
+func versionFieldHint() string {
+	scheme, err := activeVersionScheme()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("Use %s (%s scheme). The version names the release branch and the release tag; a tag that already exists is refused.",
+		scheme.format, scheme.name)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Forge is the code hosting API used by relix: merge requests, their pipelines and the account.
+// GitLabClient implements it for GitLab, GitHubClient for GitHub, where merge requests are
+// pull requests and pipelines are Actions workflow runs, GiteaClient for Gitea and Forgejo, and
+// BitbucketClient for Bitbucket Data Center.
+type Forge interface {
+	GetUserEmails() ([]string, error)
+	GetTokenExpiry() (*time.Time, error)
+	GetProjects() ([]Project, error)
+	GetProjectsPage(page int, query projectQuery) ([]Project, listPage, error)
+
+	GetOpenMergeRequests() ([]*MergeRequestDetails, error)
+	GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error)
+	GetProjectMergeRequestsPage(projectID, page int) ([]*MergeRequestDetails, listPage, error)
+	GetProjectMergeRequestsUpdatedSince(projectID int, since time.Time) ([]*MergeRequestDetails, error)
+	GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDetails, error)
+	LoadMergeRequestDetails(mrs []*MergeRequestDetails)
+	GetMergeRequestByIID(projectID, mrIID int) (*MergeRequestDetails, error)
+	GetMergeRequestBySourceBranch(projectID int, sourceBranch string) (*MergeRequestDetails, error)
+	GetMergeRequestStatus(projectID, mrIID int) (*MergeRequest, error)
+	GetMergeRequestChangedFiles(projectID, mrIID int) ([]string, error)
+	CreateMergeRequest(projectID int, sourceBranch, targetBranch, title, description string) (*MergeRequest, error)
+	CreateMergeRequestNote(projectID, mrIID int, body string) (int, error)
+	UpdateMergeRequestNote(projectID, mrIID, noteID int, body string) error
+	ApproveMergeRequest(projectID, mrIID int) error
+	RebaseMergeRequest(projectID, mrIID int) error
+	SetMergeRequestDraft(projectID, mrIID int, title string, draft bool) (string, error)
+	AddMergeRequestLabels(projectID, mrIID int, labels []string) error
+	FindMilestone(projectID int, title string) (int, error)
+	SetMergeRequestMilestone(projectID, mrIID, milestoneID int) error
+
+	GetMergeRequestPipelines(projectID, mrIID int) ([]Pipeline, error)
+	GetPipelinesByCommit(projectID int, sha string) ([]Pipeline, error)
+	GetRunningPipelines(projectID int) ([]Pipeline, error)
+	GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error)
+
+	GetRepositoryFile(projectID int, path string) ([]byte, error)
+	GetTags(projectID int, prefix string) ([]Tag, error)
+	CreateRelease(projectID int, tag, name, description string) (string, error)
+}
+
+// errRunningPipelinesUnsupported is returned by forges whose CI reports only per-commit statuses,
+// so running pipelines cannot be listed per project
+var errRunningPipelinesUnsupported = errors.New("running pipelines are not listed by this forge")
+
+// errMRSyncUnsupported is returned by forges that cannot list MRs by update time, so MR lists
+// are refreshed in full
+var errMRSyncUnsupported = errors.New("MRs are not listed by update time by this forge")
+
+// errReleasesUnsupported is returned by forges without releases attached to tags
+var errReleasesUnsupported = errors.New("releases are not supported by this forge")
+
+// errMRActionUnsupported is returned for MR quick actions a forge's API does not offer
+var errMRActionUnsupported = errors.New("not supported by this forge")
+
+// draftTitlePrefixes mark draft MRs by title: GitLab's current and former prefixes, and the
+// work-in-progress prefixes Gitea recognizes
+var draftTitlePrefixes = []string{"draft:", "[draft]", "(draft)", "wip:", "[wip]"}
+
+// draftTitle returns an MR title with its draft prefix removed, then prefix added if draft is set
+func draftTitle(title string, draft bool, prefix string) string {
+	title = strings.TrimSpace(title)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, p := range draftTitlePrefixes {
+			if len(title) >= len(p) && strings.EqualFold(title[:len(p)], p) {
+				title = strings.TrimSpace(title[len(p):])
+				trimmed = true
+			}
+		}
+	}
+	if draft {
+		return prefix + " " + title
+	}
+	return title
+}
+
+// listPageSize is the number of projects or MRs requested per page; lists load further pages on demand
+const listPageSize = 100
+
+// listPage describes a fetched page of a paginated list (pages are numbered from 1)
+type listPage struct {
+	Total   int  // Items in the whole list, or -1 if the forge does not report it
+	HasMore bool // Further pages exist
+}
+
+// Project list scopes (see projectQuery)
+const (
+	projectScopeAll     = "" // Every project the user is a member of or can access
+	projectScopeStarred = "starred"
+	projectScopeOwned   = "owned"
+)
+
+// projectQuery narrows the project list of the project selector
+type projectQuery struct {
+	Archived bool   // Include archived projects
+	Scope    string // projectScopeAll, projectScopeStarred or projectScopeOwned
+}
+
+// loadProjectQuery reads the project list options from the config
+func loadProjectQuery() projectQuery {
+	config, err := LoadConfig()
+	if err != nil {
+		return projectQuery{}
+	}
+	return projectQuery{Archived: config.ProjectsIncludeArchived, Scope: config.ProjectsScope}
+}
+
+// String describes the query for the project selector, e.g. "starred · with archived"
+func (q projectQuery) String() string {
+	s := "all projects"
+	if q.Scope != projectScopeAll {
+		s = q.Scope
+	}
+	if q.Archived {
+		s += " · with archived"
+	}
+	return s
+}
+
+// Forge kinds stored in Credentials.Forge
+const (
+	forgeGitLab    = "" // Default, so credentials saved before forges were added stay GitLab
+	forgeGitHub    = "github"
+	forgeGitea     = "gitea" // Also Forgejo, which keeps the Gitea API
+	forgeBitbucket = "bitbucket"
+)
+
+// NewForge returns the API client for the forge of the credentials
+func NewForge(creds Credentials) Forge {
+	registerSecret(creds.Token)
+	switch creds.Forge {
+	case forgeGitHub:
+		return NewGitHubClient(creds.GitLabURL, creds.Token)
+	case forgeGitea:
+		return NewGiteaClient(creds.GitLabURL, creds.Token)
+	case forgeBitbucket:
+		return NewBitbucketClient(creds.GitLabURL, creds.Token)
+	default:
+		return NewGitLabClient(creds.GitLabURL, creds.Token)
+	}
+}
+
+// forgeName returns the display name of the forge of the credentials
+func forgeName(creds Credentials) string {
+	switch creds.Forge {
+	case forgeGitHub:
+		return "GitHub"
+	case forgeGitea:
+		return "Gitea"
+	case forgeBitbucket:
+		return "Bitbucket"
+	}
+	return "GitLab"
+}
+
+// detectForge tells the forge by the URL entered at login: github.com, or an instance answering
+// the GitHub Enterprise API under /api/v3 (which GitLab no longer serves), is GitHub; an instance
+// reporting its version under /api/v1 is Gitea or Forgejo; one serving the Bitbucket REST API is
+// Bitbucket Data Center; anything else is GitLab
+func detectForge(baseURL string) string {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return forgeGitLab
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "github.com" || host == "api.github.com" {
+		return forgeGitHub
+	}
+
+	registerForgeHost(baseURL)
+	client := &http.Client{Transport: gitlabTransport}
+	probes := []struct{ path, forge string }{
+		{"/api/v3/meta", forgeGitHub},
+		{"/api/v1/version", forgeGitea},
+		{"/rest/api/1.0/application-properties", forgeBitbucket},
+	}
+	for _, probe := range probes {
+		resp, err := client.Get(strings.TrimSuffix(baseURL, "/") + probe.path)
+		if err != nil {
+			return forgeGitLab
+		}
+		resp.Body.Close()
+		if resp.StatusCode == 200 && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+			return probe.forge
+		}
+	}
+	return forgeGitLab
+}
+
+// maxTagPages bounds the pages of tags fetched when looking tags up by prefix
+const maxTagPages = 10
+
+// maxChangedFilePages bounds the pages of changed files fetched per MR; forges truncate huge diffs anyway
+const maxChangedFilePages = 30
+
+// appendChangedFile adds the path of a changed file, and its previous path if it was renamed
+func appendChangedFile(files []string, path, oldPath string) []string {
+	files = append(files, path)
+	if oldPath != "" && oldPath != path {
+		files = append(files, oldPath)
+	}
+	return files
+}
+
+// linkHasNext reports whether a paginated response links to a next page (RFC 8288 Link header,
+// used by GitHub and Gitea)
+func linkHasNext(header http.Header) bool {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		if strings.Contains(link, `rel="next"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxRepositoryFileSize bounds the files read from repositories, e.g. .restitcher.yaml
+const maxRepositoryFileSize = 1 << 20
+
+// getRawFile fetches a file from a forge endpoint serving it as is. A missing file is nil
+// without an error.
+func getRawFile(client *http.Client, url string, headers map[string]string, forge string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return nil, fmt.Errorf("invalid token: authentication failed")
+	}
+	if resp.StatusCode == 404 {
+		return nil, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s API error: status %d", forge, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRepositoryFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > maxRepositoryFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxRepositoryFileSize)
+	}
+	return data, nil
+}
+
+// escapeFilePath escapes the segments of a repository file path for use in a URL path
+func escapeFilePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
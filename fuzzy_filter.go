@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// filterDebounce is how long typing must pause before a list is filtered
+const filterDebounce = 120 * time.Millisecond
+
+// typoTolerantMinLength is the shortest filter that is retried with one character left out
+const typoTolerantMinLength = 4
+
+// fuzzyFilter ranks targets matching term as a case-insensitive subsequence, best first.
+// If nothing matches, a term of typoTolerantMinLength+ characters is retried with each
+// character left out in turn, so one mistyped or extra character still finds the item.
+// MatchedIndexes are rune positions, as expected by lipgloss.StyleRunes.
+// It has the signature of list.FilterFunc.
+func fuzzyFilter(term string, targets []string) []list.Rank {
+	matches := fuzzy.Find(term, targets)
+
+	runes := []rune(term)
+	if len(matches) == 0 && len(runes) >= typoTolerantMinLength {
+		best := make(map[int]fuzzy.Match)
+		for i := range runes {
+			variant := string(runes[:i]) + string(runes[i+1:])
+			for _, match := range fuzzy.Find(variant, targets) {
+				if prev, ok := best[match.Index]; !ok || match.Score > prev.Score {
+					best[match.Index] = match
+				}
+			}
+		}
+		for _, match := range best {
+			matches = append(matches, match)
+		}
+	}
+	sort.Stable(matches)
+
+	ranks := make([]list.Rank, len(matches))
+	for i, match := range matches {
+		ranks[i] = list.Rank{Index: match.Index, MatchedIndexes: runePositions(match.Str, match.MatchedIndexes)}
+	}
+	return ranks
+}
+
+// runePositions converts byte offsets in s to rune positions
+func runePositions(s string, byteOffsets []int) []int {
+	positions := make([]int, len(byteOffsets))
+	for i, offset := range byteOffsets {
+		positions[i] = utf8.RuneCountInString(s[:offset])
+	}
+	return positions
+}
+
+// highlightMatches renders text with the runes at the given positions underlined.
+// Positions past the end of text are ignored.
+func highlightMatches(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+	return lipgloss.StyleRunes(text, positions, base.Underline(true), base)
+}
+
+// shiftPositions returns the positions at or after offset, moved to start at 0.
+// It maps matches in a full string to a substring starting at offset.
+func shiftPositions(positions []int, offset int) []int {
+	var shifted []int
+	for _, p := range positions {
+		if p >= offset {
+			shifted = append(shifted, p-offset)
+		}
+	}
+	return shifted
+}
+
+// normalizeSpaces collapses runs of whitespace to single spaces, as wrapText does,
+// so match positions stay aligned with wrapped lines
+func normalizeSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -14,7 +15,6 @@ import (
 	"time"
 
 	"github.com/ActiveState/vt10x"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/creack/pty"
 )
@@ -117,7 +117,8 @@ func (vt *VirtualTerminal) RenderScreen() string {
 	// Reset colors at the end
 	sb.WriteString("\033[0m")
 
-	return sb.String()
+	// Basic ANSI colors of the command drawn in the theme's colors
+	return remapOutputColors(sb.String())
 }
 
 // buildColorSequence builds ANSI escape sequence for foreground and background colors
@@ -175,24 +176,36 @@ type GitExecutor struct {
 	workDir string
 	ptyFile *os.File
 	cmd     *exec.Cmd
-	program *tea.Program // For sending messages back to UI
+	sender  messageSender // For sending messages back to the UI, to the release's tab
 	cols    uint16
 	rows    uint16
 	vterm   *VirtualTerminal
 	doneCh  chan struct{}
 	mu      sync.Mutex
+	env     []string        // Environment of the commands; nil inherits relix's
+	ctx     context.Context // Commands fail without running once it is done (the release was aborted)
 }
 
 // NewGitExecutor creates a new git executor for the given directory
-func NewGitExecutor(workDir string, program *tea.Program) *GitExecutor {
+func NewGitExecutor(workDir string, sender messageSender) *GitExecutor {
 	return &GitExecutor{
 		workDir: workDir,
-		program: program,
+		sender:  sender,
 		cols:    120, // Default, should be set via SetSize before running commands
 		rows:    24,
 	}
 }
 
+// SetEnv sets the environment of the commands run, instead of relix's own
+func (g *GitExecutor) SetEnv(env []string) {
+	g.env = env
+}
+
+// SetContext makes the commands fail without running once ctx is done
+func (g *GitExecutor) SetContext(ctx context.Context) {
+	g.ctx = ctx
+}
+
 // SetSize sets the terminal size for the executor
 func (g *GitExecutor) SetSize(cols, rows uint16) {
 	g.mu.Lock()
@@ -202,14 +215,24 @@ func (g *GitExecutor) SetSize(cols, rows uint16) {
 }
 
 // RunCommand executes a shell command via PTY and streams output through virtual terminal
-func (g *GitExecutor) RunCommand(command string) (string, error) {
+func (g *GitExecutor) RunCommand(command string) (output string, err error) {
+	if g.ctx != nil && g.ctx.Err() != nil {
+		return "", context.Cause(g.ctx)
+	}
+	span := startTraceSpan(commandSpanName(command), spanKindInternal, map[string]string{"process.command_line": command})
+	defer func() {
+		span.finish(err)
+		auditGitCommand(g.workDir, command, err)
+	}()
+
 	cmd := exec.Command("sh", "-c", command)
 	cmd.Dir = g.workDir
+	cmd.Env = g.env
 
-	// Send command header to UI immediately if program is set (before PTY starts)
+	// Send command header to UI immediately if sender is set (before PTY starts)
 	// Use special message to request smart empty line handling
-	if g.program != nil {
-		g.program.Send(releaseCommandStartMsg{command: command})
+	if g.sender != nil {
+		g.sender.Send(releaseCommandStartMsg{command: command})
 	}
 
 	// Start PTY
@@ -247,7 +270,7 @@ func (g *GitExecutor) RunCommand(command string) (string, error) {
 	}()
 
 	// Throttled render loop (50ms = 20 FPS max)
-	if g.program != nil {
+	if g.sender != nil {
 		go func() {
 			ticker := time.NewTicker(50 * time.Millisecond)
 			defer ticker.Stop()
@@ -256,14 +279,14 @@ func (g *GitExecutor) RunCommand(command string) (string, error) {
 				case <-g.doneCh:
 					// Final render
 					content := vterm.RenderScreen()
-					g.program.Send(releaseScreenMsg{content: strings.TrimRight(content, " \n")})
+					g.sender.Send(releaseScreenMsg{content: strings.TrimRight(content, " \n")})
 					return
 				case <-ticker.C:
 					content := vterm.RenderScreen()
 					// Trim trailing whitespace/newlines from vt10x screen
 					content = strings.TrimRight(content, " \n")
 					if content != "" {
-						g.program.Send(releaseScreenMsg{content: content})
+						g.sender.Send(releaseScreenMsg{content: content})
 					}
 				}
 			}
@@ -272,7 +295,7 @@ func (g *GitExecutor) RunCommand(command string) (string, error) {
 
 	// Wait for command to finish
 	err = cmd.Wait()
-	output := outputBuilder.String()
+	output = outputBuilder.String()
 
 	// Close PTY to unblock read goroutine (macOS may not EOF automatically after process exits)
 	ptmx.Close()
@@ -462,6 +485,7 @@ type ReleaseCommands struct {
 	branches             []string // MR source branches to merge
 	sourceBranch         string   // Custom source branch name (e.g. release/rpb-1.0.0-root)
 	sourceBranchIsRemote bool     // Whether source branch exists on remote
+	pushOptions          string   // Options of pushes starting release pipelines, with a leading space
 }
 
 // NewReleaseCommands creates a new command builder
@@ -501,6 +525,12 @@ func (r *ReleaseCommands) ReleaseRootBranch() string {
 	return fmt.Sprintf("release/rpb-%s-root", r.version)
 }
 
+// DevelopReleaseBranch returns the branch merged into develop through a merge request when develop
+// cannot be pushed to (see push_strategy.go)
+func (r *ReleaseCommands) DevelopReleaseBranch() string {
+	return fmt.Sprintf("release/rpb-%s-develop", r.version)
+}
+
 // EnvReleaseBranch returns the environment release branch name
 func (r *ReleaseCommands) EnvReleaseBranch() string {
 	return fmt.Sprintf("release/rpb-%s-%s", r.version, r.envBranch)
@@ -528,6 +558,12 @@ func (r *ReleaseCommands) Step1CheckoutRoot() []string {
 	}
 }
 
+// StepCheckoutRollback returns the command for step 1 of a rollback: the source branch is created
+// from the tag of the release restored
+func (r *ReleaseCommands) StepCheckoutRollback(tag string) string {
+	return fmt.Sprintf("git checkout -B %s refs/tags/%s", r.ReleaseRootBranch(), tag)
+}
+
 // Step2MergeBranch returns the command to merge a specific branch
 func (r *ReleaseCommands) Step2MergeBranch(branchIndex int) string {
 	if branchIndex >= len(r.branches) {
@@ -536,6 +572,15 @@ func (r *ReleaseCommands) Step2MergeBranch(branchIndex int) string {
 	return fmt.Sprintf("GIT_EDITOR=true git merge --no-edit origin/%s", r.branches[branchIndex])
 }
 
+// Step2MergeBranchWithMessage returns the command to merge a specific branch with a merge commit
+// (also when it could be fast-forwarded) that has the message
+func (r *ReleaseCommands) Step2MergeBranchWithMessage(branchIndex int, message string) string {
+	if branchIndex >= len(r.branches) {
+		return ""
+	}
+	return fmt.Sprintf("GIT_EDITOR=true git merge --no-ff -m %s origin/%s", shellQuote(message), r.branches[branchIndex])
+}
+
 // Step3CheckoutEnv returns the command for step 3
 // This handles the case where local env branch might not exist
 func (r *ReleaseCommands) Step3CheckoutEnv() []string {
@@ -565,7 +610,17 @@ func (r *ReleaseCommands) Step6PushSourceBranch() string {
 
 // Step6Push returns the command for step 6 (push only, MR is created via API)
 func (r *ReleaseCommands) Step6Push() string {
-	return fmt.Sprintf("git push -u origin %s", r.EnvReleaseBranch())
+	return fmt.Sprintf("git push -u origin %s", r.EnvReleaseBranch()) + r.pushOptions
+}
+
+// SetPushOptions sets the options of the pushes starting release pipelines (see release_vars.go)
+func (r *ReleaseCommands) SetPushOptions(options string) {
+	r.pushOptions = options
+}
+
+// PushOptions returns the options of the pushes starting release pipelines, with a leading space
+func (r *ReleaseCommands) PushOptions() string {
+	return r.pushOptions
 }
 
 // StepMergeToRoot returns the command to merge source branch to base branch
@@ -639,14 +694,14 @@ func VersionsMatch(v1, v2 string) bool {
 	return n1 == n2
 }
 
-// GetNextVersionNumber parses git log and returns the next v-number to use
-// Returns (vNumber, error)
-func GetNextVersionNumber(workDir, envBranch, currentVersion string) (int, error) {
+// LatestReleasedVersion returns the version and v-number of the last release commit among the
+// recent commits of the remote environment branch; found is false if there is none
+func LatestReleasedVersion(workDir, envBranch string) (version string, vNum int, found bool, err error) {
 	cmd := exec.Command("git", "log", fmt.Sprintf("origin/%s", envBranch), "-n", "10", "--pretty=%s")
 	cmd.Dir = workDir
 	output, err := cmd.Output()
 	if err != nil {
-		return 0, fmt.Errorf("failed to read git log: %w", err)
+		return "", 0, false, fmt.Errorf("failed to read git log: %w", err)
 	}
 
 	lines := strings.Split(string(output), "\n")
@@ -656,24 +711,110 @@ func GetNextVersionNumber(workDir, envBranch, currentVersion string) (int, error
 			continue
 		}
 
-		version, vNum, found := ParseVersionNumber(line, envBranch)
-		if !found {
-			continue
+		if version, vNum, found := ParseVersionNumber(line, envBranch); found {
+			return strings.TrimSpace(version), vNum, true, nil
 		}
-
-		// Found a release commit
-		if VersionsMatch(version, currentVersion) {
-			// Same version - increment
-			return vNum + 1, nil
-		}
-		// Different version - reset to v1
-		return 1, nil
 	}
+	return "", 0, false, nil
+}
 
-	// No previous release found - start from v1
+// GetNextVersionNumber parses git log and returns the next v-number to use
+// Returns (vNumber, error)
+func GetNextVersionNumber(workDir, envBranch, currentVersion string) (int, error) {
+	version, vNum, found, err := LatestReleasedVersion(workDir, envBranch)
+	if err != nil {
+		return 0, err
+	}
+	if found && VersionsMatch(version, currentVersion) {
+		// Same version - increment
+		return vNum + 1, nil
+	}
+	// Different version or no previous release - start from v1
 	return 1, nil
 }
 
+// hasVersionFormat reports whether the environment formats its released versions
+func (e Environment) hasVersionFormat() bool {
+	return e.TagPrefix != "" || e.VersionSuffix != "" || e.BuildMetadata != ""
+}
+
+// FormatReleaseVersion applies the environment's version format rules, e.g. "v1.2.3-rc.2+build.20261016".
+// In the suffix and build metadata {n} stands for the v-number and {date} for today (YYYYMMDD).
+func FormatReleaseVersion(env Environment, version string, vNumber int) string {
+	return formatReleaseVersion(env, version, vNumber, versionDate(time.Now()))
+}
+
+// formatReleaseVersion applies the environment's version format rules with date for {date}
+func formatReleaseVersion(env Environment, version string, vNumber int, date string) string {
+	expand := strings.NewReplacer("{n}", strconv.Itoa(vNumber), "{date}", date).Replace
+	formatted := env.TagPrefix + version + expand(env.VersionSuffix)
+	if env.BuildMetadata != "" {
+		formatted += "+" + expand(env.BuildMetadata)
+	}
+	return formatted
+}
+
+// versionDate returns the {date} of versions formatted on the day of t
+func versionDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// ReleaseTagName returns the tag put on the root branch by a release: the formatted version if the
+// environment has format rules (e.g. "v1.2.3-rc.2"), otherwise "<env>-<version>-v<N>" (e.g. "prod-1.2.3-v2")
+func ReleaseTagName(env Environment, version string, vNumber int) string {
+	return releaseTagName(env, version, vNumber, versionDate(time.Now()))
+}
+
+// releaseTagName returns the tag of a release formatted with date for {date}
+func releaseTagName(env Environment, version string, vNumber int, date string) string {
+	if env.hasVersionFormat() {
+		return formatReleaseVersion(env, version, vNumber, date)
+	}
+	return fmt.Sprintf("%s-%s-v%d", strings.ToLower(env.Name), version, vNumber)
+}
+
+// versionDate returns the {date} of the release's versions: the day it started, so a release
+// running past midnight keeps its tag. Releases saved before the day was recorded take today.
+func (s *ReleaseState) versionDate() string {
+	if s.VersionDate != "" {
+		return s.VersionDate
+	}
+	return versionDate(time.Now())
+}
+
+// formatVersion returns the formatted version of the release with the v-number
+func (s *ReleaseState) formatVersion(vNumber int) string {
+	return formatReleaseVersion(s.Environment, s.Version, vNumber, s.versionDate())
+}
+
+// tagName returns the tag of the release with the v-number
+func (s *ReleaseState) tagName(vNumber int) string {
+	return releaseTagName(s.Environment, s.Version, vNumber, s.versionDate())
+}
+
+// checkRefFormat reports why name is not a valid git ref name (see git check-ref-format)
+func checkRefFormat(name string) error {
+	switch {
+	case name == "" || name == "@":
+		return fmt.Errorf("%q is not a valid ref name", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return fmt.Errorf("%q cannot start with a slash or end with a slash or dot", name)
+	case strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{"):
+		return fmt.Errorf("%q cannot contain \"..\", \"//\" or \"@{\"", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("%q cannot contain %q", name, r)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("%q has a component starting with a dot or ending with .lock", name)
+		}
+	}
+	return nil
+}
+
 // BuildCommitMessage builds the commit message for step 4
 func BuildCommitMessage(version, envBranch string, vNumber int, branches []string) (string, string) {
 	title := fmt.Sprintf("release:%s %s v%d", version, envBranch, vNumber)
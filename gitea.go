@@ -0,0 +1,636 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GiteaClient handles Gitea and Forgejo API requests. Pull requests decode like GitHub's
+// (see githubPull); pipelines are the commit statuses reported by Gitea Actions, Woodpecker,
+// Drone and other CI, since there is no workflow run API common to Gitea and Forgejo versions.
+type GiteaClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// giteaRepoPaths caches "owner/repo" by repository ID, shared by all clients
+var giteaRepoPaths sync.Map
+
+// giteaPipelineSHAs maps the pipeline IDs made up from commit statuses to their commit
+var giteaPipelineSHAs sync.Map
+
+// NewGiteaClient creates a new Gitea/Forgejo API client for the instance at baseURL
+func NewGiteaClient(baseURL, token string) *GiteaClient {
+	registerForgeHost(baseURL)
+	return &GiteaClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Transport: gitlabTransport},
+	}
+}
+
+// giteaCommitStatus is a status reported by CI for a commit; its context names the job
+type giteaCommitStatus struct {
+	ID        int    `json:"id"`
+	Status    string `json:"status"`
+	Context   string `json:"context"`
+	TargetURL string `json:"target_url"`
+}
+
+// giteaStatus maps a commit status state to the GitLab pipeline/job status names the observer counts
+func giteaStatus(state string) string {
+	switch state {
+	case "success", "warning":
+		return "success"
+	case "failure", "error":
+		return "failed"
+	case "pending":
+		return "pending"
+	}
+	return "created" // No statuses yet
+}
+
+// do sends a request to path (relative to /api/v1) and decodes a 2xx JSON response into out
+func (c *GiteaClient) do(method, path string, payload, out interface{}) error {
+	_, err := c.request(method, path, payload, out)
+	return err
+}
+
+// request is do returning the response headers, which carry the pagination of lists
+func (c *GiteaClient) request(method, path string, payload, out interface{}) (http.Header, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+"/api/v1"+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return nil, fmt.Errorf("invalid token: authentication failed")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("Gitea API error: status %d, %s", resp.StatusCode, apiErr.Message)
+		}
+		return nil, fmt.Errorf("Gitea API error: status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// repoPath returns "owner/repo" of a repository ID
+func (c *GiteaClient) repoPath(projectID int) (string, error) {
+	if path, ok := giteaRepoPaths.Load(projectID); ok {
+		return path.(string), nil
+	}
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if err := c.do("GET", fmt.Sprintf("/repositories/%d", projectID), nil, &repo); err != nil {
+		return "", err
+	}
+	giteaRepoPaths.Store(projectID, repo.FullName)
+	return repo.FullName, nil
+}
+
+// GetUserEmails retrieves the authenticated user's emails
+func (c *GiteaClient) GetUserEmails() ([]string, error) {
+	var emails []struct {
+		Email string `json:"email"`
+	}
+	if err := c.do("GET", "/user/emails", nil, &emails); err != nil {
+		return nil, err
+	}
+	result := make([]string, len(emails))
+	for i, e := range emails {
+		result[i] = e.Email
+	}
+	return result, nil
+}
+
+// GetTokenExpiry returns nil: Gitea and Forgejo access tokens do not expire
+func (c *GiteaClient) GetTokenExpiry() (*time.Time, error) {
+	return nil, nil
+}
+
+// giteaPageSize is the page size of lists; Gitea caps pages at 50 items by default
+const giteaPageSize = 50
+
+// GetProjects fetches the first page of repositories the user has access to
+func (c *GiteaClient) GetProjects() ([]Project, error) {
+	projects, _, err := c.GetProjectsPage(1, projectQuery{})
+	return projects, err
+}
+
+// GetProjectsPage fetches a page of repositories the user has access to, recently updated first,
+// or the repositories the user has starred or owns. Only the search filters out archived
+// repositories; they are dropped from the other lists.
+func (c *GiteaClient) GetProjectsPage(page int, query projectQuery) ([]Project, listPage, error) {
+	type giteaRepo struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+		Archived bool   `json:"archived"`
+	}
+	var repos []giteaRepo
+	var header http.Header
+	var err error
+	switch query.Scope {
+	case projectScopeStarred:
+		header, err = c.request("GET", fmt.Sprintf("/user/starred?limit=%d&page=%d", giteaPageSize, page), nil, &repos)
+	case projectScopeOwned:
+		header, err = c.request("GET", fmt.Sprintf("/user/repos?limit=%d&page=%d", giteaPageSize, page), nil, &repos)
+	default:
+		var result struct {
+			Data []giteaRepo `json:"data"`
+		}
+		path := fmt.Sprintf("/repos/search?limit=%d&page=%d&sort=updated&order=desc", giteaPageSize, page)
+		if !query.Archived {
+			path += "&archived=false"
+		}
+		header, err = c.request("GET", path, nil, &result)
+		repos = result.Data
+	}
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+
+	projects := make([]Project, 0, len(repos))
+	for _, r := range repos {
+		giteaRepoPaths.Store(r.ID, r.FullName)
+		if r.Archived && !query.Archived {
+			continue
+		}
+		projects = append(projects, Project{
+			ID:                r.ID,
+			Name:              r.Name,
+			NameWithNamespace: strings.ReplaceAll(r.FullName, "/", " / "),
+			Path:              r.Name,
+			PathWithNamespace: r.FullName,
+			WebURL:            r.HTMLURL,
+		})
+	}
+	return projects, giteaListPage(header), nil
+}
+
+// giteaListPage reads the pagination of a list from its response headers
+func giteaListPage(header http.Header) listPage {
+	info := listPage{Total: -1, HasMore: linkHasNext(header)}
+	if total, err := strconv.Atoi(header.Get("X-Total-Count")); err == nil {
+		info.Total = total
+	}
+	return info
+}
+
+// GetOpenMergeRequests fetches open pull requests of all repositories the user has access to.
+// Search results are issues without branches, so each pull request is fetched with its details.
+func (c *GiteaClient) GetOpenMergeRequests() ([]*MergeRequestDetails, error) {
+	var issues []struct {
+		Number     int    `json:"number"`
+		HTMLURL    string `json:"html_url"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := c.do("GET", "/repos/issues/search?type=pulls&state=open&limit=50", nil, &issues); err != nil {
+		return nil, err
+	}
+
+	mrs := make([]*MergeRequestDetails, 0, len(issues))
+	for _, issue := range issues {
+		var pull githubPull
+		if err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d", issue.Repository.FullName, issue.Number), nil, &pull); err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, pull.details())
+	}
+	return mrs, nil
+}
+
+// GetProjectMergeRequests fetches the first page of open pull requests of a repository
+func (c *GiteaClient) GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error) {
+	mrs, _, err := c.GetProjectMergeRequestsPage(projectID, 1)
+	return mrs, err
+}
+
+// GetProjectMergeRequestsPage fetches a page of open pull requests of a repository
+func (c *GiteaClient) GetProjectMergeRequestsPage(projectID, page int) ([]*MergeRequestDetails, listPage, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	var pulls []githubPull
+	header, err := c.request("GET", fmt.Sprintf("/repos/%s/pulls?state=open&limit=%d&page=%d", repo, giteaPageSize, page), nil, &pulls)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	return newPullList(pulls), giteaListPage(header), nil
+}
+
+// GetProjectMergeRequestsUpdatedSince fetches the repository's pull requests of any state updated
+// since the given time, reading the most recently updated first until older ones come
+func (c *GiteaClient) GetProjectMergeRequestsUpdatedSince(projectID int, since time.Time) ([]*MergeRequestDetails, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var updated []githubPull
+	for page := 1; ; page++ {
+		var pulls []githubPull
+		header, err := c.request("GET", fmt.Sprintf("/repos/%s/pulls?state=all&sort=recentupdate&limit=%d&page=%d", repo, giteaPageSize, page), nil, &pulls)
+		if err != nil {
+			return nil, err
+		}
+		recent, more := pullsUpdatedSince(pulls, since)
+		updated = append(updated, recent...)
+		if !more || !giteaListPage(header).HasMore {
+			break
+		}
+	}
+	return newPullList(updated), nil
+}
+
+// LoadMergeRequestDetails fetches details for every pull request of a list that does not have them yet
+func (c *GiteaClient) LoadMergeRequestDetails(mrs []*MergeRequestDetails) {
+	for i, mr := range mrs {
+		if mr.DetailsLoaded {
+			continue
+		}
+		if details, err := c.GetMergeRequestDetails(mr.MergeRequest); err == nil {
+			mrs[i] = details
+		}
+	}
+}
+
+// GetMergeRequestDetails fetches the full pull request; its repository is taken from the web URL
+func (c *GiteaClient) GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDetails, error) {
+	// URL format: https://gitea.example.com/owner/repo/pulls/123
+	idx := strings.Index(mr.WebURL, "/pulls/")
+	if idx == -1 {
+		return &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}, nil
+	}
+	parts := strings.Split(mr.WebURL[:idx], "/")
+	if len(parts) < 2 {
+		return &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}, nil
+	}
+	repo := parts[len(parts)-2] + "/" + parts[len(parts)-1]
+
+	var pull githubPull
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, mr.IID), nil, &pull); err != nil {
+		return nil, err
+	}
+	return pull.details(), nil
+}
+
+// getPull fetches a pull request of a repository by number
+func (c *GiteaClient) getPull(projectID, number int) (*githubPull, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var pull githubPull
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), nil, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// GetMergeRequestByIID fetches a pull request by its number
+func (c *GiteaClient) GetMergeRequestByIID(projectID, mrIID int) (*MergeRequestDetails, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return pull.details(), nil
+}
+
+// GetMergeRequestBySourceBranch fetches the most recently updated pull request from a branch (including merged ones).
+// The API cannot filter by head branch, so only the 50 most recently updated pull requests are searched.
+func (c *GiteaClient) GetMergeRequestBySourceBranch(projectID int, sourceBranch string) (*MergeRequestDetails, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var pulls []githubPull
+	if err := c.do("GET", "/repos/"+repo+"/pulls?state=all&sort=recentupdate&limit=50", nil, &pulls); err != nil {
+		return nil, err
+	}
+	for _, pull := range pulls {
+		if pull.Head.Ref == sourceBranch {
+			return pull.details(), nil
+		}
+	}
+	return nil, fmt.Errorf("no MR found for branch %s", sourceBranch)
+}
+
+// GetMergeRequestStatus fetches a pull request to check if it's merged
+func (c *GiteaClient) GetMergeRequestStatus(projectID, mrIID int) (*MergeRequest, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// CreateMergeRequest opens a pull request
+func (c *GiteaClient) CreateMergeRequest(projectID int, sourceBranch, targetBranch, title, description string) (*MergeRequest, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{
+		"head":  sourceBranch,
+		"base":  targetBranch,
+		"title": title,
+		"body":  description,
+	}
+	var pull githubPull
+	if err := c.do("POST", "/repos/"+repo+"/pulls", payload, &pull); err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// GetMergeRequestChangedFiles lists the paths of files changed by a pull request
+func (c *GiteaClient) GetMergeRequestChangedFiles(projectID, mrIID int) ([]string, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for page := 1; page <= maxChangedFilePages; page++ {
+		var changed []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		}
+		header, err := c.request("GET", fmt.Sprintf("/repos/%s/pulls/%d/files?limit=%d&page=%d", repo, mrIID, listPageSize, page), nil, &changed)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range changed {
+			files = appendChangedFile(files, f.Filename, f.PreviousFilename)
+		}
+		if !linkHasNext(header) {
+			break
+		}
+	}
+	return files, nil
+}
+
+// CreateMergeRequestNote adds a comment to a pull request and returns its ID
+func (c *GiteaClient) CreateMergeRequestNote(projectID, mrIID int, body string) (int, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var comment struct {
+		ID int `json:"id"`
+	}
+	err = c.do("POST", fmt.Sprintf("/repos/%s/issues/%d/comments", repo, mrIID), map[string]string{"body": body}, &comment)
+	return comment.ID, err
+}
+
+// UpdateMergeRequestNote replaces the body of a pull request comment
+func (c *GiteaClient) UpdateMergeRequestNote(projectID, mrIID, noteID int, body string) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	return c.do("PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", repo, noteID), map[string]string{"body": body}, nil)
+}
+
+// ApproveMergeRequest submits an approving review of a pull request
+func (c *GiteaClient) ApproveMergeRequest(projectID, mrIID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	return c.do("POST", fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, mrIID), map[string]string{"event": "APPROVED"}, nil)
+}
+
+// RebaseMergeRequest rebases the head branch of a pull request onto its base branch
+func (c *GiteaClient) RebaseMergeRequest(projectID, mrIID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	return c.do("POST", fmt.Sprintf("/repos/%s/pulls/%d/update?style=rebase", repo, mrIID), nil, nil)
+}
+
+// SetMergeRequestDraft marks a pull request as work in progress or ready by its "WIP:" title
+// prefix and returns the new title
+func (c *GiteaClient) SetMergeRequestDraft(projectID, mrIID int, title string, draft bool) (string, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return title, err
+	}
+	title = draftTitle(title, draft, "WIP:")
+	return title, c.do("PATCH", fmt.Sprintf("/repos/%s/pulls/%d", repo, mrIID), map[string]string{"title": title}, nil)
+}
+
+// AddMergeRequestLabels adds labels to a pull request through its issue, keeping its other
+// labels. The labels must exist in the repository.
+func (c *GiteaClient) AddMergeRequestLabels(projectID, mrIID int, labels []string) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	return c.do("POST", fmt.Sprintf("/repos/%s/issues/%d/labels", repo, mrIID), map[string][]string{"labels": labels}, nil)
+}
+
+// FindMilestone returns the ID of the milestone with a title, open or closed
+func (c *GiteaClient) FindMilestone(projectID int, title string) (int, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var milestones []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/milestones?state=all&name=%s", repo, url.QueryEscape(title)), nil, &milestones); err != nil {
+		return 0, err
+	}
+	for _, milestone := range milestones {
+		if milestone.Title == title {
+			return milestone.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no milestone %q in the repository", title)
+}
+
+// SetMergeRequestMilestone sets the milestone of a pull request through its issue
+func (c *GiteaClient) SetMergeRequestMilestone(projectID, mrIID, milestoneID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	return c.do("PATCH", fmt.Sprintf("/repos/%s/issues/%d", repo, mrIID), map[string]int{"milestone": milestoneID}, nil)
+}
+
+// GetMergeRequestPipelines fetches the pipeline of a pull request's head commit
+func (c *GiteaClient) GetMergeRequestPipelines(projectID, mrIID int) ([]Pipeline, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPipelinesByCommit(projectID, pull.Head.SHA)
+}
+
+// commitStatuses fetches the latest status per context of a commit and its combined state
+func (c *GiteaClient) commitStatuses(repo, sha string) (string, []giteaCommitStatus, error) {
+	var combined struct {
+		State    string              `json:"state"`
+		Statuses []giteaCommitStatus `json:"statuses"`
+	}
+	if err := c.do("GET", fmt.Sprintf("/repos/%s/commits/%s/status", repo, url.PathEscape(sha)), nil, &combined); err != nil {
+		return "", nil, err
+	}
+	return combined.State, combined.Statuses, nil
+}
+
+// GetPipelinesByCommit returns the commit statuses as one pipeline, or none if CI has not reported yet.
+// Its ID is the newest status ID, so it changes when CI reports again.
+func (c *GiteaClient) GetPipelinesByCommit(projectID int, sha string) ([]Pipeline, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	state, statuses, err := c.commitStatuses(repo, sha)
+	if err != nil || len(statuses) == 0 {
+		return nil, err
+	}
+
+	pipelineID := 0
+	for _, s := range statuses {
+		pipelineID = max(pipelineID, s.ID)
+	}
+	giteaPipelineSHAs.Store(pipelineID, sha)
+	return []Pipeline{{
+		ID:     pipelineID,
+		Status: giteaStatus(state),
+		WebURL: c.baseURL + "/" + repo + "/commit/" + sha,
+	}}, nil
+}
+
+// GetRunningPipelines is not supported: pipelines are the commit statuses of a single commit
+func (c *GiteaClient) GetRunningPipelines(projectID int) ([]Pipeline, error) {
+	return nil, errRunningPipelinesUnsupported
+}
+
+// GetPipelineJobs returns the statuses of the pipeline's commit as jobs, one per context
+func (c *GiteaClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error) {
+	sha, ok := giteaPipelineSHAs.Load(pipelineID)
+	if !ok {
+		return nil, fmt.Errorf("unknown pipeline %d", pipelineID)
+	}
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	_, statuses, err := c.commitStatuses(repo, sha.(string))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]PipelineJob, len(statuses))
+	for i, s := range statuses {
+		jobs[i] = PipelineJob{ID: s.ID, Name: s.Context, Status: giteaStatus(s.Status), WebURL: s.TargetURL}
+	}
+	return jobs, nil
+}
+
+// GetRepositoryFile returns a file of the repository's default branch, nil if it does not exist
+func (c *GiteaClient) GetRepositoryFile(projectID int, path string) ([]byte, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/v1/repos/%s/raw/%s", c.baseURL, repo, escapeFilePath(path))
+	return getRawFile(c.client, url, map[string]string{"Authorization": "token " + c.token}, "Gitea")
+}
+
+// GetTags returns the tags of a repository whose name starts with prefix, newest first
+func (c *GiteaClient) GetTags(projectID int, prefix string) ([]Tag, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	for page := 1; page <= maxTagPages; page++ {
+		var result []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				SHA string `json:"sha"`
+			} `json:"commit"`
+		}
+		header, err := c.request("GET", fmt.Sprintf("/repos/%s/tags?limit=%d&page=%d", repo, listPageSize, page), nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range result {
+			if strings.HasPrefix(t.Name, prefix) {
+				tags = append(tags, Tag{Name: t.Name, Commit: t.Commit.SHA})
+			}
+		}
+		if !giteaListPage(header).HasMore {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// CreateRelease creates the release of an existing tag and returns its web URL
+func (c *GiteaClient) CreateRelease(projectID int, tag, name, description string) (string, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return "", err
+	}
+	var release struct {
+		HTMLURL string `json:"html_url"`
+	}
+	payload := map[string]string{"tag_name": tag, "name": name, "body": description}
+	if err := c.do("POST", fmt.Sprintf("/repos/%s/releases", repo), payload, &release); err != nil {
+		return "", err
+	}
+	return release.HTMLURL, nil
+}
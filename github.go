@@ -0,0 +1,763 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitHubClient handles GitHub API requests (github.com or GitHub Enterprise Server).
+// Projects are repositories, addressed by their numeric ID like GitLab projects.
+type GitHubClient struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// githubRepoPaths caches "owner/repo" by repository ID, shared by all clients
+var githubRepoPaths sync.Map
+
+// NewGitHubClient creates a new GitHub API client for the instance at baseURL
+func NewGitHubClient(baseURL, token string) *GitHubClient {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	apiURL := baseURL + "/api/v3"
+	if u, err := url.Parse(baseURL); err == nil && (u.Hostname() == "github.com" || u.Hostname() == "api.github.com") {
+		apiURL = "https://api.github.com"
+	}
+	registerForgeHost(apiURL)
+	return &GitHubClient{
+		apiURL: apiURL,
+		token:  token,
+		client: &http.Client{Transport: gitlabTransport},
+	}
+}
+
+// githubPull is a pull request as returned by the API.
+// Counts, mergeability and merge commit are only filled in when a single pull request is fetched.
+type githubPull struct {
+	ID        int        `json:"id"`
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	Draft     bool       `json:"draft"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+	HTMLURL   string     `json:"html_url"`
+	User      struct {
+		ID    int    `json:"id"`
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+	Mergeable      *bool  `json:"mergeable"`
+	Comments       int    `json:"comments"`
+	ReviewComments int    `json:"review_comments"`
+	Commits        int    `json:"commits"`
+	Additions      int    `json:"additions"`
+	Deletions      int    `json:"deletions"`
+	ChangedFiles   int    `json:"changed_files"`
+}
+
+// mergeRequest converts the pull request to the GitLab-shaped MergeRequest used across relix
+func (p githubPull) mergeRequest() MergeRequest {
+	mr := MergeRequest{
+		ID:             p.ID,
+		IID:            p.Number,
+		Title:          p.Title,
+		Description:    p.Body,
+		SourceBranch:   p.Head.Ref,
+		TargetBranch:   p.Base.Ref,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		Draft:          p.Draft,
+		WebURL:         p.HTMLURL,
+		UserNotesCount: p.Comments + p.ReviewComments,
+		HasConflicts:   p.Mergeable != nil && !*p.Mergeable,
+		SHA:            p.Head.SHA,
+		MergeCommitSHA: p.MergeCommitSHA,
+		// Review threads are only reported by the GraphQL API, so there is no blocking state
+		BlockingDiscussionsResolved: true,
+	}
+	if p.Mergeable != nil {
+		mr.MergeStatus = "cannot_be_merged"
+		if *p.Mergeable {
+			mr.MergeStatus = "can_be_merged"
+		}
+	}
+	switch {
+	case p.MergedAt != nil:
+		mr.State = "merged"
+	case p.State == "open":
+		mr.State = "opened"
+	default:
+		mr.State = p.State
+	}
+	if p.ChangedFiles > 0 {
+		mr.ChangesCount = strconv.Itoa(p.ChangedFiles)
+	}
+	mr.Author.ID = p.User.ID
+	mr.Author.Username = p.User.Login
+	mr.Author.Name = p.User.Login
+	return mr
+}
+
+// details converts a fully fetched pull request to MergeRequestDetails
+func (p githubPull) details() *MergeRequestDetails {
+	details := &MergeRequestDetails{MergeRequest: p.mergeRequest(), DetailsLoaded: true}
+	details.DiffStats.Additions = p.Additions
+	details.DiffStats.Deletions = p.Deletions
+	details.CommitsCount = p.Commits
+	return details
+}
+
+// githubRun is an Actions workflow run or job; both report status and conclusion the same way
+type githubRun struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+	HeadBranch string `json:"head_branch"` // Runs only
+}
+
+// pipelineStatus maps a run or job state to the GitLab pipeline/job status names the observer counts
+func (r githubRun) pipelineStatus() string {
+	if r.Status != "completed" {
+		if r.Status == "in_progress" {
+			return "running"
+		}
+		return "pending" // queued, requested, waiting, pending
+	}
+	switch r.Conclusion {
+	case "success":
+		return "success"
+	case "cancelled":
+		return "canceled"
+	case "skipped", "neutral":
+		return "skipped"
+	case "action_required":
+		return "manual"
+	default:
+		return "failed" // failure, timed_out, startup_failure, stale
+	}
+}
+
+// do sends a request to path (relative to the API URL) and decodes a 2xx JSON response into out.
+// It returns the response for its headers; the body is already closed.
+func (c *GitHubClient) do(method, path string, payload, out interface{}) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.apiURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return resp, fmt.Errorf("invalid token: authentication failed")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return resp, fmt.Errorf("GitHub API error: status %d, %s", resp.StatusCode, apiErr.Message)
+		}
+		return resp, fmt.Errorf("GitHub API error: status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// repoPath returns "owner/repo" of a repository ID
+func (c *GitHubClient) repoPath(projectID int) (string, error) {
+	if path, ok := githubRepoPaths.Load(projectID); ok {
+		return path.(string), nil
+	}
+	var repo struct {
+		FullName string `json:"full_name"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repositories/%d", projectID), nil, &repo); err != nil {
+		return "", err
+	}
+	githubRepoPaths.Store(projectID, repo.FullName)
+	return repo.FullName, nil
+}
+
+// GetUserEmails retrieves the authenticated user's emails.
+// Tokens without access to the email list fall back to the public profile email.
+func (c *GitHubClient) GetUserEmails() ([]string, error) {
+	var emails []struct {
+		Email string `json:"email"`
+	}
+	resp, err := c.do("GET", "/user/emails", nil, &emails)
+	if err == nil {
+		result := make([]string, len(emails))
+		for i, e := range emails {
+			result[i] = e.Email
+		}
+		return result, nil
+	}
+	if resp == nil || (resp.StatusCode != 403 && resp.StatusCode != 404) {
+		return nil, err
+	}
+
+	var user struct {
+		Email string `json:"email"`
+	}
+	if _, err := c.do("GET", "/user", nil, &user); err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, nil
+	}
+	return []string{user.Email}, nil
+}
+
+// GetTokenExpiry returns the expiry date of the token, which GitHub reports in a response header.
+// It returns nil if the token never expires.
+func (c *GitHubClient) GetTokenExpiry() (*time.Time, error) {
+	resp, err := c.do("GET", "/user", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	header := resp.Header.Get("GitHub-Authentication-Token-Expiration")
+	if header == "" {
+		return nil, nil
+	}
+	expiresAt, err := time.Parse("2006-01-02 15:04:05 MST", header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token expiry: %w", err)
+	}
+	return &expiresAt, nil
+}
+
+// GetProjects fetches the first page of repositories the user has access to
+func (c *GitHubClient) GetProjects() ([]Project, error) {
+	projects, _, err := c.GetProjectsPage(1, projectQuery{})
+	return projects, err
+}
+
+// GetProjectsPage fetches a page of repositories the user has access to (or has starred or owns),
+// recently pushed first. GitHub does not report the number of repositories or filter archived
+// ones, so they are dropped from the page.
+func (c *GitHubClient) GetProjectsPage(page int, query projectQuery) ([]Project, listPage, error) {
+	var repos []struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+		Archived bool   `json:"archived"`
+	}
+	path := fmt.Sprintf("/user/repos?per_page=%d&page=%d&sort=pushed", listPageSize, page)
+	switch query.Scope {
+	case projectScopeStarred:
+		path = fmt.Sprintf("/user/starred?per_page=%d&page=%d&sort=updated", listPageSize, page)
+	case projectScopeOwned:
+		path += "&affiliation=owner"
+	}
+	resp, err := c.do("GET", path, nil, &repos)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+
+	projects := make([]Project, 0, len(repos))
+	for _, r := range repos {
+		githubRepoPaths.Store(r.ID, r.FullName)
+		if r.Archived && !query.Archived {
+			continue
+		}
+		projects = append(projects, Project{
+			ID:                r.ID,
+			Name:              r.Name,
+			NameWithNamespace: strings.ReplaceAll(r.FullName, "/", " / "),
+			Path:              r.Name,
+			PathWithNamespace: r.FullName,
+			WebURL:            r.HTMLURL,
+		})
+	}
+	return projects, listPage{Total: -1, HasMore: linkHasNext(resp.Header)}, nil
+}
+
+// GetOpenMergeRequests fetches open pull requests involving the user.
+// Search results are issues without branches, so each pull request is fetched with its details.
+func (c *GitHubClient) GetOpenMergeRequests() ([]*MergeRequestDetails, error) {
+	var result struct {
+		Items []githubPull `json:"items"`
+	}
+	if _, err := c.do("GET", "/search/issues?q="+url.QueryEscape("is:pr is:open involves:@me")+"&per_page=50", nil, &result); err != nil {
+		return nil, err
+	}
+	mrs := make([]*MergeRequestDetails, 0, len(result.Items))
+	for _, item := range result.Items {
+		details, err := c.GetMergeRequestDetails(item.mergeRequest())
+		if err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, details)
+	}
+	return mrs, nil
+}
+
+// GetProjectMergeRequests fetches the first page of open pull requests of a repository
+func (c *GitHubClient) GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error) {
+	mrs, _, err := c.GetProjectMergeRequestsPage(projectID, 1)
+	return mrs, err
+}
+
+// GetProjectMergeRequestsPage fetches a page of open pull requests of a repository
+func (c *GitHubClient) GetProjectMergeRequestsPage(projectID, page int) ([]*MergeRequestDetails, listPage, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	var pulls []githubPull
+	resp, err := c.do("GET", fmt.Sprintf("/repos/%s/pulls?state=open&per_page=%d&page=%d", repo, listPageSize, page), nil, &pulls)
+	if err != nil {
+		return nil, listPage{Total: -1}, err
+	}
+	return newPullList(pulls), listPage{Total: -1, HasMore: linkHasNext(resp.Header)}, nil
+}
+
+// GetProjectMergeRequestsUpdatedSince fetches the repository's pull requests of any state updated
+// since the given time, reading the most recently updated first until older ones come
+func (c *GitHubClient) GetProjectMergeRequestsUpdatedSince(projectID int, since time.Time) ([]*MergeRequestDetails, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var updated []githubPull
+	for page := 1; ; page++ {
+		var pulls []githubPull
+		resp, err := c.do("GET", fmt.Sprintf("/repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=%d&page=%d", repo, listPageSize, page), nil, &pulls)
+		if err != nil {
+			return nil, err
+		}
+		recent, more := pullsUpdatedSince(pulls, since)
+		updated = append(updated, recent...)
+		if !more || !linkHasNext(resp.Header) {
+			break
+		}
+	}
+	return newPullList(updated), nil
+}
+
+// pullsUpdatedSince returns the pull requests of a page sorted by update time, newest first, that
+// were updated since the given time, and whether the next page may have more
+func pullsUpdatedSince(pulls []githubPull, since time.Time) ([]githubPull, bool) {
+	for i, p := range pulls {
+		if p.UpdatedAt.Before(since) {
+			return pulls[:i], false
+		}
+	}
+	return pulls, true
+}
+
+// newPullList wraps listed pull requests without fetching their details
+func newPullList(pulls []githubPull) []*MergeRequestDetails {
+	result := make([]*MergeRequestDetails, len(pulls))
+	for i, p := range pulls {
+		result[i] = &MergeRequestDetails{MergeRequest: p.mergeRequest()}
+	}
+	return result
+}
+
+// LoadMergeRequestDetails fetches details for every pull request of a list that does not have them yet
+func (c *GitHubClient) LoadMergeRequestDetails(mrs []*MergeRequestDetails) {
+	for i, mr := range mrs {
+		if mr.DetailsLoaded {
+			continue
+		}
+		if details, err := c.GetMergeRequestDetails(mr.MergeRequest); err == nil {
+			mrs[i] = details
+		}
+	}
+}
+
+// GetMergeRequestDetails fetches the full pull request; its repository is taken from the web URL
+func (c *GitHubClient) GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDetails, error) {
+	// URL format: https://github.com/owner/repo/pull/123
+	idx := strings.Index(mr.WebURL, "/pull/")
+	if idx == -1 {
+		return &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}, nil
+	}
+	parts := strings.Split(mr.WebURL[:idx], "/")
+	if len(parts) < 2 {
+		return &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}, nil
+	}
+	repo := parts[len(parts)-2] + "/" + parts[len(parts)-1]
+
+	var pull githubPull
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, mr.IID), nil, &pull); err != nil {
+		return nil, err
+	}
+	details := pull.details()
+	details.ID = mr.ID // Search results carry the issue ID; lists match details by the listed ID
+
+	// How far the head is behind the base branch; best effort, the pull request is loaded anyway
+	var compare struct {
+		BehindBy int `json:"behind_by"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/compare/%s...%s", repo, pull.Base.Ref, pull.Head.SHA), nil, &compare); err == nil {
+		details.BehindCount = compare.BehindBy
+		details.BehindKnown = true
+	}
+	return details, nil
+}
+
+// getPull fetches a pull request of a repository by number
+func (c *GitHubClient) getPull(projectID, number int) (*githubPull, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var pull githubPull
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), nil, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// GetMergeRequestByIID fetches a pull request by its number
+func (c *GitHubClient) GetMergeRequestByIID(projectID, mrIID int) (*MergeRequestDetails, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return pull.details(), nil
+}
+
+// GetMergeRequestBySourceBranch fetches the most recently updated pull request from a branch (including merged ones)
+func (c *GitHubClient) GetMergeRequestBySourceBranch(projectID int, sourceBranch string) (*MergeRequestDetails, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	owner := strings.SplitN(repo, "/", 2)[0]
+	var pulls []githubPull
+	path := fmt.Sprintf("/repos/%s/pulls?state=all&head=%s&sort=updated&direction=desc&per_page=1",
+		repo, url.QueryEscape(owner+":"+sourceBranch))
+	if _, err := c.do("GET", path, nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, fmt.Errorf("no MR found for branch %s", sourceBranch)
+	}
+	return c.GetMergeRequestByIID(projectID, pulls[0].Number)
+}
+
+// GetMergeRequestStatus fetches a pull request to check if it's merged
+func (c *GitHubClient) GetMergeRequestStatus(projectID, mrIID int) (*MergeRequest, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// CreateMergeRequest opens a pull request
+func (c *GitHubClient) CreateMergeRequest(projectID int, sourceBranch, targetBranch, title, description string) (*MergeRequest, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{
+		"head":  sourceBranch,
+		"base":  targetBranch,
+		"title": title,
+		"body":  description,
+	}
+	var pull githubPull
+	if _, err := c.do("POST", "/repos/"+repo+"/pulls", payload, &pull); err != nil {
+		return nil, err
+	}
+	mr := pull.mergeRequest()
+	return &mr, nil
+}
+
+// GetMergeRequestChangedFiles lists the paths of files changed by a pull request
+func (c *GitHubClient) GetMergeRequestChangedFiles(projectID, mrIID int) ([]string, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for page := 1; page <= maxChangedFilePages; page++ {
+		var changed []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		}
+		resp, err := c.do("GET", fmt.Sprintf("/repos/%s/pulls/%d/files?per_page=%d&page=%d", repo, mrIID, listPageSize, page), nil, &changed)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range changed {
+			files = appendChangedFile(files, f.Filename, f.PreviousFilename)
+		}
+		if !linkHasNext(resp.Header) {
+			break
+		}
+	}
+	return files, nil
+}
+
+// CreateMergeRequestNote adds a comment to a pull request and returns its ID
+func (c *GitHubClient) CreateMergeRequestNote(projectID, mrIID int, body string) (int, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var comment struct {
+		ID int `json:"id"`
+	}
+	_, err = c.do("POST", fmt.Sprintf("/repos/%s/issues/%d/comments", repo, mrIID), map[string]string{"body": body}, &comment)
+	return comment.ID, err
+}
+
+// UpdateMergeRequestNote replaces the body of a pull request comment
+func (c *GitHubClient) UpdateMergeRequestNote(projectID, mrIID, noteID int, body string) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", repo, noteID), map[string]string{"body": body}, nil)
+	return err
+}
+
+// ApproveMergeRequest submits an approving review of a pull request
+func (c *GitHubClient) ApproveMergeRequest(projectID, mrIID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("POST", fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, mrIID), map[string]string{"event": "APPROVE"}, nil)
+	return err
+}
+
+// RebaseMergeRequest brings a pull request up to date with its base branch. The REST API only
+// merges the base branch in; it cannot rebase.
+func (c *GitHubClient) RebaseMergeRequest(projectID, mrIID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("PUT", fmt.Sprintf("/repos/%s/pulls/%d/update-branch", repo, mrIID), map[string]string{}, nil)
+	return err
+}
+
+// SetMergeRequestDraft is not offered: the REST API cannot convert pull requests to draft
+func (c *GitHubClient) SetMergeRequestDraft(projectID, mrIID int, title string, draft bool) (string, error) {
+	return title, errMRActionUnsupported
+}
+
+// AddMergeRequestLabels adds labels to a pull request through its issue, keeping its other
+// labels. Labels the repository does not have yet are created.
+func (c *GitHubClient) AddMergeRequestLabels(projectID, mrIID int, labels []string) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("POST", fmt.Sprintf("/repos/%s/issues/%d/labels", repo, mrIID), map[string][]string{"labels": labels}, nil)
+	return err
+}
+
+// FindMilestone returns the number of the milestone with a title, open or closed
+func (c *GitHubClient) FindMilestone(projectID int, title string) (int, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return 0, err
+	}
+	var milestones []struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/milestones?state=all&per_page=100", repo), nil, &milestones); err != nil {
+		return 0, err
+	}
+	for _, milestone := range milestones {
+		if milestone.Title == title {
+			return milestone.Number, nil
+		}
+	}
+	return 0, fmt.Errorf("no milestone %q in the repository", title)
+}
+
+// SetMergeRequestMilestone sets the milestone of a pull request through its issue
+func (c *GitHubClient) SetMergeRequestMilestone(projectID, mrIID, milestoneID int) error {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return err
+	}
+	_, err = c.do("PATCH", fmt.Sprintf("/repos/%s/issues/%d", repo, mrIID), map[string]int{"milestone": milestoneID}, nil)
+	return err
+}
+
+// GetMergeRequestPipelines fetches the workflow runs of a pull request's head commit
+func (c *GitHubClient) GetMergeRequestPipelines(projectID, mrIID int) ([]Pipeline, error) {
+	pull, err := c.getPull(projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPipelinesByCommit(projectID, pull.Head.SHA)
+}
+
+// GetPipelinesByCommit fetches workflow runs for a commit, newest first
+func (c *GitHubClient) GetPipelinesByCommit(projectID int, sha string) ([]Pipeline, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		WorkflowRuns []githubRun `json:"workflow_runs"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/actions/runs?head_sha=%s&per_page=20", repo, url.QueryEscape(sha)), nil, &result); err != nil {
+		return nil, err
+	}
+
+	pipelines := make([]Pipeline, len(result.WorkflowRuns))
+	for i, run := range result.WorkflowRuns {
+		pipelines[i] = Pipeline{ID: run.ID, Status: run.pipelineStatus(), WebURL: run.HTMLURL}
+	}
+	return pipelines, nil
+}
+
+// GetRunningPipelines fetches the workflow runs in progress in a repository, newest first
+func (c *GitHubClient) GetRunningPipelines(projectID int) ([]Pipeline, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		WorkflowRuns []githubRun `json:"workflow_runs"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/actions/runs?status=in_progress&per_page=20", repo), nil, &result); err != nil {
+		return nil, err
+	}
+
+	pipelines := make([]Pipeline, len(result.WorkflowRuns))
+	for i, run := range result.WorkflowRuns {
+		pipelines[i] = Pipeline{ID: run.ID, Status: run.pipelineStatus(), WebURL: run.HTMLURL, Ref: run.HeadBranch}
+	}
+	return pipelines, nil
+}
+
+// GetPipelineJobs fetches the jobs of a workflow run
+func (c *GitHubClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Jobs []githubRun `json:"jobs"`
+	}
+	if _, err := c.do("GET", fmt.Sprintf("/repos/%s/actions/runs/%d/jobs?per_page=100", repo, pipelineID), nil, &result); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]PipelineJob, len(result.Jobs))
+	for i, job := range result.Jobs {
+		jobs[i] = PipelineJob{ID: job.ID, Name: job.Name, Status: job.pipelineStatus(), WebURL: job.HTMLURL}
+	}
+	return jobs, nil
+}
+
+// GetRepositoryFile returns a file of the repository's default branch, nil if it does not exist
+func (c *GitHubClient) GetRepositoryFile(projectID int, path string) ([]byte, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return getRawFile(c.client, fmt.Sprintf("%s/repos/%s/contents/%s", c.apiURL, repo, escapeFilePath(path)), map[string]string{
+		"Authorization":        "Bearer " + c.token,
+		"Accept":               "application/vnd.github.raw+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}, "GitHub")
+}
+
+// GetTags returns the tags of a repository whose name starts with prefix, in the order GitHub
+// lists them
+func (c *GitHubClient) GetTags(projectID int, prefix string) ([]Tag, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	for page := 1; page <= maxTagPages; page++ {
+		var result []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				SHA string `json:"sha"`
+			} `json:"commit"`
+		}
+		resp, err := c.do("GET", fmt.Sprintf("/repos/%s/tags?per_page=%d&page=%d", repo, listPageSize, page), nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range result {
+			if strings.HasPrefix(t.Name, prefix) {
+				tags = append(tags, Tag{Name: t.Name, Commit: t.Commit.SHA})
+			}
+		}
+		if !linkHasNext(resp.Header) {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// CreateRelease creates the GitHub release of a tag and returns its web URL. GitHub creates a
+// missing tag on the default branch, so callers check that the tag exists first.
+func (c *GitHubClient) CreateRelease(projectID int, tag, name, description string) (string, error) {
+	repo, err := c.repoPath(projectID)
+	if err != nil {
+		return "", err
+	}
+	var release struct {
+		HTMLURL string `json:"html_url"`
+	}
+	payload := map[string]string{"tag_name": tag, "name": name, "body": description}
+	if _, err := c.do("POST", fmt.Sprintf("/repos/%s/releases", repo), payload, &release); err != nil {
+		return "", err
+	}
+	return release.HTMLURL, nil
+}
@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"mime/multipart"
 	"net/http"
+	neturl "net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,12 +24,62 @@ type GitLabClient struct {
 	client  *http.Client
 }
 
+// gitlabTransport is shared by all GitLab clients, so connections to the instance are pooled across
+// clients instead of being dialed (and TLS-negotiated) per request. It asks for gzip responses and
+// decompresses them transparently; setting Accept-Encoding by hand would turn that off.
+// Requests to busy endpoints are capped by gitlabLimiter (see poll_scheduler.go), and their
+// latency is recorded for /metrics (see metrics.go), and the rate limit it reports for the footer
+// (see api_quota.go). Timeouts and extra headers come from the
+// config (see http_timeouts.go and http_headers.go).
+var gitlabTransport http.RoundTripper = &lazyTransport{build: newGitLabTransport}
+
+// newGitLabTransport tunes the default transport for many small requests to one host
+func newGitLabTransport() http.RoundTripper {
+	timeouts := forgeTimeouts()
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = timeouts.dialer().DialContext
+	t.TLSHandshakeTimeout = timeouts.connect
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 32 // Default is 2: detail requests for a page of MRs would redial
+	t.IdleConnTimeout = 90 * time.Second
+	t.DisableKeepAlives = false
+	t.DisableCompression = false
+	return timeoutTransport{headerTransport{drainingTransport{limitedTransport{tracingTransport{metricsTransport{quotaTransport{t}}}, gitlabLimiter}}}}
+}
+
+// drainingTransport reads what is left of a response body before closing it.
+// A connection only returns to the pool once its body is read to the end, and json.Decoder
+// stops after the value, leaving e.g. a trailing newline unread.
+type drainingTransport struct {
+	base http.RoundTripper
+}
+
+func (t drainingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = drainingBody{resp.Body}
+	return resp, nil
+}
+
+// drainingBody discards up to 64 KB of unread data on Close; larger leftovers are not worth reading
+type drainingBody struct {
+	io.ReadCloser
+}
+
+func (b drainingBody) Close() error {
+	io.Copy(io.Discard, io.LimitReader(b.ReadCloser, 64<<10))
+	return b.ReadCloser.Close()
+}
+
 // NewGitLabClient creates a new GitLab API client
 func NewGitLabClient(baseURL, token string) *GitLabClient {
+	registerForgeHost(baseURL)
 	return &GitLabClient{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		token:   token,
-		client:  &http.Client{Timeout: 10 * time.Second},
+		client:  &http.Client{Transport: gitlabTransport},
 	}
 }
 
@@ -69,7 +126,7 @@ func (c *GitLabClient) GetUserEmails() ([]string, error) {
 
 // ValidateCredentials checks if the credentials are valid and email matches
 func ValidateCredentials(creds Credentials) error {
-	client := NewGitLabClient(creds.GitLabURL, creds.Token)
+	client := NewForge(creds)
 
 	emails, err := client.GetUserEmails()
 	if err != nil {
@@ -83,10 +140,59 @@ func ValidateCredentials(creds Credentials) error {
 		}
 	}
 
-	return fmt.Errorf("email '%s' not found in your GitLab account", creds.Email)
+	return fmt.Errorf("email '%s' not found in your %s account", creds.Email, forgeName(creds))
+}
+
+// GetTokenExpiry returns the expiry date of the personal access token.
+// It returns nil if the token never expires or the GitLab instance does not report it.
+func (c *GitLabClient) GetTokenExpiry() (*time.Time, error) {
+	url := c.baseURL + "/api/v4/personal_access_tokens/self"
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return nil, fmt.Errorf("invalid token: authentication failed")
+	}
+
+	// Older GitLab versions have no such endpoint
+	if resp.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		ExpiresAt string `json:"expires_at"` // YYYY-MM-DD or null
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if token.ExpiresAt == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.ParseInLocation("2006-01-02", token.ExpiresAt, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token expiry: %w", err)
+	}
+	return &expiresAt, nil
 }
 
-// GetOpenMergeRequests fetches open merge requests for the current user
+// GetOpenMergeRequests fetches open merge requests for the current user.
+// Details are not loaded; see GetMergeRequestDetails and LoadMergeRequestDetails.
 func (c *GitLabClient) GetOpenMergeRequests() ([]*MergeRequestDetails, error) {
 	// Get MRs where user is assignee or reviewer
 	url := c.baseURL + "/api/v4/merge_requests?state=opened&scope=all&per_page=100"
@@ -113,23 +219,71 @@ func (c *GitLabClient) GetOpenMergeRequests() ([]*MergeRequestDetails, error) {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Fetch additional details for each MR
-	result := make([]*MergeRequestDetails, 0, len(mrs))
-	for _, mr := range mrs {
-		details, err := c.GetMergeRequestDetails(mr)
-		if err != nil {
-			// Skip MRs we can't get details for
+	return newMergeRequestList(mrs), nil
+}
+
+// newMergeRequestList wraps listed MRs without fetching their details
+func newMergeRequestList(mrs []MergeRequest) []*MergeRequestDetails {
+	result := make([]*MergeRequestDetails, len(mrs))
+	for i, mr := range mrs {
+		result[i] = &MergeRequestDetails{MergeRequest: mr}
+	}
+	return result
+}
+
+// LoadMergeRequestDetails fetches details for every MR of a list that does not have them yet.
+// This costs four requests per MR; the TUI instead loads details of the highlighted MR only.
+func (c *GitLabClient) LoadMergeRequestDetails(mrs []*MergeRequestDetails) {
+	for i, mr := range mrs {
+		if mr.DetailsLoaded {
 			continue
 		}
-		result = append(result, details)
+		if details, err := c.GetMergeRequestDetails(mr.MergeRequest); err == nil {
+			mrs[i] = details
+		}
 	}
+}
 
-	return result, nil
+// gitlabMergeRequest is the response of a single merge request: the fields of listed ones and those
+// only the single request reports
+type gitlabMergeRequest struct {
+	MergeRequest
+	DivergedCommitsCount *int      `json:"diverged_commits_count"` // With include_diverged_commits_count
+	HeadPipeline         *Pipeline `json:"head_pipeline"`
 }
 
-// GetMergeRequestDetails fetches detailed info for a merge request
+// gitlabDiscussion is a discussion of a merge request; its first note tells whether it is a
+// resolvable review thread rather than system notes or comments
+type gitlabDiscussion struct {
+	Notes []struct {
+		Resolvable bool `json:"resolvable"`
+		Resolved   bool `json:"resolved"`
+	} `json:"notes"`
+}
+
+// gitlabApprovals is the approval state of a merge request
+type gitlabApprovals struct {
+	ApprovalsLeft int `json:"approvals_left"`
+	ApprovedBy    []struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+	} `json:"approved_by"`
+}
+
+// approvers returns the usernames of the users who approved
+func (a gitlabApprovals) approvers() []string {
+	usernames := make([]string, 0, len(a.ApprovedBy))
+	for _, approval := range a.ApprovedBy {
+		usernames = append(usernames, approval.User.Username)
+	}
+	return usernames
+}
+
+// GetMergeRequestDetails fetches detailed info for a merge request. The merge request itself must
+// load; its commits, discussions and approvals are best effort, each known only if it loaded.
 func (c *GitLabClient) GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDetails, error) {
-	details := &MergeRequestDetails{MergeRequest: mr}
+	details := &MergeRequestDetails{MergeRequest: mr, DetailsLoaded: true}
 
 	// Extract project path from web URL
 	// URL format: https://gitlab.com/namespace/project/-/merge_requests/123
@@ -138,52 +292,58 @@ func (c *GitLabClient) GetMergeRequestDetails(mr MergeRequest) (*MergeRequestDet
 		return details, nil
 	}
 
-	encodedPath := strings.ReplaceAll(projectPath, "/", "%2F")
-
-	// Get single MR details (includes changes_count)
 	mrURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d",
-		c.baseURL, encodedPath, mr.IID)
-	mrData, err := c.fetchJSON(mrURL)
-	if err == nil {
-		if mrMap, ok := mrData.(map[string]interface{}); ok {
-			if changesCount, ok := mrMap["changes_count"].(string); ok {
-				details.ChangesCount = changesCount
-			}
-		}
+		c.baseURL, neturl.PathEscape(projectPath), mr.IID)
+
+	// Single MR (includes changes_count, the head pipeline and how far the source branch is behind)
+	var single gitlabMergeRequest
+	if _, err := c.fetchPage(mrURL+"?include_diverged_commits_count=true", &single); err != nil {
+		return nil, fmt.Errorf("failed to fetch MR !%d: %w", mr.IID, err)
+	}
+	details.MergeRequest = single.MergeRequest
+	details.HeadPipeline = single.HeadPipeline
+	if single.DivergedCommitsCount != nil {
+		details.BehindCount = *single.DivergedCommitsCount
+		details.BehindKnown = true
 	}
 
-	// Get commits count
-	commitsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/commits",
-		c.baseURL, encodedPath, mr.IID)
-	commits, err := c.fetchJSON(commitsURL)
-	if err == nil {
-		if arr, ok := commits.([]interface{}); ok {
-			details.CommitsCount = len(arr)
+	// Commits count, from the total of the paginated list
+	var commits []struct {
+		ID string `json:"id"`
+	}
+	if page, err := c.fetchPage(mrURL+"/commits?per_page=100", &commits); err == nil {
+		details.CommitsCount = len(commits)
+		if page.Total > details.CommitsCount {
+			details.CommitsCount = page.Total
 		}
 	}
 
-	// Get discussions stats (only count resolvable discussions - actual review threads)
-	discussionsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/discussions",
-		c.baseURL, encodedPath, mr.IID)
-	discussions, err := c.fetchJSON(discussionsURL)
-	if err == nil {
-		if arr, ok := discussions.([]interface{}); ok {
-			for _, d := range arr {
-				if disc, ok := d.(map[string]interface{}); ok {
-					if notes, ok := disc["notes"].([]interface{}); ok && len(notes) > 0 {
-						// Check if first note is resolvable (skip system notes)
-						if note, ok := notes[0].(map[string]interface{}); ok {
-							if resolvable, ok := note["resolvable"].(bool); ok && resolvable {
-								details.DiscussionsTotal++
-								if resolved, ok := note["resolved"].(bool); ok && resolved {
-									details.DiscussionsResolved++
-								}
-							}
-						}
-					}
+	// Discussions stats (only count resolvable discussions - actual review threads)
+	for pageNum := 1; ; pageNum++ {
+		var discussions []gitlabDiscussion
+		page, err := c.fetchPage(fmt.Sprintf("%s/discussions?per_page=100&page=%d", mrURL, pageNum), &discussions)
+		if err != nil {
+			break
+		}
+		for _, d := range discussions {
+			if len(d.Notes) > 0 && d.Notes[0].Resolvable {
+				details.DiscussionsTotal++
+				if d.Notes[0].Resolved {
+					details.DiscussionsResolved++
 				}
 			}
 		}
+		if !page.HasMore {
+			break
+		}
+	}
+
+	// Approvals
+	var approvals gitlabApprovals
+	if _, err := c.fetchPage(mrURL+"/approvals", &approvals); err == nil {
+		details.ApprovedBy = approvals.approvers()
+		details.ApprovalsLeft = approvals.ApprovalsLeft
+		details.ApprovalsKnown = true
 	}
 
 	return details, nil
@@ -216,72 +376,115 @@ func (c *GitLabClient) fetchJSON(url string) (interface{}, error) {
 	return result, nil
 }
 
-// GetProjects fetches projects the user has access to
+// GetProjects fetches the first page of projects the user has access to
 func (c *GitLabClient) GetProjects() ([]Project, error) {
-	url := c.baseURL + "/api/v4/projects?membership=true&per_page=100&order_by=last_activity_at"
+	projects, _, err := c.GetProjectsPage(1, projectQuery{})
+	return projects, err
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// GetProjectsPage fetches a page of projects the user is a member of (or has starred or owns),
+// recently active first
+func (c *GitLabClient) GetProjectsPage(page int, query projectQuery) ([]Project, listPage, error) {
+	url := fmt.Sprintf("%s/api/v4/projects?per_page=%d&page=%d&order_by=last_activity_at", c.baseURL, listPageSize, page)
+	switch query.Scope {
+	case projectScopeStarred:
+		url += "&starred=true"
+	case projectScopeOwned:
+		url += "&owned=true"
+	default:
+		url += "&membership=true"
+	}
+	if !query.Archived {
+		url += "&archived=false"
 	}
 
-	req.Header.Set("PRIVATE-TOKEN", c.token)
-
-	resp, err := c.client.Do(req)
+	var projects []Project
+	info, err := c.fetchPage(url, &projects)
 	if err != nil {
-		return nil, fmt.Errorf("network error: %w", err)
+		return nil, info, err
 	}
-	defer resp.Body.Close()
+	return projects, info, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+// GetProjectByPath fetches a project the user can access by its path, e.g. "group/app"
+func (c *GitLabClient) GetProjectByPath(path string) (*Project, error) {
+	var project Project
+	if _, err := c.fetchPage(fmt.Sprintf("%s/api/v4/projects/%s", c.baseURL, neturl.PathEscape(path)), &project); err != nil {
+		return nil, err
 	}
+	return &project, nil
+}
 
-	var projects []Project
-	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+// GetProjectMergeRequests fetches the first page of open merge requests for a specific project.
+// Details are not loaded; see GetMergeRequestDetails and LoadMergeRequestDetails.
+func (c *GitLabClient) GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error) {
+	mrs, _, err := c.GetProjectMergeRequestsPage(projectID, 1)
+	return mrs, err
+}
+
+// GetProjectMergeRequestsPage fetches a page of open merge requests for a specific project
+func (c *GitLabClient) GetProjectMergeRequestsPage(projectID, page int) ([]*MergeRequestDetails, listPage, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests?state=opened&per_page=%d&page=%d", c.baseURL, projectID, listPageSize, page)
+
+	var mrs []MergeRequest
+	info, err := c.fetchPage(url, &mrs)
+	if err != nil {
+		return nil, info, err
 	}
+	return newMergeRequestList(mrs), info, nil
+}
 
-	return projects, nil
+// GetProjectMergeRequestsUpdatedSince fetches the project's merge requests of any state updated
+// since the given time, so a refresh only transfers what changed
+func (c *GitLabClient) GetProjectMergeRequestsUpdatedSince(projectID int, since time.Time) ([]*MergeRequestDetails, error) {
+	var all []MergeRequest
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests?state=all&updated_after=%s&order_by=updated_at&per_page=%d&page=%d",
+			c.baseURL, projectID, since.UTC().Format(time.RFC3339), listPageSize, page)
+		var mrs []MergeRequest
+		info, err := c.fetchPage(url, &mrs)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, mrs...)
+		if !info.HasMore {
+			break
+		}
+	}
+	return newMergeRequestList(all), nil
 }
 
-// GetProjectMergeRequests fetches open merge requests for a specific project
-func (c *GitLabClient) GetProjectMergeRequests(projectID int) ([]*MergeRequestDetails, error) {
-	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests?state=opened&per_page=100", c.baseURL, projectID)
+// fetchPage makes a GET request for a page of a list, decodes it into out and reads the
+// pagination headers. GitLab omits X-Total for very large lists.
+func (c *GitLabClient) fetchPage(url string, out interface{}) (listPage, error) {
+	info := listPage{Total: -1}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return info, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("PRIVATE-TOKEN", c.token)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("network error: %w", err)
+		return info, fmt.Errorf("network error: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+		return info, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
 	}
 
-	var mrs []MergeRequest
-	if err := json.NewDecoder(resp.Body).Decode(&mrs); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return info, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Fetch additional details for each MR
-	result := make([]*MergeRequestDetails, 0, len(mrs))
-	for _, mr := range mrs {
-		details, err := c.GetMergeRequestDetails(mr)
-		if err != nil {
-			continue
-		}
-		result = append(result, details)
+	if total, err := strconv.Atoi(resp.Header.Get("X-Total")); err == nil {
+		info.Total = total
 	}
-
-	return result, nil
+	info.HasMore = resp.Header.Get("X-Next-Page") != ""
+	return info, nil
 }
 
 // GetMergeRequestBySourceBranch fetches MR details by source branch name (including merged MRs)
@@ -426,6 +629,237 @@ func (c *GitLabClient) CreateMergeRequest(projectID int, sourceBranch, targetBra
 	return &mr, nil
 }
 
+// GetMergeRequestChangedFiles lists the paths of files changed by a merge request
+func (c *GitLabClient) GetMergeRequestChangedFiles(projectID, mrIID int) ([]string, error) {
+	var files []string
+	for page := 1; page <= maxChangedFilePages; page++ {
+		url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/diffs?per_page=%d&page=%d", c.baseURL, projectID, mrIID, listPageSize, page)
+		var diffs []struct {
+			NewPath string `json:"new_path"`
+			OldPath string `json:"old_path"`
+		}
+		info, err := c.fetchPage(url, &diffs)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range diffs {
+			files = appendChangedFile(files, d.NewPath, d.OldPath)
+		}
+		if !info.HasMore {
+			break
+		}
+	}
+	return files, nil
+}
+
+// CreateMergeRequestNote adds a comment to a merge request and returns its ID
+func (c *GitLabClient) CreateMergeRequestNote(projectID, mrIID int, body string) (int, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/notes", c.baseURL, projectID, mrIID)
+
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var note struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&note); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return note.ID, nil
+}
+
+// UpdateMergeRequestNote replaces the body of a merge request comment
+func (c *GitLabClient) UpdateMergeRequestNote(projectID, mrIID, noteID int, body string) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/notes/%d", c.baseURL, projectID, mrIID, noteID)
+
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// ApproveMergeRequest approves a merge request as the token's user
+func (c *GitLabClient) ApproveMergeRequest(projectID, mrIID int) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/approve", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("POST", url, nil, 201)
+}
+
+// RebaseMergeRequest starts a rebase of the source branch onto the target branch; GitLab runs
+// it in the background
+func (c *GitLabClient) RebaseMergeRequest(projectID, mrIID int) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/rebase", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("PUT", url, nil, 202)
+}
+
+// SetMergeRequestDraft marks a merge request as draft or ready by its "Draft:" title prefix
+// and returns the new title
+func (c *GitLabClient) SetMergeRequestDraft(projectID, mrIID int, title string, draft bool) (string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d", c.baseURL, projectID, mrIID)
+	title = draftTitle(title, draft, "Draft:")
+	return title, c.sendMergeRequestAction("PUT", url, map[string]string{"title": title}, 200)
+}
+
+// AddMergeRequestLabels adds labels to a merge request, keeping its other labels. Labels the
+// project does not have yet are created.
+func (c *GitLabClient) AddMergeRequestLabels(projectID, mrIID int, labels []string) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("PUT", url, map[string]string{"add_labels": strings.Join(labels, ",")}, 200)
+}
+
+// FindMilestone returns the ID of the milestone with a title, in the project or its groups
+func (c *GitLabClient) FindMilestone(projectID int, title string) (int, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/milestones?title=%s&include_ancestors=true", c.baseURL, projectID, neturl.QueryEscape(title))
+	var milestones []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	if _, err := c.fetchPage(url, &milestones); err != nil {
+		return 0, err
+	}
+	for _, milestone := range milestones {
+		if milestone.Title == title {
+			return milestone.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("no milestone %q in the project or its groups", title)
+}
+
+// SetMergeRequestMilestone sets the milestone of a merge request
+func (c *GitLabClient) SetMergeRequestMilestone(projectID, mrIID, milestoneID int) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("PUT", url, map[string]string{"milestone_id": strconv.Itoa(milestoneID)}, 200)
+}
+
+// sendMergeRequestAction sends a merge request request whose response body is not needed
+func (c *GitLabClient) sendMergeRequestAction(method, url string, payload map[string]string, status int) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// CreateWikiPage creates a Markdown wiki page and returns its web URL.
+// Slashes in the title put the page in wiki directories.
+func (c *GitLabClient) CreateWikiPage(projectID int, title, content string) (string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/wikis", c.baseURL, projectID)
+
+	jsonData, err := json.Marshal(map[string]string{
+		"title":   title,
+		"content": content,
+		"format":  "markdown",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var page struct {
+		Slug string `json:"slug"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// The page URL is below the project's web URL
+	project, err := c.fetchJSON(fmt.Sprintf("%s/api/v4/projects/%d", c.baseURL, projectID))
+	if err != nil {
+		return "", nil // Created; only the link is missing
+	}
+	fields, _ := project.(map[string]interface{})
+	webURL, _ := fields["web_url"].(string)
+	if webURL == "" {
+		return "", nil
+	}
+	return webURL + "/-/wikis/" + page.Slug, nil
+}
+
 // GetMergeRequestStatus fetches the status of a merge request to check if it's merged
 func (c *GitLabClient) GetMergeRequestStatus(projectID, mrIID int) (*MergeRequest, error) {
 	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d", c.baseURL, projectID, mrIID)
@@ -513,9 +947,9 @@ func (c *GitLabClient) GetPipelinesByCommit(projectID int, sha string) ([]Pipeli
 	return pipelines, nil
 }
 
-// GetPipelineJobs fetches jobs for a specific pipeline
-func (c *GitLabClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error) {
-	url := fmt.Sprintf("%s/api/v4/projects/%d/pipelines/%d/jobs?per_page=100", c.baseURL, projectID, pipelineID)
+// GetRunningPipelines fetches the running pipelines of a project, newest first
+func (c *GitLabClient) GetRunningPipelines(projectID int) ([]Pipeline, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/pipelines?scope=running&per_page=20", c.baseURL, projectID)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -534,10 +968,656 @@ func (c *GitLabClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob
 		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
 	}
 
-	var jobs []PipelineJob
-	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+	var pipelines []Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return jobs, nil
+	return pipelines, nil
+}
+
+// CreatePipeline runs a pipeline for a ref of a project given by path or ID, with variables and
+// the spec inputs of its .gitlab-ci.yml
+func (c *GitLabClient) CreatePipeline(project, ref string, variables map[string]string, inputs map[string]any) (*Pipeline, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%s/pipeline", c.baseURL, neturl.PathEscape(project))
+
+	type variable struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	payload := struct {
+		Ref       string         `json:"ref"`
+		Variables []variable     `json:"variables,omitempty"`
+		Inputs    map[string]any `json:"inputs,omitempty"`
+	}{Ref: ref, Inputs: inputs}
+	for _, key := range slices.Sorted(maps.Keys(variables)) {
+		payload.Variables = append(payload.Variables, variable{Key: key, Value: variables[key]})
+	}
+	return c.startPipeline(url, payload, true)
+}
+
+// TriggerPipeline runs a pipeline for a ref of a project given by path or ID with a pipeline
+// trigger token, which works without access to the project
+func (c *GitLabClient) TriggerPipeline(project, ref, triggerToken string, variables map[string]string, inputs map[string]any) (*Pipeline, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%s/trigger/pipeline", c.baseURL, neturl.PathEscape(project))
+
+	payload := struct {
+		Token     string            `json:"token"`
+		Ref       string            `json:"ref"`
+		Variables map[string]string `json:"variables,omitempty"`
+		Inputs    map[string]any    `json:"inputs,omitempty"`
+	}{Token: triggerToken, Ref: ref, Variables: variables, Inputs: inputs}
+	return c.startPipeline(url, payload, false)
+}
+
+// startPipeline posts a pipeline creation request and returns the created pipeline
+func (c *GitLabClient) startPipeline(url string, payload interface{}, authenticate bool) (*Pipeline, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if authenticate {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var pipeline Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &pipeline, nil
+}
+
+// GetPipeline fetches a pipeline of a project given by path or ID
+func (c *GitLabClient) GetPipeline(project string, pipelineID int) (*Pipeline, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%s/pipelines/%d", c.baseURL, neturl.PathEscape(project), pipelineID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	var pipeline Pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &pipeline, nil
+}
+
+// GetDefaultBranch fetches the default branch of a project given by path or ID
+func (c *GitLabClient) GetDefaultBranch(project string) (string, error) {
+	data, err := c.fetchJSON(fmt.Sprintf("%s/api/v4/projects/%s", c.baseURL, neturl.PathEscape(project)))
+	if err != nil {
+		return "", err
+	}
+	fields, _ := data.(map[string]interface{})
+	branch, _ := fields["default_branch"].(string)
+	if branch == "" {
+		return "", fmt.Errorf("project %s has no default branch", project)
+	}
+	return branch, nil
+}
+
+// CreateDeployment records a finished deployment of a ref to an environment, which GitLab
+// creates if it does not exist yet
+func (c *GitLabClient) CreateDeployment(projectID int, environment, ref, sha string, tag bool) (*Deployment, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/deployments", c.baseURL, projectID)
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"environment": environment,
+		"ref":         ref,
+		"sha":         sha,
+		"tag":         tag,
+		"status":      "success",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var deployment Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&deployment); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &deployment, nil
+}
+
+// GetLastDeployment fetches the latest successful deployment to an environment, or nil if there is none
+func (c *GitLabClient) GetLastDeployment(projectID int, environment string) (*Deployment, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/deployments?environment=%s&status=success&order_by=id&sort=desc&per_page=1",
+		c.baseURL, projectID, neturl.QueryEscape(environment))
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	var deployments []Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&deployments); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+
+	return &deployments[0], nil
+}
+
+// GetPipelineJobs fetches jobs for a specific pipeline
+func (c *GitLabClient) GetPipelineJobs(projectID, pipelineID int) ([]PipelineJob, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/pipelines/%d/jobs?per_page=100", c.baseURL, projectID, pipelineID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	var jobs []PipelineJob
+	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// DownloadJobArtifacts writes the artifacts archive (zip) of a job to w.
+// Archives can be large, so the download is not bound by the client's request timeout.
+func (c *GitLabClient) DownloadJobArtifacts(projectID, jobID int, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/jobs/%d/artifacts", c.baseURL, projectID, jobID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	client := &http.Client{Transport: c.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return fmt.Errorf("job %d has no artifacts", jobID)
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("download interrupted: %w", err)
+	}
+	return nil
+}
+
+// GetProjectFile returns a file of a project given by path or ID at a ref (default the default
+// branch), nil if it does not exist or the project is not accessible
+func (c *GitLabClient) GetProjectFile(project, ref, path string) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw", c.baseURL, neturl.PathEscape(project), neturl.PathEscape(path))
+	if ref != "" {
+		url += "?ref=" + neturl.QueryEscape(ref)
+	}
+	return getRawFile(c.client, url, map[string]string{"PRIVATE-TOKEN": c.token}, "GitLab")
+}
+
+// GetRepositoryFile returns a file of the project's default branch, nil if it does not exist
+func (c *GitLabClient) GetRepositoryFile(projectID int, path string) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/repository/files/%s/raw", c.baseURL, projectID, neturl.PathEscape(path))
+	return getRawFile(c.client, url, map[string]string{"PRIVATE-TOKEN": c.token}, "GitLab")
+}
+
+// GetRepositoryFileBlob returns a file of a branch along with the last commit that changed it, to
+// be passed to CommitRepositoryFile; content is nil if the file does not exist
+func (c *GitLabClient) GetRepositoryFileBlob(projectID int, branch, path string) (content []byte, lastCommitID string, err error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/repository/files/%s?ref=%s", c.baseURL, projectID, neturl.PathEscape(path), neturl.QueryEscape(branch))
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, "", nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, "", fmt.Errorf("GitLab API error: status %d", resp.StatusCode)
+	}
+
+	var blob struct {
+		Content      string `json:"content"`
+		Encoding     string `json:"encoding"`
+		LastCommitID string `json:"last_commit_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 2*maxRepositoryFileSize)).Decode(&blob); err != nil {
+		return nil, "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	content = []byte(blob.Content)
+	if blob.Encoding == "base64" {
+		if content, err = base64.StdEncoding.DecodeString(blob.Content); err != nil {
+			return nil, "", fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+	}
+	return content, blob.LastCommitID, nil
+}
+
+// GetTags returns the tags of a project whose name starts with prefix, most recently updated first
+func (c *GitLabClient) GetTags(projectID int, prefix string) ([]Tag, error) {
+	var tags []Tag
+	for page := 1; page <= maxTagPages; page++ {
+		url := fmt.Sprintf("%s/api/v4/projects/%d/repository/tags?per_page=%d&page=%d", c.baseURL, projectID, listPageSize, page)
+		if prefix != "" {
+			url += "&search=" + neturl.QueryEscape("^"+prefix)
+		}
+		var result []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				ID string `json:"id"`
+			} `json:"commit"`
+		}
+		info, err := c.fetchPage(url, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range result {
+			tags = append(tags, Tag{Name: t.Name, Commit: t.Commit.ID})
+		}
+		if !info.HasMore {
+			break
+		}
+	}
+	return tags, nil
+}
+
+// CreateRelease creates the GitLab Release of an existing tag and returns its web URL
+func (c *GitLabClient) CreateRelease(projectID int, tag, name, description string) (string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/releases", c.baseURL, projectID)
+	jsonData, err := json.Marshal(map[string]string{"tag_name": tag, "name": name, "description": description})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var release struct {
+		Links struct {
+			Self string `json:"self"`
+		} `json:"_links"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return release.Links.Self, nil
+}
+
+// errRepositoryFileChanged is returned by CommitRepositoryFile when the file was changed (or
+// created) by someone else since it was read
+var errRepositoryFileChanged = errors.New("the file was changed meanwhile")
+
+// CommitRepositoryFile commits the content of a file to a branch. lastCommitID is the one the file
+// was read at (see GetRepositoryFileBlob), empty to create the file; the commit is refused with
+// errRepositoryFileChanged if the file changed since.
+func (c *GitLabClient) CommitRepositoryFile(projectID int, branch, path string, content []byte, lastCommitID, message string) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/repository/files/%s", c.baseURL, projectID, neturl.PathEscape(path))
+	payload := map[string]string{"branch": branch, "content": string(content), "commit_message": message}
+	method, status := "POST", 201
+	if lastCommitID != "" {
+		payload["last_commit_id"] = lastCommitID
+		method, status = "PUT", 200
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		// GitLab refuses a stale last_commit_id, or creating a file that exists, with a 400
+		if resp.StatusCode == 400 && (bytes.Contains(body, []byte("changed since")) || bytes.Contains(body, []byte("already exists"))) {
+			return errRepositoryFileChanged
+		}
+		return fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// GetMergeProtection reports whether the project merges through merge trains or merged results
+// pipelines
+func (c *GitLabClient) GetMergeProtection(projectID int) (*MergeProtection, error) {
+	data, err := c.fetchJSON(fmt.Sprintf("%s/api/v4/projects/%d", c.baseURL, projectID))
+	if err != nil {
+		return nil, err
+	}
+	fields, _ := data.(map[string]interface{})
+	trains, _ := fields["merge_trains_enabled"].(bool)
+	mergedResults, _ := fields["merge_pipelines_enabled"].(bool)
+	return &MergeProtection{MergeTrains: trains, MergedResultsPipelines: mergedResults || trains}, nil
+}
+
+// AddToMergeTrain adds a merge request to the merge train of its target branch, to be merged once
+// its pipeline succeeds
+func (c *GitLabClient) AddToMergeTrain(projectID, mrIID int) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_trains/merge_requests/%d", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("POST", url, map[string]string{"when_pipeline_succeeds": "true"}, 201)
+}
+
+// AcceptMergeRequest merges a merge request, deleting its source branch afterwards with
+// removeSource. It fails while the merge request cannot be merged yet, e.g. before it is approved.
+func (c *GitLabClient) AcceptMergeRequest(projectID, mrIID int, removeSource bool) error {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/merge", c.baseURL, projectID, mrIID)
+	return c.sendMergeRequestAction("PUT", url, map[string]string{"should_remove_source_branch": strconv.FormatBool(removeSource)}, 200)
+}
+
+// CanPushToBranch reports whether the token's user may push to a branch directly, which protected
+// branches forbid
+func (c *GitLabClient) CanPushToBranch(projectID int, branch string) (bool, error) {
+	data, err := c.fetchJSON(fmt.Sprintf("%s/api/v4/projects/%d/repository/branches/%s", c.baseURL, projectID, neturl.PathEscape(branch)))
+	if err != nil {
+		return false, err
+	}
+	fields, _ := data.(map[string]interface{})
+	canPush, _ := fields["can_push"].(bool)
+	return canPush, nil
+}
+
+// GetMergeRequestAwards returns the names of the emoji awarded to a merge request, e.g. "rocket"
+func (c *GitLabClient) GetMergeRequestAwards(projectID, mrIID int) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/award_emoji?per_page=100", c.baseURL, projectID, mrIID)
+	var awards []struct {
+		Name string `json:"name"`
+	}
+	if _, err := c.fetchPage(url, &awards); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(awards))
+	for i, award := range awards {
+		names[i] = award.Name
+	}
+	return names, nil
+}
+
+// GetMergeRequestComments returns the bodies of the comments on a merge request written by
+// people, newest first; system notes are left out
+func (c *GitLabClient) GetMergeRequestComments(projectID, mrIID int) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/notes?sort=desc&order_by=created_at&per_page=100", c.baseURL, projectID, mrIID)
+	var notes []struct {
+		Body   string `json:"body"`
+		System bool   `json:"system"`
+	}
+	if _, err := c.fetchPage(url, &notes); err != nil {
+		return nil, err
+	}
+	var bodies []string
+	for _, note := range notes {
+		if !note.System {
+			bodies = append(bodies, note.Body)
+		}
+	}
+	return bodies, nil
+}
+
+// GetPipelineSchedules returns the active pipeline schedules of the project
+func (c *GitLabClient) GetPipelineSchedules(projectID int) ([]PipelineSchedule, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/pipeline_schedules?scope=active&per_page=100", c.baseURL, projectID)
+	var schedules []PipelineSchedule
+	if _, err := c.fetchPage(url, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetRunningScheduledPipelines returns the running and pending pipelines of the project started by
+// a schedule
+func (c *GitLabClient) GetRunningScheduledPipelines(projectID int) ([]Pipeline, error) {
+	var pipelines []Pipeline
+	for _, scope := range []string{"running", "pending"} {
+		url := fmt.Sprintf("%s/api/v4/projects/%d/pipelines?source=schedule&scope=%s&per_page=20", c.baseURL, projectID, scope)
+		var page []Pipeline
+		if _, err := c.fetchPage(url, &page); err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, page...)
+	}
+	return pipelines, nil
+}
+
+// GetMergeRequestApprovers returns the usernames of the users who approved the merge request
+func (c *GitLabClient) GetMergeRequestApprovers(projectID, mrIID int) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/merge_requests/%d/approvals", c.baseURL, projectID, mrIID)
+	var approvals gitlabApprovals
+	if _, err := c.fetchPage(url, &approvals); err != nil {
+		return nil, err
+	}
+	return approvals.approvers(), nil
+}
+
+// UploadProjectFile uploads a file to the project's uploads and returns its absolute URL
+func (c *GitLabClient) UploadProjectFile(projectID int, name string, data []byte) (string, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/uploads", c.baseURL, projectID)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	part.Write(data)
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 201 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitLab API error: status %d, body: %s", resp.StatusCode, string(respBody))
+	}
+
+	var upload struct {
+		URL      string `json:"url"`       // Relative to the project's web URL
+		FullPath string `json:"full_path"` // Relative to the instance, GitLab 17.0 and later
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if upload.FullPath != "" {
+		return c.baseURL + upload.FullPath, nil
+	}
+	project, err := c.fetchJSON(fmt.Sprintf("%s/api/v4/projects/%d", c.baseURL, projectID))
+	if err != nil {
+		return "", err
+	}
+	fields, _ := project.(map[string]interface{})
+	webURL, _ := fields["web_url"].(string)
+	return webURL + upload.URL, nil
+}
+
+// AddReleaseLink links a file to the GitLab Release of the tag, creating the release if the tag
+// has none
+func (c *GitLabClient) AddReleaseLink(projectID int, tag, name, linkURL string) error {
+	releasesURL := fmt.Sprintf("%s/api/v4/projects/%d/releases", c.baseURL, projectID)
+	releaseURL := releasesURL + "/" + neturl.PathEscape(tag)
+	if _, err := c.fetchJSON(releaseURL); err != nil {
+		payload := map[string]string{"tag_name": tag, "name": tag}
+		if err := c.sendMergeRequestAction("POST", releasesURL, payload, 201); err != nil {
+			return fmt.Errorf("create release: %w", err)
+		}
+	}
+	payload := map[string]string{"name": name, "url": linkURL, "link_type": "other"}
+	return c.sendMergeRequestAction("POST", releaseURL+"/assets/links", payload, 201)
+}
+
+// GetCurrentIterations returns the iterations of the project's groups running today (GitLab
+// Premium); empty without any
+func (c *GitLabClient) GetCurrentIterations(projectID int) ([]Iteration, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/iterations?state=current&include_ancestors=true&per_page=100", c.baseURL, projectID)
+	var iterations []Iteration
+	if _, err := c.fetchPage(url, &iterations); err != nil {
+		return nil, err
+	}
+	return iterations, nil
+}
+
+// GetIterationIssues returns the IIDs of the project's issues in an iteration
+func (c *GitLabClient) GetIterationIssues(projectID, iterationID int) ([]int, error) {
+	var iids []int
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/api/v4/projects/%d/issues?iteration_id=%d&per_page=%d&page=%d", c.baseURL, projectID, iterationID, listPageSize, page)
+		var issues []struct {
+			IID int `json:"iid"`
+		}
+		info, err := c.fetchPage(url, &issues)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			iids = append(iids, issue.IID)
+		}
+		if !info.HasMore {
+			break
+		}
+	}
+	return iids, nil
+}
+
+// GetIssueMergeRequests returns the IIDs of the project's merge requests related to an issue:
+// those closing it or mentioning it
+func (c *GitLabClient) GetIssueMergeRequests(projectID, issueIID int) ([]int, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%d/issues/%d/related_merge_requests?per_page=100", c.baseURL, projectID, issueIID)
+	var mrs []struct {
+		IID       int `json:"iid"`
+		ProjectID int `json:"project_id"`
+	}
+	if _, err := c.fetchPage(url, &mrs); err != nil {
+		return nil, err
+	}
+	var iids []int
+	for _, mr := range mrs {
+		if mr.ProjectID == projectID {
+			iids = append(iids, mr.IID)
+		}
+	}
+	return iids, nil
 }
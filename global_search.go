@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Global search: ctrl+p opens one search over the listed (or cached) projects, the open MRs of
+// every project with cached MRs and the releases history. Results of all kinds are ranked
+// together by fuzzy match; enter jumps to the result's screen: the MR list of a project, the MR
+// highlighted in the list of its project, or the history detail of a release.
+
+// globalSearchKey opens the global search
+const globalSearchKey = "ctrl+p"
+
+// globalSearchVisible is the number of results the global search shows at once
+const globalSearchVisible = 10
+
+// searchKind is the kind of a global search result
+type searchKind int
+
+const (
+	searchProject searchKind = iota
+	searchMR
+	searchRelease
+)
+
+func (k searchKind) String() string {
+	switch k {
+	case searchMR:
+		return "MR"
+	case searchRelease:
+		return "release"
+	}
+	return "project"
+}
+
+// searchResult is an entry of the global search
+type searchResult struct {
+	kind      searchKind
+	label     string  // Text matched against the query and shown
+	project   Project // Project of a project or MR result
+	mrIID     int
+	historyID string
+}
+
+// searchMatch is a result matching the global search query
+type searchMatch struct {
+	result  searchResult
+	matches []int // Matched rune positions in the label
+}
+
+// globalSearchMsg carries the entries the global search looks through
+type globalSearchMsg struct {
+	results []searchResult
+}
+
+// openGlobalSearch opens the global search and loads its entries
+func (m *model) openGlobalSearch() tea.Cmd {
+	m.closeAllModals()
+	m.showGlobalSearch = true
+	m.globalSearchInput = ""
+	m.globalSearchIndex = 0
+	m.globalSearchResults = nil
+	m.globalSearchMatches = nil
+	m.globalSearchLoading = true
+	return m.loadGlobalSearch()
+}
+
+// loadGlobalSearch collects the projects, the MRs of the selected project and the cached ones of
+// the others, and the releases history. Nothing is fetched from the forge.
+func (m model) loadGlobalSearch() tea.Cmd {
+	var gitlabURL string
+	if m.creds != nil {
+		gitlabURL = m.creds.GitLabURL
+	}
+	var projects []Project
+	if m.projectsLoaded {
+		projects = append(projects, m.projects...)
+	}
+	projects = append(projects, m.pinnedProjects...)
+	var selected *Project
+	var selectedMRs []*MergeRequestDetails
+	if m.selectedProject != nil {
+		p := *m.selectedProject
+		selected = &p
+		projects = append(projects, p)
+		selectedMRs = m.mrsAll
+	}
+	projectsLoaded := m.projectsLoaded
+	query := m.projectQuery
+
+	return func() tea.Msg {
+		if !projectsLoaded && gitlabURL != "" {
+			cached, _ := loadCachedProjects(gitlabURL, query)
+			projects = append(cached, projects...)
+		}
+
+		var results []searchResult
+		seen := make(map[int]bool)
+		for _, p := range projects {
+			if seen[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			results = append(results, searchResult{kind: searchProject, label: p.PathWithNamespace, project: p})
+
+			mrs := selectedMRs
+			if selected == nil || selected.ID != p.ID || mrs == nil {
+				mrs, _ = loadCachedMRs(gitlabURL, p.ID)
+			}
+			for _, mr := range mrs {
+				results = append(results, searchResult{
+					kind:    searchMR,
+					label:   fmt.Sprintf("!%d %s · %s", mr.IID, mr.Title, p.PathWithNamespace),
+					project: p,
+					mrIID:   mr.IID,
+				})
+			}
+		}
+
+		entries, _ := LoadHistoryIndex()
+		for _, e := range entries {
+			name := e.Tag
+			if name == "" {
+				name = e.Version
+			}
+			results = append(results, searchResult{
+				kind:      searchRelease,
+				label:     fmt.Sprintf("%s %s · %s %s", name, e.Environment, e.Status, e.DateTime.Format("2006-01-02")),
+				historyID: e.ID,
+			})
+		}
+		return globalSearchMsg{results: results}
+	}
+}
+
+// handleGlobalSearch shows the loaded entries
+func (m *model) handleGlobalSearch(msg globalSearchMsg) {
+	m.globalSearchLoading = false
+	m.globalSearchResults = msg.results
+	m.filterGlobalSearch()
+}
+
+// filterGlobalSearch ranks the entries matching the query and resets the cursor; nothing is
+// listed while the query is empty
+func (m *model) filterGlobalSearch() {
+	m.globalSearchIndex = 0
+	m.globalSearchMatches = nil
+	query := strings.TrimSpace(m.globalSearchInput)
+	if query == "" {
+		return
+	}
+	labels := make([]string, len(m.globalSearchResults))
+	for i, r := range m.globalSearchResults {
+		labels[i] = r.label
+	}
+	for _, rank := range fuzzyFilter(query, labels) {
+		m.globalSearchMatches = append(m.globalSearchMatches, searchMatch{m.globalSearchResults[rank.Index], rank.MatchedIndexes})
+	}
+}
+
+// updateGlobalSearch handles key events when the global search is open
+func (m model) updateGlobalSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+q", "esc":
+		m.showGlobalSearch = false
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.globalSearchIndex > 0 {
+			m.globalSearchIndex--
+		}
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.globalSearchIndex < len(m.globalSearchMatches)-1 {
+			m.globalSearchIndex++
+		}
+		return m, nil
+
+	case "enter":
+		if m.globalSearchIndex < len(m.globalSearchMatches) {
+			return m.openSearchResult(m.globalSearchMatches[m.globalSearchIndex].result)
+		}
+		return m, nil
+
+	case "backspace":
+		if len(m.globalSearchInput) > 0 {
+			runes := []rune(m.globalSearchInput)
+			m.globalSearchInput = string(runes[:len(runes)-1])
+			m.filterGlobalSearch()
+		}
+		return m, nil
+
+	default:
+		switch msg.Type {
+		case tea.KeySpace:
+			m.globalSearchInput += " "
+		case tea.KeyRunes:
+			m.globalSearchInput += string(msg.Runes)
+		default:
+			return m, nil
+		}
+		m.filterGlobalSearch()
+		return m, nil
+	}
+}
+
+// openSearchResult jumps to the screen of a global search result. A release in progress keeps
+// its tab; results open in another one.
+func (m model) openSearchResult(r searchResult) (tea.Model, tea.Cmd) {
+	m.closeAllModals()
+	if m.releaseState != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "The release in this tab is not finished: open another tab (Alt+N) to go elsewhere"
+		return m, nil
+	}
+
+	switch r.kind {
+	case searchRelease:
+		cmd := m.openHistoryList()
+		return m, tea.Batch(cmd, m.loadHistoryDetail(r.historyID))
+
+	case searchMR:
+		if m.selectedProject != nil && m.selectedProject.ID == r.project.ID && m.mrsLoaded {
+			m.moveToScreen(screenMain)
+			return m, m.focusMR(r.mrIID)
+		}
+		// Highlighted once the project's MRs are listed (see setMRItems)
+		m.searchMRIID = r.mrIID
+		cmd := m.selectProject(r.project)
+		m.moveToScreen(screenMain)
+		return m, cmd
+	}
+
+	if m.selectedProject != nil && m.selectedProject.ID == r.project.ID && m.mrsLoaded {
+		m.moveToScreen(screenMain)
+		return m, nil
+	}
+	cmd := m.selectProject(r.project)
+	m.moveToScreen(screenMain)
+	return m, cmd
+}
+
+// focusMR highlights an MR in the list of the selected project, clearing a list filter hiding it
+func (m *model) focusMR(iid int) tea.Cmd {
+	index := func() int {
+		for i, item := range m.list.VisibleItems() {
+			if mr, ok := item.(mrListItem); ok && mr.mr.IID == iid {
+				return i
+			}
+		}
+		return -1
+	}
+	i := index()
+	if i < 0 && m.list.FilterState() != list.Unfiltered {
+		m.list.ResetFilter()
+		i = index()
+	}
+	if i < 0 {
+		return m.showToast(fmt.Sprintf("!%d is no longer listed", iid))
+	}
+	m.list.Select(i)
+	if m.ready {
+		m.viewport.SetContent(m.renderMarkdown())
+	}
+	return m.loadHighlightedMRDetails()
+}
+
+// overlayGlobalSearch renders the global search as an overlay on top of the current view
+func (m model) overlayGlobalSearch(background string) string {
+	var b strings.Builder
+
+	b.WriteString(commandMenuTitleStyle.Render("Search"))
+	b.WriteString("\n")
+	b.WriteString(commandItemStyle.Render("› ") + m.globalSearchInput + "█")
+	b.WriteString("\n\n")
+
+	switch {
+	case m.globalSearchLoading:
+		b.WriteString(commandDescStyle.Render("  Loading…"))
+		b.WriteString("\n")
+	case strings.TrimSpace(m.globalSearchInput) == "":
+		counts := make(map[searchKind]int)
+		for _, r := range m.globalSearchResults {
+			counts[r.kind]++
+		}
+		b.WriteString(commandDescStyle.Render(fmt.Sprintf("  %d projects, %d open MRs, %d releases",
+			counts[searchProject], counts[searchMR], counts[searchRelease])))
+		b.WriteString("\n")
+	case len(m.globalSearchMatches) == 0:
+		b.WriteString(commandDescStyle.Render("  No matches"))
+		b.WriteString("\n")
+	}
+
+	start := max(0, m.globalSearchIndex-globalSearchVisible+1)
+	for i := start; i < len(m.globalSearchMatches) && i < start+globalSearchVisible; i++ {
+		match := m.globalSearchMatches[i]
+		var style lipgloss.Style
+		prefix := "  "
+		if i == m.globalSearchIndex {
+			style = commandItemSelectedStyle
+			prefix = "> "
+		} else {
+			style = commandItemStyle
+		}
+		b.WriteString(style.Render(prefix))
+		b.WriteString(commandDescStyle.Render(fmt.Sprintf("%-8s", match.result.kind)))
+		b.WriteString(highlightMatches(match.result.label, match.matches, style))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓: nav • enter: open • C+q: close"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 70, Percent: true},
+		MinWidth: 40,
+		MaxWidth: 100,
+		Style:    commandMenuStyle,
+	}
+	return placeOverlayCenter(renderModal(b.String(), config, m.width), background, m.width, m.height)
+}
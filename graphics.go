@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Avatar formats
+	_ "image/jpeg" // Avatar formats
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Terminal graphics protocols (terminal_graphics config)
+const (
+	graphicsAuto  = "auto" // Default: detected from the environment
+	graphicsKitty = "kitty"
+	graphicsSixel = "sixel"
+	graphicsOff   = "off"
+)
+
+// termGraphics is the graphics protocol images are drawn with; graphicsOff draws text glyphs.
+// It is detected at startup and set from the config once it is read (see applyStartupConfig).
+var termGraphics = detectGraphics(os.Getenv)
+
+// inlineImageCells is the width of avatars and badges in cells; they are one row high
+const inlineImageCells = 2
+
+// inlineImagePixels is the size images are scaled to. Sixel images are drawn at their pixel
+// size, so they are kept within the height of a text row.
+const inlineImagePixels = 16
+
+// resolveGraphics returns the protocol for a terminal_graphics setting
+func resolveGraphics(setting string) string {
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case graphicsKitty:
+		return graphicsKitty
+	case graphicsSixel:
+		return graphicsSixel
+	case graphicsOff:
+		return graphicsOff
+	}
+	return detectGraphics(os.Getenv)
+}
+
+// detectGraphics tells the graphics protocol of the terminal from its environment. Terminal
+// multiplexers do not pass images through, so they get text glyphs.
+func detectGraphics(getenv func(string) string) string {
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return graphicsOff
+	}
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty", program == "ghostty":
+		return graphicsKitty
+	case program == "WezTerm", program == "iTerm.app", strings.Contains(term, "foot"),
+		strings.Contains(term, "mlterm"), strings.Contains(term, "contour"), strings.Contains(term, "sixel"):
+		return graphicsSixel
+	}
+	return graphicsOff
+}
+
+// inlineImage is an image drawn inline with text, inlineImageCells wide and one row high
+type inlineImage struct {
+	id   uint32 // Kitty image ID
+	data string // Kitty: base64 PNG; sixel: the encoded image
+}
+
+// lastImageID numbers kitty images; IDs fit in the 24-bit color of the placeholder cells
+var lastImageID atomic.Uint32
+
+// newInlineImage encodes an image for the current graphics protocol; nil without graphics
+func newInlineImage(img image.Image) *inlineImage {
+	scaled := scaleImage(img, inlineImagePixels, inlineImagePixels)
+	switch termGraphics {
+	case graphicsKitty:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, scaled); err != nil {
+			return nil
+		}
+		id := lastImageID.Add(1)&0xFFFFFF | 0x100000 // Keep clear of IDs other programs start from
+		return &inlineImage{id: id, data: base64.StdEncoding.EncodeToString(buf.Bytes())}
+	case graphicsSixel:
+		return &inlineImage{data: encodeSixel(scaled)}
+	}
+	return nil
+}
+
+// render returns the image followed by text cells of its width, so layout code measuring the
+// line sees inlineImageCells cells
+func (i *inlineImage) render() string {
+	if termGraphics == graphicsKitty {
+		return i.kittyTransmit() + i.kittyPlaceholders()
+	}
+	// Save and restore the cursor around the sixel, whose cursor movement varies by terminal
+	return "\x1b7" + i.data + "\x1b8" + strings.Repeat(" ", inlineImageCells)
+}
+
+// kittyTransmit returns the image with a virtual placement for unicode placeholders. Lines are
+// redrawn as a whole, so the image is sent with every redraw of its line; images are small.
+func (i *inlineImage) kittyTransmit() string {
+	var b strings.Builder
+	data := i.data
+	first := true
+	for first || data != "" {
+		chunk := data
+		if len(chunk) > 4096 {
+			chunk = chunk[:4096]
+		}
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=1,m=%d;%s\x1b\\", i.id, inlineImageCells, more, chunk)
+			first = false
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// kittyPlaceholderDiacritics number the rows and columns of placeholder cells
+var kittyPlaceholderDiacritics = []rune{0x0305, 0x030D, 0x030E, 0x0310}
+
+// kittyPlaceholders returns the placeholder cells the terminal draws the image in; their
+// foreground color carries the image ID
+func (i *inlineImage) kittyPlaceholders() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm", i.id>>16&0xFF, i.id>>8&0xFF, i.id&0xFF)
+	for col := 0; col < inlineImageCells; col++ {
+		b.WriteRune(0x10EEEE)
+		b.WriteRune(kittyPlaceholderDiacritics[0])
+		b.WriteRune(kittyPlaceholderDiacritics[col])
+	}
+	b.WriteString("\x1b[39m")
+	return b.String()
+}
+
+// scaleImage resizes an image with nearest-neighbor sampling
+func scaleImage(src image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return dst
+	}
+	for y := 0; y < h; y++ {
+		sy := bounds.Min.Y + y*bounds.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/w
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+// encodeSixel encodes an image as sixel graphics with a palette of up to 216 colors.
+// Mostly transparent pixels are left undrawn.
+func encodeSixel(img *image.NRGBA) string {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	palette := map[int]int{} // 6x6x6 color cube index -> register
+	var order []int
+	pixels := make([]int, w*h) // Register of each pixel, -1 if transparent
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				pixels[y*w+x] = -1
+				continue
+			}
+			cube := int(c.R)*5/255*36 + int(c.G)*5/255*6 + int(c.B)*5/255
+			reg, ok := palette[cube]
+			if !ok {
+				reg = len(order)
+				palette[cube] = reg
+				order = append(order, cube)
+			}
+			pixels[y*w+x] = reg
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for reg, cube := range order {
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", reg, cube/36*20, cube/6%6*20, cube%6*20)
+	}
+	for band := 0; band < h; band += 6 {
+		for reg := range order {
+			row := make([]byte, w)
+			used := false
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if pixels[(band+dy)*w+x] == reg {
+						bits |= 1 << dy
+						used = true
+					}
+				}
+				row[x] = 63 + bits
+			}
+			if !used {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", reg)
+			writeSixelRun(&b, row)
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRun writes a row of sixels with run-length encoding
+func writeSixelRun(b *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// badgeImages caches the pipeline badge of each color, drawn once per protocol
+var badgeImages sync.Map // "protocol color" -> *inlineImage
+
+// pipelineBadge returns a badge for a pipeline stage: a colored dot image where the terminal
+// draws images, a colored glyph elsewhere
+func pipelineBadge(stage PipelineObserverStage) string {
+	var glyph string
+	var c lipgloss.Color
+	switch stage {
+	case PipelineStageCompleted:
+		glyph, c = "✓", currentTheme.Success
+	case PipelineStageFailed:
+		glyph, c = "✗", currentTheme.Error
+	case PipelineStageRunning:
+		glyph, c = "●", currentTheme.Warning
+	default:
+		glyph, c = "◌", currentTheme.Notion
+	}
+	if termGraphics == graphicsOff || !isValidHexColor(string(c)) {
+		return lipgloss.NewStyle().Foreground(c).Render(glyph)
+	}
+
+	key := termGraphics + " " + string(c)
+	if img, ok := badgeImages.Load(key); ok {
+		return img.(*inlineImage).render()
+	}
+	img := newInlineImage(badgeDot(string(c)))
+	if img == nil {
+		return lipgloss.NewStyle().Foreground(c).Render(glyph)
+	}
+	badgeImages.Store(key, img)
+	return img.render()
+}
+
+// badgeDot draws a filled circle of a hex color on a transparent background
+func badgeDot(hex string) image.Image {
+	r, g, b := parseHexColor(hex)
+	size := inlineImagePixels
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	center := float64(size-1) / 2
+	radius := float64(size) / 2.5
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-center, float64(y)-center
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// renderProjectAvatar returns the avatar of a project where the terminal draws images and one
+// was loaded, or the initial of its name, padded to the same width
+func (m model) renderProjectAvatar(p Project, style lipgloss.Style) string {
+	if img := m.projectAvatars[p.ID]; img != nil && termGraphics != graphicsOff {
+		return img.render()
+	}
+	initial := "·"
+	if name := strings.TrimSpace(p.Name); name != "" {
+		initial = strings.ToUpper(string([]rune(name)[:1]))
+	}
+	return style.Render(initial + " ")
+}
+
+// projectAvatarsMsg carries the avatars loaded for projects, by project ID
+type projectAvatarsMsg struct {
+	avatars map[int]*inlineImage
+}
+
+// maxAvatarBytes bounds the size of an avatar download
+const maxAvatarBytes = 1 << 20
+
+// maxAvatarPixels bounds the dimensions of an avatar, checked before it is decoded: a small
+// file may declare a huge image
+const maxAvatarPixels = 4096 * 4096
+
+// loadProjectAvatars downloads the avatars of projects not requested yet, a few at a time.
+// Nothing is downloaded when the terminal does not draw images.
+func (m *model) loadProjectAvatars(projects []Project) tea.Cmd {
+	if termGraphics == graphicsOff || m.creds == nil {
+		return nil
+	}
+	if m.avatarsRequested == nil {
+		m.avatarsRequested = make(map[int]bool)
+	}
+	var pending []Project
+	for _, p := range projects {
+		if p.AvatarURL != "" && !m.avatarsRequested[p.ID] {
+			m.avatarsRequested[p.ID] = true
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	creds := *m.creds
+	return func() tea.Msg {
+		client := &http.Client{Transport: gitlabTransport}
+		avatars := make(map[int]*inlineImage)
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, 4)
+		for _, p := range pending {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(p Project) {
+				defer func() { <-slots; wg.Done() }()
+				img, err := fetchAvatar(client, creds, p.AvatarURL)
+				if err != nil {
+					return
+				}
+				if inline := newInlineImage(img); inline != nil {
+					mu.Lock()
+					avatars[p.ID] = inline
+					mu.Unlock()
+				}
+			}(p)
+		}
+		wg.Wait()
+		return projectAvatarsMsg{avatars: avatars}
+	}
+}
+
+// fetchAvatar downloads and decodes an avatar. Avatars of private projects are served to
+// authenticated users only, so the token is sent to the forge's own host.
+func fetchAvatar(client *http.Client, creds Credentials, avatarURL string) (image.Image, error) {
+	req, err := http.NewRequest("GET", avatarURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The token is a GitLab one, and only for the instance itself
+	if forge, err := url.Parse(creds.GitLabURL); err == nil && creds.Forge == forgeGitLab &&
+		strings.EqualFold(req.URL.Scheme, forge.Scheme) && strings.EqualFold(req.URL.Host, forge.Host) {
+		req.Header.Set("PRIVATE-TOKEN", creds.Token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("avatar download failed: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes))
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return nil, fmt.Errorf("avatar is %dx%d, too large", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// historyCommand groups the release history subcommands
+var historyCommand = &cliCommand{
+	Name:        "history",
+	Summary:     "Inspect and export the local release history",
+	Description: "Reads the release history recorded by the TUI and headless releases (~/.local/.relix/releases) without starting the interface, so reports can be produced from scripts and scheduled jobs.",
+	Sub: []*cliCommand{
+		historyListCommand,
+		historyShowCommand,
+		historyExportCommand,
+		historyDigestCommand,
+		historyCompressCommand,
+		historyEvidenceCommand,
+	},
+}
+
+var historyListCommand = &cliCommand{
+	Name:        "list",
+	Summary:     "List recorded releases, newest first",
+	Description: "Prints one line per release with its ID, tag, environment, date, MR count and status. Filters can be combined.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix history list",
+		"relix history list --env prod --status completed --limit 10",
+		"relix history list --since 2026-01-01 --format json",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		filter := registerHistoryFilterFlags(fs)
+		limit := fs.Int("limit", 0, "Show at most `n` releases (0 = all)")
+		format := fs.String("format", "table", "Output `format`: table or json")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			entries, err := loadFilteredHistory(filter)
+			if err != nil {
+				return err
+			}
+			if *limit > 0 && len(entries) > *limit {
+				entries = entries[:*limit]
+			}
+			switch *format {
+			case "table":
+				return writeHistoryTable(os.Stdout, entries)
+			case "json":
+				return writeJSON(os.Stdout, entries)
+			default:
+				return fmt.Errorf("unknown format %q (expected table or json)", *format)
+			}
+		}
+	},
+}
+
+var historyShowCommand = &cliCommand{
+	Name:        "show",
+	Summary:     "Show full details of a single release",
+	Description: "Prints the metadata and merge requests of one release. The release can be given by its ID or by its tag (e.g. 5.2-v13 or prod-5.2-v13); for a tag the newest matching release is shown.",
+	Usage:       "[options] <id|tag>",
+	Examples: []string{
+		"relix history show 20260215-143012",
+		"relix history show 5.2-v13 --logs",
+		"relix history show 5.2-v13 --format json",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		format := fs.String("format", "text", "Output `format`: text, markdown or json")
+		logs := fs.Bool("logs", false, "Include the release terminal output (text and markdown formats)")
+		return func(args []string) error {
+			if len(args) != 1 {
+				return errCLIUsage
+			}
+			entry, err := findHistoryEntry(args[0])
+			if err != nil {
+				return err
+			}
+			switch *format {
+			case "text":
+				writeHistoryDetailText(os.Stdout, entry, *logs)
+				return nil
+			case "markdown":
+				writeHistoryChangelog(os.Stdout, []*ReleaseHistoryEntry{entry}, *logs)
+				return nil
+			case "json":
+				return writeJSON(os.Stdout, entry)
+			default:
+				return fmt.Errorf("unknown format %q (expected text, markdown or json)", *format)
+			}
+		}
+	},
+}
+
+var historyExportCommand = &cliCommand{
+	Name:        "export",
+	Summary:     "Export releases as a changelog or machine-readable report",
+	Description: "Writes every matching release with its merge requests as a markdown changelog, a JSON array or a CSV table.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix history export > CHANGELOG.md",
+		"relix history export --env prod --since 2026-01-01 --output prod-releases.md",
+		"relix history export --format csv --status completed",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		filter := registerHistoryFilterFlags(fs)
+		format := fs.String("format", "markdown", "Output `format`: markdown, json or csv")
+		output := fs.String("output", "", "Write to `file` instead of stdout")
+		logs := fs.Bool("logs", false, "Include terminal output of each release (markdown format)")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			index, err := loadFilteredHistory(filter)
+			if err != nil {
+				return err
+			}
+
+			// Load details for every matching entry; entries with missing detail files are skipped
+			entries := make([]*ReleaseHistoryEntry, 0, len(index))
+			for _, ie := range index {
+				detail, err := LoadHistoryDetail(ie.ID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", ie.ID, err)
+					continue
+				}
+				entries = append(entries, detail)
+			}
+
+			w := io.Writer(os.Stdout)
+			if *output != "" {
+				f, err := os.Create(*output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			switch *format {
+			case "markdown":
+				writeHistoryChangelog(w, entries, *logs)
+				return nil
+			case "json":
+				return writeJSON(w, entries)
+			case "csv":
+				return writeHistoryCSV(w, entries)
+			default:
+				return fmt.Errorf("unknown format %q (expected markdown, json or csv)", *format)
+			}
+		}
+	},
+}
+
+var historyCompressCommand = &cliCommand{
+	Name:        "compress",
+	Summary:     "Compress the terminal output of older releases",
+	Description: "Releases are saved with their terminal output compressed. This converts the plain log files of releases saved by older versions, which can take most of the history directory.",
+	Examples: []string{
+		"relix history compress",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			index, err := LoadHistoryIndex()
+			if err != nil {
+				return err
+			}
+			var count int
+			var before, after int64
+			for _, ie := range index {
+				plain, compressed, err := compressHistoryLog(ie.ID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", ie.ID, err)
+					continue
+				}
+				if plain > 0 {
+					count++
+					before += plain
+					after += compressed
+				}
+			}
+			if count == 0 {
+				fmt.Println("No uncompressed logs")
+				return nil
+			}
+			fmt.Printf("Compressed %d logs: %s -> %s\n", count, formatByteSize(before), formatByteSize(after))
+			return nil
+		}
+	},
+}
+
+// formatByteSize formats a file size as B, KB or MB
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// historyFilter holds the list/export filtering flags
+type historyFilter struct {
+	env     *string
+	status  *string
+	failure *string
+	since   *string
+	until   *string
+}
+
+// registerHistoryFilterFlags registers the common history filtering flags
+func registerHistoryFilterFlags(fs *flag.FlagSet) *historyFilter {
+	return &historyFilter{
+		env:     fs.String("env", "", "Only releases to environment `name` (case-insensitive)"),
+		status:  fs.String("status", "", "Only releases with `status` completed or aborted"),
+		failure: fs.String("failure", "", "Only aborted releases whose post-mortem has `category`, e.g. \"merge conflict\""),
+		since:   fs.String("since", "", "Only releases on or after `date` (YYYY-MM-DD)"),
+		until:   fs.String("until", "", "Only releases on or before `date` (YYYY-MM-DD)"),
+	}
+}
+
+// loadFilteredHistory loads the history index and applies the filter flags
+func loadFilteredHistory(f *historyFilter) ([]HistoryIndexEntry, error) {
+	var since, until time.Time
+	var err error
+	if *f.since != "" {
+		if since, err = time.ParseInLocation("2006-01-02", *f.since, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid since date %q (expected YYYY-MM-DD)", *f.since)
+		}
+	}
+	if *f.until != "" {
+		if until, err = time.ParseInLocation("2006-01-02", *f.until, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid until date %q (expected YYYY-MM-DD)", *f.until)
+		}
+		// Inclusive: cover the whole day
+		until = until.AddDate(0, 0, 1)
+	}
+
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		return nil, fmt.Errorf("load history index: %w", err)
+	}
+
+	filtered := make([]HistoryIndexEntry, 0, len(index))
+	for _, e := range index {
+		if *f.env != "" && !strings.EqualFold(e.Environment, *f.env) {
+			continue
+		}
+		if *f.status != "" && e.Status != *f.status {
+			continue
+		}
+		if *f.failure != "" && !strings.EqualFold(e.FailureCategory, *f.failure) {
+			continue
+		}
+		if !since.IsZero() && e.DateTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !e.DateTime.Before(until) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered, nil
+}
+
+// findHistoryEntry loads a history entry by ID, falling back to the newest entry with a matching tag
+func findHistoryEntry(ref string) (*ReleaseHistoryEntry, error) {
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		return nil, fmt.Errorf("load history index: %w", err)
+	}
+	for _, e := range index {
+		if e.ID == ref {
+			return LoadHistoryDetail(e.ID)
+		}
+	}
+	// Index is stored newest first, so the first tag match is the latest release
+	for _, e := range index {
+		if e.Tag == ref || strings.EqualFold(historyFullTag(e), ref) {
+			return LoadHistoryDetail(e.ID)
+		}
+	}
+	return nil, fmt.Errorf("no release found with id or tag %q", ref)
+}
+
+// historyFullTag reconstructs the git tag ({env}-{tag}) of a history entry
+func historyFullTag(e HistoryIndexEntry) string {
+	if e.Environment == "" || e.Tag == "" {
+		return e.Tag
+	}
+	return strings.ToLower(e.Environment) + "-" + e.Tag
+}
+
+// writeHistoryTable prints history entries as an aligned table
+func writeHistoryTable(w io.Writer, entries []HistoryIndexEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTAG\tENV\tDATE\tMRS\tSTATUS")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.ID, e.Tag, e.Environment, formatDateTime(e.DateTime), e.MRCount, e.Status)
+	}
+	return tw.Flush()
+}
+
+// writeHistoryDetailText prints a human-readable release report
+func writeHistoryDetailText(w io.Writer, e *ReleaseHistoryEntry, withLogs bool) {
+	rows := [][2]string{
+		{"ID", e.ID},
+		{"Date", formatDateTime(e.DateTime)},
+		{"Environment", e.Environment},
+		{"Version", e.Version},
+		{"Tag", historyFullTag(e.HistoryIndexEntry)},
+		{"Status", e.Status},
+		{"Root merge", strconv.FormatBool(e.RootMerge)},
+		{"Release branch", e.SourceBranch},
+		{"Env branch", e.EnvBranch},
+		{"MRs count", strconv.Itoa(e.MRCount)},
+	}
+	if e.EnvMergeMode != "" {
+		rows = append(rows, [2]string{"Env merge mode", e.EnvMergeMode})
+	}
+	if e.Rollout != nil {
+		rows = append(rows, [2]string{"Rollout", e.Rollout.String()})
+	}
+	if len(e.PipelineInputs) > 0 {
+		rows = append(rows, [2]string{"Pipeline inputs", formatPipelineInputs(e.PipelineInputs)})
+	}
+	if e.RollbackOf != "" {
+		rows = append(rows, [2]string{"Rollback of", e.RollbackOf})
+	}
+	if e.FailureCategory != "" {
+		rows = append(rows, [2]string{"Post-mortem", strings.TrimSuffix(e.FailureCategory+": "+e.PostMortem, ": ")})
+	}
+	if e.Candidate > 0 {
+		rows = append(rows, [2]string{"Candidate", strconv.Itoa(e.Candidate)})
+	}
+	if e.PromotedFrom != "" {
+		rows = append(rows, [2]string{"Promoted from", e.PromotedFrom})
+	}
+	if e.CreatedMRURL != "" {
+		rows = append(rows, [2]string{"MR URL", e.CreatedMRURL})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(w, "%-16s %s\n", row[0]+":", row[1])
+	}
+
+	if len(e.MRBranches) > 0 {
+		fmt.Fprintln(w, "\nMerge requests:")
+		for i, branch := range e.MRBranches {
+			line := "  - " + branch
+			if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+				line += "  " + e.MRURLs[i]
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	if withLogs && historyHasLog(e) {
+		fmt.Fprintln(w, "\nTerminal output:")
+		forEachHistoryLogLine(e, func(line string) {
+			fmt.Fprintln(w, ansi.Strip(line))
+		})
+	}
+}
+
+// writeHistoryChangelog renders releases as a markdown changelog
+func writeHistoryChangelog(w io.Writer, entries []*ReleaseHistoryEntry, withLogs bool) {
+	fmt.Fprintln(w, "# Releases")
+	for _, e := range entries {
+		fmt.Fprintf(w, "\n## %s (%s)\n\n", historyFullTag(e.HistoryIndexEntry), e.Environment)
+		fmt.Fprintf(w, "- **Date:** %s\n", formatDateTime(e.DateTime))
+		fmt.Fprintf(w, "- **Version:** %s\n", e.Version)
+		fmt.Fprintf(w, "- **Status:** %s\n", e.Status)
+		if e.SourceBranch != "" {
+			fmt.Fprintf(w, "- **Release branch:** `%s`\n", e.SourceBranch)
+		}
+		if e.CreatedMRURL != "" {
+			fmt.Fprintf(w, "- **Release MR:** %s\n", e.CreatedMRURL)
+		}
+		if e.Rollout != nil {
+			fmt.Fprintf(w, "- **Rollout:** %s\n", e.Rollout)
+		}
+		if e.FailureCategory != "" {
+			fmt.Fprintf(w, "- **Post-mortem:** %s\n", strings.TrimSuffix(e.FailureCategory+": "+e.PostMortem, ": "))
+		}
+
+		if len(e.MRBranches) > 0 {
+			fmt.Fprintf(w, "\n### Merge requests (%d)\n\n", len(e.MRBranches))
+			for i, branch := range e.MRBranches {
+				if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+					fmt.Fprintf(w, "- [%s](%s)\n", branch, e.MRURLs[i])
+				} else {
+					fmt.Fprintf(w, "- %s\n", branch)
+				}
+			}
+		}
+
+		if withLogs && historyHasLog(e) {
+			fmt.Fprintln(w, "\n<details><summary>Terminal output</summary>\n\n```")
+			forEachHistoryLogLine(e, func(line string) {
+				fmt.Fprintln(w, ansi.Strip(line))
+			})
+			fmt.Fprintln(w, "```\n\n</details>")
+		}
+	}
+}
+
+// writeHistoryCSV writes one row per release
+func writeHistoryCSV(w io.Writer, entries []*ReleaseHistoryEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "tag", "environment", "datetime", "version", "status", "mr_count", "source_branch", "release_mr_url", "mr_branches"})
+	for _, e := range entries {
+		cw.Write([]string{
+			e.ID,
+			historyFullTag(e.HistoryIndexEntry),
+			e.Environment,
+			e.DateTime.Format(time.RFC3339),
+			e.Version,
+			e.Status,
+			strconv.Itoa(e.MRCount),
+			e.SourceBranch,
+			e.CreatedMRURL,
+			strings.Join(e.MRBranches, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -2,12 +2,12 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -16,8 +16,9 @@ var historyDetailTabs = []string{"MRs", "Meta", "Logs"}
 // remapTerminalColors replaces ANSI escape sequences from the theme that was
 // active at save time with sequences from the current theme. Plain-text lines
 // (no color codes) are wrapped with the current foreground color. Git-native
-// SGR codes (e.g. \033[31m) are untouched because lipgloss uses 256-color
-// format (\033[38;5;Nm) which never collides.
+// SGR codes (e.g. \033[31m) of logs saved before the release terminal rewrote
+// them are rewritten to the current theme (see output_colors.go); lipgloss uses
+// 256-color or 24-bit format (\033[38;5;Nm) which never collides with them.
 func remapTerminalColors(lines []string, savedMap *ThemeANSIMap) []string {
 	if savedMap == nil {
 		savedMap = defaultThemeANSIMap()
@@ -54,6 +55,7 @@ func remapTerminalColors(lines []string, savedMap *ThemeANSIMap) []string {
 		if r != nil {
 			line = r.Replace(line)
 		}
+		line = remapOutputColors(line)
 		// Prepend foreground color to all non-empty lines so plain text
 		// renders in the theme color. Inline ANSI codes (git diff colors,
 		// command headers) override it for their segments.
@@ -81,8 +83,11 @@ func (m *model) initHistoryDetailScreen() {
 			logsHeight = 1
 		}
 		m.historyLogsViewport = viewport.New(logsWidth, logsHeight)
-		remapped := remapTerminalColors(m.historySelected.TerminalOutput, m.historySelected.ThemeANSIMap)
-		m.historyLogsViewport.SetContent(strings.Join(remapped, "\n"))
+		m.setHistoryLogsContent()
+		// A partly loaded log shows its end; scrolling up loads earlier chunks
+		if m.historyLogStart > 0 {
+			m.historyLogsViewport.GotoBottom()
+		}
 	}
 
 	// Initialize MRs viewport for MRs tab
@@ -99,6 +104,75 @@ func (m *model) initHistoryDetailScreen() {
 	}
 }
 
+// loadHistoryLog starts loading the terminal output of the selected release from its end.
+// Releases saved before log files have it inline and need no loading.
+func (m *model) loadHistoryLog() tea.Cmd {
+	m.historyLogLines = nil
+	m.historyLogStart = 0
+	m.historyLogLoading = false
+	if m.historySelected == nil {
+		return nil
+	}
+	if len(m.historySelected.TerminalOutput) > 0 {
+		m.historyLogLines = m.historySelected.TerminalOutput
+		return nil
+	}
+	m.historyLogLoading = true
+	return loadHistoryLogChunk(m.historySelected, -1)
+}
+
+// loadHistoryLogChunk reads the chunk of a history log that ends at offset end (-1 for the end),
+// decompressing only that part of a compressed log
+func loadHistoryLogChunk(e *ReleaseHistoryEntry, end int64) tea.Cmd {
+	return func() tea.Msg {
+		lines, start, err := readHistoryLogChunk(e, end)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return historyLogChunkMsg{id: e.ID, lines: lines, start: start, err: err}
+	}
+}
+
+// applyHistoryLogChunk prepends a loaded chunk, keeping the Logs tab scrolled to the same lines
+func (m *model) applyHistoryLogChunk(msg historyLogChunkMsg) {
+	if m.historySelected == nil || m.historySelected.ID != msg.id {
+		return
+	}
+	m.historyLogLoading = false
+	if msg.err != nil {
+		// Stop here; the lines loaded so far stay visible
+		m.historyLogStart = 0
+		return
+	}
+	m.historyLogStart = msg.start
+	m.historyLogLines = append(msg.lines, m.historyLogLines...)
+
+	if m.historyDetailTab != 2 || m.historyLogsViewport.Height == 0 {
+		return
+	}
+	if len(m.historyLogLines) == len(msg.lines) {
+		// First chunk: the tab was opened while loading
+		m.initHistoryDetailScreen()
+		return
+	}
+	before := m.historyLogsViewport.TotalLineCount()
+	offset := m.historyLogsViewport.YOffset
+	m.setHistoryLogsContent()
+	m.historyLogsViewport.SetYOffset(offset + m.historyLogsViewport.TotalLineCount() - before)
+}
+
+// setHistoryLogsContent renders the loaded log lines into the Logs viewport
+func (m *model) setHistoryLogsContent() {
+	remapped := remapTerminalColors(m.historyLogLines, m.historySelected.ThemeANSIMap)
+	if m.historyLogsWrap {
+		remapped = wrapLines(remapped, m.historyLogsViewport.Width)
+		m.historyLogsViewport.SetHorizontalStep(0)
+	} else {
+		m.historyLogsViewport.SetHorizontalStep(outputScrollStep)
+	}
+	m.historyLogsViewport.SetContent(strings.Join(remapped, "\n"))
+}
+
 // initHistoryMRFetch triggers fetching all MRs when entering detail screen
 func (m *model) initHistoryMRFetch() tea.Cmd {
 	if m.historySelected == nil || len(m.historySelected.MRBranches) == 0 {
@@ -118,11 +192,17 @@ func (m model) updateHistoryDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.showOpenOptionsModal {
 		return m.updateOpenOptionsModal(msg)
 	}
+	if m.showRollbackConfirm {
+		return m.updateRollbackConfirm(msg)
+	}
+	if m.showPromoteConfirm {
+		return m.updatePromoteConfirm(msg)
+	}
 
 	switch msg.String() {
 	case "ctrl+q", "esc":
 		// Go back to history list
-		m.screen = screenHistoryList
+		m.goBack()
 		m.historySelected = nil
 		m.historyMRDetailsMap = make(map[int]*MergeRequestDetails)
 		return m, nil
@@ -140,6 +220,31 @@ func (m model) updateHistoryDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m.handleOpenAction(buildHistoryOpenOptions(m.historySelected, m.historyMRIndex, m.historyDetailTab))
 		}
 		return m, nil
+	case "e":
+		// Annotate the release, e.g. with what went wrong or was checked afterwards
+		if m.historySelected != nil {
+			cmd := m.editText(editHistoryAnnotation, m.historySelected.Annotation)
+			return m, cmd
+		}
+		return m, nil
+	case "R":
+		// Roll the environment back to the release before this one
+		(&m).askRollback()
+		return m, nil
+	case "P":
+		// Promote the release candidate to the final tag
+		(&m).askPromote()
+		return m, nil
+	case "F":
+		// Write the post-mortem of an aborted release
+		if entry := m.historySelected; entry != nil && entry.Status == "aborted" {
+			(&m).askPostMortem(entry.ID, entry.FailureCategory, entry.PostMortem)
+		}
+		return m, nil
+	case "t":
+		// Label or set the milestone of the released MRs
+		cmd := m.bulkEditReleaseMRs()
+		return m, cmd
 	case "r":
 		// Reload MRs (if on MRs tab)
 		if m.historyDetailTab == 0 && m.historySelected != nil {
@@ -153,8 +258,32 @@ func (m model) updateHistoryDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case 0: // MRs tab - navigate MR list
 		return m.updateHistoryMRsTab(msg)
 	case 2: // Logs tab - scroll viewport
+		switch msg.String() {
+		case "w":
+			// Toggle between wrapped and truncated log lines, keeping the end in view if it was
+			atBottom := m.historyLogsViewport.AtBottom()
+			m.historyLogsWrap = !m.historyLogsWrap
+			m.historyLogsViewport.SetXOffset(0)
+			m.setHistoryLogsContent()
+			if atBottom {
+				m.historyLogsViewport.GotoBottom()
+			}
+			return m, nil
+		case "<":
+			m.historyLogsViewport.ScrollLeft(outputScrollStep)
+			return m, nil
+		case ">":
+			if !m.historyLogsWrap {
+				m.historyLogsViewport.ScrollRight(outputScrollStep)
+			}
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.historyLogsViewport, cmd = m.historyLogsViewport.Update(msg)
+		if m.historyLogsViewport.AtTop() && m.historyLogStart > 0 && !m.historyLogLoading {
+			m.historyLogLoading = true
+			return m, tea.Batch(cmd, loadHistoryLogChunk(m.historySelected, m.historyLogStart))
+		}
 		return m, cmd
 	}
 
@@ -209,7 +338,7 @@ func (m *model) fetchAllHistoryMRs() tea.Cmd {
 			return fetchAllHistoryMRsMsg{err: fmt.Errorf("no credentials or project")}
 		}
 
-		client := NewGitLabClient(m.creds.GitLabURL, m.creds.Token)
+		client := NewForge(*m.creds)
 		mrDetailsMap := make(map[int]*MergeRequestDetails)
 
 		// Fetch all MRs - prefer using saved IIDs if available, fallback to branch search
@@ -299,6 +428,9 @@ func (m model) viewHistoryDetail() string {
 			vNumber = " v" + parts[len(parts)-1]
 		}
 	}
+	if entry.Candidate > 0 {
+		vNumber = fmt.Sprintf(" candidate %d", entry.Candidate)
+	}
 
 	// Prefix style (matching history list title)
 	prefixStyle := lipgloss.NewStyle().
@@ -313,7 +445,7 @@ func (m model) viewHistoryDetail() string {
 		fgStyle.Render(entry.Version+vNumber+" to ") +
 		envStyle.Render(entry.Environment) + fgStyle.Render(" was ") +
 		statusStyle.Render(entry.Status) + fgStyle.Render(" at "+
-		entry.DateTime.Format("02.01.2006 15:04"))
+		formatDateTime(entry.DateTime))
 
 	// Wrap title with border (matching contentStyle border color)
 	titleWithBorder := lipgloss.NewStyle().
@@ -329,7 +461,24 @@ func (m model) viewHistoryDetail() string {
 		Render(titleWithBorder + "\n\n" + tabs + "\n\n" + content)
 
 	// Help footer with empty line after
-	helpText := "H/L: switch tab • j/k: nav • d/u: scroll • o: open • r: reload • C+q: back"
+	rollbackHelp := ""
+	if m.historySelected != nil && m.historySelected.Status == "completed" {
+		rollbackHelp = " • R: roll back"
+		if m.historySelected.Candidate > 0 {
+			rollbackHelp += " • P: promote"
+		}
+	}
+	if m.historySelected != nil && m.historySelected.Status == "aborted" {
+		rollbackHelp = " • F: post-mortem"
+	}
+	helpText := "H/L: switch tab • j/k: nav • d/u: scroll • o: open • r: reload • e: annotate • t: label" + rollbackHelp + " • C+q: back"
+	if m.historyDetailTab == 2 {
+		if m.historyLogsWrap {
+			helpText = "H/L: switch tab • j/k/d/u: scroll • w: truncate • o: open • e: annotate" + rollbackHelp + " • C+q: back"
+		} else {
+			helpText = "H/L: switch tab • j/k/d/u: scroll • </>: sideways • w: wrap • o: open • e: annotate" + rollbackHelp + " • C+q: back"
+		}
+	}
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 
 	return lipgloss.JoinVertical(lipgloss.Left, main, help, "")
@@ -415,7 +564,7 @@ func (m model) viewHistoryMetaTab(height int) string {
 		label string
 		value string
 	}{
-		{"Date", entry.DateTime.Format("02.01.2006 15:04")},
+		{"Date", formatDateTime(entry.DateTime)},
 		{"Environment", entry.Environment},
 		{"Version", entry.Version},
 		{"Number", number},
@@ -433,6 +582,60 @@ func (m model) viewHistoryMetaTab(height int) string {
 			value string
 		}{"MR URL", entry.CreatedMRURL})
 	}
+	if entry.Rollout != nil {
+		rows = append(rows, struct {
+			label string
+			value string
+		}{"Rollout", entry.Rollout.String()})
+	}
+	if len(entry.PipelineInputs) > 0 {
+		rows = append(rows, struct {
+			label string
+			value string
+		}{"Pipeline inputs", formatPipelineInputs(entry.PipelineInputs)})
+	}
+	for _, e := range m.historyEntries {
+		if e.ID == entry.RollbackOf {
+			rows = append(rows, struct {
+				label string
+				value string
+			}{"Rollback of", historyGitTag(e)})
+		}
+	}
+	if e := rolledBackBy(m.historyEntries, entry.ID); e != nil {
+		rows = append(rows, struct {
+			label string
+			value string
+		}{"Rolled back by", historyGitTag(*e)})
+	}
+	if entry.Candidate > 0 || entry.PromotedFrom != "" {
+		// The release candidate cycle of the version: its candidates and their promotion
+		candidates, promotion := candidateLineage(m.historyEntries, entry.HistoryIndexEntry)
+		tags := make([]string, len(candidates))
+		for i, c := range candidates {
+			tags[i] = historyGitTag(c)
+		}
+		if len(tags) > 0 {
+			rows = append(rows, struct {
+				label string
+				value string
+			}{"Candidates", strings.Join(tags, ", ")})
+		}
+		if promotion != nil && promotion.ID != entry.ID {
+			rows = append(rows, struct {
+				label string
+				value string
+			}{"Promoted to", historyGitTag(*promotion)})
+		}
+		for _, e := range m.historyEntries {
+			if e.ID == entry.PromotedFrom {
+				rows = append(rows, struct {
+					label string
+					value string
+				}{"Promoted from", historyGitTag(e)})
+			}
+		}
+	}
 
 	for _, row := range rows {
 		label := historyMetaLabelStyle.Width(20).Render(row.label)
@@ -454,6 +657,20 @@ func (m model) viewHistoryMetaTab(height int) string {
 		sb.WriteString("\n")
 	}
 
+	if entry.FailureCategory != "" {
+		sb.WriteString("\n" + historyMetaLabelStyle.Render("Post-mortem: "+entry.FailureCategory) + "\n")
+		if entry.PostMortem != "" {
+			sb.WriteString(historyMetaValueStyle.Width(m.width - 12).Render(entry.PostMortem))
+			sb.WriteString("\n")
+		}
+	}
+
+	if entry.Annotation != "" {
+		sb.WriteString("\n" + historyMetaLabelStyle.Render("Annotation") + "\n")
+		sb.WriteString(historyMetaValueStyle.Width(m.width - 12).Render(entry.Annotation))
+		sb.WriteString("\n")
+	}
+
 	return sb.String()
 }
 
@@ -463,7 +680,10 @@ func (m model) viewHistoryLogsTab(height int) string {
 		return ""
 	}
 
-	if len(m.historySelected.TerminalOutput) == 0 {
+	if len(m.historyLogLines) == 0 {
+		if m.historyLogLoading {
+			return lipgloss.NewStyle().Foreground(currentTheme.Notion).Render("Loading logs...")
+		}
 		return lipgloss.NewStyle().Foreground(currentTheme.Notion).Render("No logs available")
 	}
 
@@ -522,15 +742,7 @@ func (m model) renderHistoryMRMarkdown() string {
 		return lipgloss.NewStyle().Foreground(currentTheme.Notion).Render("No MR details available for this branch")
 	}
 
-	style := styles.DarkStyleConfig
-	style.Document.StylePrimitive.Color = stringPtr(string(currentTheme.Foreground))
-	style.Strong.Color = stringPtr(string(currentTheme.Warning))
-	style.H1.Prefix = " "
-	style.H1.BackgroundColor = stringPtr(string(currentTheme.Accent))
-	style.H1.Color = stringPtr(string(currentTheme.AccentForeground))
-	style.H2.Prefix = ""
-	style.H3.Prefix = ""
-	style.H3.Color = stringPtr(string(currentTheme.Accent))
+	style := markdownStyle()
 
 	// Clean up author name (replace multiple spaces with single space)
 	authorName := strings.Join(strings.Fields(details.Author.Name), " ")
@@ -566,7 +778,7 @@ func (m model) renderHistoryMRMarkdown() string {
 		details.Author.Username,
 		details.SourceBranch,
 		details.TargetBranch,
-		details.CreatedAt.Format("02.01.2006 15:04"),
+		formatDateTime(details.CreatedAt),
 		discussionInfo,
 		details.CommitsCount,
 		changesCount,
@@ -575,6 +787,7 @@ func (m model) renderHistoryMRMarkdown() string {
 
 	renderer, _ := glamour.NewTermRenderer(
 		glamour.WithStyles(style),
+		glamour.WithColorProfile(termPolicy.colors),
 		glamour.WithWordWrap(m.historyMRViewport.Width),
 		glamour.WithPreservedNewLines(),
 	)
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// digestPeriod is the period a digest covers without --since
+const digestPeriod = 7 * 24 * time.Hour
+
+// historyDigestCommand summarizes the releases of a period for team channels
+var historyDigestCommand = &cliCommand{
+	Name:        "digest",
+	Summary:     "Summarize the releases of the past week as Markdown",
+	Description: "Aggregates the release history of a period, by default the past seven days, into a Markdown digest for posting in team channels: per project the versions shipped to each environment, the released MRs with their authors, and incidents (rollbacks and annotations written in the history). Releases saved before the project was recorded are listed under \"Other releases\". Runs without the TUI, e.g. from a weekly scheduled job.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix history digest",
+		"relix history digest --env prod --output digest.md",
+		"relix history digest --since 2026-03-01 --until 2026-03-31 --project group/app",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		filter := registerHistoryFilterFlags(fs)
+		project := fs.String("project", "", "Only releases of project `path`, e.g. group/app")
+		output := fs.String("output", "", "Write to `file` instead of stdout")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			now := time.Now()
+			if *filter.since == "" {
+				*filter.since = now.Add(-digestPeriod).Format("2006-01-02")
+			}
+			index, err := loadFilteredHistory(filter)
+			if err != nil {
+				return err
+			}
+
+			entries := make([]*ReleaseHistoryEntry, 0, len(index))
+			for _, ie := range index {
+				detail, err := LoadHistoryDetail(ie.ID)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", ie.ID, err)
+					continue
+				}
+				if *project != "" && !strings.EqualFold(detail.Project, *project) {
+					continue
+				}
+				entries = append(entries, detail)
+			}
+
+			until := now
+			if *filter.until != "" {
+				until, _ = time.ParseInLocation("2006-01-02", *filter.until, time.Local) // Validated by loadFilteredHistory
+			}
+			since, _ := time.ParseInLocation("2006-01-02", *filter.since, time.Local)
+
+			w := io.Writer(os.Stdout)
+			if *output != "" {
+				f, err := os.Create(*output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			writeHistoryDigest(w, entries, since, until)
+			return nil
+		}
+	},
+}
+
+// digestMR is a merge request released in the digest period
+type digestMR struct {
+	iid    int
+	title  string
+	author string
+	url    string
+}
+
+// writeHistoryDigest renders releases, newest first, as a Markdown digest grouped by project
+func writeHistoryDigest(w io.Writer, entries []*ReleaseHistoryEntry, since, until time.Time) {
+	fmt.Fprintf(w, "# Release digest %s – %s\n", formatDate(since), formatDate(until))
+
+	byProject := make(map[string][]*ReleaseHistoryEntry)
+	var projects []string
+	authors := make(map[string]bool)
+	var shipped, aborted int
+	for _, e := range entries {
+		if _, ok := byProject[e.Project]; !ok {
+			projects = append(projects, e.Project)
+		}
+		byProject[e.Project] = append(byProject[e.Project], e)
+		if e.Status != "completed" {
+			aborted++
+		} else {
+			shipped++
+			for _, author := range e.MRAuthors {
+				if author != "" {
+					authors[author] = true
+				}
+			}
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "\nNo releases.")
+		return
+	}
+	fmt.Fprintf(w, "\nShipped: %d, aborted: %d, projects: %d, MR authors: %d\n", shipped, aborted, len(projects), len(authors))
+
+	// Projects in alphabetical order, releases of older versions without a project last
+	slices.SortFunc(projects, func(a, b string) int {
+		if (a == "") != (b == "") {
+			if a == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	for _, project := range projects {
+		title := project
+		if title == "" {
+			title = "Other releases"
+		}
+		fmt.Fprintf(w, "\n## %s\n", title)
+		writeProjectDigest(w, byProject[project])
+	}
+}
+
+// writeProjectDigest renders the releases of one project
+func writeProjectDigest(w io.Writer, entries []*ReleaseHistoryEntry) {
+	var shipped, aborted []string
+	var mrs []digestMR
+	seen := make(map[string]bool)
+	authors := make(map[string]bool)
+	var incidents []string
+	for _, e := range entries {
+		tag := historyFullTag(e.HistoryIndexEntry)
+		if e.Tag == "" {
+			tag = strings.TrimSpace(e.Environment + " " + e.Version)
+		}
+		release := fmt.Sprintf("%s (%s)", tag, formatDate(e.DateTime))
+		if e.Status != "completed" {
+			aborted = append(aborted, release)
+		} else {
+			shipped = append(shipped, release)
+			for i, branch := range e.MRBranches {
+				mr := digestMR{title: branch}
+				if i < len(e.MRIIDs) {
+					mr.iid = e.MRIIDs[i]
+				}
+				if i < len(e.MRTitles) && e.MRTitles[i] != "" {
+					mr.title = e.MRTitles[i]
+				}
+				if i < len(e.MRAuthors) {
+					mr.author = e.MRAuthors[i]
+				}
+				if i < len(e.MRURLs) {
+					mr.url = e.MRURLs[i]
+				}
+				// An MR released to several environments is listed once
+				key := branch
+				if mr.iid != 0 {
+					key = fmt.Sprint(mr.iid)
+				}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				mrs = append(mrs, mr)
+				if mr.author != "" {
+					authors[mr.author] = true
+				}
+			}
+		}
+		if e.RollbackOf != "" {
+			incidents = append(incidents, fmt.Sprintf("%s: rollback of release %s", release, e.RollbackOf))
+		}
+		if e.FailureCategory != "" {
+			incidents = append(incidents, strings.TrimSuffix(fmt.Sprintf("%s: aborted, %s: %s", release, e.FailureCategory, e.PostMortem), ": "))
+		}
+		if note := strings.TrimSpace(e.Annotation); note != "" {
+			incidents = append(incidents, fmt.Sprintf("%s: %s", release, strings.Join(strings.Fields(note), " ")))
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(shipped) > 0 {
+		fmt.Fprintf(w, "- **Shipped:** %s\n", strings.Join(shipped, ", "))
+	}
+	if len(aborted) > 0 {
+		fmt.Fprintf(w, "- **Aborted:** %s\n", strings.Join(aborted, ", "))
+	}
+	if len(authors) > 0 {
+		names := make([]string, 0, len(authors))
+		for author := range authors {
+			names = append(names, "@"+author)
+		}
+		slices.Sort(names)
+		fmt.Fprintf(w, "- **Authors:** %s\n", strings.Join(names, ", "))
+	}
+
+	if len(mrs) > 0 {
+		fmt.Fprintf(w, "\n### Merge requests (%d)\n\n", len(mrs))
+		for _, mr := range mrs {
+			line := mr.title
+			if mr.iid != 0 {
+				line = fmt.Sprintf("!%d %s", mr.iid, line)
+			}
+			if mr.url != "" {
+				line = fmt.Sprintf("[%s](%s)", line, mr.url)
+			}
+			if mr.author != "" {
+				line += " — @" + mr.author
+			}
+			fmt.Fprintf(w, "- %s\n", line)
+		}
+	}
+
+	if len(incidents) > 0 {
+		fmt.Fprintf(w, "\n### Incidents (%d)\n\n", len(incidents))
+		for _, incident := range incidents {
+			fmt.Fprintf(w, "- %s\n", incident)
+		}
+	}
+}
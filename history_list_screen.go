@@ -43,12 +43,18 @@ func (d historyDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	// Format values
 	tag := truncateWithEllipsis(entry.Tag, tagW)
 	env := entry.Environment
-	dateStr := entry.DateTime.Format("02.01.2006 15:04")
+	dateStr := formatDateTime(entry.DateTime)
 	mrsLabel := "MRs"
 	if entry.MRCount == 1 {
 		mrsLabel = "MR"
 	}
 	mrs := fmt.Sprintf("%d %s", entry.MRCount, mrsLabel)
+	if entry.RollbackOf != "" {
+		mrs = "rollback"
+	}
+	if entry.PromotedFrom != "" {
+		mrs = "promotion"
+	}
 
 	// Pad columns
 	tag = padColumn(tag, tagW)
@@ -140,6 +146,7 @@ func (m *model) updateHistoryListSize() {
 	// Adjust height for title border (2) + help empty line (1) = 3 extra lines
 	m.historyList.SetSize(listWidth, m.height-11)
 	m.historyList.SetDelegate(newHistoryDelegate(listWidth))
+	fitPagination(&m.historyList)
 }
 
 // selectedHistoryCount returns the number of selected history entries
@@ -161,6 +168,14 @@ func (m *model) fetchHistory() tea.Cmd {
 	}
 }
 
+// openHistoryList opens the releases history and loads it
+func (m *model) openHistoryList() tea.Cmd {
+	m.moveToScreen(screenHistoryList)
+	m.loadingHistory = true
+	m.initHistoryListScreen()
+	return tea.Batch(m.spinner.Tick, m.fetchHistory())
+}
+
 // updateHistoryList handles key events on the history list screen
 func (m model) updateHistoryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle delete confirmation modal first
@@ -188,9 +203,22 @@ func (m model) updateHistoryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// The timeline replaces the list: only its keys and going back apply
+	if m.historyTimeline && msg.String() != "ctrl+q" {
+		switch msg.String() {
+		case "T", "esc":
+			m.historyTimeline = false
+		case "[":
+			m.historyTimelineSpan = max(m.historyTimelineSpan-1, 0)
+		case "]":
+			m.historyTimelineSpan = min(m.historyTimelineSpan+1, len(timelineSpans)-1)
+		}
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "ctrl+q":
-		m.screen = screenHome
+		m.goBack()
 		m.historySelectMode = false
 		m.historySelectedIDs = nil
 		return m, nil
@@ -205,7 +233,14 @@ func (m model) updateHistoryList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.historySelectedIDs = nil
 			return m, nil
 		}
-		m.screen = screenHome
+		m.goBack()
+		return m, nil
+	case "T":
+		if m.historyList.FilterState() == list.Filtering || m.historySelectMode {
+			break
+		}
+		m.historyTimeline = true
+		m.historyTimelineSpan = defaultTimelineSpan
 		return m, nil
 	case "v":
 		if m.historyList.FilterState() == list.Filtering {
@@ -304,6 +339,7 @@ func (m model) executeHistoryDelete() (tea.Model, tea.Cmd) {
 		items[i] = historyListItem{entry: entry}
 	}
 	m.historyList.SetItems(items)
+	fitPagination(&m.historyList)
 	m.historyList.Title = fmt.Sprintf("Releases History (%d)", len(filtered))
 
 	// Exit select mode and close modal
@@ -375,6 +411,10 @@ func (m model) viewHistoryList() string {
 	)
 
 	listContent := m.historyList.View()
+	if m.historyTimeline {
+		header = ""
+		listContent = m.viewHistoryTimeline(listWidth)
+	}
 
 	// Render with spacing: title, empty line, header, list
 	content := contentStyle.
@@ -384,10 +424,12 @@ func (m model) viewHistoryList() string {
 
 	// Help footer with empty line after
 	var helpText string
-	if m.historySelectMode {
+	if m.historyTimeline {
+		helpText = "[/]: period • T: list • C+q: back • C+c: quit"
+	} else if m.historySelectMode {
 		helpText = "v: exit select • space: toggle • d: delete • esc: cancel"
 	} else {
-		helpText = "j/k: nav • enter: view • /: search • v: select • C+q: back • C+c: quit"
+		helpText = "j/k: nav • enter: view • /: search • v: select • T: timeline • C+q: back • C+c: quit"
 	}
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// History sync: with "history_sync" in the config, every release saved to the local history is
+// pushed to a shared "relix serve" instance (POST /api/history), which keeps the releases of the
+// whole team. Releases that cannot be pushed wait in ~/.relix/history-sync.json and are pushed
+// again along with the next one. The home screen reads the team activity feed back from the
+// server (GET /api/activity): who released what to which environment, with the user's own
+// releases dimmed and releases whose annotation mentions the user highlighted.
+
+const (
+	historySyncFileName = "history-sync.json"
+	teamActivityLines   = 6 // Releases shown in the team activity widget
+)
+
+// historySyncMu serializes access to the pending file (pushes run in their own commands)
+var historySyncMu sync.Mutex
+
+// teamActivity is a release in the team activity feed
+type teamActivity struct {
+	ID          string    `json:"id"`
+	ReleasedBy  string    `json:"released_by"`
+	Environment string    `json:"environment"`
+	Tag         string    `json:"tag"`
+	Project     string    `json:"project,omitempty"`
+	Status      string    `json:"status"`
+	DateTime    time.Time `json:"datetime"`
+	Annotation  string    `json:"annotation,omitempty"`
+}
+
+// historySyncError is an error response of the sync server, as opposed to the server being unreachable
+type historySyncError struct {
+	status int
+	msg    string
+}
+
+func (e *historySyncError) Error() string {
+	if e.msg != "" {
+		return "history sync server: " + e.msg
+	}
+	return fmt.Sprintf("history sync server error: status %d", e.status)
+}
+
+// getHistorySyncPath returns the path of the file listing the releases waiting to be pushed
+func getHistorySyncPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historySyncFileName), nil
+}
+
+// readPendingHistory returns the IDs of the releases waiting to be pushed; the caller holds historySyncMu
+func readPendingHistory() ([]string, error) {
+	path, err := getHistorySyncPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// writePendingHistory replaces the releases waiting to be pushed, removing the file once none
+// wait; the caller holds historySyncMu
+func writePendingHistory(ids []string) error {
+	path, err := getHistorySyncPath()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// historySyncRequest sends a request to the sync server and decodes its JSON response into out
+func historySyncRequest(server *HistorySyncConfig, method, path string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(server.URL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+server.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return &historySyncError{status: resp.StatusCode, msg: apiErr.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// pushHistoryEntry pushes a release of the local history to the sync server
+func pushHistoryEntry(server *HistorySyncConfig, id string) error {
+	entry, err := LoadHistoryDetail(id)
+	if err != nil {
+		return err
+	}
+	if entry.ReleasedBy == "" {
+		// Saved before releases recorded their account: the feed could not say who released it
+		return nil
+	}
+	entry.TerminalOutput, entry.LogFrames = nil, nil
+	return historySyncRequest(server, "POST", "/api/history", entry, nil)
+}
+
+// syncHistoryEntry queues a release of the local history for the sync server and pushes the
+// queued releases in order. It stops at the first release the server cannot be reached for,
+// keeping that one and the rest queued; releases the server rejects or that were deleted
+// meanwhile are dropped.
+func syncHistoryEntry(id string) error {
+	config, err := LoadConfig()
+	if err != nil || config.HistorySync == nil || config.HistorySync.URL == "" {
+		return err
+	}
+
+	historySyncMu.Lock()
+	defer historySyncMu.Unlock()
+	pending, err := readPendingHistory()
+	if err != nil {
+		return fmt.Errorf("read pending history: %w", err)
+	}
+	if id != "" && !slices.Contains(pending, id) {
+		pending = append(pending, id)
+	}
+
+	var errs []error
+	i := 0
+	for ; i < len(pending); i++ {
+		err := pushHistoryEntry(config.HistorySync, pending[i])
+		var syncErr *historySyncError
+		if err != nil && !errors.As(err, &syncErr) && !os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release %s: %w", pending[i], err))
+		}
+	}
+	if err := writePendingHistory(pending[i:]); err != nil {
+		errs = append(errs, fmt.Errorf("write pending history: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// syncHistory returns a command pushing a saved release to the sync server, if one is configured
+func syncHistory(id string) tea.Cmd {
+	if id == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		syncHistoryEntry(id)
+		return nil
+	}
+}
+
+// loadTeamActivity returns the latest releases of the team from the sync server, and whether
+// history sync is configured at all. The releases waiting to be pushed are pushed first.
+func loadTeamActivity() ([]teamActivity, bool, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, false, err
+	}
+	if config.HistorySync == nil || config.HistorySync.URL == "" {
+		return nil, false, nil
+	}
+	syncHistoryEntry("")
+
+	var activity []teamActivity
+	query := url.Values{"limit": {strconv.Itoa(teamActivityLines)}}
+	err = historySyncRequest(config.HistorySync, "GET", "/api/activity?"+query.Encode(), nil, &activity)
+	return activity, true, err
+}
+
+// releaserName shortens an account to its name, e.g. "maria" for maria@acme.com
+func releaserName(account string) string {
+	name, _, _ := strings.Cut(account, "@")
+	return name
+}
+
+// mentions reports whether the annotation mentions the account as @name
+func (a teamActivity) mentions(account string) bool {
+	name := releaserName(account)
+	if name == "" {
+		return false
+	}
+	annotation := strings.ToLower(a.Annotation)
+	mention := "@" + strings.ToLower(name)
+	for {
+		i := strings.Index(annotation, mention)
+		if i < 0 {
+			return false
+		}
+		annotation = annotation[i+len(mention):]
+		// "@maria" and "@maria." but not "@mariana" or "@maria.k"
+		rest := strings.TrimPrefix(annotation, ".")
+		if rest == "" || !isMentionChar(rest[0]) {
+			return true
+		}
+	}
+}
+
+// isMentionChar reports whether c may be part of a mentioned name
+func isMentionChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-'
+}
+
+// text describes the release, e.g. "maria released stage 2.3.1-v2 of billing 2 hours ago"
+func (a teamActivity) text() string {
+	who := releaserName(a.ReleasedBy)
+	if who == "" {
+		who = "someone"
+	}
+	verb := "released"
+	if a.Status == "aborted" {
+		verb = "aborted"
+	}
+	text := fmt.Sprintf("%s %s %s %s", who, verb, strings.ToLower(a.Environment), a.Tag)
+	if a.Project != "" {
+		text += " of " + a.Project[strings.LastIndex(a.Project, "/")+1:]
+	}
+	return text + " " + humanize.Time(a.DateTime)
+}
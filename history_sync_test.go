@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTeamActivityText(t *testing.T) {
+	at := time.Now().Add(-2 * time.Hour)
+	tests := []struct {
+		activity teamActivity
+		want     string
+	}{
+		{teamActivity{ReleasedBy: "maria@acme.com", Environment: "STAGE", Tag: "2.3.1-v2", Project: "acme/billing", Status: "completed", DateTime: at},
+			"maria released stage 2.3.1-v2 of billing 2 hours ago"},
+		{teamActivity{ReleasedBy: "bob", Environment: "prod", Tag: "1.0.0-v1", Status: "aborted", DateTime: at},
+			"bob aborted prod 1.0.0-v1 2 hours ago"},
+		{teamActivity{Environment: "test", Tag: "0.1.0-v3", Project: "shop", Status: "completed", DateTime: at},
+			"someone released test 0.1.0-v3 of shop 2 hours ago"},
+	}
+	for _, tt := range tests {
+		if got := tt.activity.text(); got != tt.want {
+			t.Errorf("text %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTeamActivityMentions(t *testing.T) {
+	tests := []struct {
+		annotation string
+		want       bool
+	}{
+		{"@maria please check the smoke tests", true},
+		{"Rolled back, cc @Maria.", true},
+		{"thanks @mariana", false},
+		{"@maria.k on call", false},
+		{"maria knows", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		a := teamActivity{Annotation: tt.annotation}
+		if got := a.mentions("maria@acme.com"); got != tt.want {
+			t.Errorf("%q mentions maria: %v, want %v", tt.annotation, got, tt.want)
+		}
+	}
+	if (teamActivity{Annotation: "@ anyone"}).mentions("") {
+		t.Error("an empty account is mentioned")
+	}
+}
+
+func TestSyncHistoryEntry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var up atomic.Bool
+	var pushed []ReleaseHistoryEntry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer team-secret" {
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+		if !up.Load() {
+			// Unreachable: the connection is dropped
+			hj, _ := w.(http.Hijacker)
+			conn, _, _ := hj.Hijack()
+			conn.Close()
+			return
+		}
+		var entry ReleaseHistoryEntry
+		json.NewDecoder(r.Body).Decode(&entry)
+		pushed = append(pushed, entry)
+		writeAPIJSON(w, http.StatusOK, map[string]string{"id": entry.ID})
+	}))
+	defer server.Close()
+
+	config, _ := LoadConfig()
+	config.HistorySync = &HistorySyncConfig{URL: server.URL + "/", Token: "team-secret"}
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	setAuditAccount(Credentials{Email: "maria@acme.com"})
+	defer setAuditAccount(Credentials{})
+	id, err := SaveReleaseHistory(0, "acme/billing", &ReleaseState{Environment: Environment{Name: "stage"}, Version: "2.3.1"}, "completed", []string{"output"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syncHistoryEntry(id); err != nil {
+		t.Fatal(err)
+	}
+	historySyncMu.Lock()
+	pending, _ := readPendingHistory()
+	historySyncMu.Unlock()
+	if len(pending) != 1 || pending[0] != id {
+		t.Fatalf("pending %v while the server is unreachable, want %s", pending, id)
+	}
+
+	up.Store(true)
+	if err := syncHistoryEntry(""); err != nil {
+		t.Fatal(err)
+	}
+	historySyncMu.Lock()
+	pending, _ = readPendingHistory()
+	historySyncMu.Unlock()
+	if len(pending) != 0 {
+		t.Errorf("pending %v after the server is back", pending)
+	}
+	if len(pushed) != 1 || pushed[0].ReleasedBy != "maria@acme.com" || pushed[0].Project != "acme/billing" || len(pushed[0].LogFrames) != 0 {
+		t.Errorf("pushed %+v, want the release without its log", pushed)
+	}
+}
+
+func TestImportHistoryAndActivity(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := newAPIServer("secret", "", "sync-secret", "", &Credentials{}, 1)
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	id := func(d time.Duration) string { return at.Add(d).Local().Format(releaseIDLayout) }
+	push := func(entry ReleaseHistoryEntry) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(entry)
+		req := httptest.NewRequest("POST", "/api/history", strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Bearer sync-secret")
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, req)
+		return rec
+	}
+	release := func(by string, datetime time.Time, annotation string) ReleaseHistoryEntry {
+		return ReleaseHistoryEntry{
+			HistoryIndexEntry: HistoryIndexEntry{ID: "20261016-120000", ReleasedBy: by, Environment: "stage", Tag: "2.3.1-v1", Status: "completed", DateTime: datetime},
+			Project:           "acme/billing",
+			Annotation:        annotation,
+		}
+	}
+
+	for _, entry := range []ReleaseHistoryEntry{
+		release("maria@acme.com", at, ""),
+		release("bob@acme.com", at, ""),                    // Same time, another account: listed first of the two
+		release("maria@acme.com", at, "cc @bob"),           // Pushed again with an annotation
+		release("carol@acme.com", at.Add(-time.Hour), ""),  // Older, listed last
+		release("dave@acme.com", at.Add(time.Minute), "x"), // Newer, listed first
+	} {
+		if rec := push(entry); rec.Code != http.StatusOK {
+			t.Fatalf("push by %s: status %d: %s", entry.ReleasedBy, rec.Code, rec.Body)
+		}
+	}
+	if rec := push(ReleaseHistoryEntry{HistoryIndexEntry: HistoryIndexEntry{ID: "x"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("a release without its account: status %d, want 400", rec.Code)
+	}
+
+	// The pushed ID would name a file outside the history
+	config, _ := LoadConfig()
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	escape := release("eve@acme.com", at.Add(-2*time.Hour), "")
+	escape.ID = "../../../.relix/config"
+	if rec := push(escape); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"`+id(-2*time.Hour)+`"`) {
+		t.Errorf("a release with a path as its ID: status %d: %s", rec.Code, rec.Body)
+	}
+	if after, err := LoadConfig(); err != nil || after.SelectedProjectID != config.SelectedProjectID || len(after.Environments) == 0 {
+		t.Errorf("the config was overwritten: %+v %v", after, err)
+	}
+
+	// The sync token cannot start releases
+	req := httptest.NewRequest("POST", "/api/release", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer sync-secret")
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("a release started with the sync token: status %d, want 403", rec.Code)
+	}
+
+	rec = apiRequest(t, s, "GET", "/api/activity?limit=10")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var activity []teamActivity
+	if err := json.Unmarshal(rec.Body.Bytes(), &activity); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range activity {
+		got = append(got, a.ID+" "+a.ReleasedBy+" "+a.Project+" "+a.Annotation)
+	}
+	want := []string{
+		id(time.Minute) + " dave@acme.com acme/billing x",
+		id(0) + "-2 bob@acme.com acme/billing ",
+		id(0) + " maria@acme.com acme/billing cc @bob",
+		id(-time.Hour) + " carol@acme.com acme/billing ",
+		id(-2*time.Hour) + " eve@acme.com acme/billing ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("activity\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
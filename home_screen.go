@@ -5,7 +5,6 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/charmbracelet/x/ansi"
 )
 
 // RELIX FIGlet ANSI Shadow font
@@ -17,9 +16,9 @@ const relixASCII = `
 ██║  ██║███████╗███████╗██║██╔╝ ██╗
 ╚═╝  ╚═╝╚══════╝╚══════╝╚═╝╚═╝  ╚═╝`
 
-// hasInProgressRelease checks if there is an uncompleted release
-func hasInProgressRelease() bool {
-	state, err := LoadReleaseState()
+// hasInProgressRelease checks if there is an uncompleted release in a tab
+func hasInProgressRelease(tab int) bool {
+	state, err := LoadReleaseState(tab)
 	return err == nil && state != nil
 }
 
@@ -33,77 +32,49 @@ func (m model) updateHome(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			(&m).initListScreen()
 			(&m).updateListSize()
 		}
-		m.screen = screenMain
+		m.moveToScreen(screenMain)
 		if m.selectedProject == nil {
 			// No project selected - show project selector
 			m.showProjectSelector = true
-			m.loadingProjects = true
-			return m, tea.Batch(m.spinner.Tick, m.fetchProjects())
+			return m, m.loadProjects()
 		}
 		if !m.mrsLoaded {
-			m.loadingMRs = true
-			return m, tea.Batch(m.spinner.Tick, m.fetchMRs())
+			return m, m.loadMRs()
 		}
 		return m, nil
 	case "h":
 		// Go to releases history
-		m.screen = screenHistoryList
-		m.loadingHistory = true
-		m.initHistoryListScreen()
-		return m, tea.Batch(m.spinner.Tick, m.fetchHistory())
+		cmd := m.openHistoryList()
+		return m, cmd
 	case "s":
 		// Open settings screen
-		m.settingsPreviousScreen = m.screen
-		m.settingsTab = 0
-		m.settingsFocusIndex = 0
-		if config, err := LoadConfig(); err == nil {
-			m.settingsExcludePatterns.SetValue(config.ExcludePatterns)
-			pipelineRegex := config.PipelineJobsRegex
-			if pipelineRegex == "" {
-				pipelineRegex = defaultPipelineJobsRegex
-			}
-			m.settingsPipelineRegex.SetValue(pipelineRegex)
-			// Load base branch
-			baseBranch := config.BaseBranch
-			if baseBranch == "" {
-				baseBranch = "root"
-			}
-			m.settingsBaseBranch.SetValue(baseBranch)
-			// Load environment settings
-			envs := config.Environments
-			if len(envs) == 0 {
-				envs = defaultEnvironments()
-			}
-			for i := 0; i < 4 && i < len(envs); i++ {
-				m.settingsEnvNames[i].SetValue(strings.ToUpper(envs[i].Name))
-				m.settingsEnvBranches[i].SetValue(envs[i].BranchName)
-			}
+		cmd := m.openSettings()
+		return m, cmd
+	case "u":
+		// Release notes of a newer relix
+		if m.availableUpdate != nil {
+			m.closeAllModals()
+			m.showUpdateNotes = true
+			m.updateNotesOffset = 0
 		}
-		(&m).updateTextareaTheme()
-		m.screen = screenSettings
-		(&m).initSettingsViewport()
-		return m, m.settingsBaseBranch.Focus()
+		return m, nil
+	case "x":
+		m.dismissUpdate()
+		return m, nil
 	}
 
 	return m, nil
 }
 
-// viewHome renders the home screen
+// viewHome renders the home screen: title, menu and the dashboard widgets
 func (m model) viewHome() string {
 	if m.width == 0 || m.height == 0 {
 		return ""
 	}
 
-	var sb strings.Builder
-
-	// Render ASCII title
-	title := homeTitleStyle.Render(relixASCII)
-	sb.WriteString(title)
-	sb.WriteString("\n\n")
-
 	// Menu items
 	releaseLabel := "Release"
-	if hasInProgressRelease() {
+	if hasInProgressRelease(m.tabID) {
 		releaseLabel = "Continue release"
 	}
 
@@ -116,50 +87,56 @@ func (m model) viewHome() string {
 		{"s", "Settings"},
 	}
 
-	// Get the width of the ASCII title to use as reference
-	titleWidth := 0
-	for _, line := range strings.Split(relixASCII, "\n") {
-		if w := ansi.StringWidth(line); w > titleWidth {
-			titleWidth = w
-		}
-	}
+	// The dashboard spans the screen; title and menu are centered above it
+	width := min(m.width-6, dashboardMaxWidth)
 
-	// Build menu lines (left-aligned text)
-	var menuLines []string
+	// Menu items on one line
+	var menuItemsRendered []string
 	for _, item := range menuItems {
-		menuLines = append(menuLines, homeMenuKeyStyle.Render("["+item.key+"]")+" "+homeMenuItemStyle.Render(item.label))
+		menuItemsRendered = append(menuItemsRendered, homeMenuKeyStyle.Render("["+item.key+"]")+" "+homeMenuItemStyle.Render(item.label))
+	}
+	menu := strings.Join(menuItemsRendered, "   ")
+
+	var below strings.Builder
+	below.WriteString(lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(menu))
+	below.WriteString("\n\n")
+	below.WriteString(m.renderDashboard(width))
+
+	if warning := m.offlineWarning(); warning != "" {
+		below.WriteString("\n\n")
+		below.WriteString(lipgloss.NewStyle().
+			Width(width).
+			Align(lipgloss.Center).
+			Render(settingsErrorStyle.Render(warning)))
 	}
 
-	// Find the widest menu line
-	menuWidth := 0
-	for _, line := range menuLines {
-		if w := ansi.StringWidth(line); w > menuWidth {
-			menuWidth = w
-		}
+	if warning := m.tokenWarning(); warning != "" {
+		below.WriteString("\n\n")
+		below.WriteString(lipgloss.NewStyle().
+			Width(width).
+			Align(lipgloss.Center).
+			Render(settingsErrorStyle.Render(warning)))
+	}
+
+	if banner := m.updateBanner(width); banner != "" {
+		below.WriteString("\n\n")
+		below.WriteString(banner)
 	}
 
-	// Center the left-aligned menu block within the title width
-	menuBlock := lipgloss.NewStyle().
-		Width(titleWidth).
-		Align(lipgloss.Center).
-		Render(
-			lipgloss.NewStyle().
-				Width(menuWidth).
-				Align(lipgloss.Left).
-				Render(strings.Join(menuLines, "\n")),
-		)
-	sb.WriteString(menuBlock)
-
-	// Version centered within title width
-	sb.WriteString("\n\n")
-	version := lipgloss.NewStyle().
-		Width(titleWidth).
-		Align(lipgloss.Center).
-		Render(homeVersionStyle.Render("v" + AppVersion))
-	sb.WriteString(version)
+	// ASCII title with the version below it, or a one-line title when the screen is too short for both
+	version := homeVersionStyle.Render("v" + AppVersion)
+	if m.activeWorkspace != "" {
+		version += homeVersionStyle.Render(" • workspace " + m.activeWorkspace)
+	}
+	title := lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(
+		lipgloss.JoinVertical(lipgloss.Center, homeTitleStyle.Render(relixASCII), "", version))
+	if lipgloss.Height(title)+1+lipgloss.Height(below.String()) > m.height-4 {
+		title = lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(
+			homeTitleStyle.Bold(true).Render("relix") + " " + version)
+	}
 
 	// Center the whole block on screen
-	content := sb.String()
+	content := title + "\n\n" + below.String()
 	contentBlock := contentStyle.
 		Width(m.width - 2).
 		Height(m.height - 4).
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Instances behind an SSO reverse proxy need extra headers or a short-lived cookie on every
+// request. Static headers come from http_headers; the cookie is printed by sso_cookie_command,
+// reused for sso_cookie_ttl and fetched again early when the proxy turns a request away.
+
+const (
+	defaultSSOCookieTTL    = 10 * time.Minute
+	ssoCookieTimeout       = 30 * time.Second // The command may open a browser for the SSO login
+	ssoCookieMinRefreshAge = 30 * time.Second // A rejected cookie younger than this is not fetched again
+)
+
+// forgeHeaderSet is the header config of forge requests
+type forgeHeaderSet struct {
+	headers       map[string]string
+	cookieCommand string
+	cookieTTL     time.Duration
+}
+
+// forgeHeaders reads the header config on first use
+var forgeHeaders = sync.OnceValue(func() forgeHeaderSet {
+	set := forgeHeaderSet{cookieTTL: defaultSSOCookieTTL}
+	if config, err := LoadConfig(); err == nil {
+		set.headers = config.HTTPHeaders
+		set.cookieCommand = strings.TrimSpace(config.SSOCookieCommand)
+		set.cookieTTL = parseTimeout(config.SSOCookieTTL, defaultSSOCookieTTL)
+	}
+	return set
+})
+
+// forgeHosts are the hosts forge clients were created for. Only requests to them get the headers
+// and cookie, not e.g. avatars served from another host.
+var forgeHosts sync.Map
+
+// registerForgeHost marks the host of a forge URL as one to send the headers and cookie to
+func registerForgeHost(rawURL string) {
+	if u, err := url.Parse(strings.TrimSpace(rawURL)); err == nil && u.Host != "" {
+		forgeHosts.Store(strings.ToLower(u.Host), true)
+	}
+}
+
+// isForgeHost reports whether the request goes to a forge host
+func isForgeHost(req *http.Request) bool {
+	_, ok := forgeHosts.Load(strings.ToLower(req.URL.Host))
+	return ok
+}
+
+// ssoCookie caches the output of the SSO cookie command
+type ssoCookie struct {
+	mu        sync.Mutex
+	value     string
+	fetchedAt time.Time
+}
+
+var forgeSSOCookie ssoCookie
+
+// get returns the cookie, running the command if there is none, it expired, or stale is
+// the rejected cookie and it is old enough to be worth fetching again
+func (c *ssoCookie) get(set forgeHeaderSet, stale string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	age := time.Since(c.fetchedAt)
+	fresh := c.value != "" && age < set.cookieTTL
+	if fresh && (stale == "" || stale != c.value || age < ssoCookieMinRefreshAge) {
+		return c.value, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ssoCookieTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "sh", "-c", set.cookieCommand).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("sso_cookie_command failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("sso_cookie_command failed: %w", err)
+	}
+	value := strings.TrimSpace(string(output))
+	if value == "" {
+		return "", fmt.Errorf("sso_cookie_command printed no cookie")
+	}
+	c.value, c.fetchedAt = value, time.Now()
+	return value, nil
+}
+
+// headerTransport adds the configured headers and SSO cookie to requests to the forge host. A request the
+// proxy turns away (401, 403, or a redirect to another host such as the SSO login page) is sent
+// once more with a freshly fetched cookie.
+type headerTransport struct {
+	base http.RoundTripper
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	set := forgeHeaders()
+	if len(set.headers) == 0 && set.cookieCommand == "" || !isForgeHost(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	cookie := ""
+	if set.cookieCommand != "" {
+		var err error
+		if cookie, err = forgeSSOCookie.get(set, ""); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := t.send(req, set, cookie, false)
+	if err != nil || cookie == "" || !rejectedByProxy(req, resp) {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil // The body cannot be sent again
+	}
+	refreshed, err := forgeSSOCookie.get(set, cookie)
+	if err != nil || refreshed == cookie {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return t.send(req, set, refreshed, true)
+}
+
+// send sends a copy of the request with the headers and cookie; retry rewinds the body
+func (t headerTransport) send(req *http.Request, set forgeHeaderSet, cookie string, retry bool) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if retry && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+	for name, value := range set.headers {
+		out.Header.Set(name, value)
+	}
+	if cookie != "" {
+		out.Header.Add("Cookie", cookie)
+	}
+	return t.base.RoundTrip(out)
+}
+
+// rejectedByProxy reports whether the response looks like the SSO proxy turning the request away
+func rejectedByProxy(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	case http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect:
+		location, err := resp.Location()
+		return err == nil && location.Host != req.URL.Host
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Forge API requests are limited by the timeout of their class rather than a fixed client
+// timeout, so slow self-hosted instances and heavy endpoints can be given more time in the
+// config (http_timeouts) without waiting as long for everything else.
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// Request classes, told apart by the request path like endpointLimiter does
+const (
+	requestClassDefault = ""
+	requestClassListing = "listing"
+	requestClassDiff    = "diff"
+	requestClassTrace   = "trace"
+)
+
+// diffSegments and traceSegments are path segments of diff and streamed endpoints across forges
+var (
+	diffSegments  = map[string]bool{"changes": true, "diffs": true, "diff": true, "compare": true, "files": true}
+	traceSegments = map[string]bool{"trace": true, "logs": true, "artifacts": true}
+)
+
+// pageParams are query parameters of paginated lists (GitLab, GitHub, Gitea, Bitbucket)
+var pageParams = []string{"page", "per_page", "limit", "pagelen", "start"}
+
+// forgeTimeoutSet is the parsed http_timeouts config
+type forgeTimeoutSet struct {
+	connect time.Duration
+	read    time.Duration
+	classes map[string]time.Duration
+}
+
+// liveForgeTimeouts are the timeouts of the current config, read on first use after it is saved
+var liveForgeTimeouts atomic.Pointer[forgeTimeoutSet]
+
+// forgeTimeouts returns http_timeouts of the current config; invalid values keep their default
+func forgeTimeouts() forgeTimeoutSet {
+	if set := liveForgeTimeouts.Load(); set != nil {
+		return *set
+	}
+	set := loadForgeTimeouts()
+	liveForgeTimeouts.Store(&set)
+	return set
+}
+
+// loadForgeTimeouts reads http_timeouts from the config
+func loadForgeTimeouts() forgeTimeoutSet {
+	var cfg HTTPTimeouts
+	if config, err := LoadConfig(); err == nil {
+		cfg = config.HTTPTimeouts
+	}
+	set := forgeTimeoutSet{
+		connect: parseTimeout(cfg.Connect, defaultHTTPTimeout),
+		read:    parseTimeout(cfg.Read, defaultHTTPTimeout),
+	}
+	set.classes = map[string]time.Duration{
+		requestClassListing: parseTimeout(cfg.Listing, set.read),
+		requestClassDiff:    parseTimeout(cfg.Diff, set.read),
+		requestClassTrace:   parseTimeout(cfg.Trace, 0),
+	}
+	return set
+}
+
+// parseTimeout parses a configured timeout, returning def if it is empty or invalid
+func parseTimeout(value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return def
+	}
+	return d
+}
+
+// requestClass tells the class of a forge API request by its URL
+func requestClass(u *url.URL) string {
+	segments := strings.Split(u.Path, "/")
+	for _, segment := range segments {
+		if traceSegments[segment] {
+			return requestClassTrace
+		}
+	}
+	for _, segment := range segments {
+		if diffSegments[segment] {
+			return requestClassDiff
+		}
+	}
+	query := u.Query()
+	for _, param := range pageParams {
+		if query.Has(param) {
+			return requestClassListing
+		}
+	}
+	return requestClassDefault
+}
+
+// timeout returns the timeout of a request; 0 means no limit
+func (s forgeTimeoutSet) timeout(u *url.URL) time.Duration {
+	if d, ok := s.classes[requestClass(u)]; ok {
+		return d
+	}
+	return s.read
+}
+
+// dialer returns the dialer of the forge transport, limited by the connect timeout
+func (s forgeTimeoutSet) dialer() *net.Dialer {
+	return &net.Dialer{Timeout: s.connect, KeepAlive: 30 * time.Second}
+}
+
+// timeoutTransport limits each request by the timeout of its class. The deadline also covers
+// reading the body, so it is released only when the body is closed.
+type timeoutTransport struct {
+	base http.RoundTripper
+}
+
+func (t timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := forgeTimeouts().timeout(req.URL)
+	if timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelingBody{resp.Body, cancel}
+	return resp, nil
+}
+
+// cancelingBody releases the request deadline once the body is closed
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// lazyTransport builds its transport on first use, as building it reads the config, and builds it
+// again once the connect timeout it dials with is changed
+type lazyTransport struct {
+	mu      sync.Mutex
+	build   func() http.RoundTripper
+	rt      http.RoundTripper
+	connect time.Duration // Connect timeout rt was built with
+}
+
+func (t *lazyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	connect := forgeTimeouts().connect
+	t.mu.Lock()
+	if t.rt == nil || t.connect != connect {
+		t.rt, t.connect = t.build(), connect
+	}
+	rt := t.rt
+	t.mu.Unlock()
+	return rt.RoundTrip(req)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestForgeTimeoutsFollowConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	liveForgeTimeouts.Store(nil)
+	defer liveForgeTimeouts.Store(nil)
+	save := func(timeouts HTTPTimeouts) {
+		t.Helper()
+		config, _ := LoadConfig()
+		config.HTTPTimeouts = timeouts
+		if err := SaveConfig(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	save(HTTPTimeouts{Read: "20s", Diff: "bogus"})
+	if got := forgeTimeouts(); got.read != 20*time.Second || got.connect != defaultHTTPTimeout || got.classes[requestClassDiff] != 20*time.Second {
+		t.Errorf("timeouts %+v, want read and diff 20s", got)
+	}
+
+	var builds []time.Duration
+	transport := &lazyTransport{build: func() http.RoundTripper {
+		builds = append(builds, forgeTimeouts().connect)
+		return http.DefaultTransport
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	get := func() {
+		t.Helper()
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	get()
+	get()
+
+	// Saved in the settings: used without restarting
+	save(HTTPTimeouts{Connect: "3s", Read: "1m"})
+	if got := forgeTimeouts(); got.read != time.Minute || got.connect != 3*time.Second {
+		t.Errorf("timeouts %+v after saving, want read 1m and connect 3s", got)
+	}
+	get()
+	save(HTTPTimeouts{Connect: "3s", Read: "2m"})
+	get()
+	if len(builds) != 2 || builds[0] != defaultHTTPTimeout || builds[1] != 3*time.Second {
+		t.Errorf("transport built with connect timeouts %v, want once per connect timeout", builds)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Iteration selection: "i" on the MR list selects the MRs of the current iteration (GitLab
+// Premium), for teams planning their releases by sprint. An MR belongs to the iteration when it
+// closes or mentions one of the iteration's issues in the project. Drafts and MRs the include_mr
+// hook keeps out are left unselected, as with release votes; MRs selected before stay selected.
+
+// iterationWorkers bounds the issues whose MRs are read at once
+const iterationWorkers = 4
+
+// iterationMRsMsg carries the MRs of the current iteration of a project
+type iterationMRsMsg struct {
+	projectID int
+	title     string // Titles of the current iterations
+	iids      []int
+	err       error
+}
+
+// iterationTitle names an iteration by its title, or its dates for iterations of an automatic
+// cadence
+func iterationTitle(it Iteration) string {
+	if title := strings.TrimSpace(it.Title); title != "" {
+		return title
+	}
+	return it.StartDate + " – " + it.DueDate
+}
+
+// selectIterationMRs returns the command reading the MRs of the current iteration of the project
+func (m *model) selectIterationMRs() tea.Cmd {
+	if m.iterationLoading || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	projectID := m.selectedProject.ID
+	client, ok := NewForge(*m.creds).(*GitLabClient)
+	if !ok {
+		return func() tea.Msg {
+			return iterationMRsMsg{projectID: projectID, err: errors.New("iterations are available on GitLab only")}
+		}
+	}
+	m.iterationLoading = true
+	m.updateMRListTitle()
+	listed := make(map[int]*MergeRequestDetails)
+	for _, mr := range m.mrsAll {
+		if !mr.Draft {
+			listed[mr.IID] = mr
+		}
+	}
+
+	return func() tea.Msg {
+		msg := iterationMRsMsg{projectID: projectID}
+		iterations, err := client.GetCurrentIterations(projectID)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		if len(iterations) == 0 {
+			msg.err = errors.New("no iteration is running in the groups of this project")
+			return msg
+		}
+
+		var titles []string
+		var issues []int
+		for _, it := range iterations {
+			titles = append(titles, iterationTitle(it))
+			iids, err := client.GetIterationIssues(projectID, it.ID)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			issues = append(issues, iids...)
+		}
+		msg.title = strings.Join(titles, ", ")
+
+		// MRs of each issue; issues whose MRs cannot be read are skipped
+		related := make([][]int, len(issues))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, iterationWorkers)
+		for i, issue := range issues {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				related[i], _ = client.GetIssueMergeRequests(projectID, issue)
+			}()
+		}
+		wg.Wait()
+
+		// MRs the hook script keeps out and blocked MRs are left unselected
+		hooks, hooksErr := loadScriptHooks(client, projectID, nil)
+		seen := make(map[int]bool)
+		for _, iids := range related {
+			for _, iid := range iids {
+				mr, ok := listed[iid]
+				if !ok || seen[iid] {
+					continue
+				}
+				seen[iid] = true
+				if _, blocked := blockedMRReason(projectID, iid); blocked {
+					continue
+				}
+				if hooksErr == nil {
+					if ok, _, hookErr := hooks.includeMR(mr); hookErr == nil && !ok {
+						continue
+					}
+				}
+				msg.iids = append(msg.iids, iid)
+			}
+		}
+		return msg
+	}
+}
+
+// handleIterationMRs selects the MRs of the current iteration of the listed project
+func (m *model) handleIterationMRs(msg iterationMRsMsg) {
+	m.iterationLoading = false
+	if m.selectedProject == nil || m.selectedProject.ID != msg.projectID {
+		return
+	}
+	if msg.err != nil {
+		m.updateMRListTitle()
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot select the iteration: " + msg.err.Error()
+		return
+	}
+	listed := make(map[int]bool, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok {
+			listed[mr.MR().IID] = true
+		}
+	}
+	selected := 0
+	for _, iid := range msg.iids {
+		if listed[iid] {
+			m.selectedMRs[iid] = true
+			selected++
+		}
+	}
+	m.saveSelection()
+	m.iterationSelected = fmt.Sprintf("%s: %d MRs", msg.title, selected)
+	m.updateMRListTitle()
+}
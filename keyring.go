@@ -2,7 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/zalando/go-keyring"
 )
 
@@ -11,31 +19,394 @@ const (
 	keyringUser    = "gitlab-credentials"
 )
 
-// LoadCredentials retrieves credentials from the system keyring
+// Keyring backends, chosen with keyring_backend in config
+const (
+	keyringAuto          = "auto" // The platform keyring
+	keyringSecretService = "secret-service"
+	keyringKeychain      = "keychain"
+	keyringWincred       = "wincred"
+	keyringFile          = "file" // ~/.relix/credentials.json, readable only by the user
+)
+
+const credentialsFileName = "credentials.json"
+
+// keyringBackendLabels are the display names of the backends
+var keyringBackendLabels = map[string]string{
+	keyringSecretService: "Secret Service",
+	keyringKeychain:      "macOS Keychain",
+	keyringWincred:       "Windows Credential Manager",
+	keyringFile:          "File",
+}
+
+// platformKeyringBackend returns the OS keyring available on this platform, or "" if there is none
+func platformKeyringBackend() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return keyringKeychain
+	case "windows":
+		return keyringWincred
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return keyringSecretService
+	}
+	return ""
+}
+
+// keyringChoices returns the backends selectable on this platform
+func keyringChoices() []string {
+	if platform := platformKeyringBackend(); platform != "" {
+		return []string{keyringAuto, platform, keyringFile}
+	}
+	return []string{keyringAuto, keyringFile}
+}
+
+// resolveKeyringBackend turns a keyring_backend value into the backend it selects
+func resolveKeyringBackend(backend string) string {
+	if backend == "" || backend == keyringAuto {
+		return platformKeyringBackend()
+	}
+	return backend
+}
+
+// keyringBackendLabel describes a keyring_backend value, e.g. "auto (Secret Service)"
+func keyringBackendLabel(backend string) string {
+	if backend == "" || backend == keyringAuto {
+		if platform := platformKeyringBackend(); platform != "" {
+			return keyringAuto + " (" + keyringBackendLabels[platform] + ")"
+		}
+		return keyringAuto + " (unsupported platform)"
+	}
+	if label, ok := keyringBackendLabels[backend]; ok {
+		return label
+	}
+	return backend
+}
+
+// configuredKeyringBackend returns the keyring_backend value from config
+func configuredKeyringBackend() string {
+	if config, err := LoadConfig(); err == nil && config.KeyringBackend != "" {
+		return config.KeyringBackend
+	}
+	return keyringAuto
+}
+
+// keyringStore returns the store behind a resolved backend
+func keyringStore(backend string) (keyring.Keyring, error) {
+	switch {
+	case backend == keyringFile:
+		return fileKeyring{}, nil
+	case backend == "":
+		return nil, fmt.Errorf("no OS keyring on %s; set \"keyring_backend\": %q in ~/.relix/config.json", runtime.GOOS, keyringFile)
+	case backend == platformKeyringBackend():
+		return systemKeyring{}, nil
+	case keyringBackendLabels[backend] != "":
+		return nil, fmt.Errorf("%s is not available on %s; set \"keyring_backend\" to %q or %q in ~/.relix/config.json",
+			keyringBackendLabels[backend], runtime.GOOS, keyringAuto, keyringFile)
+	}
+	return nil, fmt.Errorf("unknown keyring_backend %q (use auto, secret-service, keychain, wincred or file)", backend)
+}
+
+// credentialStore returns the store selected in config
+func credentialStore() (string, keyring.Keyring, error) {
+	backend := resolveKeyringBackend(configuredKeyringBackend())
+	store, err := keyringStore(backend)
+	return backend, store, err
+}
+
+// LoadCredentials retrieves credentials from the keyring selected in config
 func LoadCredentials() (*Credentials, error) {
-	secret, err := keyring.Get(keyringService, keyringUser)
+	backend, store, err := credentialStore()
 	if err != nil {
 		return nil, err
 	}
+	secret, err := store.Get(keyringService, keyringUser)
+	if err != nil {
+		return nil, explainKeyringError(backend, err)
+	}
 
 	var creds Credentials
 	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
 		return nil, err
 	}
 
+	setAuditAccount(creds)
 	return &creds, nil
 }
 
-// SaveCredentials stores credentials in the system keyring
+// SaveCredentials stores credentials in the keyring selected in config
 func SaveCredentials(creds Credentials) error {
+	backend, store, err := credentialStore()
+	if err == nil {
+		err = setCredentials(backend, store, creds)
+	}
+	if err == nil {
+		setAuditAccount(creds)
+	}
+	recordAudit(auditCredentialsSave, creds.GitLabURL, creds.Email, err)
+	return err
+}
+
+// setCredentials writes credentials to a store
+func setCredentials(backend string, store keyring.Keyring, creds Credentials) error {
 	data, err := json.Marshal(creds)
 	if err != nil {
 		return err
 	}
-	return keyring.Set(keyringService, keyringUser, string(data))
+	return explainKeyringError(backend, store.Set(keyringService, keyringUser, string(data)))
 }
 
-// DeleteCredentials removes credentials from the system keyring
+// DeleteCredentials removes credentials from the keyring selected in config
 func DeleteCredentials() error {
-	return keyring.Delete(keyringService, keyringUser)
+	backend, store, err := credentialStore()
+	if err == nil {
+		err = explainKeyringError(backend, store.Delete(keyringService, keyringUser))
+	}
+	recordAudit(auditCredentialsDelete, "", "", err)
+	if err == nil {
+		setAuditAccount(Credentials{})
+	}
+	return err
+}
+
+// moveCredentials stores creds in the backend selected by to and removes them from the one
+// selected by from. A from backend that is locked or missing is left as is.
+func moveCredentials(from, to string, creds *Credentials) error {
+	fromBackend, toBackend := resolveKeyringBackend(from), resolveKeyringBackend(to)
+	if fromBackend == toBackend {
+		return nil
+	}
+	store, err := keyringStore(toBackend)
+	if err != nil {
+		return err
+	}
+	if creds != nil {
+		err := setCredentials(toBackend, store, *creds)
+		recordAudit(auditCredentialsSave, creds.GitLabURL, creds.Email+" (moved to "+toBackend+")", err)
+		if err != nil {
+			return err
+		}
+	}
+	if old, err := keyringStore(fromBackend); err == nil {
+		old.Delete(keyringService, keyringUser)
+	}
+	return nil
+}
+
+// keyringError is a failed keyring call with a hint on how to fix it
+type keyringError struct {
+	backend string
+	err     error
+	hint    string
+}
+
+func (e *keyringError) Error() string {
+	msg := keyringBackendLabel(e.backend) + ": " + e.err.Error()
+	if e.hint != "" {
+		msg += ". " + e.hint
+	}
+	return msg
+}
+
+func (e *keyringError) Unwrap() error {
+	return e.err
+}
+
+// explainKeyringError wraps an error of a keyring backend with what it means and how to fix it.
+// A missing secret is returned as is, so callers can tell "not logged in" from a broken keyring.
+func explainKeyringError(backend string, err error) error {
+	if err == nil || errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	fileHint := fmt.Sprintf("or set \"keyring_backend\": %q in ~/.relix/config.json", keyringFile)
+	text := strings.ToLower(err.Error())
+	hint := ""
+	switch {
+	case errors.Is(err, keyring.ErrSetDataTooBig):
+		hint = "The credentials are too large for this keyring; " + strings.TrimPrefix(fileHint, "or ")
+	case backend == keyringSecretService && (strings.Contains(text, "session bus") || strings.Contains(text, "dbus-launch") || strings.Contains(text, "connection refused") || strings.Contains(text, "no such file")):
+		hint = "No D-Bus session bus is running: start relix from a desktop session or with dbus-run-session, " + fileHint
+	case backend == keyringSecretService && (strings.Contains(text, "org.freedesktop.secrets") || strings.Contains(text, "serviceunknown")):
+		hint = "No Secret Service provider is running: install and start gnome-keyring or KWallet, or enable Secret Service integration in KeePassXC, " + fileHint
+	case backend == keyringSecretService && (strings.Contains(text, "unlock") || strings.Contains(text, "locked") || strings.Contains(text, "dismissed")):
+		hint = "The login keyring is locked: unlock it (e.g. in Passwords and Keys) and retry, " + fileHint
+	case backend == keyringKeychain && strings.Contains(text, "executable file not found"):
+		hint = "The security tool is missing from /usr/bin, " + fileHint
+	case backend == keyringKeychain && (strings.Contains(text, "exit status 36") || strings.Contains(text, "exit status 51") || strings.Contains(text, "interaction")):
+		hint = "The login keychain is locked (common over SSH): run `security unlock-keychain` and retry, " + fileHint
+	case backend == keyringWincred:
+		hint = "Check that the Credential Manager service is running, " + fileHint
+	case backend == keyringFile:
+		hint = "Check the permissions of ~/.relix/" + credentialsFileName
+	}
+	return &keyringError{backend: backend, err: err, hint: hint}
+}
+
+// keyringDiagnosis is the state of a keyring backend as seen from relix
+type keyringDiagnosis struct {
+	backend        string
+	hasCredentials bool
+	err            error // The backend cannot be used
+}
+
+// keyringDiagnosedMsg carries the state of the selectable backends
+type keyringDiagnosedMsg struct {
+	results map[string]keyringDiagnosis // Keyed by resolved backend
+}
+
+// diagnoseKeyrings reads the credentials from each backend selectable on this platform.
+// It runs in the background: the OS keyring may block while it asks to be unlocked.
+func diagnoseKeyrings() tea.Cmd {
+	return func() tea.Msg {
+		results := make(map[string]keyringDiagnosis)
+		for _, choice := range keyringChoices() {
+			backend := resolveKeyringBackend(choice)
+			if _, done := results[backend]; done {
+				continue
+			}
+			diagnosis := keyringDiagnosis{backend: backend}
+			store, err := keyringStore(backend)
+			if err == nil {
+				_, err = store.Get(keyringService, keyringUser)
+				diagnosis.hasCredentials = err == nil
+				if errors.Is(err, keyring.ErrNotFound) {
+					err = nil
+				}
+			}
+			diagnosis.err = explainKeyringError(backend, err)
+			results[backend] = diagnosis
+		}
+		return keyringDiagnosedMsg{results: results}
+	}
+}
+
+// systemKeyring is the OS keyring of the platform
+type systemKeyring struct{}
+
+func (systemKeyring) Set(service, user, password string) error {
+	return keyring.Set(service, user, password)
+}
+
+func (systemKeyring) Get(service, user string) (string, error) {
+	return keyring.Get(service, user)
+}
+
+func (systemKeyring) Delete(service, user string) error {
+	return keyring.Delete(service, user)
+}
+
+func (systemKeyring) DeleteAll(service string) error {
+	return keyring.DeleteAll(service)
+}
+
+// fileKeyring keeps secrets in a JSON file readable only by the user, for machines without
+// a usable OS keyring (headless servers, containers). The secrets are not encrypted.
+type fileKeyring struct{}
+
+// fileKeyringMu serializes access to the credentials file
+var fileKeyringMu sync.Mutex
+
+func (fileKeyring) path() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, credentialsFileName), nil
+}
+
+// read returns the stored secrets keyed by "service/user"; the caller holds fileKeyringMu
+func (k fileKeyring) read() (map[string]string, error) {
+	path, err := k.path()
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// write replaces the stored secrets, removing the file once none are left; the caller holds fileKeyringMu
+func (k fileKeyring) write(secrets map[string]string) error {
+	path, err := k.path()
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o600) // WriteFile keeps the mode of an existing file
+}
+
+func (k fileKeyring) Set(service, user, password string) error {
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return err
+	}
+	secrets[service+"/"+user] = password
+	return k.write(secrets)
+}
+
+func (k fileKeyring) Get(service, user string) (string, error) {
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[service+"/"+user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k fileKeyring) Delete(service, user string) error {
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[service+"/"+user]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(secrets, service+"/"+user)
+	return k.write(secrets)
+}
+
+func (k fileKeyring) DeleteAll(service string) error {
+	fileKeyringMu.Lock()
+	defer fileKeyringMu.Unlock()
+	secrets, err := k.read()
+	if err != nil {
+		return err
+	}
+	for key := range secrets {
+		if strings.HasPrefix(key, service+"/") {
+			delete(secrets, key)
+		}
+	}
+	return k.write(secrets)
 }
@@ -0,0 +1,949 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hook scripts are written in a subset of Lua 5.4: local and global variables, functions and
+// closures, tables, if/while/repeat/numeric and generic for, and the usual operators. Not
+// supported: metatables, coroutines, goto, varargs and integer/float distinction (all numbers are
+// float64). This file holds the lexer and parser; lua_eval.go runs the syntax tree.
+
+// luaToken kinds
+const (
+	luaTokEOF = iota
+	luaTokName
+	luaTokNumber
+	luaTokString
+	luaTokKeyword
+	luaTokOp
+)
+
+var luaKeywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true, "end": true,
+	"false": true, "for": true, "function": true, "if": true, "in": true, "local": true,
+	"nil": true, "not": true, "or": true, "repeat": true, "return": true, "then": true,
+	"true": true, "until": true, "while": true,
+}
+
+// luaOps lists the operators, longest first so the lexer matches greedily
+var luaOps = []string{
+	"...", "..", "==", "~=", "<=", ">=", "//",
+	"+", "-", "*", "/", "%", "^", "#", "<", ">", "=", "(", ")", "{", "}", "[", "]", ";", ":", ",", ".",
+}
+
+type luaToken struct {
+	kind int
+	text string // Name, keyword or operator; decoded value for strings
+	num  float64
+	line int
+}
+
+// luaLexer splits a chunk into tokens
+type luaLexer struct {
+	chunk string // Chunk name used in error messages
+	src   string
+	pos   int
+	line  int
+}
+
+func (l *luaLexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", l.chunk, l.line, fmt.Sprintf(format, args...))
+}
+
+// tokens returns all tokens of the source, ending with luaTokEOF
+func (l *luaLexer) tokens() ([]luaToken, error) {
+	var tokens []luaToken
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == luaTokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *luaLexer) next() (luaToken, error) {
+	if err := l.skipSpace(); err != nil {
+		return luaToken{}, err
+	}
+	if l.pos >= len(l.src) {
+		return luaToken{kind: luaTokEOF, line: l.line}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case isLuaNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isLuaNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		word := l.src[start:l.pos]
+		if luaKeywords[word] {
+			return luaToken{kind: luaTokKeyword, text: word, line: l.line}, nil
+		}
+		return luaToken{kind: luaTokName, text: word, line: l.line}, nil
+
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		return l.number()
+
+	case c == '"' || c == '\'':
+		return l.quotedString(c)
+
+	case c == '[' && l.longBracketLevel() >= 0:
+		s, err := l.longString()
+		return luaToken{kind: luaTokString, text: s, line: l.line}, err
+	}
+
+	for _, op := range luaOps {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return luaToken{kind: luaTokOp, text: op, line: l.line}, nil
+		}
+	}
+	return luaToken{}, l.errorf("unexpected symbol %q", c)
+}
+
+// skipSpace skips whitespace and comments
+func (l *luaLexer) skipSpace() error {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "--"):
+			l.pos += 2
+			if l.pos < len(l.src) && l.src[l.pos] == '[' && l.longBracketLevel() >= 0 {
+				if _, err := l.longString(); err != nil {
+					return err
+				}
+				continue
+			}
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case l.line == 1 && l.pos == 0 && strings.HasPrefix(l.src, "#!"):
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// longBracketLevel returns the level of a long bracket ([[ or [==[) at the position, or -1
+func (l *luaLexer) longBracketLevel() int {
+	i := l.pos + 1
+	for i < len(l.src) && l.src[i] == '=' {
+		i++
+	}
+	if i < len(l.src) && l.src[i] == '[' {
+		return i - l.pos - 1
+	}
+	return -1
+}
+
+// longString reads a [[...]] string; a newline right after the opening bracket is skipped
+func (l *luaLexer) longString() (string, error) {
+	level := l.longBracketLevel()
+	l.pos += level + 2
+	if strings.HasPrefix(l.src[l.pos:], "\r\n") {
+		l.pos += 2
+		l.line++
+	} else if strings.HasPrefix(l.src[l.pos:], "\n") {
+		l.pos++
+		l.line++
+	}
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(l.src[l.pos:], closing)
+	if end < 0 {
+		return "", l.errorf("unfinished long string")
+	}
+	s := l.src[l.pos : l.pos+end]
+	l.line += strings.Count(s, "\n")
+	l.pos += end + len(closing)
+	return s, nil
+}
+
+func (l *luaLexer) quotedString(quote byte) (luaToken, error) {
+	line := l.line
+	l.pos++
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			return luaToken{}, l.errorf("unfinished string")
+		}
+		c := l.src[l.pos]
+		l.pos++
+		if c == quote {
+			return luaToken{kind: luaTokString, text: b.String(), line: line}, nil
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if l.pos >= len(l.src) {
+			return luaToken{}, l.errorf("unfinished string")
+		}
+		e := l.src[l.pos]
+		l.pos++
+		switch e {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '\\', '"', '\'':
+			b.WriteByte(e)
+		case '\n':
+			b.WriteByte('\n')
+			l.line++
+		default:
+			if !isDigit(e) {
+				return luaToken{}, l.errorf("invalid escape sequence '\\%c'", e)
+			}
+			start := l.pos - 1
+			for l.pos < len(l.src) && l.pos-start < 3 && isDigit(l.src[l.pos]) {
+				l.pos++
+			}
+			n, _ := strconv.Atoi(l.src[start:l.pos])
+			if n > 255 {
+				return luaToken{}, l.errorf("decimal escape too large")
+			}
+			b.WriteByte(byte(n))
+		}
+	}
+}
+
+func (l *luaLexer) number() (luaToken, error) {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], "0x") || strings.HasPrefix(l.src[l.pos:], "0X") {
+		l.pos += 2
+		for l.pos < len(l.src) && strings.IndexByte("0123456789abcdefABCDEF", l.src[l.pos]) >= 0 {
+			l.pos++
+		}
+		n, err := strconv.ParseUint(l.src[start+2:l.pos], 16, 64)
+		if err != nil {
+			return luaToken{}, l.errorf("malformed number near %q", l.src[start:l.pos])
+		}
+		return luaToken{kind: luaTokNumber, num: float64(n), line: l.line}, nil
+	}
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if isDigit(c) || c == '.' {
+			l.pos++
+		} else if (c == 'e' || c == 'E') && l.pos+1 < len(l.src) {
+			l.pos++
+			if l.src[l.pos] == '+' || l.src[l.pos] == '-' {
+				l.pos++
+			}
+		} else {
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(l.src[start:l.pos], 64)
+	if err != nil {
+		return luaToken{}, l.errorf("malformed number near %q", l.src[start:l.pos])
+	}
+	return luaToken{kind: luaTokNumber, num: n, line: l.line}, nil
+}
+
+func isLuaNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Syntax tree. Expressions and statements are the node structs below; the evaluator switches on
+// their types.
+
+type luaExpr any
+
+type luaStat any
+
+type luaBlock []luaStat
+
+type (
+	luaConstExpr struct{ value luaValue }
+	luaNameExpr  struct {
+		name string
+		line int
+	}
+	luaIndexExpr struct {
+		obj, key luaExpr
+		line     int
+	}
+	luaCallExpr struct {
+		fn     luaExpr
+		method string // Set for obj:method(...) calls
+		args   []luaExpr
+		line   int
+	}
+	luaFuncExpr struct {
+		name   string
+		params []string
+		body   luaBlock
+	}
+	luaTableExpr struct {
+		keys   []luaExpr // nil for positional items
+		values []luaExpr
+	}
+	luaBinExpr struct {
+		op          string
+		left, right luaExpr
+		line        int
+	}
+	luaUnaryExpr struct {
+		op   string
+		expr luaExpr
+		line int
+	}
+	luaParenExpr struct{ expr luaExpr } // Truncates multiple results to one
+)
+
+type (
+	luaLocalStat struct {
+		names []string
+		exprs []luaExpr
+	}
+	luaAssignStat struct {
+		targets []luaExpr
+		exprs   []luaExpr
+	}
+	luaCallStat struct{ call *luaCallExpr }
+	luaIfStat   struct {
+		conds  []luaExpr
+		blocks []luaBlock
+		orElse luaBlock
+	}
+	luaWhileStat struct {
+		cond luaExpr
+		body luaBlock
+	}
+	luaRepeatStat struct {
+		body luaBlock
+		cond luaExpr
+	}
+	luaNumForStat struct {
+		name              string
+		start, stop, step luaExpr
+		body              luaBlock
+		line              int
+	}
+	luaGenForStat struct {
+		names []string
+		exprs []luaExpr
+		body  luaBlock
+		line  int
+	}
+	luaDoStat        struct{ body luaBlock }
+	luaReturnStat    struct{ exprs []luaExpr }
+	luaBreakStat     struct{}
+	luaLocalFuncStat struct {
+		name string
+		fn   *luaFuncExpr
+	}
+)
+
+// luaParser builds the syntax tree from tokens
+type luaParser struct {
+	chunk  string
+	tokens []luaToken
+	pos    int
+}
+
+// parseLua parses a chunk of Lua source
+func parseLua(chunk, src string) (luaBlock, error) {
+	lexer := &luaLexer{chunk: chunk, src: src, line: 1}
+	tokens, err := lexer.tokens()
+	if err != nil {
+		return nil, err
+	}
+	p := &luaParser{chunk: chunk, tokens: tokens}
+	block, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != luaTokEOF {
+		return nil, p.unexpected(tok)
+	}
+	return block, nil
+}
+
+func (p *luaParser) peek() luaToken { return p.tokens[p.pos] }
+
+func (p *luaParser) advance() luaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != luaTokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// is reports whether the next token is the given keyword or operator
+func (p *luaParser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == luaTokKeyword || tok.kind == luaTokOp) && tok.text == text
+}
+
+func (p *luaParser) accept(text string) bool {
+	if p.is(text) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *luaParser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		return fmt.Errorf("%s:%d: '%s' expected near %s", p.chunk, tok.line, text, luaTokenText(tok))
+	}
+	return nil
+}
+
+func (p *luaParser) name() (string, error) {
+	tok := p.peek()
+	if tok.kind != luaTokName {
+		return "", fmt.Errorf("%s:%d: name expected near %s", p.chunk, tok.line, luaTokenText(tok))
+	}
+	p.advance()
+	return tok.text, nil
+}
+
+func (p *luaParser) unexpected(tok luaToken) error {
+	return fmt.Errorf("%s:%d: unexpected %s", p.chunk, tok.line, luaTokenText(tok))
+}
+
+func luaTokenText(tok luaToken) string {
+	switch tok.kind {
+	case luaTokEOF:
+		return "<eof>"
+	case luaTokString:
+		return strconv.Quote(tok.text)
+	case luaTokNumber:
+		return luaNumberString(tok.num)
+	}
+	return "'" + tok.text + "'"
+}
+
+// blockEnds reports whether the next token closes a block
+func (p *luaParser) blockEnds() bool {
+	tok := p.peek()
+	if tok.kind == luaTokEOF {
+		return true
+	}
+	return tok.kind == luaTokKeyword && (tok.text == "end" || tok.text == "else" || tok.text == "elseif" || tok.text == "until")
+}
+
+func (p *luaParser) block() (luaBlock, error) {
+	var block luaBlock
+	for !p.blockEnds() {
+		if p.accept(";") {
+			continue
+		}
+		if p.accept("return") {
+			ret := &luaReturnStat{}
+			if !p.blockEnds() && !p.is(";") {
+				exprs, err := p.exprList()
+				if err != nil {
+					return nil, err
+				}
+				ret.exprs = exprs
+			}
+			p.accept(";")
+			block = append(block, ret)
+			if !p.blockEnds() {
+				return nil, p.unexpected(p.peek())
+			}
+			break
+		}
+		stat, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		block = append(block, stat)
+	}
+	return block, nil
+}
+
+func (p *luaParser) statement() (luaStat, error) {
+	tok := p.peek()
+	line := tok.line
+	switch {
+	case p.accept("break"):
+		return &luaBreakStat{}, nil
+
+	case p.accept("do"):
+		body, err := p.blockUntil("end")
+		return &luaDoStat{body: body}, err
+
+	case p.accept("while"):
+		cond, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.blockUntil("end")
+		return &luaWhileStat{cond: cond, body: body}, err
+
+	case p.accept("repeat"):
+		body, err := p.blockUntil("until")
+		if err != nil {
+			return nil, err
+		}
+		cond, err := p.expr(0)
+		return &luaRepeatStat{body: body, cond: cond}, err
+
+	case p.accept("if"):
+		return p.ifStatement()
+
+	case p.accept("for"):
+		return p.forStatement(line)
+
+	case p.accept("function"):
+		target, name, method, err := p.funcName()
+		if err != nil {
+			return nil, err
+		}
+		fn, err := p.funcBody(name, method)
+		if err != nil {
+			return nil, err
+		}
+		return &luaAssignStat{targets: []luaExpr{target}, exprs: []luaExpr{fn}}, nil
+
+	case p.accept("local"):
+		if p.accept("function") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			fn, err := p.funcBody(name, false)
+			return &luaLocalFuncStat{name: name, fn: fn}, err
+		}
+		stat := &luaLocalStat{}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			stat.names = append(stat.names, name)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if p.accept("=") {
+			exprs, err := p.exprList()
+			if err != nil {
+				return nil, err
+			}
+			stat.exprs = exprs
+		}
+		return stat, nil
+	}
+
+	// Assignment or function call
+	expr, err := p.suffixedExpr()
+	if err != nil {
+		return nil, err
+	}
+	if call, ok := expr.(*luaCallExpr); ok && !p.is("=") && !p.is(",") {
+		return &luaCallStat{call: call}, nil
+	}
+	targets := []luaExpr{expr}
+	for p.accept(",") {
+		target, err := p.suffixedExpr()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	for _, target := range targets {
+		switch target.(type) {
+		case *luaNameExpr, *luaIndexExpr:
+		default:
+			return nil, fmt.Errorf("%s:%d: syntax error: cannot assign to expression", p.chunk, line)
+		}
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	exprs, err := p.exprList()
+	return &luaAssignStat{targets: targets, exprs: exprs}, err
+}
+
+// blockUntil parses a block closed by the given keyword
+func (p *luaParser) blockUntil(closing string) (luaBlock, error) {
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return body, p.expect(closing)
+}
+
+func (p *luaParser) ifStatement() (luaStat, error) {
+	stat := &luaIfStat{}
+	for {
+		cond, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		stat.conds = append(stat.conds, cond)
+		stat.blocks = append(stat.blocks, body)
+		if !p.accept("elseif") {
+			break
+		}
+	}
+	if p.accept("else") {
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		stat.orElse = body
+	}
+	return stat, p.expect("end")
+}
+
+func (p *luaParser) forStatement(line int) (luaStat, error) {
+	first, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("=") {
+		stat := &luaNumForStat{name: first, line: line}
+		if stat.start, err = p.expr(0); err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if stat.stop, err = p.expr(0); err != nil {
+			return nil, err
+		}
+		if p.accept(",") {
+			if stat.step, err = p.expr(0); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		stat.body, err = p.blockUntil("end")
+		return stat, err
+	}
+
+	stat := &luaGenForStat{names: []string{first}, line: line}
+	for p.accept(",") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		stat.names = append(stat.names, name)
+	}
+	if err := p.expect("in"); err != nil {
+		return nil, err
+	}
+	if stat.exprs, err = p.exprList(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("do"); err != nil {
+		return nil, err
+	}
+	stat.body, err = p.blockUntil("end")
+	return stat, err
+}
+
+// funcName parses the name of a function statement: a.b.c or a.b:c
+func (p *luaParser) funcName() (target luaExpr, name string, method bool, err error) {
+	line := p.peek().line
+	name, err = p.name()
+	if err != nil {
+		return nil, "", false, err
+	}
+	target = &luaNameExpr{name: name, line: line}
+	for p.is(".") || p.is(":") {
+		method = p.advance().text == ":"
+		key, err := p.name()
+		if err != nil {
+			return nil, "", false, err
+		}
+		name += "." + key
+		target = &luaIndexExpr{obj: target, key: &luaConstExpr{value: key}, line: line}
+		if method {
+			break
+		}
+	}
+	return target, name, method, nil
+}
+
+// funcBody parses the parameters and body of a function; methods get an implicit self
+func (p *luaParser) funcBody(name string, method bool) (*luaFuncExpr, error) {
+	fn := &luaFuncExpr{name: name}
+	if method {
+		fn.params = append(fn.params, "self")
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if !p.is(")") {
+		for {
+			param, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			fn.params = append(fn.params, param)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	body, err := p.blockUntil("end")
+	fn.body = body
+	return fn, err
+}
+
+func (p *luaParser) exprList() ([]luaExpr, error) {
+	var exprs []luaExpr
+	for {
+		expr, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.accept(",") {
+			return exprs, nil
+		}
+	}
+}
+
+// luaBinaryPriority holds the left and right priorities of the binary operators, as in the
+// reference implementation; right-associative operators have a lower right priority
+var luaBinaryPriority = map[string][2]int{
+	"or":  {1, 1},
+	"and": {2, 2},
+	"<":   {3, 3},
+	">":   {3, 3},
+	"<=":  {3, 3},
+	">=":  {3, 3},
+	"~=":  {3, 3},
+	"==":  {3, 3},
+	"..":  {9, 8},
+	"+":   {10, 10},
+	"-":   {10, 10},
+	"*":   {11, 11},
+	"/":   {11, 11},
+	"//":  {11, 11},
+	"%":   {11, 11},
+	"^":   {14, 13},
+}
+
+const luaUnaryPriority = 12
+
+// expr parses an expression whose binary operators bind tighter than limit
+func (p *luaParser) expr(limit int) (luaExpr, error) {
+	var left luaExpr
+	var err error
+	if tok := p.peek(); p.is("not") || p.is("-") || p.is("#") {
+		p.advance()
+		operand, err := p.expr(luaUnaryPriority)
+		if err != nil {
+			return nil, err
+		}
+		left = &luaUnaryExpr{op: tok.text, expr: operand, line: tok.line}
+	} else if left, err = p.simpleExpr(); err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		prio, ok := luaBinaryPriority[tok.text]
+		if !ok || (tok.kind != luaTokOp && tok.kind != luaTokKeyword) || prio[0] <= limit {
+			return left, nil
+		}
+		p.advance()
+		right, err := p.expr(prio[1])
+		if err != nil {
+			return nil, err
+		}
+		left = &luaBinExpr{op: tok.text, left: left, right: right, line: tok.line}
+	}
+}
+
+func (p *luaParser) simpleExpr() (luaExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == luaTokNumber:
+		p.advance()
+		return &luaConstExpr{value: tok.num}, nil
+	case tok.kind == luaTokString:
+		p.advance()
+		return &luaConstExpr{value: tok.text}, nil
+	case p.accept("nil"):
+		return &luaConstExpr{value: nil}, nil
+	case p.accept("true"):
+		return &luaConstExpr{value: true}, nil
+	case p.accept("false"):
+		return &luaConstExpr{value: false}, nil
+	case p.is("{"):
+		return p.tableConstructor()
+	case p.accept("function"):
+		return p.funcBody("anonymous", false)
+	case p.is("..."):
+		return nil, fmt.Errorf("%s:%d: varargs are not supported", p.chunk, tok.line)
+	}
+	return p.suffixedExpr()
+}
+
+// suffixedExpr parses a name or parenthesized expression followed by fields, indexes and calls
+func (p *luaParser) suffixedExpr() (luaExpr, error) {
+	tok := p.peek()
+	var expr luaExpr
+	switch {
+	case tok.kind == luaTokName:
+		p.advance()
+		expr = &luaNameExpr{name: tok.text, line: tok.line}
+	case p.accept("("):
+		inner, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		expr = &luaParenExpr{expr: inner}
+	default:
+		return nil, p.unexpected(tok)
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case p.accept("."):
+			key, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaIndexExpr{obj: expr, key: &luaConstExpr{value: key}, line: tok.line}
+		case p.accept("["):
+			key, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			expr = &luaIndexExpr{obj: expr, key: key, line: tok.line}
+		case p.accept(":"):
+			method, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			args, err := p.callArgs()
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaCallExpr{fn: expr, method: method, args: args, line: tok.line}
+		case p.is("(") || p.is("{") || tok.kind == luaTokString:
+			args, err := p.callArgs()
+			if err != nil {
+				return nil, err
+			}
+			expr = &luaCallExpr{fn: expr, args: args, line: tok.line}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// callArgs parses f(a, b), f{...} or f"string"
+func (p *luaParser) callArgs() ([]luaExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == luaTokString:
+		p.advance()
+		return []luaExpr{&luaConstExpr{value: tok.text}}, nil
+	case p.is("{"):
+		table, err := p.tableConstructor()
+		return []luaExpr{table}, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	if p.accept(")") {
+		return nil, nil
+	}
+	args, err := p.exprList()
+	if err != nil {
+		return nil, err
+	}
+	return args, p.expect(")")
+}
+
+func (p *luaParser) tableConstructor() (luaExpr, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	table := &luaTableExpr{}
+	for !p.is("}") {
+		var key luaExpr
+		switch {
+		case p.accept("["):
+			k, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			key = k
+		case p.peek().kind == luaTokName && p.tokens[p.pos+1].kind == luaTokOp && p.tokens[p.pos+1].text == "=":
+			key = &luaConstExpr{value: p.advance().text}
+			p.advance()
+		}
+		value, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		table.keys = append(table.keys, key)
+		table.values = append(table.values, value)
+		if !p.accept(",") && !p.accept(";") {
+			break
+		}
+	}
+	return table, p.expect("}")
+}
@@ -0,0 +1,744 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// luaValue is a Lua value: nil, bool, float64, string, *luaTable, *luaFunction or *luaGoFunction
+type luaValue any
+
+// luaTable is a Lua table. Sequences are stored under float64 keys like any other key.
+type luaTable struct {
+	items map[luaValue]luaValue
+}
+
+func newLuaTable() *luaTable {
+	return &luaTable{items: map[luaValue]luaValue{}}
+}
+
+func (t *luaTable) get(key luaValue) luaValue {
+	return t.items[key]
+}
+
+// set stores a value; assigning nil removes the key
+func (t *luaTable) set(key, value luaValue) {
+	if value == nil {
+		delete(t.items, key)
+		return
+	}
+	t.items[key] = value
+}
+
+// length returns the border of the sequence part (the # operator)
+func (t *luaTable) length() int {
+	n := 0
+	for t.items[float64(n+1)] != nil {
+		n++
+	}
+	return n
+}
+
+func (t *luaTable) append(value luaValue) {
+	t.set(float64(t.length()+1), value)
+}
+
+// keys returns the keys in a stable order: the sequence first, then the other keys sorted
+func (t *luaTable) keys() []luaValue {
+	n := t.length()
+	keys := make([]luaValue, 0, len(t.items))
+	var rest []luaValue
+	for i := 1; i <= n; i++ {
+		keys = append(keys, float64(i))
+	}
+	for key := range t.items {
+		if f, ok := key.(float64); ok && f >= 1 && f <= float64(n) && f == math.Trunc(f) {
+			continue
+		}
+		rest = append(rest, key)
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if luaTypeName(a) != luaTypeName(b) {
+			return luaTypeName(a) < luaTypeName(b)
+		}
+		if fa, ok := a.(float64); ok {
+			return fa < b.(float64)
+		}
+		return luaToString(a) < luaToString(b)
+	})
+	return append(keys, rest...)
+}
+
+// luaFunction is a closure defined in a script
+type luaFunction struct {
+	*luaFuncExpr
+	scope *luaScope
+}
+
+// luaGoFunction is a library function implemented in Go. It raises errors with luaInterp.raise.
+type luaGoFunction struct {
+	name string
+	fn   func(in *luaInterp, args []luaValue) []luaValue
+}
+
+// luaScope holds the local variables of a block
+type luaScope struct {
+	vars   map[string]*luaValue
+	parent *luaScope
+}
+
+func newLuaScope(parent *luaScope) *luaScope {
+	return &luaScope{vars: map[string]*luaValue{}, parent: parent}
+}
+
+func (s *luaScope) declare(name string, value luaValue) {
+	s.vars[name] = &value
+}
+
+func (s *luaScope) lookup(name string) *luaValue {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// luaError is a Lua error value raised by error() or a runtime error
+type luaError struct {
+	value luaValue
+	fatal bool // Limit errors, which pcall cannot catch
+}
+
+func (e *luaError) Error() string {
+	if s, ok := e.value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("(error object is a %s value)", luaTypeName(e.value))
+}
+
+// Limits that keep a misbehaving script from hanging or crashing relix. Hook scripts may come from
+// the repository, so a script must not be able to exhaust the memory of the releaser either.
+const (
+	luaMaxSteps       = 10_000_000
+	luaMaxCallDepth   = 200
+	luaMaxStringLen   = 1 << 24   // Bytes of a single string
+	luaMaxMemory      = 256 << 20 // Bytes of strings and table entries a script may allocate in total
+	luaMaxResults     = 1_000_000 // Values a single call may return, e.g. table.unpack
+	luaTableEntrySize = 64        // Bytes charged against luaMaxMemory for a table entry
+)
+
+// luaInterp runs Lua chunks. Scripts only see the libraries installed in globals, so they cannot
+// touch files, processes or the network except through the functions relix provides.
+type luaInterp struct {
+	chunk   string
+	globals *luaTable
+	print   func(line string)
+	steps   int
+	depth   int
+	memory  int // Bytes allocated so far (see alloc)
+	line    int // Line of the statement being executed, for error()
+}
+
+// Control flow results of executing a block
+const (
+	luaFlowNormal = iota
+	luaFlowBreak
+	luaFlowReturn
+)
+
+// newLuaInterp returns an interpreter with the standard library subset installed
+func newLuaInterp(chunk string, print func(line string)) *luaInterp {
+	in := &luaInterp{chunk: chunk, globals: newLuaTable(), print: print}
+	installLuaLibs(in)
+	return in
+}
+
+// raise aborts the script with an error at the given line
+func (in *luaInterp) raise(line int, format string, args ...any) {
+	panic(&luaError{value: fmt.Sprintf("%s:%d: %s", in.chunk, line, fmt.Sprintf(format, args...))})
+}
+
+// protect converts an error raised by the script into a Go error. A Go panic in the interpreter or
+// a library function fails the script rather than relix.
+func (in *luaInterp) protect(err *error) {
+	if r := recover(); r != nil {
+		lerr, ok := r.(*luaError)
+		if !ok {
+			*err = fmt.Errorf("%s: internal error: %v", in.chunk, r)
+			return
+		}
+		*err = lerr
+	}
+}
+
+// run parses and executes a chunk, defining its globals
+func (in *luaInterp) run(src string) (err error) {
+	block, err := parseLua(in.chunk, src)
+	if err != nil {
+		return err
+	}
+	defer in.protect(&err)
+	in.exec(block, newLuaScope(nil))
+	return nil
+}
+
+// callGlobal calls a global function; found is false if the script does not define it
+func (in *luaInterp) callGlobal(name string, args ...luaValue) (results []luaValue, found bool, err error) {
+	fn := in.globals.get(name)
+	if fn == nil {
+		return nil, false, nil
+	}
+	found = true
+	defer in.protect(&err)
+	return in.call(fn, args, 0, name), found, nil
+}
+
+// call calls a Lua or Go function
+func (in *luaInterp) call(fn luaValue, args []luaValue, line int, desc string) []luaValue {
+	in.depth++
+	defer func() { in.depth-- }()
+	if in.depth > luaMaxCallDepth {
+		in.raise(line, "stack overflow")
+	}
+
+	switch fn := fn.(type) {
+	case *luaGoFunction:
+		return fn.fn(in, args)
+	case *luaFunction:
+		scope := newLuaScope(fn.scope)
+		for i, param := range fn.params {
+			var v luaValue
+			if i < len(args) {
+				v = args[i]
+			}
+			scope.declare(param, v)
+		}
+		flow, results := in.exec(fn.body, scope)
+		if flow == luaFlowReturn {
+			return results
+		}
+		return nil
+	}
+	if desc != "" {
+		in.raise(line, "attempt to call a %s value (%s)", luaTypeName(fn), desc)
+	}
+	in.raise(line, "attempt to call a %s value", luaTypeName(fn))
+	return nil
+}
+
+func (in *luaInterp) step() {
+	in.steps++
+	if in.steps > luaMaxSteps {
+		panic(&luaError{value: fmt.Sprintf("%s: script exceeded %d steps", in.chunk, luaMaxSteps), fatal: true})
+	}
+}
+
+// alloc charges n bytes against the memory budget of the script
+func (in *luaInterp) alloc(n int) {
+	in.memory += n
+	if in.memory > luaMaxMemory {
+		panic(&luaError{value: fmt.Sprintf("%s: script exceeded %d bytes of memory", in.chunk, luaMaxMemory), fatal: true})
+	}
+}
+
+// allocString checks the length of a string about to be built and charges it against the memory budget
+func (in *luaInterp) allocString(line, n int) {
+	if n > luaMaxStringLen {
+		in.raise(line, "resulting string too large")
+	}
+	in.alloc(n)
+}
+
+// exec executes a block in the given scope
+func (in *luaInterp) exec(block luaBlock, s *luaScope) (int, []luaValue) {
+	for _, stat := range block {
+		in.step()
+		switch st := stat.(type) {
+		case *luaLocalStat:
+			values := in.evalList(st.exprs, s)
+			for i, name := range st.names {
+				var v luaValue
+				if i < len(values) {
+					v = values[i]
+				}
+				s.declare(name, v)
+			}
+
+		case *luaLocalFuncStat:
+			s.declare(st.name, nil)
+			*s.lookup(st.name) = &luaFunction{luaFuncExpr: st.fn, scope: s}
+
+		case *luaAssignStat:
+			values := in.evalList(st.exprs, s)
+			for i, target := range st.targets {
+				var v luaValue
+				if i < len(values) {
+					v = values[i]
+				}
+				in.assign(target, v, s)
+			}
+
+		case *luaCallStat:
+			in.evalCall(st.call, s)
+
+		case *luaIfStat:
+			matched := false
+			for i, cond := range st.conds {
+				if luaTruthy(in.eval(cond, s)) {
+					matched = true
+					if flow, results := in.exec(st.blocks[i], newLuaScope(s)); flow != luaFlowNormal {
+						return flow, results
+					}
+					break
+				}
+			}
+			if !matched && st.orElse != nil {
+				if flow, results := in.exec(st.orElse, newLuaScope(s)); flow != luaFlowNormal {
+					return flow, results
+				}
+			}
+
+		case *luaWhileStat:
+			for luaTruthy(in.eval(st.cond, s)) {
+				in.step()
+				flow, results := in.exec(st.body, newLuaScope(s))
+				if flow == luaFlowBreak {
+					break
+				}
+				if flow == luaFlowReturn {
+					return flow, results
+				}
+			}
+
+		case *luaRepeatStat:
+			for {
+				in.step()
+				scope := newLuaScope(s)
+				flow, results := in.exec(st.body, scope)
+				if flow == luaFlowBreak {
+					break
+				}
+				if flow == luaFlowReturn {
+					return flow, results
+				}
+				if luaTruthy(in.eval(st.cond, scope)) {
+					break
+				}
+			}
+
+		case *luaNumForStat:
+			start := in.forNumber(in.eval(st.start, s), "initial", st.line)
+			stop := in.forNumber(in.eval(st.stop, s), "limit", st.line)
+			step := 1.0
+			if st.step != nil {
+				step = in.forNumber(in.eval(st.step, s), "step", st.line)
+			}
+			if step == 0 {
+				in.raise(st.line, "'for' step is zero")
+			}
+			for v := start; (step > 0 && v <= stop) || (step < 0 && v >= stop); v += step {
+				in.step()
+				scope := newLuaScope(s)
+				scope.declare(st.name, v)
+				flow, results := in.exec(st.body, scope)
+				if flow == luaFlowBreak {
+					break
+				}
+				if flow == luaFlowReturn {
+					return flow, results
+				}
+			}
+
+		case *luaGenForStat:
+			values := in.evalList(st.exprs, s)
+			values = append(values, nil, nil, nil)
+			iter, state, control := values[0], values[1], values[2]
+			for {
+				in.step()
+				results := in.call(iter, []luaValue{state, control}, st.line, "for iterator")
+				if len(results) == 0 || results[0] == nil {
+					break
+				}
+				control = results[0]
+				scope := newLuaScope(s)
+				for i, name := range st.names {
+					var v luaValue
+					if i < len(results) {
+						v = results[i]
+					}
+					scope.declare(name, v)
+				}
+				flow, results := in.exec(st.body, scope)
+				if flow == luaFlowBreak {
+					break
+				}
+				if flow == luaFlowReturn {
+					return flow, results
+				}
+			}
+
+		case *luaDoStat:
+			if flow, results := in.exec(st.body, newLuaScope(s)); flow != luaFlowNormal {
+				return flow, results
+			}
+
+		case *luaReturnStat:
+			return luaFlowReturn, in.evalList(st.exprs, s)
+
+		case *luaBreakStat:
+			return luaFlowBreak, nil
+		}
+	}
+	return luaFlowNormal, nil
+}
+
+func (in *luaInterp) forNumber(v luaValue, what string, line int) float64 {
+	n, ok := luaToNumber(v)
+	if !ok {
+		in.raise(line, "'for' %s value must be a number", what)
+	}
+	return n
+}
+
+// assign stores a value in a variable or table field
+func (in *luaInterp) assign(target luaExpr, v luaValue, s *luaScope) {
+	switch t := target.(type) {
+	case *luaNameExpr:
+		if cell := s.lookup(t.name); cell != nil {
+			*cell = v
+		} else {
+			in.globals.set(t.name, v)
+		}
+	case *luaIndexExpr:
+		obj := in.eval(t.obj, s)
+		key := in.eval(t.key, s)
+		table, ok := obj.(*luaTable)
+		if !ok {
+			in.raise(t.line, "attempt to index a %s value%s", luaTypeName(obj), luaExprDesc(t.obj))
+		}
+		if key == nil {
+			in.raise(t.line, "table index is nil")
+		}
+		if f, ok := key.(float64); ok && math.IsNaN(f) {
+			in.raise(t.line, "table index is NaN")
+		}
+		if v != nil && table.get(key) == nil {
+			in.alloc(luaTableEntrySize)
+		}
+		table.set(key, v)
+	}
+}
+
+// evalList evaluates expressions; a call in the last position contributes all its results
+func (in *luaInterp) evalList(exprs []luaExpr, s *luaScope) []luaValue {
+	values := make([]luaValue, 0, len(exprs))
+	for i, e := range exprs {
+		if call, ok := e.(*luaCallExpr); ok && i == len(exprs)-1 {
+			values = append(values, in.evalCall(call, s)...)
+			continue
+		}
+		values = append(values, in.eval(e, s))
+	}
+	return values
+}
+
+func (in *luaInterp) evalCall(c *luaCallExpr, s *luaScope) []luaValue {
+	in.line = c.line
+	fn := in.eval(c.fn, s)
+	desc := luaExprName(c.fn)
+	var args []luaValue
+	if c.method != "" {
+		self := fn
+		fn = in.index(self, c.method, c.line, c.fn)
+		desc = "method '" + c.method + "'"
+		args = append(args, self)
+	}
+	args = append(args, in.evalList(c.args, s)...)
+	in.line = c.line
+	return in.call(fn, args, c.line, desc)
+}
+
+// index returns obj[key]; strings index the string library, so s:upper() works
+func (in *luaInterp) index(obj, key luaValue, line int, expr luaExpr) luaValue {
+	switch o := obj.(type) {
+	case *luaTable:
+		return o.get(key)
+	case string:
+		if lib, ok := in.globals.get("string").(*luaTable); ok {
+			return lib.get(key)
+		}
+	}
+	in.raise(line, "attempt to index a %s value%s", luaTypeName(obj), luaExprDesc(expr))
+	return nil
+}
+
+func (in *luaInterp) eval(e luaExpr, s *luaScope) luaValue {
+	switch e := e.(type) {
+	case *luaConstExpr:
+		return e.value
+
+	case *luaNameExpr:
+		if cell := s.lookup(e.name); cell != nil {
+			return *cell
+		}
+		return in.globals.get(e.name)
+
+	case *luaIndexExpr:
+		return in.index(in.eval(e.obj, s), in.eval(e.key, s), e.line, e.obj)
+
+	case *luaCallExpr:
+		if results := in.evalCall(e, s); len(results) > 0 {
+			return results[0]
+		}
+		return nil
+
+	case *luaParenExpr:
+		return in.eval(e.expr, s)
+
+	case *luaFuncExpr:
+		return &luaFunction{luaFuncExpr: e, scope: s}
+
+	case *luaTableExpr:
+		table := newLuaTable()
+		in.alloc(len(e.keys) * luaTableEntrySize)
+		n := 0
+		for i, keyExpr := range e.keys {
+			if keyExpr != nil {
+				key := in.eval(keyExpr, s)
+				if key == nil {
+					in.raise(in.line, "table index is nil")
+				}
+				table.set(key, in.eval(e.values[i], s))
+				continue
+			}
+			if call, ok := e.values[i].(*luaCallExpr); ok && i == len(e.keys)-1 {
+				values := in.evalCall(call, s)
+				in.alloc(max(len(values)-1, 0) * luaTableEntrySize)
+				for _, v := range values {
+					n++
+					table.set(float64(n), v)
+				}
+				continue
+			}
+			n++
+			table.set(float64(n), in.eval(e.values[i], s))
+		}
+		return table
+
+	case *luaUnaryExpr:
+		v := in.eval(e.expr, s)
+		switch e.op {
+		case "not":
+			return !luaTruthy(v)
+		case "-":
+			n, ok := luaToNumber(v)
+			if !ok {
+				in.raise(e.line, "attempt to perform arithmetic on a %s value%s", luaTypeName(v), luaExprDesc(e.expr))
+			}
+			return -n
+		case "#":
+			switch v := v.(type) {
+			case string:
+				return float64(len(v))
+			case *luaTable:
+				return float64(v.length())
+			}
+			in.raise(e.line, "attempt to get length of a %s value%s", luaTypeName(v), luaExprDesc(e.expr))
+		}
+
+	case *luaBinExpr:
+		switch e.op {
+		case "and":
+			if left := in.eval(e.left, s); !luaTruthy(left) {
+				return left
+			}
+			return in.eval(e.right, s)
+		case "or":
+			if left := in.eval(e.left, s); luaTruthy(left) {
+				return left
+			}
+			return in.eval(e.right, s)
+		}
+		return in.binary(e, in.eval(e.left, s), in.eval(e.right, s))
+	}
+	return nil
+}
+
+// binary applies an arithmetic, comparison or concatenation operator
+func (in *luaInterp) binary(e *luaBinExpr, a, b luaValue) luaValue {
+	switch e.op {
+	case "==":
+		return luaEquals(a, b)
+	case "~=":
+		return !luaEquals(a, b)
+	case "<", "<=", ">", ">=":
+		if e.op == ">" || e.op == ">=" {
+			a, b = b, a
+		}
+		strict := e.op == "<" || e.op == ">"
+		if x, ok := a.(float64); ok {
+			if y, ok := b.(float64); ok {
+				return x < y || (!strict && x == y)
+			}
+		}
+		if x, ok := a.(string); ok {
+			if y, ok := b.(string); ok {
+				return x < y || (!strict && x == y)
+			}
+		}
+		if luaTypeName(a) == luaTypeName(b) {
+			in.raise(e.line, "attempt to compare two %s values", luaTypeName(a))
+		}
+		in.raise(e.line, "attempt to compare %s with %s", luaTypeName(a), luaTypeName(b))
+	case "..":
+		x, okA := luaConcatString(a)
+		y, okB := luaConcatString(b)
+		if !okA {
+			in.raise(e.line, "attempt to concatenate a %s value%s", luaTypeName(a), luaExprDesc(e.left))
+		}
+		if !okB {
+			in.raise(e.line, "attempt to concatenate a %s value%s", luaTypeName(b), luaExprDesc(e.right))
+		}
+		in.allocString(e.line, len(x)+len(y))
+		return x + y
+	}
+
+	x, okA := luaToNumber(a)
+	y, okB := luaToNumber(b)
+	if !okA {
+		in.raise(e.line, "attempt to perform arithmetic on a %s value%s", luaTypeName(a), luaExprDesc(e.left))
+	}
+	if !okB {
+		in.raise(e.line, "attempt to perform arithmetic on a %s value%s", luaTypeName(b), luaExprDesc(e.right))
+	}
+	switch e.op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		return x / y
+	case "//":
+		return math.Floor(x / y)
+	case "%":
+		return x - math.Floor(x/y)*y
+	case "^":
+		return math.Pow(x, y)
+	}
+	return nil
+}
+
+// luaExprName names a variable or field for error messages, e.g. "field 'x'"
+func luaExprName(e luaExpr) string {
+	switch e := e.(type) {
+	case *luaNameExpr:
+		return fmt.Sprintf("variable '%s'", e.name)
+	case *luaIndexExpr:
+		if c, ok := e.key.(*luaConstExpr); ok {
+			if name, ok := c.value.(string); ok {
+				return fmt.Sprintf("field '%s'", name)
+			}
+		}
+	}
+	return ""
+}
+
+// luaExprDesc is luaExprName as a suffix for error messages, e.g. " (field 'x')"
+func luaExprDesc(e luaExpr) string {
+	if name := luaExprName(e); name != "" {
+		return " (" + name + ")"
+	}
+	return ""
+}
+
+func luaTruthy(v luaValue) bool {
+	return v != nil && v != false
+}
+
+// luaEquals compares values; tables and functions are equal only to themselves
+func luaEquals(a, b luaValue) bool {
+	return a == b
+}
+
+func luaTypeName(v luaValue) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *luaTable:
+		return "table"
+	case *luaFunction, *luaGoFunction:
+		return "function"
+	}
+	return "userdata"
+}
+
+// luaToNumber converts numbers and numeric strings
+func luaToNumber(v luaValue) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		s := strings.TrimSpace(v)
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			n, err := strconv.ParseUint(s[2:], 16, 64)
+			return float64(n), err == nil
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		return n, err == nil && !strings.ContainsAny(s, "iInN") // No inf/nan literals
+	}
+	return 0, false
+}
+
+func luaConcatString(v luaValue) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return luaNumberString(v), true
+	}
+	return "", false
+}
+
+// luaNumberString formats a number like Lua: integral values without a fraction
+func luaNumberString(n float64) string {
+	if n == math.Trunc(n) && math.Abs(n) < 1e15 {
+		return strconv.FormatInt(int64(n), 10)
+	}
+	if math.IsInf(n, 0) {
+		if n > 0 {
+			return "inf"
+		}
+		return "-inf"
+	}
+	if math.IsNaN(n) {
+		return "nan"
+	}
+	return strconv.FormatFloat(n, 'g', 14, 64)
+}
+
+func luaToString(v luaValue) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return luaNumberString(v)
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%s: %p", luaTypeName(v), v)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// runLuaTest runs a chunk named "test" and returns the lines it printed
+func runLuaTest(src string) (string, error) {
+	var lines []string
+	in := newLuaInterp("test", func(line string) { lines = append(lines, line) })
+	err := in.run(src)
+	return strings.Join(lines, "\n"), err
+}
+
+func TestLuaEval(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"arithmetic", `print(1 + 2 * 3, 7 // 2, -7 // 2, 7 % 3, -7 % 3, 2 ^ 10, 1 / 4)`, "7\t3\t-4\t1\t2\t1024\t0.25"},
+		{"number formatting", `print(10 / 2, 1 / 3, 1 / 0, -1 / 0, 1e15, 0x10)`, "5\t0.33333333333333\tinf\t-inf\t1e+15\t16"},
+		{"string coercion", `print("10" + 1, "3" * "4", 1 .. 2, "0x10" + 0)`, "11\t12\t12\t16"},
+		{"comparison", `print(1 < 2, "a" < "b", 2 <= 2, 3 > 4, 1 == 1.0, "1" == 1, {} == {})`, "true\ttrue\ttrue\tfalse\ttrue\tfalse\tfalse"},
+		{"logic", `print(nil or "x", false and error("skipped"), 0 and "zero is true", not nil, not 0)`, "x\tfalse\tzero is true\ttrue\tfalse"},
+		{"length", `print(#"abc", #{1, 2, 3}, #{})`, "3\t3\t0"},
+		{"locals shadow globals", `
+x = 1
+local x = 2
+do local x = 3 end
+print(x, _G)`, "2\tnil"},
+		{"multiple assignment", `
+local a, b, c = 1, 2
+a, b = b, a
+print(a, b, c)`, "2\t1\tnil"},
+		{"multiple results", `
+local function two() return 1, 2 end
+local t = {two(), two()}
+print(#t, (two()))
+local a, b, c = two(), 10
+print(a, b, c)`, "3\t1\n1\t10\tnil"},
+		{"closures", `
+local function counter()
+  local n = 0
+  return function() n = n + 1 return n end
+end
+local c1, c2 = counter(), counter()
+c1() c1()
+print(c1(), c2())`, "3\t1"},
+		{"recursion", `
+local function fib(n) if n < 2 then return n end return fib(n - 1) + fib(n - 2) end
+print(fib(20))`, "6765"},
+		{"if chain", `
+for _, n in ipairs({1, 5, 10}) do
+  if n < 3 then print("small") elseif n < 7 then print("medium") else print("large") end
+end`, "small\nmedium\nlarge"},
+		{"numeric for", `
+local s = ""
+for i = 1, 3 do s = s .. i end
+for i = 10, 1, -4 do s = s .. "," .. i end
+for i = 1, 0 do s = s .. "never" end
+print(s)`, "123,10,6,2"},
+		{"while and repeat", `
+local i, j = 0, 0
+while true do i = i + 1 if i == 5 then break end end
+repeat local k = j j = j + 1 until k >= 2
+print(i, j)`, "5\t3"},
+		{"tables", `
+local t = {1, 2, x = "a", ["y z"] = "b", [10] = "c"}
+t.x = t.x .. "!"
+t[#t + 1] = 3
+print(t[1], t.x, t["y z"], t[10], #t, t.missing)`, "1\ta!\tb\tc\t3\tnil"},
+		{"pairs in a stable order", `
+local t = {}
+t.b, t.a, t[5], t[1] = 1, 2, 3, 4
+local keys = {}
+for k in pairs(t) do keys[#keys + 1] = tostring(k) end
+print(table.concat(keys, ","))`, "1,5,a,b"},
+		{"removing fields while iterating", `
+local t = {a = 1, b = 2, c = 3}
+local n = 0
+for k in pairs(t) do t.b = nil n = n + 1 end
+print(n, t.b)`, "2\tnil"},
+		{"methods", `
+local account = {balance = 10}
+function account:deposit(n) self.balance = self.balance + n return self end
+account:deposit(5):deposit(1)
+print(account.balance)`, "16"},
+		{"pcall and error", `
+print(pcall(error, "plain", 0))
+local ok, e = pcall(error, {code = 1})
+print(ok, type(e), e.code)
+print(pcall(function() error("at line") end))
+print(pcall(function() local x = nil; return x.field end))
+print(pcall(function(a, b) return a + b end, 1, 2))`, "false\tplain\nfalse\ttable\t1\nfalse\ttest:5: at line\nfalse\ttest:6: attempt to index a nil value (variable 'x')\ntrue\t3"},
+		{"assert", `
+print(assert(1, "unused"))
+print(pcall(assert, false, "boom"))
+print(pcall(assert, nil))`, "1\tunused\nfalse\tboom\nfalse\ttest:4: assertion failed!"},
+		{"only the last call expands", `
+local function two() return 1, 2 end
+print(two(), two())`, "1\t1\t2"},
+		{"type and tonumber", `print(type(nil), type(1), type("s"), type({}), type(print), tonumber("ff", 16), tonumber("x"), tonumber(" 12 "))`, "nil\tnumber\tstring\ttable\tfunction\t255\tnil\t12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runLuaTest(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("printed\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuaLibraries(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"string.format", `print(string.format("%d|%5.2f|%-3s|%q|%x|%%", 42, 3.14159, "a", "hi\n", 255))`, `42| 3.14|a  |"hi\n"|ff|%`},
+		{"string.find", `
+print(string.find("hello world", "o w"))
+print(string.find("a.b", ".", 1, true))
+print(string.find("abc", "x"))`, "5\t7\n2\t2\nnil"},
+		{"string.match", `
+print(string.match("key = value", "(%w+)%s*=%s*(%w+)"))
+print(("v1.2.3"):match("%d+%.%d+"))`, "key\tvalue\n1.2"},
+		{"string.gmatch", `
+local words = {}
+for w in ("one two  three"):gmatch("%a+") do words[#words + 1] = w end
+print(table.concat(words, "|"))`, "one|two|three"},
+		{"string.gsub", `
+print(string.gsub("hello world", "o", "0"))
+print(("a-b-c"):gsub("-", "+", 1))
+print(("name=relix"):gsub("(%w+)=(%w+)", "%2=%1"))
+print(("$x and $y"):gsub("%$(%w+)", {x = "1"}))
+print(("abc"):gsub("%w", function(c) return c:upper() .. "." end))`, "hell0 w0rld\t2\na+b-c\t1\nrelix=name\t1\n1 and $y\t2\nA.B.C.\t3"},
+		{"string.sub", `local s = "release" print(s:sub(2, 4), s:sub(-3), s:sub(3), s:sub(0), s:sub(5, 2) == "")`, "ele\tase\tlease\trelease\ttrue"},
+		{"string helpers", `print(("ab"):rep(3, ","), ("abc"):reverse(), ("MiX"):lower(), ("MiX"):upper(), #("abc"), ("  x  "):trim())`, "ab,ab,ab\tcba\tmix\tMIX\t3\tx"},
+		{"string.byte and char", `print(string.char(72, 105), string.byte("AB", 1, 2))`, "Hi\t65\t66"},
+		{"string.split", `local parts = ("a,b,,c"):split() print(#parts, parts[3] == "", table.concat(("x y"):split(" "), "+"))`, "4\ttrue\tx+y"},
+		{"table.insert and remove", `
+local t = {1, 2, 3}
+table.insert(t, 4)
+table.insert(t, 1, 0)
+print(table.concat(t, ","), table.remove(t), table.remove(t, 1), table.concat(t, ","))`, "0,1,2,3,4\t4\t0\t1,2,3"},
+		{"table.sort", `
+local t = {5, 2, 8, 1}
+table.sort(t)
+local names = {"b", "c", "a"}
+table.sort(names, function(a, b) return a > b end)
+print(table.concat(t, " "), table.concat(names, " "))`, "1 2 5 8\tc b a"},
+		{"table.concat range", `print(table.concat({1, 2, 3, 4}, "-", 2, 3), table.concat({}, ","))`, "2-3\t"},
+		{"table.unpack", `print(table.unpack({1, 2, 3})) print(table.unpack({1, 2, 3}, 2))`, "1\t2\t3\n2\t3"},
+		{"math", `print(math.abs(-2), math.floor(2.7), math.ceil(2.1), math.sqrt(16), math.max(3, 9, 1), math.min(3, 9, 1), math.huge, math.floor(math.pi * 100))`, "2\t2\t3\t4\t9\t1\tinf\t314"},
+		{"os.time", `print(type(os.time()), os.date("%Y", 0) == "1970")`, "number\ttrue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runLuaTest(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("printed\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLuaRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"local t = {}\nreturn t.count + 1", "test:2: attempt to perform arithmetic on a nil value (field 'count')"},
+		{"missing()", "test:1: attempt to call a nil value (variable 'missing')"},
+		{"local t = {}\nt.run()", "test:2: attempt to call a nil value (field 'run')"},
+		{"return 1 < 'x'", "test:1: attempt to compare number with string"},
+		{"return {} < {}", "test:1: attempt to compare two table values"},
+		{"return 'a' .. {}", "test:1: attempt to concatenate a table value"},
+		{"local s = nil\nreturn #s", "test:2: attempt to get length of a nil value (variable 's')"},
+		{"error('failed')", "test:1: failed"},
+		{"error({})", "(error object is a table value)"},
+		{"string.rep()", "test:1: bad argument #1 to 'rep' (string expected, got nil)"},
+	}
+	for _, tt := range tests {
+		_, err := runLuaTest(tt.src)
+		if err == nil {
+			t.Errorf("%q: no error, want %s", tt.src, tt.want)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestLuaLimits(t *testing.T) {
+	_, err := runLuaTest("local ok = pcall(function() while true do end end)\nprint(ok)")
+	if want := fmt.Sprintf("test: script exceeded %d steps", luaMaxSteps); err == nil || err.Error() != want {
+		t.Errorf("an endless loop: error %v, want %s", err, want)
+	}
+
+	out, err := runLuaTest("local function f() return f() + 1 end\nprint(pcall(f))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "false\t") || !strings.HasSuffix(out, "stack overflow") {
+		t.Errorf("unbounded recursion printed %q, want a caught stack overflow", out)
+	}
+}
+
+func TestLuaMemoryLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"repeating past the int range", `string.rep("ab", 2^62)`, "test:1: resulting string too large"},
+		{"repeating past the string size", `string.rep("x", 2^24 + 1)`, "test:1: resulting string too large"},
+		{"repeating with a separator", `string.rep("x", 2^23 + 1, ",")`, "test:1: resulting string too large"},
+		{"doubling a string", `local s = "x" for i = 1, 40 do s = s .. s end`, "test:1: resulting string too large"},
+		{"expanding a replacement", `string.rep("x", 2^20):gsub("x", string.rep("%0", 100))`, "test:1: resulting string too large"},
+		{"joining a table", `local t = {} for i = 1, 20 do t[i] = string.rep("x", 2^20) end table.concat(t)`, "test:1: resulting string too large"},
+		{"padding a number", `string.format("%999999999d", 1)`, "test:1: invalid conversion '%999' to 'format'"},
+		{"unpacking a huge range", `table.unpack({}, 1, 2^40)`, "test:1: too many results to unpack"},
+		{"unpacking the int range", `table.unpack({}, -2^63, 2^63 - 1024)`, "test:1: too many results to unpack"},
+		{"slicing bytes", `string.byte(string.rep("x", 2^21), 1, -1)`, "test:1: string slice too long"},
+		{"an infinite count", `string.rep("x", math.huge)`, "test:1: bad argument #2 to 'rep' (number has no integer representation)"},
+		{"a count past the int range", `string.sub("abc", 2^63)`, "test:1: bad argument #2 to 'sub' (number has no integer representation)"},
+		{"a NaN count", `string.rep("x", 0/0)`, "test:1: bad argument #2 to 'rep' (number has no integer representation)"},
+		{"growing tables", `local t = {} for i = 1, 1e7 do t[i] = {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15} end`, fmt.Sprintf("test: script exceeded %d bytes of memory", luaMaxMemory)},
+		{"allocating under pcall", `
+local s = string.rep("x", 2^24)
+while true do pcall(function() return s .. "" end) end`, fmt.Sprintf("test: script exceeded %d bytes of memory", luaMaxMemory)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runLuaTest(tt.src)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("error %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	// Size errors are ordinary errors, which pcall catches
+	out, err := runLuaTest(`print(pcall(string.rep, "ab", 2^62))`)
+	if err != nil || out != "false\ttest:1: resulting string too large" {
+		t.Errorf("pcall printed %q, err %v", out, err)
+	}
+}
+
+func TestLuaGoPanic(t *testing.T) {
+	in := newLuaInterp("test", func(string) {})
+	in.globals.set("broken", &luaGoFunction{name: "broken", fn: func(*luaInterp, []luaValue) []luaValue {
+		var values []luaValue
+		return values[:1]
+	}})
+	err := in.run("pcall(broken)")
+	if err == nil || !strings.HasPrefix(err.Error(), "test: internal error: runtime error: slice bounds out of range") {
+		t.Errorf("error %v, want an internal error", err)
+	}
+}
+
+func TestLuaCallGlobal(t *testing.T) {
+	in := newLuaInterp("test", func(string) {})
+	if err := in.run(`function greet(name, n) return "hi " .. name, n * 2 end`); err != nil {
+		t.Fatal(err)
+	}
+	results, found, err := in.callGlobal("greet", "relix", 21.0)
+	if err != nil || !found {
+		t.Fatalf("greet: found %v, err %v", found, err)
+	}
+	if len(results) != 2 || results[0] != "hi relix" || results[1] != 42.0 {
+		t.Errorf("greet returned %v", results)
+	}
+
+	if _, found, err := in.callGlobal("missing"); found || err != nil {
+		t.Errorf("missing: found %v, err %v, want not found", found, err)
+	}
+
+	if err := in.run(`function broken() return nil .. "x" end`); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := in.callGlobal("broken"); err == nil || !strings.Contains(err.Error(), "attempt to concatenate a nil value") {
+		t.Errorf("broken: err %v", err)
+	}
+}
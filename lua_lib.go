@@ -0,0 +1,874 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The library subset available to hook scripts: the basic functions and the string, table, math
+// and os.date/os.time functions of Lua. There is no io, load, require or os.execute. Patterns use
+// Lua syntax without %b, %f and back-references; they are translated to Go regular expressions.
+
+// installLuaLibs registers the libraries in the interpreter's globals
+func installLuaLibs(in *luaInterp) {
+	for name, fn := range map[string]func(*luaInterp, []luaValue) []luaValue{
+		"assert":   luaAssert,
+		"error":    luaErrorFn,
+		"ipairs":   luaIpairs,
+		"next":     luaNext,
+		"pairs":    luaPairs,
+		"pcall":    luaPcall,
+		"print":    luaPrint,
+		"tonumber": luaTonumber,
+		"tostring": func(in *luaInterp, args []luaValue) []luaValue { return []luaValue{luaToString(luaArg(args, 0))} },
+		"type":     luaType,
+	} {
+		in.globals.set(name, &luaGoFunction{name: name, fn: fn})
+	}
+
+	in.globals.set("string", luaLib("string", map[string]func(*luaInterp, []luaValue) []luaValue{
+		"byte":   luaStringByte,
+		"char":   luaStringChar,
+		"find":   luaStringFind,
+		"format": luaStringFormat,
+		"gmatch": luaStringGmatch,
+		"gsub":   luaStringGsub,
+		"len":    luaStringLen,
+		"lower": func(in *luaInterp, args []luaValue) []luaValue {
+			s := in.checkString(args, 0, "lower")
+			in.alloc(len(s))
+			return []luaValue{strings.ToLower(s)}
+		},
+		"match":   luaStringMatch,
+		"rep":     luaStringRep,
+		"reverse": luaStringReverse,
+		"split":   luaStringSplit,
+		"sub":     luaStringSub,
+		"trim": func(in *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{strings.TrimSpace(in.checkString(args, 0, "trim"))}
+		},
+		"upper": func(in *luaInterp, args []luaValue) []luaValue {
+			s := in.checkString(args, 0, "upper")
+			in.alloc(len(s))
+			return []luaValue{strings.ToUpper(s)}
+		},
+	}))
+
+	in.globals.set("table", luaLib("table", map[string]func(*luaInterp, []luaValue) []luaValue{
+		"concat": luaTableConcat,
+		"insert": luaTableInsert,
+		"remove": luaTableRemove,
+		"sort":   luaTableSort,
+		"unpack": luaTableUnpack,
+	}))
+
+	mathLib := luaLib("math", map[string]func(*luaInterp, []luaValue) []luaValue{
+		"abs":   luaMathFn("abs", math.Abs),
+		"ceil":  luaMathFn("ceil", math.Ceil),
+		"floor": luaMathFn("floor", math.Floor),
+		"sqrt":  luaMathFn("sqrt", math.Sqrt),
+		"max":   luaMathMax,
+		"min":   luaMathMin,
+	})
+	mathLib.set("huge", math.Inf(1))
+	mathLib.set("pi", math.Pi)
+	in.globals.set("math", mathLib)
+
+	in.globals.set("os", luaLib("os", map[string]func(*luaInterp, []luaValue) []luaValue{
+		"date": luaOSDate,
+		"time": func(in *luaInterp, args []luaValue) []luaValue { return []luaValue{float64(time.Now().Unix())} },
+	}))
+}
+
+// luaLib builds a library table
+func luaLib(name string, funcs map[string]func(*luaInterp, []luaValue) []luaValue) *luaTable {
+	lib := newLuaTable()
+	for fnName, fn := range funcs {
+		lib.set(fnName, &luaGoFunction{name: name + "." + fnName, fn: fn})
+	}
+	return lib
+}
+
+// Argument helpers. Indexes are 0-based; messages use Lua's 1-based argument numbers.
+
+func luaArg(args []luaValue, i int) luaValue {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+func (in *luaInterp) argError(i int, fn, msg string) {
+	in.raise(in.line, "bad argument #%d to '%s' (%s)", i+1, fn, msg)
+}
+
+func (in *luaInterp) checkString(args []luaValue, i int, fn string) string {
+	switch v := luaArg(args, i).(type) {
+	case string:
+		return v
+	case float64:
+		return luaNumberString(v)
+	}
+	in.argError(i, fn, "string expected, got "+luaTypeName(luaArg(args, i)))
+	return ""
+}
+
+func (in *luaInterp) checkNumber(args []luaValue, i int, fn string) float64 {
+	n, ok := luaToNumber(luaArg(args, i))
+	if !ok {
+		in.argError(i, fn, "number expected, got "+luaTypeName(luaArg(args, i)))
+	}
+	return n
+}
+
+func (in *luaInterp) checkInt(args []luaValue, i int, fn string) int {
+	n := in.checkNumber(args, i, fn)
+	// Converting a float outside the int range is undefined, e.g. math.huge or 2^63
+	if n != math.Trunc(n) || n < math.MinInt64 || n >= -math.MinInt64 {
+		in.argError(i, fn, "number has no integer representation")
+	}
+	return int(n)
+}
+
+func (in *luaInterp) optInt(args []luaValue, i int, fn string, def int) int {
+	if luaArg(args, i) == nil {
+		return def
+	}
+	return in.checkInt(args, i, fn)
+}
+
+func (in *luaInterp) checkTable(args []luaValue, i int, fn string) *luaTable {
+	t, ok := luaArg(args, i).(*luaTable)
+	if !ok {
+		in.argError(i, fn, "table expected, got "+luaTypeName(luaArg(args, i)))
+	}
+	return t
+}
+
+// Basic functions
+
+func luaAssert(in *luaInterp, args []luaValue) []luaValue {
+	if !luaTruthy(luaArg(args, 0)) {
+		if msg := luaArg(args, 1); msg != nil {
+			panic(&luaError{value: msg})
+		}
+		in.raise(in.line, "assertion failed!")
+	}
+	return args
+}
+
+func luaErrorFn(in *luaInterp, args []luaValue) []luaValue {
+	value := luaArg(args, 0)
+	if msg, ok := value.(string); ok && in.optInt(args, 1, "error", 1) > 0 {
+		value = fmt.Sprintf("%s:%d: %s", in.chunk, in.line, msg)
+	}
+	panic(&luaError{value: value})
+}
+
+func luaIpairs(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "ipairs")
+	iter := &luaGoFunction{name: "ipairs_iterator", fn: func(in *luaInterp, args []luaValue) []luaValue {
+		i := in.checkInt(args, 1, "ipairs_iterator") + 1
+		v := t.get(float64(i))
+		if v == nil {
+			return []luaValue{nil}
+		}
+		return []luaValue{float64(i), v}
+	}}
+	return []luaValue{iter, t, 0.0}
+}
+
+func luaNext(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "next")
+	keys := t.keys()
+	i := 0
+	if key := luaArg(args, 1); key != nil {
+		for i < len(keys) && keys[i] != key {
+			i++
+		}
+		i++
+	}
+	if i >= len(keys) {
+		return []luaValue{nil}
+	}
+	return []luaValue{keys[i], t.get(keys[i])}
+}
+
+func luaPairs(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "pairs")
+	keys := t.keys()
+	i := 0
+	iter := &luaGoFunction{name: "pairs_iterator", fn: func(in *luaInterp, args []luaValue) []luaValue {
+		for i < len(keys) {
+			key := keys[i]
+			i++
+			if v := t.get(key); v != nil { // Skip fields removed during the loop
+				return []luaValue{key, v}
+			}
+		}
+		return []luaValue{nil}
+	}}
+	return []luaValue{iter, t, nil}
+}
+
+func luaPcall(in *luaInterp, args []luaValue) (results []luaValue) {
+	defer func() {
+		if r := recover(); r != nil {
+			lerr, ok := r.(*luaError)
+			if !ok || lerr.fatal {
+				panic(r)
+			}
+			results = []luaValue{false, lerr.value}
+		}
+	}()
+	if len(args) == 0 {
+		in.argError(0, "pcall", "value expected")
+	}
+	return append([]luaValue{true}, in.call(args[0], args[1:], in.line, "")...)
+}
+
+func luaPrint(in *luaInterp, args []luaValue) []luaValue {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = luaToString(arg)
+	}
+	if in.print != nil {
+		in.print(strings.Join(parts, "\t"))
+	}
+	return nil
+}
+
+func luaTonumber(in *luaInterp, args []luaValue) []luaValue {
+	if luaArg(args, 1) != nil {
+		n, err := strconv.ParseInt(strings.TrimSpace(in.checkString(args, 0, "tonumber")), in.checkInt(args, 1, "tonumber"), 64)
+		if err != nil {
+			return []luaValue{nil}
+		}
+		return []luaValue{float64(n)}
+	}
+	if n, ok := luaToNumber(luaArg(args, 0)); ok {
+		return []luaValue{n}
+	}
+	return []luaValue{nil}
+}
+
+func luaType(in *luaInterp, args []luaValue) []luaValue {
+	if len(args) == 0 {
+		in.argError(0, "type", "value expected")
+	}
+	return []luaValue{luaTypeName(args[0])}
+}
+
+// String library
+
+// luaStringRange converts Lua's 1-based, possibly negative string positions to a Go slice range
+func luaStringRange(i, j, length int) (int, int) {
+	if i < 0 {
+		i = max(length+i+1, 1)
+	} else if i == 0 {
+		i = 1
+	}
+	if j < 0 {
+		j = length + j + 1
+	} else if j > length {
+		j = length
+	}
+	if i > j {
+		return 0, 0
+	}
+	return i - 1, j
+}
+
+func luaStringLen(in *luaInterp, args []luaValue) []luaValue {
+	return []luaValue{float64(len(in.checkString(args, 0, "len")))}
+}
+
+func luaStringSub(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "sub")
+	start, end := luaStringRange(in.optInt(args, 1, "sub", 1), in.optInt(args, 2, "sub", -1), len(s))
+	return []luaValue{s[start:end]}
+}
+
+func luaStringRep(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "rep")
+	n := in.checkInt(args, 1, "rep")
+	sep := ""
+	if luaArg(args, 2) != nil {
+		sep = in.checkString(args, 2, "rep")
+	}
+	if n <= 0 {
+		return []luaValue{""}
+	}
+	// n copies of s with n-1 separators; dividing keeps a huge n from overflowing the length
+	unit := len(s) + len(sep)
+	if unit > 0 && n > (luaMaxStringLen+len(sep))/unit {
+		in.raise(in.line, "resulting string too large")
+	}
+	in.allocString(in.line, n*unit-len(sep))
+	return []luaValue{strings.Repeat(s+sep, n-1) + s}
+}
+
+func luaStringReverse(in *luaInterp, args []luaValue) []luaValue {
+	b := []byte(in.checkString(args, 0, "reverse"))
+	in.alloc(len(b))
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return []luaValue{string(b)}
+}
+
+func luaStringByte(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "byte")
+	i := in.optInt(args, 1, "byte", 1)
+	start, end := luaStringRange(i, in.optInt(args, 2, "byte", i), len(s))
+	if end-start > luaMaxResults {
+		in.raise(in.line, "string slice too long")
+	}
+	var result []luaValue
+	for _, c := range []byte(s[start:end]) {
+		result = append(result, float64(c))
+	}
+	return result
+}
+
+func luaStringChar(in *luaInterp, args []luaValue) []luaValue {
+	b := make([]byte, len(args))
+	for i := range args {
+		c := in.checkInt(args, i, "char")
+		if c < 0 || c > 255 {
+			in.argError(i, "char", "value out of range")
+		}
+		b[i] = byte(c)
+	}
+	return []luaValue{string(b)}
+}
+
+func luaStringSplit(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "split")
+	sep := ","
+	if luaArg(args, 1) != nil {
+		sep = in.checkString(args, 1, "split")
+	}
+	parts := len(s)
+	if sep != "" {
+		parts = strings.Count(s, sep) + 1
+	}
+	in.alloc(parts * luaTableEntrySize)
+	result := newLuaTable()
+	for _, part := range strings.Split(s, sep) {
+		result.append(part)
+	}
+	return []luaValue{result}
+}
+
+// luaPatternClasses maps Lua character classes to POSIX class names
+var luaPatternClasses = map[byte]string{
+	'a': "alpha", 'c': "cntrl", 'd': "digit", 'g': "graph", 'l': "lower", 'p': "punct",
+	's': "space", 'u': "upper", 'w': "alnum", 'x': "xdigit",
+}
+
+// luaPatternRegexp translates a Lua pattern to an equivalent Go regular expression
+func luaPatternRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	b.WriteString("(?s)")
+	inSet := false
+	single := false // The last item matches a single character, so a quantifier may follow
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '%':
+			i++
+			if i >= len(pattern) {
+				return "", fmt.Errorf("malformed pattern (ends with '%%')")
+			}
+			e := pattern[i]
+			lower := e | 0x20
+			if name, ok := luaPatternClasses[lower]; ok && e >= 'A' {
+				negate := ""
+				if e != lower {
+					negate = "^"
+				}
+				if inSet {
+					b.WriteString("[:" + negate + name + ":]")
+				} else {
+					b.WriteString("[[:" + negate + name + ":]]")
+				}
+			} else if e == 'b' || e == 'f' || isDigit(e) {
+				return "", fmt.Errorf("pattern item '%%%c' is not supported", e)
+			} else {
+				b.WriteString(regexp.QuoteMeta(string(e)))
+			}
+			single = !inSet
+		case inSet:
+			if c == ']' {
+				inSet = false
+				single = true
+				b.WriteByte(']')
+			} else if c == '\\' || c == '[' {
+				b.WriteString(`\` + string(c))
+			} else {
+				b.WriteByte(c)
+			}
+		case c == '[':
+			inSet = true
+			b.WriteByte('[')
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				b.WriteByte('^')
+				i++
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				b.WriteString(`\]`)
+				i++
+			}
+		case single && strings.IndexByte("*+?-", c) >= 0:
+			if c == '-' {
+				b.WriteString("*?")
+			} else {
+				b.WriteByte(c)
+			}
+			single = false
+		case c == '^' && i == 0:
+			b.WriteByte('^')
+		case c == '$' && i == len(pattern)-1:
+			b.WriteString(`\z`)
+		case c == '(' || c == ')':
+			b.WriteByte(c)
+			single = false
+		case c == '.':
+			b.WriteByte('.')
+			single = true
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+			single = true
+		}
+	}
+	if inSet {
+		return "", fmt.Errorf("malformed pattern (missing ']')")
+	}
+	return b.String(), nil
+}
+
+// pattern compiles a Lua pattern argument
+func (in *luaInterp) pattern(args []luaValue, i int, fn string) *regexp.Regexp {
+	expr, err := luaPatternRegexp(in.checkString(args, i, fn))
+	if err == nil {
+		var re *regexp.Regexp
+		if re, err = regexp.Compile(expr); err == nil {
+			return re
+		}
+	}
+	in.argError(i, fn, err.Error())
+	return nil
+}
+
+// luaCaptures returns the captures of a match, or the whole match if the pattern has none
+func luaCaptures(s string, match []int) []luaValue {
+	if len(match) == 2 {
+		return []luaValue{s[match[0]:match[1]]}
+	}
+	var result []luaValue
+	for i := 2; i < len(match); i += 2 {
+		if match[i] < 0 {
+			result = append(result, nil)
+		} else {
+			result = append(result, s[match[i]:match[i+1]])
+		}
+	}
+	return result
+}
+
+// luaFindStart returns the 0-based start position of a find or match call
+func (in *luaInterp) luaFindStart(args []luaValue, i int, fn string, length int) int {
+	init := in.optInt(args, i, fn, 1)
+	if init < 0 {
+		init = max(length+init+1, 1)
+	} else if init == 0 {
+		init = 1
+	}
+	return init - 1
+}
+
+func luaStringFind(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "find")
+	start := in.luaFindStart(args, 2, "find", len(s))
+	if start > len(s) {
+		return []luaValue{nil}
+	}
+	if luaTruthy(luaArg(args, 3)) {
+		i := strings.Index(s[start:], in.checkString(args, 1, "find"))
+		if i < 0 {
+			return []luaValue{nil}
+		}
+		i += start
+		return []luaValue{float64(i + 1), float64(i + len(in.checkString(args, 1, "find")))}
+	}
+	match := in.pattern(args, 1, "find").FindStringSubmatchIndex(s[start:])
+	if match == nil {
+		return []luaValue{nil}
+	}
+	result := []luaValue{float64(start + match[0] + 1), float64(start + match[1])}
+	if len(match) > 2 {
+		result = append(result, luaCaptures(s[start:], match)...)
+	}
+	return result
+}
+
+func luaStringMatch(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "match")
+	start := in.luaFindStart(args, 2, "match", len(s))
+	if start > len(s) {
+		return []luaValue{nil}
+	}
+	match := in.pattern(args, 1, "match").FindStringSubmatchIndex(s[start:])
+	if match == nil {
+		return []luaValue{nil}
+	}
+	return luaCaptures(s[start:], match)
+}
+
+func luaStringGmatch(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "gmatch")
+	matches := in.pattern(args, 1, "gmatch").FindAllStringSubmatchIndex(s, -1)
+	i := 0
+	return []luaValue{&luaGoFunction{name: "gmatch_iterator", fn: func(in *luaInterp, args []luaValue) []luaValue {
+		if i >= len(matches) {
+			return []luaValue{nil}
+		}
+		i++
+		return luaCaptures(s, matches[i-1])
+	}}}
+}
+
+func luaStringGsub(in *luaInterp, args []luaValue) []luaValue {
+	s := in.checkString(args, 0, "gsub")
+	re := in.pattern(args, 1, "gsub")
+	repl := luaArg(args, 2)
+	limit := in.optInt(args, 3, "gsub", -1)
+
+	var b strings.Builder
+	write := func(part string) {
+		if b.Len()+len(part) > luaMaxStringLen {
+			in.raise(in.line, "resulting string too large")
+		}
+		in.alloc(len(part))
+		b.WriteString(part)
+	}
+	last, count := 0, 0
+	for _, match := range re.FindAllStringSubmatchIndex(s, limit) {
+		write(s[last:match[0]])
+		last = match[1]
+		count++
+		whole := s[match[0]:match[1]]
+		captures := luaCaptures(s, match)
+
+		var value luaValue
+		switch r := repl.(type) {
+		case string, float64:
+			template := luaToString(r)
+			for i := 0; i < len(template); i++ {
+				if template[i] != '%' || i+1 >= len(template) {
+					write(template[i : i+1])
+					continue
+				}
+				i++
+				switch c := template[i]; {
+				case c == '0':
+					write(whole)
+				case c >= '1' && c <= '9':
+					if n := int(c - '0'); n <= len(captures) {
+						write(luaToString(captures[n-1]))
+					} else {
+						in.raise(in.line, "invalid capture index %%%c in replacement string", c)
+					}
+				default:
+					write(template[i : i+1])
+				}
+			}
+			continue
+		case *luaTable:
+			value = r.get(captures[0])
+		case *luaFunction, *luaGoFunction:
+			if results := in.call(r, captures, in.line, "gsub replacement"); len(results) > 0 {
+				value = results[0]
+			}
+		default:
+			in.argError(2, "gsub", "string/function/table expected, got "+luaTypeName(repl))
+		}
+		switch v := value.(type) {
+		case nil, bool:
+			if v == true {
+				in.raise(in.line, "invalid replacement value (a boolean)")
+			}
+			write(whole)
+		case string, float64:
+			write(luaToString(v))
+		default:
+			in.raise(in.line, "invalid replacement value (a %s)", luaTypeName(v))
+		}
+	}
+	write(s[last:])
+	return []luaValue{b.String(), float64(count)}
+}
+
+func luaStringFormat(in *luaInterp, args []luaValue) []luaValue {
+	format := in.checkString(args, 0, "format")
+	var b strings.Builder
+	arg := 1
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+		start, digits := i, 0
+		for i < len(format) && strings.IndexByte("-+ #0123456789.", format[i]) >= 0 {
+			// Widths and precisions have at most 2 digits, as in Lua, so "%999999999d" cannot pad
+			// a string out of memory
+			if format[i] >= '0' && format[i] <= '9' {
+				digits++
+			} else {
+				digits = 0
+			}
+			if digits > 2 {
+				in.raise(in.line, "invalid conversion '%%%s' to 'format'", format[start:i+1])
+			}
+			i++
+		}
+		if i >= len(format) {
+			in.raise(in.line, "invalid conversion '%%%s' to 'format'", format[start:])
+		}
+		spec, verb := "%"+format[start:i], format[i]
+		if arg >= len(args) {
+			in.argError(arg, "format", "no value")
+		}
+		switch verb {
+		case 'd', 'i':
+			fmt.Fprintf(&b, spec+"d", int64(in.checkInt(args, arg, "format")))
+		case 'x', 'X', 'o':
+			fmt.Fprintf(&b, spec+string(verb), int64(in.checkInt(args, arg, "format")))
+		case 'c':
+			b.WriteByte(byte(in.checkInt(args, arg, "format")))
+		case 'e', 'E', 'f', 'F', 'g', 'G':
+			fmt.Fprintf(&b, spec+string(verb), in.checkNumber(args, arg, "format"))
+		case 's':
+			fmt.Fprintf(&b, spec+"s", luaToString(args[arg]))
+		case 'q':
+			if s, ok := args[arg].(string); ok {
+				b.WriteString(strconv.Quote(s))
+			} else {
+				b.WriteString(luaToString(args[arg]))
+			}
+		default:
+			in.raise(in.line, "invalid conversion '%s%c' to 'format'", spec, verb)
+		}
+		if b.Len() > luaMaxStringLen {
+			in.raise(in.line, "resulting string too large")
+		}
+		arg++
+	}
+	in.alloc(b.Len())
+	return []luaValue{b.String()}
+}
+
+// Table library
+
+func luaTableInsert(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "insert")
+	n := t.length()
+	in.alloc(luaTableEntrySize)
+	switch len(args) {
+	case 2:
+		t.set(float64(n+1), args[1])
+	case 3:
+		pos := in.checkInt(args, 1, "insert")
+		if pos < 1 || pos > n+1 {
+			in.argError(1, "insert", "position out of bounds")
+		}
+		for i := n; i >= pos; i-- {
+			t.set(float64(i+1), t.get(float64(i)))
+		}
+		t.set(float64(pos), args[2])
+	default:
+		in.raise(in.line, "wrong number of arguments to 'insert'")
+	}
+	return nil
+}
+
+func luaTableRemove(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "remove")
+	n := t.length()
+	pos := in.optInt(args, 1, "remove", n)
+	if n == 0 && luaArg(args, 1) == nil {
+		return []luaValue{nil}
+	}
+	if pos < 1 || pos > n+1 {
+		in.argError(1, "remove", "position out of bounds")
+	}
+	removed := t.get(float64(pos))
+	for i := pos; i < n; i++ {
+		t.set(float64(i), t.get(float64(i+1)))
+	}
+	if pos <= n {
+		t.set(float64(n), nil)
+	}
+	return []luaValue{removed}
+}
+
+func luaTableConcat(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "concat")
+	sep := ""
+	if luaArg(args, 1) != nil {
+		sep = in.checkString(args, 1, "concat")
+	}
+	first := in.optInt(args, 2, "concat", 1)
+	last := in.optInt(args, 3, "concat", t.length())
+	var parts []string
+	size := 0
+	for i := first; i <= last; i++ {
+		s, ok := luaConcatString(t.get(float64(i)))
+		if !ok {
+			in.raise(in.line, "invalid value (at index %d) in table for 'concat'", i)
+		}
+		if size += len(s) + len(sep); size > luaMaxStringLen+len(sep) {
+			in.raise(in.line, "resulting string too large")
+		}
+		parts = append(parts, s)
+	}
+	in.alloc(max(size-len(sep), 0))
+	return []luaValue{strings.Join(parts, sep)}
+}
+
+func luaTableSort(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "sort")
+	comp := luaArg(args, 1)
+	values := make([]luaValue, t.length())
+	for i := range values {
+		values[i] = t.get(float64(i + 1))
+	}
+	less := &luaBinExpr{op: "<", line: in.line}
+	sort.SliceStable(values, func(i, j int) bool {
+		if comp != nil {
+			results := in.call(comp, []luaValue{values[i], values[j]}, in.line, "sort comparator")
+			return len(results) > 0 && luaTruthy(results[0])
+		}
+		return in.binary(less, values[i], values[j]).(bool)
+	})
+	for i, v := range values {
+		t.set(float64(i+1), v)
+	}
+	return nil
+}
+
+func luaTableUnpack(in *luaInterp, args []luaValue) []luaValue {
+	t := in.checkTable(args, 0, "unpack")
+	first := in.optInt(args, 1, "unpack", 1)
+	last := in.optInt(args, 2, "unpack", t.length())
+	if first > last {
+		return nil
+	}
+	// Counted in unsigned arithmetic, which cannot overflow for bounds at the ends of the int range
+	count := uint64(last) - uint64(first) + 1
+	if count == 0 || count > luaMaxResults {
+		in.raise(in.line, "too many results to unpack")
+	}
+	result := make([]luaValue, count)
+	for i := range result {
+		result[i] = t.get(float64(first + i))
+	}
+	return result
+}
+
+// Math library
+
+func luaMathFn(name string, fn func(float64) float64) func(*luaInterp, []luaValue) []luaValue {
+	return func(in *luaInterp, args []luaValue) []luaValue {
+		return []luaValue{fn(in.checkNumber(args, 0, name))}
+	}
+}
+
+func luaMathMax(in *luaInterp, args []luaValue) []luaValue {
+	result := in.checkNumber(args, 0, "max")
+	for i := 1; i < len(args); i++ {
+		result = math.Max(result, in.checkNumber(args, i, "max"))
+	}
+	return []luaValue{result}
+}
+
+func luaMathMin(in *luaInterp, args []luaValue) []luaValue {
+	result := in.checkNumber(args, 0, "min")
+	for i := 1; i < len(args); i++ {
+		result = math.Min(result, in.checkNumber(args, i, "min"))
+	}
+	return []luaValue{result}
+}
+
+// OS library: only the clock
+
+// luaStrftime maps strftime conversions to Go layouts
+var luaStrftime = map[byte]string{
+	'a': "Mon", 'A': "Monday", 'b': "Jan", 'B': "January", 'd': "02", 'H': "15", 'I': "03",
+	'm': "01", 'M': "04", 'p': "PM", 'S': "05", 'y': "06", 'Y': "2006", 'Z': "MST",
+	'c': "Mon Jan  2 15:04:05 2006", 'x': "01/02/06", 'X': "15:04:05", 'F': "2006-01-02", 'T': "15:04:05",
+}
+
+func luaOSDate(in *luaInterp, args []luaValue) []luaValue {
+	format := "%c"
+	if luaArg(args, 0) != nil {
+		format = in.checkString(args, 0, "date")
+	}
+	t := time.Now()
+	if luaArg(args, 1) != nil {
+		t = time.Unix(int64(in.checkInt(args, 1, "date")), 0)
+	}
+	if strings.HasPrefix(format, "!") {
+		format = format[1:]
+		t = t.UTC()
+	}
+
+	if format == "*t" {
+		result := newLuaTable()
+		for key, value := range map[string]int{
+			"year": t.Year(), "month": int(t.Month()), "day": t.Day(), "hour": t.Hour(),
+			"min": t.Minute(), "sec": t.Second(), "wday": int(t.Weekday()) + 1, "yday": t.YearDay(),
+		} {
+			result.set(key, float64(value))
+		}
+		return []luaValue{result}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch c := format[i]; c {
+		case '%':
+			b.WriteByte('%')
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", week)
+		case 'w':
+			fmt.Fprintf(&b, "%d", int(t.Weekday()))
+		default:
+			layout, ok := luaStrftime[c]
+			if !ok {
+				in.argError(0, "date", fmt.Sprintf("invalid conversion specifier '%%%c'", c))
+			}
+			b.WriteString(t.Format(layout))
+		}
+	}
+	return []luaValue{b.String()}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// luaTreeString renders an expression with every operation parenthesized, to check how the parser
+// grouped it
+func luaTreeString(e luaExpr) string {
+	switch e := e.(type) {
+	case *luaConstExpr:
+		if s, ok := e.value.(string); ok {
+			return fmt.Sprintf("%q", s)
+		}
+		return luaToString(e.value)
+	case *luaNameExpr:
+		return e.name
+	case *luaIndexExpr:
+		return luaTreeString(e.obj) + "[" + luaTreeString(e.key) + "]"
+	case *luaCallExpr:
+		var args []string
+		for _, arg := range e.args {
+			args = append(args, luaTreeString(arg))
+		}
+		fn := luaTreeString(e.fn)
+		if e.method != "" {
+			fn += ":" + e.method
+		}
+		return fn + "(" + strings.Join(args, ", ") + ")"
+	case *luaBinExpr:
+		return "(" + luaTreeString(e.left) + " " + e.op + " " + luaTreeString(e.right) + ")"
+	case *luaUnaryExpr:
+		return "(" + e.op + " " + luaTreeString(e.expr) + ")"
+	case *luaParenExpr:
+		return luaTreeString(e.expr)
+	case *luaTableExpr:
+		var items []string
+		for i, value := range e.values {
+			if e.keys[i] == nil {
+				items = append(items, luaTreeString(value))
+			} else {
+				items = append(items, "["+luaTreeString(e.keys[i])+"]="+luaTreeString(value))
+			}
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	case *luaFuncExpr:
+		return "function(" + strings.Join(e.params, ", ") + ")"
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func TestLuaLexer(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"local x = 1", "local x = 1"},
+		{"a..b...c", "a .. b ... c"},
+		{"x ~= y // 2", "x ~= y // 2"},
+		{"0x1F 1e2 .5 3.25", "31 100 0.5 3.25"},
+		{`"a\tb\65\n" 'it''s'`, `"a\tbA\n" "it" "s"`},
+		{"[[\nline\n]] [==[a]]b]==]", `"line\n" "a]]b"`},
+		{"-- comment\nx --[[ long\ncomment ]] y", "x y"},
+		{"#!/usr/bin/env lua\nreturn", "return"},
+	}
+	for _, tt := range tests {
+		lexer := &luaLexer{chunk: "test", src: tt.src, line: 1}
+		tokens, err := lexer.tokens()
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		var got []string
+		for _, tok := range tokens[:len(tokens)-1] {
+			got = append(got, strings.Trim(luaTokenText(tok), "'"))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q: tokens %s, want %s", tt.src, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestLuaLexerLines(t *testing.T) {
+	lexer := &luaLexer{chunk: "test", src: "a\n[[x\ny]]\n\"s\\\nt\" b", line: 1}
+	tokens, err := lexer.tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, tok := range tokens {
+		lines = append(lines, tok.line)
+	}
+	if got := fmt.Sprint(lines); got != "[1 3 4 5 5]" {
+		t.Errorf("lines %s, want [1 3 4 5 5]", got)
+	}
+}
+
+func TestParseLuaPrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"1 - 2 - 3", "((1 - 2) - 3)"},
+		{"2 ^ 3 ^ 2", "(2 ^ (3 ^ 2))"},
+		{"-2 ^ 2", "(- (2 ^ 2))"},
+		{`"a" .. "b" .. "c"`, `("a" .. ("b" .. "c"))`},
+		{"1 + 2 .. 3", "((1 + 2) .. 3)"},
+		{"not a == b", "((not a) == b)"},
+		{"a or b and c", "(a or (b and c))"},
+		{"a < b and b <= c", "((a < b) and (b <= c))"},
+		{"#t + 1", "((# t) + 1)"},
+		{"a.b.c", `a["b"]["c"]`},
+		{"a[1].b", `a[1]["b"]`},
+		{`f "x"`, `f("x")`},
+		{"f{1, 2}", "f({1, 2})"},
+		{"obj:m(1)(2)", "obj:m(1)(2)"},
+		{"{1, x = 2, [3] = 4; 5}", `{1, ["x"]=2, [3]=4, 5}`},
+		{"function(a, b) end", "function(a, b)"},
+	}
+	for _, tt := range tests {
+		block, err := parseLua("test", "return "+tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		ret, ok := block[0].(*luaReturnStat)
+		if len(block) != 1 || !ok || len(ret.exprs) != 1 {
+			t.Errorf("%s: parsed as %#v", tt.expr, block)
+			continue
+		}
+		if got := luaTreeString(ret.exprs[0]); got != tt.want {
+			t.Errorf("%s: parsed as %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseLuaStatements(t *testing.T) {
+	src := `
+local a, b = 1, 2
+x, y = y, x
+f(a)
+obj:method()
+if a then elseif b then else end
+while a do break end
+repeat local z = 1 until z
+for i = 1, 10, 2 do end
+for k, v in pairs(t) do end
+do end
+function t.a.b:c(self2) end
+local function g() return end
+;;
+return a, b
+`
+	block, err := parseLua("test", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, stat := range block {
+		got = append(got, strings.TrimPrefix(fmt.Sprintf("%T", stat), "*main."))
+	}
+	want := "luaLocalStat luaAssignStat luaCallStat luaCallStat luaIfStat luaWhileStat luaRepeatStat " +
+		"luaNumForStat luaGenForStat luaDoStat luaAssignStat luaLocalFuncStat luaReturnStat"
+	if strings.Join(got, " ") != want {
+		t.Errorf("statements %s,\nwant %s", strings.Join(got, " "), want)
+	}
+
+	method := block[10].(*luaAssignStat)
+	if target := luaTreeString(method.targets[0]); target != `t["a"]["b"]["c"]` {
+		t.Errorf("method defined on %s", target)
+	}
+	if params := method.exprs[0].(*luaFuncExpr).params; strings.Join(params, ",") != "self,self2" {
+		t.Errorf("method params %v, want self and self2", params)
+	}
+}
+
+func TestParseLuaErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"x = ", "test:1: unexpected <eof>"},
+		{"if x then\n", "test:2: 'end' expected near <eof>"},
+		{"local 1 = 2", "test:1: name expected near 1"},
+		{"x = 'abc", "test:1: unfinished string"},
+		{"x = [[abc", "test:1: unfinished long string"},
+		{"x = '\\q'", `test:1: invalid escape sequence '\q'`},
+		{"x = '\\300'", "test:1: decimal escape too large"},
+		{"x = 1 @ 2", `test:1: unexpected symbol '@'`},
+		{"f(...)", "test:1: varargs are not supported"},
+		{"return 1\nx = 2", "test:2: unexpected 'x'"},
+		{"\n\nx = (1", "test:3: ')' expected near <eof>"},
+		{"1 + 1", "test:1: unexpected 1"},
+		{"f() = 1", "test:1: syntax error: cannot assign to expression"},
+	}
+	for _, tt := range tests {
+		_, err := parseLua("test", tt.src)
+		if err == nil {
+			t.Errorf("%q: no error, want %s", tt.src, tt.want)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err.Error(), tt.want)
+		}
+	}
+}
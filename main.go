@@ -4,7 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,10 +15,22 @@ const AppVersion = "0.1.0"
 var projectDirectory string
 
 func main() {
+	// Dispatch non-interactive subcommands (e.g. "relix history list") before TUI flags
+	if len(os.Args) > 1 {
+		if cmd := findCLICommand(os.Args[1]); cmd != nil {
+			os.Exit(runCLI(cmd, "relix", os.Args[2:]))
+		}
+	}
+
 	// Define command-line flags
 	var showHelp bool
 	var showVersion bool
 	var projectDir string
+	var demo bool
+	var fixturePath string
+	var openHistory bool
+	var openSettings bool
+	var projectPath string
 
 	flag.StringVar(&projectDir, "d", "", "Project root directory path")
 	flag.StringVar(&projectDir, "project-directory", "", "Project root directory path")
@@ -27,18 +38,15 @@ func main() {
 	flag.BoolVar(&showHelp, "help", false, "Show help message")
 	flag.BoolVar(&showVersion, "v", false, "Show version")
 	flag.BoolVar(&showVersion, "version", false, "Show version")
+	flag.BoolVar(&demo, "demo", false, "Run against a fake GitLab and a throwaway git fixture")
+	flag.StringVar(&fixturePath, "fixture", "", "Run in demo mode with the projects and MRs of a JSON fixture")
+	flag.BoolVar(&openHistory, "history", false, "Open the releases history")
+	flag.BoolVar(&openSettings, "settings", false, "Open the settings")
+	flag.StringVar(&projectPath, "project", "", "Select the project with this path and open its MRs")
 
 	// Custom usage message
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Relix - GitLab Release Manager\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: relix [options]\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fmt.Fprintf(os.Stderr, "  -d, --project-directory <path>  Project root directory path\n")
-		fmt.Fprintf(os.Stderr, "  -h, --help                      Show this help message\n")
-		fmt.Fprintf(os.Stderr, "  -v, --version                   Show version\n")
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  relix                           Run in current directory\n")
-		fmt.Fprintf(os.Stderr, "  relix -d /path/to/project       Run with specified project directory\n")
+		printRootUsage(os.Stderr)
 	}
 
 	flag.Parse()
@@ -56,45 +64,48 @@ func main() {
 	}
 
 	// Validate and set project directory
-	if projectDir != "" {
-		// Convert to absolute path
-		absPath, err := filepath.Abs(projectDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: invalid project directory path: %v\n", err)
-			os.Exit(1)
-		}
+	if err := setProjectDirectory(projectDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-		// Check if directory exists
-		info, err := os.Stat(absPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "Error: project directory does not exist: %s\n", absPath)
-			} else {
-				fmt.Fprintf(os.Stderr, "Error: cannot access project directory: %v\n", err)
-			}
-			os.Exit(1)
-		}
+	// Screen and project to open instead of the home screen
+	if err := setStartupScreen(openHistory, openSettings, projectPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-		if !info.IsDir() {
-			fmt.Fprintf(os.Stderr, "Error: specified path is not a directory: %s\n", absPath)
+	// Demo mode replaces the config, credentials and project directory with throwaway ones
+	stopDemo := func() {}
+	if demo || fixturePath != "" {
+		var err error
+		if stopDemo, err = startDemo(fixturePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: demo mode: %v\n", err)
 			os.Exit(1)
 		}
-
-		projectDirectory = absPath
 	}
 
-	// Load theme from config before creating the model (rebuilds all styles)
-	loadThemeFromConfig()
+	// Start in safe mode if the previous session crashed or left a release mid-step
+	openSessionMarker()
+
+	// Build styles for the default theme; the configured theme is loaded by the model's Init
+	rebuildStyles()
 
-	p := tea.NewProgram(NewModel(), tea.WithAltScreen())
+	p := tea.NewProgram(crashGuard{NewModel()}, tea.WithAltScreen())
 
 	// Send program reference to model for async message sending
 	go func() {
 		p.Send(setProgramMsg{program: p})
 	}()
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	closeSessionMarker(final, err)
+	stopDemo()
+	if err != nil {
 		fmt.Printf("Error running program: %v", err)
+		if lastCrashReport != "" {
+			fmt.Printf("\nCrash report written to %s\n", lastCrashReport)
+		}
 		os.Exit(1)
 	}
 }
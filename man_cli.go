@@ -0,0 +1,257 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// manCommand generates man pages from the command definitions
+var manCommand = &cliCommand{
+	Name:        "man",
+	Summary:     "Generate man pages for relix and its commands",
+	Description: "Writes one roff man page per command (relix.1, relix-history.1, relix-history-list.1, ...) built from the same definitions as --help. The page date is taken from SOURCE_DATE_EPOCH when set, for reproducible builds.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix man --output /usr/local/share/man/man1",
+		"relix man --output ./man && man ./man/relix-history-export.1",
+	},
+	Env: []cliEnvVar{
+		{Name: "SOURCE_DATE_EPOCH", Description: "Unix timestamp used as the page date instead of the current time"},
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		output := fs.String("output", "man", "Output `directory`")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			if err := os.MkdirAll(*output, 0o755); err != nil {
+				return err
+			}
+
+			date := time.Now()
+			if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+				date = time.Unix(epoch, 0).UTC()
+			}
+
+			pages := map[string]func(w io.Writer){
+				"relix.1": func(w io.Writer) { writeRootManPage(w, date) },
+			}
+			var walk func(cmd *cliCommand, path []string)
+			walk = func(cmd *cliCommand, path []string) {
+				path = append(append([]string{}, path...), cmd.Name)
+				pages[strings.Join(path, "-")+".1"] = func(w io.Writer) { writeCommandManPage(w, cmd, path, date) }
+				for _, sub := range cmd.Sub {
+					walk(sub, path)
+				}
+			}
+			for _, cmd := range cliCommands {
+				walk(cmd, []string{"relix"})
+			}
+
+			names := make([]string, 0, len(pages))
+			for name := range pages {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				write := pages[name]
+				f, err := os.Create(filepath.Join(*output, name))
+				if err != nil {
+					return err
+				}
+				write(f)
+				if err := f.Close(); err != nil {
+					return err
+				}
+				fmt.Println(filepath.Join(*output, name))
+			}
+			return nil
+		}
+	},
+}
+
+// helpCommand prints detailed help for a command path ("relix help history list")
+var helpCommand = &cliCommand{
+	Name:    "help",
+	Summary: "Show detailed help for a command",
+	Usage:   "[command [subcommand]]",
+	Examples: []string{
+		"relix help",
+		"relix help history export",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		return func(args []string) error {
+			if len(args) == 0 {
+				printRootUsage(os.Stdout)
+				return nil
+			}
+			cmd := findCLICommand(args[0])
+			path := "relix " + args[0]
+			for _, name := range args[1:] {
+				if cmd == nil {
+					break
+				}
+				var next *cliCommand
+				for _, sub := range cmd.Sub {
+					if sub.Name == name {
+						next = sub
+					}
+				}
+				cmd = next
+				path += " " + name
+			}
+			if cmd == nil {
+				return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+			}
+			printCLIUsage(os.Stdout, cmd, path)
+			return nil
+		}
+	},
+}
+
+// Registered in init: both commands walk cliCommands, which would otherwise be an initialization cycle
+func init() {
+	cliCommands = append(cliCommands, helpCommand, manCommand)
+}
+
+// printRootUsage prints the top-level help for the TUI and the available commands
+func printRootUsage(w io.Writer) {
+	fmt.Fprintf(w, "Relix - GitLab Release Manager\n\n")
+	fmt.Fprintf(w, "Usage: relix [options]\n")
+	fmt.Fprintf(w, "       relix <command> [options]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+	for _, c := range cliCommands {
+		fmt.Fprintf(w, "  %-31s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintf(w, "\nOptions:\n")
+	fmt.Fprintf(w, "  -d, --project-directory <path>  Project root directory path\n")
+	fmt.Fprintf(w, "      --demo                      Run against a fake GitLab and a throwaway git fixture\n")
+	fmt.Fprintf(w, "      --fixture <file>            Run in demo mode with the projects and MRs of a JSON file\n")
+	fmt.Fprintf(w, "      --project <path>            Select the project with this path and open its MRs\n")
+	fmt.Fprintf(w, "      --history                   Open the releases history\n")
+	fmt.Fprintf(w, "      --settings                  Open the settings\n")
+	fmt.Fprintf(w, "  -h, --help                      Show this help message\n")
+	fmt.Fprintf(w, "  -v, --version                   Show version\n")
+	fmt.Fprintf(w, "\nExamples:\n")
+	fmt.Fprintf(w, "  relix                           Run in current directory\n")
+	fmt.Fprintf(w, "  relix -d /path/to/project       Run with specified project directory\n")
+	fmt.Fprintf(w, "  relix --demo                    Try relix without a GitLab instance\n")
+	fmt.Fprintf(w, "  relix --project group/app       Open the MRs of group/app\n")
+	fmt.Fprintf(w, "  relix history list              List recorded releases\n")
+	fmt.Fprintf(w, "\nRun 'relix help <command>' for details on a command.\n")
+}
+
+// writeRootManPage writes relix(1)
+func writeRootManPage(w io.Writer, date time.Time) {
+	writeManHeader(w, "relix", date)
+	fmt.Fprintf(w, ".SH NAME\nrelix \\- GitLab release manager\n")
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B relix\n[\\fIoptions\\fR]\n.br\n.B relix\n\\fIcommand\\fR [\\fIoptions\\fR]\n")
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roffEscape("Without a command, relix starts the interactive terminal UI for selecting merge requests, merging them into a release branch, creating the environment MR and tagging the release. Commands provide the same functionality for scripts and automation."))
+	fmt.Fprintf(w, ".SH OPTIONS\n")
+	writeManOption(w, "\\-d, \\-\\-project\\-directory", "path", "Project root directory path")
+	writeManOption(w, "\\-\\-demo", "", "Run against a fake GitLab and a throwaway git fixture; nothing of the user's config is touched")
+	writeManOption(w, "\\-\\-fixture", "file", "Run in demo mode with the projects and merge requests of a JSON fixture")
+	writeManOption(w, "\\-\\-project", "path", "Select the project with this path, e.g. group/app, and open its merge requests instead of the home screen")
+	writeManOption(w, "\\-\\-history", "", "Open the releases history instead of the home screen")
+	writeManOption(w, "\\-\\-settings", "", "Open the settings instead of the home screen")
+	writeManOption(w, "\\-h, \\-\\-help", "", "Show help message")
+	writeManOption(w, "\\-v, \\-\\-version", "", "Show version")
+	fmt.Fprintf(w, ".SH COMMANDS\n")
+	for _, c := range cliCommands {
+		fmt.Fprintf(w, ".TP\n.BR relix\\-%s (1)\n%s\n", roffEscape(c.Name), roffEscape(c.Summary))
+	}
+	fmt.Fprintf(w, ".SH FILES\n")
+	fmt.Fprintf(w, ".TP\n.I ~/.relix/config.json\nProject selection, release settings and themes\n")
+	fmt.Fprintf(w, ".TP\n.I ~/.relix/release.json\nState of the release in progress, used to resume after a crash (release-N.json for other release tabs)\n")
+	fmt.Fprintf(w, ".TP\n.I ~/.local/.relix/releases/\nRelease history (index.json and one file per release)\n")
+}
+
+// writeCommandManPage writes the man page of a command, e.g. relix-history-list(1)
+func writeCommandManPage(w io.Writer, cmd *cliCommand, path []string, date time.Time) {
+	name := strings.Join(path, "-")
+	invocation := strings.Join(path, " ")
+
+	writeManHeader(w, name, date)
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roffEscape(name), roffEscape(cmd.Summary))
+
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n", roffEscape(invocation))
+	if len(cmd.Sub) > 0 {
+		fmt.Fprintf(w, "\\fIcommand\\fR [\\fIoptions\\fR]\n")
+	} else if cmd.Usage != "" {
+		fmt.Fprintf(w, "%s\n", roffEscape(cmd.Usage))
+	}
+
+	description := cmd.Summary + "."
+	if cmd.Description != "" {
+		description = cmd.Description
+	}
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roffEscape(description))
+
+	if len(cmd.Sub) > 0 {
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, sub := range cmd.Sub {
+			fmt.Fprintf(w, ".TP\n.BR %s\\-%s (1)\n%s\n", roffEscape(name), roffEscape(sub.Name), roffEscape(sub.Summary))
+		}
+	}
+
+	if options := cliCommandOptions(cmd); len(options) > 0 {
+		fmt.Fprintf(w, ".SH OPTIONS\n")
+		for _, opt := range options {
+			writeManOption(w, "\\-\\-"+roffEscape(opt.Name), opt.Arg, opt.Description)
+		}
+	}
+
+	if len(cmd.Env) > 0 {
+		fmt.Fprintf(w, ".SH ENVIRONMENT\n")
+		for _, env := range cmd.Env {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roffEscape(env.Name), roffEscape(env.Description))
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintf(w, ".SH EXAMPLES\n.nf\n")
+		for _, ex := range cmd.Examples {
+			fmt.Fprintf(w, "%s\n", roffEscape(ex))
+		}
+		fmt.Fprintf(w, ".fi\n")
+	}
+
+	parent := "relix"
+	if len(path) > 2 {
+		parent = strings.Join(path[:len(path)-1], "-")
+	}
+	fmt.Fprintf(w, ".SH SEE ALSO\n.BR %s (1)\n", roffEscape(parent))
+}
+
+// writeManHeader writes the .TH title line
+func writeManHeader(w io.Writer, name string, date time.Time) {
+	fmt.Fprintf(w, ".TH %s 1 \"%s\" \"relix %s\" \"Relix Manual\"\n",
+		strings.ToUpper(roffEscape(name)), date.Format("January 2006"), AppVersion)
+}
+
+// writeManOption writes a tagged paragraph for a flag; flagText must already be escaped
+func writeManOption(w io.Writer, flagText, arg, description string) {
+	fmt.Fprintf(w, ".TP\n\\fB%s\\fR", flagText)
+	if arg != "" {
+		fmt.Fprintf(w, " \\fI%s\\fR", roffEscape(arg))
+	}
+	fmt.Fprintf(w, "\n%s\n", roffEscape(description))
+}
+
+// roffEscape escapes text for use in roff: backslashes, hyphens and leading control characters
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
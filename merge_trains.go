@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Merge trains and merged results pipelines: a GitLab project enabling them only lets merge
+// requests reach their target branch once a pipeline of the merged result passed. Releases keep to
+// that instead of bypassing it with local merges: each MR is stitched only once its latest pipeline
+// passed (waiting for one still running), the release MR is added to the merge train, and with root
+// merge the release root branch reaches the base branch through a merge request on the train
+// rather than a local merge pushed over it (see mergeThroughMR in push_strategy.go). The settings
+// are read by the fetch step of a release.
+
+// mergeTrainTimeout bounds the waits for MR pipelines and merge trains
+const mergeTrainTimeout = time.Hour
+
+// MergeProtection holds the merge settings of a project that releases keep to
+type MergeProtection struct {
+	MergeTrains            bool `json:"merge_trains,omitempty"`
+	MergedResultsPipelines bool `json:"merged_results_pipelines,omitempty"`
+}
+
+// trains reports whether merges go through merge trains
+func (p *MergeProtection) trains() bool {
+	return p != nil && p.MergeTrains
+}
+
+// mergedResults reports whether MRs are merged only after their merged results pipeline passed
+func (p *MergeProtection) mergedResults() bool {
+	return p != nil && p.MergedResultsPipelines
+}
+
+// describe returns the line telling the release output what the release keeps to
+func (p *MergeProtection) describe() string {
+	if p.trains() {
+		return "Project uses merge trains: MRs are stitched once their pipelines pass, release merges go through the train"
+	}
+	return "Project uses merged results pipelines: MRs are stitched once their pipelines pass"
+}
+
+// detectMergeProtection reads the merge settings of a GitLab project; nil if it uses neither, on
+// other forges or if they cannot be read
+func detectMergeProtection(creds Credentials, projectID int) *MergeProtection {
+	client, ok := NewForge(creds).(*GitLabClient)
+	if !ok {
+		return nil
+	}
+	protection, err := client.GetMergeProtection(projectID)
+	if err != nil || (!protection.MergeTrains && !protection.MergedResultsPipelines) {
+		return nil
+	}
+	return protection
+}
+
+// waitMRPipeline waits for the latest pipeline of the index-th MR of the release to finish and
+// fails unless it passed
+func waitMRPipeline(ctx context.Context, creds Credentials, state *ReleaseState, index int, sender messageSender) (string, error) {
+	var output strings.Builder
+	if index >= len(state.SelectedMRIIDs) {
+		return "", nil
+	}
+	iid := state.SelectedMRIIDs[index]
+	printLine := releaseStepPrinter(sender, &output, fmt.Sprintf("wait for pipeline of !%d", iid))
+	client := NewForge(creds)
+
+	deadline := time.Now().Add(mergeTrainTimeout)
+	status := ""
+	for {
+		pipelines, err := client.GetMergeRequestPipelines(state.ProjectID, iid)
+		if err != nil && !isNetworkError(err) {
+			return output.String(), fmt.Errorf("failed to get pipelines of !%d: %w", iid, err)
+		}
+		if err == nil {
+			if len(pipelines) == 0 {
+				return output.String(), fmt.Errorf("!%d has no pipeline: run one from the MR and retry", iid)
+			}
+			latest := pipelines[0]
+			if latest.Status != status {
+				status = latest.Status
+				printLine(fmt.Sprintf("Pipeline #%d: %s", latest.ID, status))
+			}
+			if pipelineFinished(status) {
+				if status != "success" {
+					return output.String(), fmt.Errorf("pipeline #%d of !%d %s: %s", latest.ID, iid, status, latest.WebURL)
+				}
+				return output.String(), nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return output.String(), fmt.Errorf("pipeline of !%d did not finish in %s", iid, mergeTrainTimeout)
+		}
+		if err := waitPollInterval(ctx); err != nil {
+			return output.String(), err
+		}
+	}
+}
+
+// addToMergeTrain adds a release MR to the merge train of its target branch
+func addToMergeTrain(creds Credentials, projectID, mrIID int) error {
+	client, ok := NewForge(creds).(*GitLabClient)
+	if !ok {
+		return fmt.Errorf("merge trains need GitLab credentials")
+	}
+	err := client.AddToMergeTrain(projectID, mrIID)
+	recordAudit(auditMRMergeTrain, fmt.Sprintf("project %d", projectID), fmt.Sprintf("!%d", mrIID), err)
+	return err
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics are recorded in every mode and exposed in the Prometheus text format on /metrics by
+// "relix serve". The few metric types needed are implemented here rather than pulling in the
+// Prometheus client library.
+var (
+	releasesStarted = newCounterVec("relix_releases_started_total",
+		"Releases started.", "environment")
+	releasesFinished = newCounterVec("relix_releases_finished_total",
+		"Releases finished, by outcome: completed, failed (headless releases stopped by an error) or aborted.", "environment", "outcome")
+	mergeConflicts = newCounterVec("relix_merge_conflicts_total",
+		"Releases suspended by a merge conflict.", "environment")
+	releaseStepDuration = newHistogramVec("relix_release_step_duration_seconds",
+		"Duration of release steps.", []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}, "step", "result")
+	forgeRequestDuration = newHistogramVec("relix_forge_request_duration_seconds",
+		"Latency of GitLab (or other forge) API requests.", []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}, "endpoint", "code")
+)
+
+// metricsRegistry lists the metrics written by writeMetrics, in output order
+var metricsRegistry = []metricWriter{releasesStarted, releasesFinished, mergeConflicts, releaseStepDuration, forgeRequestDuration}
+
+// metricWriter writes a metric family in the Prometheus text format
+type metricWriter interface {
+	write(w io.Writer)
+}
+
+// counterVec is a counter with labels
+type counterVec struct {
+	name, help string
+	labels     []string
+	mu         sync.Mutex
+	values     map[string]float64 // Joined label values -> count
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// inc adds one to the counter with the given label values
+func (c *counterVec) inc(labelValues ...string) {
+	c.mu.Lock()
+	c.values[strings.Join(labelValues, "\xff")]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, key, "", ""), formatFloat(c.values[key]))
+	}
+}
+
+// histogramVec is a histogram with labels
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64 // Upper bounds, ascending; +Inf is implied
+	mu         sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+// histogramSeries holds the observations of one label set
+type histogramSeries struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// observe records a duration for the given label values
+func (h *histogramVec) observe(d time.Duration, labelValues ...string) {
+	v := d.Seconds()
+	key := strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, key, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, key, "", ""), s.count)
+	}
+}
+
+// formatLabels renders {name="value",...} from joined label values, plus an extra label if given
+func formatLabels(names []string, key, extraName, extraValue string) string {
+	var pairs []string
+	if len(names) > 0 {
+		for i, value := range strings.Split(key, "\xff") {
+			if i < len(names) {
+				pairs = append(pairs, names[i]+"="+strconv.Quote(value))
+			}
+		}
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+"="+strconv.Quote(extraValue))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatFloat renders a sample value the way Prometheus parses it
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of m in order, so output is stable between scrapes
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeMetrics writes all metrics in the Prometheus text format
+func writeMetrics(w io.Writer) {
+	for _, m := range metricsRegistry {
+		m.write(w)
+	}
+}
+
+// handleMetrics serves the metrics for Prometheus
+func (s *apiServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w)
+}
+
+// metricsTransport records the latency of API requests by endpoint: the last path segment that
+// is not an ID, e.g. "merge_requests", "pipelines" or "notes"
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	forgeRequestDuration.observe(time.Since(start), metricsEndpoint(req.URL.Path), code)
+	return resp, err
+}
+
+// metricsEndpoint returns the last path segment that is not numeric or a commit SHA
+func metricsEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		s := segments[i]
+		if s == "" || isMetricsID(s) {
+			continue
+		}
+		return s
+	}
+	return "/"
+}
+
+// isMetricsID reports whether a path segment is an ID (number or hex SHA) rather than a resource name
+func isMetricsID(s string) bool {
+	if _, err := strconv.Atoi(s); err == nil {
+		return true
+	}
+	if len(s) < 7 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
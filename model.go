@@ -1,11 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -22,58 +23,46 @@ type model struct {
 	height  int
 	program *tea.Program // Reference for sending async messages
 
+	// headless is set when the model is driven without a terminal UI (see release_headless.go)
+	headless bool
+
+	// Release flow of the active tab (see release_tabs.go)
+	releaseSession
+	parkedTabs []releaseSession // Inactive tabs, by tab ID
+	lastTabID  int              // Highest tab ID given out, so IDs of closed tabs are not reused
+	termHeight int              // Terminal height; height excludes the tab bar
+
 	// Auth form
 	inputs     []textinput.Model
 	focusIndex int
 	loading    bool
 	spinner    spinner.Model
 
-	// Error
-	errorMsg string
-
-	// Main screen
-	list        list.Model
-	viewport    viewport.Model
-	ready       bool
-	creds       *Credentials
-	selectedMRs map[int]bool // Track selected MRs by IID
-	loadingMRs   bool // Loading modal for MRs
-	mrsLoaded    bool // True after first MR load completes
-	mrsLoadError bool // True if last MR load failed
-
-	// Environment selection screen
-	environments   []Environment
-	envSelectIndex int
-
-	// Version input screen
-	versionInput textinput.Model
-	selectedEnv  *Environment
-	versionError string
-
-	// Source branch input screen
-	sourceBranchInput         textinput.Model
-	sourceBranchError         string
-	sourceBranchVersion       string    // Version used when source branch was last modified
-	sourceBranchRemoteStatus  string    // "exists-same", "exists-diff", "new", "checking", ""
-	sourceBranchLastCheckTime time.Time // For throttling checks
-	sourceBranchCheckedName   string    // Branch name that was last checked
+	keyringError string // Why the stored credentials could not be read, shown on the auth form
 
-	// Env merge screen
-	envMergeOptionIndex  int  // 0 = squash (default), 1 = regular
-	envMergeSelection    int  // confirmed choice: 0 = squash, 1 = regular
-	envMergeCommitCount  int  // calculated commit count for regular merge display
-	envMergeCountLoading bool // true while calculating commit count
+	// Error
+	errorMsg     string
+	errorContext errorContext // Failed operation and the actions it offers
+	errorNotice  string       // Result of copying the error details
 
-	// Root merge screen
-	rootMergeButtonIndex int  // 0 = Yes, 1 = No
-	rootMergeSelection   bool // true = merge, false = skip
+	creds *Credentials
 
-	// Confirmation screen
-	confirmViewport viewport.Model
+	// Environments from the config, offered on the environment selection screen
+	environments []Environment
 
 	// Command menu
 	showCommandMenu  bool
 	commandMenuIndex int
+	commandInput     string   // Typed command line, e.g. "set theme nord"
+	commandHistory   []string // Command lines run before, most recent first (see command_history.go)
+
+	// Global search over projects, MRs and history (ctrl+p, see global_search.go)
+	showGlobalSearch    bool
+	globalSearchInput   string
+	globalSearchIndex   int
+	globalSearchLoading bool
+	globalSearchResults []searchResult
+	globalSearchMatches []searchMatch
 
 	// Error modal
 	showErrorModal bool
@@ -82,21 +71,33 @@ type model struct {
 	// Project selector
 	showProjectSelector  bool
 	projects             []Project
-	projectsLoaded       bool // True after projects are fetched
-	loadingProjects      bool // Loading state for project selector
+	projectsLoaded       bool         // True after projects are fetched
+	loadingProjects      bool         // Loading state for project selector
+	projectsCached       bool         // Selector shows cached projects while they are refreshed
+	projectsStaleAt      time.Time    // Fetch time of the cached projects shown while the forge is unreachable
+	projectPages         int          // Pages of projects loaded
+	projectPage          listPage     // Pagination reported with the last loaded page
+	projectsLoadingMore  bool         // The next page of projects is being fetched
+	projectQuery         projectQuery // Scope and archived toggle of the listed projects
 	projectSelectorIndex int
 	projectFilter        string
-	selectedProject      *Project
+	projectFilterPending bool                 // Filter typed but not applied yet (debounced)
+	projectFilterSeq     int                  // Debounce generation; only the latest tick applies the filter
+	projectMatches       []projectMatch       // Projects matching the applied filter, best first
+	projectAvatars       map[int]*inlineImage // Avatars drawn in the selector, by project ID (see graphics.go)
+	avatarsRequested     map[int]bool         // Projects whose avatar download was started
 
 	// Settings screen
 	settingsPreviousScreen  screen // Screen to return to when closing settings
 	settingsViewport        viewport.Model
-	settingsTab             int // Current tab index (0 = Release, 1 = Theme)
+	settingsTab             int // Current tab index (0 = Release, 1 = Theme, 2 = Keyring)
 	settingsBaseBranch      textinput.Model
 	settingsEnvNames        [4]textinput.Model
 	settingsEnvBranches     [4]textinput.Model
 	settingsExcludePatterns textarea.Model
 	settingsPipelineRegex   textinput.Model
+	settingsMRTarget        textinput.Model
+	settingsMRSourceRegex   textinput.Model
 	settingsError           string // Validation error message
 	settingsFocusIndex      int    // 0=base branch, 1-8=env fields, 9=textarea, 10=pipeline regex, 11=save button
 
@@ -105,15 +106,266 @@ type model struct {
 	settingsThemeIndex int           // Cursor position in theme list
 	settingsThemeError string        // Error message when loading themes
 
+	// Keyring settings
+	settingsKeyringIndex  int                         // Cursor position in keyringChoices()
+	settingsKeyringChecks map[string]keyringDiagnosis // State of each backend; nil while checking
+
+	// Config diff shown before the settings are saved (see config_diff.go)
+	showSettingsDiff   bool
+	settingsDiff       []string // Unified diff of the config file
+	settingsDiffBase   []byte   // Config file the diff was made against
+	settingsDiffConfig []byte   // Config file to write
+	settingsDiffOffset int      // First diff line shown
+	settingsDiffIndex  int      // 0=Save, 1=Cancel
+
+	// Background polling (see background_poll.go)
+	backgroundPollGen int // Incremented on login/logout so loops of old credentials stop
+	tokenExpiresAt    *time.Time
+	tokenCheckErr     error // Last token check failed with an authentication error
+
+	// Offline mode (see offline.go)
+	offline      bool // The last forge request could not reach the forge
+	queueFlushed bool // Queued actions were submitted since startup
+
+	// Release history
+	historyList               list.Model
+	historyEntries            []HistoryIndexEntry
+	historySelected           *ReleaseHistoryEntry
+	historyDetailTab          int // 0=MRs, 1=Meta, 2=Logs
+	historyLogsViewport       viewport.Model
+	historyLogLines           []string // Loaded tail of the selected release's terminal output
+	historyLogStart           int64    // Log file offset of historyLogLines; 0 once the start is loaded
+	historyLogLoading         bool
+	historyLogsWrap           bool // Wrap long log lines instead of truncating them (scrolled with < and >)
+	historyMRViewport         viewport.Model
+	historyMRIndex            int                          // Selected MR in detail MRs tab
+	historyMRDetailsMap       map[int]*MergeRequestDetails // All fetched MR details by index
+	loadingHistory            bool
+	loadingHistoryMRs         bool               // Loading state for all MRs fetch
+	historyMRsLoadError       bool               // True if MRs failed to load
+	historySelectMode         bool               // Whether select mode is active
+	historySelectedIDs        map[string]bool    // Selected history entry IDs for deletion
+	showHistoryDeleteConfirm  bool               // Show delete confirmation modal
+	historyDeleteConfirmIndex int                // 0=Delete, 1=Cancel
+	historyTimeline           bool               // Show the release timeline instead of the list (see release_timeline.go)
+	historyTimelineSpan       int                // Index of the period shown in timelineSpans
+	showRollbackConfirm       bool               // Show rollback confirmation modal (see rollback.go)
+	rollbackConfirmIndex      int                // 0=Roll back, 1=Cancel
+	rollbackTarget            *HistoryIndexEntry // Release the rollback restores
+	showPromoteConfirm        bool               // Show the promotion confirmation modal (see release_candidates.go)
+	promoteConfirmIndex       int                // 0=Promote, 1=Cancel
+	showReleaseLock           bool               // Show the modal of a release lock held by someone else (see release_lock.go)
+	releaseLockIndex          int                // 0=Override, 1=Cancel
+	releaseLockHeld           *releaseLock       // Lock of the other holder
+	releaseLockState          *ReleaseState      // Release waiting for the lock
+	releaseLockIntro          []string           // First output lines of the release waiting for the lock
+	releaseLockTab            int                // Tab of the release waiting for the lock
+
+	// Startup: checkCredsMsg is held until the config has been applied
+	startupConfigLoaded bool
+	pendingCreds        *checkCredsMsg
+
+	// Safe mode after a crash or an unfinished release (see safe_mode.go)
+	safeMode          *safeModeInfo
+	safeModeCreds     *checkCredsMsg // Startup held until an action is chosen
+	safeModeIndex     int
+	safeModeExporting bool
+	safeModeExported  string // Archive the logs were exported to
+	safeModeExportErr error
+
+	// Open options modal (for "open" actions)
+	showOpenOptionsModal bool
+	openOptions          []OpenOption
+	openOptionsIndex     int
+
+	// MR quick actions menu ("." on the MR list, see mr_actions.go)
+	showMRActions  bool
+	mrActionsIndex int
+	mrActionTarget *MergeRequestDetails // Kept after closing, for the comment written in the editor
+	mrActionBusy   bool
+	mrActionResult string
+	mrActionFailed bool
+
+	// Post-mortem of an aborted release (see post_mortem.go)
+	showPostMortem     bool
+	postMortemID       string // History entry of the release
+	postMortemCategory int
+	postMortemFocus    int // 0 = category, 1 = text
+	postMortemInput    textinput.Model
+	postMortemError    string
+
+	// Bulk label/milestone edit ("t" on the MR list and the history detail screen, see bulk_edit.go)
+	showBulkEdit        bool
+	bulkEditInput       textinput.Model
+	bulkEditMilestone   bool  // Set a milestone rather than add a label
+	bulkEditIIDs        []int // MRs of the current run: all of them, or the failed ones on retry
+	bulkEditTotal       int
+	bulkEditPending     []int // MRs of the run not sent yet
+	bulkEditMilestoneID int
+	bulkEditResults     []bulkEditResult
+	bulkEditRun         int // Results of an earlier run are dropped
+	bulkEditRunning     bool
+	bulkEditStarted     bool
+	bulkEditError       string
+
+	// Message returned by a plugin command
+	showPluginResult  bool
+	pluginResultTitle string
+	pluginResultText  string
+
+	// Release windows from the config, for the upcoming releases on the home screen
+	releaseWindows []ReleaseWindow
+
+	// Home dashboard (see dashboard.go)
+	pinnedProjects []Project // Projects pinned in the project selector
+	dashboard      dashboardState
+
+	// Newer relix release (see update_check.go)
+	availableUpdate   *relixRelease // Shown on the home screen banner; nil while there is none
+	showUpdateNotes   bool
+	updateNotesOffset int // First line of the release notes shown
+
+	// Short confirmation in the bottom right corner (see toast.go)
+	toast    string
+	toastGen int // Only the expiry of the latest toast hides it
+
+	// Hints under the focused input, hidden with F1 (see field_hints.go)
+	hideFieldHints bool
+
+	// Workspace in use, shown on the home screen; empty before the first switch (see workspaces.go)
+	activeWorkspace string
+}
+
+// releaseSession is the state of one release tab: the project, the MRs and choices of the
+// release flow, and the release itself. The active tab is embedded in the model; the others
+// are parked (see release_tabs.go).
+type releaseSession struct {
+	tabID     int    // Numbers the tab's release state and output files; the first tab uses the plain names
+	tabScreen screen // Screen of a parked tab, shown again when it is switched to
+
+	// Main screen
+	list        list.Model
+	viewport    viewport.Model
+	ready       bool
+	selectedMRs map[int]bool // Track selected MRs by IID
+
+	// Saved MR selection of the project (see selection_draft.go)
+	selectionDraftLoaded  bool
+	selectionDraftPending map[int]bool // Saved MRs not listed yet
+	selectionDraftSaved   []int        // Sorted IIDs last saved
+
+	// Release votes of the listed MRs (see release_votes.go)
+	releaseVotesLoaded bool
+	releaseVotes       map[int]bool // Voted MRs, marked on the list
+
+	// Blocklist of the selected project (see mr_blocklist.go)
+	blockedMRs map[int]string // Reason by IID, marked on the list
+
+	// MRs of the current iteration (see iterations.go)
+	iterationLoading  bool
+	iterationSelected string // Iteration selected last and how many MRs it had, shown in the list title
+
+	// Dependency updates selected as a group (see dependency_updates.go)
+	dependencyLoading  bool
+	dependencySelected string                 // How many were selected last and left out, shown in the list title
+	loadingMRs         bool                   // Loading modal for MRs
+	mrsLoaded          bool                   // True after first MR load completes
+	mrsLoadError       bool                   // True if last MR load failed
+	mrsCached          bool                   // List shows cached MRs while they are refreshed
+	mrsStaleAt         time.Time              // Fetch time of the cached MRs shown while the forge is unreachable
+	mrsAll             []*MergeRequestDetails // Loaded MRs, before the MR filters of the config
+	searchMRIID        int                    // MR picked in the global search, highlighted once listed
+	mrPages            int                    // Pages of the project's MRs loaded (see listPageSize)
+	mrPage             listPage               // Pagination reported with the last loaded page
+	mrsLoadingMore     bool                   // The next page of MRs is being fetched
+	mrDetailsLoading   map[int]bool           // Global IDs of MRs whose details are being fetched
+	mrPollInFlight     bool                   // A background MR list refresh is running (see background_poll.go)
+	mrsSyncedAt        time.Time              // Start of the last MR list fetch, for delta refreshes
+	mrsFullSyncAt      time.Time              // Start of the last full MR list fetch
+
+	selectedProject *Project
+
+	// Environment selection screen
+	envSelectIndex int
+	envDeployments map[string]*Deployment // Latest GitLab deployment of each environment, by name (see deployments.go)
+
+	// Upstream changes of the selected MRs while the release plan is prepared (see plan_watch.go)
+	planWatchGen   int
+	planWatchHeads map[int]string // Head commit of each watched MR, by IID
+	planChanges    []string       // Changes found, shown in a toast until the plan is refreshed
+
+	// Version input screen
+	versionInput        textinput.Model
+	selectedEnv         *Environment
+	versionError        string
+	versionCheckSeq     int                // Debounce generation of the tag check
+	versionCheckedFor   string             // Environment branch and version whose tag check finished (see versionCheckKey)
+	versionTagExists    string             // Tag found by the last finished check, empty if the tag is free
+	versionCandidates   []string           // Release candidates of the version found by the last finished check
+	versionEnterPending bool               // Enter was pressed while the tag check was running
+	versionLatest       []envLatestRelease // Latest released version of each environment, for reference
+	versionSuggestion   string             // Next version of the version scheme, taken with tab
+
+	// Source branch input screen
+	sourceBranchInput         textinput.Model
+	sourceBranchError         string
+	sourceBranchVersion       string    // Version used when source branch was last modified
+	sourceBranchRemoteStatus  string    // "exists-same", "exists-diff", "new", "checking", ""
+	sourceBranchLastCheckTime time.Time // For throttling checks
+	sourceBranchCheckedName   string    // Branch name that was last checked
+
+	// Env merge screen
+	envMergeOptionIndex  int  // 0 = squash (default), 1 = regular
+	envMergeSelection    int  // confirmed choice: 0 = squash, 1 = regular
+	envMergeCommitCount  int  // calculated commit count for regular merge display
+	envMergeCountLoading bool // true while calculating commit count
+
+	// Root merge screen
+	rootMergeButtonIndex int  // 0 = Yes, 1 = No
+	rootMergeSelection   bool // true = merge, false = skip
+
+	// Rollout screen (environments with rollout enabled)
+	rolloutCanaryInput textinput.Model
+	rolloutFlagsInput  textinput.Model
+	rolloutFocus       int // 0 = canary percentage, 1 = feature flags
+	rolloutError       string
+
+	// Confirmation screen
+	confirmViewport    viewport.Model
+	releaseImpact      *releaseImpact      // Files changed by the selected MRs (see release_impact.go)
+	scheduleCollisions []scheduleCollision // Scheduled pipelines the release would collide with (see pipeline_schedules.go)
+
+	// Confirmation phrase of the environment (see confirm_phrase.go)
+	showConfirmPhrase  bool
+	confirmPhraseInput textinput.Model
+	confirmPhraseError string
+
+	// Deployment pipeline inputs asked for before the release (see pipeline_inputs.go)
+	showPipelineInputs    bool
+	pipelineInputsLoading bool
+	pipelineInputs        []PipelineInput
+	pipelineInputFields   []textinput.Model
+	pipelineInputFocus    int
+	pipelineInputsError   string
+	pipelineInputValues   []PipelineInputValue // Given in the form, for the release it starts
+
 	// Release execution screen
 	releaseState                     *ReleaseState
 	releaseViewport                  viewport.Model
-	releaseOutputBuffer              []string
-	releaseCurrentScreen             string // Virtual terminal screen content
+	releaseOutputBuffer              []string // Latest output lines; older ones are spilled to disk (see output_log.go)
+	releaseOutputBytes               int      // Size of releaseOutputBuffer
+	releaseOutputTotal               int      // Lines appended since the release started, including spilled ones
+	releaseOutputLimit               int      // Memory limit of releaseOutputBuffer in bytes
+	releaseCurrentScreen             string   // Virtual terminal screen content
+	releaseOutputWrap                bool     // Wrap long output lines instead of truncating them (scrolled with < and >)
 	releaseButtonIndex               int
 	releaseButtons                   []ReleaseButton
 	releaseRunning                   bool
+	releaseStepStartedAt             time.Time // Start of the running step, for the step duration metric
 	releaseExecutor                  *GitExecutor
+	releaseLockPending               bool                    // The release lock is being taken for a release to start (see release_lock.go)
+	releaseCtx                       context.Context         // Context of the release's steps, cancelled on abort (see releaseContext)
+	releaseCancel                    context.CancelCauseFunc // Cancels releaseCtx
 	showAbortConfirm                 bool
 	abortConfirmIndex                int  // 0 = Yes, 1 = Cancel
 	showDeleteRemoteConfirm          bool // Second confirmation for deleting remote branch
@@ -121,31 +373,36 @@ type model struct {
 	releaseNeedEmptyLineAfterCommand bool // Flag to add empty line after command output if needed
 
 	// Pipeline observer
-	pipelineObserving    bool
-	pipelineStatus       *PipelineStatus
-	pipelineFailNotified bool // Track if we already sent a failure notification
-
-	// Release history
-	historyList                list.Model
-	historyEntries             []HistoryIndexEntry
-	historySelected            *ReleaseHistoryEntry
-	historyDetailTab           int // 0=MRs, 1=Meta, 2=Logs
-	historyLogsViewport        viewport.Model
-	historyMRViewport          viewport.Model
-	historyMRIndex             int                              // Selected MR in detail MRs tab
-	historyMRDetailsMap        map[int]*MergeRequestDetails     // All fetched MR details by index
-	loadingHistory             bool
-	loadingHistoryMRs          bool                             // Loading state for all MRs fetch
-	historyMRsLoadError        bool                             // True if MRs failed to load
-	historySelectMode          bool                             // Whether select mode is active
-	historySelectedIDs         map[string]bool                  // Selected history entry IDs for deletion
-	showHistoryDeleteConfirm   bool                             // Show delete confirmation modal
-	historyDeleteConfirmIndex  int                              // 0=Delete, 1=Cancel
-
-	// Open options modal (for "open" actions)
-	showOpenOptionsModal bool
-	openOptions          []OpenOption
-	openOptionsIndex     int
+	pipelineObserving     bool
+	pipelineStatus        *PipelineStatus
+	pipelineFailNotified  bool // Track if we already sent a failure notification
+	pipelinePollGen       int  // Incremented on start/stop so ticks of an old loop are dropped
+	pipelineCheckInFlight bool // A status check is running; further ticks are coalesced into it
+
+	// Gated step that may be approved in Telegram (see telegram.go)
+	remoteApproval   *remoteApproval
+	remoteApprovedBy string // Telegram user whose approval passes the gate, for its ReleaseApproval
+
+	// Artifacts modal (release pipeline jobs to download artifacts of)
+	showArtifactsModal bool
+	artifactsLoading   bool
+	artifactsErr       error
+	artifactJobs       []PipelineJob
+	artifactsIndex     int
+
+	// MRs of the completed release missing from lower environments (see back_merge.go)
+	showBackMerge      bool
+	backMergeGaps      []backMergeGap
+	backMergeProjectID int
+	backMergeIndex     int // 0 = Create MRs, 1 = Dismiss
+
+	// Rebase editor of the source branch before Create MR (see rebase_editor.go)
+	showRebaseEditor bool
+	rebaseLoading    bool
+	rebaseErr        error
+	rebaseUpstream   string
+	rebaseCommits    []rebaseCommit
+	rebaseIndex      int
 }
 
 // NewModel creates a new application model
@@ -201,6 +458,25 @@ func NewModel() model {
 	pipelineRegexInput.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
 	pipelineRegexInput.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
 
+	// Initialize settings MR filter inputs
+	mrTargetInput := textinput.New()
+	mrTargetInput.Placeholder = "all target branches, e.g. develop"
+	mrTargetInput.CharLimit = 100
+	mrTargetInput.Width = 40
+	mrTargetInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	mrTargetInput.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	mrTargetInput.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	mrTargetInput.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+
+	mrSourceRegexInput := textinput.New()
+	mrSourceRegexInput.Placeholder = "all source branches, e.g. ^feature/"
+	mrSourceRegexInput.CharLimit = 500
+	mrSourceRegexInput.Width = 40
+	mrSourceRegexInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	mrSourceRegexInput.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	mrSourceRegexInput.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	mrSourceRegexInput.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+
 	// Initialize settings environment name and branch inputs
 	var envNames [4]textinput.Model
 	var envBranches [4]textinput.Model
@@ -235,33 +511,67 @@ func NewModel() model {
 		settingsEnvBranches:     envBranches,
 		settingsExcludePatterns: ta,
 		settingsPipelineRegex:   pipelineRegexInput,
-		environments:            getEnvironments(),
-		selectedMRs:             make(map[int]bool),
+		settingsMRTarget:        mrTargetInput,
+		settingsMRSourceRegex:   mrSourceRegexInput,
+		environments:            envsFromConfig(defaultEnvironments()), // Replaced by loadStartupConfig
 		historyMRDetailsMap:     make(map[int]*MergeRequestDetails),
-		envMergeOptionIndex:     0, // Default to squash
-		envMergeSelection:       0, // Default to squash
-		rootMergeSelection:      true, // Default to "Yes, merge it"
-		rootMergeButtonIndex:    0,    // Default button is "Yes, merge it"
+		releaseSession:          newReleaseSession(firstTabID),
+		safeMode:                previousSession,
+	}
+}
+
+// newReleaseSession creates the state of a release tab before a project is picked
+func newReleaseSession(tabID int) releaseSession {
+	return releaseSession{
+		tabID:                tabID,
+		tabScreen:            screenMain,
+		selectedMRs:          make(map[int]bool),
+		envMergeOptionIndex:  0,    // Default to squash
+		envMergeSelection:    0,    // Default to squash
+		rootMergeSelection:   true, // Default to "Yes, merge it"
+		rootMergeButtonIndex: 0,    // Default button is "Yes, merge it"
 	}
 }
 
 // Init initializes the model
 func (m model) Init() tea.Cmd {
+	// Safe mode loads plugins and checks for updates once it is left
+	if m.safeMode != nil {
+		return tea.Batch(textinput.Blink, m.spinner.Tick, loadStartupConfig(), checkStoredCredentials())
+	}
 	return tea.Batch(
 		textinput.Blink,
 		m.spinner.Tick,
+		loadStartupConfig(),
 		checkStoredCredentials(),
+		loadPlugins(),
+		checkForUpdate(),
 	)
 }
 
 // closeAllModals closes all open modals
 func (m *model) closeAllModals() {
 	m.showCommandMenu = false
+	m.showGlobalSearch = false
 	m.showProjectSelector = false
 	m.showErrorModal = false
 	m.errorModalMsg = ""
 	m.showHistoryDeleteConfirm = false
+	m.showRollbackConfirm = false
+	m.showPromoteConfirm = false
+	m.closeSettingsDiff()
+	m.closeReleaseLock()
 	m.closeOpenOptionsModal()
+	m.closeArtifactsModal()
+	m.closeRebaseEditor()
+	m.showPluginResult = false
+	m.showMRActions = false
+	m.showUpdateNotes = false
+	m.showConfirmPhrase = false
+	m.showPipelineInputs = false
+	m.showBulkEdit = false
+	m.showPostMortem = false
+	m.closeBackMerge()
 }
 
 // closeOpenOptionsModal closes the open options modal and clears its state
@@ -271,8 +581,8 @@ func (m *model) closeOpenOptionsModal() {
 	m.openOptionsIndex = 0
 }
 
-// Update handles all messages
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+// update handles the messages of the active tab and the app-wide ones (see Update in release_tabs.go)
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -280,6 +590,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+		if msg.String() == "ctrl+z" {
+			return m, tea.Suspend
+		}
 
 		// Block all input during loading states
 		if m.loading || m.loadingProjects || m.loadingMRs || m.loadingHistory || m.loadingHistoryMRs {
@@ -296,6 +609,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Handle plugin command result if open
+		if m.showPluginResult {
+			switch msg.String() {
+			case "enter", "esc", "q", "ctrl+q":
+				m.showPluginResult = false
+			}
+			return m, nil
+		}
+
+		// Handle the release lock modal if open
+		if m.showReleaseLock {
+			return m.updateReleaseLock(msg)
+		}
+
+		// Handle the release notes of a newer relix if open
+		if m.showUpdateNotes {
+			return m.updateUpdateNotes(msg)
+		}
+
+		// Handle the confirmation phrase of the environment if open
+		if m.showConfirmPhrase {
+			return m.updateConfirmPhrase(msg)
+		}
+
+		// Handle the deployment pipeline inputs form if open
+		if m.showPipelineInputs {
+			return m.updatePipelineInputs(msg)
+		}
+
+		// Handle the post-mortem of an aborted release if open
+		if m.showPostMortem {
+			return m.updatePostMortem(msg)
+		}
+
+		// Handle the bulk label/milestone edit if open
+		if m.showBulkEdit {
+			return m.updateBulkEdit(msg)
+		}
+
+		// Handle the back-merge offer after a release if open
+		if m.showBackMerge {
+			return m.updateBackMerge(msg)
+		}
+
 		// Handle project selector if open
 		if m.showProjectSelector {
 			return m.updateProjectSelector(msg)
@@ -306,14 +663,45 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCommandMenu(msg)
 		}
 
-		// Open command menu with "/" (except on auth and settings screens)
-		if msg.String() == "/" && m.screen != screenAuth && m.screen != screenSettings {
+		// Handle the global search if open
+		if m.showGlobalSearch {
+			return m.updateGlobalSearch(msg)
+		}
+
+		// Open the global search (except on auth, settings and safe mode screens)
+		if msg.String() == globalSearchKey && m.screen != screenAuth && m.screen != screenSettings && m.screen != screenSafeMode {
+			cmd := m.openGlobalSearch()
+			return m, cmd
+		}
+
+		// Open command menu with "/" (except on auth, settings and safe mode screens)
+		if msg.String() == "/" && m.screen != screenAuth && m.screen != screenSettings && m.screen != screenSafeMode {
 			m.closeAllModals()
 			m.showCommandMenu = true
 			m.commandMenuIndex = 0
+			m.commandInput = ""
+			m.commandHistory = loadCommandHistory()
+			return m, nil
+		}
+
+		// Show or hide the hints under the focused input
+		if msg.String() == fieldHintsKey && m.hasFieldHints() {
+			m.toggleFieldHints()
 			return m, nil
 		}
 
+		// Refresh the release plan after the selected MRs changed upstream
+		if msg.String() == "ctrl+r" && len(m.planChanges) > 0 && m.isPlanScreen() {
+			return m.refreshPlan()
+		}
+
+		// Switch, open and close release tabs (except on auth, settings and safe mode screens)
+		if m.screen != screenAuth && m.screen != screenSettings && m.screen != screenSafeMode {
+			if cmd, ok := m.handleTabKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+
 		switch m.screen {
 		case screenAuth:
 			return m.updateAuth(msg)
@@ -333,6 +721,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateEnvMerge(msg)
 		case screenRootMerge:
 			return m.updateRootMerge(msg)
+		case screenRollout:
+			return m.updateRollout(msg)
 		case screenConfirm:
 			return m.updateConfirm(msg)
 		case screenRelease:
@@ -343,94 +733,80 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateHistoryDetail(msg)
 		case screenSettings:
 			return m.updateSettings(msg)
+		case screenSafeMode:
+			return m.updateSafeMode(msg)
 		}
 
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-
-		if m.screen == screenMain {
-			m.updateListSize()
-		}
-		if m.screen == screenConfirm {
-			m.initConfirmViewport()
-		}
-		if m.screen == screenRelease {
-			m.initReleaseScreen()
-			if m.releaseExecutor != nil {
-				m.releaseExecutor.Resize(uint16(m.height-10), uint16(m.width-sidebarWidth(m.width)-10))
-			}
-		}
-		if m.screen == screenHistoryList {
-			m.updateHistoryListSize()
-		}
-		if m.screen == screenHistoryDetail {
-			m.initHistoryDetailScreen()
-		}
-		if m.screen == screenSettings {
-			m.updateSettingsSize()
+		m.resize(msg.Width, msg.Height)
+
+	case startupConfigMsg:
+		m.applyStartupConfig(msg)
+		if m.pendingCreds != nil {
+			pending := *m.pendingCreds
+			m.pendingCreds = nil
+			next, cmd := m.Update(pending)
+			return next, tea.Batch(mouseModeCmd(), cmd)
 		}
+		cmds = append(cmds, mouseModeCmd())
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 
 	case checkCredsMsg:
+		// Wait for the theme so the first screen is not drawn with the default one
+		if !m.startupConfigLoaded {
+			m.pendingCreds = &msg
+			return m, nil
+		}
 		m.loading = false
-		if msg.creds != nil {
-			m.creds = msg.creds
-
-			// Check for existing release state first
-			if releaseState, err := LoadReleaseState(); err == nil && releaseState != nil {
-				// Resume existing release
-				m.initListScreen()
-				m.updateListSize()
-				// Load project info for release
-				if config, err := LoadConfig(); err == nil && config.SelectedProjectID != 0 {
-					m.selectedProject = &Project{
-						ID:                config.SelectedProjectID,
-						Name:              config.SelectedProjectShortName,
-						PathWithNamespace: config.SelectedProjectPath,
-						NameWithNamespace: config.SelectedProjectName,
-					}
-				}
-				return m, m.resumeRelease(releaseState)
-			}
-
-			// Load saved project from config
-			if config, err := LoadConfig(); err == nil && config.SelectedProjectID != 0 {
-				m.selectedProject = &Project{
-					ID:                config.SelectedProjectID,
-					Name:              config.SelectedProjectShortName,
-					PathWithNamespace: config.SelectedProjectPath,
-					NameWithNamespace: config.SelectedProjectName,
-				}
-			}
-
-			m.screen = screenHome
+		// After a crash or an unfinished release, the startup waits in safe mode
+		if m.safeMode != nil {
+			m.openSafeMode(msg)
+			return m, nil
 		}
-		// No credentials - show auth screen
-		if msg.creds == nil {
-			m.screen = screenAuth
+		cmds = append(cmds, m.startSession(msg))
+
+	case cursor.BlinkMsg:
+		// Cursors stay in their focused state without blink ticks
+		if reduceMotion {
+			return m, nil
 		}
 
 	case spinner.TickMsg:
-		if m.loading || m.loadingProjects || m.loadingMRs || m.loadingHistory || m.loadingHistoryMRs || m.releaseRunning || m.sourceBranchRemoteStatus == "checking" || m.envMergeCountLoading || (m.pipelineObserving && m.pipelineStatus != nil && m.pipelineStatus.Stage != PipelineStageCompleted && m.pipelineStatus.Stage != PipelineStageFailed) {
+		if reduceMotion {
+			return m, nil // The tick loop ends, so the static spinner is not redrawn
+		}
+		if m.loading || m.loadingProjects || m.projectsLoadingMore || m.loadingMRs || m.loadingHistory || m.loadingHistoryMRs || m.releaseRunning || m.sourceBranchRemoteStatus == "checking" || m.envMergeCountLoading || m.artifactsLoading || m.dashboard.loading() || m.parkedTabRunning() || (m.pipelineObserving && m.pipelineStatus != nil && m.pipelineStatus.Stage != PipelineStageCompleted && m.pipelineStatus.Stage != PipelineStageFailed) {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
 
+	case keyringDiagnosedMsg:
+		m.settingsKeyringChecks = msg.results
+		if m.screen == screenSettings && m.settingsTab == 2 {
+			m.refreshSettingsViewport()
+		}
+
 	case authResultMsg:
 		m.loading = false
 		if msg.err != nil {
-			m.errorMsg = msg.err.Error()
-			m.screen = screenError
+			m.showError(msg.err.Error(), authErrorContext(msg.creds, msg.err))
 		} else {
 			// Load credentials from keyring after successful auth
 			creds, err := LoadCredentials()
 			if err != nil {
-				m.errorMsg = "Failed to load credentials: " + err.Error()
-				m.screen = screenError
+				// Signing in again saves them anew, e.g. to the keyring backend chosen in settings
+				m.showError("Failed to load credentials: "+err.Error(), errorContext{
+					operation: "Reading the saved credentials",
+					retry:     validateCredentialsCmd(msg.creds),
+					settings:  true,
+				})
 				return m, nil
 			}
 			m.creds = creds
+			m.keyringError = ""
 
 			// Load saved project from config
 			if config, err := LoadConfig(); err == nil && config.SelectedProjectID != 0 {
@@ -441,27 +817,137 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					NameWithNamespace: config.SelectedProjectName,
 				}
 			}
-
-			m.screen = screenHome
+			m.moveToScreen(screenHome)
+			cmds = append(cmds, m.startBackgroundPolling(), m.useProjectRepoSettings(), m.openStartupScreen())
 		}
 
 	case fetchProjectsMsg:
+		// Projects listed before the scope or archived toggle changed
+		if msg.query != m.projectQuery {
+			break
+		}
 		m.loadingProjects = false
 		m.projectsLoaded = true
-		if msg.err != nil {
+		wasCached := m.projectsCached
+		m.projectsCached = false
+		m.projectsStaleAt = time.Time{}
+		cmds = append(cmds, m.trackOffline(msg.err))
+		if isNetworkError(msg.err) {
+			// Offline: keep the cached list, or fall back to an expired cache, instead of failing
+			var projects []Project
+			if fetchedAt, ok := loadStaleCache(projectsCacheName(msg.query), m.creds.GitLabURL, &projects); ok {
+				m.projectsStaleAt = fetchedAt
+				if !wasCached {
+					m.projects = projects
+					m.projectFilter = ""
+					m.filterProjects()
+				}
+			}
+		} else if msg.err != nil {
 			m.closeAllModals()
 			m.showErrorModal = true
 			m.errorModalMsg = "Failed to fetch projects: " + msg.err.Error()
+		} else if wasCached {
+			// The user may already be filtering the cached list; keep the filter and cursor
+			m.projectPages, m.projectPage = 1, msg.page
+			m.replaceProjects(msg.projects)
+			cmds = append(cmds, m.loadProjectAvatars(msg.projects))
 		} else {
+			// The filter is empty unless the selector was opened by "goto project <query>"
+			m.projectPages, m.projectPage = 1, msg.page
 			m.projects = msg.projects
-			m.projectSelectorIndex = 0
-			m.projectFilter = ""
+			m.filterProjects()
+			cmds = append(cmds, m.loadProjectAvatars(msg.projects))
+		}
+
+	case fetchMoreProjectsMsg:
+		if !m.projectsLoadingMore || msg.query != m.projectQuery || msg.page != m.projectPages+1 {
+			break
+		}
+		m.projectsLoadingMore = false
+		if msg.err != nil {
+			m.closeAllModals()
+			m.showErrorModal = true
+			m.errorModalMsg = "Failed to load more projects: " + msg.err.Error()
+			break
+		}
+		m.projectPages, m.projectPage = msg.page, msg.info
+		m.replaceProjects(appendProjectPage(m.projects, msg.projects))
+		cmds = append(cmds, m.loadProjectAvatars(msg.projects))
+
+	case projectAvatarsMsg:
+		if m.projectAvatars == nil {
+			m.projectAvatars = make(map[int]*inlineImage)
+		}
+		for id, img := range msg.avatars {
+			m.projectAvatars[id] = img
+		}
+
+	case projectFilterMsg:
+		if m.projectFilterPending && msg.seq == m.projectFilterSeq {
+			m.filterProjects()
+		}
+
+	case list.FilterMatchesMsg:
+		// Results of the MR list filter, which ranks items asynchronously
+		if m.screen == screenMain {
+			var cmd tea.Cmd
+			m.list, cmd = m.list.Update(msg)
+			if m.ready {
+				m.viewport.SetContent(m.renderMarkdown())
+			}
+			return m, tea.Batch(cmd, m.loadHighlightedMRDetails())
 		}
 
 	case fetchMRsMsg:
+		if msg.background {
+			m.mrPollInFlight = false
+		}
+		// A project switch during a background refresh makes the response stale
+		if msg.projectID != 0 && (m.selectedProject == nil || m.selectedProject.ID != msg.projectID) {
+			break
+		}
+		cmds = append(cmds, m.trackOffline(msg.err))
+		if msg.background {
+			// A failed refresh keeps the list; the next tick retries.
+			// An interactive load or a filter may have started meanwhile; both own the list then.
+			// A refresh started before a page was loaded with "load more" would drop it.
+			if msg.err != nil || m.creds == nil || m.loadingMRs || m.mrsCached || m.mrsLoadingMore || m.list.FilterState() != list.Unfiltered {
+				m.updateMRListTitle()
+				break
+			}
+			// Back online, the refresh replaces a stale list or fills an empty one
+			m.mrsStaleAt = time.Time{}
+			m.mrsLoadError = false
+			m.trackMRSync(msg)
+			m.mrPages, m.mrPage = msg.pages, msg.page
+			cmds = append(cmds, m.setMRItems(msg.mrs))
+			break
+		}
 		m.loadingMRs = false
 		m.mrsLoaded = true
-		if msg.err != nil {
+		wasCached := m.mrsCached
+		m.mrsCached = false
+		m.mrsLoadingMore = false
+		m.mrsStaleAt = time.Time{}
+		if isNetworkError(msg.err) {
+			// Offline: keep the cached list, or fall back to an expired cache, instead of failing.
+			// The background refresh fills it in once the forge is reachable.
+			m.mrsLoadError = false
+			var mrs []*MergeRequestDetails
+			if m.selectedProject != nil {
+				if fetchedAt, ok := loadStaleCache(mrsCacheName(m.selectedProject.ID), m.creds.GitLabURL, &mrs); ok {
+					m.mrsStaleAt = fetchedAt
+					if !wasCached {
+						cmds = append(cmds, m.setMRItems(mrs))
+					}
+				}
+			}
+			m.updateMRListTitle()
+			if m.ready {
+				m.viewport.SetContent(m.renderMarkdown())
+			}
+		} else if msg.err != nil {
 			m.mrsLoadError = true
 			m.closeAllModals()
 			m.showErrorModal = true
@@ -472,28 +958,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		} else {
 			m.mrsLoadError = false
-			// Sort MRs: non-drafts first (by date newest first), then drafts (by date newest first)
-			sort.Slice(msg.mrs, func(i, j int) bool {
-				// Both drafts or both non-drafts: sort by date (newest first)
-				if msg.mrs[i].Draft == msg.mrs[j].Draft {
-					return msg.mrs[i].CreatedAt.After(msg.mrs[j].CreatedAt)
-				}
-				// Drafts go last
-				return !msg.mrs[i].Draft && msg.mrs[j].Draft
-			})
-
-			items := make([]list.Item, len(msg.mrs))
-			for i, mr := range msg.mrs {
-				items[i] = mrListItem{mr: mr}
-			}
-			m.list.SetItems(items)
+			m.trackMRSync(msg)
+			m.mrPages, m.mrPage = msg.pages, msg.page
+			return m, tea.Batch(m.setMRItems(msg.mrs), m.loadMoreMRs())
+		}
 
-			// Build title: "Open MRs (count)"
-			m.list.Title = fmt.Sprintf("Open MRs (%d)", len(msg.mrs))
+	case fetchMoreMRsMsg:
+		// A reload or project switch meanwhile makes the page stale
+		if !m.mrsLoadingMore || m.selectedProject == nil || m.selectedProject.ID != msg.projectID || msg.page != m.mrPages+1 {
+			break
+		}
+		m.mrsLoadingMore = false
+		if msg.err != nil {
+			m.updateMRListTitle()
+			m.closeAllModals()
+			m.showErrorModal = true
+			m.errorModalMsg = "Failed to load more MRs: " + msg.err.Error()
+			break
+		}
+		m.mrPages, m.mrPage = msg.page, msg.info
+		return m, tea.Batch(m.setMRItems(appendMRPage(m.mrsAll, msg.mrs)), m.loadMoreMRs())
 
-			if m.ready {
-				m.viewport.SetContent(m.renderMarkdown())
-			}
+	case fetchMRDetailsMsg:
+		delete(m.mrDetailsLoading, msg.id)
+		if msg.err == nil {
+			m.applyMRDetails(msg.details)
 		}
 
 	case existingReleaseMsg:
@@ -503,19 +992,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case releaseOutputMsg:
-		m.appendReleaseOutput(msg.line)
+		m.appendReleaseOutput(remapOutputColors(msg.line))
 		return m, nil
 
 	case releaseCommandStartMsg:
 		// Flush current virtual terminal screen to buffer before starting new command
 		if m.releaseCurrentScreen != "" {
-			lines := strings.Split(m.releaseCurrentScreen, "\n")
-			for _, line := range lines {
-				m.releaseOutputBuffer = append(m.releaseOutputBuffer, line)
-			}
-			if len(m.releaseOutputBuffer) > maxOutputLines {
-				m.releaseOutputBuffer = m.releaseOutputBuffer[len(m.releaseOutputBuffer)-maxOutputLines:]
-			}
+			m.addReleaseOutput(strings.Split(m.releaseCurrentScreen, "\n")...)
 			m.releaseCurrentScreen = ""
 		}
 		// Smart empty line before command: only add if last line isn't empty
@@ -546,16 +1029,191 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.appendReleaseOutput("")
 			}
 		}
-		m.releaseCurrentScreen = msg.content
+		m.releaseCurrentScreen = redactSecrets(msg.content)
 		m.updateReleaseViewport()
 		return m, nil
 
 	case releaseStepCompleteMsg:
 		return m.handleReleaseStepComplete(msg)
 
+	case releaseLockMsg:
+		cmd := m.handleReleaseLock(msg)
+		return m, cmd
+
 	case releaseMRCreatedMsg:
 		return m.handleMRCreated(msg)
 
+	case deployPipelineStartedMsg:
+		m.handleDeployPipelineStarted(msg)
+		return m, nil
+
+	case pipelineInputsMsg:
+		return m.handlePipelineInputs(msg)
+
+	case deploymentRecordedMsg:
+		m.handleDeploymentRecorded(msg)
+		return m, nil
+
+	case envDeploymentsMsg:
+		m.handleEnvDeployments(msg)
+		return m, nil
+
+	case planWatchTickMsg:
+		return m, m.handlePlanWatchTick(msg)
+
+	case planWatchMsg:
+		return m, m.handlePlanWatch(msg)
+
+	case remoteApprovalMsg:
+		return m.handleRemoteApproval(msg)
+
+	case releaseNotifyMsg:
+		// Report failed deliveries in the release output; after the release ends they are dropped
+		if m.releaseState != nil {
+			for _, err := range msg.errs {
+				m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("WARNING: " + err.Error()))
+			}
+		}
+		return m, nil
+
+	case releaseNotesMsg:
+		m.handleReleaseNotes(msg)
+		return m, nil
+
+	case releaseEvidenceMsg:
+		m.handleReleaseEvidence(msg)
+		return m, nil
+
+	case pluginCommandMsg:
+		return m, m.handlePluginCommand(msg)
+
+	case customCommandMsg:
+		m.handleCustomCommand(msg)
+		return m, nil
+
+	case startupProjectMsg:
+		return m, m.handleStartupProject(msg)
+
+	case toastExpiredMsg:
+		m.handleToastExpired(msg)
+		return m, nil
+
+	case repoSettingsMsg:
+		m.handleRepoSettings(msg)
+
+	case commandOpenMRMsg:
+		return m, m.handleCommandOpenMR(msg)
+
+	case screenshotMsg:
+		m.handleScreenshot(msg)
+		return m, nil
+
+	case updateAvailableMsg:
+		m.availableUpdate = msg.release
+
+	case releaseVotesMsg:
+		m.handleReleaseVotes(msg)
+
+	case iterationMRsMsg:
+		m.handleIterationMRs(msg)
+
+	case dependencyUpdatesMsg:
+		m.handleDependencyUpdates(msg)
+
+	case mrHookMsg:
+		m.handleMRHook(msg)
+		return m, nil
+
+	case versionHookMsg:
+		m.handleVersionHook(msg)
+		if msg.err != nil {
+			return m, nil
+		}
+		cmd := m.checkVersion()
+		return m, cmd
+
+	case versionCheckTickMsg:
+		if msg.seq == m.versionCheckSeq && m.screen == screenVersion {
+			return m, m.checkVersionTag()
+		}
+		return m, nil
+
+	case versionTagCheckMsg:
+		if msg.key != m.versionCheckKey() {
+			return m, nil
+		}
+		m.versionCheckedFor = msg.key
+		m.versionTagExists = msg.tag
+		m.versionCandidates = msg.candidates
+		if msg.tag != "" {
+			m.versionError = m.existingTagError(msg.tag)
+			m.versionEnterPending = false
+			return m, nil
+		}
+		if m.versionEnterPending && m.screen == screenVersion {
+			m.versionEnterPending = false
+			return m.proceedFromVersion()
+		}
+		return m, nil
+
+	case candidatePromotedMsg:
+		return m, m.handleCandidatePromoted(msg)
+
+	case versionLatestMsg:
+		m.versionLatest = msg.releases
+		m.versionSuggestion = m.schemeVersionSuggestion()
+		return m, nil
+
+	case artifactJobsMsg:
+		m.handleArtifactJobs(msg)
+		return m, nil
+
+	case artifactDownloadMsg:
+		m.handleArtifactDownload(msg)
+		return m, nil
+
+	case rebaseCommitsMsg:
+		m.handleRebaseCommits(msg)
+		return m, nil
+
+	case rebaseDoneMsg:
+		return m, m.handleRebaseDone(msg)
+
+	case backMergeCheckMsg:
+		m.handleBackMergeCheck(msg)
+		return m, nil
+
+	case backMergeCreatedMsg:
+		return m, m.handleBackMergeCreated(msg)
+
+	case shellExitedMsg:
+		m.handleShellExited(msg)
+		return m, nil
+
+	case releaseImpactMsg:
+		m.handleReleaseImpact(msg)
+		return m, nil
+
+	case scheduleCollisionsMsg:
+		m.handleScheduleCollisions(msg)
+		return m, nil
+
+	case editTextMsg:
+		cmd := m.handleEditText(msg)
+		return m, cmd
+
+	case editorFinishedMsg:
+		cmd := m.handleEditorFinished(msg)
+		return m, cmd
+
+	case bulkEditBatchMsg:
+		cmd := m.handleBulkEditBatch(msg)
+		return m, cmd
+
+	case mrActionDoneMsg:
+		m.handleMRActionDone(msg)
+		return m, nil
+
 	case setProgramMsg:
 		m.program = msg.program
 		return m, nil
@@ -596,7 +1254,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case pipelineTickMsg:
-		if m.pipelineObserving {
+		// Only one check at a time: a tick arriving while a check is running is dropped,
+		// the check re-arms the tick when it returns
+		if m.pipelineObserving && msg.gen == m.pipelinePollGen && !m.pipelineCheckInFlight {
+			m.pipelineCheckInFlight = true
 			return m, m.checkPipelineStatus()
 		}
 		return m, nil
@@ -604,6 +1265,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pipelineStatusMsg:
 		return m.handlePipelineStatus(msg)
 
+	case mrPollTickMsg:
+		return m, m.handleMRPollTick(msg)
+
+	case tokenPollTickMsg:
+		if msg.gen == m.backgroundPollGen {
+			return m, m.checkToken()
+		}
+		return m, nil
+
+	case tokenStatusMsg:
+		return m, m.handleTokenStatus(msg)
+
+	case dashboardPollTickMsg:
+		return m, m.handleDashboardPollTick(msg)
+
+	case dashboardMRsMsg, dashboardPipelinesMsg, dashboardReleasesMsg, dashboardActivityMsg:
+		return m, m.handleDashboardMsg(msg)
+
+	case queuedActionsFlushedMsg:
+		// Actions the forge rejected are dropped; say so in the release output or a modal
+		if len(msg.errs) == 0 {
+			return m, nil
+		}
+		if m.releaseState != nil {
+			for _, err := range msg.errs {
+				m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("WARNING: " + err.Error()))
+			}
+			return m, nil
+		}
+		lines := make([]string, len(msg.errs))
+		for i, err := range msg.errs {
+			lines[i] = err.Error()
+		}
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Queued actions failed:\n" + strings.Join(lines, "\n")
+		return m, nil
+
+	case globalSearchMsg:
+		m.handleGlobalSearch(msg)
+		return m, nil
+
+	case safeModeExportMsg:
+		m.handleSafeModeExport(msg)
+		return m, nil
+
 	case fetchHistoryMsg:
 		m.loadingHistory = false
 		if msg.err != nil {
@@ -617,6 +1324,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				items[i] = historyListItem{entry: entry}
 			}
 			m.historyList.SetItems(items)
+			fitPagination(&m.historyList)
 			m.historyList.Title = fmt.Sprintf("Releases History (%d)", len(msg.entries))
 		}
 		return m, nil
@@ -634,12 +1342,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.historyMRIndex = 0
 			m.historyMRDetailsMap = make(map[int]*MergeRequestDetails)
 			m.historyMRsLoadError = false
-			m.screen = screenHistoryDetail
+			m.moveToScreen(screenHistoryDetail)
+			logCmd := m.loadHistoryLog()
 			m.initHistoryDetailScreen()
 			// Don't auto-load MRs, let user trigger with 'r'
+			return m, logCmd
 		}
 		return m, nil
 
+	case historyLogChunkMsg:
+		m.applyHistoryLogChunk(msg)
+		return m, nil
+
 	case fetchAllHistoryMRsMsg:
 		m.loadingHistoryMRs = false
 		if msg.err != nil {
@@ -700,6 +1414,45 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	// Update the post-mortem input for non-KeyMsg messages (like cursor blink)
+	if m.showPostMortem && m.postMortemFocus == 1 {
+		var cmd tea.Cmd
+		m.postMortemInput, cmd = m.postMortemInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Update the bulk edit input for non-KeyMsg messages (like cursor blink)
+	if m.showBulkEdit && !m.bulkEditStarted {
+		var cmd tea.Cmd
+		m.bulkEditInput, cmd = m.bulkEditInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Update the confirmation phrase input for non-KeyMsg messages (like cursor blink)
+	if m.showConfirmPhrase {
+		var cmd tea.Cmd
+		m.confirmPhraseInput, cmd = m.confirmPhraseInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Update the focused pipeline input for non-KeyMsg messages (like cursor blink)
+	if m.showPipelineInputs && len(m.pipelineInputFields) > 0 {
+		var cmd tea.Cmd
+		m.pipelineInputFields[m.pipelineInputFocus], cmd = m.pipelineInputFields[m.pipelineInputFocus].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	// Update the focused rollout input for non-KeyMsg messages (like cursor blink)
+	if m.screen == screenRollout {
+		var cmd tea.Cmd
+		if m.rolloutFocus == 0 {
+			m.rolloutCanaryInput, cmd = m.rolloutCanaryInput.Update(msg)
+		} else {
+			m.rolloutFlagsInput, cmd = m.rolloutFlagsInput.Update(msg)
+		}
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
@@ -727,6 +1480,8 @@ func (m model) View() string {
 		view = m.viewEnvMerge()
 	case screenRootMerge:
 		view = m.viewRootMerge()
+	case screenRollout:
+		view = m.viewRollout()
 	case screenConfirm:
 		view = m.viewConfirm()
 	case screenRelease:
@@ -737,6 +1492,8 @@ func (m model) View() string {
 		view = m.viewHistoryDetail()
 	case screenSettings:
 		view = m.viewSettings()
+	case screenSafeMode:
+		view = m.viewSafeMode()
 	}
 
 	// Overlay loading modal if loading MRs or history
@@ -744,16 +1501,36 @@ func (m model) View() string {
 		view = overlayLoadingModal(m.spinner.View(), view, m.width, m.height)
 	}
 
+	// Overlay the toast about upstream changes of the selected MRs while the plan is prepared
+	if len(m.planChanges) > 0 && m.isPlanScreen() {
+		view = m.overlayPlanToast(view)
+	}
+
+	// Overlay the toast, below the modals
+	if m.toast != "" {
+		view = m.overlayToast(view)
+	}
+
 	// Overlay command menu if open
 	if m.showCommandMenu {
 		view = m.overlayCommandMenu(view)
 	}
 
+	// Overlay the global search if open
+	if m.showGlobalSearch {
+		view = m.overlayGlobalSearch(view)
+	}
+
 	// Overlay project selector if open
 	if m.showProjectSelector {
 		view = m.overlayProjectSelector(view)
 	}
 
+	// Overlay plugin command result if open
+	if m.showPluginResult {
+		view = m.overlayPluginResult(view)
+	}
+
 	// Overlay error modal if open
 	if m.showErrorModal {
 		view = m.overlayErrorModal(view)
@@ -764,19 +1541,122 @@ func (m model) View() string {
 		view = m.overlayHistoryDeleteConfirm(view)
 	}
 
+	// Overlay rollback confirmation if open
+	if m.showRollbackConfirm {
+		view = m.overlayRollbackConfirm(view)
+	}
+
+	// Overlay the promotion confirmation of a release candidate if open
+	if m.showPromoteConfirm {
+		view = m.overlayPromoteConfirm(view)
+	}
+
+	// Overlay the confirmation phrase of the environment if open
+	if m.showConfirmPhrase {
+		view = m.overlayConfirmPhrase(view)
+	}
+
+	// Overlay the deployment pipeline inputs form if open
+	if m.showPipelineInputs {
+		view = m.overlayPipelineInputs(view)
+	}
+
+	// Overlay release lock modal if open
+	if m.showReleaseLock {
+		view = m.overlayReleaseLock(view)
+	}
+
+	// Overlay the release notes of a newer relix if open
+	if m.showUpdateNotes {
+		view = m.overlayUpdateNotes(view)
+	}
+
 	// Overlay open options modal if open
 	if m.showOpenOptionsModal {
 		view = m.overlayOpenOptionsModal(view)
 	}
 
+	// Overlay MR quick actions menu if open
+	if m.showMRActions {
+		view = m.overlayMRActions(view)
+	}
+
+	// Overlay the post-mortem of an aborted release if open
+	if m.showPostMortem {
+		view = m.overlayPostMortem(view)
+	}
+
+	// Overlay the bulk label/milestone edit if open
+	if m.showBulkEdit {
+		view = m.overlayBulkEdit(view)
+	}
+
+	// Overlay artifacts modal if open
+	if m.showArtifactsModal {
+		view = m.overlayArtifactsModal(view)
+	}
+
+	// Overlay the rebase editor of the source branch if open
+	if m.showRebaseEditor {
+		view = m.overlayRebaseEditor(view)
+	}
+
+	// Overlay the back-merge offer after a release if open
+	if m.showBackMerge {
+		view = m.overlayBackMerge(view)
+	}
+
+	// Overlay the config diff of the settings if open
+	if m.showSettingsDiff {
+		view = m.overlaySettingsDiff(view)
+	}
+
+	// Tab bar above the screen while several release tabs are open
+	if bar := m.renderTabBar(); bar != "" {
+		view = bar + "\n" + view
+	}
+
+	// Glyphs the terminal cannot draw are replaced
+	view = termPolicy.degrade(view)
+
 	// Apply app background color if set
-	if currentTheme.HasBackground {
-		view = applyFullBackground(view, currentTheme.Background, m.width, m.height)
+	if currentTheme.HasBackground && termPolicy.fullBackground() {
+		view = applyFullBackground(view, currentTheme.Background, m.width, m.termHeight)
 	}
 
 	return view
 }
 
+// resize lays out the current screen for the terminal size. The screens get the height below
+// the tab bar.
+func (m *model) resize(width, height int) {
+	m.width = width
+	m.termHeight = height
+	m.height = height - m.tabBarHeight()
+
+	if m.screen == screenMain {
+		m.updateListSize()
+	}
+	if m.screen == screenConfirm {
+		m.initConfirmViewport()
+	}
+	if m.screen == screenRelease {
+		m.initReleaseScreen()
+		if m.releaseExecutor != nil {
+			m.releaseExecutor.Resize(uint16(m.height-10), uint16(m.width-sidebarWidth(m.width)-10))
+		}
+	}
+	if m.screen == screenHistoryList {
+		m.updateHistoryListSize()
+	}
+	if m.screen == screenHistoryDetail {
+		m.initHistoryDetailScreen()
+	}
+	if m.screen == screenSettings {
+		m.updateSettingsSize()
+	}
+}
+
 // getTerminalWidth returns the width available for terminal content
 func (m *model) getTerminalWidth() int {
 	if m.width == 0 {
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MR quick actions: "." on the highlighted MR of the list opens a menu of common MR operations,
+// so they need no keys of their own in the list keymap. Forge actions run in the background and
+// report their result in the menu, which stays open for the next action.
+
+// mrAction is an entry of the MR quick actions menu
+type mrAction int
+
+const (
+	mrActionApprove mrAction = iota
+	mrActionOpen
+	mrActionCopyBranch
+	mrActionRebase
+	mrActionToggleDraft
+	mrActionComment
+	mrActionBlocklist
+)
+
+// mrActionKeys are the shortcuts of the menu entries, in menu order
+var mrActionKeys = []string{"a", "o", "c", "r", "d", "m", "b"}
+
+// mrActionDoneMsg reports a finished forge action on an MR
+type mrActionDoneMsg struct {
+	action  mrAction
+	id      int    // Global MR ID
+	title   string // New title after toggling draft
+	queued  bool   // The comment was queued while the forge is unreachable
+	blocked bool   // The MR was added to the blocklist rather than removed
+	err     error
+}
+
+// mrActionLabel returns the menu label of an action for the MR; blocked tells whether it is on the
+// blocklist
+func mrActionLabel(action mrAction, mr *MergeRequestDetails, blocked bool) string {
+	switch action {
+	case mrActionApprove:
+		return "Approve"
+	case mrActionOpen:
+		return "Open in browser"
+	case mrActionCopyBranch:
+		return "Copy branch name"
+	case mrActionRebase:
+		return "Rebase onto " + mr.TargetBranch
+	case mrActionToggleDraft:
+		if mr.Draft {
+			return "Mark as ready"
+		}
+		return "Mark as draft"
+	case mrActionBlocklist:
+		if blocked {
+			return "Remove from blocklist"
+		}
+		return "Add to blocklist…"
+	}
+	return "Comment…"
+}
+
+// openMRActions opens the quick actions menu on the highlighted MR
+func (m model) openMRActions() (tea.Model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(mrListItem)
+	if !ok || item.MR() == nil || m.selectedProject == nil {
+		return m, nil
+	}
+	m.closeAllModals()
+	m.showMRActions = true
+	m.mrActionsIndex = 0
+	m.mrActionTarget = item.MR()
+	m.mrActionBusy = false
+	m.mrActionResult = ""
+	m.mrActionFailed = false
+	return m, nil
+}
+
+// updateMRActions handles key events of the quick actions menu
+func (m model) updateMRActions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+q", "q", "esc":
+		m.showMRActions = false
+		return m, nil
+	case "down", "j":
+		if m.mrActionsIndex < len(mrActionKeys)-1 {
+			m.mrActionsIndex++
+		}
+		return m, nil
+	case "up", "k":
+		if m.mrActionsIndex > 0 {
+			m.mrActionsIndex--
+		}
+		return m, nil
+	case "enter":
+		return m.runMRAction(mrAction(m.mrActionsIndex))
+	}
+	for i, k := range mrActionKeys {
+		if key == k {
+			m.mrActionsIndex = i
+			return m.runMRAction(mrAction(i))
+		}
+	}
+	return m, nil
+}
+
+// runMRAction performs an action on the MR of the menu. Only one forge action runs at a time.
+func (m model) runMRAction(action mrAction) (tea.Model, tea.Cmd) {
+	mr := m.mrActionTarget
+	if mr == nil || m.creds == nil || m.selectedProject == nil || m.mrActionBusy {
+		return m, nil
+	}
+
+	switch action {
+	case mrActionOpen:
+		m.showMRActions = false
+		return m.handleOpenAction(buildMROpenOptions(mr))
+	case mrActionCopyBranch:
+		if err := copyToClipboard(mr.SourceBranch); err != nil {
+			m.mrActionResult, m.mrActionFailed = "Cannot copy: "+err.Error(), true
+		} else {
+			m.mrActionResult, m.mrActionFailed = "Copied "+mr.SourceBranch, false
+		}
+		return m, nil
+	case mrActionComment:
+		return m, m.editText(editMRComment, "")
+	case mrActionBlocklist:
+		// The reason of a block is written in the editor
+		if _, blocked := m.blockedMRs[mr.IID]; blocked {
+			return m, m.toggleMRBlocklist("")
+		}
+		return m, m.editText(editMRBlockReason, "")
+	}
+
+	m.mrActionBusy = true
+	m.mrActionResult = ""
+	client := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	target := fmt.Sprintf("project %d !%d", projectID, mr.IID)
+	id, iid, title, draft := mr.ID, mr.IID, mr.Title, mr.Draft
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		done := mrActionDoneMsg{action: action, id: id}
+		switch action {
+		case mrActionApprove:
+			done.err = client.ApproveMergeRequest(projectID, iid)
+			recordAudit(auditMRApprove, target, "", done.err)
+		case mrActionRebase:
+			done.err = client.RebaseMergeRequest(projectID, iid)
+			recordAudit(auditMRRebase, target, "", done.err)
+		case mrActionToggleDraft:
+			done.title, done.err = client.SetMergeRequestDraft(projectID, iid, title, !draft)
+			recordAudit(auditMRDraft, target, done.title, done.err)
+		}
+		return done
+	})
+}
+
+// postMRComment posts a comment written in the editor on the MR of the menu. While the forge is
+// unreachable the comment is queued (see offline.go).
+func (m *model) postMRComment(body string) tea.Cmd {
+	mr := m.mrActionTarget
+	if body == "" || mr == nil || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	m.showMRActions = true
+	m.mrActionBusy = true
+	m.mrActionResult = ""
+	client := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	id, iid := mr.ID, mr.IID
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		done := mrActionDoneMsg{action: mrActionComment, id: id}
+		_, done.err = client.CreateMergeRequestNote(projectID, iid, body)
+		recordAudit(auditMRNote, fmt.Sprintf("project %d !%d", projectID, iid), body, done.err)
+		if isNetworkError(done.err) {
+			key := fmt.Sprintf("mr_comment:%d!%d:%d", projectID, iid, time.Now().UnixNano())
+			done.err = queueAction(queuedAction{Kind: queuedMRNote, Key: key, ProjectID: projectID, MRIID: iid, Body: body})
+			done.queued = done.err == nil
+		}
+		return done
+	})
+}
+
+// handleMRActionDone shows the result of a forge action in the menu and applies a changed title
+func (m *model) handleMRActionDone(msg mrActionDoneMsg) {
+	if msg.action == mrActionBlocklist && msg.err == nil {
+		m.refreshBlockedMRs()
+	}
+	if m.mrActionTarget == nil || m.mrActionTarget.ID != msg.id {
+		return
+	}
+	m.mrActionBusy = false
+	m.mrActionFailed = msg.err != nil
+	if msg.err != nil {
+		_, blocked := m.blockedMRs[m.mrActionTarget.IID]
+		m.mrActionResult = strings.TrimSuffix(mrActionLabel(msg.action, m.mrActionTarget, blocked), "…") + " failed: " + msg.err.Error()
+		return
+	}
+
+	switch msg.action {
+	case mrActionApprove:
+		m.mrActionResult = "Approved"
+	case mrActionRebase:
+		m.mrActionResult = "Rebase started"
+	case mrActionToggleDraft:
+		// The list item shares the MR, so the list shows the new title and draft state
+		m.mrActionTarget.Draft = !m.mrActionTarget.Draft
+		m.mrActionTarget.Title = msg.title
+		if m.mrActionTarget.Draft {
+			delete(m.selectedMRs, m.mrActionTarget.IID) // Drafts cannot be released
+			m.saveSelection()
+			m.mrActionResult = "Marked as draft"
+		} else {
+			m.mrActionResult = "Marked as ready"
+		}
+		if m.ready {
+			m.viewport.SetContent(m.renderMarkdown())
+		}
+	case mrActionComment:
+		m.mrActionResult = "Comment posted"
+		if msg.queued {
+			m.mrActionResult = "Offline: comment queued"
+		}
+	case mrActionBlocklist:
+		m.mrActionResult = "Removed from the blocklist"
+		if msg.blocked {
+			m.mrActionResult = "Added to the blocklist"
+		}
+		if m.ready {
+			m.viewport.SetContent(m.renderMarkdown())
+		}
+	}
+}
+
+// overlayMRActions renders the quick actions menu
+func (m model) overlayMRActions(background string) string {
+	mr := m.mrActionTarget
+	if mr == nil {
+		return background
+	}
+	var sb strings.Builder
+
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render(fmt.Sprintf("!%d", mr.IID)))
+	sb.WriteString(" " + helpStyle.Render(truncateWithEllipsis(mr.Title, 40)))
+	sb.WriteString("\n\n")
+
+	_, blocked := m.blockedMRs[mr.IID]
+	for i, key := range mrActionKeys {
+		label := key + "  " + mrActionLabel(mrAction(i), mr, blocked)
+		if i == m.mrActionsIndex {
+			sb.WriteString(commandItemSelectedStyle.Render("▸ " + label))
+		} else {
+			sb.WriteString(commandItemStyle.Render("  " + label))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case m.mrActionBusy:
+		sb.WriteString(m.spinner.View() + " " + helpStyle.Render("Working…"))
+		sb.WriteString("\n\n")
+	case m.mrActionResult != "":
+		color := currentTheme.Success
+		if m.mrActionFailed {
+			color = currentTheme.Error
+		}
+		sb.WriteString(lipgloss.NewStyle().Foreground(color).Render(m.mrActionResult))
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(helpStyle.Render("j/k: nav • enter or key: run • C+q: close"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 50, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 60,
+		Style:    commandMenuStyle,
+	}
+
+	modalContent := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modalContent, background, m.width, m.height)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// mrAnnounceNotifier comments on every MR of a completed release that it was released, e.g.
+// "Released to STAGE in v2.4.0", so MR authors are notified by the forge without anyone writing
+// updates by hand. Comments that cannot be posted while the forge is unreachable are queued
+// (see offline.go).
+type mrAnnounceNotifier struct{}
+
+func (mrAnnounceNotifier) send(event ReleaseEvent) error {
+	if event.Kind != releaseEventCompleted || len(event.MRIIDs) == 0 {
+		return nil
+	}
+	creds, err := LoadCredentials()
+	if err != nil {
+		return fmt.Errorf("no credentials: %w", err)
+	}
+	client := NewForge(*creds)
+	body := event.announceComment(tagURL(creds.Forge, event.ProjectURL, event.Tag))
+
+	var errs []error
+	for _, iid := range event.MRIIDs {
+		target := fmt.Sprintf("project %d !%d", event.ProjectID, iid)
+		_, err := client.CreateMergeRequestNote(event.ProjectID, iid, body)
+		recordAudit(auditMRNote, target, body, err)
+		if isNetworkError(err) {
+			key := fmt.Sprintf("mr_announce:%d!%d:%s", event.ProjectID, iid, event.Tag)
+			err = queueAction(queuedAction{Kind: queuedMRNote, Key: key, ProjectID: event.ProjectID, MRIID: iid, Body: body})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("!%d: %w", iid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// announceComment renders the comment announcing the release on its MRs; tagLink is the web page
+// of the tag, if known
+func (e ReleaseEvent) announceComment(tagLink string) string {
+	name := e.Tag
+	if name == "" {
+		name = e.Version
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s Released to **%s** in `%s`", eventEmoji(releaseEventStarted), e.Environment, name)
+	if tagLink != "" {
+		fmt.Fprintf(&b, " ([tag](%s))", tagLink)
+	}
+	if e.ReleaseMRURL != "" {
+		fmt.Fprintf(&b, " with the [release MR](%s)", e.ReleaseMRURL)
+	}
+	if e.Rollout != nil {
+		fmt.Fprintf(&b, "\n\n**Rollout:** %s", markdownEscape(e.Rollout.String()))
+	}
+	b.WriteString("\n\n<sub>Posted by relix</sub>\n")
+	return b.String()
+}
+
+// tagURL returns the web page of a tag of a project on a forge, "" without a project URL or tag
+func tagURL(forge, projectURL, tag string) string {
+	if projectURL == "" || tag == "" {
+		return ""
+	}
+	projectURL = strings.TrimSuffix(projectURL, "/")
+	tag = url.PathEscape(tag)
+	switch forge {
+	case forgeGitHub:
+		return projectURL + "/releases/tag/" + tag
+	case forgeGitea:
+		return projectURL + "/src/tag/" + tag
+	case forgeBitbucket:
+		return strings.TrimSuffix(projectURL, "/browse") + "/browse?at=refs%2Ftags%2F" + tag
+	}
+	return projectURL + "/-/tags/" + tag
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// The MR blocklist of a project lists MRs that must not be released, e.g. known-broken features
+// waiting for a feature flag, along with the reason. It is the mr_blocklist of .restitcher.yaml,
+// so it is shared by everyone releasing the project, and is edited from the MR quick actions menu
+// ("b"), which commits the change to the default branch (GitLab only). A blocked MR is marked on
+// the list and cannot be selected; selecting an iteration, the voted MRs or a saved selection
+// leaves it out, it is deselected when the blocklist is read, and a headless plan listing it is
+// refused.
+
+// BlockedMR is an entry of the MR blocklist
+type BlockedMR struct {
+	IID    int    `yaml:"iid"`
+	Reason string `yaml:"reason"`
+}
+
+// blockedMRReason returns why an MR of a project is on its blocklist; ok is false if it is not
+func blockedMRReason(projectID, iid int) (reason string, ok bool) {
+	settings := projectRepoSettings(projectID)
+	if settings == nil {
+		return "", false
+	}
+	for _, blocked := range settings.MRBlocklist {
+		if blocked.IID == iid {
+			return blocked.Reason, true
+		}
+	}
+	return "", false
+}
+
+// blockedMRError returns the error refusing a blocked MR
+func blockedMRError(iid int, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("MR !%d is on the project's blocklist", iid)
+	}
+	return fmt.Errorf("MR !%d is on the project's blocklist: %s", iid, reason)
+}
+
+// refreshBlockedMRs reads the blocklist of the selected project into the one marked on the list
+// and deselects the blocked MRs
+func (m *model) refreshBlockedMRs() {
+	if m.blockedMRs == nil {
+		m.blockedMRs = make(map[int]string)
+	} else {
+		clear(m.blockedMRs)
+	}
+	if settings := projectRepoSettings(m.selectedProjectID()); settings != nil {
+		for _, blocked := range settings.MRBlocklist {
+			m.blockedMRs[blocked.IID] = blocked.Reason
+		}
+	}
+
+	deselected := false
+	for iid := range m.blockedMRs {
+		if m.selectedMRs[iid] {
+			delete(m.selectedMRs, iid)
+			deselected = true
+		}
+	}
+	if deselected {
+		m.saveSelection()
+	}
+}
+
+// setRepoBlocklist returns .restitcher.yaml with its mr_blocklist replaced by list, keeping the
+// rest of the file and its comments
+func setRepoBlocklist(data []byte, list []BlockedMR) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", repoSettingsFileName, err)
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a mapping", repoSettingsFileName)
+	}
+
+	var value yaml.Node
+	if err := value.Encode(list); err != nil {
+		return nil, err
+	}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "mr_blocklist" {
+			continue
+		}
+		found = true
+		if len(list) == 0 {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		} else {
+			root.Content[i+1] = &value
+		}
+		break
+	}
+	if !found && len(list) > 0 {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "mr_blocklist"}
+		root.Content = append(root.Content, key, &value)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// updateMRBlocklistAttempts is how many times the blocklist is read and committed again when
+// someone else changes .restitcher.yaml in between
+const updateMRBlocklistAttempts = 3
+
+// updateMRBlocklist adds an MR to the blocklist of a project, or removes it with an empty reason,
+// committing .restitcher.yaml to the default branch. The file is read anew first and committed
+// against the commit it was read at, so entries added by others meanwhile are kept; if the file
+// changes in between, the update is retried on the new content.
+func updateMRBlocklist(client *GitLabClient, projectID, iid int, reason string) error {
+	branch, err := client.GetDefaultBranch(strconv.Itoa(projectID))
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err := commitMRBlocklist(client, projectID, branch, iid, reason)
+		if !errors.Is(err, errRepositoryFileChanged) || attempt == updateMRBlocklistAttempts {
+			return err
+		}
+	}
+}
+
+// commitMRBlocklist makes one attempt of updateMRBlocklist on a branch
+func commitMRBlocklist(client *GitLabClient, projectID int, branch string, iid int, reason string) error {
+	data, lastCommitID, err := client.GetRepositoryFileBlob(projectID, branch, repoSettingsFileName)
+	if err != nil {
+		return err
+	}
+	settings := &RepoSettings{}
+	if data != nil {
+		if settings, err = parseRepoSettings(data); err != nil {
+			return fmt.Errorf("fix the file first: %w", err)
+		}
+	}
+
+	var list []BlockedMR
+	for _, blocked := range settings.MRBlocklist {
+		if blocked.IID != iid {
+			list = append(list, blocked)
+		}
+	}
+	message := fmt.Sprintf("Remove !%d from the MR blocklist", iid)
+	if reason != "" {
+		list = append(list, BlockedMR{IID: iid, Reason: reason})
+		message = fmt.Sprintf("Add !%d to the MR blocklist: %s", iid, reason)
+	}
+	updated, err := setRepoBlocklist(data, list)
+	if err != nil {
+		return err
+	}
+	if settings, err = parseRepoSettings(updated); err != nil {
+		return err
+	}
+	if err := client.CommitRepositoryFile(projectID, branch, repoSettingsFileName, updated, lastCommitID, message); err != nil {
+		return err
+	}
+	repoSettingsCache.Store(projectID, settings)
+	return nil
+}
+
+// toggleMRBlocklist adds the MR of the quick actions menu to the blocklist with reason, or removes
+// it if it is blocked
+func (m *model) toggleMRBlocklist(reason string) tea.Cmd {
+	mr := m.mrActionTarget
+	if mr == nil || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	client, ok := NewForge(*m.creds).(*GitLabClient)
+	if !ok {
+		m.showMRActions = true
+		m.mrActionResult = "The blocklist is edited from relix on GitLab only; edit " + repoSettingsFileName + " instead"
+		m.mrActionFailed = true
+		return nil
+	}
+	if _, blocked := m.blockedMRs[mr.IID]; blocked {
+		reason = ""
+	} else if reason = strings.Join(strings.Fields(reason), " "); reason == "" {
+		return nil // Not blocked without a reason
+	}
+
+	m.showMRActions = true
+	m.mrActionBusy = true
+	m.mrActionResult = ""
+	projectID := m.selectedProject.ID
+	id, iid := mr.ID, mr.IID
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		done := mrActionDoneMsg{action: mrActionBlocklist, id: id, blocked: reason != ""}
+		done.err = updateMRBlocklist(client, projectID, iid, reason)
+		recordAudit(auditMRBlocklist, fmt.Sprintf("project %d !%d", projectID, iid), reason, done.err)
+		return done
+	})
+}
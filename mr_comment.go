@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mrCommentSteps are the release steps listed in the progress comment, in execution order.
+// The steps waiting for a button press are shown on the step they wait for.
+var mrCommentSteps = []struct {
+	step  ReleaseStep
+	label string
+}{
+	{ReleaseStepGitFetch, "Fetch remote branches"},
+	{ReleaseStepCheckoutRoot, "Check out the source branch"},
+	{ReleaseStepMergeBranches, "Merge the MR branches"},
+	{ReleaseStepCheckoutEnv, "Check out the environment branch"},
+	{ReleaseStepCopyContent, "Copy the release content"},
+	{ReleaseStepCommit, "Commit"},
+	{ReleaseStepPushBranches, "Push branches"},
+	{ReleaseStepPushAndCreateMR, "Create the release MR"},
+	{ReleaseStepPushRootBranches, "Tag and push the root branches"},
+	{ReleaseStepSwitchToRoot, "Switch back to the root branch"},
+	{ReleaseStepDeployPipeline, "Run the deployment pipeline"},
+}
+
+// Progress comments by release MR ("<project ID>!<MR IID>"). Events are sent one at a time
+// (see sendReleaseEvent), so the comment is created once and then edited.
+var (
+	mrCommentMu    sync.Mutex
+	mrCommentNotes = map[string]int{}
+)
+
+// mrCommentNotifier mirrors the progress of a release into a single comment on the release MR,
+// edited on every event, so people watching the MR see the release without the operator's
+// terminal. Events before the release MR exists are skipped; the first comment covers them.
+// While the forge is unreachable the latest comment is queued (see offline.go).
+type mrCommentNotifier struct{}
+
+func (mrCommentNotifier) send(event ReleaseEvent) error {
+	if event.ReleaseMRIID == 0 {
+		return nil
+	}
+	creds, err := LoadCredentials()
+	if err != nil {
+		return fmt.Errorf("no credentials: %w", err)
+	}
+	client := NewForge(*creds)
+	body := event.progressComment(time.Now())
+	key := fmt.Sprintf("%d!%d", event.ProjectID, event.ReleaseMRIID)
+	target := fmt.Sprintf("project %d !%d", event.ProjectID, event.ReleaseMRIID)
+
+	mrCommentMu.Lock()
+	noteID, ok := mrCommentNotes[key]
+	if event.Kind == releaseEventCompleted || event.Kind == releaseEventAborted {
+		delete(mrCommentNotes, key) // Last update of the release
+	}
+	mrCommentMu.Unlock()
+
+	// A queued comment is replaced rather than posted after it, so the MR gets a single comment
+	queued := queuedAction{Kind: queuedMRNote, Key: "mr_note:" + key, ProjectID: event.ProjectID, MRIID: event.ReleaseMRIID, NoteID: noteID, Body: body}
+	if isQueued(queued.Key) {
+		return queueAction(queued)
+	}
+
+	if ok {
+		err := client.UpdateMergeRequestNote(event.ProjectID, event.ReleaseMRIID, noteID, body)
+		recordAudit(auditMRNoteUpdate, target, fmt.Sprintf("progress comment %d: %s", noteID, event.Kind), err)
+		if isNetworkError(err) {
+			return queueAction(queued)
+		}
+		return err
+	}
+
+	noteID, err = client.CreateMergeRequestNote(event.ProjectID, event.ReleaseMRIID, body)
+	recordAudit(auditMRNote, target, body, err)
+	if isNetworkError(err) {
+		return queueAction(queued)
+	}
+	if err != nil {
+		return err
+	}
+	rememberMRComment(event.ProjectID, event.ReleaseMRIID, noteID)
+	return nil
+}
+
+// rememberMRComment records the progress comment of a release MR, so later events edit it
+func rememberMRComment(projectID, mrIID, noteID int) {
+	mrCommentMu.Lock()
+	mrCommentNotes[fmt.Sprintf("%d!%d", projectID, mrIID)] = noteID
+	mrCommentMu.Unlock()
+}
+
+// progressComment renders the release as a Markdown checklist of its steps
+func (e ReleaseEvent) progressComment(now time.Time) string {
+	current := ReleaseStepIdle
+	for step, name := range releaseStepNames {
+		if name == e.Step {
+			current = step
+		}
+	}
+	// Step events report the step that just finished; the others the step the release is at
+	finished := e.Kind == releaseEventStep
+
+	status := "in progress"
+	switch e.Kind {
+	case releaseEventWaiting:
+		status = "waiting for confirmation"
+	case releaseEventFailed:
+		status = "failed"
+	case releaseEventSuspended:
+		status = "suspended"
+	case releaseEventCompleted:
+		status = "completed"
+	case releaseEventAborted:
+		status = "aborted"
+	}
+	name := e.Tag
+	if name == "" {
+		name = e.Version
+	}
+
+	emoji := eventEmoji(e.Kind)
+	if finished {
+		emoji = eventEmoji(releaseEventStarted)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s Release `%s` to %s: %s\n\n", emoji, name, e.Environment, status)
+	if e.Reason != "" && (e.Kind == releaseEventFailed || e.Kind == releaseEventSuspended) {
+		fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(strings.TrimSpace(e.Reason), "\n", "\n> "))
+	}
+	if e.Rollout != nil {
+		fmt.Fprintf(&b, "**Rollout:** %s\n\n", markdownEscape(e.Rollout.String()))
+	}
+
+	next := true // The first step not done yet is the one running or waiting
+	for _, s := range mrCommentSteps {
+		if s.step == ReleaseStepDeployPipeline && e.DeployProject == "" {
+			continue
+		}
+		mark := "⬜"
+		switch {
+		case e.Kind == releaseEventCompleted || s.step < current || (s.step == current && finished):
+			mark = "✅"
+		case s.step == current && (e.Kind == releaseEventFailed || e.Kind == releaseEventSuspended):
+			mark = "❌"
+			next = false
+		case next && e.Kind != releaseEventAborted:
+			mark = "⏳"
+			if e.Kind == releaseEventWaiting {
+				mark = "⏸️"
+			}
+			next = false
+		}
+		fmt.Fprintf(&b, "- %s %s\n", mark, s.label)
+	}
+
+	fmt.Fprintf(&b, "\nMRs: %d", len(e.MRBranches))
+	for i, branch := range e.MRBranches {
+		if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+			fmt.Fprintf(&b, " · [%s](%s)", markdownEscape(branch), e.MRURLs[i])
+		}
+	}
+	fmt.Fprintf(&b, "\n\n<sub>Updated by relix at %s</sub>\n", now.UTC().Format("2006-01-02 15:04 UTC"))
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MRs are classified by size from the counts of their details: the class of the changed files and
+// the class of the commits, whichever is larger. The list shows the class of each MR whose details
+// are loaded, and the confirmation screen warns when a release contains several XL MRs, which are
+// a common cause of painful conflicts and rollbacks.
+type mrSize int
+
+const (
+	mrSizeUnknown mrSize = iota // Details not loaded yet
+	mrSizeS
+	mrSizeM
+	mrSizeL
+	mrSizeXL
+)
+
+// Upper bounds of the S, M and L classes; anything above is XL
+var (
+	mrSizeFileLimits   = [3]int{5, 20, 50}
+	mrSizeCommitLimits = [3]int{3, 10, 25}
+)
+
+// maxXLMRs is the number of XL MRs a release can contain without a warning
+const maxXLMRs = 1
+
+func (s mrSize) String() string {
+	switch s {
+	case mrSizeS:
+		return "S"
+	case mrSizeM:
+		return "M"
+	case mrSizeL:
+		return "L"
+	case mrSizeXL:
+		return "XL"
+	}
+	return ""
+}
+
+// classifyMRSize returns the class of an MR changing files files in commits commits
+func classifyMRSize(files, commits int) mrSize {
+	class := func(n int, limits [3]int) mrSize {
+		for i, limit := range limits {
+			if n <= limit {
+				return mrSizeS + mrSize(i)
+			}
+		}
+		return mrSizeXL
+	}
+	return max(class(files, mrSizeFileLimits), class(commits, mrSizeCommitLimits))
+}
+
+// mrSizeOf returns the size class of an MR, or mrSizeUnknown until its details are loaded.
+// GitLab reports large change counts as e.g. "1000+".
+func mrSizeOf(mr *MergeRequestDetails) mrSize {
+	if !mr.DetailsLoaded {
+		return mrSizeUnknown
+	}
+	files, _ := strconv.Atoi(strings.TrimSuffix(mr.ChangesCount, "+"))
+	return classifyMRSize(files, mr.CommitsCount)
+}
+
+// color returns the theme color of the size class
+func (s mrSize) color() lipgloss.Color {
+	switch s {
+	case mrSizeS:
+		return currentTheme.Success
+	case mrSizeL:
+		return currentTheme.Warning
+	case mrSizeXL:
+		return currentTheme.Error
+	}
+	return currentTheme.Foreground
+}
+
+// xlMRWarning returns the confirmation screen warning about the XL MRs of a release, or "" when
+// it contains at most maxXLMRs of them
+func xlMRWarning(mrs []*MergeRequestDetails) string {
+	var branches []string
+	for _, mr := range mrs {
+		if mrSizeOf(mr) == mrSizeXL {
+			branches = append(branches, mr.SourceBranch)
+		}
+	}
+	if len(branches) <= maxXLMRs {
+		return ""
+	}
+	return fmt.Sprintf("*%d XL MRs* in this release (%s) ~~— large MRs are a common cause of painful conflicts and rollbacks, consider releasing some of them separately~~",
+		len(branches), strings.Join(branches, ", "))
+}
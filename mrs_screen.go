@@ -3,13 +3,16 @@ package main
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 )
@@ -125,11 +128,13 @@ func truncateWithEllipsis(text string, maxWidth int) string {
 
 // mrDelegate is a custom delegate for displaying MR items with 2-line titles
 type mrDelegate struct {
-	selectedMRs map[int]bool
+	selectedMRs  map[int]bool
+	releaseVotes map[int]bool
+	blockedMRs   map[int]string
 }
 
-func newMRDelegate(selectedMRs map[int]bool) mrDelegate {
-	return mrDelegate{selectedMRs: selectedMRs}
+func newMRDelegate(selectedMRs, releaseVotes map[int]bool, blockedMRs map[int]string) mrDelegate {
+	return mrDelegate{selectedMRs: selectedMRs, releaseVotes: releaseVotes, blockedMRs: blockedMRs}
 }
 
 func (d mrDelegate) Height() int                             { return 3 }
@@ -177,6 +182,18 @@ func (d mrDelegate) Render(w io.Writer, m list.Model, index int, item list.Item)
 	titleStyle := buildTitleStyle(colors, isSelected)
 	descStyle := buildDescStyle(colors, isSelected)
 
+	// Underline characters matched by the filter; positions refer to the space-normalized title
+	var matches []int
+	if m.FilterState() != list.Unfiltered {
+		matches = m.MatchesForItem(index)
+	}
+	highlight := func(line string, offset int) string {
+		if len(matches) == 0 {
+			return line
+		}
+		return highlightMatches(line, shiftPositions(matches, offset), lipgloss.NewStyle().Foreground(colors.titleFg))
+	}
+
 	// Prepare marker
 	marker := ""
 	wrapWidth := contentWidth
@@ -199,18 +216,51 @@ func (d mrDelegate) Render(w io.Writer, m list.Model, index int, item list.Item)
 		}
 	}
 
-	// Prepare description
-	desc := truncateWithEllipsis(mr.Description(), contentWidth)
+	// Prepare description; the size class, the release vote, the dependency update mark and a flag
+	// for MRs that cannot be merged or are blocked follow it
+	size := ""
+	if s := mrSizeOf(mr.MR()); s != mrSizeUnknown {
+		size = " • " + s.String()
+	}
+	vote := ""
+	if d.releaseVotes[mr.MR().IID] {
+		vote = " • voted"
+	}
+	deps := ""
+	if isDependencyUpdate(mr.MR()) {
+		deps = " • dependency update"
+	}
+	flag := ""
+	if mr.MR().MergeStatus == "cannot_be_merged" {
+		flag = " • cannot be merged"
+	}
+	if _, blocked := d.blockedMRs[mr.MR().IID]; blocked {
+		flag += " • blocked"
+	}
+	desc := truncateWithEllipsis(mr.Description(), max(contentWidth-ansi.StringWidth(size+vote+deps+flag), 0))
+	if size != "" {
+		desc += lipgloss.NewStyle().Foreground(mrSizeOf(mr.MR()).color()).Render(size)
+	}
+	if vote != "" {
+		desc += lipgloss.NewStyle().Foreground(currentTheme.Success).Render(vote)
+	}
+	if deps != "" {
+		desc += lipgloss.NewStyle().Foreground(currentTheme.Notion).Render(deps)
+	}
+	if flag != "" {
+		desc += lipgloss.NewStyle().Foreground(currentTheme.Error).Render(flag)
+	}
 
 	// Build rendered lines
 	var lines []string
 
 	// First title line with marker
-	lines = append(lines, titleStyle.Render(padLine(marker+titleLines[0], contentWidth)))
+	lines = append(lines, titleStyle.Render(padLine(marker+highlight(titleLines[0], 0), contentWidth)))
 
-	// Second title line if exists
+	// Second title line if exists (wrapped lines are joined by single spaces)
 	if len(titleLines) > 1 {
-		lines = append(lines, titleStyle.Render(padLine(titleLines[1], contentWidth)))
+		offset := utf8.RuneCountInString(titleLines[0]) + 1
+		lines = append(lines, titleStyle.Render(padLine(highlight(titleLines[1], offset), contentWidth)))
 	}
 
 	// Description line
@@ -262,11 +312,19 @@ func (m *model) initListScreen() {
 			delete(m.selectedMRs, k)
 		}
 	}
-	l := list.New([]list.Item{}, newMRDelegate(m.selectedMRs), 0, 0)
+	if m.releaseVotes == nil {
+		m.releaseVotes = make(map[int]bool)
+	} else {
+		clear(m.releaseVotes)
+	}
+	m.refreshBlockedMRs()
+	l := list.New([]list.Item{}, newMRDelegate(m.selectedMRs, m.releaseVotes, m.blockedMRs), 0, 0)
 	l.Title = "Open MRs"
 	l.Styles.Title = lipgloss.NewStyle().Bold(true).Background(currentTheme.Accent).Foreground(currentTheme.AccentForeground).PaddingLeft(1).PaddingRight(1)
 	l.SetShowHelp(false)
 	l.SetFilteringEnabled(true)
+	l.Filter = fuzzyFilter
+	l.KeyMap.Filter.SetKeys("f") // "/" opens the command menu
 	l.SetShowStatusBar(false)
 
 	// Disable default quit keybindings (q, esc)
@@ -279,6 +337,18 @@ func (m *model) initListScreen() {
 	m.list = l
 	m.ready = false
 	m.mrsLoaded = false
+	m.mrsAll = nil
+	m.mrPages, m.mrPage = 0, listPage{}
+	m.mrsLoadingMore = false
+	m.mrsSyncedAt, m.mrsFullSyncAt = time.Time{}, time.Time{}
+	m.selectionDraftLoaded = false
+	m.selectionDraftPending = nil
+	m.selectionDraftSaved = nil
+	m.releaseVotesLoaded = false
+	m.iterationLoading = false
+	m.iterationSelected = ""
+	m.dependencyLoading = false
+	m.dependencySelected = ""
 }
 
 // fetchMRs creates a command to fetch MRs from GitLab
@@ -288,18 +358,287 @@ func (m *model) fetchMRs() tea.Cmd {
 			return fetchMRsMsg{err: fmt.Errorf("no credentials")}
 		}
 
-		client := NewGitLabClient(m.creds.GitLabURL, m.creds.Token)
+		client := NewForge(*m.creds)
+		started := time.Now()
 
 		var mrs []*MergeRequestDetails
 		var err error
+		projectID := 0
+		page := listPage{Total: -1}
 
 		if m.selectedProject != nil {
-			mrs, err = client.GetProjectMergeRequests(m.selectedProject.ID)
+			projectID = m.selectedProject.ID
+			mrs, page, err = client.GetProjectMergeRequestsPage(projectID, 1)
+			if err == nil {
+				saveCachedMRs(m.creds.GitLabURL, projectID, mrs)
+			}
 		} else {
 			mrs, err = client.GetOpenMergeRequests()
 		}
 
-		return fetchMRsMsg{mrs: mrs, err: err}
+		return fetchMRsMsg{projectID: projectID, mrs: mrs, err: err, pages: 1, page: page, syncedAt: started, fullSync: true}
+	}
+}
+
+// loadMRs shows the cached MRs of the selected project right away and refreshes them in the background.
+// Without a cache it falls back to the loading modal.
+func (m *model) loadMRs() tea.Cmd {
+	if m.creds != nil && m.selectedProject != nil {
+		if mrs, ok := loadCachedMRs(m.creds.GitLabURL, m.selectedProject.ID); ok {
+			m.mrsLoaded = true
+			m.mrsLoadError = false
+			m.mrsCached = true
+			return tea.Batch(m.setMRItems(mrs), m.fetchMRs())
+		}
+	}
+	m.loadingMRs = true
+	return tea.Batch(m.spinner.Tick, m.fetchMRs())
+}
+
+// loadMoreMRs fetches the next page of the project's MRs once the cursor is on the last listed
+// MR and the forge reported more. Filtered, cached or loading lists do not load more.
+func (m *model) loadMoreMRs() tea.Cmd {
+	if !m.mrPage.HasMore || m.mrsLoadingMore || m.mrsCached || m.loadingMRs || m.creds == nil || m.selectedProject == nil ||
+		m.list.FilterState() != list.Unfiltered || m.list.Index() < len(m.list.Items())-1 {
+		return nil
+	}
+	m.mrsLoadingMore = true
+	m.updateMRListTitle()
+
+	creds := *m.creds
+	projectID := m.selectedProject.ID
+	page := m.mrPages + 1
+	return func() tea.Msg {
+		mrs, info, err := NewForge(creds).GetProjectMergeRequestsPage(projectID, page)
+		return fetchMoreMRsMsg{projectID: projectID, page: page, mrs: mrs, info: info, err: err}
+	}
+}
+
+// appendMRPage adds a page of MRs to the ones loaded before. MRs opened or closed meanwhile
+// shift the pages, so MRs already loaded are skipped.
+func appendMRPage(loaded, page []*MergeRequestDetails) []*MergeRequestDetails {
+	seen := make(map[int]bool, len(loaded))
+	all := make([]*MergeRequestDetails, 0, len(loaded)+len(page))
+	for _, mr := range loaded {
+		seen[mr.ID] = true
+		all = append(all, mr)
+	}
+	for _, mr := range page {
+		if !seen[mr.ID] {
+			all = append(all, mr)
+		}
+	}
+	return all
+}
+
+// mrFilter keeps the MRs matching the MR filters of the config
+type mrFilter struct {
+	targetBranch string
+	sourceRegex  *regexp.Regexp
+}
+
+// loadMRFilter reads the MR filters from the config, with the project's settings applied
+func loadMRFilter(projectID int) (mrFilter, error) {
+	config, err := LoadProjectConfig(projectID)
+	if err != nil {
+		return mrFilter{}, err
+	}
+	f := mrFilter{targetBranch: strings.TrimSpace(config.MRTargetBranch)}
+	if expr := strings.TrimSpace(config.MRSourceBranchRegex); expr != "" {
+		if f.sourceRegex, err = regexp.Compile(expr); err != nil {
+			return mrFilter{}, fmt.Errorf("invalid MR source branch regex: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// active reports whether the filter hides any MRs
+func (f mrFilter) active() bool {
+	return f.targetBranch != "" || f.sourceRegex != nil
+}
+
+// apply returns the MRs passing the filter
+func (f mrFilter) apply(mrs []*MergeRequestDetails) []*MergeRequestDetails {
+	if !f.active() {
+		return mrs
+	}
+	kept := make([]*MergeRequestDetails, 0, len(mrs))
+	for _, mr := range mrs {
+		if f.targetBranch != "" && mr.TargetBranch != f.targetBranch {
+			continue
+		}
+		if f.sourceRegex != nil && !f.sourceRegex.MatchString(mr.SourceBranch) {
+			continue
+		}
+		kept = append(kept, mr)
+	}
+	return kept
+}
+
+// String describes the filter for the list title, e.g. "→ develop, ^feature/"
+func (f mrFilter) String() string {
+	var parts []string
+	if f.targetBranch != "" {
+		parts = append(parts, "→ "+f.targetBranch)
+	}
+	if f.sourceRegex != nil {
+		parts = append(parts, f.sourceRegex.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// setMRItems fills the MR list, keeping the cursor on the same MR and dropping selections of MRs that are gone.
+// It returns the command loading details of the highlighted MR.
+func (m *model) setMRItems(mrs []*MergeRequestDetails) tea.Cmd {
+	// Hide MRs outside the configured filters; a broken filter shows everything
+	m.mrsAll = mrs
+	filter, _ := loadMRFilter(m.selectedProjectID())
+	mrs = filter.apply(mrs)
+
+	// Sort MRs: non-drafts first (by date newest first), then drafts (by date newest first)
+	sort.Slice(mrs, func(i, j int) bool {
+		// Both drafts or both non-drafts: sort by date (newest first)
+		if mrs[i].Draft == mrs[j].Draft {
+			return mrs[i].CreatedAt.After(mrs[j].CreatedAt)
+		}
+		// Drafts go last
+		return !mrs[i].Draft && mrs[j].Draft
+	})
+
+	focusedIID := 0
+	if item, ok := m.list.SelectedItem().(mrListItem); ok {
+		focusedIID = item.mr.IID
+	}
+
+	items := make([]list.Item, len(mrs))
+	present := make(map[int]bool, len(mrs))
+	focusIndex := 0
+	for i, mr := range mrs {
+		items[i] = mrListItem{mr: mr}
+		present[mr.IID] = true
+		if mr.IID == focusedIID {
+			focusIndex = i
+		}
+	}
+	// An MR picked in the global search is highlighted once listed, or given up on a fresh list
+	if m.searchMRIID != 0 {
+		for i, mr := range mrs {
+			if mr.IID == m.searchMRIID {
+				focusIndex = i
+				m.searchMRIID = 0
+			}
+		}
+		if !m.mrsCached {
+			m.searchMRIID = 0
+		}
+	}
+	m.list.SetItems(items)
+	fitPagination(&m.list)
+	m.list.Select(focusIndex)
+
+	m.applySelectionDraft(mrs)
+	for iid := range m.selectedMRs {
+		if !present[iid] {
+			delete(m.selectedMRs, iid)
+		}
+	}
+	m.saveSelection()
+
+	m.updateMRListTitle()
+
+	if m.ready {
+		m.viewport.SetContent(m.renderMarkdown())
+	}
+	return tea.Batch(m.loadHighlightedMRDetails(), m.loadReleaseVotes(m.mrsAll))
+}
+
+// updateMRListTitle sets the list title: "Open MRs (count)", the config filters and how many
+// of the project's MRs are loaded, e.g. "Open MRs (3 of 200) · → develop · showing 200 of 347"
+func (m *model) updateMRListTitle() {
+	filter, filterErr := loadMRFilter(m.selectedProjectID())
+	shown := len(m.list.Items())
+	total := len(m.mrsAll)
+
+	m.list.Title = fmt.Sprintf("Open MRs (%d)", shown)
+	if filter.active() {
+		m.list.Title = fmt.Sprintf("Open MRs (%d of %d) · %s", shown, total, filter)
+	} else if filterErr != nil {
+		m.list.Title += " · " + filterErr.Error()
+	}
+	if m.mrPage.HasMore {
+		if m.mrPage.Total > total {
+			m.list.Title += fmt.Sprintf(" · showing %d of %d", total, m.mrPage.Total)
+		} else {
+			m.list.Title += fmt.Sprintf(" · showing %d, more below", total)
+		}
+	}
+	if m.mrsLoadingMore {
+		m.list.Title += " · loading more…"
+	}
+	if m.iterationLoading {
+		m.list.Title += " · selecting iteration…"
+	} else if m.iterationSelected != "" {
+		m.list.Title += " · iteration " + m.iterationSelected
+	}
+	if m.dependencyLoading {
+		m.list.Title += " · checking dependency updates…"
+	} else if m.dependencySelected != "" {
+		m.list.Title += " · dependency updates " + m.dependencySelected
+	}
+	if m.mrsCached {
+		m.list.Title += " · cached, refreshing…"
+	} else if m.offline {
+		m.list.Title += " · " + staleLabel(m.mrsStaleAt)
+	}
+}
+
+// loadHighlightedMRDetails fetches commit, change and discussion counts of the highlighted MR
+// unless they are loaded or already being fetched. Details are loaded only for MRs the user
+// looks at, instead of three requests for every listed MR up front.
+func (m *model) loadHighlightedMRDetails() tea.Cmd {
+	item, ok := m.list.SelectedItem().(mrListItem)
+	if !ok || item.mr.DetailsLoaded || m.mrDetailsLoading[item.mr.ID] || m.creds == nil {
+		return nil
+	}
+	if m.mrDetailsLoading == nil {
+		m.mrDetailsLoading = make(map[int]bool)
+	}
+	m.mrDetailsLoading[item.mr.ID] = true
+
+	creds := m.creds
+	mr := item.mr.MergeRequest
+	return func() tea.Msg {
+		client := NewForge(*creds)
+		details, err := client.GetMergeRequestDetails(mr)
+		return fetchMRDetailsMsg{id: mr.ID, details: details, err: err}
+	}
+}
+
+// applyMRDetails stores fetched details on the listed MR with the same ID
+func (m *model) applyMRDetails(details *MergeRequestDetails) {
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok && mr.mr.ID == details.ID {
+			mr.mr.ChangesCount = details.ChangesCount
+			mr.mr.CommitsCount = details.CommitsCount
+			mr.mr.DiscussionsTotal = details.DiscussionsTotal
+			mr.mr.DiscussionsResolved = details.DiscussionsResolved
+			mr.mr.BehindCount = details.BehindCount
+			mr.mr.BehindKnown = details.BehindKnown
+			if details.MergeStatus != "" {
+				mr.mr.MergeStatus = details.MergeStatus
+			}
+			if details.DetailedMergeStatus != "" {
+				mr.mr.DetailedMergeStatus = details.DetailedMergeStatus
+			}
+			mr.mr.HeadPipeline = details.HeadPipeline
+			mr.mr.ApprovedBy = details.ApprovedBy
+			mr.mr.ApprovalsLeft = details.ApprovalsLeft
+			mr.mr.ApprovalsKnown = details.ApprovalsKnown
+			mr.mr.DetailsLoaded = true
+		}
+	}
+	if m.ready {
+		m.viewport.SetContent(m.renderMarkdown())
 	}
 }
 
@@ -313,6 +652,7 @@ func (m *model) updateListSize() {
 	contentWidth := m.width - sidebarWidth - 4
 
 	m.list.SetSize(sidebarWidth-4, m.height-6)
+	fitPagination(&m.list)
 
 	if !m.ready {
 		m.viewport = viewport.New(contentWidth-4, m.height-6)
@@ -330,25 +670,41 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.showOpenOptionsModal {
 		return m.updateOpenOptionsModal(msg)
 	}
+	if m.showMRActions {
+		return m.updateMRActions(msg)
+	}
 
 	var cmds []tea.Cmd
 
+	// While typing a filter all keys go to the list; esc also clears an applied filter
+	if m.list.FilterState() == list.Filtering || (msg.String() == "esc" && m.list.FilterState() == list.FilterApplied) {
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		if m.ready {
+			m.viewport.SetContent(m.renderMarkdown())
+		}
+		return m, tea.Batch(cmd, m.loadHighlightedMRDetails())
+	}
+
 	switch msg.String() {
 	case "esc":
 		// Ignore esc - only ctrl+c quits (ctrl+q goes back)
 		return m, nil
 	case "enter":
-		// Don't proceed if MRs failed to load
-		if m.mrsLoadError {
+		// Don't proceed if MRs failed to load, the cached list is still being refreshed,
+		// or the forge is unreachable (a release needs it)
+		if m.mrsLoadError || m.mrsCached || m.offline {
 			return m, nil
 		}
 		// Proceed to environment selection (MRs selection is optional for prod releases)
-		m.screen = screenEnvSelect
+		m.moveToScreen(screenEnvSelect)
 		// Only reset index if no environment was previously selected
 		if m.selectedEnv == nil {
 			m.envSelectIndex = 0
 		}
-		return m, nil
+		m.envDeployments = nil
+		watchCmd := m.startPlanWatch()
+		return m, tea.Batch(m.loadEnvDeployments(), watchCmd)
 	case "o":
 		// Show options modal for selected MR
 		selected := m.list.SelectedItem()
@@ -358,12 +714,17 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		return m, nil
+	case ".":
+		return m.openMRActions()
+	case "t":
+		// Label or set the milestone of the selected MRs
+		cmd := m.bulkEditListMRs()
+		return m, cmd
 	case "r":
 		// If no project selected, open project selector instead
 		if m.selectedProject == nil {
 			m.showProjectSelector = true
-			m.loadingProjects = true
-			return m, tea.Batch(m.spinner.Tick, m.fetchProjects())
+			return m, m.loadProjects()
 		}
 		// Refresh MRs with loading modal
 		m.loadingMRs = true
@@ -376,19 +737,32 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				iid := mr.MR().IID
 				if m.selectedMRs[iid] {
 					delete(m.selectedMRs, iid)
+					m.saveSelection()
+				} else if reason, blocked := m.blockedMRs[iid]; blocked {
+					m.showErrorModal = true
+					m.errorModalMsg = blockedMRError(iid, reason).Error()
 				} else {
-					m.selectedMRs[iid] = true
+					// Selected once the hook script allows it
+					return m, m.selectMRWithHooks(mr.MR())
 				}
 			}
 		}
 		return m, nil
+	case "i":
+		// Select the MRs of the current iteration
+		cmd := m.selectIterationMRs()
+		return m, cmd
+	case "D":
+		// Select the dependency updates merging together
+		cmd := m.selectDependencyUpdates()
+		return m, cmd
 	case "d":
 		// Half page down in viewport
 		m.viewport.HalfViewDown()
 		return m, nil
 	case "ctrl+q":
 		// Go back to home screen
-		m.screen = screenHome
+		m.goBack()
 		return m, nil
 	}
 
@@ -401,6 +775,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.ready {
 		m.viewport.SetContent(m.renderMarkdown())
 	}
+	cmds = append(cmds, m.loadHighlightedMRDetails(), m.loadMoreMRs())
 
 	// Handle viewport updates
 	m.viewport, cmd = m.viewport.Update(msg)
@@ -421,15 +796,7 @@ func (m model) renderMarkdown() string {
 		return lipgloss.NewStyle().PaddingLeft(1).Foreground(currentTheme.Foreground).Render("No merge requests found.\nPress 'r' to refresh.")
 	}
 
-	style := styles.DarkStyleConfig
-	style.Document.StylePrimitive.Color = stringPtr(string(currentTheme.Foreground))
-	style.Strong.Color = stringPtr(string(currentTheme.Warning))
-	style.H1.Prefix = " "
-	style.H1.BackgroundColor = stringPtr(string(currentTheme.Accent))
-	style.H1.Color = stringPtr(string(currentTheme.AccentForeground))
-	style.H2.Prefix = ""
-	style.H3.Prefix = ""
-	style.H3.Color = stringPtr(string(currentTheme.Accent))
+	style := markdownStyle()
 
 	mr, ok := selected.(mrListItem)
 	if !ok {
@@ -454,6 +821,26 @@ func (m model) renderMarkdown() string {
 	if changesCount == "" {
 		changesCount = "0"
 	}
+	commitsCount := fmt.Sprintf("%d", details.CommitsCount)
+
+	behind := "—"
+	if details.BehindKnown {
+		behind = fmt.Sprintf("%d", details.BehindCount)
+	}
+
+	// Counts are fetched when the MR is highlighted
+	if !details.DetailsLoaded {
+		discussionInfo, commitsCount, changesCount, behind = "…", "…", "…", "…"
+	}
+
+	// A blocked MR shows why above its description, the pipeline and approvals if reported
+	description := details.Description
+	if reason, blocked := m.blockedMRs[details.IID]; blocked {
+		description = "> **On the blocklist:** " + reason + "\n\n" + description
+	}
+	if status := mrReviewStatus(details); status != "" {
+		description = status + "\n\n" + description
+	}
 
 	// Build markdown content
 	markdown := fmt.Sprintf(`# %s 
@@ -461,9 +848,9 @@ func (m model) renderMarkdown() string {
 ### %s (@%s)
 **%s** -> %s (at %s)
  
- | Overview | Commits | Changes |
- |:--------:|:-------:|:-------:|
- | %s | %d | %s |
+ | Overview | Commits | Changes | Behind | Merge status |
+ |:--------:|:-------:|:-------:|:------:|:------------:|
+ | %s | %s | %s | %s | %s |
  
  %s
  `,
@@ -472,15 +859,18 @@ func (m model) renderMarkdown() string {
 		details.Author.Username,
 		details.SourceBranch,
 		details.TargetBranch,
-		details.CreatedAt.Format("02.01.2006 15:04"),
+		formatDateTime(details.CreatedAt),
 		discussionInfo,
-		details.CommitsCount,
+		commitsCount,
 		changesCount,
-		details.Description,
+		behind,
+		mergeStatusLabel(details.MergeStatus),
+		description,
 	)
 
 	renderer, _ := glamour.NewTermRenderer(
 		glamour.WithStyles(style),
+		glamour.WithColorProfile(termPolicy.colors),
 		glamour.WithWordWrap(m.viewport.Width),
 		glamour.WithPreservedNewLines(),
 	)
@@ -493,6 +883,41 @@ func (m model) renderMarkdown() string {
 	return strings.Trim(rendered, "\n")
 }
 
+// mrReviewStatus summarizes the head pipeline and approvals of an MR for the details pane, e.g.
+// "**Pipeline:** success • **Approved by:** @ann (1 more needed)"; "" if neither is reported
+func mrReviewStatus(mr *MergeRequestDetails) string {
+	var parts []string
+	if mr.HeadPipeline != nil {
+		parts = append(parts, "**Pipeline:** "+mr.HeadPipeline.Status)
+	}
+	if mr.ApprovalsKnown {
+		approvals := "none"
+		if len(mr.ApprovedBy) > 0 {
+			approvals = "@" + strings.Join(mr.ApprovedBy, ", @")
+		}
+		if mr.ApprovalsLeft > 0 {
+			approvals += fmt.Sprintf(" (%d more needed)", mr.ApprovalsLeft)
+		}
+		parts = append(parts, "**Approved by:** "+approvals)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// mergeStatusLabel describes a merge status for the details pane; cannot-be-merged is bold
+func mergeStatusLabel(status string) string {
+	switch status {
+	case "can_be_merged":
+		return "mergeable"
+	case "cannot_be_merged":
+		return "**cannot be merged**"
+	case "cannot_be_merged_recheck", "unchecked", "checking":
+		return "checking"
+	case "":
+		return "—"
+	}
+	return strings.ReplaceAll(status, "_", " ")
+}
+
 // viewList renders the main list screen
 func (m model) viewList() string {
 	if !m.ready {
@@ -533,8 +958,13 @@ func (m model) viewList() string {
 	main := lipgloss.JoinHorizontal(lipgloss.Top, sidebar, content)
 
 	// Help footer (centered)
-	helpText := "j/k/g/G: nav • space: select • enter: proceed • o: open • r: reload • C+q: back • /: commands"
+	helpText := "j/k/g/G: nav • space: select • i: iteration • enter: proceed • f: filter • o: open • .: actions • t: label • r: reload • C+q: back • /: commands"
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
+	// The API quota leads, so a narrow terminal cuts the keys rather than the warning
+	if quota := renderAPIQuota(); quota != "" {
+		line := ansi.Truncate(quota+helpStyle.Render(" • "+helpText), m.width, "…")
+		help = lipgloss.PlaceHorizontal(m.width, lipgloss.Center, line)
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, main, help)
 }
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Release events are posted to the chat webhooks listed under "notifications" in config.
+// Each entry picks a provider and may be limited to some environments and events, e.g. prod only.
+const (
+	releaseEventStarted   = "started"
+	releaseEventSuspended = "suspended" // Merge conflict
+	releaseEventCompleted = "completed"
+	releaseEventAborted   = "aborted"
+	releaseEventWaiting   = "waiting" // Gated step (Create MR, Push root branches) waits for confirmation
+	releaseEventStep      = "step"    // A step finished; only sent to providers that ask for it, or to webhooks
+	releaseEventFailed    = "failed"  // A step failed and waits for retry or abort; sent like step events
+)
+
+// ReleaseEvent describes a release for a notification
+type ReleaseEvent struct {
+	Kind           string
+	Project        string
+	Version        string
+	ReleaseVersion string // Version formatted by the environment's rules; empty until the release MR is created
+	Tag            string // Empty until the release MR is created
+	Environment    string
+	EnvBranch      string
+	MRBranches     []string
+	MRURLs         []string // Same order as MRBranches; may be shorter for old releases
+	MRIIDs         []int    // Same order as MRBranches; may be shorter for old releases
+	ReleaseMRURL   string
+	ReleaseMRIID   int // 0 until the release MR is created
+	ProjectID      int
+	ProjectURL     string
+	Step           string // Current step (the finished one for step events), see releaseStepNames
+	Reason         string // Why the release is suspended, or the step it waits for
+	Gate           string // Set for waiting events that can be approved remotely (see telegram.go)
+	Rollout        *Rollout
+	DeployProject  string // Deployment project whose pipeline the release runs, if any
+}
+
+// notificationProvider posts a release event to one chat
+type notificationProvider interface {
+	send(event ReleaseEvent) error
+}
+
+// notificationProviders creates providers by their config name
+var notificationProviders = map[string]func(NotificationConfig) notificationProvider{
+	"slack":      func(c NotificationConfig) notificationProvider { return slackNotifier{webhookURL: c.WebhookURL} },
+	"teams":      func(c NotificationConfig) notificationProvider { return teamsNotifier{webhookURL: c.WebhookURL} },
+	"mattermost": func(c NotificationConfig) notificationProvider { return mattermostNotifier{webhookURL: c.WebhookURL} },
+	"telegram": func(c NotificationConfig) notificationProvider {
+		return telegramNotifier{botToken: c.BotToken, chatID: c.ChatID, approvals: len(c.Approvers) > 0}
+	},
+	"webhook": func(c NotificationConfig) notificationProvider {
+		return outboundWebhook{url: c.WebhookURL, secret: c.Secret}
+	},
+	"email": func(c NotificationConfig) notificationProvider {
+		return emailNotifier{addr: c.SMTP, username: c.Username, password: c.Password, from: c.From, to: c.To}
+	},
+	"mr_comment":  func(c NotificationConfig) notificationProvider { return mrCommentNotifier{} },
+	"mr_announce": func(c NotificationConfig) notificationProvider { return mrAnnounceNotifier{} },
+}
+
+// notifyDone is closed once the last queued event has been delivered. Each event waits for the
+// one queued before it, so events arrive in order even when their commands overlap.
+var (
+	notifyQueueMu sync.Mutex
+	notifyDone    = closedChan()
+)
+
+// closedChan returns a closed channel
+func closedChan() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+// releaseNotifyMsg carries the failed deliveries of a notification
+type releaseNotifyMsg struct {
+	errs []error
+}
+
+// releaseEvent snapshots the current release for a notification
+func (m *model) releaseEvent(kind, reason string) ReleaseEvent {
+	state := m.releaseState
+	event := ReleaseEvent{
+		Kind:           kind,
+		Version:        state.Version,
+		ReleaseVersion: state.ReleaseVersion,
+		Tag:            state.TagName,
+		Environment:    state.Environment.Name,
+		EnvBranch:      state.Environment.BranchName,
+		MRBranches:     append([]string(nil), state.MRBranches...),
+		MRURLs:         append([]string(nil), state.MRURLs...),
+		MRIIDs:         append([]int(nil), state.SelectedMRIIDs...),
+		ReleaseMRURL:   state.CreatedMRURL,
+		ReleaseMRIID:   state.CreatedMRIID,
+		ProjectID:      state.ProjectID,
+		Step:           releaseStepNames[state.CurrentStep],
+		Reason:         reason,
+		Rollout:        state.Rollout,
+	}
+	if state.Environment.DeployPipeline != nil {
+		event.DeployProject = state.Environment.DeployPipeline.Project
+	}
+	if m.selectedProject != nil {
+		event.Project = m.selectedProject.PathWithNamespace
+		event.ProjectURL = m.selectedProject.WebURL
+	}
+	return event
+}
+
+// notifyRelease returns a command posting an event of the current release to the configured
+// providers, or nil if there is no release
+func (m *model) notifyRelease(kind, reason string) tea.Cmd {
+	if m.releaseState == nil {
+		return nil
+	}
+	return sendReleaseEvent(m.releaseEvent(kind, reason))
+}
+
+// sendReleaseEvent returns a command posting the event to the configured providers,
+// after the events queued before it
+func sendReleaseEvent(event ReleaseEvent) tea.Cmd {
+	notifyQueueMu.Lock()
+	prev, done := notifyDone, make(chan struct{})
+	notifyDone = done
+	notifyQueueMu.Unlock()
+
+	return func() tea.Msg {
+		<-prev
+		defer close(done)
+
+		config, err := LoadConfig()
+		if err != nil || len(config.Notifications) == 0 {
+			return nil
+		}
+
+		var errs []error
+		for _, nc := range config.Notifications {
+			if !nc.wants(event.Kind, event.Environment, event.EnvBranch) {
+				continue
+			}
+			var provider notificationProvider
+			if newProvider, ok := notificationProviders[nc.Provider]; ok {
+				provider = newProvider(nc)
+			} else if provider, ok = pluginNotificationProvider(nc); !ok {
+				errs = append(errs, fmt.Errorf("unknown notification provider %q", nc.Provider))
+				continue
+			}
+			if err := provider.send(event); err != nil {
+				errs = append(errs, fmt.Errorf("%s notification failed: %w", nc.Provider, err))
+			}
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		return releaseNotifyMsg{errs: errs}
+	}
+}
+
+// wants reports whether the entry is subscribed to the event for the environment,
+// given by name or branch; empty lists match everything, except that chats only get
+// step and failed events when they list them and email only gets summaries of finished releases
+func (nc NotificationConfig) wants(kind, envName, envBranch string) bool {
+	if len(nc.Events) > 0 && !containsFold(nc.Events, kind) {
+		return false
+	}
+	if len(nc.Events) == 0 && (kind == releaseEventStep || kind == releaseEventFailed) && nc.Provider != "webhook" && nc.Provider != "mr_comment" {
+		return false
+	}
+	if len(nc.Events) == 0 && nc.Provider == "email" && kind != releaseEventCompleted && kind != releaseEventAborted {
+		return false
+	}
+	if len(nc.Environments) > 0 && !containsFold(nc.Environments, envName) && !containsFold(nc.Environments, envBranch) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// title returns the headline of the event, e.g. "Release prod-1.2.0-v3 to PROD completed"
+func (e ReleaseEvent) title() string {
+	name := e.Tag
+	if name == "" {
+		name = e.Version
+	}
+	title := fmt.Sprintf("Release %s to %s %s", name, e.Environment, e.Kind)
+	if e.Project != "" {
+		title += " in " + e.Project
+	}
+	return title
+}
+
+// links returns the MR links of the event as label/URL pairs, the release MR first
+func (e ReleaseEvent) links() [][2]string {
+	var links [][2]string
+	if e.ReleaseMRURL != "" {
+		links = append(links, [2]string{"Release MR", e.ReleaseMRURL})
+	}
+	for i, branch := range e.MRBranches {
+		if i < len(e.MRURLs) && e.MRURLs[i] != "" {
+			links = append(links, [2]string{branch, e.MRURLs[i]})
+		}
+	}
+	return links
+}
+
+// eventEmoji returns the emoji prefixed to the event title
+func eventEmoji(kind string) string {
+	switch kind {
+	case releaseEventStarted:
+		return "🚀"
+	case releaseEventSuspended:
+		return "⚠️"
+	case releaseEventCompleted:
+		return "✅"
+	case releaseEventAborted:
+		return "❌"
+	case releaseEventWaiting:
+		return "⏸️"
+	case releaseEventFailed:
+		return "🛑"
+	}
+	return "ℹ️"
+}
+
+// slackNotifier posts to a Slack incoming webhook
+type slackNotifier struct {
+	webhookURL string
+}
+
+func (s slackNotifier) send(event ReleaseEvent) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s *%s*\n", eventEmoji(event.Kind), slackEscape(event.title()))
+	if event.Reason != "" {
+		fmt.Fprintf(&b, "%s\n", slackEscape(event.Reason))
+	}
+	if event.Rollout != nil {
+		fmt.Fprintf(&b, "Rollout: %s\n", slackEscape(event.Rollout.String()))
+	}
+	fmt.Fprintf(&b, "MRs: %d", len(event.MRBranches))
+	for _, link := range event.links() {
+		fmt.Fprintf(&b, "\n• <%s|%s>", link[1], slackEscape(link[0]))
+	}
+	return postWebhookJSON(s.webhookURL, map[string]string{"text": b.String()})
+}
+
+// slackEscape escapes the characters Slack treats as markup in message text
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// mattermostNotifier posts to a Mattermost incoming webhook, which takes Markdown text
+type mattermostNotifier struct {
+	webhookURL string
+}
+
+func (mm mattermostNotifier) send(event ReleaseEvent) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s **%s**\n", eventEmoji(event.Kind), event.title())
+	if event.Reason != "" {
+		fmt.Fprintf(&b, "%s\n", event.Reason)
+	}
+	if event.Rollout != nil {
+		fmt.Fprintf(&b, "Rollout: %s\n", markdownEscape(event.Rollout.String()))
+	}
+	fmt.Fprintf(&b, "MRs: %d", len(event.MRBranches))
+	for _, link := range event.links() {
+		fmt.Fprintf(&b, "\n- [%s](%s)", markdownEscape(link[0]), link[1])
+	}
+	return postWebhookJSON(mm.webhookURL, map[string]string{"text": b.String()})
+}
+
+// markdownEscape escapes the characters that would break a Markdown link label
+func markdownEscape(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", "*", "\\*", "_", "\\_").Replace(s)
+}
+
+// teamsNotifier posts an Adaptive Card to a Microsoft Teams incoming webhook (Workflows or connector)
+type teamsNotifier struct {
+	webhookURL string
+}
+
+func (t teamsNotifier) send(event ReleaseEvent) error {
+	body := []map[string]interface{}{{
+		"type":   "TextBlock",
+		"text":   eventEmoji(event.Kind) + " " + event.title(),
+		"weight": "Bolder",
+		"size":   "Medium",
+		"wrap":   true,
+	}}
+	if event.Reason != "" {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": event.Reason, "wrap": true})
+	}
+	facts := []map[string]string{
+		{"title": "Environment", "value": event.Environment},
+		{"title": "MRs", "value": fmt.Sprintf("%d", len(event.MRBranches))},
+	}
+	if event.Tag != "" {
+		facts = append(facts, map[string]string{"title": "Tag", "value": event.Tag})
+	}
+	if event.Rollout != nil {
+		facts = append(facts, map[string]string{"title": "Rollout", "value": event.Rollout.String()})
+	}
+	body = append(body, map[string]interface{}{"type": "FactSet", "facts": facts})
+
+	var actions []map[string]string
+	for _, link := range event.links() {
+		actions = append(actions, map[string]string{"type": "Action.OpenUrl", "title": link[0], "url": link[1]})
+	}
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+
+	return postWebhookJSON(t.webhookURL, map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	})
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// Offline mode: when the forge cannot be reached, lists fall back to their cache (even if expired)
+// and are marked stale, and non-critical writes such as MR comments are queued in
+// ~/.relix/queue.json until the forge answers again.
+
+const queueFileName = "queue.json"
+
+// Kinds of queued actions
+const (
+	queuedMRNote = "mr_note" // Create or edit a comment on an MR
+)
+
+// queuedAction is a non-critical forge write postponed while the forge is unreachable
+type queuedAction struct {
+	Kind      string    `json:"kind"`
+	Key       string    `json:"key"` // A later action with the same key replaces a queued one
+	ProjectID int       `json:"project_id"`
+	MRIID     int       `json:"mr_iid"`
+	NoteID    int       `json:"note_id,omitempty"` // Comment to edit; 0 creates one
+	Body      string    `json:"body"`
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+// queueMu serializes access to the queue file (notifications run in their own goroutine)
+var queueMu sync.Mutex
+
+// queuedActionsFlushedMsg reports the queued actions submitted once the forge was reachable again
+type queuedActionsFlushedMsg struct {
+	sent int
+	errs []error // Actions the forge rejected; they are dropped
+}
+
+// isNetworkError reports whether err means the forge could not be reached at all,
+// as opposed to an error response from its API
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// getQueuePath returns the path of the queue file
+func getQueuePath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, queueFileName), nil
+}
+
+// readQueue returns the queued actions; the caller holds queueMu
+func readQueue() ([]queuedAction, error) {
+	path, err := getQueuePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var actions []queuedAction
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// writeQueue replaces the queued actions, removing the file once the queue is empty; the caller holds queueMu
+func writeQueue(actions []queuedAction) error {
+	path, err := getQueuePath()
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(actions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// queueAction adds an action to the queue, replacing a queued one with the same key
+func queueAction(action queuedAction) error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	actions, err := readQueue()
+	if err != nil {
+		return err
+	}
+	action.QueuedAt = time.Now()
+	for i, queued := range actions {
+		if queued.Key == action.Key {
+			actions[i] = action
+			return writeQueue(actions)
+		}
+	}
+	return writeQueue(append(actions, action))
+}
+
+// isQueued reports whether an action with the key is waiting in the queue
+func isQueued(key string) bool {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	actions, _ := readQueue()
+	for _, queued := range actions {
+		if queued.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// clearQueuedActions drops all queued actions (on logout, as they were made under that account)
+func clearQueuedActions() error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	return writeQueue(nil)
+}
+
+// submit performs the queued action
+func (a queuedAction) submit(client Forge) error {
+	switch a.Kind {
+	case queuedMRNote:
+		target := fmt.Sprintf("project %d !%d", a.ProjectID, a.MRIID)
+		if a.NoteID != 0 {
+			err := client.UpdateMergeRequestNote(a.ProjectID, a.MRIID, a.NoteID, a.Body)
+			recordAudit(auditMRNoteUpdate, target, fmt.Sprintf("queued comment %d", a.NoteID), err)
+			return err
+		}
+		noteID, err := client.CreateMergeRequestNote(a.ProjectID, a.MRIID, a.Body)
+		recordAudit(auditMRNote, target, a.Body, err)
+		if err == nil {
+			rememberMRComment(a.ProjectID, a.MRIID, noteID)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown queued action %q", a.Kind)
+}
+
+// flushQueuedActions submits the queued actions in order. It stops at the first network error,
+// keeping that action and the rest queued; actions the forge rejects are dropped.
+func flushQueuedActions(creds Credentials) queuedActionsFlushedMsg {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	var msg queuedActionsFlushedMsg
+	actions, err := readQueue()
+	if err != nil {
+		msg.errs = append(msg.errs, fmt.Errorf("read action queue: %w", err))
+		return msg
+	}
+	if len(actions) == 0 {
+		return msg
+	}
+
+	client := NewForge(creds)
+	i := 0
+	for ; i < len(actions); i++ {
+		err := actions[i].submit(client)
+		if isNetworkError(err) {
+			break
+		}
+		if err != nil {
+			msg.errs = append(msg.errs, fmt.Errorf("queued %s on project %d !%d: %w", actions[i].Kind, actions[i].ProjectID, actions[i].MRIID, err))
+		} else {
+			msg.sent++
+		}
+	}
+	if err := writeQueue(actions[i:]); err != nil {
+		msg.errs = append(msg.errs, fmt.Errorf("write action queue: %w", err))
+	}
+	return msg
+}
+
+// flushQueuedActionsCmd submits the queued actions in the background
+func flushQueuedActionsCmd(creds Credentials) tea.Cmd {
+	return func() tea.Msg {
+		return flushQueuedActions(creds)
+	}
+}
+
+// trackOffline records whether the last forge request reached the forge. Coming back online
+// submits the queued actions; so does the first successful request after startup.
+func (m *model) trackOffline(err error) tea.Cmd {
+	if isNetworkError(err) {
+		m.offline = true
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	wasOffline := m.offline
+	m.offline = false
+	if m.creds == nil || (!wasOffline && m.queueFlushed) {
+		return nil
+	}
+	m.queueFlushed = true
+	return flushQueuedActionsCmd(*m.creds)
+}
+
+// offlineWarning tells that the forge is unreachable, or returns ""
+func (m model) offlineWarning() string {
+	if !m.offline || m.creds == nil {
+		return ""
+	}
+	return forgeName(*m.creds) + " is unreachable: showing cached data, MR comments are queued"
+}
+
+// staleLabel describes a list shown from the cache while offline, e.g. "offline · cached 3 hours ago"
+func staleLabel(fetchedAt time.Time) string {
+	if fetchedAt.IsZero() {
+		return "offline"
+	}
+	return "offline · cached " + humanize.Time(fetchedAt)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// outboundWebhook posts release events as JSON to any URL. With a secret, requests are signed like
+// the inbound /api/webhooks/release: X-Relix-Signature is "sha256=" + hex HMAC-SHA256 of
+// "<timestamp>.<body>", with the Unix timestamp in X-Relix-Timestamp.
+type outboundWebhook struct {
+	url    string
+	secret string
+}
+
+// webhookEventPayload is the JSON body of an outbound webhook
+type webhookEventPayload struct {
+	Event          string           `json:"event"`
+	Timestamp      time.Time        `json:"timestamp"`
+	Project        string           `json:"project,omitempty"`
+	Version        string           `json:"version"`
+	ReleaseVersion string           `json:"release_version,omitempty"`
+	Tag            string           `json:"tag,omitempty"`
+	Environment    webhookEventEnv  `json:"environment"`
+	Step           string           `json:"step,omitempty"`
+	Reason         string           `json:"reason,omitempty"`
+	MRs            []webhookEventMR `json:"mrs"`
+	ReleaseMRURL   string           `json:"release_mr_url,omitempty"`
+	Rollout        *Rollout         `json:"rollout,omitempty"`
+}
+
+type webhookEventEnv struct {
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+}
+
+type webhookEventMR struct {
+	Branch string `json:"branch"`
+	URL    string `json:"url,omitempty"`
+}
+
+// newWebhookEventPayload converts a release event to its JSON form, also sent to plugins
+func newWebhookEventPayload(event ReleaseEvent) webhookEventPayload {
+	payload := webhookEventPayload{
+		Event:          event.Kind,
+		Timestamp:      time.Now().UTC(),
+		Project:        event.Project,
+		Version:        event.Version,
+		ReleaseVersion: event.ReleaseVersion,
+		Tag:            event.Tag,
+		Environment:    webhookEventEnv{Name: event.Environment, Branch: event.EnvBranch},
+		Step:           event.Step,
+		Reason:         event.Reason,
+		MRs:            []webhookEventMR{},
+		ReleaseMRURL:   event.ReleaseMRURL,
+		Rollout:        event.Rollout,
+	}
+	for i, branch := range event.MRBranches {
+		mr := webhookEventMR{Branch: branch}
+		if i < len(event.MRURLs) {
+			mr.URL = event.MRURLs[i]
+		}
+		payload.MRs = append(payload.MRs, mr)
+	}
+	return payload
+}
+
+func (w outboundWebhook) send(event ReleaseEvent) error {
+	body, err := json.Marshal(newWebhookEventPayload(event))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Relix-Event", event.Kind)
+	if w.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Relix-Timestamp", ts)
+		req.Header.Set("X-Relix-Signature", "sha256="+signWebhookPayload(w.secret, ts+".", body))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook response error: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Commands run in the release terminal color their output with the 16 basic ANSI colors, e.g.
+// git's red and green diff lines, which the terminal draws from its own palette and which clash
+// with custom themes. The release terminal rewrites them to the theme: red to the error color,
+// green to success, yellow to warning, blue, magenta and cyan to the accent, white to the
+// foreground and black to the notion color. The rewritten sequences are the theme's own, so the
+// history remaps them like the rest of the output when the theme changes. 256-color and 24-bit
+// colors are kept, as the command chose them on purpose.
+
+// outputPalette holds the theme escape sequence each basic ANSI color is rewritten to; an empty
+// sequence keeps the color
+type outputPalette [16]string
+
+// liveOutputPalette is the palette of the current theme, built on first use after a theme change.
+// The release terminal renders from its own goroutine.
+var liveOutputPalette atomic.Pointer[outputPalette]
+
+// newOutputPalette returns the palette of a theme
+func newOutputPalette(theme ThemeColors) *outputPalette {
+	colors := [8]lipgloss.Color{
+		theme.Notion,     // Black
+		theme.Error,      // Red
+		theme.Success,    // Green
+		theme.Warning,    // Yellow
+		theme.Accent,     // Blue
+		theme.Accent,     // Magenta
+		theme.Accent,     // Cyan
+		theme.Foreground, // White
+	}
+	var p outputPalette
+	for i, color := range colors {
+		p[i] = captureANSIForeground(color)
+		p[i+8] = p[i] // Bright variants
+	}
+	return &p
+}
+
+// currentOutputPalette returns the palette of the current theme
+func currentOutputPalette() *outputPalette {
+	if p := liveOutputPalette.Load(); p != nil {
+		return p
+	}
+	p := newOutputPalette(currentTheme)
+	liveOutputPalette.Store(p)
+	return p
+}
+
+// remapOutputColors rewrites the basic ANSI foreground colors of command output to the current theme
+func remapOutputColors(s string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+	return currentOutputPalette().remap(s)
+}
+
+// remap rewrites the basic foreground colors of the SGR sequences in s
+func (p *outputPalette) remap(s string) string {
+	return sgrResetBgRe.ReplaceAllStringFunc(s, func(seq string) string {
+		params := strings.Split(seq[2:len(seq)-1], ";")
+		kept := make([]string, 0, len(params))
+		var fg string
+		for i := 0; i < len(params); i++ {
+			code, err := strconv.Atoi(params[i])
+			switch {
+			case err != nil && params[i] != "":
+				return seq // Not a plain SGR, leave it alone
+			case code == 38 || code == 48:
+				// Extended color: 38;5;N or 38;2;R;G;B, kept with its arguments
+				n := 2
+				if i+1 < len(params) && params[i+1] == "2" {
+					n = 4
+				}
+				end := min(i+n+1, len(params))
+				kept = append(kept, params[i:end]...)
+				i = end - 1
+				continue
+			case code >= 30 && code <= 37 && p[code-30] != "":
+				fg = p[code-30]
+				continue
+			case code >= 90 && code <= 97 && p[code-90+8] != "":
+				fg = p[code-90+8]
+				continue
+			case code == 0 || code == 39 || params[i] == "":
+				fg = "" // Reset after the color
+			}
+			kept = append(kept, params[i])
+		}
+		if fg == "" && len(kept) == len(params) {
+			return seq
+		}
+		var out string
+		if len(kept) > 0 {
+			out = "\033[" + strings.Join(kept, ";") + "m"
+		}
+		return out + fg
+	})
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Release output is kept in memory only up to a size limit. Older lines are moved to a spill file
+// next to release.json, and the complete output is saved to history as a zstd log file. The log is
+// a series of independent zstd frames of historyLogChunkLines lines each (together still a valid
+// zstd stream), with the frame offsets kept in the release's detail file, so the history viewer
+// decompresses only the frames it shows, from the end backwards as it is scrolled up. Releases
+// saved before compression have a plain log file, read backwards the same way.
+const (
+	defaultOutputMemoryLimitKB = 4096
+	releaseOutputLogFile       = "release-output.log"
+	historyLogChunkLines       = 1000 // Lines loaded per chunk in the history Logs tab
+)
+
+// getReleaseOutputLogPath returns the path of the spill file of the release in progress in a tab
+func getReleaseOutputLogPath(tab int) (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, tabFileName(releaseOutputLogFile, tab)), nil
+}
+
+// clearReleaseOutputLog removes the spill file of a tab
+func clearReleaseOutputLog(tab int) error {
+	path, err := getReleaseOutputLogPath(tab)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// outputMemoryLimit returns the configured in-memory release output limit in bytes
+func outputMemoryLimit() int {
+	if config, err := LoadConfig(); err == nil && config.OutputMemoryLimitKB > 0 {
+		return config.OutputMemoryLimitKB << 10
+	}
+	return defaultOutputMemoryLimitKB << 10
+}
+
+// setReleaseOutput replaces the in-memory output (on release start, resume and cleanup).
+// Lines already spilled to disk are kept.
+func (m *model) setReleaseOutput(lines []string) {
+	m.releaseOutputBuffer = lines
+	m.releaseOutputTotal = len(lines)
+	m.releaseOutputBytes = 0
+	for _, line := range lines {
+		m.releaseOutputBytes += len(line) + 1
+	}
+	m.releaseOutputLimit = outputMemoryLimit()
+}
+
+// addReleaseOutput appends lines to the output buffer without redrawing, with secrets redacted.
+// When the buffer exceeds maxOutputLines or the memory limit, the oldest lines are spilled to disk.
+func (m *model) addReleaseOutput(lines ...string) {
+	for _, line := range lines {
+		line = redactSecrets(line)
+		m.releaseOutputBuffer = append(m.releaseOutputBuffer, line)
+		m.releaseOutputBytes += len(line) + 1
+	}
+	m.releaseOutputTotal += len(lines)
+
+	limit := m.releaseOutputLimit
+	if limit <= 0 {
+		limit = defaultOutputMemoryLimitKB << 10
+	}
+	if len(m.releaseOutputBuffer) > maxOutputLines || m.releaseOutputBytes > limit {
+		m.spillReleaseOutput(limit)
+	}
+}
+
+// spillReleaseOutput moves the oldest lines to the spill file until the buffer is down to three
+// quarters of its limits, so lines are written in batches rather than one by one.
+// If the file cannot be written, the lines are dropped.
+func (m *model) spillReleaseOutput(limit int) {
+	buf := m.releaseOutputBuffer
+	size := m.releaseOutputBytes
+	n := 0
+	for n < len(buf) && (len(buf)-n > maxOutputLines*3/4 || size > limit*3/4) {
+		size -= len(buf[n]) + 1
+		n++
+	}
+
+	if path, err := getReleaseOutputLogPath(m.tabID); err == nil {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600); err == nil {
+			w := bufio.NewWriter(f)
+			for _, line := range buf[:n] {
+				w.WriteString(line)
+				w.WriteByte('\n')
+			}
+			w.Flush()
+			f.Close()
+		}
+	}
+
+	// Copy the rest so the spilled lines can be garbage collected
+	m.releaseOutputBuffer = append([]string(nil), buf[n:]...)
+	m.releaseOutputBytes = size
+}
+
+// logFrameWriter writes lines as zstd frames of historyLogChunkLines lines, recording the offset
+// each frame starts at
+type logFrameWriter struct {
+	w      *bufio.Writer
+	offset int64
+	zw     *zstd.Encoder
+	lines  int
+	frames []int64
+}
+
+// Write counts the compressed bytes written to the file
+func (fw *logFrameWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.offset += int64(n)
+	return n, err
+}
+
+// writeLine adds a line, starting a new frame when the current one is full
+func (fw *logFrameWriter) writeLine(line string) error {
+	if fw.lines == 0 {
+		fw.frames = append(fw.frames, fw.offset)
+		if fw.zw == nil {
+			zw, err := zstd.NewWriter(fw, zstd.WithEncoderLevel(zstd.SpeedBestCompression), zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				return err
+			}
+			fw.zw = zw
+		} else {
+			fw.zw.Reset(fw)
+		}
+	}
+	if _, err := io.WriteString(fw.zw, line+"\n"); err != nil {
+		return err
+	}
+	fw.lines++
+	if fw.lines == historyLogChunkLines {
+		fw.lines = 0
+		return fw.zw.Close()
+	}
+	return nil
+}
+
+// close ends the last frame and flushes the file
+func (fw *logFrameWriter) close() error {
+	if fw.lines > 0 {
+		if err := fw.zw.Close(); err != nil {
+			return err
+		}
+	}
+	return fw.w.Flush()
+}
+
+// writeCompressedLog writes the lines read from r and then lines to path as a framed zstd log and
+// returns the frame offsets. Without any lines no file is left behind.
+func writeCompressedLog(path string, r io.Reader, lines []string) ([]int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	fw := &logFrameWriter{w: bufio.NewWriter(f)}
+
+	if r != nil {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if line != "" {
+				if werr := fw.writeLine(strings.TrimSuffix(line, "\n")); werr != nil {
+					f.Close()
+					return nil, werr
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	for _, line := range lines {
+		if err := fw.writeLine(line); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	if err := fw.close(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if len(fw.frames) == 0 {
+		os.Remove(path)
+	}
+	return fw.frames, nil
+}
+
+// writeReleaseOutputLog writes the complete output of the release in progress in a tab to path as
+// a framed zstd log: the spilled lines followed by the in-memory lines. It returns the frame offsets.
+func writeReleaseOutputLog(path string, tab int, lines []string) ([]int64, error) {
+	if spillPath, err := getReleaseOutputLogPath(tab); err == nil {
+		if spill, err := os.Open(spillPath); err == nil {
+			defer spill.Close()
+			return writeCompressedLog(path, spill, lines)
+		}
+	}
+	return writeCompressedLog(path, nil, lines)
+}
+
+// readLogFrame decompresses the frame of a framed zstd log that ends at byte offset end (-1 for the
+// last frame). It returns the lines and the offset of the frame; 0 means the first frame.
+func readLogFrame(path string, frames []int64, end int64) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if end < 0 || end > info.Size() {
+		end = info.Size()
+	}
+	k := len(frames) - 1
+	for k > 0 && frames[k] >= end {
+		k--
+	}
+	if k < 0 {
+		return nil, 0, nil
+	}
+	next := info.Size()
+	if k+1 < len(frames) {
+		next = frames[k+1]
+	}
+
+	zr, err := zstd.NewReader(io.NewSectionReader(f, frames[k], next-frames[k]), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, 0, err
+	}
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	return lines, frames[k], nil
+}
+
+// readLogChunk reads up to maxLines lines that end at byte offset end (-1 for the end of the file),
+// reading backwards. It returns the lines and the offset of the first one; 0 means the start of
+// the file was reached.
+func readLogChunk(path string, end int64, maxLines int) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if end < 0 || end > info.Size() {
+		end = info.Size()
+	}
+
+	// Read blocks backwards until the data holds more than maxLines line breaks
+	// (one extra, so the first line is known to be complete) or the file starts
+	const blockSize = 64 << 10
+	pos := end
+	var data []byte
+	for pos > 0 && bytes.Count(data, []byte{'\n'}) <= maxLines {
+		n := int64(blockSize)
+		if n > pos {
+			n = pos
+		}
+		pos -= n
+		block := make([]byte, n)
+		if _, err := f.ReadAt(block, pos); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		data = append(block, data...)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	if pos > 0 && len(lines) > 0 {
+		lines = lines[1:] // Partial line; it belongs to the previous chunk
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+
+	start := end
+	for _, line := range lines {
+		start -= int64(len(line)) + 1
+	}
+	if start < 0 {
+		start = 0 // Last line without a trailing newline
+	}
+	return lines, start, nil
+}
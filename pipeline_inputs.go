@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// Pipeline inputs: a deployment pipeline may need values only the person releasing knows, e.g. a
+// maintenance window or whether to run migrations. They are declared in the inputs of the
+// environment's deploy_pipeline and, on GitLab, read from the spec:inputs header of the deployment
+// project's .gitlab-ci.yml at the pipeline's ref. Before the release starts a small form asks for
+// them, prefilled with their defaults, and checks each value against its type, options and regex.
+// Declared inputs are passed as pipeline variables, spec inputs as pipeline inputs. The values are
+// kept with the release and its history entry, those of secret inputs redacted in history.
+// Headless and API releases give them in the plan (--input NAME=VALUE, pipeline_inputs).
+
+// PipelineInput declares a value the deployment pipeline needs
+type PipelineInput struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"` // "string" (default), "number" or "boolean"
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Options     []string `json:"options,omitempty"`  // Allowed values
+	Regex       string   `json:"regex,omitempty"`    // Pattern a value must match, e.g. "^[0-9]{2}:[0-9]{2}$"
+	Required    bool     `json:"required,omitempty"` // An empty value is refused
+	Secret      bool     `json:"secret,omitempty"`   // Masked in the form and redacted in history
+
+	Spec bool `json:"-"` // A spec input of .gitlab-ci.yml rather than a variable
+}
+
+// PipelineInputValue is the value given for a pipeline input
+type PipelineInputValue struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Type   string `json:"type,omitempty"`
+	Spec   bool   `json:"spec,omitempty"` // Passed as a pipeline input rather than a variable
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// pipelineInputsMsg reports the pipeline inputs of an environment, loaded before its release
+type pipelineInputsMsg struct {
+	env    string
+	inputs []PipelineInput
+	err    error
+}
+
+// loadPipelineInputs returns the inputs of the environment's deployment pipeline: the declared
+// ones, then the spec inputs of the deployment project's .gitlab-ci.yml
+func loadPipelineInputs(client Forge, env Environment) ([]PipelineInput, error) {
+	cfg := env.DeployPipeline
+	if cfg == nil {
+		return nil, nil
+	}
+	inputs := slices.Clone(cfg.Inputs)
+	gitlab, ok := client.(*GitLabClient)
+	if !ok {
+		return inputs, nil // The deployment step refuses to run anyway
+	}
+	data, err := gitlab.GetProjectFile(cfg.Project, cfg.Ref, ".gitlab-ci.yml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the pipeline of %s: %w", cfg.Project, err)
+	}
+	spec, err := parseSpecInputs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s .gitlab-ci.yml: %w", cfg.Project, err)
+	}
+	return append(inputs, spec...), nil
+}
+
+// ciSpecInput is an input in the spec:inputs header of a .gitlab-ci.yml
+type ciSpecInput struct {
+	Type        string    `yaml:"type"`
+	Description string    `yaml:"description"`
+	Default     yaml.Node `yaml:"default"` // Zero when absent, which makes the input required
+	Options     []string  `yaml:"options"`
+	Regex       string    `yaml:"regex"`
+}
+
+// parseSpecInputs returns the inputs declared in the header document of a .gitlab-ci.yml, in order.
+// Array inputs with a default are left to it; without one they cannot be given here.
+func parseSpecInputs(data []byte) ([]PipelineInput, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	spec := yamlMappingValue(doc.Content[0], "spec")
+	declared := yamlMappingValue(spec, "inputs")
+	if declared == nil || declared.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var inputs []PipelineInput
+	for i := 0; i+1 < len(declared.Content); i += 2 {
+		name := declared.Content[i].Value
+		var in ciSpecInput
+		if err := declared.Content[i+1].Decode(&in); err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		if in.Type == "array" {
+			if in.Default.Kind == 0 {
+				return nil, fmt.Errorf("input %s: array inputs without a default are not supported", name)
+			}
+			continue
+		}
+		inputs = append(inputs, PipelineInput{
+			Name:        name,
+			Type:        in.Type,
+			Description: in.Description,
+			Default:     in.Default.Value,
+			Options:     in.Options,
+			Regex:       strings.TrimSuffix(strings.TrimPrefix(in.Regex, "/"), "/"),
+			Required:    in.Default.Kind == 0,
+			Spec:        true,
+		})
+	}
+	return inputs, nil
+}
+
+// yamlMappingValue returns the value of a key of a YAML mapping, nil if there is none
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// check validates a value given for the input
+func (p PipelineInput) check(value string) error {
+	if value == "" {
+		if p.Required {
+			return fmt.Errorf("%s is required", p.Name)
+		}
+		return nil
+	}
+	switch p.Type {
+	case "", "string":
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number", p.Name)
+		}
+	case "boolean":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", p.Name)
+		}
+	default:
+		return fmt.Errorf("%s has the unsupported type %q", p.Name, p.Type)
+	}
+	if len(p.Options) > 0 && !slices.Contains(p.Options, value) {
+		return fmt.Errorf("%s must be one of %s", p.Name, strings.Join(p.Options, ", "))
+	}
+	if p.Regex != "" {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return fmt.Errorf("%s: invalid regex: %w", p.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s must match %s", p.Name, p.Regex)
+		}
+	}
+	return nil
+}
+
+// resolvePipelineInputs checks the values given for the inputs by name, defaults applying to the
+// ones not given, and returns the non-empty ones
+func resolvePipelineInputs(inputs []PipelineInput, values map[string]string) ([]PipelineInputValue, error) {
+	for name := range values {
+		if !slices.ContainsFunc(inputs, func(p PipelineInput) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("unknown pipeline input %q", name)
+		}
+	}
+	var resolved []PipelineInputValue
+	for _, p := range inputs {
+		value, ok := values[p.Name]
+		if !ok {
+			value = p.Default
+		}
+		value = strings.TrimSpace(value)
+		if err := p.check(value); err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+		if p.Secret {
+			registerSecret(value)
+		}
+		resolved = append(resolved, PipelineInputValue{Name: p.Name, Value: value, Type: p.Type, Spec: p.Spec, Secret: p.Secret})
+	}
+	return resolved, nil
+}
+
+// typed returns the value as the JSON type of its input, for the pipeline inputs of GitLab
+func (v PipelineInputValue) typed() any {
+	switch v.Type {
+	case "number":
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case "boolean":
+		return v.Value == "true"
+	}
+	return v.Value
+}
+
+// redactPipelineInputs returns the values with those of secret inputs redacted, for history
+func redactPipelineInputs(values []PipelineInputValue) []PipelineInputValue {
+	redacted := slices.Clone(values)
+	for i := range redacted {
+		if redacted[i].Secret {
+			redacted[i].Value = redactedSecret
+		}
+	}
+	return redacted
+}
+
+// formatPipelineInputs summarizes the values, e.g. "WINDOW=02:00, migrate=true"
+func formatPipelineInputs(values []PipelineInputValue) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = v.Name + "=" + v.Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pipelineInputFlag collects repeated --input NAME=VALUE flags
+type pipelineInputFlag map[string]string
+
+func (f pipelineInputFlag) String() string { return "" }
+
+func (f pipelineInputFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected NAME=VALUE, got %q", s)
+	}
+	f[strings.TrimSpace(name)] = value
+	return nil
+}
+
+// askPipelineInputs starts the release once the inputs of the environment's deployment pipeline,
+// if it has any, are given in the form
+func (m *model) askPipelineInputs() (tea.Model, tea.Cmd) {
+	m.pipelineInputValues = nil
+	if m.selectedEnv == nil || m.selectedEnv.DeployPipeline == nil || m.creds == nil {
+		return m.startRelease()
+	}
+	m.closeAllModals()
+	m.pipelineInputs = nil
+	m.pipelineInputFields = nil
+	m.pipelineInputFocus = 0
+	m.pipelineInputsError = ""
+	m.pipelineInputsLoading = true
+	m.showPipelineInputs = true
+	client, env := NewForge(*m.creds), *m.selectedEnv
+	return m, func() tea.Msg {
+		inputs, err := loadPipelineInputs(client, env)
+		return pipelineInputsMsg{env: env.Name, inputs: inputs, err: err}
+	}
+}
+
+// handlePipelineInputs fills the form with the loaded inputs, or starts the release if there are
+// none to ask for
+func (m *model) handlePipelineInputs(msg pipelineInputsMsg) (tea.Model, tea.Cmd) {
+	if !m.showPipelineInputs || !m.pipelineInputsLoading || m.selectedEnv == nil || m.selectedEnv.Name != msg.env {
+		return m, nil
+	}
+	m.pipelineInputsLoading = false
+	if msg.err != nil {
+		m.pipelineInputsError = msg.err.Error()
+		return m, nil
+	}
+	if len(msg.inputs) == 0 {
+		m.showPipelineInputs = false
+		return m.startRelease()
+	}
+
+	m.pipelineInputs = msg.inputs
+	m.pipelineInputFields = make([]textinput.Model, len(msg.inputs))
+	for i, p := range msg.inputs {
+		ti := textinput.New()
+		switch {
+		case len(p.Options) > 0:
+			ti.Placeholder = strings.Join(p.Options, " | ")
+		case p.Type == "boolean":
+			ti.Placeholder = "true | false"
+		case p.Type == "number":
+			ti.Placeholder = "number"
+		}
+		ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+		ti.CharLimit = 500
+		ti.Width = 50
+		ti.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+		ti.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+		ti.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+		if p.Secret {
+			ti.EchoMode = textinput.EchoPassword
+		}
+		ti.SetValue(p.Default)
+		m.pipelineInputFields[i] = ti
+	}
+	return m, m.pipelineInputFields[0].Focus()
+}
+
+// focusPipelineInput moves the focus of the form to the field i
+func (m *model) focusPipelineInput(i int) tea.Cmd {
+	m.pipelineInputFields[m.pipelineInputFocus].Blur()
+	m.pipelineInputFocus = i
+	return m.pipelineInputFields[i].Focus()
+}
+
+// updatePipelineInputs handles keys of the pipeline inputs form
+func (m model) updatePipelineInputs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+q":
+		m.showPipelineInputs = false
+		return m, nil
+	}
+	if len(m.pipelineInputFields) == 0 {
+		return m, nil // Loading, or loading failed
+	}
+
+	last := len(m.pipelineInputFields) - 1
+	switch msg.String() {
+	case "tab", "down":
+		return m, m.focusPipelineInput(min(m.pipelineInputFocus+1, last))
+	case "shift+tab", "up":
+		return m, m.focusPipelineInput(max(m.pipelineInputFocus-1, 0))
+	case "enter":
+		if m.pipelineInputFocus < last {
+			return m, m.focusPipelineInput(m.pipelineInputFocus + 1)
+		}
+		values := make(map[string]string)
+		for i, p := range m.pipelineInputs {
+			values[p.Name] = m.pipelineInputFields[i].Value()
+		}
+		resolved, err := resolvePipelineInputs(m.pipelineInputs, values)
+		if err != nil {
+			m.pipelineInputsError = err.Error()
+			return m, nil
+		}
+		m.showPipelineInputs = false
+		m.pipelineInputValues = resolved
+		return m.startRelease()
+	}
+	var cmd tea.Cmd
+	m.pipelineInputFields[m.pipelineInputFocus], cmd = m.pipelineInputFields[m.pipelineInputFocus].Update(msg)
+	m.pipelineInputsError = ""
+	return m, cmd
+}
+
+// overlayPipelineInputs renders the pipeline inputs form
+func (m model) overlayPipelineInputs(background string) string {
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render("Deployment pipeline inputs"))
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.pipelineInputsLoading:
+		sb.WriteString(helpStyle.Render("Reading the inputs of the deployment pipeline…"))
+	case len(m.pipelineInputFields) == 0:
+		sb.WriteString(settingsErrorStyle.Render("Cannot read the pipeline inputs: " + m.pipelineInputsError))
+		sb.WriteString("\n\n")
+		sb.WriteString(helpStyle.Render("esc: cancel"))
+	default:
+		for i, p := range m.pipelineInputs {
+			label := p.Name
+			if p.Required {
+				label += " *"
+			}
+			if i == m.pipelineInputFocus {
+				sb.WriteString(commandItemSelectedStyle.Render(label))
+			} else {
+				sb.WriteString(commandItemStyle.Render(label))
+			}
+			if p.Description != "" {
+				sb.WriteString(" " + helpStyle.Render(truncateWithEllipsis(p.Description, 50)))
+			}
+			sb.WriteString("\n")
+			sb.WriteString(m.pipelineInputFields[i].View())
+			sb.WriteString("\n\n")
+		}
+		if m.pipelineInputsError != "" {
+			sb.WriteString(settingsErrorStyle.Render(m.pipelineInputsError))
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(helpStyle.Render("tab/↑↓: field • enter: next, release on the last • esc: cancel"))
+	}
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 70, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 80,
+		Style:    commandMenuStyle,
+	}
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// A release tagged while a scheduled pipeline runs, e.g. a nightly deploy, races it: both deploy,
+// and whichever finishes last wins. The confirmation screen warns about the active pipeline
+// schedules of a GitLab project due within schedule_window minutes (30 by default) and about
+// scheduled pipelines still running. With delay_tag_for_schedules, "Push root branches" waits until
+// they passed before merging and pushing the tag. Other forges have no pipeline schedules in
+// their APIs, so nothing is checked there.
+
+// defaultScheduleWindow is how long before a scheduled pipeline a release collides with it
+const defaultScheduleWindow = 30 * time.Minute
+
+// scheduleWaitTimeout bounds the wait of the tag push for scheduled pipelines
+const scheduleWaitTimeout = 6 * time.Hour
+
+// scheduleCollision is a scheduled pipeline a release now would collide with
+type scheduleCollision struct {
+	description string
+	ref         string
+	at          time.Time // Next run of a schedule; zero for a running pipeline
+	url         string    // Running pipeline
+}
+
+// scheduleCollisionsMsg carries the scheduled pipelines the release would collide with
+type scheduleCollisionsMsg struct {
+	collisions []scheduleCollision
+}
+
+// scheduleWindow returns the window of the config
+func scheduleWindow(config *AppConfig) time.Duration {
+	if config == nil || config.ScheduleWindow <= 0 {
+		return defaultScheduleWindow
+	}
+	return time.Duration(config.ScheduleWindow) * time.Minute
+}
+
+// findScheduleCollisions returns the running and pending scheduled pipelines of the project and
+// the schedules due within window from now
+func findScheduleCollisions(client *GitLabClient, projectID int, now time.Time, window time.Duration) ([]scheduleCollision, error) {
+	running, err := client.GetRunningScheduledPipelines(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var collisions []scheduleCollision
+	for _, p := range running {
+		collisions = append(collisions, scheduleCollision{description: fmt.Sprintf("pipeline #%d", p.ID), ref: p.Ref, url: p.WebURL})
+	}
+
+	schedules, err := client.GetPipelineSchedules(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range schedules {
+		// A run that is overdue has not started yet: GitLab moves next_run_at on once it created the pipeline
+		if !s.Active || s.NextRunAt == nil || s.NextRunAt.After(now.Add(window)) {
+			continue
+		}
+		description := s.Description
+		if description == "" {
+			description = s.Cron
+		}
+		collisions = append(collisions, scheduleCollision{description: description, ref: s.Ref, at: *s.NextRunAt})
+	}
+	return collisions, nil
+}
+
+// describe returns a line about the collision
+func (c scheduleCollision) describe() string {
+	if c.at.IsZero() {
+		return fmt.Sprintf("scheduled %s on %s is running: %s", c.description, c.ref, c.url)
+	}
+	if !c.at.After(time.Now()) {
+		return fmt.Sprintf("scheduled pipeline %q on %s is due", c.description, c.ref)
+	}
+	return fmt.Sprintf("scheduled pipeline %q on %s runs %s (%s)", c.description, c.ref, humanize.Time(c.at), formatDateTime(c.at))
+}
+
+// loadScheduleCollisions checks the pipeline schedules of the selected project for the
+// confirmation screen
+func (m *model) loadScheduleCollisions() tea.Cmd {
+	m.scheduleCollisions = nil
+	if m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	client, ok := NewForge(*m.creds).(*GitLabClient)
+	if !ok {
+		return nil
+	}
+	projectID := m.selectedProject.ID
+	return func() tea.Msg {
+		config, _ := LoadProjectConfig(projectID)
+		collisions, err := findScheduleCollisions(client, projectID, time.Now(), scheduleWindow(config))
+		if err != nil || len(collisions) == 0 {
+			return nil
+		}
+		return scheduleCollisionsMsg{collisions: collisions}
+	}
+}
+
+// handleScheduleCollisions shows the scheduled pipelines on the confirmation screen
+func (m *model) handleScheduleCollisions(msg scheduleCollisionsMsg) {
+	m.scheduleCollisions = msg.collisions
+	if m.screen == screenConfirm {
+		m.initConfirmViewport()
+	}
+}
+
+// scheduleWarning returns the confirmation screen warning about the scheduled pipelines, "" without any
+func (m model) scheduleWarning() string {
+	if len(m.scheduleCollisions) == 0 {
+		return ""
+	}
+	lines := make([]string, len(m.scheduleCollisions))
+	for i, c := range m.scheduleCollisions {
+		lines[i] = c.describe()
+	}
+	advice := "~~— the release may collide with it, consider releasing after it~~"
+	if config, err := loadActiveConfig(); err == nil && config.DelayTagForSchedules {
+		advice = "~~— the tag push will wait until it passed~~"
+	}
+	return fmt.Sprintf("*Scheduled pipelines:* %s %s", strings.Join(lines, "; "), advice)
+}
+
+// waitForPipelineSchedules waits until no scheduled pipeline of the project runs or is due within
+// window. Schedules that cannot be read do not hold the release up.
+func waitForPipelineSchedules(ctx context.Context, creds Credentials, projectID int, window time.Duration, sender messageSender) (string, error) {
+	client, ok := NewForge(creds).(*GitLabClient)
+	if !ok {
+		return "", nil
+	}
+	collisions, err := findScheduleCollisions(client, projectID, time.Now(), window)
+	if err != nil || len(collisions) == 0 {
+		return "", nil
+	}
+
+	var output strings.Builder
+	printLine := releaseStepPrinter(sender, &output, "wait for scheduled pipelines")
+	deadline := time.Now().Add(scheduleWaitTimeout)
+	last := ""
+	for len(collisions) > 0 {
+		// The relative time in the description changes on every poll, so only a new collision is printed
+		if current := collisions[0].description + "@" + collisions[0].ref; current != last {
+			last = current
+			printLine("Waiting: " + collisions[0].describe())
+		}
+		if time.Now().After(deadline) {
+			return output.String(), fmt.Errorf("scheduled pipelines did not pass in %s; retry to wait again", scheduleWaitTimeout)
+		}
+		if err := waitPollInterval(ctx); err != nil {
+			return output.String(), err
+		}
+		if next, err := findScheduleCollisions(client, projectID, time.Now(), window); err == nil {
+			collisions = next
+		} else if !isNetworkError(err) {
+			printLine(fmt.Sprintf("Cannot read the pipeline schedules (%v), going on", err))
+			break
+		}
+	}
+	printLine("Scheduled pipelines passed")
+	return output.String(), nil
+}
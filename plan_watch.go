@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// While a release plan is prepared (environment, version and the other screens up to the
+// confirmation), the selected MRs are watched: one closed, merged elsewhere or pushed to since it
+// was selected is reported in a toast, so the plan can be refreshed before the release merges
+// branches it no longer should. Refreshing goes back to the MR list and reloads it, which drops MRs
+// that are no longer open from the selection.
+
+// planWatchTickMsg triggers a check of the selected MRs
+type planWatchTickMsg struct {
+	gen int
+}
+
+// planWatchMsg carries the selected MRs as fetched by a check, by IID; failed lookups are missing
+type planWatchMsg struct {
+	gen int
+	mrs map[int]*MergeRequestDetails
+}
+
+// isPlanScreen reports whether the screen is one of the release plan screens
+func (m model) isPlanScreen() bool {
+	switch m.screen {
+	case screenEnvSelect, screenVersion, screenSourceBranch, screenEnvMerge, screenRootMerge, screenRollout, screenConfirm:
+		return true
+	}
+	return false
+}
+
+// startPlanWatch starts watching the selected MRs from their listed state. A watch started before
+// stops at its next tick.
+func (m *model) startPlanWatch() tea.Cmd {
+	m.planWatchGen++
+	m.planChanges = nil
+	m.planWatchHeads = make(map[int]string, len(m.selectedMRs))
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok && m.selectedMRs[mr.MR().IID] {
+			m.planWatchHeads[mr.MR().IID] = mr.MR().SHA
+		}
+	}
+	if len(m.planWatchHeads) == 0 {
+		return nil
+	}
+	return m.planWatchTick()
+}
+
+// planWatchTick returns a command that triggers a check after planWatchInterval
+func (m *model) planWatchTick() tea.Cmd {
+	gen := m.planWatchGen
+	return tea.Tick(planWatchInterval, func(t time.Time) tea.Msg {
+		return planWatchTickMsg{gen: gen}
+	})
+}
+
+// handlePlanWatchTick fetches the selected MRs while the plan is prepared
+func (m *model) handlePlanWatchTick(msg planWatchTickMsg) tea.Cmd {
+	if msg.gen != m.planWatchGen || !m.isPlanScreen() || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	creds := *m.creds
+	projectID := m.selectedProject.ID
+	var iids []int
+	for iid := range m.planWatchHeads {
+		iids = append(iids, iid)
+	}
+
+	return func() tea.Msg {
+		client := NewForge(creds)
+		mrs := make(map[int]*MergeRequestDetails, len(iids))
+		for _, iid := range iids {
+			if mr, err := client.GetMergeRequestByIID(projectID, iid); err == nil {
+				mrs[iid] = mr
+			}
+		}
+		return planWatchMsg{gen: msg.gen, mrs: mrs}
+	}
+}
+
+// handlePlanWatch reports the selected MRs that changed since the last check and re-arms the tick.
+// Closed and merged MRs are not checked again.
+func (m *model) handlePlanWatch(msg planWatchMsg) tea.Cmd {
+	if msg.gen != m.planWatchGen || !m.isPlanScreen() {
+		return nil
+	}
+	for iid, mr := range msg.mrs {
+		head, watched := m.planWatchHeads[iid]
+		if !watched {
+			continue
+		}
+		switch {
+		case mr.State == "closed":
+			m.planChanges = append(m.planChanges, fmt.Sprintf("!%d was closed", iid))
+			delete(m.planWatchHeads, iid)
+		case mr.State == "merged":
+			m.planChanges = append(m.planChanges, fmt.Sprintf("!%d was merged elsewhere", iid))
+			delete(m.planWatchHeads, iid)
+		case head != "" && mr.SHA != "" && mr.SHA != head:
+			m.planChanges = append(m.planChanges, fmt.Sprintf("!%d got new commits or was force-pushed", iid))
+			m.planWatchHeads[iid] = mr.SHA
+		}
+	}
+	if len(m.planWatchHeads) == 0 {
+		return nil
+	}
+	return m.planWatchTick()
+}
+
+// refreshPlan goes back to the MR list and reloads it after the selected MRs changed upstream
+func (m *model) refreshPlan() (tea.Model, tea.Cmd) {
+	m.planWatchGen++
+	m.planChanges = nil
+	m.moveToScreen(screenMain)
+	m.loadingMRs = true
+	return m, tea.Batch(m.spinner.Tick, m.fetchMRs())
+}
+
+// overlayPlanToast renders the upstream changes of the selected MRs in the top right corner
+func (m model) overlayPlanToast(background string) string {
+	var b strings.Builder
+	b.WriteString(errorTitleStyle.Render("Selected MRs changed"))
+	b.WriteString("\n")
+	for _, change := range m.planChanges {
+		b.WriteString("\n" + change)
+	}
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("C+r: refresh the plan"))
+
+	toast := renderModal(b.String(), ModalConfig{Width: ModalWidth{Value: 44}, MinWidth: 30, MaxWidth: 50, Style: errorBoxStyle}, m.width)
+	return placeOverlay(m.width-lipgloss.Width(toast)-1, 1, toast, background)
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Plugins are executables in ~/.relix/plugins. For every call relix starts the plugin, writes one
+// JSON request to its stdin and reads one JSON response from its stdout; stderr is shown when the
+// plugin fails. At startup each plugin is asked to "describe" itself and may add commands to the
+// command menu, steps that run before a release step, and a notification provider.
+const (
+	pluginsDirName        = "plugins"
+	pluginProtocolVersion = 1
+	pluginDescribeTimeout = 5 * time.Second
+	pluginCallTimeout     = 10 * time.Minute // Steps may run test suites or deployments
+)
+
+// Plugin request types
+const (
+	pluginRequestDescribe = "describe"
+	pluginRequestCommand  = "command"
+	pluginRequestStep     = "step"
+	pluginRequestNotify   = "notify"
+)
+
+// pluginManifest is the response to a describe request
+type pluginManifest struct {
+	Name          string          `json:"name"` // Default: file name without extension
+	Commands      []pluginCommand `json:"commands,omitempty"`
+	Steps         []pluginStep    `json:"steps,omitempty"`
+	Notifications bool            `json:"notifications,omitempty"` // Handles notify requests as provider Name
+}
+
+// pluginCommand is a command the plugin adds to the command menu
+type pluginCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// pluginStep runs before the built-in release step Before (see releaseStepNames)
+type pluginStep struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Before      string `json:"before"`
+}
+
+// pluginRequest is written to the plugin's stdin
+type pluginRequest struct {
+	Protocol int                  `json:"protocol"`
+	Type     string               `json:"type"`
+	Command  string               `json:"command,omitempty"`
+	Step     string               `json:"step,omitempty"`
+	Project  string               `json:"project,omitempty"`
+	WorkDir  string               `json:"work_dir,omitempty"`
+	Release  *webhookEventPayload `json:"release,omitempty"` // Current release, as sent to webhooks
+	Options  map[string]string    `json:"options,omitempty"` // Notify: options of the notifications entry
+	// Step: release variables (see release_vars.go), also set in the plugin's environment
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// pluginResponse is read from the plugin's stdout
+type pluginResponse struct {
+	Message string `json:"message,omitempty"` // Command: text shown to the user
+	URL     string `json:"url,omitempty"`     // Command: opened in the browser
+	Output  string `json:"output,omitempty"`  // Step: appended to the release output
+	Error   string `json:"error,omitempty"`   // Fails the call
+}
+
+// plugin is a discovered plugin executable
+type plugin struct {
+	path string
+	pluginManifest
+}
+
+var (
+	pluginsOnce    sync.Once
+	pluginsLoaded  atomic.Bool
+	pluginList     []*plugin
+	pluginLoadErrs []error
+)
+
+// getPluginsDir returns ~/.relix/plugins
+func getPluginsDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, pluginsDirName), nil
+}
+
+// plugins discovers the plugins on first use and returns them sorted by name
+func plugins() []*plugin {
+	pluginsOnce.Do(func() {
+		pluginList, pluginLoadErrs = discoverPlugins()
+		pluginsLoaded.Store(true)
+	})
+	return pluginList
+}
+
+// loadedPlugins returns the plugins if discovery has finished, without waiting for it
+func loadedPlugins() []*plugin {
+	if !pluginsLoaded.Load() {
+		return nil
+	}
+	return pluginList
+}
+
+// loadPlugins discovers the plugins in the background, so the command menu can list them
+func loadPlugins() tea.Cmd {
+	return func() tea.Msg {
+		plugins()
+		return nil
+	}
+}
+
+// discoverPlugins describes every executable in the plugins directory. Plugins that fail to
+// describe themselves are skipped and reported in the returned errors.
+func discoverPlugins() ([]*plugin, []error) {
+	dir, err := getPluginsDir()
+	if err != nil {
+		return nil, []error{err}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []*plugin
+		errs  []error
+	)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p := &plugin{path: filepath.Join(dir, entry.Name())}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.describe()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %w", filepath.Base(p.path), err))
+				return
+			}
+			found = append(found, p)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, errs
+}
+
+// describe asks the plugin for its manifest
+func (p *plugin) describe() error {
+	var manifest pluginManifest
+	if err := p.exec(pluginDescribeTimeout, "", pluginRequest{Type: pluginRequestDescribe}, &manifest); err != nil {
+		return err
+	}
+	if manifest.Name == "" {
+		manifest.Name = strings.TrimSuffix(filepath.Base(p.path), filepath.Ext(p.path))
+	}
+	for _, s := range manifest.Steps {
+		if _, ok := releaseStepByName(s.Before); !ok {
+			return fmt.Errorf("step %s: unknown release step %q", s.Name, s.Before)
+		}
+	}
+	p.pluginManifest = manifest
+	return nil
+}
+
+// call sends a request to the plugin and returns its response; an error field fails the call
+func (p *plugin) call(dir string, req pluginRequest) (*pluginResponse, error) {
+	var resp pluginResponse
+	if err := p.exec(pluginCallTimeout, dir, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// exec runs the plugin with the request on stdin and decodes its stdout into out
+func (p *plugin) exec(timeout time.Duration, dir string, req pluginRequest, out any) error {
+	req.Protocol = pluginProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Dir = dir
+	if req.Variables != nil {
+		cmd.Env = releaseEnviron(req.Variables)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, GetLastNLines(msg, 5))
+		}
+		return err
+	}
+	if err := json.Unmarshal(stdout.Bytes(), out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// releaseStepByName returns the release step with the identifier used by the API and plugins
+func releaseStepByName(name string) (ReleaseStep, bool) {
+	for step, stepName := range releaseStepNames {
+		if stepName == name {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// findPlugin returns the plugin with the given name
+func findPlugin(name string) *plugin {
+	for _, p := range plugins() {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// pluginCommandItems returns the command menu entries of the loaded plugins, named "plugin:command"
+func pluginCommandItems() []commandItem {
+	var items []commandItem
+	for _, p := range loadedPlugins() {
+		for _, c := range p.Commands {
+			desc := c.Description
+			if desc == "" {
+				desc = "Plugin command"
+			}
+			items = append(items, commandItem{name: p.Name + ":" + c.Name, desc: desc})
+		}
+	}
+	return items
+}
+
+// pluginCommandMsg carries the result of a plugin command
+type pluginCommandMsg struct {
+	name string
+	resp *pluginResponse
+	err  error
+}
+
+// runPluginCommand runs a command menu entry of a plugin
+func (m model) runPluginCommand(name string) (tea.Model, tea.Cmd) {
+	pluginName, command, _ := strings.Cut(name, ":")
+	req := pluginRequest{Type: pluginRequestCommand, Command: command, WorkDir: projectDirectory}
+	if m.selectedProject != nil {
+		req.Project = m.selectedProject.PathWithNamespace
+	}
+	if m.releaseState != nil {
+		payload := newWebhookEventPayload(m.releaseEvent(releaseEventStep, ""))
+		req.Release = &payload
+	}
+	m.closeAllModals()
+	return m, func() tea.Msg {
+		p := findPlugin(pluginName)
+		if p == nil {
+			return pluginCommandMsg{name: name, err: fmt.Errorf("plugin %s not found", pluginName)}
+		}
+		resp, err := p.call(projectDirectory, req)
+		return pluginCommandMsg{name: name, resp: resp, err: err}
+	}
+}
+
+// handlePluginCommand shows the result of a plugin command and opens its URL
+func (m *model) handlePluginCommand(msg pluginCommandMsg) tea.Cmd {
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("%s failed: %v", msg.name, msg.err)
+		return nil
+	}
+	if msg.resp.Message != "" {
+		m.closeAllModals()
+		m.showPluginResult = true
+		m.pluginResultTitle = msg.name
+		m.pluginResultText = msg.resp.Message
+	}
+	if msg.resp.URL != "" {
+		return openInBrowser(msg.resp.URL)
+	}
+	return nil
+}
+
+// overlayPluginResult renders the message returned by a plugin command
+func (m model) overlayPluginResult(background string) string {
+	var b strings.Builder
+
+	b.WriteString(commandMenuTitleStyle.Render(m.pluginResultTitle))
+	b.WriteString("\n\n")
+	b.WriteString(m.pluginResultText)
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("C+q: close"))
+
+	modalContent := renderModal(b.String(), CommandMenuModalConfig(), m.width)
+	return placeOverlayCenter(modalContent, background, m.width, m.height)
+}
+
+// runPluginSteps runs the plugin steps registered before a release step, in the project
+// directory with the release variables vars. Their output is streamed to the release screen when sender is set; otherwise it is
+// returned, for the error report of a failed step.
+func runPluginSteps(step ReleaseStep, event ReleaseEvent, workDir string, vars map[string]string, sender messageSender) (string, error) {
+	stepName := releaseStepNames[step]
+	var output strings.Builder
+	for _, p := range plugins() {
+		for _, s := range p.Steps {
+			if s.Before != stepName {
+				continue
+			}
+			header := fmt.Sprintf("plugin %s %s", p.Name, s.Name)
+			if sender != nil {
+				sender.Send(releaseCommandStartMsg{command: header})
+			} else {
+				output.WriteString("$ " + header + "\n")
+			}
+
+			payload := newWebhookEventPayload(event)
+			span := startTraceSpan(header, spanKindInternal, nil)
+			resp, err := p.call(workDir, pluginRequest{Type: pluginRequestStep, Step: s.Name, Project: event.Project, WorkDir: workDir, Release: &payload, Variables: vars})
+			span.finish(err)
+
+			if resp != nil && resp.Output != "" {
+				if sender != nil {
+					for _, line := range strings.Split(strings.TrimRight(resp.Output, "\n"), "\n") {
+						sender.Send(releaseOutputMsg{line: line})
+					}
+				} else {
+					output.WriteString(strings.TrimRight(resp.Output, "\n") + "\n")
+				}
+			}
+			if err != nil {
+				return output.String(), fmt.Errorf("plugin step %s/%s failed: %w", p.Name, s.Name, err)
+			}
+		}
+	}
+	return output.String(), nil
+}
+
+// pluginNotifier delivers release events to a plugin that handles notifications
+type pluginNotifier struct {
+	plugin  *plugin
+	options map[string]string
+}
+
+func (n pluginNotifier) send(event ReleaseEvent) error {
+	payload := newWebhookEventPayload(event)
+	_, err := n.plugin.call(projectDirectory, pluginRequest{Type: pluginRequestNotify, Project: event.Project, Release: &payload, Options: n.options})
+	return err
+}
+
+// pluginNotificationProvider returns the provider of a plugin named like the notifications entry
+func pluginNotificationProvider(nc NotificationConfig) (notificationProvider, bool) {
+	p := findPlugin(nc.Provider)
+	if p == nil || !p.Notifications {
+		return nil, false
+	}
+	return pluginNotifier{plugin: p, options: nc.Options}, true
+}
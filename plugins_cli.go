@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// pluginsCommand lists the discovered plugins and what they add
+var pluginsCommand = &cliCommand{
+	Name:        "plugins",
+	Summary:     "List installed plugins with their commands, release steps and notifications",
+	Description: "Describes every executable in ~/.relix/plugins the way the TUI does at startup and prints what each plugin adds. Plugins that fail to describe themselves are reported with the error, which helps when writing a plugin.",
+	Examples:    []string{"relix plugins"},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			list := plugins()
+			if len(list) == 0 && len(pluginLoadErrs) == 0 {
+				dir, _ := getPluginsDir()
+				fmt.Printf("No plugins installed in %s\n", dir)
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "PLUGIN\tKIND\tNAME\tDESCRIPTION")
+			for _, p := range list {
+				for _, c := range p.Commands {
+					fmt.Fprintf(tw, "%s\tcommand\t%s:%s\t%s\n", p.Name, p.Name, c.Name, c.Description)
+				}
+				for _, s := range p.Steps {
+					fmt.Fprintf(tw, "%s\tstep\t%s (before %s)\t%s\n", p.Name, s.Name, s.Before, s.Description)
+				}
+				if p.Notifications {
+					fmt.Fprintf(tw, "%s\tnotifications\t%s\t\n", p.Name, p.Name)
+				}
+			}
+			tw.Flush()
+
+			for _, err := range pluginLoadErrs {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			return nil
+		}
+	},
+}
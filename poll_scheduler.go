@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Pipeline checks go through a shared scheduler: checks for several targets (MRs, possibly in
+// different projects) run in parallel instead of one after another, and the GitLab transport caps
+// the requests in flight per endpoint, so gating on many pipelines neither multiplies the wait nor
+// floods the instance with requests.
+const pipelinePollWorkers = 8 // Targets checked at once
+
+// endpointCaps limits the concurrent requests per API endpoint, across all GitLab clients
+var endpointCaps = map[string]int{
+	"merge_requests": 8,
+	"pipelines":      4,
+	"jobs":           4,
+}
+
+// gitlabLimiter is used by gitlabTransport
+var gitlabLimiter = newEndpointLimiter(endpointCaps)
+
+// pipelineTarget is an MR whose pipeline is checked
+type pipelineTarget struct {
+	projectID int
+	mrIID     int
+}
+
+// checkPipelines checks the pipelines of all targets in parallel.
+// Results are in the order of targets; the job filter from config is read once for the batch.
+func checkPipelines(creds Credentials, targets []pipelineTarget) []pipelineStatusMsg {
+	client := NewForge(creds)
+	var pipelineRegex *regexp.Regexp
+	if cfg, err := LoadConfig(); err == nil && cfg.PipelineJobsRegex != "" {
+		pipelineRegex, _ = regexp.Compile(cfg.PipelineJobsRegex)
+	}
+
+	results := make([]pipelineStatusMsg, len(targets))
+	workers := make(chan struct{}, pipelinePollWorkers)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			results[i] = checkPipeline(client, target, pipelineRegex)
+			<-workers
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// endpointLimiter holds a semaphore per capped endpoint
+type endpointLimiter struct {
+	slots map[string]chan struct{}
+}
+
+func newEndpointLimiter(caps map[string]int) *endpointLimiter {
+	l := &endpointLimiter{slots: make(map[string]chan struct{}, len(caps))}
+	for endpoint, n := range caps {
+		l.slots[endpoint] = make(chan struct{}, n)
+	}
+	return l
+}
+
+// endpointOf returns the capped endpoint a request path belongs to, or "".
+// The last capped segment wins: /projects/1/merge_requests/2/pipelines is "pipelines".
+func (l *endpointLimiter) endpointOf(path string) string {
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, ok := l.slots[segments[i]]; ok {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+// acquire waits for a free slot of the request's endpoint and returns the function releasing it.
+// It fails only if the request is canceled while waiting.
+func (l *endpointLimiter) acquire(req *http.Request) (func(), error) {
+	slots, ok := l.slots[l.endpointOf(req.URL.Path)]
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-slots }) }, nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+// limitedTransport keeps a slot of the request's endpoint until the response body is closed
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *endpointLimiter
+}
+
+func (t limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = releasingBody{resp.Body, release}
+	return resp, nil
+}
+
+// releasingBody frees the endpoint slot on Close
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
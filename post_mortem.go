@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Post-mortems: when a release is aborted, relix asks what went wrong: a category and a line of
+// text, saved with its history entry (esc skips it). "F" on the history detail screen of an aborted
+// release writes or edits it later. The category is kept in the history index, so the history
+// list filter finds releases by it, `relix history list --failure` lists them, and the home
+// dashboard counts the failures of the past postMortemWindow by category.
+
+// postMortemCategories are the failure categories to pick from
+var postMortemCategories = []string{
+	"merge conflict",
+	"pipeline failure",
+	"broken MR",
+	"wrong selection",
+	"environment",
+	"process",
+	"other",
+}
+
+// postMortemWindow is the period the dashboard counts failures over
+const postMortemWindow = 90 * 24 * time.Hour
+
+// failureCount is the number of aborted releases of a post-mortem category; "" counts the ones
+// without a post-mortem
+type failureCount struct {
+	category string
+	count    int
+}
+
+// countFailures counts the releases aborted since a time by post-mortem category, most frequent
+// first
+func countFailures(index []HistoryIndexEntry, since time.Time) []failureCount {
+	counts := make(map[string]int)
+	for _, e := range index {
+		if e.Status == "aborted" && e.DateTime.After(since) {
+			counts[e.FailureCategory]++
+		}
+	}
+	var failures []failureCount
+	for category, count := range counts {
+		failures = append(failures, failureCount{category, count})
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].count != failures[j].count {
+			return failures[i].count > failures[j].count
+		}
+		return failures[i].category < failures[j].category
+	})
+	return failures
+}
+
+// askPostMortem asks for the post-mortem of an aborted release, prefilled with what was written
+// before
+func (m *model) askPostMortem(id, category, text string) {
+	if id == "" {
+		return
+	}
+	m.closeAllModals()
+	ti := textinput.New()
+	ti.Placeholder = "what went wrong, e.g. migration failed on stage"
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	ti.CharLimit = 500
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.SetValue(text)
+	m.postMortemInput = ti
+	m.postMortemID = id
+	m.postMortemCategory = max(0, slices.Index(postMortemCategories, category))
+	m.postMortemFocus = 0
+	m.postMortemError = ""
+	m.showPostMortem = true
+}
+
+// updatePostMortem handles keys of the post-mortem modal: the category list is focused first,
+// tab moves to the text
+func (m model) updatePostMortem(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+q":
+		m.showPostMortem = false
+		return m, nil
+	case "tab", "shift+tab":
+		m.postMortemFocus = 1 - m.postMortemFocus
+		if m.postMortemFocus == 1 {
+			return m, m.postMortemInput.Focus()
+		}
+		m.postMortemInput.Blur()
+		return m, nil
+	case "enter":
+		return m.savePostMortem()
+	}
+
+	if m.postMortemFocus == 1 {
+		var cmd tea.Cmd
+		m.postMortemInput, cmd = m.postMortemInput.Update(msg)
+		m.postMortemError = ""
+		return m, cmd
+	}
+	switch msg.String() {
+	case "up", "k":
+		if m.postMortemCategory > 0 {
+			m.postMortemCategory--
+		}
+	case "down", "j":
+		if m.postMortemCategory < len(postMortemCategories)-1 {
+			m.postMortemCategory++
+		}
+	}
+	return m, nil
+}
+
+// savePostMortem saves the post-mortem to the history entry of the aborted release
+func (m model) savePostMortem() (tea.Model, tea.Cmd) {
+	category := postMortemCategories[m.postMortemCategory]
+	text := strings.Join(strings.Fields(m.postMortemInput.Value()), " ")
+	if err := SaveHistoryPostMortem(m.postMortemID, category, text); err != nil {
+		m.postMortemError = "Cannot save the post-mortem: " + err.Error()
+		return m, nil
+	}
+	m.showPostMortem = false
+	if m.historySelected != nil && m.historySelected.ID == m.postMortemID {
+		m.historySelected.FailureCategory = category
+		m.historySelected.PostMortem = text
+	}
+	var dashboardCmd tea.Cmd
+	if m.screen == screenHome {
+		dashboardCmd = m.loadDashboard()
+	}
+	return m, tea.Batch(m.showToast("Post-mortem saved: "+category), m.fetchHistory(), dashboardCmd)
+}
+
+// overlayPostMortem renders the post-mortem modal
+func (m model) overlayPostMortem(background string) string {
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("What went wrong?"))
+	sb.WriteString("\n\n")
+	sb.WriteString("The release was aborted. A short post-mortem is saved with its history entry.\n\n")
+
+	for i, category := range postMortemCategories {
+		switch {
+		case i == m.postMortemCategory && m.postMortemFocus == 0:
+			sb.WriteString(commandItemSelectedStyle.Render("▸ " + category))
+		case i == m.postMortemCategory:
+			sb.WriteString(commandItemStyle.Render("● " + category))
+		default:
+			sb.WriteString(commandItemStyle.Render("  " + category))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(m.postMortemInput.View())
+	if m.postMortemError != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(settingsErrorStyle.Render(m.postMortemError))
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(helpStyle.Render("j/k: category • tab: text • enter: save • esc: skip"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 64, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 72,
+		Style:    errorBoxStyle,
+	}
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
+
+// dashboardFailureLines counts the releases aborted in the past postMortemWindow by category
+func (m model) dashboardFailureLines() []string {
+	if !m.dashboard.releasesLoaded {
+		return []string{homeVersionStyle.Render("Loading…")}
+	}
+	if m.dashboard.releasesErr != nil {
+		return []string{settingsErrorStyle.Render(m.dashboard.releasesErr.Error())}
+	}
+	if len(m.dashboard.failures) == 0 {
+		return []string{homeVersionStyle.Render(fmt.Sprintf("No aborted releases in %d days", int(postMortemWindow.Hours()/24)))}
+	}
+	labels := make([]string, len(m.dashboard.failures))
+	width := 0
+	for i, f := range m.dashboard.failures {
+		labels[i] = f.category
+		if labels[i] == "" {
+			labels[i] = "no post-mortem"
+		}
+		width = max(width, len(labels[i]))
+	}
+	var lines []string
+	for i, f := range m.dashboard.failures {
+		lines = append(lines, homeMenuItemStyle.Render(fmt.Sprintf("%-*s", width, labels[i]))+" "+homeVersionStyle.Render(fmt.Sprint(f.count)))
+	}
+	return lines
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// progressBarWidth is the number of cells of the release progress bar
+const progressBarWidth = 20
+
+// Default progress bar characters: done, pending and the marker sweeping over the running step
+const (
+	defaultProgressFull   = "█"
+	defaultProgressEmpty  = "░"
+	defaultProgressActive = "▓"
+)
+
+// reduceMotion replaces animations with static text for users sensitive to motion and for slow
+// links (reduce_motion in the config): the spinner shows a fixed glyph and stops ticking, the
+// progress bar marker stays on the running step and text cursors don't blink
+var reduceMotion bool
+
+// staticSpinner is the spinner shown with reduceMotion
+var staticSpinner = spinner.Spinner{Frames: []string{"•"}, FPS: time.Second}
+
+// spinnerVariants are the spinners a theme can pick with "spinner"
+var spinnerVariants = map[string]spinner.Spinner{
+	"line":      spinner.Line,
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+	"monkey":    spinner.Monkey,
+	"meter":     spinner.Meter,
+	"hamburger": spinner.Hamburger,
+	"ellipsis":  spinner.Ellipsis,
+}
+
+// resolveSpinner returns the spinner variant of a theme with its animation rate in frames per
+// second, falling back to the default spinner and the variant's own rate
+func resolveSpinner(name string, fps int) spinner.Spinner {
+	s, ok := spinnerVariants[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		s = defaultThemeColors.Spinner
+	}
+	if fps > 0 {
+		s.FPS = time.Second / time.Duration(fps)
+	}
+	return s
+}
+
+// resolveProgressChar returns the first character of a theme's progress bar setting, or fallback
+func resolveProgressChar(value, fallback string) string {
+	r, _ := utf8.DecodeRuneInString(value)
+	if value == "" || r == utf8.RuneError || lipgloss.Width(string(r)) != 1 {
+		return fallback
+	}
+	return string(r)
+}
+
+// applySpinnerTheme restyles the shared spinner after the theme changed. A spinner with fewer
+// frames than the previous one shows its first frame from the next tick on. With reduceMotion
+// there are no ticks, so the static spinner is a new one, starting at its only frame. Terminals
+// without unicode glyphs get the ASCII spinner.
+func (m *model) applySpinnerTheme() {
+	style := lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	if reduceMotion {
+		m.spinner = spinner.New(spinner.WithSpinner(staticSpinner), spinner.WithStyle(style))
+		return
+	}
+	m.spinner.Spinner = currentTheme.Spinner
+	if termPolicy.ascii {
+		m.spinner.Spinner = spinner.Line
+	}
+	m.spinner.Style = style
+}
+
+// renderReleaseProgress renders the progress bar of a release followed by the percentage and the
+// sub-step count. Completed sub-steps fill the bar; while a step runs, a marker sweeps over the
+// cells of the running sub-step so a long step still shows activity, or marks its first cell with
+// reduceMotion.
+func (m model) renderReleaseProgress(state *ReleaseState) string {
+	completed := state.CompletedSubSteps
+	total := state.TotalSubSteps
+	if total == 0 {
+		total = 1
+	}
+	percentage := (completed * 100) / total
+	if state.CurrentStep == ReleaseStepComplete {
+		percentage = 100
+	}
+	text := fmt.Sprintf("%d%% [%d/%d]", percentage, completed, state.TotalSubSteps)
+
+	filled := min(percentage, 100) * progressBarWidth / 100
+	active := -1
+	if m.releaseRunning && filled < progressBarWidth {
+		// The running sub-step spans the cells up to the next sub-step's share of the bar
+		segment := max((completed+1)*progressBarWidth/total-filled, 1)
+		segment = min(segment, progressBarWidth-filled)
+		frame := 0
+		if !reduceMotion && !m.releaseStepStartedAt.IsZero() && currentTheme.Spinner.FPS > 0 {
+			frame = int(time.Since(m.releaseStepStartedAt) / currentTheme.Spinner.FPS)
+		}
+		active = filled + frame%segment
+	}
+
+	var bar strings.Builder
+	doneStyle := lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	pendingStyle := lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	bar.WriteString(doneStyle.Render(strings.Repeat(currentTheme.ProgressFull, filled)))
+	for i := filled; i < progressBarWidth; i++ {
+		if i == active {
+			bar.WriteString(doneStyle.Render(currentTheme.ProgressActive))
+		} else {
+			bar.WriteString(pendingStyle.Render(currentTheme.ProgressEmpty))
+		}
+	}
+	return bar.String() + " " + releasePercentStyle.Render(text)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Launched inside a clone of a GitLab project, relix selects that project and opens its MRs: the
+// origin remote of the working directory (or of -d) is matched against the GitLab instance of the
+// credentials, and the project is looked up by its path, so only projects the user can access are
+// selected. A toast confirms the switch. Resumed releases and disable_project_detection skip it.
+
+// startupProjectMsg carries the project of the clone relix was launched in; nil if there is none.
+// For a project given with --project, requested is its path.
+type startupProjectMsg struct {
+	project   *Project
+	requested string
+	err       error
+}
+
+// detectStartupProject looks up the project of the clone relix was launched in
+func detectStartupProject(creds Credentials) tea.Cmd {
+	return func() tea.Msg {
+		if config, err := LoadConfig(); err != nil || config.DisableProjectDetection {
+			return startupProjectMsg{}
+		}
+		client, ok := NewForge(creds).(*GitLabClient)
+		if !ok {
+			return startupProjectMsg{}
+		}
+		dir := projectDirectory
+		if dir == "" {
+			var err error
+			if dir, err = os.Getwd(); err != nil {
+				return startupProjectMsg{}
+			}
+		}
+		remote, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+		if err != nil {
+			return startupProjectMsg{} // Not a clone, or no origin
+		}
+		path, ok := remoteProjectPath(strings.TrimSpace(string(remote)), creds.GitLabURL)
+		if !ok {
+			return startupProjectMsg{}
+		}
+		project, err := client.GetProjectByPath(path)
+		if err != nil {
+			return startupProjectMsg{}
+		}
+		return startupProjectMsg{project: project}
+	}
+}
+
+// remoteProjectPath returns the project path of a git remote URL, e.g. "group/app" for
+// git@gitlab.example.com:group/app.git, if the remote is on the host of baseURL. Hosts are
+// compared without ports, as SSH remotes use a different one than the web URL.
+func remoteProjectPath(remote, baseURL string) (string, bool) {
+	base, err := neturl.Parse(baseURL)
+	if err != nil || base.Hostname() == "" {
+		return "", false
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := neturl.Parse(remote)
+		if err != nil {
+			return "", false
+		}
+		host, path = u.Hostname(), u.Path
+		if u.Scheme == "http" || u.Scheme == "https" {
+			// Instances under a relative URL root, e.g. https://example.com/gitlab
+			path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+		}
+	} else {
+		// scp-like syntax: [user@]host:path
+		hostPart, pathPart, ok := strings.Cut(remote, ":")
+		if !ok {
+			return "", false
+		}
+		if _, h, found := strings.Cut(hostPart, "@"); found {
+			hostPart = h
+		}
+		host, path = hostPart, pathPart
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !strings.EqualFold(host, base.Hostname()) || !strings.Contains(path, "/") {
+		return "", false
+	}
+	return path, true
+}
+
+// handleStartupProject selects the detected project and opens its MRs, unless the user has moved
+// on from the home screen in the meantime
+func (m *model) handleStartupProject(msg startupProjectMsg) tea.Cmd {
+	if msg.requested != "" {
+		return m.handleRequestedProject(msg)
+	}
+	if msg.project == nil || m.screen != screenHome || m.releaseState != nil ||
+		m.showCommandMenu || m.showProjectSelector || m.showErrorModal {
+		return nil
+	}
+	cmd := m.selectProject(*msg.project)
+	m.moveToScreen(screenMain)
+	toast := fmt.Sprintf("Selected %s from the current directory", msg.project.PathWithNamespace)
+	return tea.Batch(cmd, m.showToast(toast))
+}
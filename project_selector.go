@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -51,9 +52,17 @@ func (m model) updateProjectSelector(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.selectedProject != nil {
 			m.showProjectSelector = false
 			m.projectFilter = ""
+			m.filterProjects()
 		}
 		return m, nil
+	}
+
+	// Navigation acts on what the user typed, even if the debounce has not fired yet
+	if m.projectFilterPending {
+		m.filterProjects()
+	}
 
+	switch msg.String() {
 	case "up", "ctrl+p":
 		if m.projectSelectorIndex > 0 {
 			m.projectSelectorIndex--
@@ -61,37 +70,56 @@ func (m model) updateProjectSelector(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "down", "ctrl+n":
-		filtered := m.getFilteredProjects()
-		if m.projectSelectorIndex < len(filtered)-1 {
+		if m.projectSelectorIndex < len(m.projectMatches)-1 {
 			m.projectSelectorIndex++
+			return m, nil
 		}
-		return m, nil
+		// Past the last project: fetch the next page, if any
+		return m, m.loadMoreProjects()
 
 	case "enter":
-		filtered := m.getFilteredProjects()
-		if len(filtered) > 0 && m.projectSelectorIndex < len(filtered) {
-			selected := filtered[m.projectSelectorIndex]
-			m.selectedProject = &selected
-			m.showProjectSelector = false
-			m.projectFilter = ""
-
-			// Save to config
-			SaveSelectedProject(&selected)
-
-			// Reset list screen state (clears selections and hides old content)
-			m.initListScreen()
-			m.updateListSize()
+		if len(m.projectMatches) > 0 && m.projectSelectorIndex < len(m.projectMatches) {
+			cmd := m.selectProject(m.projectMatches[m.projectSelectorIndex].project)
+			return m, cmd
+		}
+		return m, nil
 
-			// Refresh MRs for the new project with loading modal
-			m.loadingMRs = true
-			return m, tea.Batch(m.spinner.Tick, m.fetchMRs())
+	case "ctrl+t":
+		// Pin the project to the home dashboard, or unpin it
+		if m.projectSelectorIndex < len(m.projectMatches) {
+			if pinned, err := TogglePinnedProject(m.projectMatches[m.projectSelectorIndex].project); err == nil {
+				m.pinnedProjects = pinned
+				return m, m.loadDashboard()
+			}
 		}
 		return m, nil
 
+	case "tab", "ctrl+a":
+		// Cycle the scope (all, starred, owned) or toggle archived projects, and list them anew
+		query := m.projectQuery
+		if msg.String() == "ctrl+a" {
+			query.Archived = !query.Archived
+		} else {
+			switch query.Scope {
+			case projectScopeAll:
+				query.Scope = projectScopeStarred
+			case projectScopeStarred:
+				query.Scope = projectScopeOwned
+			default:
+				query.Scope = projectScopeAll
+			}
+		}
+		if err := SaveProjectQuery(query); err != nil {
+			return m, nil
+		}
+		m.projects = nil
+		m.filterProjects()
+		return m, m.loadProjects()
+
 	case "backspace":
 		if len(m.projectFilter) > 0 {
 			m.projectFilter = m.projectFilter[:len(m.projectFilter)-1]
-			m.projectSelectorIndex = 0
+			return m, m.debounceProjectFilter()
 		}
 		return m, nil
 
@@ -101,29 +129,92 @@ func (m model) updateProjectSelector(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			char := msg.String()[0]
 			if char >= 32 && char < 127 {
 				m.projectFilter += msg.String()
-				m.projectSelectorIndex = 0
+				return m, m.debounceProjectFilter()
 			}
 		}
 		return m, nil
 	}
 }
 
-// getFilteredProjects returns projects matching the current filter
-func (m model) getFilteredProjects() []Project {
+// selectProject makes the project the selected one and lists its MRs
+func (m *model) selectProject(selected Project) tea.Cmd {
+	m.selectedProject = &selected
+	m.showProjectSelector = false
+	m.projectFilter = ""
+	m.filterProjects()
+
+	// Save to config
+	SaveSelectedProject(&selected)
+
+	// Reset list screen state (clears selections and hides old content)
+	m.initListScreen()
+	m.updateListSize()
+
+	// Show cached MRs of the new project while refreshing, or the loading modal.
+	// The dashboard shows the selected project while none are pinned.
+	loadCmd := tea.Batch(m.loadMRs(), m.useProjectRepoSettings())
+	if len(m.pinnedProjects) == 0 {
+		loadCmd = tea.Batch(loadCmd, m.loadDashboard())
+	}
+	return loadCmd
+}
+
+// selectedProjectPath returns the path of the selected project, or "" while none is selected
+func (m model) selectedProjectPath() string {
+	if m.selectedProject == nil {
+		return ""
+	}
+	return m.selectedProject.PathWithNamespace
+}
+
+// projectMatch is a project matching the selector filter
+type projectMatch struct {
+	project Project
+	matches []int // Matched rune positions in NameWithNamespace
+}
+
+// filterProjects applies the current filter to the project list and resets the cursor.
+// Projects are ranked by fuzzy match of the displayed name; projects matching only by
+// path (e.g. "sub-project" for "Sub Project") follow without highlighting.
+func (m *model) filterProjects() {
+	m.projectFilterPending = false
+	m.projectSelectorIndex = 0
+	m.projectMatches = make([]projectMatch, 0, len(m.projects))
+
 	if m.projectFilter == "" {
-		return m.projects
+		for _, p := range m.projects {
+			m.projectMatches = append(m.projectMatches, projectMatch{project: p})
+		}
+		return
+	}
+
+	names := make([]string, len(m.projects))
+	paths := make([]string, len(m.projects))
+	for i, p := range m.projects {
+		names[i] = p.NameWithNamespace
+		paths[i] = p.PathWithNamespace
 	}
 
-	filter := strings.ToLower(m.projectFilter)
-	var filtered []Project
-	for _, p := range m.projects {
-		name := strings.ToLower(p.NameWithNamespace)
-		path := strings.ToLower(p.PathWithNamespace)
-		if strings.Contains(name, filter) || strings.Contains(path, filter) {
-			filtered = append(filtered, p)
+	matched := make(map[int]bool)
+	for _, rank := range fuzzyFilter(m.projectFilter, names) {
+		m.projectMatches = append(m.projectMatches, projectMatch{project: m.projects[rank.Index], matches: rank.MatchedIndexes})
+		matched[rank.Index] = true
+	}
+	for _, rank := range fuzzyFilter(m.projectFilter, paths) {
+		if !matched[rank.Index] {
+			m.projectMatches = append(m.projectMatches, projectMatch{project: m.projects[rank.Index]})
 		}
 	}
-	return filtered
+}
+
+// debounceProjectFilter schedules the filter to be applied once typing pauses
+func (m *model) debounceProjectFilter() tea.Cmd {
+	m.projectFilterPending = true
+	m.projectFilterSeq++
+	seq := m.projectFilterSeq
+	return tea.Tick(filterDebounce, func(time.Time) tea.Msg {
+		return projectFilterMsg{seq: seq}
+	})
 }
 
 // overlayProjectSelector renders the project selector modal
@@ -135,6 +226,12 @@ func (m model) overlayProjectSelector(background string) string {
 	} else {
 		b.WriteString(commandMenuTitleStyle.Render("Select Project"))
 	}
+	b.WriteString(" " + helpStyle.Render("· "+m.projectQuery.String()))
+	if m.projectsCached {
+		b.WriteString(" " + helpStyle.Render("· cached, refreshing…"))
+	} else if m.offline {
+		b.WriteString(" " + helpStyle.Render("· "+staleLabel(m.projectsStaleAt)))
+	}
 	b.WriteString("\n")
 
 	// Show loading state
@@ -153,7 +250,7 @@ func (m model) overlayProjectSelector(background string) string {
 		b.WriteString("\n\n")
 
 		// Show filtered projects
-		filtered := m.getFilteredProjects()
+		filtered := m.projectMatches
 		maxVisible := 10
 		startIdx := 0
 
@@ -172,7 +269,7 @@ func (m model) overlayProjectSelector(background string) string {
 			b.WriteString("\n")
 		} else {
 			for i := startIdx; i < endIdx; i++ {
-				p := filtered[i]
+				p := filtered[i].project
 				isSelected := i == m.projectSelectorIndex
 				isActive := m.selectedProject != nil && m.selectedProject.ID == p.ID
 
@@ -191,11 +288,14 @@ func (m model) overlayProjectSelector(background string) string {
 					style = projectItemStyle
 				}
 
-				line := prefix + p.NameWithNamespace
+				line := style.Render(prefix) + m.renderProjectAvatar(p, helpStyle) + highlightMatches(p.NameWithNamespace, filtered[i].matches, style)
 				if isActive {
-					line += " (current)"
+					line += style.Render(" (current)")
 				}
-				b.WriteString(style.Render(line))
+				if m.isPinned(p.ID) {
+					line += helpStyle.Render(" · pinned")
+				}
+				b.WriteString(line)
 				b.WriteString("\n")
 			}
 
@@ -207,12 +307,25 @@ func (m model) overlayProjectSelector(background string) string {
 			}
 		}
 
+		// Show how many projects are loaded when the forge has more
+		if m.projectsLoadingMore {
+			b.WriteString(helpStyle.Render("  " + m.spinner.View() + " Loading more projects..."))
+			b.WriteString("\n")
+		} else if m.projectPage.HasMore {
+			more := fmt.Sprintf("  showing %d", len(m.projects))
+			if m.projectPage.Total > len(m.projects) {
+				more = fmt.Sprintf("  showing %d of %d", len(m.projects), m.projectPage.Total)
+			}
+			b.WriteString(helpStyle.Render(more + " · ↓ at the end loads more"))
+			b.WriteString("\n")
+		}
+
 		// Help footer
 		b.WriteString("\n")
 		if m.selectedProject == nil {
-			b.WriteString(helpStyle.Render("C+n/p: nav • enter: select (reqired)"))
+			b.WriteString(helpStyle.Render("C+n/p: nav • enter: select (reqired) • C+t: pin • tab: all/starred/owned • C+a: archived"))
 		} else {
-			b.WriteString(helpStyle.Render("C+n/p: nav • enter: select • esc/C+q: close"))
+			b.WriteString(helpStyle.Render("C+n/p: nav • enter: select • C+t: pin • tab: all/starred/owned • C+a: archived • esc/C+q: close"))
 		}
 	}
 
@@ -229,13 +342,85 @@ func (m model) overlayProjectSelector(background string) string {
 
 // fetchProjects creates a command to fetch projects from GitLab
 func (m *model) fetchProjects() tea.Cmd {
+	query := m.projectQuery
 	return func() tea.Msg {
 		if m.creds == nil {
-			return fetchProjectsMsg{err: nil}
+			return fetchProjectsMsg{query: query, err: nil}
 		}
 
-		client := NewGitLabClient(m.creds.GitLabURL, m.creds.Token)
-		projects, err := client.GetProjects()
-		return fetchProjectsMsg{projects: projects, err: err}
+		client := NewForge(*m.creds)
+		projects, page, err := client.GetProjectsPage(1, query)
+		if err == nil {
+			saveCache(projectsCacheName(query), m.creds.GitLabURL, projects)
+		}
+		return fetchProjectsMsg{query: query, projects: projects, page: page, err: err}
+	}
+}
+
+// loadMoreProjects fetches the next page of projects if the forge reported more.
+// The cached list is refreshed first, so it does not load more.
+func (m *model) loadMoreProjects() tea.Cmd {
+	if !m.projectPage.HasMore || m.projectsLoadingMore || m.projectsCached || m.creds == nil {
+		return nil
+	}
+	m.projectsLoadingMore = true
+
+	creds := *m.creds
+	query := m.projectQuery
+	page := m.projectPages + 1
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		projects, info, err := NewForge(creds).GetProjectsPage(page, query)
+		return fetchMoreProjectsMsg{query: query, page: page, projects: projects, info: info, err: err}
+	})
+}
+
+// appendProjectPage adds a page of projects to the ones loaded before, skipping projects
+// already loaded (pages shift as project activity changes the order)
+func appendProjectPage(loaded, page []Project) []Project {
+	seen := make(map[int]bool, len(loaded))
+	for _, p := range loaded {
+		seen[p.ID] = true
+	}
+	all := append([]Project{}, loaded...)
+	for _, p := range page {
+		if !seen[p.ID] {
+			all = append(all, p)
+		}
+	}
+	return all
+}
+
+// loadProjects shows cached projects right away and refreshes them in the background.
+// Without a cache it falls back to the loading state.
+func (m *model) loadProjects() tea.Cmd {
+	m.projectQuery = loadProjectQuery()
+	m.projectsLoadingMore = false
+	m.projectPages, m.projectPage = 0, listPage{}
+	if m.creds != nil {
+		if projects, ok := loadCachedProjects(m.creds.GitLabURL, m.projectQuery); ok {
+			m.projects = projects
+			m.projectsCached = true
+			m.filterProjects()
+			return m.fetchProjects()
+		}
+	}
+	m.loadingProjects = true
+	return tea.Batch(m.spinner.Tick, m.fetchProjects())
+}
+
+// replaceProjects swaps in a refreshed project list, keeping the cursor on the same project
+func (m *model) replaceProjects(projects []Project) {
+	focusedID := 0
+	if m.projectSelectorIndex < len(m.projectMatches) {
+		focusedID = m.projectMatches[m.projectSelectorIndex].project.ID
+	}
+
+	m.projects = projects
+	m.filterProjects()
+	for i, p := range m.projectMatches {
+		if p.project.ID == focusedID {
+			m.projectSelectorIndex = i
+			break
+		}
 	}
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Push strategies decide how "Push root branches" gets the release onto the base branch and
+// develop. The environment branch is always reached through the release MR; the base branch and
+// develop are pushed to directly, which fails on branches protected against pushes. With the MR
+// strategy the release root branch is merged into the base branch, and a release develop branch
+// into develop, through merge requests that relix merges via the API once they are approved (or
+// that the merge train merges, in projects using one). The strategy is set per environment with
+// push_strategy; "auto" (the default) uses merge requests for the branches the user cannot push to.
+const (
+	pushStrategyAuto = "auto"
+	pushStrategyPush = "push"
+	pushStrategyMR   = "mr"
+)
+
+// mergeApprovalTimeout bounds the wait for a merge request of the MR strategy to be approved
+const mergeApprovalTimeout = 24 * time.Hour
+
+// branchMergeStrategy tells whether the release reaches a branch through a merge request instead
+// of a push, and why. Auto falls back to pushing when protection cannot be read, e.g. on forges
+// other than GitLab. The base branch of a project using merge trains is always merged on the train.
+func branchMergeStrategy(creds *Credentials, state *ReleaseState, branch string) (bool, string, error) {
+	if state.MergeProtection.trains() && branch != "develop" {
+		return true, "the project uses merge trains", nil
+	}
+	switch state.Environment.PushStrategy {
+	case pushStrategyPush:
+		return false, "", nil
+	case pushStrategyMR:
+		return true, "push strategy mr", nil
+	case "", pushStrategyAuto:
+	default:
+		return false, "", fmt.Errorf("unknown push_strategy %q of %s (expected auto, push or mr)", state.Environment.PushStrategy, state.Environment.Name)
+	}
+	if creds == nil {
+		return false, "", nil
+	}
+	client, ok := NewForge(*creds).(*GitLabClient)
+	if !ok {
+		return false, "", nil
+	}
+	canPush, err := client.CanPushToBranch(state.ProjectID, branch)
+	if err != nil || canPush {
+		return false, "", nil
+	}
+	return true, branch + " is protected against pushes", nil
+}
+
+// mergeThroughMR merges the pushed source branch into target through a merge request and checks
+// target out at the result. The merge request is added to the merge train with train, otherwise
+// merged via the API as soon as it can be, i.e. once approved. It returns the merge commit; a retry
+// picks up the merge request opened before.
+func mergeThroughMR(ctx context.Context, creds Credentials, state *ReleaseState, source, target, reason string, train, removeSource bool, executor *GitExecutor, sender messageSender) (string, string, error) {
+	var output strings.Builder
+	printLine := releaseStepPrinter(sender, &output, fmt.Sprintf("merge %s into %s through a merge request (%s)", source, target, reason))
+	client, ok := NewForge(creds).(*GitLabClient)
+	if !ok {
+		return output.String(), "", fmt.Errorf("merging through merge requests needs GitLab credentials")
+	}
+
+	var mr *MergeRequest
+	if existing, err := client.GetMergeRequestBySourceBranch(state.ProjectID, source); err == nil &&
+		existing.TargetBranch == target && (existing.State == "opened" || existing.State == "merged") {
+		mr = &existing.MergeRequest
+		printLine(fmt.Sprintf("Merge request opened before: %s", mr.WebURL))
+	} else {
+		title := fmt.Sprintf("Merge %s into %s", source, target)
+		created, err := client.CreateMergeRequest(state.ProjectID, source, target, title, "")
+		recordAudit(auditMRCreate, fmt.Sprintf("project %d", state.ProjectID), fmt.Sprintf("%s -> %s: %s", source, target, title), err)
+		if err != nil {
+			return output.String(), "", fmt.Errorf("failed to create the merge request into %s: %w", target, err)
+		}
+		mr = created
+		printLine(fmt.Sprintf("Merge request created: %s", mr.WebURL))
+	}
+	if mr.State != "merged" && train {
+		if err := addToMergeTrain(creds, state.ProjectID, mr.IID); err != nil {
+			// Adding it again fails while it is on the train already
+			printLine(fmt.Sprintf("Not added to the merge train (%v), waiting for it to merge", err))
+		} else {
+			printLine("Added to the merge train")
+		}
+	}
+
+	timeout := mergeApprovalTimeout
+	if train {
+		timeout = mergeTrainTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	status := ""
+	for mr.State != "merged" {
+		if mr.State == "closed" {
+			return output.String(), "", fmt.Errorf("merge request !%d into %s was closed: %s", mr.IID, target, mr.WebURL)
+		}
+		if !train {
+			if mr.DetailedMergeStatus != status {
+				status = mr.DetailedMergeStatus
+				if status != "" {
+					printLine(fmt.Sprintf("Merge status: %s", strings.ReplaceAll(status, "_", " ")))
+				}
+			}
+			if status == "conflict" || mr.HasConflicts {
+				return output.String(), "", fmt.Errorf("merge request !%d into %s has conflicts: %s", mr.IID, target, mr.WebURL)
+			}
+			// GitLab before 15.6 reports only merge_status; merging then fails until it is approved
+			if status == "mergeable" || (status == "" && mr.MergeStatus == "can_be_merged") {
+				// Failures are not recorded: they repeat until the merge request can be merged
+				if err := client.AcceptMergeRequest(state.ProjectID, mr.IID, removeSource); err == nil {
+					recordAudit(auditMRMerge, fmt.Sprintf("project %d", state.ProjectID), fmt.Sprintf("!%d %s -> %s", mr.IID, source, target), nil)
+					printLine("Merged via the API")
+				}
+			}
+		}
+		if time.Now().After(deadline) {
+			return output.String(), "", fmt.Errorf("merge request !%d was not merged in %s: %s", mr.IID, timeout, mr.WebURL)
+		}
+		if err := waitPollInterval(ctx); err != nil {
+			return output.String(), "", err
+		}
+		current, err := client.GetMergeRequestStatus(state.ProjectID, mr.IID)
+		if err != nil {
+			if isNetworkError(err) {
+				continue // Keep waiting through short outages; the deadline still applies
+			}
+			return output.String(), "", fmt.Errorf("failed to get merge request !%d: %w", mr.IID, err)
+		}
+		mr = current
+	}
+	printLine(fmt.Sprintf("Merged into %s", target))
+
+	checkout, err := executor.RunCommands([]string{
+		fmt.Sprintf("git fetch origin %s", target),
+		fmt.Sprintf("git checkout %s", target),
+		fmt.Sprintf("git reset --hard origin/%s", target),
+	})
+	output.WriteString(checkout)
+	if err != nil {
+		return output.String(), "", err
+	}
+	commit := mr.MergeCommitSHA
+	if commit == "" {
+		commit = "HEAD" // Fast-forward merges have no merge commit
+	}
+	return output.String(), commit, nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Teams that keep a linear, curated release history can rework the source branch before anything
+// is pushed: "r" on the release screen, while the release waits for Create MR, lists the commits
+// the MRs brought and lets them be reordered, squashed, fixed up or dropped. Applying runs git
+// rebase -i with the edited todo list, which also flattens the merge commits of the MRs, then
+// builds the environment branch again from the rebased source branch. A rebase that stops (e.g. on
+// a conflict) is aborted and the release is left as it was. Commits already on the remote source
+// branch are kept as they are.
+
+// Actions of a commit in the rebase todo list
+const (
+	rebasePick   = "pick"
+	rebaseSquash = "squash"
+	rebaseFixup  = "fixup"
+	rebaseDrop   = "drop"
+)
+
+// rebaseActionKeys maps the editor keys to the actions they set
+var rebaseActionKeys = map[string]string{
+	"p": rebasePick,
+	"s": rebaseSquash,
+	"f": rebaseFixup,
+	"d": rebaseDrop,
+}
+
+// rebaseCommit is a commit of the source branch in the rebase editor
+type rebaseCommit struct {
+	sha     string
+	subject string
+	action  string
+}
+
+// rebaseCommitsMsg carries the commits of the source branch that can be rebased
+type rebaseCommitsMsg struct {
+	upstream string
+	commits  []rebaseCommit
+	err      error
+}
+
+// rebaseDoneMsg reports the rebase of the source branch
+type rebaseDoneMsg struct {
+	output string
+	err    error
+}
+
+// rebaseUpstream returns what the source branch is rebased onto: the base branch, or the remote
+// source branch when the release continues one, as its pushed commits cannot be rewritten
+func rebaseUpstream(state *ReleaseState) string {
+	if state.SourceBranchIsRemote {
+		return "origin/" + state.SourceBranch
+	}
+	if state.BaseBranch == "" {
+		return "root"
+	}
+	return state.BaseBranch
+}
+
+// listRebaseCommits returns the commits of source not in upstream, oldest first, as git rebase
+// lists them: merge commits and changes upstream already has are left out
+func listRebaseCommits(workDir, upstream, source string) ([]rebaseCommit, error) {
+	out, err := gitOutput(workDir, "log", "--reverse", "--topo-order", "--no-merges", "--cherry-pick", "--right-only",
+		"--format=%H%x09%s", upstream+"..."+source)
+	if err != nil {
+		return nil, fmt.Errorf("list commits of %s: %w", source, err)
+	}
+	var commits []rebaseCommit
+	for _, line := range strings.Split(out, "\n") {
+		sha, subject, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		commits = append(commits, rebaseCommit{sha: sha, subject: subject, action: rebasePick})
+	}
+	return commits, nil
+}
+
+// rebaseTodo returns the todo list of git rebase -i for the commits, refusing lists git would
+// refuse or that leave nothing to release
+func rebaseTodo(commits []rebaseCommit) (string, error) {
+	var sb strings.Builder
+	picked := false
+	for _, c := range commits {
+		switch c.action {
+		case rebaseDrop:
+		case rebaseSquash, rebaseFixup:
+			if !picked {
+				return "", fmt.Errorf("the first kept commit cannot be a %s: there is no commit before it", c.action)
+			}
+		default:
+			picked = true
+		}
+		fmt.Fprintf(&sb, "%s %s %s\n", c.action, c.sha, c.subject)
+	}
+	if !picked {
+		return "", fmt.Errorf("keep at least one commit")
+	}
+	return sb.String(), nil
+}
+
+// runRebase rebases source onto upstream with the todo list, aborting a rebase that stops
+func runRebase(workDir, upstream, source, todo string) (string, error) {
+	f, err := os.CreateTemp("", "relix-rebase-*.todo")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(todo); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	cmd := exec.Command("git", "rebase", "--quiet", "-i", upstream, source)
+	cmd.Dir = workDir
+	// The sequence editor replaces the todo list git wrote with ours; squashes keep both messages
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(f.Name()), "GIT_EDITOR=true")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		abort := exec.Command("git", "rebase", "--abort")
+		abort.Dir = workDir
+		abort.Run()
+		return string(out), fmt.Errorf("rebase of %s stopped and was aborted: %w", source, err)
+	}
+	return string(out), nil
+}
+
+// openRebaseEditor lists the commits of the source branch in the rebase editor
+func (m model) openRebaseEditor() (tea.Model, tea.Cmd) {
+	state := m.releaseState
+	if state == nil || state.CurrentStep != ReleaseStepWaitForMR || m.releaseRunning {
+		return m, nil
+	}
+	m.showRebaseEditor = true
+	m.rebaseLoading = true
+	m.rebaseErr = nil
+	m.rebaseCommits = nil
+	m.rebaseIndex = 0
+
+	workDir, upstream, source := state.WorkDir, rebaseUpstream(state), state.SourceBranch
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		commits, err := listRebaseCommits(workDir, upstream, source)
+		return rebaseCommitsMsg{upstream: upstream, commits: commits, err: err}
+	})
+}
+
+// closeRebaseEditor closes the rebase editor and clears its state
+func (m *model) closeRebaseEditor() {
+	m.showRebaseEditor = false
+	m.rebaseLoading = false
+	m.rebaseErr = nil
+	m.rebaseCommits = nil
+	m.rebaseIndex = 0
+}
+
+// handleRebaseCommits fills the rebase editor with the listed commits
+func (m *model) handleRebaseCommits(msg rebaseCommitsMsg) {
+	if !m.showRebaseEditor {
+		return
+	}
+	m.rebaseLoading = false
+	m.rebaseErr = msg.err
+	m.rebaseUpstream = msg.upstream
+	m.rebaseCommits = msg.commits
+}
+
+// updateRebaseEditor handles key events of the rebase editor
+func (m model) updateRebaseEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+q", "q", "esc":
+		m.closeRebaseEditor()
+		return m, nil
+	case "down", "j":
+		m.rebaseIndex = min(m.rebaseIndex+1, max(len(m.rebaseCommits)-1, 0))
+		return m, nil
+	case "up", "k":
+		m.rebaseIndex = max(m.rebaseIndex-1, 0)
+		return m, nil
+	case "J", "shift+down":
+		if m.rebaseIndex < len(m.rebaseCommits)-1 {
+			c := m.rebaseCommits
+			c[m.rebaseIndex], c[m.rebaseIndex+1] = c[m.rebaseIndex+1], c[m.rebaseIndex]
+			m.rebaseIndex++
+		}
+		return m, nil
+	case "K", "shift+up":
+		if m.rebaseIndex > 0 && m.rebaseIndex < len(m.rebaseCommits) {
+			c := m.rebaseCommits
+			c[m.rebaseIndex], c[m.rebaseIndex-1] = c[m.rebaseIndex-1], c[m.rebaseIndex]
+			m.rebaseIndex--
+		}
+		return m, nil
+	case "enter":
+		return m.applyRebase()
+	}
+	if action, ok := rebaseActionKeys[key]; ok && m.rebaseIndex < len(m.rebaseCommits) {
+		m.rebaseCommits[m.rebaseIndex].action = action
+		m.rebaseErr = nil
+	}
+	return m, nil
+}
+
+// applyRebase rebases the source branch with the edited todo list
+func (m model) applyRebase() (tea.Model, tea.Cmd) {
+	state := m.releaseState
+	if state == nil || m.rebaseLoading || len(m.rebaseCommits) == 0 {
+		return m, nil
+	}
+	todo, err := rebaseTodo(m.rebaseCommits)
+	if err != nil {
+		m.rebaseErr = err
+		return m, nil
+	}
+	upstream := m.rebaseUpstream
+	m.closeRebaseEditor()
+	m.cancelRemoteApproval() // Create MR is asked for again once the environment branch is rebuilt
+	m.releaseRunning = true
+	m.appendReleaseOutput(fmt.Sprintf("Rebasing %s onto %s...", releaseOrangeStyle.Render(state.SourceBranch), upstream))
+
+	workDir, source := state.WorkDir, state.SourceBranch
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		output, err := runRebase(workDir, upstream, source, todo)
+		return rebaseDoneMsg{output: output, err: err}
+	})
+}
+
+// handleRebaseDone builds the environment branch again from the rebased source branch, or reports
+// why the rebase was aborted
+func (m *model) handleRebaseDone(msg rebaseDoneMsg) tea.Cmd {
+	m.releaseRunning = false
+	state := m.releaseState
+	if state == nil {
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimRight(msg.output, "\n"), "\n") {
+		if line != "" {
+			m.addReleaseOutput(line)
+		}
+	}
+	if msg.err != nil {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("ERROR: " + msg.err.Error()))
+		// Back to the environment branch the release left checked out
+		cmds := NewReleaseCommands(state.WorkDir, state.Version, state.BaseBranch, &state.Environment, nil, nil)
+		gitOutput(state.WorkDir, "checkout", "-q", cmds.EnvReleaseBranch())
+		return nil
+	}
+
+	if err := state.moveTo(ReleaseStepCheckoutEnv); err != nil {
+		m.appendReleaseOutput("ERROR: " + err.Error())
+		return nil
+	}
+	// The environment branch is built again: its sub-steps are counted again
+	state.CompletedSubSteps = 2 + len(state.MRBranches)
+	m.appendReleaseOutput("Building the environment branch again from the rebased source branch")
+	m.saveReleaseProgress()
+	m.releaseRunning = true
+	return tea.Batch(m.spinner.Tick, m.executeReleaseStep(ReleaseStepCheckoutEnv))
+}
+
+// overlayRebaseEditor renders the rebase editor over the release screen
+func (m model) overlayRebaseEditor(background string) string {
+	var sb strings.Builder
+	title := "Rebase"
+	if m.releaseState != nil {
+		title += " " + m.releaseState.SourceBranch
+	}
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render(title))
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.rebaseLoading:
+		sb.WriteString(m.spinner.View() + " Listing commits...\n")
+	case m.rebaseErr != nil && len(m.rebaseCommits) == 0:
+		sb.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Error).Render(m.rebaseErr.Error()) + "\n")
+	case len(m.rebaseCommits) == 0:
+		sb.WriteString(helpStyle.Render("No commits to rebase onto "+m.rebaseUpstream) + "\n")
+	default:
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("%d commits onto %s, oldest first", len(m.rebaseCommits), m.rebaseUpstream)))
+		sb.WriteString("\n\n")
+		visible := max(m.height-16, 3)
+		start := min(max(m.rebaseIndex-visible/2, 0), max(len(m.rebaseCommits)-visible, 0))
+		end := min(start+visible, len(m.rebaseCommits))
+		for i := start; i < end; i++ {
+			c := m.rebaseCommits[i]
+			actionStyle := lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+			switch c.action {
+			case rebaseSquash, rebaseFixup:
+				actionStyle = actionStyle.Foreground(currentTheme.Accent)
+			case rebaseDrop:
+				actionStyle = actionStyle.Foreground(currentTheme.Error).Strikethrough(true)
+			}
+			line := fmt.Sprintf("%-6s %s %s", c.action, c.sha[:min(len(c.sha), 7)], truncateWithEllipsis(c.subject, 50))
+			if i == m.rebaseIndex {
+				sb.WriteString(commandItemSelectedStyle.Render("▸ ") + actionStyle.Bold(true).Render(line))
+			} else {
+				sb.WriteString("  " + actionStyle.Render(line))
+			}
+			sb.WriteString("\n")
+		}
+		if m.rebaseErr != nil {
+			sb.WriteString("\n" + lipgloss.NewStyle().Foreground(currentTheme.Error).Render(m.rebaseErr.Error()) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("j/k: nav • J/K: move • p: pick • s: squash • f: fixup • d: drop • enter: rebase • C+q: close"))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 80, Percent: true},
+		MinWidth: 50,
+		MaxWidth: 100,
+		Style:    commandMenuStyle,
+	}
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
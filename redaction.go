@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Commands and hooks can echo secrets into the release terminal, e.g. a token in a remote URL or
+// a password a hook prints while debugging. Every line of the release terminal is redacted before
+// it is shown, so the spill file, the saved release state and the history log never hold them.
+// Redacted are the matches of built-in patterns for common tokens and credentials, of the
+// redact_patterns of the config, and the secrets relix knows itself: the forge token, the
+// credentials of notifications, release notes and deployment pipelines, and the values of release
+// variables named like secrets (see secretVariableName). A pattern with a group
+// named "secret" only has that group replaced, keeping e.g. the "password=" before it. Trace spans
+// and crash reports are redacted the same way.
+
+// redactedSecret replaces a redacted secret
+const redactedSecret = "***"
+
+// minKnownSecretLength is the length below which known secrets are not redacted, as short values
+// would mask ordinary words of the output
+const minKnownSecretLength = 6
+
+// builtinRedactPatterns match common secrets. Values stop at whitespace, quotes and escape
+// sequences, as output lines carry colors.
+var builtinRedactPatterns = []string{
+	`gl(?:pat|dt|rt|cbt|ptt|ft|soat|imt)-[A-Za-z0-9_-]{20,}`,          // GitLab tokens
+	`gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}`,         // GitHub tokens
+	`xox[abposr]-[A-Za-z0-9-]{10,}`,                                   // Slack tokens
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,                                   // AWS access key IDs
+	`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`, // JWTs
+	`://[^/\s:@\x1b]+:(?P<secret>[^/\s@\x1b]+)@`,                      // Credentials in URLs
+	`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]{8,}=*)`,              // Authorization headers
+	// Assignments such as password=... or "token": "..."
+	`(?i)(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)["']?\s*[=:]\s*["']?(?P<secret>[^\s"',;&\x1b]{4,})`,
+}
+
+// redactor redacts the secrets of output lines
+type redactor struct {
+	patterns []*regexp.Regexp
+	secrets  []string // Known secrets, longest first
+	errs     []error  // Invalid redact_patterns, which are skipped
+}
+
+// liveRedactor is the redactor of the current config and known secrets, built on first use after
+// either changes. The release terminal writes from its own goroutine.
+var liveRedactor atomic.Pointer[redactor]
+
+// knownSecrets holds the secrets registered by registerSecret
+var knownSecrets struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+// registerSecret adds a secret relix uses, e.g. the forge token, to the redacted ones
+func registerSecret(secret string) {
+	if len(secret) < minKnownSecretLength {
+		return
+	}
+	knownSecrets.mu.Lock()
+	defer knownSecrets.mu.Unlock()
+	if knownSecrets.values[secret] {
+		return
+	}
+	if knownSecrets.values == nil {
+		knownSecrets.values = make(map[string]bool)
+	}
+	knownSecrets.values[secret] = true
+	liveRedactor.Store(nil)
+}
+
+// configSecrets returns the credentials set in the config
+func configSecrets(config *AppConfig) []string {
+	var secrets []string
+	for _, n := range config.Notifications {
+		secrets = append(secrets, n.BotToken, n.Secret, n.Password)
+	}
+	for _, r := range config.ReleaseNotes {
+		secrets = append(secrets, r.Token)
+	}
+	if config.HistorySync != nil {
+		secrets = append(secrets, config.HistorySync.Token)
+	}
+	for _, env := range config.Environments {
+		secrets = append(secrets, variableSecrets(env.Variables)...)
+		if env.DeployPipeline != nil {
+			secrets = append(secrets, env.DeployPipeline.TriggerToken)
+			secrets = append(secrets, variableSecrets(env.DeployPipeline.Variables)...)
+		}
+	}
+	return secrets
+}
+
+// secretVariableName matches the names of release variables holding secrets, e.g. DEPLOY_TOKEN.
+// Other values, such as an environment name, would mask ordinary words of the output.
+var secretVariableName = regexp.MustCompile(`(?i)password|passwd|secret|token|api[_-]?key|access[_-]?key|credential|private[_-]?key`)
+
+// variableSecrets returns the values of the variables named like secrets
+func variableSecrets(vars map[string]string) []string {
+	var secrets []string
+	for name, value := range vars {
+		if secretVariableName.MatchString(name) {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// newRedactor builds a redactor from the user patterns and known secrets
+func newRedactor(userPatterns []string, secrets []string) *redactor {
+	r := &redactor{}
+	for _, expr := range builtinRedactPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(expr))
+	}
+	for _, expr := range userPatterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("redact_patterns: %q: %w", expr, err))
+			continue
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range secrets {
+		if len(s) >= minKnownSecretLength && !seen[s] {
+			seen[s] = true
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	// Longer secrets first, so one containing another is replaced whole
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	return r
+}
+
+// currentRedactor returns the redactor of the current config and known secrets
+func currentRedactor() *redactor {
+	if r := liveRedactor.Load(); r != nil {
+		return r
+	}
+	var patterns, secrets []string
+	if config, err := LoadConfig(); err == nil {
+		patterns = config.RedactPatterns
+		secrets = configSecrets(config)
+	}
+	knownSecrets.mu.Lock()
+	for s := range knownSecrets.values {
+		secrets = append(secrets, s)
+	}
+	knownSecrets.mu.Unlock()
+
+	r := newRedactor(patterns, secrets)
+	liveRedactor.Store(r)
+	return r
+}
+
+// redactSecrets replaces the secrets in s
+func redactSecrets(s string) string {
+	if s == "" {
+		return s
+	}
+	return currentRedactor().redact(s)
+}
+
+// redact replaces the secrets in s
+func (r *redactor) redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedSecret)
+	}
+	for _, re := range r.patterns {
+		group := re.SubexpIndex("secret")
+		matches := re.FindAllStringSubmatchIndex(s, -1)
+		if matches == nil {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if group > 0 && m[2*group] >= 0 {
+				start, end = m[2*group], m[2*group+1]
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(redactedSecret)
+			last = end
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
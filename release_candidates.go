@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// An environment with release_candidates releases candidates: every release of a version cuts
+// the next one, tagged with the version suffix ("-rc.{n}" by default), e.g. v2.4.0-rc.1. A version
+// is released again to cut rc.2, from the same release branch, so the MRs selected then are added
+// to the ones of rc.1. The version screen lists the candidates cut so far and suggests the version
+// of an open cycle. The latest candidate is promoted from its history entry ("P" on the history
+// detail screen): the commit of its tag gets the final tag, the formatted version without the
+// suffix (v2.4.0), and a history entry links the candidate it made final ("promoted_from"). Once
+// promoted, the version gets no more candidates.
+
+// defaultCandidateSuffix is the version suffix of release candidates without a version_suffix
+const defaultCandidateSuffix = "-rc.{n}"
+
+// promotedTagName returns the final tag of the candidates of version: the environment's formatted
+// version without the suffix
+func promotedTagName(env Environment, version string) string {
+	env.VersionSuffix = ""
+	return FormatReleaseVersion(env, version, 0)
+}
+
+// versionCandidates returns the tags of the candidates before the v-number vNumber. With {date} in
+// the version format, the tags are looked up in workDir, as each candidate has the day it was cut.
+func versionCandidates(workDir string, env Environment, version string, vNumber int) []string {
+	dated := strings.Contains(env.VersionSuffix+env.BuildMetadata, "{date}")
+	var tags []string
+	for n := 1; n < vNumber; n++ {
+		tag := ReleaseTagName(env, version, n)
+		if dated {
+			cmd := exec.Command("git", "tag", "--list", "--sort=-creatordate", releaseTagName(env, version, n, "*"))
+			cmd.Dir = workDir
+			if output, err := cmd.Output(); err == nil {
+				if found, _, _ := strings.Cut(string(output), "\n"); found != "" {
+					tag = found
+				}
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// existingTagError returns the version screen error about a tag of the entered version that exists
+func (m model) existingTagError(tag string) string {
+	if m.selectedEnv != nil && m.selectedEnv.ReleaseCandidates && tag == promotedTagName(*m.selectedEnv, m.versionInput.Value()) {
+		return fmt.Sprintf("%s was promoted to %s; pick another version", m.versionInput.Value(), tag)
+	}
+	return fmt.Sprintf("Tag %s already exists; pick another version", tag)
+}
+
+// candidateLineage returns the completed candidates of the version of entry in its project and
+// environment, oldest first, and their promotion, if any. index is newest first.
+func candidateLineage(index []HistoryIndexEntry, entry HistoryIndexEntry) ([]HistoryIndexEntry, *HistoryIndexEntry) {
+	var candidates []HistoryIndexEntry
+	var promotion *HistoryIndexEntry
+	for i := len(index) - 1; i >= 0; i-- {
+		e := index[i]
+		if e.Status != "completed" || e.ProjectID != entry.ProjectID || e.Version != entry.Version || !strings.EqualFold(e.Environment, entry.Environment) {
+			continue
+		}
+		switch {
+		case e.PromotedFrom != "":
+			promotion = &index[i]
+		case e.Candidate > 0:
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates, promotion
+}
+
+// promotionProblem returns why entry cannot be promoted, "" if it can
+func promotionProblem(index []HistoryIndexEntry, entry HistoryIndexEntry) string {
+	if entry.Candidate == 0 || entry.Status != "completed" {
+		return "only completed release candidates can be promoted"
+	}
+	candidates, promotion := candidateLineage(index, entry)
+	if promotion != nil {
+		return fmt.Sprintf("%s was promoted already, to %s", entry.Version, historyGitTag(*promotion))
+	}
+	if len(candidates) > 0 && candidates[len(candidates)-1].ID != entry.ID {
+		return fmt.Sprintf("%s was cut after it; promote the latest candidate", historyGitTag(candidates[len(candidates)-1]))
+	}
+	return ""
+}
+
+// candidatePromotedMsg reports the promotion of a release candidate
+type candidatePromotedMsg struct {
+	candidate string // Tag of the candidate
+	tag       string // Final tag
+	output    string
+	err       error
+}
+
+// askPromote asks to confirm promoting the release candidate shown in the history detail screen
+func (m *model) askPromote() {
+	m.closeAllModals()
+	entry := m.historySelected
+	if entry == nil {
+		return
+	}
+	fail := func(msg string) {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot promote: " + msg
+	}
+	// The history entry of the promotion is saved like a release of this tab
+	if m.releaseState != nil {
+		fail("this tab has an unfinished release. Finish it, or open a new tab with Alt+N.")
+		return
+	}
+	if m.selectedProject == nil {
+		fail("no project selected")
+		return
+	}
+	// The final tag is pushed in the selected project's checkout
+	if problem := m.historyProjectProblem(entry); problem != "" {
+		fail(problem)
+		return
+	}
+	env, ok := findEnvironment(entry.Environment)
+	if !ok {
+		fail(fmt.Sprintf("environment %s is no longer configured", entry.Environment))
+		return
+	}
+	if !env.ReleaseCandidates {
+		fail(fmt.Sprintf("%s does not release candidates", env.Name))
+		return
+	}
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	if problem := promotionProblem(index, entry.HistoryIndexEntry); problem != "" {
+		fail(problem)
+		return
+	}
+	m.promoteConfirmIndex = 1 // Cancel focused by default
+	m.showPromoteConfirm = true
+}
+
+// updatePromoteConfirm handles keys of the promotion confirmation
+func (m model) updatePromoteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		return m.startPromote()
+	case "n", "N", "esc":
+		m.showPromoteConfirm = false
+		return m, nil
+	case "enter":
+		if m.promoteConfirmIndex == 0 {
+			return m.startPromote()
+		}
+		m.showPromoteConfirm = false
+		return m, nil
+	case "tab", "left", "right", "h", "l":
+		m.promoteConfirmIndex = 1 - m.promoteConfirmIndex
+		return m, nil
+	}
+	return m, nil
+}
+
+// startPromote tags the commit of the candidate shown in the history detail screen with the final
+// tag, pushes it and records the promotion in history
+func (m model) startPromote() (tea.Model, tea.Cmd) {
+	m.showPromoteConfirm = false
+	entry := m.historySelected
+	if entry == nil || m.selectedProject == nil || m.historyProjectProblem(entry) != "" {
+		return m, nil
+	}
+	env, ok := findEnvironment(entry.Environment)
+	if !ok {
+		return m, nil
+	}
+	project, path, tab := m.selectedProject, m.selectedProjectPath(), m.tabID
+	candidate, final := historyGitTag(entry.HistoryIndexEntry), promotedTagName(env, entry.Version)
+	toastCmd := m.showToast(fmt.Sprintf("Promoting %s to %s…", candidate, final))
+
+	return m, tea.Batch(toastCmd, func() tea.Msg {
+		msg := candidatePromotedMsg{candidate: candidate, tag: final}
+		workDir, err := projectWorkDir(project)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		var output []string
+		run := func(args ...string) error {
+			output = append(output, "$ git "+strings.Join(args, " "))
+			cmd := exec.Command("git", args...)
+			cmd.Dir = workDir
+			out, err := cmd.CombinedOutput()
+			if text := strings.TrimSpace(string(out)); text != "" {
+				output = append(output, strings.Split(text, "\n")...)
+			}
+			return err
+		}
+		err = run("fetch", "origin", "--tags")
+		if err == nil {
+			err = run("tag", final, candidate+"^{commit}")
+		}
+		if err == nil {
+			err = run("push", "origin", "refs/tags/"+final)
+		}
+		recordAudit(auditReleasePromote, env.Name, fmt.Sprintf("%s -> %s", candidate, final), err)
+		msg.output = strings.Join(output, "\n")
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		// The promotion lists the MRs of every candidate of the cycle
+		state := &ReleaseState{
+			ProjectID:      project.ID,
+			Environment:    env,
+			Version:        entry.Version,
+			SourceBranch:   entry.SourceBranch,
+			RootMerge:      entry.RootMerge,
+			EnvMergeMode:   entry.EnvMergeMode,
+			CreatedMRURL:   entry.CreatedMRURL,
+			TagName:        final,
+			ReleaseVersion: final,
+			PromotedFrom:   entry.ID,
+		}
+		index, _ := LoadHistoryIndex()
+		candidates, _ := candidateLineage(index, entry.HistoryIndexEntry)
+		for _, c := range candidates {
+			detail, err := LoadHistoryDetail(c.ID)
+			if err != nil {
+				continue
+			}
+			appendCandidateMRs(state, detail)
+		}
+		lines := append([]string{fmt.Sprintf("Promoting %s to %s (%s)", candidate, final, formatDateTime(time.Now()))}, output...)
+		id, err := SaveReleaseHistory(tab, path, state, "completed", lines)
+		if err != nil {
+			msg.err = fmt.Errorf("%s was pushed, but the promotion was not saved to history: %w", final, err)
+			return msg
+		}
+		syncHistoryEntry(id)
+		return msg
+	})
+}
+
+// appendCandidateMRs adds the MRs of a candidate to the promotion. The MR fields are parallel to
+// MRBranches; candidates saved by older versions lack some of them, so each is fitted to the
+// candidate's MRs to keep the following candidates lined up.
+func appendCandidateMRs(state *ReleaseState, detail *ReleaseHistoryEntry) {
+	n := len(detail.MRBranches)
+	state.MRBranches = append(state.MRBranches, detail.MRBranches...)
+	state.MRURLs = append(state.MRURLs, fitMRField(detail.MRURLs, n)...)
+	state.SelectedMRIIDs = append(state.SelectedMRIIDs, fitMRField(detail.MRIIDs, n)...)
+	state.MRCommitSHAs = append(state.MRCommitSHAs, fitMRField(detail.MRCommitSHAs, n)...)
+	state.MRTitles = append(state.MRTitles, fitMRField(detail.MRTitles, n)...)
+	state.MRAuthors = append(state.MRAuthors, fitMRField(detail.MRAuthors, n)...)
+}
+
+// fitMRField pads an MR field of a history entry with zero values, or cuts it, to n MRs
+func fitMRField[T any](values []T, n int) []T {
+	if len(values) >= n {
+		return values[:n]
+	}
+	return append(values[:len(values):len(values)], make([]T, n-len(values))...)
+}
+
+// handleCandidatePromoted reports the promotion and reloads the history
+func (m *model) handleCandidatePromoted(msg candidatePromotedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Promoting %s to %s failed: %v", msg.candidate, msg.tag, msg.err)
+		if msg.output != "" {
+			m.errorModalMsg += "\n\n" + msg.output
+		}
+		return m.fetchHistory()
+	}
+	return tea.Batch(m.showToast(fmt.Sprintf("Promoted %s to %s", msg.candidate, msg.tag)), m.fetchHistory())
+}
+
+// overlayPromoteConfirm renders the promotion confirmation modal
+func (m model) overlayPromoteConfirm(background string) string {
+	entry := m.historySelected
+	if entry == nil {
+		return background
+	}
+	env, ok := findEnvironment(entry.Environment)
+	if !ok {
+		return background
+	}
+
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("Promote " + historyGitTag(entry.HistoryIndexEntry) + "?"))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "Tag its commit as %s and push the tag. %s gets no more candidates afterwards.\n\n",
+		promotedTagName(env, entry.Version), entry.Version)
+
+	var promoteBtn, cancelBtn string
+	if m.promoteConfirmIndex == 0 {
+		promoteBtn = buttonActiveStyle.Render("Promote")
+		cancelBtn = buttonStyle.Render("Cancel")
+	} else {
+		promoteBtn = buttonStyle.Render("Promote")
+		cancelBtn = buttonActiveStyle.Render("Cancel")
+	}
+	sb.WriteString(fmt.Sprintf("     %s       %s", promoteBtn, cancelBtn))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 70,
+		Style:    errorBoxStyle,
+	}
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAppendCandidateMRs(t *testing.T) {
+	state := &ReleaseState{}
+	// Saved before IIDs, titles and authors were recorded
+	appendCandidateMRs(state, &ReleaseHistoryEntry{
+		MRBranches: []string{"feature/a", "feature/b"},
+		MRURLs:     []string{"https://forge/mr/1", "https://forge/mr/2"},
+	})
+	appendCandidateMRs(state, &ReleaseHistoryEntry{
+		MRBranches:   []string{"feature/c"},
+		MRURLs:       []string{"https://forge/mr/3"},
+		MRIIDs:       []int{3},
+		MRCommitSHAs: []string{"ccc"},
+		MRTitles:     []string{"Add C"},
+		MRAuthors:    []string{"carol"},
+	})
+
+	for name, n := range map[string]int{
+		"urls": len(state.MRURLs), "iids": len(state.SelectedMRIIDs), "shas": len(state.MRCommitSHAs),
+		"titles": len(state.MRTitles), "authors": len(state.MRAuthors),
+	} {
+		if n != len(state.MRBranches) {
+			t.Errorf("%d %s for %d MRs", n, name, len(state.MRBranches))
+		}
+	}
+	got := fmt.Sprintln(state.MRBranches[2], state.SelectedMRIIDs[2], state.MRCommitSHAs[2], state.MRTitles[2], state.MRAuthors[2])
+	if want := "feature/c 3 ccc Add C carol\n"; got != want {
+		t.Errorf("the third MR is %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Release checkouts: with release_checkouts, releases run in long-lived clones kept per project
+// and environment under ~/.relix/checkouts instead of in the project's clone, so the clone people
+// work in is left alone and releases to different environments can run at once in tabs. The
+// first release clones the project's clone locally (hardlinking its objects, so no download) and
+// points origin at its remote; later ones reuse the checkout, and the release's fetch step only
+// brings in what is new. Before reuse the checkout is checked: it must be a git work tree of the
+// same remote with a valid HEAD; leftovers of an interrupted release (a merge or rebase in
+// progress, uncommitted files) are cleaned up, and a checkout failing the checks is cloned anew.
+//
+// They are clones rather than git worktrees of the project's clone: the release checks out the
+// base, develop and environment branches, and git refuses to check out a branch in two worktrees.
+
+// releaseCheckoutsDir is the directory of the checkouts in the config directory
+const releaseCheckoutsDir = "checkouts"
+
+// checkoutNameUnsafe matches the characters left out of checkout directory names
+var checkoutNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// releaseCheckoutsEnabled reports whether releases run in release checkouts
+func releaseCheckoutsEnabled() bool {
+	config, err := LoadConfig()
+	return err == nil && config.ReleaseCheckouts
+}
+
+// releaseCheckoutPath returns the checkout of the environment for the remote URL, e.g.
+// ~/.relix/checkouts/gitlab.example.com_acme_shop/prod
+func releaseCheckoutPath(remoteURL, env string) (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := remoteURL
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		name = name[i+1:] // user:token@host or git@host
+	}
+	name = strings.Trim(checkoutNameUnsafe.ReplaceAllString(strings.TrimSuffix(name, ".git"), "_"), "_.")
+	env = strings.Trim(checkoutNameUnsafe.ReplaceAllString(strings.ToLower(env), "_"), "_.")
+	if name == "" || env == "" {
+		return "", fmt.Errorf("cannot name a checkout for %q", remoteURL)
+	}
+	return filepath.Join(dir, releaseCheckoutsDir, name, env), nil
+}
+
+// releaseCheckoutFor returns the project's clone, its remote and the release checkout of the
+// environment for it, without touching the checkout
+func releaseCheckoutFor(project *Project, env string) (source, remoteURL, path string, err error) {
+	source, err = projectWorkDir(project)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to find project root: %w", err)
+	}
+	remoteURL, err = gitOutput(source, "remote", "get-url", "origin")
+	if err != nil || remoteURL == "" {
+		return "", "", "", fmt.Errorf("the project clone %s has no origin remote", source)
+	}
+	path, err = releaseCheckoutPath(remoteURL, env)
+	return source, remoteURL, path, err
+}
+
+// savedReleaseUsing returns the state file of a saved release (of any tab, or of "relix release")
+// running in the directory, "" if there is none
+func savedReleaseUsing(dir string) string {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return ""
+	}
+	paths, _ := filepath.Glob(filepath.Join(configDir, "release*.json"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state ReleaseState
+		if json.Unmarshal(data, &state) != nil || state.WorkDir == "" {
+			continue
+		}
+		if same, err := samePath(state.WorkDir, dir); err == nil && same {
+			return path
+		}
+	}
+	return ""
+}
+
+// prepareReleaseCheckout returns the release checkout of the environment for the project's clone,
+// cloning it on first use and checking it before reuse. A checkout a saved release runs in is
+// refused before anything is cleaned up, so its merges and resolved conflicts are kept.
+func prepareReleaseCheckout(project *Project, env string) (string, error) {
+	source, remoteURL, path, err := releaseCheckoutFor(project, env)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if state := savedReleaseUsing(path); state != "" {
+			return "", fmt.Errorf("the unfinished release saved in %s runs in %s: finish or abort it first", state, path)
+		}
+		if err := reuseReleaseCheckout(path, remoteURL); err == nil {
+			return path, nil
+		}
+		// Not fit for reuse: it is ours, so it is cloned anew
+		if err := os.RemoveAll(path); err != nil {
+			return "", fmt.Errorf("remove broken checkout %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if out, err := exec.Command("git", "clone", "--quiet", source, path).CombinedOutput(); err != nil {
+		os.RemoveAll(path)
+		return "", fmt.Errorf("clone %s: %s", source, strings.TrimSpace(string(out)))
+	}
+	for _, args := range [][]string{
+		{"remote", "set-url", "origin", remoteURL},
+		{"fetch", "--quiet", "--prune", "origin"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.RemoveAll(path)
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(out)))
+		}
+	}
+	return path, nil
+}
+
+// reuseReleaseCheckout checks a checkout before a release reuses it and cleans up what an
+// interrupted release left behind
+func reuseReleaseCheckout(path, remoteURL string) error {
+	top, err := gitOutput(path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not a git work tree")
+	}
+	if same, err := samePath(top, path); err != nil || !same {
+		return fmt.Errorf("belongs to the work tree %s", top)
+	}
+	if url, err := gitOutput(path, "remote", "get-url", "origin"); err != nil || url != remoteURL {
+		return fmt.Errorf("origin is %q rather than %q", url, remoteURL)
+	}
+	if _, err := gitOutput(path, "rev-parse", "--verify", "--quiet", "HEAD^{commit}"); err != nil {
+		return fmt.Errorf("HEAD is missing or broken")
+	}
+
+	gitDir, err := gitOutput(path, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "index.lock")); err == nil {
+		return fmt.Errorf("a git command was interrupted (index.lock)")
+	}
+	// Operations an interrupted release may have left in progress; each fails harmlessly if not
+	for _, args := range [][]string{
+		{"merge", "--abort"},
+		{"rebase", "--abort"},
+		{"cherry-pick", "--abort"},
+	} {
+		gitOutput(path, args...)
+	}
+	if _, err := gitOutput(path, "reset", "--hard", "--quiet"); err != nil {
+		return err
+	}
+	_, err = gitOutput(path, "clean", "-ffdx", "--quiet")
+	return err
+}
+
+// samePath reports whether two paths name the same directory
+func samePath(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ai, bi), nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// releaseCommand runs a release without the TUI
+var releaseCommand = &cliCommand{
+	Name:        "release",
+	Summary:     "Run a release headlessly from a list of MRs",
+	Description: "Runs the same release steps as the TUI release screen and presses Create MR and Push root branches automatically. Every MR is looked up in GitLab before anything runs. If a step fails, the release state is kept so it can be retried or aborted in the TUI.",
+	Usage:       "--env <name> [--version <version>] --mrs <list|-> [options]",
+	Examples: []string{
+		"relix release --env test --version 1.2.3 --mrs 42,57",
+		"relix release --env prod --version 1.2.3 --mrs feature/login,!57 --env-merge regular",
+		"git log --format=%b origin/root..origin/develop | grep -o '![0-9]*' | relix release --env test --version 1.2.3 --mrs -",
+		"relix release --env test --version 1.2.3 --mrs - --dry-run < mrs.txt",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		env := fs.String("env", "", "Target environment `name` (e.g. test, prod)")
+		version := fs.String("version", "", "Release `version` (X.Y, X.Y.Z or X.Y.Z.W; default: computed by the version hook of the hook script)")
+		mrsArg := fs.String("mrs", "", "MR IIDs or branch names in merge order: comma-separated `list`, or - to read from stdin")
+		sourceBranch := fs.String("source-branch", "", "Source `branch` (default release/rpb-{version}-root)")
+		rootMerge := fs.Bool("root-merge", true, "Merge the release into the base branch and develop")
+		envMerge := fs.String("env-merge", "squash", "Env merge `mode`: squash or regular")
+		canary := fs.String("canary", "", "Canary `percentage` of the rollout, e.g. 10")
+		flags := fs.String("flags", "", "Feature `flags` toggled with the release, comma-separated, e.g. +new-checkout,-legacy-cart")
+		inputs := pipelineInputFlag{}
+		fs.Var(inputs, "input", "Deployment pipeline input as `NAME=VALUE`; repeat for several")
+		projectID := fs.Int("project", 0, "GitLab project `id` (default: project selected in the TUI)")
+		projectDir := fs.String("project-directory", "", "Project root directory `path`")
+		dryRun := fs.Bool("dry-run", false, "Validate the MRs and print the plan without releasing")
+		forceLock := fs.Bool("force-lock", false, "Override the release lock of the environment held by someone else")
+		return func(args []string) error {
+			if len(args) > 0 || *env == "" || *mrsArg == "" {
+				return errCLIUsage
+			}
+
+			var refs []string
+			if *mrsArg == "-" {
+				var err error
+				if refs, err = readMRRefs(os.Stdin); err != nil {
+					return fmt.Errorf("read MRs from stdin: %w", err)
+				}
+			} else {
+				refs = splitMRRefs(*mrsArg)
+			}
+			if len(refs) == 0 {
+				return errors.New("no merge requests given")
+			}
+
+			rollout, err := parseRollout(*canary, *flags)
+			if err != nil {
+				return err
+			}
+
+			if err := setProjectDirectory(*projectDir); err != nil {
+				return err
+			}
+			creds, project, err := loadCLISession(*projectID)
+			if err != nil {
+				return err
+			}
+			client := NewForge(*creds)
+
+			iids, err := resolveMRRefs(client, project, refs)
+			if err != nil {
+				return err
+			}
+
+			plan := ReleasePlan{
+				Environment:  *env,
+				Version:      *version,
+				SourceBranch: *sourceBranch,
+				MRIIDs:       iids,
+				RootMerge:    rootMerge,
+				EnvMergeMode: *envMerge,
+				Rollout:      rollout,
+
+				PipelineInputs: inputs,
+			}
+
+			if existing, err := LoadReleaseState(firstTabID); err == nil && existing != nil {
+				return errors.New("an unfinished release exists; retry or abort it in the TUI first")
+			}
+			workDir, err := prepareReleaseWorkDir(nil, plan.Environment)
+			if err != nil {
+				return err
+			}
+			state, err := resolveReleasePlan(plan, client, project, workDir)
+			if err != nil {
+				return err
+			}
+
+			if *dryRun {
+				printReleasePlan(os.Stdout, state)
+				return nil
+			}
+
+			if releaseLocksEnabled() {
+				held, err := acquireReleaseLock(state, releaseLockHolder(creds), *forceLock)
+				if err != nil {
+					return fmt.Errorf("release lock: %w", err)
+				}
+				if held != nil {
+					return fmt.Errorf("%s; pass --force-lock to override the lock", held.describe(state.Environment.Name))
+				}
+			}
+
+			loadThemeFromConfig()
+			return runHeadlessRelease(creds, state,
+				func(line string) { fmt.Println(ansi.Strip(line)) },
+				nil,
+			)
+		}
+	},
+}
+
+// splitMRRefs splits a list of MR references separated by commas or whitespace
+func splitMRRefs(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// readMRRefs reads MR references from r, one or more per line.
+// Blank lines and lines starting with # are ignored; "origin/" prefixes from git output are stripped.
+func readMRRefs(r io.Reader) ([]string, error) {
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, ref := range splitMRRefs(line) {
+			refs = append(refs, strings.TrimPrefix(ref, "origin/"))
+		}
+	}
+	return refs, scanner.Err()
+}
+
+// resolveMRRefs converts MR IIDs ("42", "!42") and source branch names to IIDs by looking each up in GitLab.
+// Duplicates are dropped, keeping the first occurrence. State checks are left to resolveReleasePlan.
+func resolveMRRefs(client Forge, projectID int, refs []string) ([]int, error) {
+	var iids []int
+	seen := make(map[int]bool)
+	for _, ref := range refs {
+		var mr *MergeRequestDetails
+		var err error
+		if iid, convErr := strconv.Atoi(strings.TrimPrefix(ref, "!")); convErr == nil {
+			mr, err = client.GetMergeRequestByIID(projectID, iid)
+		} else {
+			mr, err = client.GetMergeRequestBySourceBranch(projectID, ref)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("MR %s: %w", ref, err)
+		}
+		if seen[mr.IID] {
+			continue
+		}
+		seen[mr.IID] = true
+		iids = append(iids, mr.IID)
+	}
+	return iids, nil
+}
+
+// printReleasePlan prints the resolved release for --dry-run
+func printReleasePlan(w io.Writer, state *ReleaseState) {
+	fmt.Fprintf(w, "%-16s %s (%s)\n", "Environment:", state.Environment.Name, state.Environment.BranchName)
+	fmt.Fprintf(w, "%-16s %s\n", "Version:", state.Version)
+	fmt.Fprintf(w, "%-16s %s (remote: %t)\n", "Source branch:", state.SourceBranch, state.SourceBranchIsRemote)
+	fmt.Fprintf(w, "%-16s %s\n", "Env merge mode:", state.EnvMergeMode)
+	fmt.Fprintf(w, "%-16s %t\n", "Root merge:", state.RootMerge)
+	if state.Rollout != nil {
+		fmt.Fprintf(w, "%-16s %s\n", "Rollout:", state.Rollout)
+	}
+	if len(state.PipelineInputs) > 0 {
+		fmt.Fprintf(w, "%-16s %s\n", "Pipeline inputs:", formatPipelineInputs(redactPipelineInputs(state.PipelineInputs)))
+	}
+	fmt.Fprintf(w, "\nMerge requests (%d):\n", len(state.MRBranches))
+	for i, branch := range state.MRBranches {
+		fmt.Fprintf(w, "  !%-6d %s  %s\n", state.SelectedMRIIDs[i], branch, state.MRURLs[i])
+	}
+}
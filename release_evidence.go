@@ -0,0 +1,517 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Regulated environments need evidence of what was deployed, by whom and after which checks. With
+// "evidence" in the config, each completed release writes a signed bundle next to its history
+// entry (~/.relix/releases/{id}.evidence.json): the plan with its MRs and their approvers, the
+// gates passed and who passed them, the pipelines of the MRs, the release MR and the tag, the
+// SHA-256 of the terminal output as saved in the history log, and the operator. The bundle is
+// signed with an Ed25519 key, by default ~/.relix/evidence.key created on first use, and can be
+// uploaded and linked from the GitLab Release of the tag. `relix history evidence` verifies it.
+
+const (
+	evidenceFormat     = 1
+	evidenceFileSuffix = ".evidence.json"
+	evidenceKeyFile    = "evidence.key"
+)
+
+// evidenceBundle is the file written per release: the evidence and its signature. The signature
+// covers the compact JSON encoding of the evidence, so the file can be reindented.
+type evidenceBundle struct {
+	Evidence  json.RawMessage   `json:"evidence"`
+	Signature evidenceSignature `json:"signature"`
+}
+
+// evidenceSignature signs the evidence of a bundle
+type evidenceSignature struct {
+	Algorithm string `json:"algorithm"`  // "ed25519"
+	PublicKey string `json:"public_key"` // Base64
+	Value     string `json:"value"`      // Base64
+}
+
+// releaseEvidence is what a bundle attests about a release
+type releaseEvidence struct {
+	Format      int                `json:"format"`
+	HistoryID   string             `json:"history_id"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Project     string             `json:"project"`
+	ProjectID   int                `json:"project_id"`
+	Plan        evidencePlan       `json:"plan"`
+	Approvals   []ReleaseApproval  `json:"approvals"`
+	ReleaseMR   *evidenceMR        `json:"release_mr,omitempty"`
+	Pipelines   []evidencePipeline `json:"pipelines"`
+	Output      evidenceOutput     `json:"output"`
+	Operator    evidenceOperator   `json:"operator"`
+}
+
+// evidencePlan is the released content
+type evidencePlan struct {
+	Environment    string       `json:"environment"`
+	EnvBranch      string       `json:"env_branch"`
+	Version        string       `json:"version"`
+	ReleaseVersion string       `json:"release_version,omitempty"`
+	Tag            string       `json:"tag"`
+	TagCommit      string       `json:"tag_commit,omitempty"`
+	SourceBranch   string       `json:"source_branch"`
+	BaseBranch     string       `json:"base_branch"`
+	RootMerge      bool         `json:"root_merge"`
+	EnvMergeMode   string       `json:"env_merge_mode,omitempty"`
+	Rollout        *Rollout     `json:"rollout,omitempty"`
+	RollbackOf     string       `json:"rollback_of,omitempty"`
+	MRs            []evidenceMR `json:"mrs"`
+}
+
+// evidenceMR is a released MR, or the release MR
+type evidenceMR struct {
+	IID        int      `json:"iid,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Branch     string   `json:"branch,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	CommitSHA  string   `json:"commit_sha,omitempty"`
+	ApprovedBy []string `json:"approved_by,omitempty"` // GitLab approvals
+}
+
+// evidencePipeline is a pipeline result of the release
+type evidencePipeline struct {
+	For    string `json:"for"` // "!12" for an MR, "release MR", "tag" or "deployment"
+	ID     int    `json:"id"`
+	Status string `json:"status,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// evidenceOutput identifies the terminal output saved in the history log
+type evidenceOutput struct {
+	Lines  int    `json:"lines"`
+	SHA256 string `json:"sha256"` // Of the lines, each followed by a newline
+}
+
+// evidenceOperator is who ran the release
+type evidenceOperator struct {
+	User         string `json:"user"` // OS account
+	Host         string `json:"host,omitempty"`
+	Account      string `json:"account,omitempty"` // Forge login
+	Forge        string `json:"forge,omitempty"`
+	RelixVersion string `json:"relix_version"`
+}
+
+// releaseEvidenceMsg reports the evidence bundle written for a release
+type releaseEvidenceMsg struct {
+	path string
+	url  string // Link on the GitLab Release, if attached
+	errs []error
+}
+
+// operatorName returns the OS account running relix
+func operatorName() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// recordApproval records that the release passed the gate, by the Telegram user who approved it or
+// the operator
+func (m *model) recordApproval(gate string) {
+	approval := ReleaseApproval{Gate: gate, By: operatorName(), Via: "tui", Time: time.Now().UTC()}
+	switch {
+	case m.remoteApprovedBy != "":
+		approval.By, approval.Via = m.remoteApprovedBy, "telegram"
+		m.remoteApprovedBy = ""
+	case m.headless:
+		approval.Via = "headless"
+	}
+	m.releaseState.Approvals = append(m.releaseState.Approvals, approval)
+}
+
+// evidenceWanted reports whether the config asks for evidence of releases to the environment
+func evidenceWanted(config *AppConfig, env Environment) bool {
+	if config.Evidence == nil {
+		return false
+	}
+	envs := config.Evidence.Environments
+	return len(envs) == 0 || containsFold(envs, env.Name) || containsFold(envs, env.BranchName)
+}
+
+// loadEvidenceKey reads the signing key. The default key is created on first use; a configured
+// key file must exist.
+func loadEvidenceKey(path string) (ed25519.PrivateKey, error) {
+	create := false
+	if path == "" {
+		dir, err := getConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path, create = filepath.Join(dir, evidenceKeyFile), true
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// hashHistoryOutput returns the line count and SHA-256 of a release's saved terminal output
+func hashHistoryOutput(e *ReleaseHistoryEntry) (evidenceOutput, error) {
+	h := sha256.New()
+	var lines int
+	err := forEachHistoryLogLine(e, func(line string) {
+		h.Write([]byte(line + "\n"))
+		lines++
+	})
+	return evidenceOutput{Lines: lines, SHA256: hex.EncodeToString(h.Sum(nil))}, err
+}
+
+// collectReleaseEvidence gathers the evidence of a release saved to history. Approvals and
+// pipelines that cannot be read are left out; the bundle records what the forge reported.
+func collectReleaseEvidence(creds Credentials, state *ReleaseState, project, historyID string) (*releaseEvidence, error) {
+	entry, err := LoadHistoryDetail(historyID)
+	if err != nil {
+		return nil, err
+	}
+	output, err := hashHistoryOutput(entry)
+	if err != nil {
+		return nil, fmt.Errorf("hash output: %w", err)
+	}
+
+	ev := &releaseEvidence{
+		Format:      evidenceFormat,
+		HistoryID:   historyID,
+		GeneratedAt: time.Now().UTC(),
+		Project:     project,
+		ProjectID:   state.ProjectID,
+		Plan: evidencePlan{
+			Environment:    state.Environment.Name,
+			EnvBranch:      state.Environment.BranchName,
+			Version:        state.Version,
+			ReleaseVersion: state.ReleaseVersion,
+			Tag:            state.TagName,
+			SourceBranch:   state.SourceBranch,
+			BaseBranch:     state.BaseBranch,
+			RootMerge:      state.RootMerge,
+			EnvMergeMode:   state.EnvMergeMode,
+			Rollout:        state.Rollout,
+			RollbackOf:     state.RollbackOf,
+		},
+		Approvals: state.Approvals,
+		Output:    output,
+		Operator: evidenceOperator{
+			User:         operatorName(),
+			Account:      creds.Email,
+			Forge:        creds.GitLabURL,
+			RelixVersion: AppVersion,
+		},
+	}
+	ev.Operator.Host, _ = os.Hostname()
+	if state.TagName != "" {
+		ev.Plan.TagCommit = GetBranchCommitID(state.WorkDir, state.TagName+"^{commit}")
+	}
+
+	forge := NewForge(creds)
+	gitlab, _ := forge.(*GitLabClient)
+	approvers := func(iid int) []string {
+		if gitlab == nil || iid == 0 {
+			return nil
+		}
+		names, _ := gitlab.GetMergeRequestApprovers(state.ProjectID, iid)
+		return names
+	}
+
+	for i, branch := range state.MRBranches {
+		mr := evidenceMR{Branch: branch}
+		if i < len(state.SelectedMRIIDs) {
+			mr.IID = state.SelectedMRIIDs[i]
+		}
+		if i < len(state.MRTitles) {
+			mr.Title = state.MRTitles[i]
+		}
+		if i < len(state.MRAuthors) {
+			mr.Author = state.MRAuthors[i]
+		}
+		if i < len(state.MRURLs) {
+			mr.URL = state.MRURLs[i]
+		}
+		if i < len(state.MRCommitSHAs) {
+			mr.CommitSHA = state.MRCommitSHAs[i]
+		}
+		mr.ApprovedBy = approvers(mr.IID)
+		ev.Plan.MRs = append(ev.Plan.MRs, mr)
+
+		if mr.CommitSHA != "" {
+			if pipelines, err := forge.GetPipelinesByCommit(state.ProjectID, mr.CommitSHA); err == nil && len(pipelines) > 0 {
+				p := pipelines[0]
+				ev.Pipelines = append(ev.Pipelines, evidencePipeline{For: fmt.Sprintf("!%d", mr.IID), ID: p.ID, Status: p.Status, URL: p.WebURL})
+			}
+		}
+	}
+
+	if state.CreatedMRIID != 0 {
+		ev.ReleaseMR = &evidenceMR{IID: state.CreatedMRIID, URL: state.CreatedMRURL, ApprovedBy: approvers(state.CreatedMRIID)}
+		if pipelines, err := forge.GetMergeRequestPipelines(state.ProjectID, state.CreatedMRIID); err == nil && len(pipelines) > 0 {
+			p := pipelines[0]
+			ev.Pipelines = append(ev.Pipelines, evidencePipeline{For: "release MR", ID: p.ID, Status: p.Status, URL: p.WebURL})
+		}
+	}
+	if ev.Plan.TagCommit != "" {
+		if pipelines, err := forge.GetPipelinesByCommit(state.ProjectID, ev.Plan.TagCommit); err == nil {
+			for _, p := range pipelines {
+				if p.Ref == state.TagName {
+					ev.Pipelines = append(ev.Pipelines, evidencePipeline{For: "tag", ID: p.ID, Status: p.Status, URL: p.WebURL})
+					break
+				}
+			}
+		}
+	}
+	if state.DeployPipelineID != 0 {
+		ev.Pipelines = append(ev.Pipelines, evidencePipeline{For: "deployment", ID: state.DeployPipelineID, URL: state.DeployPipelineURL})
+	}
+	return ev, nil
+}
+
+// signEvidence returns the signed bundle of the evidence, indented for reading
+func signEvidence(ev *releaseEvidence, key ed25519.PrivateKey) ([]byte, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	bundle := evidenceBundle{
+		Evidence: payload,
+		Signature: evidenceSignature{
+			Algorithm: "ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+			Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+		},
+	}
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// verifyEvidence checks the signature of a bundle and returns its evidence and the fingerprint of
+// the signing key
+func verifyEvidence(data []byte) (*releaseEvidence, string, error) {
+	var bundle evidenceBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, "", fmt.Errorf("parse bundle: %w", err)
+	}
+	if bundle.Signature.Algorithm != "ed25519" {
+		return nil, "", fmt.Errorf("unsupported signature algorithm %q", bundle.Signature.Algorithm)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(bundle.Signature.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, "", fmt.Errorf("invalid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(bundle.Signature.Value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid signature encoding")
+	}
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, bundle.Evidence); err != nil {
+		return nil, "", fmt.Errorf("parse evidence: %w", err)
+	}
+	sum := sha256.Sum256(publicKey)
+	fingerprint := "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+	if !ed25519.Verify(publicKey, payload.Bytes(), signature) {
+		return nil, fingerprint, fmt.Errorf("signature does not match the evidence")
+	}
+	var ev releaseEvidence
+	if err := json.Unmarshal(bundle.Evidence, &ev); err != nil {
+		return nil, fingerprint, fmt.Errorf("parse evidence: %w", err)
+	}
+	return &ev, fingerprint, nil
+}
+
+// writeReleaseEvidence returns a command writing the evidence bundle of the release just saved to
+// history, and attaching it to the GitLab Release of its tag if configured
+func (m *model) writeReleaseEvidence(historyID string) tea.Cmd {
+	if m.releaseState == nil || m.creds == nil || historyID == "" {
+		return nil
+	}
+	state := *m.releaseState
+	creds := *m.creds
+	project := m.selectedProjectPath()
+
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil || !evidenceWanted(config, state.Environment) {
+			return nil
+		}
+		fail := func(err error) tea.Msg {
+			return releaseEvidenceMsg{errs: []error{fmt.Errorf("evidence bundle: %w", err)}}
+		}
+		key, err := loadEvidenceKey(config.Evidence.SigningKey)
+		if err != nil {
+			return fail(err)
+		}
+		ev, err := collectReleaseEvidence(creds, &state, project, historyID)
+		if err != nil {
+			return fail(err)
+		}
+		data, err := signEvidence(ev, key)
+		if err != nil {
+			return fail(err)
+		}
+		dir, err := getReleasesDir()
+		if err != nil {
+			return fail(err)
+		}
+		path := filepath.Join(dir, historyID+evidenceFileSuffix)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fail(err)
+		}
+
+		msg := releaseEvidenceMsg{path: path}
+		if !config.Evidence.AttachToRelease || state.TagName == "" {
+			return msg
+		}
+		client, ok := NewForge(creds).(*GitLabClient)
+		if !ok {
+			msg.errs = append(msg.errs, fmt.Errorf("evidence bundle: attaching to a release needs GitLab"))
+			return msg
+		}
+		url, err := client.UploadProjectFile(state.ProjectID, "evidence-"+state.TagName+".json", data)
+		if err == nil {
+			err = client.AddReleaseLink(state.ProjectID, state.TagName, "Release evidence", url)
+		}
+		recordAudit(auditReleaseEvidence, fmt.Sprintf("project %d", state.ProjectID), state.TagName+": "+url, err)
+		if err != nil {
+			msg.errs = append(msg.errs, fmt.Errorf("evidence bundle: attach to release %s: %w", state.TagName, err))
+		} else {
+			msg.url = url
+		}
+		return msg
+	}
+}
+
+// handleReleaseEvidence reports the evidence bundle in the release output
+func (m *model) handleReleaseEvidence(msg releaseEvidenceMsg) {
+	if m.releaseState == nil {
+		return
+	}
+	if msg.path != "" {
+		m.appendReleaseOutput("Evidence bundle written: " + msg.path)
+	}
+	if msg.url != "" {
+		m.appendReleaseOutput("Evidence bundle attached to release " + m.releaseState.TagName + ": " + msg.url)
+	}
+	for _, err := range msg.errs {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("WARNING: " + err.Error()))
+	}
+}
+
+var historyEvidenceCommand = &cliCommand{
+	Name:        "evidence",
+	Summary:     "Verify the signed evidence bundle of a release",
+	Description: "Checks the signature of the evidence bundle written for a release (see \"evidence\" in the config) and, if the release is in the local history, that its terminal output still matches the hash in the bundle. The release can be given by its ID or tag, or a bundle file can be given, e.g. one downloaded from the GitLab Release.",
+	Usage:       "[options] <id|tag|file>",
+	Examples: []string{
+		"relix history evidence 5.2-v13",
+		"relix history evidence --key SHA256:Vw3f... evidence-prod-5.2-v13.json",
+		"relix history evidence 20260215-143012 --format json",
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		key := fs.String("key", "", "Require the bundle to be signed by the key with this `fingerprint`")
+		format := fs.String("format", "text", "Output `format`: text, or json for the verified evidence")
+		return func(args []string) error {
+			if len(args) != 1 {
+				return errCLIUsage
+			}
+			path := args[0]
+			if _, err := os.Stat(path); err != nil {
+				entry, err := findHistoryEntry(args[0])
+				if err != nil {
+					return err
+				}
+				dir, err := getReleasesDir()
+				if err != nil {
+					return err
+				}
+				path = filepath.Join(dir, entry.ID+evidenceFileSuffix)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read bundle: %w", err)
+			}
+
+			ev, fingerprint, err := verifyEvidence(data)
+			if err != nil {
+				return err
+			}
+			if *key != "" && *key != fingerprint {
+				return fmt.Errorf("bundle is signed by %s, not %s", fingerprint, *key)
+			}
+			outputCheck := "history log not found locally, not checked"
+			if entry, err := LoadHistoryDetail(ev.HistoryID); err == nil {
+				output, err := hashHistoryOutput(entry)
+				if err != nil {
+					return fmt.Errorf("hash output: %w", err)
+				}
+				if output != ev.Output {
+					return fmt.Errorf("terminal output of %s does not match the bundle", ev.HistoryID)
+				}
+				outputCheck = fmt.Sprintf("matches the history log (%d lines)", output.Lines)
+			}
+
+			switch *format {
+			case "text":
+				fmt.Printf("Bundle:    %s\n", path)
+				fmt.Printf("Release:   %s %s to %s (%s)\n", ev.Project, ev.Plan.Tag, strings.ToUpper(ev.Plan.Environment), ev.HistoryID)
+				fmt.Printf("Signature: valid, key %s\n", fingerprint)
+				fmt.Printf("Output:    %s\n", outputCheck)
+				return nil
+			case "json":
+				return writeJSON(os.Stdout, ev)
+			default:
+				return fmt.Errorf("unknown format %q (expected text or json)", *format)
+			}
+		}
+	},
+}
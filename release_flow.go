@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The release runs as a finite-state machine over its steps. Each step is a state: it either runs
+// (executeReleaseStep performs it) or waits, for a button or because the release is over.
+// releaseTransitions lists the steps each step may move to, and nextReleaseStep picks the one a
+// finished step leads to from the release state alone, so a resumed release takes the same path and
+// the flow can be checked without git or a forge. The state is saved on every move, so a release
+// resumes at the step it left.
+
+// releaseTransitions lists the steps each step may move to. Staying at a step, to retry it, is
+// always allowed.
+var releaseTransitions = map[ReleaseStep][]ReleaseStep{
+	ReleaseStepIdle:             {ReleaseStepGitFetch},
+	ReleaseStepGitFetch:         {ReleaseStepCheckoutRoot},
+	ReleaseStepCheckoutRoot:     {ReleaseStepMergeBranches, ReleaseStepCheckoutEnv},
+	ReleaseStepMergeBranches:    {ReleaseStepCheckoutEnv},
+	ReleaseStepCheckoutEnv:      {ReleaseStepCopyContent},
+	ReleaseStepCopyContent:      {ReleaseStepCommit, ReleaseStepWaitForMR},
+	ReleaseStepCommit:           {ReleaseStepWaitForMR, ReleaseStepCopyContent},       // A failed commit is retried from copying the content
+	ReleaseStepPushBranches:     {ReleaseStepWaitForMR},                               // Saved by older versions only
+	ReleaseStepWaitForMR:        {ReleaseStepPushAndCreateMR, ReleaseStepCheckoutEnv}, // The environment branch is rebuilt after a rebase
+	ReleaseStepPushAndCreateMR:  {ReleaseStepWaitForRootPush},
+	ReleaseStepWaitForRootPush:  {ReleaseStepPushRootBranches},
+	ReleaseStepPushRootBranches: {ReleaseStepSwitchToRoot},
+	ReleaseStepSwitchToRoot:     {ReleaseStepDeployPipeline, ReleaseStepComplete},
+	ReleaseStepDeployPipeline:   {ReleaseStepComplete},
+}
+
+// releaseStepWaits reports whether a step waits instead of running: for the Create MR or Push root
+// branches button, or because the release is complete
+func releaseStepWaits(step ReleaseStep) bool {
+	return step == ReleaseStepWaitForMR || step == ReleaseStepWaitForRootPush || step == ReleaseStepComplete
+}
+
+// releaseTransitionAllowed reports whether a release may move from one step to another
+func releaseTransitionAllowed(from, to ReleaseStep) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range releaseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// nextReleaseStep returns the step a finished step leads to. The merge step is repeated while
+// branches are left to merge, so CurrentMRIndex must already count the branch just merged.
+func nextReleaseStep(state *ReleaseState, done ReleaseStep) ReleaseStep {
+	switch done {
+	case ReleaseStepCheckoutRoot, ReleaseStepMergeBranches:
+		if state.CurrentMRIndex < len(state.MRBranches) {
+			return ReleaseStepMergeBranches
+		}
+		return ReleaseStepCheckoutEnv
+	case ReleaseStepCopyContent:
+		// A regular merge creates its own commit
+		if state.EnvMergeMode == "regular" {
+			return ReleaseStepWaitForMR
+		}
+		return ReleaseStepCommit
+	case ReleaseStepSwitchToRoot:
+		if state.Environment.DeployPipeline != nil {
+			return ReleaseStepDeployPipeline
+		}
+		return ReleaseStepComplete
+	}
+	if next := releaseTransitions[done]; len(next) > 0 {
+		return next[0]
+	}
+	return done
+}
+
+// moveTo moves the release to a step, refusing moves the flow does not allow, e.g. a button
+// approved remotely after the release went on without it
+func (s *ReleaseState) moveTo(step ReleaseStep) error {
+	if !releaseTransitionAllowed(s.CurrentStep, step) {
+		return fmt.Errorf("release cannot move from %s to %s", releaseStepNames[s.CurrentStep], releaseStepNames[step])
+	}
+	s.CurrentStep = step
+	return nil
+}
+
+// saveReleaseProgress saves the release state with the output so far, for resuming, and updates
+// the buttons of the step it is at
+func (m *model) saveReleaseProgress() {
+	state := m.releaseState
+	if state == nil {
+		return
+	}
+	state.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
+	copy(state.TerminalOutput, m.releaseOutputBuffer)
+	SaveReleaseState(m.tabID, state)
+	m.updateReleaseButtons()
+}
+
+// The screens form a second machine, over the release flow as it is chosen: each screen of the flow
+// leads forward to the next one and back (ctrl+q) to the one before. screenTransitions lists the
+// moves of the flow, and previousScreen picks the screen going back leads to. Some screens are opened
+// from any screen instead: by the command menu, the global search or a release resuming, and the
+// screens outside the flow (startup, sign-in, errors, settings) go on or back to any screen.
+// Switching release tabs swaps in the screen of another tab along with its session, not a move.
+
+// screenTransitions lists the screens each screen of the flow may move to
+var screenTransitions = map[screen][]screen{
+	screenHome:          {screenMain, screenHistoryList},
+	screenMain:          {screenEnvSelect, screenHome},
+	screenEnvSelect:     {screenVersion, screenMain},
+	screenVersion:       {screenSourceBranch, screenEnvSelect},
+	screenSourceBranch:  {screenEnvMerge, screenVersion},
+	screenEnvMerge:      {screenRootMerge, screenSourceBranch},
+	screenRootMerge:     {screenRollout, screenConfirm, screenEnvMerge},
+	screenRollout:       {screenConfirm, screenRootMerge},
+	screenConfirm:       {screenRelease, screenRollout, screenRootMerge},
+	screenRelease:       {screenHome},
+	screenHistoryList:   {screenHistoryDetail, screenHome},
+	screenHistoryDetail: {screenHistoryList, screenRelease}, // A rollback is released from the history
+}
+
+// screensFromAnywhere are the screens opened from any screen
+var screensFromAnywhere = map[screen]bool{
+	screenAuth:        true, // Signing out
+	screenError:       true,
+	screenSafeMode:    true,
+	screenSettings:    true, // Command menu
+	screenMain:        true, // Global search, a changed plan or a new tab
+	screenHistoryList: true, // Global search
+	screenRelease:     true, // A release in progress resumes
+}
+
+// screenNames are the screen names shown when a move is refused
+var screenNames = map[screen]string{
+	screenLoading:       "Loading",
+	screenAuth:          "Sign in",
+	screenError:         "Error",
+	screenHome:          "Home",
+	screenMain:          "Merge requests",
+	screenEnvSelect:     "Environment",
+	screenVersion:       "Version",
+	screenSourceBranch:  "Source branch",
+	screenEnvMerge:      "Environment merge",
+	screenRootMerge:     "Root merge",
+	screenRollout:       "Rollout",
+	screenConfirm:       "Confirmation",
+	screenRelease:       "Release",
+	screenHistoryList:   "History",
+	screenHistoryDetail: "Release details",
+	screenSettings:      "Settings",
+	screenSafeMode:      "Safe mode",
+}
+
+// screenTransitionAllowed reports whether the app may move from one screen to another
+func screenTransitionAllowed(from, to screen) bool {
+	next, inFlow := screenTransitions[from]
+	return from == to || screensFromAnywhere[to] || !inFlow || slices.Contains(next, to)
+}
+
+// previousScreen returns the screen going back from the current one leads to. The confirmation
+// goes back to the rollout only if the environment asks for one, and the settings to the screen
+// they were opened from.
+func (m model) previousScreen() screen {
+	switch m.screen {
+	case screenMain, screenHistoryList:
+		return screenHome
+	case screenEnvSelect:
+		return screenMain
+	case screenVersion:
+		return screenEnvSelect
+	case screenSourceBranch:
+		return screenVersion
+	case screenEnvMerge:
+		return screenSourceBranch
+	case screenRootMerge:
+		return screenEnvMerge
+	case screenRollout:
+		return screenRootMerge
+	case screenConfirm:
+		if m.needsRollout() {
+			return screenRollout
+		}
+		return screenRootMerge
+	case screenHistoryDetail:
+		return screenHistoryList
+	case screenSettings:
+		return m.settingsPreviousScreen
+	}
+	return m.screen
+}
+
+// moveToScreen shows a screen, refusing moves the flow does not allow, e.g. skipping the version
+func (m *model) moveToScreen(to screen) bool {
+	if !screenTransitionAllowed(m.screen, to) {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Cannot move from the %s screen to the %s screen", screenNames[m.screen], screenNames[to])
+		return false
+	}
+	m.screen = to
+	return true
+}
+
+// goBack moves back to the screen before the current one
+func (m *model) goBack() {
+	m.moveToScreen(m.previousScreen())
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// walkRelease follows nextReleaseStep from the start of a release to its end, moving the state
+// through every step with moveTo, and returns the steps it went through
+func walkRelease(t *testing.T, state *ReleaseState) []ReleaseStep {
+	t.Helper()
+	path := []ReleaseStep{state.CurrentStep}
+	for state.CurrentStep != ReleaseStepComplete {
+		done := state.CurrentStep
+		if done == ReleaseStepMergeBranches {
+			state.CurrentMRIndex++
+		}
+		next := nextReleaseStep(state, done)
+		if err := state.moveTo(next); err != nil {
+			t.Fatalf("after %s: %v", releaseStepNames[done], err)
+		}
+		if next == done && done != ReleaseStepMergeBranches {
+			t.Fatalf("the release stays at %s", releaseStepNames[done])
+		}
+		path = append(path, next)
+		if len(path) > 50 {
+			t.Fatalf("the release does not end: %v", path)
+		}
+	}
+	return path
+}
+
+func TestNextReleaseStepPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		state ReleaseState
+		want  []ReleaseStep
+	}{
+		{
+			name:  "squash merge of two MRs",
+			state: ReleaseState{MRBranches: []string{"feature/a", "feature/b"}},
+			want: []ReleaseStep{
+				ReleaseStepIdle, ReleaseStepGitFetch, ReleaseStepCheckoutRoot,
+				ReleaseStepMergeBranches, ReleaseStepMergeBranches, ReleaseStepCheckoutEnv,
+				ReleaseStepCopyContent, ReleaseStepCommit, ReleaseStepWaitForMR,
+				ReleaseStepPushAndCreateMR, ReleaseStepWaitForRootPush, ReleaseStepPushRootBranches,
+				ReleaseStepSwitchToRoot, ReleaseStepComplete,
+			},
+		},
+		{
+			name:  "regular merge commits by itself",
+			state: ReleaseState{MRBranches: []string{"feature/a"}, EnvMergeMode: "regular"},
+			want: []ReleaseStep{
+				ReleaseStepIdle, ReleaseStepGitFetch, ReleaseStepCheckoutRoot,
+				ReleaseStepMergeBranches, ReleaseStepCheckoutEnv, ReleaseStepCopyContent,
+				ReleaseStepWaitForMR, ReleaseStepPushAndCreateMR, ReleaseStepWaitForRootPush,
+				ReleaseStepPushRootBranches, ReleaseStepSwitchToRoot, ReleaseStepComplete,
+			},
+		},
+		{
+			name:  "no MRs left to merge",
+			state: ReleaseState{CurrentStep: ReleaseStepCheckoutRoot},
+			want: []ReleaseStep{
+				ReleaseStepCheckoutRoot, ReleaseStepCheckoutEnv, ReleaseStepCopyContent,
+				ReleaseStepCommit, ReleaseStepWaitForMR, ReleaseStepPushAndCreateMR,
+				ReleaseStepWaitForRootPush, ReleaseStepPushRootBranches, ReleaseStepSwitchToRoot,
+				ReleaseStepComplete,
+			},
+		},
+		{
+			name: "deployment pipeline",
+			state: ReleaseState{
+				CurrentStep: ReleaseStepSwitchToRoot,
+				Environment: Environment{DeployPipeline: &DeployPipelineConfig{}},
+			},
+			want: []ReleaseStep{ReleaseStepSwitchToRoot, ReleaseStepDeployPipeline, ReleaseStepComplete},
+		},
+		{
+			name:  "resumed from a release saved by an older version",
+			state: ReleaseState{CurrentStep: ReleaseStepPushBranches},
+			want: []ReleaseStep{
+				ReleaseStepPushBranches, ReleaseStepWaitForMR, ReleaseStepPushAndCreateMR,
+				ReleaseStepWaitForRootPush, ReleaseStepPushRootBranches, ReleaseStepSwitchToRoot,
+				ReleaseStepComplete,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			if got := walkRelease(t, &state); !slices.Equal(got, tt.want) {
+				t.Errorf("path = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	tests := []struct {
+		from, to ReleaseStep
+		ok       bool
+	}{
+		{ReleaseStepGitFetch, ReleaseStepGitFetch, true}, // Retrying a step
+		{ReleaseStepCommit, ReleaseStepCopyContent, true},
+		{ReleaseStepWaitForMR, ReleaseStepCheckoutEnv, true}, // Rebuilt after a rebase
+		{ReleaseStepWaitForMR, ReleaseStepPushAndCreateMR, true},
+		{ReleaseStepWaitForMR, ReleaseStepPushRootBranches, false}, // A stale "Push root branches" approval
+		{ReleaseStepComplete, ReleaseStepPushAndCreateMR, false},   // A stale "Create MR" press
+		{ReleaseStepIdle, ReleaseStepComplete, false},
+		{ReleaseStepDeployPipeline, ReleaseStepSwitchToRoot, false},
+	}
+	for _, tt := range tests {
+		state := &ReleaseState{CurrentStep: tt.from}
+		err := state.moveTo(tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("moveTo %s from %s: err = %v, want ok %v", releaseStepNames[tt.to], releaseStepNames[tt.from], err, tt.ok)
+			continue
+		}
+		want := tt.from
+		if tt.ok {
+			want = tt.to
+		}
+		if state.CurrentStep != want {
+			t.Errorf("moveTo %s from %s left the release at %s", releaseStepNames[tt.to], releaseStepNames[tt.from], releaseStepNames[state.CurrentStep])
+		}
+	}
+}
+
+func TestReleaseTransitionsReachComplete(t *testing.T) {
+	// Every step the flow moves to either moves on or ends the release
+	for from, targets := range releaseTransitions {
+		if _, ok := releaseStepNames[from]; !ok {
+			t.Errorf("step %d has no name", from)
+		}
+		if len(targets) == 0 {
+			t.Errorf("%s leads nowhere", releaseStepNames[from])
+		}
+		for _, to := range targets {
+			if _, ok := releaseTransitions[to]; !ok && to != ReleaseStepComplete {
+				t.Errorf("%s leads to %s, which leads nowhere", releaseStepNames[from], releaseStepNames[to])
+			}
+		}
+	}
+	if _, ok := releaseTransitions[ReleaseStepComplete]; ok {
+		t.Error("a complete release moves on")
+	}
+}
+
+func TestScreenFlow(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout bool
+		want    []screen
+	}{
+		{
+			name: "without a rollout",
+			want: []screen{
+				screenHome, screenMain, screenEnvSelect, screenVersion, screenSourceBranch,
+				screenEnvMerge, screenRootMerge, screenConfirm, screenRelease,
+			},
+		},
+		{
+			name:    "with a rollout",
+			rollout: true,
+			want: []screen{
+				screenHome, screenMain, screenEnvSelect, screenVersion, screenSourceBranch,
+				screenEnvMerge, screenRootMerge, screenRollout, screenConfirm, screenRelease,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{screen: screenHome}
+			m.selectedEnv = &Environment{Name: "PROD", Rollout: tt.rollout}
+			for i, next := range tt.want[1:] {
+				if !m.moveToScreen(next) {
+					t.Fatalf("moving from %s to %s: %s", screenNames[m.screen], screenNames[next], m.errorModalMsg)
+				}
+				// Going back returns to the screen before, except from the release
+				if next == screenRelease {
+					break
+				}
+				back := m
+				back.goBack()
+				if back.screen != tt.want[i] {
+					t.Errorf("going back from %s leads to %s, want %s", screenNames[next], screenNames[back.screen], screenNames[tt.want[i]])
+				}
+			}
+		})
+	}
+}
+
+func TestMoveToScreen(t *testing.T) {
+	tests := []struct {
+		from, to screen
+		ok       bool
+	}{
+		{screenVersion, screenVersion, true},
+		{screenVersion, screenConfirm, false}, // Skipping the source branch and merges
+		{screenEnvSelect, screenSourceBranch, false},
+		{screenRelease, screenConfirm, false},
+		{screenHome, screenHistoryDetail, false},
+		{screenConfirm, screenSettings, true},      // Command menu
+		{screenEnvMerge, screenMain, true},         // Global search
+		{screenHistoryDetail, screenRelease, true}, // Rollback
+		{screenLoading, screenRelease, true},       // Resumed at startup
+		{screenSettings, screenRollout, true},      // Back to where the settings were opened
+	}
+	for _, tt := range tests {
+		m := model{screen: tt.from}
+		if ok := m.moveToScreen(tt.to); ok != tt.ok || m.showErrorModal == tt.ok {
+			t.Errorf("moving from %s to %s: ok %v (%q), want %v", screenNames[tt.from], screenNames[tt.to], ok, m.errorModalMsg, tt.ok)
+		}
+		want := tt.from
+		if tt.ok {
+			want = tt.to
+		}
+		if m.screen != want {
+			t.Errorf("moving from %s to %s left the app at %s", screenNames[tt.from], screenNames[tt.to], screenNames[m.screen])
+		}
+	}
+}
+
+func TestScreenBackKeys(t *testing.T) {
+	ctrlQ := tea.KeyMsg{Type: tea.KeyCtrlQ}
+	m := model{screen: screenEnvMerge}
+	got, _ := m.updateEnvMerge(ctrlQ)
+	if s := got.(model).screen; s != screenSourceBranch {
+		t.Errorf("ctrl+q on the environment merge leads to %s", screenNames[s])
+	}
+	m = model{screen: screenConfirm}
+	m.selectedEnv = &Environment{Name: "PROD"}
+	got, _ = m.updateConfirm(ctrlQ)
+	if gm := got.(model); gm.screen != screenRootMerge || gm.rootMergeButtonIndex != 1 {
+		t.Errorf("ctrl+q on the confirmation leads to %s with button %d, want the root merge with No", screenNames[gm.screen], gm.rootMergeButtonIndex)
+	}
+}
+
+func TestScreenTransitionsGoBack(t *testing.T) {
+	// Every screen of the flow may move back to the screen before it, and every screen has a name
+	for from, targets := range screenTransitions {
+		for _, env := range []*Environment{{}, {Rollout: true}} {
+			m := model{screen: from}
+			m.selectedEnv = env
+			if back := m.previousScreen(); back != from && !screenTransitionAllowed(from, back) {
+				t.Errorf("%s cannot move back to %s", screenNames[from], screenNames[back])
+			}
+		}
+		for _, to := range targets {
+			if _, ok := screenNames[to]; !ok {
+				t.Errorf("screen %d has no name", to)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Virtual terminal size used for headless releases (affects git output wrapping only)
+const (
+	headlessWidth  = 160
+	headlessHeight = 48
+)
+
+// headlessRelease drives the release model without a terminal UI.
+// It runs the same steps as the release screen and presses the
+// "Create MR" and "Push root branches" buttons automatically.
+type headlessRelease struct {
+	model   model
+	state   *ReleaseState
+	emitted int // Output lines already reported, counted like releaseOutputTotal
+	err     error
+
+	onLine     func(line string)        // Called for every new terminal output line
+	onProgress func(state ReleaseState) // Called when the step or substep counter changes
+
+	lastStep      ReleaseStep
+	lastCompleted int
+}
+
+// runHeadlessRelease executes a release built from a plan and blocks until it completes or fails.
+// On failure the release state is kept on disk, so it can be retried or aborted from the TUI.
+func runHeadlessRelease(creds *Credentials, state *ReleaseState, onLine func(string), onProgress func(ReleaseState)) error {
+	h := &headlessRelease{
+		model:      NewModel(),
+		state:      state,
+		onLine:     onLine,
+		onProgress: onProgress,
+		lastStep:   ReleaseStepIdle,
+	}
+	h.model.creds = creds
+	h.model.headless = true
+	h.model.width = headlessWidth
+	h.model.height = headlessHeight
+
+	p := tea.NewProgram(h,
+		tea.WithoutRenderer(),
+		tea.WithInput(nil),
+		tea.WithOutput(io.Discard),
+		tea.WithoutSignalHandler(),
+	)
+	// Executor streams output through the program, so it must be set before the first step runs
+	h.model.program = p
+
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// Init starts the release
+func (h *headlessRelease) Init() tea.Cmd {
+	m := h.model
+	cmd := m.beginRelease(h.state)
+	h.model = m
+	h.report()
+	return cmd
+}
+
+// Update delegates to the release model and advances through the user-action steps
+func (h *headlessRelease) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := h.model.Update(msg)
+	h.setModel(next)
+	h.report()
+
+	state := h.model.releaseState
+	if state == nil || h.model.releaseRunning {
+		return h, cmd
+	}
+
+	if state.LastError != nil {
+		if h.err == nil {
+			releasesFinished.inc(state.Environment.Name, "failed")
+			cmd = tea.Batch(cmd, finishReleaseTrace("failed"))
+		}
+		h.err = errors.New(state.LastError.Message)
+		// Let notifications of the failure go out before the program stops
+		return h, tea.Sequence(cmd, tea.Quit)
+	}
+
+	var advance func() (tea.Model, tea.Cmd)
+	switch state.CurrentStep {
+	case ReleaseStepWaitForMR:
+		advance = h.model.startCreateMR
+	case ReleaseStepWaitForRootPush:
+		advance = h.model.startPushRootBranches
+	case ReleaseStepComplete:
+		// History is saved and release state cleared when SwitchToRoot completes
+		return h, tea.Sequence(cmd, tea.Quit)
+	default:
+		return h, cmd
+	}
+
+	next, advanceCmd := advance()
+	h.setModel(next)
+	h.report()
+	return h, tea.Batch(cmd, advanceCmd)
+}
+
+// View is never rendered (the program runs without a renderer)
+func (h *headlessRelease) View() string {
+	return ""
+}
+
+// setModel stores the model returned by an update (handlers return either model or *model)
+func (h *headlessRelease) setModel(next tea.Model) {
+	switch m := next.(type) {
+	case model:
+		h.model = m
+	case *model:
+		h.model = *m
+	}
+}
+
+// report emits new output lines and progress changes to the callbacks
+func (h *headlessRelease) report() {
+	buf := h.model.releaseOutputBuffer
+	total := h.model.releaseOutputTotal
+	fresh := total - h.emitted
+	if fresh < 0 {
+		fresh = total // Output was reset by a new release
+	}
+	// Lines spilled to disk within a single update are not reported
+	if fresh > len(buf) {
+		fresh = len(buf)
+	}
+	if h.onLine != nil {
+		for _, line := range buf[len(buf)-fresh:] {
+			h.onLine(line)
+		}
+	}
+	h.emitted = total
+
+	state := h.model.releaseState
+	if state == nil || h.onProgress == nil {
+		return
+	}
+	if state.CurrentStep != h.lastStep || state.CompletedSubSteps != h.lastCompleted {
+		h.lastStep = state.CurrentStep
+		h.lastCompleted = state.CompletedSubSteps
+		h.onProgress(*state)
+	}
+}
@@ -1,17 +1,25 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
 	releasesDir      = "releases"
 	historyIndexFile = "index.json"
+	releaseIDLayout  = "20060102-150405" // Release IDs name the history files
 )
 
 // getReleasesDir returns the path to the releases history directory, creating it if needed
@@ -29,11 +37,15 @@ func getReleasesDir() (string, error) {
 
 // generateReleaseID creates a timestamp-based unique ID
 func generateReleaseID() string {
-	return time.Now().Format("20060102-150405")
+	return time.Now().Format(releaseIDLayout)
 }
 
 // buildReleaseTag constructs the tag string from version and env (e.g., "5.2-v13")
 func buildReleaseTag(state *ReleaseState) string {
+	// Formatted tags carry no env prefix (e.g. "v1.0.0-rc.2")
+	if state.Environment.hasVersionFormat() && state.ReleaseVersion != "" {
+		return state.ReleaseVersion
+	}
 	// Tag is stored in state if root merge was done
 	if state.TagName != "" {
 		// Strip env prefix from tag name (e.g., "dev-1.0.0-v2" -> "1.0.0-v2")
@@ -46,11 +58,25 @@ func buildReleaseTag(state *ReleaseState) string {
 	return state.Version
 }
 
-// SaveReleaseHistory saves a completed or aborted release to history
-func SaveReleaseHistory(state *ReleaseState, status string, terminalOutput []string) error {
+// historyLogPath returns the path of a release's terminal output log: {id}.log.zst, or the plain
+// {id}.log of releases saved before logs were compressed
+func historyLogPath(e *ReleaseHistoryEntry) (string, error) {
 	dir, err := getReleasesDir()
 	if err != nil {
-		return err
+		return "", err
+	}
+	if len(e.LogFrames) > 0 {
+		return filepath.Join(dir, e.ID+".log.zst"), nil
+	}
+	return filepath.Join(dir, e.ID+".log"), nil
+}
+
+// SaveReleaseHistory saves a completed or aborted release to history and returns its ID.
+// The terminal output goes to a separate log file, preceded by the lines the release's tab spilled to disk.
+func SaveReleaseHistory(tab int, project string, state *ReleaseState, status string, terminalOutput []string) (string, error) {
+	dir, err := getReleasesDir()
+	if err != nil {
+		return "", err
 	}
 
 	id := generateReleaseID()
@@ -64,6 +90,12 @@ func SaveReleaseHistory(state *ReleaseState, status string, terminalOutput []str
 		MRCount:     len(state.MRBranches),
 		Status:      status,
 		Version:     state.Version,
+		RollbackOf:  state.RollbackOf,
+		ProjectID:   state.ProjectID,
+		ReleasedBy:  auditAccount(),
+
+		Candidate:    state.Candidate,
+		PromotedFrom: state.PromotedFrom,
 	}
 
 	detail := &ReleaseHistoryEntry{
@@ -72,23 +104,33 @@ func SaveReleaseHistory(state *ReleaseState, status string, terminalOutput []str
 		MRURLs:            state.MRURLs,
 		MRIIDs:            state.SelectedMRIIDs,
 		MRCommitSHAs:      state.MRCommitSHAs,
+		MRTitles:          state.MRTitles,
+		MRAuthors:         state.MRAuthors,
+		Project:           project,
 		SourceBranch:      state.SourceBranch,
 		EnvBranch:         state.Environment.BranchName,
 		RootMerge:         state.RootMerge,
 		EnvMergeMode:      state.EnvMergeMode,
 		CreatedMRURL:      state.CreatedMRURL,
-		TerminalOutput:    terminalOutput,
+		Rollout:           state.Rollout,
+		PipelineInputs:    redactPipelineInputs(state.PipelineInputs),
 		ThemeANSIMap:      buildThemeANSIMap(currentTheme),
 	}
 
+	frames, err := writeReleaseOutputLog(filepath.Join(dir, id+".log.zst"), tab, terminalOutput)
+	if err != nil {
+		return "", fmt.Errorf("write log: %w", err)
+	}
+	detail.LogFrames = frames
+
 	// Save individual detail file
 	detailPath := filepath.Join(dir, id+".json")
 	detailData, err := json.MarshalIndent(detail, "", "  ")
 	if err != nil {
-		return fmt.Errorf("marshal detail: %w", err)
+		return "", fmt.Errorf("marshal detail: %w", err)
 	}
 	if err := os.WriteFile(detailPath, detailData, 0o644); err != nil {
-		return fmt.Errorf("write detail: %w", err)
+		return "", fmt.Errorf("write detail: %w", err)
 	}
 
 	// Update index file (preserve existing entries, only start fresh if file doesn't exist)
@@ -108,13 +150,27 @@ func SaveReleaseHistory(state *ReleaseState, status string, terminalOutput []str
 	indexPath := filepath.Join(dir, historyIndexFile)
 	indexData, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
-		return fmt.Errorf("marshal index: %w", err)
+		return "", fmt.Errorf("marshal index: %w", err)
 	}
 	if err := os.WriteFile(indexPath, indexData, 0o644); err != nil {
-		return fmt.Errorf("write index: %w", err)
+		return "", fmt.Errorf("write index: %w", err)
 	}
 
-	return nil
+	return id, nil
+}
+
+// historyProjectProblem returns why the history entry cannot be acted on (rolled back, promoted) in
+// the selected project, "" if it can. Entries saved before the project ID was recorded are matched
+// by the project path.
+func (m model) historyProjectProblem(entry *ReleaseHistoryEntry) string {
+	if entry.ProjectID != 0 && entry.ProjectID == m.selectedProject.ID ||
+		entry.ProjectID == 0 && entry.Project != "" && entry.Project == m.selectedProjectPath() {
+		return ""
+	}
+	if entry.Project == "" {
+		return "the release does not record its project"
+	}
+	return fmt.Sprintf("the release is of %s; select that project first", entry.Project)
 }
 
 // LoadHistoryIndex loads the history index for quick list display
@@ -171,9 +227,12 @@ func DeleteHistoryEntries(ids map[string]bool) error {
 		return fmt.Errorf("write index: %w", err)
 	}
 
-	// Delete detail files (best effort)
+	// Delete detail and log files (best effort)
 	for id := range ids {
 		os.Remove(filepath.Join(dir, id+".json"))
+		os.Remove(filepath.Join(dir, id+".log"))
+		os.Remove(filepath.Join(dir, id+".log.zst"))
+		os.Remove(filepath.Join(dir, id+evidenceFileSuffix))
 	}
 
 	return nil
@@ -198,3 +257,252 @@ func LoadHistoryDetail(id string) (*ReleaseHistoryEntry, error) {
 
 	return &entry, nil
 }
+
+// SaveHistoryAnnotation replaces the annotation of a release in its detail file
+func SaveHistoryAnnotation(id, annotation string) error {
+	entry, err := LoadHistoryDetail(id)
+	if err != nil {
+		return err
+	}
+	entry.Annotation = annotation
+
+	dir, err := getReleasesDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal detail: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, id+".json"), data, 0o644)
+}
+
+// SaveHistoryPostMortem records the post-mortem of an aborted release: the category in the index,
+// so it can be counted without reading every detail file, and both in the detail file
+func SaveHistoryPostMortem(id, category, text string) error {
+	entry, err := LoadHistoryDetail(id)
+	if err != nil {
+		return err
+	}
+	entry.FailureCategory = category
+	entry.PostMortem = text
+
+	dir, err := getReleasesDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal detail: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0o644); err != nil {
+		return err
+	}
+
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		return fmt.Errorf("load index: %w", err)
+	}
+	for i := range index {
+		if index[i].ID == id {
+			index[i].FailureCategory = category
+		}
+	}
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, historyIndexFile), indexData, 0o644)
+}
+
+// historyImportMu serializes imports of releases pushed by teammates (see history_sync.go)
+var historyImportMu sync.Mutex
+
+// ImportHistoryEntry saves a release pushed by a teammate to the shared history and returns its
+// ID. The release is the same if it was run by the same account at the same time; pushing it again
+// updates it (e.g. with a new annotation). The ID names the history files, so it is minted from the
+// release time rather than taken from the pushed release, and suffixed if another release took it.
+// The terminal output stays with the teammate.
+func ImportHistoryEntry(entry *ReleaseHistoryEntry) (string, error) {
+	historyImportMu.Lock()
+	defer historyImportMu.Unlock()
+
+	dir, err := getReleasesDir()
+	if err != nil {
+		return "", err
+	}
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		return "", fmt.Errorf("load index: %w", err)
+	}
+
+	base := entry.DateTime.Local().Format(releaseIDLayout)
+	id, existing := base, -1
+	taken := make(map[string]bool, len(index))
+	for i, e := range index {
+		taken[e.ID] = true
+		if e.ReleasedBy == entry.ReleasedBy && e.DateTime.Equal(entry.DateTime) {
+			id, existing = e.ID, i
+		}
+	}
+	for n := 2; existing < 0 && taken[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	detail := *entry
+	detail.ID = id
+	detail.TerminalOutput, detail.LogFrames = nil, nil
+	data, err := json.MarshalIndent(&detail, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal detail: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0o644); err != nil {
+		return "", fmt.Errorf("write detail: %w", err)
+	}
+
+	if existing >= 0 {
+		index[existing] = detail.HistoryIndexEntry
+	} else {
+		// The index is newest first
+		at := sort.Search(len(index), func(i int) bool { return !index[i].DateTime.After(detail.DateTime) })
+		index = slices.Insert(index, at, detail.HistoryIndexEntry)
+	}
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, historyIndexFile), indexData, 0o644); err != nil {
+		return "", fmt.Errorf("write index: %w", err)
+	}
+	return id, nil
+}
+
+// historyHasLog reports whether a release has terminal output, either in its log file
+// or, for releases saved before log files, inline in the detail file
+func historyHasLog(e *ReleaseHistoryEntry) bool {
+	if len(e.TerminalOutput) > 0 {
+		return true
+	}
+	path, err := historyLogPath(e)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > 0
+}
+
+// forEachHistoryLogLine calls fn for each line of a release's terminal output without loading it all
+func forEachHistoryLogLine(e *ReleaseHistoryEntry, fn func(line string)) error {
+	if len(e.TerminalOutput) > 0 {
+		for _, line := range e.TerminalOutput {
+			fn(line)
+		}
+		return nil
+	}
+
+	path, err := historyLogPath(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	// The frames of a compressed log read as one zstd stream
+	var src io.Reader = f
+	if len(e.LogFrames) > 0 {
+		zr, err := zstd.NewReader(f, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		src = zr
+	}
+	r := bufio.NewReader(src)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			fn(strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readHistoryLogChunk reads the lines of a release's log that end at offset end (-1 for the end),
+// reading backwards: one frame of a compressed log or historyLogChunkLines lines of a plain one.
+// It returns the lines and the offset of the first one; 0 means the start of the log was reached.
+func readHistoryLogChunk(e *ReleaseHistoryEntry, end int64) ([]string, int64, error) {
+	path, err := historyLogPath(e)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(e.LogFrames) > 0 {
+		return readLogFrame(path, e.LogFrames, end)
+	}
+	return readLogChunk(path, end, historyLogChunkLines)
+}
+
+// compressHistoryLog replaces the plain log of a release saved before logs were compressed with a
+// compressed one. It returns the sizes before and after; both are 0 when there was nothing to do.
+func compressHistoryLog(id string) (int64, int64, error) {
+	e, err := LoadHistoryDetail(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(e.LogFrames) > 0 {
+		return 0, 0, nil
+	}
+	dir, err := getReleasesDir()
+	if err != nil {
+		return 0, 0, err
+	}
+	plainPath := filepath.Join(dir, id+".log")
+	plain, err := os.Open(plainPath)
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer plain.Close()
+	info, err := plain.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	zstPath := filepath.Join(dir, id+".log.zst")
+	frames, err := writeCompressedLog(zstPath, plain, nil)
+	if err != nil {
+		os.Remove(zstPath)
+		return 0, 0, err
+	}
+	var compressed int64
+	if zstInfo, err := os.Stat(zstPath); err == nil {
+		compressed = zstInfo.Size()
+	}
+
+	// The detail file points to the new log before the old one goes
+	e.LogFrames = frames
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		os.Remove(zstPath)
+		return 0, 0, fmt.Errorf("marshal detail: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0o644); err != nil {
+		os.Remove(zstPath)
+		return 0, 0, fmt.Errorf("write detail: %w", err)
+	}
+	plain.Close()
+	os.Remove(plainPath)
+	return info.Size(), compressed, nil
+}
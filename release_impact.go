@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The confirmation screen sums up what the selected MRs change, from the forge's list of changed
+// files of each MR: how many files the release touches, which files more than one MR changes
+// (where merge conflicts are likely) and which areas of the repository are affected. An area is
+// a top-level directory, or a service under one of the directories listed in "service_dirs".
+
+// maxImpactRows bounds the overlapping files and areas listed; the rest are counted
+const maxImpactRows = 8
+
+// releaseImpact is the aggregate impact of the selected MRs
+type releaseImpact struct {
+	key      string // Selected MRs it was computed for (see impactKey)
+	loading  bool
+	files    int             // Distinct files changed
+	overlaps []impactOverlap // Files changed by several MRs, most MRs first
+	areas    []impactArea    // Areas touched, most files first
+	failed   []string        // Branches whose changes could not be fetched
+}
+
+// impactOverlap is a file changed by several MRs
+type impactOverlap struct {
+	path     string
+	branches []string
+}
+
+// impactArea is a directory or service touched by the release
+type impactArea struct {
+	name     string
+	files    int
+	branches []string
+}
+
+// releaseImpactMsg delivers a computed impact, with the details of the selected MRs that were not
+// loaded yet (for their size classes, see mr_size.go)
+type releaseImpactMsg struct {
+	impact  *releaseImpact
+	details []*MergeRequestDetails
+}
+
+// impactKey identifies a selection of MRs, so a result for an older selection is dropped
+func impactKey(mrs []*MergeRequestDetails) string {
+	keys := make([]string, len(mrs))
+	for i, mr := range mrs {
+		keys[i] = strconv.Itoa(mr.IID)
+	}
+	return strings.Join(keys, ",")
+}
+
+// loadReleaseImpact starts fetching the changed files of the selected MRs, and the details of those
+// not highlighted in the list yet, unless the impact of this selection is known or being computed already
+func (m *model) loadReleaseImpact() tea.Cmd {
+	mrs := m.selectedMRDetails()
+	if len(mrs) == 0 || m.creds == nil || m.selectedProject == nil {
+		m.releaseImpact = nil
+		return nil
+	}
+	key := impactKey(mrs)
+	if m.releaseImpact != nil && m.releaseImpact.key == key {
+		return nil
+	}
+	m.releaseImpact = &releaseImpact{key: key, loading: true}
+
+	client := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	return func() tea.Msg {
+		branches := make([]string, len(mrs))
+		changes := make([][]string, len(mrs))
+		details := make([]*MergeRequestDetails, len(mrs))
+		errs := make([]error, len(mrs))
+		var wg sync.WaitGroup
+		for i, mr := range mrs {
+			branches[i] = mr.SourceBranch
+			loaded, request := mr.DetailsLoaded, mr.MergeRequest
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				changes[i], errs[i] = client.GetMergeRequestChangedFiles(projectID, request.IID)
+				if !loaded {
+					details[i], _ = client.GetMergeRequestDetails(request)
+				}
+			}()
+		}
+		wg.Wait()
+
+		var serviceDirs []string
+		if config, err := LoadConfig(); err == nil {
+			serviceDirs = config.ServiceDirs
+		}
+		impact := computeReleaseImpact(branches, changes, serviceDirs)
+		impact.key = key
+		for i, err := range errs {
+			if err != nil {
+				impact.failed = append(impact.failed, branches[i])
+			}
+		}
+		msg := releaseImpactMsg{impact: impact}
+		for _, d := range details {
+			if d != nil {
+				msg.details = append(msg.details, d)
+			}
+		}
+		return msg
+	}
+}
+
+// handleReleaseImpact stores the impact of the current selection and shows it
+func (m *model) handleReleaseImpact(msg releaseImpactMsg) {
+	if m.releaseImpact == nil || m.releaseImpact.key != msg.impact.key {
+		return
+	}
+	m.releaseImpact = msg.impact
+	for _, details := range msg.details {
+		m.applyMRDetails(details)
+	}
+	if m.screen == screenConfirm {
+		m.initConfirmViewport()
+	}
+}
+
+// computeReleaseImpact aggregates the changed files of each branch (changes[i] are those of branches[i])
+func computeReleaseImpact(branches []string, changes [][]string, serviceDirs []string) *releaseImpact {
+	fileBranches := make(map[string][]string)
+	for i, files := range changes {
+		for _, file := range files {
+			if !slices.Contains(fileBranches[file], branches[i]) {
+				fileBranches[file] = append(fileBranches[file], branches[i])
+			}
+		}
+	}
+
+	impact := &releaseImpact{files: len(fileBranches)}
+	areas := make(map[string]*impactArea)
+	for file, fileBrs := range fileBranches {
+		if len(fileBrs) > 1 {
+			impact.overlaps = append(impact.overlaps, impactOverlap{path: file, branches: fileBrs})
+		}
+		name := impactAreaOf(file, serviceDirs)
+		area := areas[name]
+		if area == nil {
+			area = &impactArea{name: name}
+			areas[name] = area
+		}
+		area.files++
+		for _, b := range fileBrs {
+			if !slices.Contains(area.branches, b) {
+				area.branches = append(area.branches, b)
+			}
+		}
+	}
+	for _, area := range areas {
+		impact.areas = append(impact.areas, *area)
+	}
+
+	slices.SortFunc(impact.overlaps, func(a, b impactOverlap) int {
+		if len(a.branches) != len(b.branches) {
+			return len(b.branches) - len(a.branches)
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	slices.SortFunc(impact.areas, func(a, b impactArea) int {
+		if a.files != b.files {
+			return b.files - a.files
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	// Branches in merge order, as listed elsewhere on the screen
+	order := func(brs []string) {
+		slices.SortStableFunc(brs, func(a, b string) int {
+			return slices.Index(branches, a) - slices.Index(branches, b)
+		})
+	}
+	for _, o := range impact.overlaps {
+		order(o.branches)
+	}
+	for _, a := range impact.areas {
+		order(a.branches)
+	}
+	return impact
+}
+
+// impactAreaOf returns the area of a file: its service if it lies in one of serviceDirs,
+// otherwise its top-level directory ("/" for files at the repository root)
+func impactAreaOf(file string, serviceDirs []string) string {
+	parts := strings.Split(file, "/")
+	if len(parts) == 1 {
+		return "/"
+	}
+	for _, dir := range serviceDirs {
+		dirParts := strings.Split(strings.Trim(dir, "/"), "/")
+		if len(parts) > len(dirParts)+1 && slices.Equal(parts[:len(dirParts)], dirParts) {
+			return strings.Join(parts[:len(dirParts)+1], "/")
+		}
+	}
+	return parts[0]
+}
+
+// markdown renders the impact for the confirmation screen
+func (ri *releaseImpact) markdown() string {
+	if ri.loading {
+		return "Impact: computing from the changes of the selected MRs..."
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Impact: **%d** %s changed in **%d** %s", ri.files, plural(ri.files, "file", "files"),
+		len(ri.areas), plural(len(ri.areas), "area", "areas"))
+	if len(ri.failed) > 0 {
+		fmt.Fprintf(&sb, " ~~(changes of %s could not be fetched)~~", strings.Join(ri.failed, ", "))
+	}
+	sb.WriteString("\n")
+
+	for i, area := range ri.areas {
+		if i == maxImpactRows {
+			fmt.Fprintf(&sb, "- ...and %d more\n", len(ri.areas)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- `%s` %d %s by %s\n", area.name, area.files, plural(area.files, "file", "files"), strings.Join(area.branches, ", "))
+	}
+
+	if len(ri.overlaps) > 0 {
+		fmt.Fprintf(&sb, "\n*%d %s changed by several MRs* ~~(likely conflicts)~~:\n", len(ri.overlaps), plural(len(ri.overlaps), "file", "files"))
+		for i, o := range ri.overlaps {
+			if i == maxImpactRows {
+				fmt.Fprintf(&sb, "- ...and %d more\n", len(ri.overlaps)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "- `%s` by %s\n", o.path, strings.Join(o.branches, ", "))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Release locks keep teammates from stitching the same environment of a project at once. A lock is
+// a ref on the project's origin, refs/relix/locks/<environment>, pointing at an empty commit that
+// records who holds it, on which host and process, and since when; creating the ref only if it does
+// not exist yet makes taking it atomic. The lock is soft: the holder releases it when the release
+// completes or is aborted, a failed release keeps it until then, and anyone can override it. It is
+// released only while it still points at the release's own lock commit, so an overriding release
+// keeps its lock. Locks are taken with release_lock in the config, and always by relix serve.
+
+const releaseLockRefPrefix = "refs/relix/locks/"
+
+// releaseLocksAlways makes every release take the lock, for the releases of relix serve
+var releaseLocksAlways bool
+
+// releaseLock describes who holds the lock of an environment
+type releaseLock struct {
+	Holder  string
+	Host    string // Empty for locks taken before it was recorded
+	PID     int
+	Since   time.Time
+	Version string
+	Commit  string // Lock commit the ref points at
+}
+
+// releaseLockMsg reports taking the lock for a release started in the TUI
+type releaseLockMsg struct {
+	state *ReleaseState
+	intro []string // Lines the release output starts with
+	held  *releaseLock
+	err   error
+}
+
+// releaseLocksEnabled reports whether releases take the lock of their environment
+func releaseLocksEnabled() bool {
+	if releaseLocksAlways {
+		return true
+	}
+	config, err := LoadConfig()
+	return err == nil && config.ReleaseLock
+}
+
+// releaseLockRef returns the ref holding the lock of an environment
+func releaseLockRef(env Environment) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(env.Name))
+	return releaseLockRefPrefix + name
+}
+
+// describe tells who holds the lock of the environment and since when
+func (l *releaseLock) describe(env string) string {
+	s := fmt.Sprintf("%s is being released by %s since %s", env, l.Holder, formatDateTime(l.Since))
+	if l.Host != "" {
+		s += " on " + l.Host
+	}
+	if l.Version != "" {
+		s += fmt.Sprintf(" (version %s)", l.Version)
+	}
+	return s
+}
+
+// runLockGit runs a git command of the release lock in workDir and returns its trimmed output
+func runLockGit(workDir, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(stdin)
+	// Lock commits need no git identity: the holder is in the message
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=relix", "GIT_AUTHOR_EMAIL=relix@localhost",
+		"GIT_COMMITTER_NAME=relix", "GIT_COMMITTER_EMAIL=relix@localhost")
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// readReleaseLock returns the lock of an environment on origin, nil if it is free
+func readReleaseLock(workDir string, env Environment) (*releaseLock, error) {
+	ref := releaseLockRef(env)
+	listed, err := runLockGit(workDir, "", "ls-remote", "origin", ref)
+	if err != nil || listed == "" {
+		return nil, err
+	}
+	commit, _, _ := strings.Cut(listed, "\t")
+	if _, err := runLockGit(workDir, "", "fetch", "--no-tags", "origin", ref); err != nil {
+		return nil, err
+	}
+	message, err := runLockGit(workDir, "", "log", "-1", "--format=%B", "FETCH_HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &releaseLock{Holder: "unknown", Commit: commit}
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Holder":
+			lock.Holder = value
+		case "Host":
+			lock.Host = value
+		case "PID":
+			lock.PID, _ = strconv.Atoi(value)
+		case "Since":
+			lock.Since, _ = time.Parse(time.RFC3339, value)
+		case "Version":
+			lock.Version = value
+		}
+	}
+	return lock, nil
+}
+
+// leftOver reports whether the lock was left by a release of holder on this host that did not
+// finish: one of this process, or of one that no longer runs. The same holder on another host or in
+// another running relix holds the lock like anyone else.
+func (l *releaseLock) leftOver(holder string) bool {
+	host, _ := os.Hostname()
+	return l.Holder == holder && l.Host == host && (l.PID == os.Getpid() || !processAlive(l.PID))
+}
+
+// acquireReleaseLock takes the lock of the release's environment for holder and marks the release
+// as holding it. The lock of another holder is returned instead, unless force overrides it.
+func acquireReleaseLock(state *ReleaseState, holder string, force bool) (*releaseLock, error) {
+	ref := releaseLockRef(state.Environment)
+	// Without force, the push fails if someone took the lock since it was read
+	lease := "--force-with-lease=" + ref + ":"
+	if !force {
+		held, err := readReleaseLock(state.WorkDir, state.Environment)
+		if err != nil {
+			return nil, err
+		}
+		if held != nil && !held.leftOver(holder) {
+			return held, nil
+		}
+		if held != nil {
+			lease += held.Commit
+		}
+	}
+
+	tree, err := runLockGit(state.WorkDir, "", "mktree")
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	message := fmt.Sprintf("relix release lock\n\nHolder: %s\nHost: %s\nPID: %d\nSince: %s\nVersion: %s\n",
+		holder, host, os.Getpid(), time.Now().UTC().Format(time.RFC3339), state.Version)
+	commit, err := runLockGit(state.WorkDir, message, "commit-tree", tree, "-F", "-")
+	if err != nil {
+		return nil, err
+	}
+
+	if force {
+		lease = "--force"
+	}
+	if _, err := runLockGit(state.WorkDir, "", "push", lease, "origin", commit+":"+ref); err != nil {
+		if held, readErr := readReleaseLock(state.WorkDir, state.Environment); readErr == nil && held != nil && held.Commit != commit {
+			return held, nil
+		}
+		return nil, err
+	}
+	recordAudit(auditReleaseLock, state.Environment.Name, fmt.Sprintf("%s (force: %t)", state.Version, force), nil)
+	state.Locked = true
+	state.LockCommit = commit
+	return nil, nil
+}
+
+// unlockRelease returns the command releasing the lock held by a release, nil if it holds none.
+// The ref is deleted only while it points at the release's lock commit: a lock overridden since
+// is another release's. Releases saved before the commit was recorded leave their lock.
+func unlockRelease(state *ReleaseState) tea.Cmd {
+	if state == nil || !state.Locked || state.LockCommit == "" {
+		return nil
+	}
+	workDir, ref, commit := state.WorkDir, releaseLockRef(state.Environment), state.LockCommit
+	return func() tea.Msg {
+		runLockGit(workDir, "", "push", "--force-with-lease="+ref+":"+commit, "origin", "--delete", ref)
+		return nil
+	}
+}
+
+// releaseLockHolder returns the name the locks of this user are held under
+func releaseLockHolder(creds *Credentials) string {
+	if creds != nil && creds.Email != "" {
+		return creds.Email
+	}
+	if email, err := runLockGit(".", "", "config", "user.email"); err == nil && email != "" {
+		return email
+	}
+	return "unknown"
+}
+
+// needsReleaseLock reports whether a release started in the TUI has to take the lock first
+func (m model) needsReleaseLock(state *ReleaseState) bool {
+	return !m.headless && !state.Locked && releaseLocksEnabled()
+}
+
+// takeReleaseLock returns the command taking the lock for a release started in the TUI, off the UI
+// thread as it talks to origin
+func (m *model) takeReleaseLock(state *ReleaseState, force bool, intro []string) tea.Cmd {
+	m.releaseLockPending = true
+	holder := releaseLockHolder(m.creds)
+	return func() tea.Msg {
+		held, err := acquireReleaseLock(state, holder, force)
+		return releaseLockMsg{state: state, intro: intro, held: held, err: err}
+	}
+}
+
+// handleReleaseLock starts the release once its lock is taken. A lock another holder has is
+// shown in the lock modal, which can override it.
+func (m *model) handleReleaseLock(msg releaseLockMsg) tea.Cmd {
+	m.releaseLockPending = false
+	if msg.err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot start release: release lock: " + msg.err.Error()
+		return nil
+	}
+	if msg.held != nil {
+		m.closeAllModals()
+		m.showReleaseLock = true
+		m.releaseLockIndex = 1 // Cancel focused by default
+		m.releaseLockHeld = msg.held
+		m.releaseLockState = msg.state
+		m.releaseLockIntro = msg.intro
+		m.releaseLockTab = m.tabID
+		return nil
+	}
+	return m.beginRelease(msg.state, msg.intro...)
+}
+
+// updateReleaseLock handles keys of the lock modal
+func (m model) updateReleaseLock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "f", "F":
+		return m.overrideReleaseLock()
+	case "n", "N", "esc", "q", "ctrl+q":
+		m.closeReleaseLock()
+		return m, nil
+	case "enter":
+		if m.releaseLockIndex == 0 {
+			return m.overrideReleaseLock()
+		}
+		m.closeReleaseLock()
+		return m, nil
+	case "tab", "left", "right", "h", "l":
+		m.releaseLockIndex = 1 - m.releaseLockIndex
+		return m, nil
+	}
+	return m, nil
+}
+
+// closeReleaseLock closes the lock modal and drops the release waiting for the lock
+func (m *model) closeReleaseLock() {
+	m.showReleaseLock = false
+	m.releaseLockHeld = nil
+	m.releaseLockState = nil
+	m.releaseLockIntro = nil
+}
+
+// overrideReleaseLock takes the lock from its holder and starts the release waiting for it
+func (m model) overrideReleaseLock() (tea.Model, tea.Cmd) {
+	state, intro, tab := m.releaseLockState, m.releaseLockIntro, m.releaseLockTab
+	m.closeReleaseLock()
+	if state == nil {
+		return m, nil
+	}
+	// The release starts in the tab it was started in
+	if tab != m.tabID {
+		i := m.parkedTabIndex(tab)
+		if i < 0 {
+			return m, nil // The tab was closed meanwhile
+		}
+		return m, m.inParkedTab(i, func(t *model) tea.Cmd { return t.takeReleaseLock(state, true, intro) })
+	}
+	return m, m.takeReleaseLock(state, true, intro)
+}
+
+// overlayReleaseLock renders the lock modal
+func (m model) overlayReleaseLock(background string) string {
+	if m.releaseLockHeld == nil || m.releaseLockState == nil {
+		return background
+	}
+	env := m.releaseLockState.Environment.Name
+
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render(strings.ToUpper(env) + " Is Locked"))
+	sb.WriteString("\n\n")
+	sb.WriteString(m.releaseLockHeld.describe(env) + ".\n\n")
+	sb.WriteString("Overriding the lock lets both releases run at once: do it only if the other release is over.\n\n")
+
+	var forceBtn, cancelBtn string
+	if m.releaseLockIndex == 0 {
+		forceBtn = buttonDangerStyle.Render("Override")
+		cancelBtn = buttonStyle.Render("Cancel")
+	} else {
+		forceBtn = buttonStyle.Render("Override")
+		cancelBtn = buttonActiveStyle.Render("Cancel")
+	}
+	sb.WriteString(fmt.Sprintf("     %s       %s", forceBtn, cancelBtn))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 70,
+		Style:    errorBoxStyle,
+	}
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
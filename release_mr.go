@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultReleaseMRTemplate is the release MR description template when none is configured.
+// The stitched MRs are a checklist reviewers can tick while checking the environment.
+const defaultReleaseMRTemplate = `## Release {{.Name}} to {{.Environment}}
+
+{{if .Project}}- **Project:** {{.Project}}
+{{end}}- **Version:** {{.ReleaseVersion}}{{if .Tag}} (tag ` + "`{{.Tag}}`" + `){{end}}
+- **Environment:** {{.Environment}} (` + "`{{.EnvBranch}}`" + `)
+{{with .Rollout}}- **Rollout:** {{.}}
+{{end}}{{if .Notes}}
+{{.Notes}}
+{{end}}
+### Merge requests
+
+{{range .MRs}}- [ ] {{if .URL}}[{{md .Title}}]({{.URL}}){{else}}{{md .Title}}{{end}} (` + "`{{.Branch}}`" + `{{if .Author}}, @{{.Author}}{{end}}){{if .PipelineURL}} · [pipeline {{.PipelineStatus}}]({{.PipelineURL}}){{end}}
+{{end}}`
+
+// releaseMRDescription renders the description of the release MR from the configured template
+// (release_mr_template_file, relative to the project directory, or release_mr_template) over the
+// release notes data. The release MR does not exist yet, so its fields are empty, and the tag and
+// formatted version are derived from the v-number the release will get.
+func releaseMRDescription(client Forge, state *ReleaseState, project string, vNumber int) (string, error) {
+	config, err := LoadProjectConfig(state.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	text := config.ReleaseMRTemplate
+	if config.ReleaseMRTemplateFile != "" {
+		path := config.ReleaseMRTemplateFile
+		if settings := projectRepoSettings(state.ProjectID); settings != nil && settings.ReleaseMRTemplateFile != "" {
+			// Set by the project: only a file of the project is read
+			if path, err = resolveProjectFile(state.WorkDir, path); err != nil {
+				return "", fmt.Errorf("release MR template file: %w", err)
+			}
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(state.WorkDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("release MR template file: %w", err)
+		}
+		text = string(data)
+	}
+
+	data := collectReleaseNotesData(client, state, project)
+	if data.Tag == "" {
+		data.Tag = state.tagName(vNumber)
+		data.Name = data.Tag
+		data.ReleaseVersion = state.formatVersion(vNumber)
+	}
+	body, err := renderTextTemplate(text, defaultReleaseMRTemplate, data)
+	if err != nil {
+		return "", fmt.Errorf("release MR template: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Release notes are published after a release completes to the targets listed under
+// "release_notes" in config: Confluence pages (storage format, rendered with html/template so
+// values are escaped), GitLab wiki pages (Markdown) or the forge's release of the tag (Markdown).
+// Title and body are Go templates over releaseNotesData; each provider has a default body template.
+const (
+	releaseNotesConfluence   = "confluence"
+	releaseNotesGitLabWiki   = "gitlab_wiki"
+	releaseNotesForgeRelease = "forge_release"
+)
+
+// defaultReleaseNotesTitle is the page title template when none is configured
+const defaultReleaseNotesTitle = "Release {{.Name}} to {{.Environment}}"
+
+// defaultReleaseNotesMarkdown is the GitLab wiki and forge release body template
+const defaultReleaseNotesMarkdown = `# Release {{.Name}} to {{.Environment}}
+
+{{if .Project}}- **Project:** {{.Project}}
+{{end}}- **Environment:** {{.Environment}} ({{.EnvBranch}})
+- **Date:** {{.Date.Format "2006-01-02 15:04 MST"}}
+{{if .ReleaseMRURL}}- **Release MR:** {{.ReleaseMRURL}}{{if .ReleasePipelineURL}} ([pipeline]({{.ReleasePipelineURL}})){{end}}
+{{end}}{{with .Rollout}}- **Rollout:** {{.}}
+{{end}}{{if .Notes}}
+{{.Notes}}
+{{end}}
+## Merge requests
+
+| MR | Branch | Author | Pipeline |
+|----|--------|--------|----------|
+{{range .MRs}}| {{if .URL}}[{{md .Title}}]({{.URL}}){{else}}{{md .Title}}{{end}} | ` + "`{{.Branch}}`" + ` | {{md .Author}} | {{if .PipelineURL}}[{{.PipelineStatus}}]({{.PipelineURL}}){{end}} |
+{{end}}`
+
+// defaultReleaseNotesStorage is the Confluence body template (storage format)
+const defaultReleaseNotesStorage = `<p>{{if .Project}}<strong>Project:</strong> {{.Project}}<br/>{{end}}<strong>Environment:</strong> {{.Environment}} ({{.EnvBranch}})<br/><strong>Date:</strong> {{.Date.Format "2006-01-02 15:04 MST"}}{{if .ReleaseMRURL}}<br/><strong>Release MR:</strong> <a href="{{.ReleaseMRURL}}">{{.ReleaseMRURL}}</a>{{if .ReleasePipelineURL}} (<a href="{{.ReleasePipelineURL}}">pipeline</a>){{end}}{{end}}{{with .Rollout}}<br/><strong>Rollout:</strong> {{.}}{{end}}</p>
+{{range .NotesParagraphs}}<p>{{.}}</p>
+{{end}}<h2>Merge requests</h2>
+<table><tbody>
+<tr><th>MR</th><th>Branch</th><th>Author</th><th>Pipeline</th></tr>
+{{range .MRs}}<tr><td>{{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</td><td><code>{{.Branch}}</code></td><td>{{.Author}}</td><td>{{if .PipelineURL}}<a href="{{.PipelineURL}}">{{.PipelineStatus}}</a>{{end}}</td></tr>
+{{end}}</tbody></table>`
+
+// releaseNotesData is what title and body templates render
+type releaseNotesData struct {
+	Name               string // Tag, or version if there is none
+	Project            string
+	Version            string
+	ReleaseVersion     string // Version formatted by the environment's rules, e.g. "v1.2.3-rc.2"
+	Tag                string
+	Environment        string
+	EnvBranch          string
+	Date               time.Time
+	ReleaseMRURL       string
+	ReleasePipelineURL string
+	Notes              string   // Written in the editor during the release ("n" on the release screen)
+	Rollout            *Rollout // Canary percentage and feature flags, nil if not given
+	MRs                []releaseNotesMR
+}
+
+// NotesParagraphs splits Notes at blank lines, for templates that wrap paragraphs in markup
+func (d releaseNotesData) NotesParagraphs() []string {
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(d.Notes, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return paragraphs
+}
+
+// releaseNotesMR is a stitched MR in the release notes
+type releaseNotesMR struct {
+	Branch         string
+	Title          string // Branch name if the MR could not be fetched
+	Author         string
+	URL            string
+	PipelineURL    string // Latest pipeline of the MR's commit at release time
+	PipelineStatus string
+}
+
+// releaseNotesMsg reports published pages and failed targets
+type releaseNotesMsg struct {
+	urls []string
+	errs []error
+}
+
+// publishReleaseNotes returns a command publishing notes of the completed release to the configured
+// targets, or nil if there is no release or no credentials
+func (m *model) publishReleaseNotes() tea.Cmd {
+	if m.releaseState == nil || m.creds == nil {
+		return nil
+	}
+	state := *m.releaseState
+	creds := *m.creds
+	project := ""
+	if m.selectedProject != nil {
+		project = m.selectedProject.PathWithNamespace
+	}
+
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return nil
+		}
+		var targets []ReleaseNotesConfig
+		for _, rc := range config.ReleaseNotes {
+			if len(rc.Environments) == 0 || containsFold(rc.Environments, state.Environment.Name) || containsFold(rc.Environments, state.Environment.BranchName) {
+				targets = append(targets, rc)
+			}
+		}
+		if len(targets) == 0 {
+			return nil
+		}
+
+		data := collectReleaseNotesData(NewForge(creds), &state, project)
+		var msg releaseNotesMsg
+		for _, rc := range targets {
+			url, err := rc.publish(creds, &state, data)
+			recordAudit(auditReleaseNotes, rc.Provider, url, err)
+			if err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("%s release notes failed: %w", rc.Provider, err))
+			} else if url != "" {
+				msg.urls = append(msg.urls, url)
+			}
+		}
+		return msg
+	}
+}
+
+// collectReleaseNotesData fetches titles, authors and pipelines of the released MRs.
+// Failed lookups leave the fields empty; the notes are published anyway.
+func collectReleaseNotesData(client Forge, state *ReleaseState, project string) releaseNotesData {
+	data := releaseNotesData{
+		Name:           state.TagName,
+		Project:        project,
+		Version:        state.Version,
+		ReleaseVersion: state.ReleaseVersion,
+		Tag:            state.TagName,
+		Environment:    state.Environment.Name,
+		EnvBranch:      state.Environment.BranchName,
+		Date:           time.Now(),
+		ReleaseMRURL:   state.CreatedMRURL,
+		Notes:          state.ReleaseNotes,
+		Rollout:        state.Rollout,
+	}
+	if data.Name == "" {
+		data.Name = state.Version
+	}
+	if data.ReleaseVersion == "" {
+		data.ReleaseVersion = state.Version
+	}
+	if state.CreatedMRIID != 0 {
+		if pipelines, err := client.GetMergeRequestPipelines(state.ProjectID, state.CreatedMRIID); err == nil && len(pipelines) > 0 {
+			data.ReleasePipelineURL = pipelines[0].WebURL
+		}
+	}
+
+	for i, branch := range state.MRBranches {
+		mr := releaseNotesMR{Branch: branch, Title: branch}
+		if i < len(state.MRURLs) {
+			mr.URL = state.MRURLs[i]
+		}
+		if i < len(state.SelectedMRIIDs) {
+			if details, err := client.GetMergeRequestByIID(state.ProjectID, state.SelectedMRIIDs[i]); err == nil {
+				mr.Title = details.Title
+				mr.Author = details.Author.Username
+			}
+		}
+		if i < len(state.MRCommitSHAs) && state.MRCommitSHAs[i] != "" {
+			if pipelines, err := client.GetPipelinesByCommit(state.ProjectID, state.MRCommitSHAs[i]); err == nil && len(pipelines) > 0 {
+				mr.PipelineURL = pipelines[0].WebURL
+				mr.PipelineStatus = pipelines[0].Status
+			}
+		}
+		data.MRs = append(data.MRs, mr)
+	}
+	return data
+}
+
+// publish renders the notes and creates the page, returning its URL
+func (rc ReleaseNotesConfig) publish(creds Credentials, state *ReleaseState, data releaseNotesData) (string, error) {
+	title, err := renderTextTemplate(rc.Title, defaultReleaseNotesTitle, data)
+	if err != nil {
+		return "", fmt.Errorf("title template: %w", err)
+	}
+	title = strings.TrimSpace(title)
+	body, err := rc.bodyTemplate(state.WorkDir)
+	if err != nil {
+		return "", err
+	}
+
+	switch rc.Provider {
+	case releaseNotesConfluence:
+		tmpl, err := htmltemplate.New("notes").Parse(body)
+		if err != nil {
+			return "", fmt.Errorf("template: %w", err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("template: %w", err)
+		}
+		return rc.publishConfluence(title, b.String())
+
+	case releaseNotesGitLabWiki:
+		content, err := renderTextTemplate(body, "", data)
+		if err != nil {
+			return "", fmt.Errorf("template: %w", err)
+		}
+		if creds.Forge != forgeGitLab {
+			return "", fmt.Errorf("GitLab wiki needs GitLab credentials")
+		}
+		projectID := state.ProjectID
+		if rc.ProjectID != 0 {
+			projectID = rc.ProjectID
+		}
+		if rc.Parent != "" {
+			title = strings.Trim(rc.Parent, "/") + "/" + title
+		}
+		return NewGitLabClient(creds.GitLabURL, creds.Token).CreateWikiPage(projectID, title, content)
+
+	case releaseNotesForgeRelease:
+		content, err := renderTextTemplate(body, "", data)
+		if err != nil {
+			return "", fmt.Errorf("template: %w", err)
+		}
+		if state.TagName == "" {
+			return "", fmt.Errorf("the release has no tag")
+		}
+		// GitHub would create a missing tag on the default branch, so the release is only
+		// created for the tag the release pushed
+		client := NewForge(creds)
+		tags, err := client.GetTags(state.ProjectID, state.TagName)
+		if err != nil {
+			return "", fmt.Errorf("tags: %w", err)
+		}
+		if !slices.ContainsFunc(tags, func(t Tag) bool { return t.Name == state.TagName }) {
+			return "", fmt.Errorf("tag %s is not on the forge", state.TagName)
+		}
+		return client.CreateRelease(state.ProjectID, state.TagName, title, content)
+	}
+	return "", fmt.Errorf("unknown release notes provider %q", rc.Provider)
+}
+
+// bodyTemplate returns the configured body template, read from template_file (relative to the
+// project directory) if set, or the provider's default
+func (rc ReleaseNotesConfig) bodyTemplate(workDir string) (string, error) {
+	if rc.TemplateFile != "" {
+		path := rc.TemplateFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("template file: %w", err)
+		}
+		return string(data), nil
+	}
+	if rc.Template != "" {
+		return rc.Template, nil
+	}
+	if rc.Provider == releaseNotesConfluence {
+		return defaultReleaseNotesStorage, nil
+	}
+	return defaultReleaseNotesMarkdown, nil
+}
+
+// renderTextTemplate renders a text template (or fallback when it is empty) over data.
+// The md function escapes Markdown in table cells and link labels.
+func renderTextTemplate(text, fallback string, data releaseNotesData) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New("notes").Funcs(template.FuncMap{
+		"md": func(s string) string { return strings.ReplaceAll(markdownEscape(s), "|", "\\|") },
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// publishConfluence creates a page in the configured space (under the parent page, if set).
+// With an email, the token is a Confluence Cloud API token; otherwise a Server/Data Center personal token.
+func (rc ReleaseNotesConfig) publishConfluence(title, body string) (string, error) {
+	if rc.URL == "" || rc.Space == "" {
+		return "", fmt.Errorf("url and space are required")
+	}
+	page := map[string]interface{}{
+		"type":  "page",
+		"title": title,
+		"space": map[string]string{"key": rc.Space},
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+	}
+	if rc.ParentID != "" {
+		page["ancestors"] = []map[string]string{{"id": rc.ParentID}}
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(rc.URL, "/")+"/rest/api/content", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if rc.Email != "" {
+		req.SetBasicAuth(rc.Email, rc.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+rc.Token)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return "", fmt.Errorf("status %d, %s", resp.StatusCode, apiErr.Message)
+		}
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		Links struct {
+			Base  string `json:"base"`
+			WebUI string `json:"webui"`
+		} `json:"_links"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", nil // Created; only the link is missing
+	}
+	return created.Links.Base + created.Links.WebUI, nil
+}
+
+// handleReleaseNotes reports the published pages in the release output
+func (m *model) handleReleaseNotes(msg releaseNotesMsg) {
+	if m.releaseState == nil {
+		return
+	}
+	for _, url := range msg.urls {
+		m.appendReleaseOutput("Release notes published: " + url)
+	}
+	for _, err := range msg.errs {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("WARNING: " + err.Error()))
+	}
+}
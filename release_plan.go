@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReleasePlan describes a release independently of the TUI selection screens.
+// It is what non-interactive callers (API server, CLI) submit to start a release.
+type ReleasePlan struct {
+	Environment  string   `json:"environment"`              // Environment name, e.g. "PROD" (case-insensitive)
+	Version      string   `json:"version"`                  // Semantic version, e.g. "1.2.3"
+	SourceBranch string   `json:"source_branch,omitempty"`  // Defaults to release/rpb-{version}-root
+	MRIIDs       []int    `json:"mr_iids"`                  // MR IIDs in merge order
+	RootMerge    *bool    `json:"root_merge,omitempty"`     // Merge release to base branch and develop (default true)
+	EnvMergeMode string   `json:"env_merge_mode,omitempty"` // "squash" (default) or "regular"
+	Rollout      *Rollout `json:"rollout,omitempty"`        // Canary percentage and feature flags (see rollout.go)
+
+	PipelineInputs map[string]string `json:"pipeline_inputs,omitempty"` // Deployment pipeline inputs by name (see pipeline_inputs.go)
+}
+
+// projectWorkDir returns the local clone configured for the project in project_dirs, or the
+// project root found from the working directory
+func projectWorkDir(project *Project) (string, error) {
+	if project != nil {
+		if config, err := LoadConfig(); err == nil && config.ProjectDirs[project.PathWithNamespace] != "" {
+			return expandHome(config.ProjectDirs[project.PathWithNamespace])
+		}
+	}
+	return FindProjectRoot()
+}
+
+// releaseWorkDirFor returns the directory a release to the environment would run in, without
+// preparing it, so it can be checked before a release checkout is cleaned up
+func releaseWorkDirFor(project *Project, env string) (string, error) {
+	if e, ok := findEnvironment(env); ok && releaseCheckoutsEnabled() {
+		_, _, path, err := releaseCheckoutFor(project, e.Name)
+		return path, err
+	}
+	return projectWorkDir(project)
+}
+
+// prepareReleaseWorkDir finds the directory to release to an environment from and makes sure it is
+// safe to: the release checkout of the environment with release_checkouts (see
+// release_checkouts.go), otherwise the project's clone; env "" (or an unknown one, rejected later
+// with the plan) picks the project's clone
+func prepareReleaseWorkDir(project *Project, env string) (string, error) {
+	if e, ok := findEnvironment(env); ok && releaseCheckoutsEnabled() {
+		workDir, err := prepareReleaseCheckout(project, e.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to prepare the release checkout: %w", err)
+		}
+		return workDir, nil
+	}
+
+	workDir, err := projectWorkDir(project)
+	if err != nil {
+		return "", fmt.Errorf("failed to find project root: %w", err)
+	}
+
+	hasChanges, err := HasUncommittedChanges(workDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to check git status: %w", err)
+	}
+	if hasChanges {
+		return "", fmt.Errorf("there are uncommitted changes in the working directory")
+	}
+	return workDir, nil
+}
+
+// newReleaseState builds the initial release state for a plan whose environment and MRs are resolved
+func newReleaseState(plan ReleasePlan, env Environment, mrs []*MergeRequestDetails, sourceBranchIsRemote bool, projectID int, workDir string) *ReleaseState {
+	var mrIIDs []int
+	var branches []string
+	var mrURLs []string
+	var mrCommitSHAs []string
+	var mrTitles []string
+	var mrAuthors []string
+	for _, mr := range mrs {
+		mrIIDs = append(mrIIDs, mr.IID)
+		branches = append(branches, mr.SourceBranch)
+		mrURLs = append(mrURLs, mr.WebURL)
+		mrCommitSHAs = append(mrCommitSHAs, mr.SHA)
+		mrTitles = append(mrTitles, mr.Title)
+		mrAuthors = append(mrAuthors, mr.Author.Username)
+	}
+
+	envMergeMode := plan.EnvMergeMode
+	if envMergeMode == "" {
+		envMergeMode = "squash"
+	}
+	rootMerge := plan.RootMerge == nil || *plan.RootMerge
+
+	return &ReleaseState{
+		SelectedMRIIDs:       mrIIDs,
+		MRBranches:           branches,
+		MRURLs:               mrURLs,
+		MRCommitSHAs:         mrCommitSHAs,
+		MRTitles:             mrTitles,
+		MRAuthors:            mrAuthors,
+		Environment:          env,
+		Version:              plan.Version,
+		BaseBranch:           getBaseBranch(),
+		SourceBranch:         plan.SourceBranch,
+		SourceBranchIsRemote: sourceBranchIsRemote,
+		RootMerge:            rootMerge,
+		EnvMergeMode:         envMergeMode,
+		ProjectID:            projectID,
+		CurrentStep:          ReleaseStepGitFetch,
+		LastSuccessStep:      ReleaseStepIdle,
+		MergedBranches:       []string{},
+		WorkDir:              workDir,
+		Rollout:              plan.Rollout,
+		VersionDate:          versionDate(time.Now()),
+	}
+}
+
+// findEnvironment returns the configured environment with the given name (case-insensitive)
+func findEnvironment(name string) (Environment, bool) {
+	for _, env := range getEnvironments() {
+		if strings.EqualFold(env.Name, name) {
+			return env, true
+		}
+	}
+	return Environment{}, false
+}
+
+// resolveReleasePlan validates a plan, fetches its MRs from GitLab and builds the release state.
+// It applies the same rules the TUI screens enforce on interactive input, and the hook script.
+// Without a version, the version hook computes it.
+func resolveReleasePlan(plan ReleasePlan, client Forge, projectID int, workDir string) (*ReleaseState, error) {
+	if err := useRepoSettings(client, projectID); err != nil {
+		return nil, fmt.Errorf("project settings: %w", err)
+	}
+	hooks, err := loadScriptHooks(client, projectID, hookOutput)
+	if err != nil {
+		return nil, err
+	}
+	if plan, err = hooks.applyPlan(plan); err != nil {
+		return nil, err
+	}
+
+	scheme, err := activeVersionScheme()
+	if err != nil {
+		return nil, err
+	}
+	// Checked before any MR is fetched; a version computed by the hook is checked once it is known
+	if !scheme.valid(plan.Version) {
+		return nil, fmt.Errorf("invalid version %q (use %s)", plan.Version, scheme.format)
+	}
+
+	env, ok := findEnvironment(plan.Environment)
+	if !ok {
+		var names []string
+		for _, e := range getEnvironments() {
+			names = append(names, e.Name)
+		}
+		return nil, fmt.Errorf("unknown environment %q (available: %s)", plan.Environment, strings.Join(names, ", "))
+	}
+
+	if plan.EnvMergeMode != "" && plan.EnvMergeMode != "squash" && plan.EnvMergeMode != "regular" {
+		return nil, fmt.Errorf("invalid env merge mode %q (use squash or regular)", plan.EnvMergeMode)
+	}
+
+	if err := plan.Rollout.validate(); err != nil {
+		return nil, err
+	}
+
+	declared, err := loadPipelineInputs(client, env)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := resolvePipelineInputs(declared, plan.PipelineInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plan.MRIIDs) == 0 {
+		return nil, fmt.Errorf("no merge requests selected")
+	}
+
+	seen := make(map[int]bool)
+	var mrs []*MergeRequestDetails
+	for _, iid := range plan.MRIIDs {
+		if seen[iid] {
+			return nil, fmt.Errorf("MR !%d is listed more than once", iid)
+		}
+		seen[iid] = true
+
+		mr, err := client.GetMergeRequestByIID(projectID, iid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch MR !%d: %w", iid, err)
+		}
+		if mr.State != "opened" {
+			return nil, fmt.Errorf("MR !%d is %s, only open MRs can be released", iid, mr.State)
+		}
+		if mr.Draft {
+			return nil, fmt.Errorf("MR !%d is a draft", iid)
+		}
+		if reason, blocked := blockedMRReason(projectID, iid); blocked {
+			return nil, blockedMRError(iid, reason)
+		}
+		if ok, reason, err := hooks.includeMR(mr); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("MR !%d cannot be released: %s", iid, reason)
+		}
+		mrs = append(mrs, mr)
+	}
+
+	if plan.Version == "" {
+		if plan.Version, err = hooks.version(env, mrs); err != nil {
+			return nil, err
+		}
+		if plan.Version == "" {
+			return nil, fmt.Errorf("no version given and the hook script defines no version function")
+		}
+		if !scheme.valid(plan.Version) {
+			return nil, fmt.Errorf("the version hook returned invalid version %q (use %s)", plan.Version, scheme.format)
+		}
+	}
+	if plan.SourceBranch == "" {
+		plan.SourceBranch = "release/rpb-" + plan.Version + "-root"
+	}
+	if !strings.Contains(plan.SourceBranch, plan.Version) {
+		return nil, fmt.Errorf("source branch %q must contain version %s", plan.SourceBranch, plan.Version)
+	}
+	if err := checkRefFormat(ReleaseTagName(env, plan.Version, 1)); err != nil {
+		return nil, fmt.Errorf("invalid release tag: %w", err)
+	}
+
+	sourceBranchIsRemote := RemoteBranchExists(workDir, plan.SourceBranch)
+
+	state := newReleaseState(plan, env, mrs, sourceBranchIsRemote, projectID, workDir)
+	state.PipelineInputs = inputs
+	return state, nil
+}
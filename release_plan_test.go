@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveReleasePlanHookVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", "")
+	directory := projectDirectory
+	t.Cleanup(func() { projectDirectory = directory })
+	stop, err := startDemo("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	creds, project, err := loadCLISession(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := getConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"next", `invalid version "next"`},
+		{"1.0.0", ""},
+	}
+	for _, tt := range tests {
+		script := "function version(ctx) return \"" + tt.version + "\" end"
+		if err := os.WriteFile(filepath.Join(dir, "hooks.lua"), []byte(script), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		state, err := resolveReleasePlan(ReleasePlan{Environment: "develop", MRIIDs: []int{12}}, NewForge(*creds), project, projectDirectory)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("version %s: %v", tt.version, err)
+		case tt.want == "" && state.Version != tt.version:
+			t.Errorf("version %s: resolved %s", tt.version, state.Version)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("version %s: error %v, want %s", tt.version, err, tt.want)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -89,6 +92,9 @@ func calculateReleaseTotalSteps(state *ReleaseState) int {
 		total += 3 // checkout release-root, tag, push with tags
 	}
 	total += 1 // SwitchToRoot
+	if state.Environment.DeployPipeline != nil {
+		total += 1 // Deployment pipeline
+	}
 	return total
 }
 
@@ -178,26 +184,23 @@ func (m *model) updateReleaseViewport() {
 		}
 		content += m.releaseCurrentScreen
 	}
+	if m.releaseOutputWrap {
+		content = strings.Join(wrapLines(strings.Split(content, "\n"), m.releaseViewport.Width), "\n")
+	}
 	m.releaseViewport.SetContent(content)
 	m.releaseViewport.GotoBottom()
 }
 
 // appendReleaseOutput adds a line to the output buffer
 func (m *model) appendReleaseOutput(line string) {
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, line)
-
-	// Enforce buffer limit
-	if len(m.releaseOutputBuffer) > maxOutputLines {
-		m.releaseOutputBuffer = m.releaseOutputBuffer[len(m.releaseOutputBuffer)-maxOutputLines:]
-	}
-
+	m.addReleaseOutput(line)
 	m.updateReleaseViewport()
 }
 
 // appendRecoveryMetadata adds recovery metadata to the terminal output at release start
 func (m *model) appendRecoveryMetadata(workDir string, state *ReleaseState) {
 	headerStyle := lipgloss.NewStyle().Foreground(currentTheme.Success)
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, headerStyle.Render("Release recover metadata:"))
+	m.addReleaseOutput(headerStyle.Render("Release recover metadata:"))
 
 	// Get commit IDs for various branches
 	baseBranch := state.BaseBranch
@@ -225,16 +228,16 @@ func (m *model) appendRecoveryMetadata(workDir string, state *ReleaseState) {
 	// Format string with dynamic width
 	format := fmt.Sprintf("%%-%ds %%s", maxWidth)
 
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, fmt.Sprintf(format, baseBranchLabel, rootCommit))
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, fmt.Sprintf(format, originBaseBranchLabel, originRootCommit))
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, fmt.Sprintf(format, envBranchLabel, originEnvCommit))
+	m.addReleaseOutput(fmt.Sprintf(format, baseBranchLabel, rootCommit))
+	m.addReleaseOutput(fmt.Sprintf(format, originBaseBranchLabel, originRootCommit))
+	m.addReleaseOutput(fmt.Sprintf(format, envBranchLabel, originEnvCommit))
 
 	// Only show source branch if it existed remotely on release start
 	if state.SourceBranchIsRemote {
 		originSourceCommit := GetBranchCommitID(workDir, "origin/"+state.SourceBranch)
-		m.releaseOutputBuffer = append(m.releaseOutputBuffer, fmt.Sprintf(format, sourceBranchLabel, originSourceCommit))
+		m.addReleaseOutput(fmt.Sprintf(format, sourceBranchLabel, originSourceCommit))
 	}
-	m.releaseOutputBuffer = append(m.releaseOutputBuffer, "") // Empty line after metadata
+	m.addReleaseOutput("") // Empty line after metadata
 }
 
 // updateRelease handles key events on the release screen
@@ -243,6 +246,12 @@ func (m model) updateRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.showOpenOptionsModal {
 		return m.updateOpenOptionsModal(msg)
 	}
+	if m.showArtifactsModal {
+		return m.updateArtifactsModal(msg)
+	}
+	if m.showRebaseEditor {
+		return m.updateRebaseEditor(msg)
+	}
 
 	// Handle delete remote branch confirmation modal (second step after abort confirm)
 	if m.showDeleteRemoteConfirm {
@@ -389,12 +398,55 @@ func (m model) updateRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.releaseViewport.GotoBottom()
 		return m, nil
 
+	case "w":
+		// Toggle between wrapped and truncated output lines
+		m.releaseOutputWrap = !m.releaseOutputWrap
+		m.releaseViewport.SetXOffset(0)
+		m.updateReleaseViewport()
+		return m, nil
+
+	case "<":
+		m.releaseViewport.ScrollLeft(outputScrollStep)
+		return m, nil
+
+	case ">":
+		if !m.releaseOutputWrap {
+			m.releaseViewport.ScrollRight(outputScrollStep)
+		}
+		return m, nil
+
 	case "o":
 		// Show options modal for release resources
 		if m.releaseState != nil {
 			return m.handleOpenAction(buildReleaseOpenOptions(m.releaseState, m.pipelineStatus))
 		}
 		return m, nil
+
+	case "a":
+		// Download artifacts of a release pipeline job
+		return m.openArtifactsModal()
+
+	case "!":
+		// Work in the release's working copy by hand
+		cmd := m.openShell()
+		return m, cmd
+
+	case "r":
+		// Reorder, squash or drop the commits of the source branch before creating the MR
+		return m.openRebaseEditor()
+
+	case "e":
+		// Write the release MR description before creating the MR
+		cmd := m.editMRDescription()
+		return m, cmd
+
+	case "n":
+		// Write the release notes text until the notes are published
+		if m.releaseState != nil && m.releaseState.CurrentStep != ReleaseStepComplete {
+			cmd := m.editText(editReleaseNotes, m.releaseState.ReleaseNotes)
+			return m, cmd
+		}
+		return m, nil
 	}
 
 	// Viewport scrolling
@@ -463,11 +515,25 @@ func (m model) viewRelease() string {
 
 	// Help footer
 	helpText := "tab: focus • j/k/d/u/g/G: scroll • enter: action"
+	if m.releaseOutputWrap {
+		helpText += " • w: truncate"
+	} else {
+		helpText += " • </>: scroll sideways • w: wrap"
+	}
 	// Add "o: open" hint when MR URL or pipeline URL is available
 	if m.releaseState != nil && (m.releaseState.CreatedMRURL != "" || (m.pipelineStatus != nil && m.pipelineStatus.PipelineWebURL != "")) {
 		helpText += " • o: open"
 	}
-	helpText += " • /: commands"
+	if m.canDownloadArtifacts() {
+		helpText += " • a: artifacts"
+	}
+	if m.releaseState != nil && m.releaseState.CurrentStep == ReleaseStepWaitForMR {
+		helpText += " • e: edit MR description • r: rebase"
+	}
+	if m.releaseState != nil && m.releaseState.CurrentStep != ReleaseStepComplete {
+		helpText += " • n: notes"
+	}
+	helpText += " • !: shell • /: commands"
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 
 	view := lipgloss.JoinVertical(lipgloss.Left, main, help)
@@ -558,17 +624,8 @@ func (m model) renderReleaseStatus(width int) string {
 
 	state := m.releaseState
 
-	// Calculate progress from substep counters
-	completed := state.CompletedSubSteps
-	total := state.TotalSubSteps
-	if total == 0 {
-		total = 1
-	}
-	percentage := (completed * 100) / total
-	if state.CurrentStep == ReleaseStepComplete {
-		percentage = 100
-	}
-	progressText := fmt.Sprintf("%d%% [%d/%d]", percentage, completed, state.TotalSubSteps)
+	// Progress bar and percentage from substep counters (see progress.go)
+	progressText := m.renderReleaseProgress(state)
 
 	var status string
 
@@ -593,7 +650,7 @@ func (m model) renderReleaseStatus(width int) string {
 			}
 			status = fmt.Sprintf("Release is %s on %s because of\n%s\nResolve merge issues and press %s",
 				releaseSuspendedStyle.Render("SUSPENDED"),
-				releasePercentStyle.Render(progressText),
+				progressText,
 				errorType,
 				releaseActiveTextStyle.Render("Retry"),
 			)
@@ -606,7 +663,7 @@ func (m model) renderReleaseStatus(width int) string {
 			cmds := NewReleaseCommandsWithSourceBranch(state.WorkDir, state.Version, statusBaseBranch, &state.Environment, nil, nil, state.SourceBranch, state.SourceBranchIsRemote)
 			status = fmt.Sprintf("Release is %s on %s because of\n%s %s\nFix errors in %s branch, commit them and press %s",
 				releaseSuspendedStyle.Render("SUSPENDED"),
-				releasePercentStyle.Render(progressText),
+				progressText,
 				releaseErrorStyle.Render("ERROR"),
 				state.LastError.Message,
 				releaseOrangeStyle.Render(cmds.ReleaseRootBranch()),
@@ -622,7 +679,7 @@ func (m model) renderReleaseStatus(width int) string {
 			}
 			status = fmt.Sprintf("Release is %s on %s because of\n%s %s\n%s",
 				releaseSuspendedStyle.Render("SUSPENDED"),
-				releasePercentStyle.Render(progressText),
+				progressText,
 				releaseErrorStyle.Render("ERROR"),
 				state.LastError.Message,
 				lastStatusLine,
@@ -637,7 +694,7 @@ func (m model) renderReleaseStatus(width int) string {
 		status = fmt.Sprintf("%s %s %s\nCreating root release branch...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText))
+			progressText)
 
 	case ReleaseStepMergeBranches:
 		branchName := ""
@@ -647,7 +704,7 @@ func (m model) renderReleaseStatus(width int) string {
 		status = fmt.Sprintf("%s %s %s\nMerging %s MR of %d: %s",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText),
+			progressText,
 			ordinal(state.CurrentMRIndex+1),
 			len(state.MRBranches),
 			branchName)
@@ -656,32 +713,32 @@ func (m model) renderReleaseStatus(width int) string {
 		status = fmt.Sprintf("%s %s %s\nCreating environment branch for %s...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText),
+			progressText,
 			getReleaseEnvStyle(state.Environment.Name).Render(state.Environment.Name))
 
 	case ReleaseStepCopyContent:
 		status = fmt.Sprintf("%s %s %s\nCopying content from root branch...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText))
+			progressText)
 
 	case ReleaseStepCommit:
 		status = fmt.Sprintf("%s %s %s\nCreating release commit...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText))
+			progressText)
 
 	case ReleaseStepPushBranches:
 		status = fmt.Sprintf("%s %s %s\nPushing %s and release branch to remote...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText),
+			progressText,
 			releaseOrangeStyle.Render(state.SourceBranch))
 
 	case ReleaseStepWaitForMR:
 		status = fmt.Sprintf("Release is %s %s\nNow do next step - create its merge request to %s\nPress %s",
 			releaseSuccessGreenStyle.Render(" SUCCESSFULLY COMPOSED "),
-			releasePercentStyle.Render(progressText),
+			progressText,
 			getReleaseEnvStyle(state.Environment.Name).Render(state.Environment.Name),
 			releaseTextActiveStyle.Render("Create MR to "+state.Environment.Name),
 		)
@@ -690,7 +747,7 @@ func (m model) renderReleaseStatus(width int) string {
 		status = fmt.Sprintf("%s %s %s\nPushing env branch and creating merge request to %s...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText),
+			progressText,
 			getReleaseEnvStyle(state.Environment.Name).Render(state.Environment.Name))
 
 	case ReleaseStepWaitForRootPush:
@@ -699,14 +756,14 @@ func (m model) renderReleaseStatus(width int) string {
 		if pipelineStatus != "" {
 			status = fmt.Sprintf("Merge request is %s %s\n%s\nNow you can push release branch to root and develop:\n%s",
 				releaseSuccessGreenStyle.Render(" CREATED "),
-				releasePercentStyle.Render(progressText),
+				progressText,
 				pipelineStatus,
 				hintText,
 			)
 		} else {
 			status = fmt.Sprintf("Merge request is %s %s\nNow you can push release branch to root and develop:\n%s",
 				releaseSuccessGreenStyle.Render(" CREATED "),
-				releasePercentStyle.Render(progressText),
+				progressText,
 				hintText,
 			)
 		}
@@ -715,13 +772,25 @@ func (m model) renderReleaseStatus(width int) string {
 		status = fmt.Sprintf("%s %s %s\nPushing root branches...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText))
+			progressText)
 
 	case ReleaseStepSwitchToRoot:
 		status = fmt.Sprintf("%s %s %s\nSwitching back to root branch...",
 			m.spinner.View(),
 			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
-			releasePercentStyle.Render(progressText))
+			progressText)
+
+	case ReleaseStepDeployPipeline:
+		pipeline := "Starting"
+		if state.DeployPipelineURL != "" {
+			pipeline = "Waiting for " + releaseOrangeStyle.Render(state.DeployPipelineURL)
+		}
+		status = fmt.Sprintf("%s %s %s\n%s deployment pipeline of %s...",
+			m.spinner.View(),
+			getReleaseEnvStyle(state.Environment.Name).Render("RELEASING"),
+			progressText,
+			pipeline,
+			state.Environment.DeployPipeline.Project)
 
 	case ReleaseStepComplete:
 		status = fmt.Sprintf("Release is %s\nPress %s to open MR link, or press\n%s to exit this release screen",
@@ -888,44 +957,32 @@ func (m model) overlayDeleteRemoteConfirm(background string) string {
 	return placeOverlayCenter(modal, background, m.width, m.height)
 }
 
-// startRelease initiates the release process
+// startRelease initiates the release process from the TUI selections
 func (m *model) startRelease() (tea.Model, tea.Cmd) {
-	// Find project root
-	workDir, err := FindProjectRoot()
-	if err != nil {
-		m.showErrorModal = true
-		m.errorModalMsg = fmt.Sprintf("Failed to find project root: %v", err)
-		return m, nil
+	envName := ""
+	if m.selectedEnv != nil {
+		envName = m.selectedEnv.Name
+	}
+	// Another tab releasing from the same directory is checked before the directory is prepared
+	if workDir, err := releaseWorkDirFor(m.selectedProject, envName); err == nil {
+		if n := m.releaseWorkDirInUse(workDir); n != 0 {
+			m.showErrorModal = true
+			m.errorModalMsg = fmt.Sprintf("Cannot start release: tab %d is releasing from %s. Map this project to a clone of its own with project_dirs, or turn on release_checkouts, in the config.", n, workDir)
+			return m, nil
+		}
 	}
-
-	// Check for uncommitted changes
-	hasChanges, err := HasUncommittedChanges(workDir)
+	workDir, err := prepareReleaseWorkDir(m.selectedProject, envName)
 	if err != nil {
 		m.showErrorModal = true
-		m.errorModalMsg = fmt.Sprintf("Failed to check git status: %v", err)
-		return m, nil
-	}
-	if hasChanges {
-		m.showErrorModal = true
-		m.errorModalMsg = "Cannot start release: there are uncommitted changes in the working directory"
+		m.errorModalMsg = "Cannot start release: " + err.Error()
 		return m, nil
 	}
 
-	// Collect selected MR branches
-	items := m.list.Items()
+	// Collect selected MRs
+	mrs := m.selectedMRDetails()
 	var mrIIDs []int
-	var branches []string
-	var mrURLs []string
-	var mrCommitSHAs []string
-	for _, item := range items {
-		if mr, ok := item.(mrListItem); ok {
-			if m.selectedMRs[mr.MR().IID] {
-				mrIIDs = append(mrIIDs, mr.MR().IID)
-				branches = append(branches, mr.MR().SourceBranch)
-				mrURLs = append(mrURLs, mr.MR().WebURL)
-				mrCommitSHAs = append(mrCommitSHAs, mr.MR().SHA)
-			}
-		}
+	for _, mr := range mrs {
+		mrIIDs = append(mrIIDs, mr.IID)
 	}
 
 	// Determine if source branch exists remotely based on the check status
@@ -936,49 +993,100 @@ func (m *model) startRelease() (tea.Model, tea.Cmd) {
 		envMergeMode = "regular"
 	}
 
-	// Create release state
-	state := &ReleaseState{
-		SelectedMRIIDs:       mrIIDs,
-		MRBranches:           branches,
-		MRURLs:               mrURLs,
-		MRCommitSHAs:         mrCommitSHAs,
-		Environment:          *m.selectedEnv,
-		Version:              m.versionInput.Value(),
-		BaseBranch:           getBaseBranch(),
-		SourceBranch:         m.sourceBranchInput.Value(),
-		SourceBranchIsRemote: sourceBranchIsRemote,
-		RootMerge:            m.rootMergeSelection,
-		EnvMergeMode:         envMergeMode,
-		ProjectID:            m.selectedProject.ID,
-		CurrentStep:          ReleaseStepGitFetch,
-		LastSuccessStep:      ReleaseStepIdle,
-		MergedBranches:       []string{},
-		WorkDir:              workDir,
+	plan := ReleasePlan{
+		Environment:  m.selectedEnv.Name,
+		Version:      m.versionInput.Value(),
+		SourceBranch: m.sourceBranchInput.Value(),
+		MRIIDs:       mrIIDs,
+		RootMerge:    boolPtr(m.rootMergeSelection),
+		EnvMergeMode: envMergeMode,
+		Rollout:      m.rollout(),
 	}
+	env := *m.selectedEnv
 
+	// Let the hook script adjust the plan; its MRs must be among the loaded ones
+	hooks, err := loadScriptHooks(NewForge(*m.creds), m.selectedProject.ID, nil)
+	if err == nil {
+		plan, err = hooks.applyPlan(plan)
+	}
+	if err == nil && !slices.Equal(plan.MRIIDs, mrIIDs) {
+		mrs, err = m.loadedMRs(plan.MRIIDs)
+	}
+	if err == nil && !strings.EqualFold(plan.Environment, env.Name) {
+		var ok bool
+		if env, ok = findEnvironment(plan.Environment); !ok {
+			err = fmt.Errorf("plan hook: unknown environment %q", plan.Environment)
+		}
+	}
+	if err == nil && (plan.Version == "" || !validateVersion(plan.Version)) {
+		err = fmt.Errorf("plan hook: invalid version %q", plan.Version)
+	}
+	if err == nil {
+		err = plan.Rollout.validate()
+	}
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot start release: " + err.Error()
+		return m, nil
+	}
+
+	state := newReleaseState(plan, env, mrs, sourceBranchIsRemote, m.selectedProject.ID, workDir)
+	state.PipelineInputs = m.pipelineInputValues
+
+	return m, m.beginRelease(state)
+}
+
+// beginRelease switches to the release screen and starts executing a freshly built release state,
+// its output starting with intro. A release taking the lock of its environment takes it first (see
+// takeReleaseLock), and does not start while the environment is locked by someone else.
+func (m *model) beginRelease(state *ReleaseState, intro ...string) tea.Cmd {
+	if m.needsReleaseLock(state) {
+		if m.releaseLockPending {
+			return nil
+		}
+		return m.takeReleaseLock(state, false, intro)
+	}
 	state.TotalSubSteps = calculateReleaseTotalSteps(state)
 	state.CompletedSubSteps = 0
 
 	m.releaseState = state
-	m.screen = screenRelease
-	m.releaseOutputBuffer = []string{}
+	m.moveToScreen(screenRelease)
+	clearReleaseOutputLog(m.tabID)
+	m.setReleaseOutput([]string{})
 	m.releaseCurrentScreen = ""
 
 	// Add recovery metadata to terminal output
-	m.appendRecoveryMetadata(workDir, state)
+	m.appendRecoveryMetadata(state.WorkDir, state)
+
+	// An invalid redact pattern leaves the secrets it is meant for in the output
+	for _, err := range currentRedactor().errs {
+		m.addReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Warning).Render("Warning: " + err.Error()))
+	}
 
 	m.initReleaseScreen()
+	for _, line := range intro {
+		m.appendReleaseOutput(line)
+	}
 
 	// Save initial state (includes recovery metadata in terminal output)
-	SaveReleaseState(state)
+	SaveReleaseState(m.tabID, state)
 
 	// Start execution with spinner
 	m.releaseRunning = true
-	return m, tea.Batch(m.spinner.Tick, m.executeReleaseStep(ReleaseStepGitFetch))
+	releasesStarted.inc(state.Environment.Name)
+	startReleaseTrace(state)
+	return tea.Batch(m.spinner.Tick, m.executeReleaseStep(ReleaseStepGitFetch), m.notifyRelease(releaseEventStarted, ""))
 }
 
 // executeReleaseStep runs the appropriate command for a step
 func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
+	m.releaseStepStartedAt = time.Now()
+	var pluginEvent ReleaseEvent
+	if m.releaseState != nil {
+		startStepSpan(m.releaseState, step)
+		pluginEvent = m.releaseEvent(releaseEventStep, "")
+	}
+	ctx := m.releaseContext()
 	return func() tea.Msg {
 		if m.releaseState == nil {
 			return releaseStepCompleteMsg{step: step, err: fmt.Errorf("no release state")}
@@ -987,8 +1095,8 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 		state := m.releaseState
 		workDir := state.WorkDir
 
-		// Load config for exclude patterns
-		config, _ := LoadConfig()
+		// Load config for exclude patterns, with the project's settings applied
+		config, _ := LoadProjectConfig(state.ProjectID)
 		patterns := strings.Split(config.ExcludePatterns, "\n")
 
 		baseBranch := state.BaseBranch
@@ -997,11 +1105,28 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 		}
 		cmds := NewReleaseCommandsWithSourceBranch(workDir, state.Version, baseBranch, &state.Environment, patterns, state.MRBranches, state.SourceBranch, state.SourceBranchIsRemote)
 
+		// Forge account for the Stitched-by lines of commit message templates
+		stitchedBy := ""
+		if m.creds != nil {
+			stitchedBy = m.creds.Email
+		}
+
 		var command string
 		var output string
 		var err error
 
-		executor := NewGitExecutor(workDir, m.program) // Pass program for real-time output
+		executor := NewGitExecutor(workDir, m.sender()) // Pass the sender for real-time output
+		executor.SetContext(ctx)                        // An abort stops the commands not run yet
+
+		// Release variables for git hooks and the pipelines started by the pushes
+		vars := releaseVariables(state)
+		for _, secret := range variableSecrets(vars) {
+			registerSecret(secret) // Also those of a project's own environments, which the config lacks
+		}
+		executor.SetEnv(releaseEnviron(vars))
+		if m.creds != nil {
+			cmds.SetPushOptions(ciVariablePushOptions(m.creds.Forge, vars))
+		}
 
 		// Set executor size based on viewport dimensions
 		// Calculate width: total width - sidebar - content padding - viewport padding
@@ -1018,12 +1143,29 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 			executor.SetSize(uint16(terminalWidth), uint16(terminalHeight))
 		}
 
+		// Plugin steps run before the step, and before the first MR only for merges
+		if step != ReleaseStepMergeBranches || state.CurrentMRIndex == 0 {
+			pluginOutput, pluginErr := runPluginSteps(step, pluginEvent, workDir, vars, m.sender())
+			if pluginErr != nil {
+				executor.Close()
+				return releaseStepCompleteMsg{step: step, err: pluginErr, output: pluginOutput}
+			}
+		}
+
 		switch step {
 		case ReleaseStepGitFetch:
 			output, err = executor.RunCommand(cmds.StepGitFetch())
+			if err == nil && m.creds != nil {
+				executor.Close()
+				return releaseStepCompleteMsg{step: step, output: output, mergeProtection: detectMergeProtection(*m.creds, state.ProjectID)}
+			}
 
 		case ReleaseStepCheckoutRoot:
-			output, err = executor.RunCommands(cmds.Step1CheckoutRoot())
+			if state.RollbackTag != "" {
+				output, err = executor.RunCommand(cmds.StepCheckoutRollback(state.RollbackTag))
+			} else {
+				output, err = executor.RunCommands(cmds.Step1CheckoutRoot())
+			}
 
 		case ReleaseStepMergeBranches:
 			// Check if we need to continue a merge
@@ -1037,10 +1179,30 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 					// Already merged, move to next
 					return releaseStepCompleteMsg{step: step, err: nil, output: fmt.Sprintf("Branch %s already merged\n", branch)}
 				}
-				command = cmds.Step2MergeBranch(state.CurrentMRIndex)
+				// Only MRs whose pipeline passed are stitched in projects merging through pipelines
+				if state.MergeProtection.mergedResults() && m.creds != nil {
+					waitOutput, waitErr := waitMRPipeline(ctx, *m.creds, state, state.CurrentMRIndex, m.sender())
+					output = waitOutput
+					if waitErr != nil {
+						executor.Close()
+						return releaseStepCompleteMsg{step: step, err: waitErr, output: output}
+					}
+				}
+				if config.MergeCommitTemplate != "" {
+					message, msgErr := mergeCommitMessage(config.MergeCommitTemplate, state, state.CurrentMRIndex, stitchedBy)
+					if msgErr != nil {
+						executor.Close()
+						return releaseStepCompleteMsg{step: step, err: msgErr}
+					}
+					command = cmds.Step2MergeBranchWithMessage(state.CurrentMRIndex, message)
+				} else {
+					command = cmds.Step2MergeBranch(state.CurrentMRIndex)
+				}
 			}
 			if command != "" {
-				output, err = executor.RunCommand(command)
+				var mergeOutput string
+				mergeOutput, err = executor.RunCommand(command)
+				output += mergeOutput
 			}
 
 		case ReleaseStepCheckoutEnv:
@@ -1076,7 +1238,7 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 					err = mergeErr
 				}
 				if err == nil {
-					m.program.Send(releaseSubStepDoneMsg{})
+					m.sender().Send(releaseSubStepDoneMsg{})
 				}
 			} else {
 				// Squash mode (default): existing content copy behavior
@@ -1085,14 +1247,14 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 				if checkoutErr != nil {
 					return releaseStepCompleteMsg{step: step, err: checkoutErr, output: checkoutOutput}
 				}
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				// Step 4.1: Remove all files
 				output1, err1 := executor.RunCommand(cmds.Step4RemoveAll())
 				if err1 != nil {
 					return releaseStepCompleteMsg{step: step, err: err1, output: checkoutOutput + output1}
 				}
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				// Step 4.2: Checkout from root
 				output2, err2 := executor.RunCommand(cmds.Step4CheckoutFromRoot())
@@ -1116,14 +1278,14 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 						}
 					}
 				}
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				output = checkoutOutput + output1 + output2 + output3
 			}
 
 		case ReleaseStepCommit:
 			// Get next v-number and create commit
-			vNumber, verr := GetNextVersionNumber(workDir, state.Environment.BranchName, state.Version)
+			vNumber, verr := releaseVNumber(state)
 			if verr != nil {
 				return releaseStepCompleteMsg{step: step, err: verr, output: ""}
 			}
@@ -1131,7 +1293,14 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 			title, body := BuildCommitMessage(state.Version, state.Environment.BranchName, vNumber, state.MRBranches)
 			// Don't use "git add -A" - files are already staged from checkout
 			var commitCmd string
-			if body != "" {
+			if config.SquashCommitTemplate != "" {
+				templated, msgErr := releaseCommitBody(config.SquashCommitTemplate, state, vNumber, stitchedBy)
+				if msgErr != nil {
+					executor.Close()
+					return releaseStepCompleteMsg{step: step, err: msgErr}
+				}
+				commitCmd = fmt.Sprintf("git commit -m %q -m %s", title, shellQuote(templated))
+			} else if body != "" {
 				// Use $'...' bash syntax to properly interpret \n as newlines in commit body
 				// Escape single quotes and convert actual newlines to \n escape sequences
 				escapedBody := strings.ReplaceAll(body, "'", "'\\''")
@@ -1155,10 +1324,24 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 			// MR will be created via API after this step completes
 
 		case ReleaseStepPushRootBranches:
+			// Nothing is merged or pushed before the wait, so a retry after its timeout waits again
+			if config.DelayTagForSchedules && m.creds != nil {
+				if waitOutput, waitErr := waitForPipelineSchedules(ctx, *m.creds, state.ProjectID, scheduleWindow(config), m.sender()); waitErr != nil {
+					return releaseStepCompleteMsg{step: step, err: waitErr, output: waitOutput}
+				}
+			}
 			tagName := state.TagName
 
 			if state.RootMerge {
 				// RootMerge: push release-root, merge to root, tag merge-commit on root, push root+tags, merge to develop
+				baseViaMR, baseReason, err := branchMergeStrategy(m.creds, state, baseBranch)
+				if err != nil {
+					return releaseStepCompleteMsg{step: step, err: err}
+				}
+				developViaMR, developReason, err := branchMergeStrategy(m.creds, state, "develop")
+				if err != nil {
+					return releaseStepCompleteMsg{step: step, err: err}
+				}
 
 				// Push release root branch
 				pushReleaseRootCmd := fmt.Sprintf("git push -u origin %s", state.SourceBranch)
@@ -1167,41 +1350,67 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 					return releaseStepCompleteMsg{step: step, err: err1, output: output1}
 				}
 				output = output1
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
-				// Merge release root to base branch (creates merge-commit)
-				output2, err2 := executor.RunCommands(cmds.StepMergeToRoot())
+				// Merge release root to base branch (creates merge-commit), through a merge request
+				// when the base branch cannot be pushed to or the project uses a merge train
+				tagCmd := fmt.Sprintf("git tag -f %s", tagName)
+				pushRootCmd := fmt.Sprintf("git push origin %s --tags --force", baseBranch) + cmds.PushOptions()
+				var output2 string
+				var err2 error
+				if baseViaMR && m.creds != nil {
+					var commit string
+					output2, commit, err2 = mergeThroughMR(ctx, *m.creds, state, state.SourceBranch, baseBranch, baseReason,
+						state.MergeProtection.trains(), false, executor, m.sender())
+					tagCmd += " " + commit
+					// The merge request moved the base branch already, only the tag is pushed
+					pushRootCmd = fmt.Sprintf("git push origin refs/tags/%s --force", tagName) + cmds.PushOptions()
+				} else {
+					output2, err2 = executor.RunCommands(cmds.StepMergeToRoot())
+				}
 				if err2 != nil {
 					return releaseStepCompleteMsg{step: step, err: err2, output: output + output2}
 				}
 				output += output2
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
-				// Tag the merge-commit on root (we are on root after StepMergeToRoot)
-				tagCmd := fmt.Sprintf("git tag -f %s", tagName)
+				// Tag the merge-commit on root (we are on root after the merge)
 				outputTag, errTag := executor.RunCommand(tagCmd)
 				if errTag != nil {
 					return releaseStepCompleteMsg{step: step, err: errTag, output: output + outputTag}
 				}
 				output += outputTag
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				// Push base branch with tags
-				pushRootCmd := fmt.Sprintf("git push origin %s --tags --force", baseBranch)
 				output3, err3 := executor.RunCommand(pushRootCmd)
 				if err3 != nil {
 					return releaseStepCompleteMsg{step: step, err: err3, output: output + output3}
 				}
 				output += output3
-				m.program.Send(releaseSubStepDoneMsg{})
-
-				// Merge root to develop and push
-				output4, err4 := executor.RunCommands(cmds.StepMergeToDevelop())
+				m.sender().Send(releaseSubStepDoneMsg{})
+
+				// Merge root to develop and push, or through a merge request of a release develop
+				// branch when develop cannot be pushed to
+				var output4 string
+				var err4 error
+				if developViaMR && m.creds != nil {
+					developBranch := cmds.DevelopReleaseBranch()
+					output4, err4 = executor.RunCommand(fmt.Sprintf("git push origin %s:refs/heads/%s --force", baseBranch, developBranch))
+					if err4 == nil {
+						var mergeOutput string
+						mergeOutput, _, err4 = mergeThroughMR(ctx, *m.creds, state, developBranch, "develop", developReason,
+							state.MergeProtection.trains(), true, executor, m.sender())
+						output4 += mergeOutput
+					}
+				} else {
+					output4, err4 = executor.RunCommands(cmds.StepMergeToDevelop())
+				}
 				if err4 != nil {
 					return releaseStepCompleteMsg{step: step, err: err4, output: output + output4}
 				}
 				output += output4
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 			} else {
 				// No RootMerge: checkout release-root, tag it, push with tags
 
@@ -1212,7 +1421,7 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 					return releaseStepCompleteMsg{step: step, err: errChk, output: outputChk}
 				}
 				output = outputChk
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				// Create tag on release root branch
 				tagCmd := fmt.Sprintf("git tag -f %s", tagName)
@@ -1221,22 +1430,30 @@ func (m *model) executeReleaseStep(step ReleaseStep) tea.Cmd {
 					return releaseStepCompleteMsg{step: step, err: errTag, output: output + outputTag}
 				}
 				output += outputTag
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 
 				// Push release root branch with tags
-				pushCmd := fmt.Sprintf("git push -u origin %s --tags --force", state.SourceBranch)
+				pushCmd := fmt.Sprintf("git push -u origin %s --tags --force", state.SourceBranch) + cmds.PushOptions()
 				outputPush, errPush := executor.RunCommand(pushCmd)
 				if errPush != nil {
 					return releaseStepCompleteMsg{step: step, err: errPush, output: output + outputPush}
 				}
 				output += outputPush
-				m.program.Send(releaseSubStepDoneMsg{})
+				m.sender().Send(releaseSubStepDoneMsg{})
 			}
 
 		case ReleaseStepSwitchToRoot:
 			// Switch back to base branch as final step
 			output, err = executor.RunCommand(fmt.Sprintf("git checkout %s", baseBranch))
 
+		case ReleaseStepDeployPipeline:
+			// Deploy from the deployment project once the release is tagged and pushed
+			if m.creds == nil {
+				err = fmt.Errorf("no credentials")
+			} else {
+				output, err = runDeployPipeline(ctx, *m.creds, state, m.sender())
+			}
+
 		default:
 			return releaseStepCompleteMsg{step: step, err: nil}
 		}
@@ -1254,14 +1471,7 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 	// Save current terminal screen to buffer (for real-time streaming mode)
 	// This preserves the command output before the next command starts
 	if m.releaseCurrentScreen != "" {
-		lines := strings.Split(m.releaseCurrentScreen, "\n")
-		for _, line := range lines {
-			m.releaseOutputBuffer = append(m.releaseOutputBuffer, line)
-		}
-		// Enforce buffer limit
-		if len(m.releaseOutputBuffer) > maxOutputLines {
-			m.releaseOutputBuffer = m.releaseOutputBuffer[len(m.releaseOutputBuffer)-maxOutputLines:]
-		}
+		m.addReleaseOutput(strings.Split(m.releaseCurrentScreen, "\n")...)
 		m.releaseCurrentScreen = ""
 		m.updateReleaseViewport()
 	}
@@ -1276,6 +1486,15 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 	}
 
 	m.releaseRunning = false
+	if !m.releaseStepStartedAt.IsZero() {
+		result := "success"
+		if msg.err != nil {
+			result = "failed"
+		}
+		releaseStepDuration.observe(time.Since(m.releaseStepStartedAt), releaseStepNames[msg.step], result)
+		m.releaseStepStartedAt = time.Time{}
+	}
+	finishStepSpan(msg.err)
 
 	if m.releaseState == nil {
 		return m, nil
@@ -1325,12 +1544,12 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 			fullOutput += "\n" + m.releaseCurrentScreen
 		}
 		state.ErrorOutput = GetLastNLines(fullOutput, 5000)
-		// Save terminal output buffer for resume
-		state.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-		copy(state.TerminalOutput, m.releaseOutputBuffer)
-		SaveReleaseState(state)
-		m.updateReleaseButtons()
-		return m, nil
+		m.saveReleaseProgress()
+		if DetectMergeConflict(state.WorkDir) {
+			mergeConflicts.inc(state.Environment.Name)
+			return m, m.notifyRelease(releaseEventSuspended, "Merge conflict: "+msg.err.Error())
+		}
+		return m, m.notifyRelease(releaseEventFailed, msg.err.Error())
 	}
 
 	// Step succeeded
@@ -1338,25 +1557,18 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 	state.LastError = nil
 	state.ErrorOutput = ""
 
-	// Determine next step
-	var nextStep ReleaseStep
-	var nextCmd tea.Cmd
-
+	// Record the step's results, then move on to the step it leads to
 	switch msg.step {
 	case ReleaseStepGitFetch:
 		state.CompletedSubSteps++
-		nextStep = ReleaseStepCheckoutRoot
-		state.CurrentStep = nextStep
+		state.MergeProtection = msg.mergeProtection
+		if state.MergeProtection != nil {
+			m.appendReleaseOutput(state.MergeProtection.describe())
+		}
 
 	case ReleaseStepCheckoutRoot:
 		state.CompletedSubSteps++
 		state.CurrentMRIndex = 0
-		if len(state.MRBranches) > 0 {
-			nextStep = ReleaseStepMergeBranches
-		} else {
-			nextStep = ReleaseStepCheckoutEnv
-		}
-		state.CurrentStep = nextStep
 
 	case ReleaseStepMergeBranches:
 		state.CompletedSubSteps++
@@ -1366,102 +1578,65 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 			state.CurrentMRIndex++
 		}
 
-		// Check if more branches to merge
-		if state.CurrentMRIndex < len(state.MRBranches) {
-			nextStep = ReleaseStepMergeBranches
-			state.CurrentStep = nextStep
-		} else {
-			nextStep = ReleaseStepCheckoutEnv
-			state.CurrentStep = nextStep
-		}
-
-	case ReleaseStepCheckoutEnv:
-		state.CompletedSubSteps++
-		nextStep = ReleaseStepCopyContent
-		state.CurrentStep = nextStep
-
-	case ReleaseStepCopyContent:
+	case ReleaseStepCopyContent, ReleaseStepPushRootBranches:
 		// substeps already incremented via releaseSubStepDoneMsg
-		if state.EnvMergeMode == "regular" {
-			// Regular merge creates its own commit, skip commit step
-			nextStep = ReleaseStepWaitForMR
-			state.CurrentStep = nextStep
-		} else {
-			nextStep = ReleaseStepCommit
-			state.CurrentStep = nextStep
-		}
 
-	case ReleaseStepCommit:
+	case ReleaseStepCheckoutEnv, ReleaseStepCommit, ReleaseStepSwitchToRoot, ReleaseStepDeployPipeline:
 		state.CompletedSubSteps++
-		// After commit, wait for user to click "Create MR" button
-		// Push will happen when user clicks the button
-		nextStep = ReleaseStepWaitForMR
-		state.CurrentStep = nextStep
 
 	case ReleaseStepPushAndCreateMR:
 		state.CompletedSubSteps++
-		// Now create the MR via GitLab API
-		// After MR is created, handleMRCreated will trigger root merge if enabled
-		state.CurrentStep = ReleaseStepPushAndCreateMR // Keep same step until MR is created
-		// Save terminal output buffer for resume
-		state.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-		copy(state.TerminalOutput, m.releaseOutputBuffer)
-		SaveReleaseState(state)
-		m.updateReleaseButtons()
-
-		// Create MR asynchronously
+		// Branches are pushed: the step completes once the MR is created via the forge API, and
+		// handleMRCreated moves on
+		m.saveReleaseProgress()
 		return m, m.createGitLabMR()
 
-	case ReleaseStepPushRootBranches:
-		// substeps already incremented via releaseSubStepDoneMsg
-		// Root push completed, now switch back to root branch
-		nextStep = ReleaseStepSwitchToRoot
-		state.CurrentStep = nextStep
-
-	case ReleaseStepSwitchToRoot:
-		state.CompletedSubSteps++
-		// Switch to root completed, save to history and mark as complete
-		nextStep = ReleaseStepComplete
-		state.CurrentStep = nextStep
-
 	default:
-		// Save terminal output buffer for resume
-		state.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-		copy(state.TerminalOutput, m.releaseOutputBuffer)
-		SaveReleaseState(state)
-		m.updateReleaseButtons()
+		m.saveReleaseProgress()
 		return m, nil
 	}
 
-	// Save terminal output buffer for resume
-	state.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-	copy(state.TerminalOutput, m.releaseOutputBuffer)
-	SaveReleaseState(state)
-	m.updateReleaseButtons()
+	var nextCmd tea.Cmd
+	nextStep := nextReleaseStep(state, msg.step)
+	if err := state.moveTo(nextStep); err != nil {
+		state.LastError = &ReleaseError{Step: msg.step, Code: "INVALID_TRANSITION", Message: err.Error()}
+		m.appendReleaseOutput("ERROR: " + err.Error())
+		m.saveReleaseProgress()
+		return m, m.notifyRelease(releaseEventFailed, err.Error())
+	}
+	m.saveReleaseProgress()
+
+	// Report the finished step before the events of the next one
+	stepEvent := m.releaseEvent(releaseEventStep, "")
+	stepEvent.Step = releaseStepNames[msg.step]
+	stepCmd := sendReleaseEvent(stepEvent)
 
 	// Continue to next step if not waiting
-	if nextStep != ReleaseStepWaitForMR && nextStep != ReleaseStepWaitForRootPush && nextStep != ReleaseStepComplete {
+	if !releaseStepWaits(nextStep) {
 		m.releaseRunning = true
 		nextCmd = tea.Batch(m.spinner.Tick, m.executeReleaseStep(nextStep))
 	} else if nextStep == ReleaseStepWaitForMR {
 		// Focus on "Create MR" button (index 1: Abort=0, CreateMR=1)
 		m.releaseButtonIndex = 1
+		nextCmd = m.requestRemoteApproval(ReleaseStepWaitForMR, "Create MR")
 	} else if nextStep == ReleaseStepWaitForRootPush {
 		// Focus on "Push root branches" button (index 2: Abort=0, Open=1, PushRoot=2)
 		m.releaseButtonIndex = 2
 	} else if nextStep == ReleaseStepComplete {
 		// Release complete
 		// Save to history immediately so it persists even if user exits with Ctrl+C
-		// (History saved here after SwitchToRoot completes)
+		// (History saved here after SwitchToRoot or the deployment pipeline completes)
 		terminalOutput := append([]string{}, m.releaseOutputBuffer...)
 		if m.releaseCurrentScreen != "" {
 			lines := strings.Split(m.releaseCurrentScreen, "\n")
 			terminalOutput = append(terminalOutput, lines...)
 		}
-		SaveReleaseHistory(state, "completed", terminalOutput)
+		historyID, _ := SaveReleaseHistory(m.tabID, m.selectedProjectPath(), state, "completed", terminalOutput)
+		releasesFinished.inc(state.Environment.Name, "completed")
+		nextCmd = tea.Batch(m.notifyRelease(releaseEventCompleted, ""), m.publishReleaseNotes(), m.writeReleaseEvidence(historyID), m.recordDeployment(), finishReleaseTrace("completed"), unlockRelease(state), m.checkBackMerges(state), syncHistory(historyID))
 
 		// Clear release state so Ctrl+C goes to MRs list
-		ClearReleaseState()
+		ClearReleaseState(m.tabID)
 
 		// Reset selected MRs for next release
 		m.initListScreen()
@@ -1476,7 +1651,7 @@ func (m *model) handleReleaseStepComplete(msg releaseStepCompleteMsg) (tea.Model
 		m.envSelectIndex = 0
 	}
 
-	return m, nextCmd
+	return m, tea.Batch(stepCmd, nextCmd)
 }
 
 // createGitLabMR creates the merge request via GitLab API
@@ -1487,7 +1662,7 @@ func (m *model) createGitLabMR() tea.Cmd {
 		}
 
 		state := m.releaseState
-		client := NewGitLabClient(m.creds.GitLabURL, m.creds.Token)
+		client := NewForge(*m.creds)
 
 		mrBaseBranch := state.BaseBranch
 		if mrBaseBranch == "" {
@@ -1498,14 +1673,34 @@ func (m *model) createGitLabMR() tea.Cmd {
 		targetBranch := state.Environment.BranchName
 
 		// Get version number and build MR title/body
-		vNumber, _ := GetNextVersionNumber(state.WorkDir, state.Environment.BranchName, state.Version)
-		title, body := BuildCommitMessage(state.Version, state.Environment.BranchName, vNumber, state.MRBranches)
+		vNumber, _ := releaseVNumber(state)
+		title, _ := BuildCommitMessage(state.Version, state.Environment.BranchName, vNumber, state.MRBranches)
+		project := ""
+		if m.selectedProject != nil {
+			project = m.selectedProject.PathWithNamespace
+		}
+		body := state.MRDescription
+		if body == "" {
+			var err error
+			body, err = releaseMRDescription(client, state, project, vNumber)
+			if err != nil {
+				return releaseMRCreatedMsg{err: err}
+			}
+		}
 
 		mr, err := client.CreateMergeRequest(state.ProjectID, sourceBranch, targetBranch, title, body)
+		recordAudit(auditMRCreate, fmt.Sprintf("project %d", state.ProjectID), fmt.Sprintf("%s -> %s: %s", sourceBranch, targetBranch, title), err)
 		if err != nil {
 			return releaseMRCreatedMsg{err: err}
 		}
 
+		// The train merges the release MR once its pipeline passes; failing to add it leaves the
+		// MR to be merged by hand, so the release goes on
+		if state.MergeProtection.trains() {
+			trainErr := addToMergeTrain(*m.creds, state.ProjectID, mr.IID)
+			return releaseMRCreatedMsg{url: mr.WebURL, iid: mr.IID, train: trainErr == nil, trainErr: trainErr}
+		}
+
 		return releaseMRCreatedMsg{url: mr.WebURL, iid: mr.IID, err: nil}
 	}
 }
@@ -1521,44 +1716,54 @@ func (m *model) handleMRCreated(msg releaseMRCreatedMsg) (tea.Model, tea.Cmd) {
 			Step:    ReleaseStepPushAndCreateMR,
 			Message: msg.err.Error(),
 		}
-		m.releaseState.CurrentStep = ReleaseStepPushAndCreateMR
 		m.appendReleaseOutput(fmt.Sprintf("ERROR: Failed to create MR: %v", msg.err))
 		// Save state for retry
-		m.releaseState.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-		copy(m.releaseState.TerminalOutput, m.releaseOutputBuffer)
-		SaveReleaseState(m.releaseState)
-		m.updateReleaseButtons()
-		return m, nil
+		m.saveReleaseProgress()
+		return m, m.notifyRelease(releaseEventFailed, "Failed to create MR: "+msg.err.Error())
 	}
 
 	m.releaseState.CreatedMRURL = msg.url
 	m.releaseState.CreatedMRIID = msg.iid
+	stepEvent := m.releaseEvent(releaseEventStep, "")
+	stepEvent.Step = releaseStepNames[ReleaseStepPushAndCreateMR]
+	stepCmd := sendReleaseEvent(stepEvent)
 	m.releaseState.CompletedSubSteps++ // MR created via API = 1 substep
 	m.appendReleaseOutput("")
 	m.appendReleaseOutput(fmt.Sprintf("Merge request created: %s", msg.url))
+	if msg.train {
+		m.appendReleaseOutput("Added to the merge train: it is merged once its pipeline passes")
+	} else if msg.trainErr != nil {
+		m.appendReleaseOutput(fmt.Sprintf("WARNING: not added to the merge train: %v", msg.trainErr))
+	}
 
 	// Calculate and store tag name for display
-	vNumber, _ := GetNextVersionNumber(m.releaseState.WorkDir, m.releaseState.Environment.BranchName, m.releaseState.Version)
-	m.releaseState.TagName = fmt.Sprintf("%s-%s-v%d",
-		strings.ToLower(m.releaseState.Environment.Name),
-		m.releaseState.Version,
-		vNumber,
-	)
+	vNumber, _ := releaseVNumber(m.releaseState)
+	m.releaseState.TagName = m.releaseState.tagName(vNumber)
+	m.releaseState.ReleaseVersion = m.releaseState.formatVersion(vNumber)
+	if m.releaseState.Environment.ReleaseCandidates {
+		m.releaseState.Candidate = vNumber
+	}
 
 	// Go to wait for root push step (user must click "Push root branches")
-	m.releaseState.CurrentStep = ReleaseStepWaitForRootPush
-
-	// Save state
-	m.releaseState.TerminalOutput = make([]string, len(m.releaseOutputBuffer))
-	copy(m.releaseState.TerminalOutput, m.releaseOutputBuffer)
-	SaveReleaseState(m.releaseState)
-	m.updateReleaseButtons()
+	if err := m.releaseState.moveTo(nextReleaseStep(m.releaseState, ReleaseStepPushAndCreateMR)); err != nil {
+		m.releaseState.LastError = &ReleaseError{Step: ReleaseStepPushAndCreateMR, Code: "INVALID_TRANSITION", Message: err.Error()}
+		m.appendReleaseOutput("ERROR: " + err.Error())
+		m.saveReleaseProgress()
+		return m, tea.Batch(stepCmd, m.notifyRelease(releaseEventFailed, err.Error()))
+	}
+	m.saveReleaseProgress()
 
 	// Focus on "Push root branches" button (index 2: Abort=0, Open=1, PushRoot=2)
 	m.releaseButtonIndex = 2
 
+	// Headless runs push root branches right away, nothing to observe or open
+	if m.headless {
+		return m, stepCmd
+	}
+
 	// Start pipeline observer and open MR URL in Safari
-	return m, tea.Batch(m.startPipelineObserver(), openInSafariWithFallback(msg.url))
+	return m, tea.Batch(stepCmd, m.startPipelineObserver(), openInSafariWithFallback(msg.url),
+		m.requestRemoteApproval(ReleaseStepWaitForRootPush, "Push root branches"))
 }
 
 // retryRelease retries from the last failed step
@@ -1567,34 +1772,66 @@ func (m model) retryRelease() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Clear error
+	// The failed step is run again: it is the current step, or one the flow may go back to
 	step := m.releaseState.LastError.Step
+	if err := m.releaseState.moveTo(step); err != nil {
+		m.appendReleaseOutput("ERROR: cannot retry: " + err.Error())
+		return m, nil
+	}
 	m.releaseState.LastError = nil
 	m.releaseState.ErrorOutput = ""
-	m.releaseState.CurrentStep = step
 
-	SaveReleaseState(m.releaseState)
+	SaveReleaseState(m.tabID, m.releaseState)
 	m.updateReleaseButtons()
 
 	m.releaseRunning = true
 	return m, tea.Batch(m.spinner.Tick, m.executeReleaseStep(step))
 }
 
+// errReleaseAborted ends the waits of a step still running when its release is aborted, and fails
+// its git commands not run yet
+var errReleaseAborted = errors.New("the release was aborted")
+
+// releaseContext returns the context of the release's steps, cancelled when the release is aborted
+func (m *model) releaseContext() context.Context {
+	if m.releaseCtx == nil {
+		m.releaseCtx, m.releaseCancel = context.WithCancelCause(context.Background())
+	}
+	return m.releaseCtx
+}
+
+// cancelReleaseSteps stops the step of the release still running, before the abort cleans up
+func (m *model) cancelReleaseSteps() {
+	if m.releaseCancel != nil {
+		m.releaseCancel(errReleaseAborted)
+		m.releaseCtx, m.releaseCancel = nil, nil
+	}
+}
+
 // abortRelease cleans up and aborts the release
 func (m model) abortRelease() (tea.Model, tea.Cmd) {
+	m.cancelReleaseSteps()
+
 	// Stop pipeline observer
 	m.stopPipelineObserver()
 	m.pipelineStatus = nil
 
 	// Save to history before cleanup
+	historyID := ""
 	if m.releaseState != nil {
 		terminalOutput := append([]string{}, m.releaseOutputBuffer...)
 		if m.releaseCurrentScreen != "" {
 			lines := strings.Split(m.releaseCurrentScreen, "\n")
 			terminalOutput = append(terminalOutput, lines...)
 		}
-		SaveReleaseHistory(m.releaseState, "aborted", terminalOutput)
+		historyID, _ = SaveReleaseHistory(m.tabID, m.selectedProjectPath(), m.releaseState, "aborted", terminalOutput)
 	}
+	notifyCmd := m.notifyRelease(releaseEventAborted, "")
+	if m.releaseState != nil {
+		releasesFinished.inc(m.releaseState.Environment.Name, "aborted")
+		notifyCmd = tea.Batch(notifyCmd, finishReleaseTrace("aborted"), unlockRelease(m.releaseState), syncHistory(historyID))
+	}
+	m.cancelRemoteApproval()
 
 	if m.releaseState != nil {
 		workDir := m.releaseState.WorkDir
@@ -1623,41 +1860,53 @@ func (m model) abortRelease() (tea.Model, tea.Cmd) {
 	}
 
 	// Clear state
-	ClearReleaseState()
+	ClearReleaseState(m.tabID)
 	m.releaseState = nil
-	m.releaseOutputBuffer = nil
+	m.setReleaseOutput(nil)
 	m.releaseCurrentScreen = ""
 	m.releaseRunning = false
 
-	// Reset selections for next release
-	m.selectedMRs = make(map[int]bool)
+	// Reset selections for next release; the MR list restores the saved draft
+	clear(m.selectedMRs)
+	m.selectionDraftLoaded = false
+	m.releaseVotesLoaded = false
 	m.selectedEnv = nil
 	m.envSelectIndex = 0
 	m.versionInput.SetValue("")
 	m.versionError = ""
 	m.mrsLoaded = false
 
-	// Go back to home screen
-	m.screen = screenHome
+	// Go back to home screen, asking what went wrong
+	m.moveToScreen(screenHome)
+	m.askPostMortem(historyID, "", "")
 
-	return m, nil
+	return m, notifyCmd
 }
 
 // abortReleaseWithRemoteDeletion cleans up and aborts the release, optionally deleting remote branch
 func (m model) abortReleaseWithRemoteDeletion(deleteRemote bool) (tea.Model, tea.Cmd) {
+	m.cancelReleaseSteps()
+
 	// Stop pipeline observer
 	m.stopPipelineObserver()
 	m.pipelineStatus = nil
 
 	// Save to history before cleanup
+	historyID := ""
 	if m.releaseState != nil {
 		terminalOutput := append([]string{}, m.releaseOutputBuffer...)
 		if m.releaseCurrentScreen != "" {
 			lines := strings.Split(m.releaseCurrentScreen, "\n")
 			terminalOutput = append(terminalOutput, lines...)
 		}
-		SaveReleaseHistory(m.releaseState, "aborted", terminalOutput)
+		historyID, _ = SaveReleaseHistory(m.tabID, m.selectedProjectPath(), m.releaseState, "aborted", terminalOutput)
+	}
+	notifyCmd := m.notifyRelease(releaseEventAborted, "")
+	if m.releaseState != nil {
+		releasesFinished.inc(m.releaseState.Environment.Name, "aborted")
+		notifyCmd = tea.Batch(notifyCmd, finishReleaseTrace("aborted"), unlockRelease(m.releaseState), syncHistory(historyID))
 	}
+	m.cancelRemoteApproval()
 
 	if m.releaseState != nil {
 		workDir := m.releaseState.WorkDir
@@ -1688,6 +1937,9 @@ func (m model) abortReleaseWithRemoteDeletion(deleteRemote bool) (tea.Model, tea
 				exec.RunCommand(fmt.Sprintf("git push origin --delete %s", cmds.ReleaseRootBranch()))
 			}
 
+			// Delete the release develop branch, pushed if develop is merged through a merge request
+			exec.RunCommand(fmt.Sprintf("git push origin --delete %s", cmds.DevelopReleaseBranch()))
+
 			exec.Close()
 		}
 
@@ -1702,24 +1954,27 @@ func (m model) abortReleaseWithRemoteDeletion(deleteRemote bool) (tea.Model, tea
 	}
 
 	// Clear state
-	ClearReleaseState()
+	ClearReleaseState(m.tabID)
 	m.releaseState = nil
-	m.releaseOutputBuffer = nil
+	m.setReleaseOutput(nil)
 	m.releaseCurrentScreen = ""
 	m.releaseRunning = false
 
-	// Reset selections for next release
-	m.selectedMRs = make(map[int]bool)
+	// Reset selections for next release; the MR list restores the saved draft
+	clear(m.selectedMRs)
+	m.selectionDraftLoaded = false
+	m.releaseVotesLoaded = false
 	m.selectedEnv = nil
 	m.envSelectIndex = 0
 	m.versionInput.SetValue("")
 	m.versionError = ""
 	m.mrsLoaded = false
 
-	// Go back to home screen
-	m.screen = screenHome
+	// Go back to home screen, asking what went wrong
+	m.moveToScreen(screenHome)
+	m.askPostMortem(historyID, "", "")
 
-	return m, nil
+	return m, notifyCmd
 }
 
 // startCreateMR initiates the push and MR creation
@@ -1728,9 +1983,14 @@ func (m model) startCreateMR() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	m.releaseState.CurrentStep = ReleaseStepPushAndCreateMR
+	// Stale presses and remote approvals of a release that went on are ignored
+	if err := m.releaseState.moveTo(ReleaseStepPushAndCreateMR); err != nil {
+		return m, nil
+	}
+	m.recordApproval("Create MR")
+	m.cancelRemoteApproval()
 	m.updateReleaseButtons()
-	SaveReleaseState(m.releaseState)
+	SaveReleaseState(m.tabID, m.releaseState)
 
 	m.releaseRunning = true
 	return m, tea.Batch(m.spinner.Tick, m.executeReleaseStep(ReleaseStepPushAndCreateMR))
@@ -1788,13 +2048,19 @@ func (m model) startPushRootBranches() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Stale presses and remote approvals of a release that went on are ignored
+	if err := m.releaseState.moveTo(ReleaseStepPushRootBranches); err != nil {
+		return m, nil
+	}
+	m.recordApproval("Push root branches")
+
 	// Stop pipeline observer
 	m.stopPipelineObserver()
 	m.pipelineStatus = nil
 
-	m.releaseState.CurrentStep = ReleaseStepPushRootBranches
+	m.cancelRemoteApproval()
 	m.updateReleaseButtons()
-	SaveReleaseState(m.releaseState)
+	SaveReleaseState(m.tabID, m.releaseState)
 
 	m.releaseRunning = true
 	return m, tea.Batch(m.spinner.Tick, m.executeReleaseStep(ReleaseStepPushRootBranches))
@@ -1809,9 +2075,14 @@ func (m model) completeRelease() (tea.Model, tea.Cmd) {
 
 	// No need to checkout root here - it's already done as part of ReleaseStepSwitchToRoot
 
-	ClearReleaseState()
+	// The released MRs leave the draft; a rollback released none of them
+	if m.releaseState == nil || m.releaseState.RollbackOf == "" {
+		m.clearSelectionDraft()
+	}
+
+	ClearReleaseState(m.tabID)
 	m.releaseState = nil
-	m.releaseOutputBuffer = nil
+	m.setReleaseOutput(nil)
 	m.releaseCurrentScreen = ""
 	m.releaseRunning = false
 	m.releaseNeedEmptyLineAfterCommand = false
@@ -1849,7 +2120,7 @@ func (m model) completeRelease() (tea.Model, tea.Cmd) {
 	m.rootMergeSelection = true
 
 	// Go back to home screen
-	m.screen = screenHome
+	m.moveToScreen(screenHome)
 
 	return m, nil
 }
@@ -1860,30 +2131,26 @@ func (m *model) resumeRelease(state *ReleaseState) tea.Cmd {
 	state.TotalSubSteps = calculateReleaseTotalSteps(state)
 
 	m.releaseState = state
-	m.screen = screenRelease
+	m.moveToScreen(screenRelease)
 	m.releaseCurrentScreen = ""
 
-	// Restore saved terminal output
+	// Restore saved terminal output (lines spilled to disk before the crash stay there)
 	if len(state.TerminalOutput) > 0 {
-		m.releaseOutputBuffer = make([]string, len(state.TerminalOutput))
-		copy(m.releaseOutputBuffer, state.TerminalOutput)
+		m.setReleaseOutput(append([]string{}, state.TerminalOutput...))
 	} else if state.ErrorOutput != "" {
 		// Fallback: if no terminal output saved but there's error output, show it
-		m.releaseOutputBuffer = []string{"Resuming previous release...", ""}
+		m.setReleaseOutput([]string{"Resuming previous release...", ""})
 		lines := strings.Split(state.ErrorOutput, "\n")
-		m.releaseOutputBuffer = append(m.releaseOutputBuffer, lines...)
+		m.addReleaseOutput(lines...)
 	} else {
-		m.releaseOutputBuffer = []string{}
+		m.setReleaseOutput([]string{})
 	}
 
 	m.initReleaseScreen()
 
 	// If step is in progress (not waiting for user action or complete),
 	// mark as interrupted so user must press Retry to continue
-	if state.LastError == nil &&
-		state.CurrentStep != ReleaseStepWaitForMR &&
-		state.CurrentStep != ReleaseStepWaitForRootPush &&
-		state.CurrentStep != ReleaseStepComplete {
+	if state.LastError == nil && !releaseStepWaits(state.CurrentStep) {
 		state.LastError = &ReleaseError{
 			Step: state.CurrentStep,
 			Code: "RELEASE_INTERRUPTED",
@@ -1921,10 +2188,10 @@ func (m *model) resumeRelease(state *ReleaseState) tea.Cmd {
 	return nil
 }
 
-// checkExistingRelease checks if there's an in-progress release on startup
-func checkExistingRelease() tea.Cmd {
+// checkExistingRelease checks if there's an in-progress release in a tab on startup
+func checkExistingRelease(tab int) tea.Cmd {
 	return func() tea.Msg {
-		state, err := LoadReleaseState()
+		state, err := LoadReleaseState(tab)
 		if err != nil || state == nil {
 			return nil
 		}
@@ -1939,13 +2206,16 @@ func delayedStartRelease() tea.Cmd {
 	})
 }
 
-// startPipelineObserver starts the pipeline observer and returns the first tick command
+// startPipelineObserver starts the pipeline observer and returns the first tick command.
+// Starting it again (e.g. opening the MR twice) replaces the running loop instead of adding a second one.
 func (m *model) startPipelineObserver() tea.Cmd {
 	m.pipelineObserving = true
 	m.pipelineFailNotified = false
 	m.pipelineStatus = &PipelineStatus{
 		Stage: PipelineStageLoading,
 	}
+	m.pipelinePollGen++
+	m.pipelineCheckInFlight = true
 	// Return first check immediately, with spinner tick to keep animation running
 	return tea.Batch(m.spinner.Tick, m.checkPipelineStatus())
 }
@@ -1953,130 +2223,142 @@ func (m *model) startPipelineObserver() tea.Cmd {
 // stopPipelineObserver stops the pipeline observer
 func (m *model) stopPipelineObserver() {
 	m.pipelineObserving = false
+	m.pipelinePollGen++
+	m.pipelineCheckInFlight = false
 }
 
-// pipelineTick returns a command that triggers a pipeline check after 7 seconds
+// pipelineTick returns a command that triggers a pipeline check after pipelinePollInterval
 func (m *model) pipelineTick() tea.Cmd {
-	return tea.Tick(7*time.Second, func(t time.Time) tea.Msg {
-		return pipelineTickMsg{}
+	gen := m.pipelinePollGen
+	return tea.Tick(pipelinePollInterval, func(t time.Time) tea.Msg {
+		return pipelineTickMsg{gen: gen}
 	})
 }
 
-// checkPipelineStatus fetches MR and pipeline status from GitLab API
+// checkPipelineStatus fetches MR and pipeline status from GitLab API.
+// The release state is read before the command runs, so the check never touches the model off the UI thread.
 func (m *model) checkPipelineStatus() tea.Cmd {
-	return func() tea.Msg {
-		if m.releaseState == nil || m.creds == nil {
-			return pipelineStatusMsg{err: fmt.Errorf("invalid state")}
+	gen := m.pipelinePollGen
+	if m.releaseState == nil || m.creds == nil {
+		return func() tea.Msg {
+			return pipelineStatusMsg{gen: gen, err: fmt.Errorf("invalid state")}
 		}
+	}
+	creds := *m.creds
+	projectID := m.releaseState.ProjectID
+	mrIID := m.releaseState.CreatedMRIID
 
-		client := NewGitLabClient(m.creds.GitLabURL, m.creds.Token)
-		status := &PipelineStatus{}
-
-		// Step 1: Fetch MR status
-		mr, err := client.GetMergeRequestStatus(m.releaseState.ProjectID, m.releaseState.CreatedMRIID)
-		if err != nil {
-			status.Error = err
-			return pipelineStatusMsg{status: status, err: err}
-		}
+	return func() tea.Msg {
+		msg := checkPipelines(creds, []pipelineTarget{{projectID: projectID, mrIID: mrIID}})[0]
+		msg.gen = gen
+		return msg
+	}
+}
 
-		// Check if MR is merged
-		if mr.State != "merged" {
-			status.Stage = PipelineStageWaitingForMerge
-			status.MRMerged = false
-			return pipelineStatusMsg{status: status}
-		}
+// checkPipeline fetches the status of an MR and its pipeline.
+// Jobs not matching pipelineRegex (when set) are not counted.
+func checkPipeline(client Forge, target pipelineTarget, pipelineRegex *regexp.Regexp) pipelineStatusMsg {
+	projectID, mrIID := target.projectID, target.mrIID
+	status := &PipelineStatus{}
 
-		status.MRMerged = true
+	// Step 1: Fetch MR status
+	mr, err := client.GetMergeRequestStatus(projectID, mrIID)
+	if err != nil {
+		status.Error = err
+		return pipelineStatusMsg{status: status, err: err}
+	}
 
-		// Step 2: Fetch pipelines for the merge commit
-		// After MR is merged, pipelines run on target branch, not associated with MR directly
-		var pipelines []Pipeline
-		if mr.MergeCommitSHA != "" {
-			pipelines, err = client.GetPipelinesByCommit(m.releaseState.ProjectID, mr.MergeCommitSHA)
-			if err != nil {
-				status.Error = err
-				return pipelineStatusMsg{status: status, err: err}
-			}
-		}
+	// Check if MR is merged
+	if mr.State != "merged" {
+		status.Stage = PipelineStageWaitingForMerge
+		status.MRMerged = false
+		return pipelineStatusMsg{status: status}
+	}
 
-		// Fallback: try MR pipelines API if no pipelines found by commit
-		if len(pipelines) == 0 {
-			pipelines, err = client.GetMergeRequestPipelines(m.releaseState.ProjectID, m.releaseState.CreatedMRIID)
-			if err != nil {
-				status.Error = err
-				return pipelineStatusMsg{status: status, err: err}
-			}
-		}
+	status.MRMerged = true
 
-		// No pipelines yet
-		if len(pipelines) == 0 {
-			status.Stage = PipelineStageWaitingForStart
-			return pipelineStatusMsg{status: status}
+	// Step 2: Fetch pipelines for the merge commit
+	// After MR is merged, pipelines run on target branch, not associated with MR directly
+	var pipelines []Pipeline
+	if mr.MergeCommitSHA != "" {
+		pipelines, err = client.GetPipelinesByCommit(projectID, mr.MergeCommitSHA)
+		if err != nil {
+			status.Error = err
+			return pipelineStatusMsg{status: status, err: err}
 		}
+	}
 
-		// Get the latest pipeline (first in the list)
-		latestPipeline := pipelines[0]
-		status.PipelineID = latestPipeline.ID
-		status.PipelineWebURL = latestPipeline.WebURL
-		status.PipelineState = latestPipeline.Status
-
-		// Step 3: Fetch pipeline jobs to track specific Package/Deploy jobs
-		jobs, err := client.GetPipelineJobs(m.releaseState.ProjectID, latestPipeline.ID)
+	// Fallback: try MR pipelines API if no pipelines found by commit
+	if len(pipelines) == 0 {
+		pipelines, err = client.GetMergeRequestPipelines(projectID, mrIID)
 		if err != nil {
 			status.Error = err
 			return pipelineStatusMsg{status: status, err: err}
 		}
+	}
 
-		// Load pipeline jobs regex from config to filter observable jobs
-		var pipelineRegex *regexp.Regexp
-		if cfg, err := LoadConfig(); err == nil && cfg.PipelineJobsRegex != "" {
-			pipelineRegex, _ = regexp.Compile(cfg.PipelineJobsRegex)
-		}
+	// No pipelines yet
+	if len(pipelines) == 0 {
+		status.Stage = PipelineStageWaitingForStart
+		return pipelineStatusMsg{status: status}
+	}
 
-		// Count jobs by status (filtered by regex when set, otherwise all jobs)
-		for _, job := range jobs {
-			if pipelineRegex != nil && !pipelineRegex.MatchString(job.Name) {
-				continue
-			}
+	// Get the latest pipeline (first in the list)
+	latestPipeline := pipelines[0]
+	status.PipelineID = latestPipeline.ID
+	status.PipelineWebURL = latestPipeline.WebURL
+	status.PipelineState = latestPipeline.Status
 
-			status.TotalJobs++
-
-			switch job.Status {
-			case "success":
-				status.CompletedJobs++
-			case "failed", "canceled":
-				status.FailedJobs++
-			case "pending", "running", "preparing", "waiting_for_resource":
-				status.RunningJobs++
-			case "created", "manual":
-				// "created" = job exists but not yet scheduled; "manual" = awaiting manual trigger
-				// Neither means the job is actively running
-			case "skipped":
-				// Skipped jobs don't count towards failure, but keep them in total
-				// to maintain consistent denominator (e.g., "1/2 failed" not "1/1")
-			}
+	// Step 3: Fetch pipeline jobs to track specific Package/Deploy jobs
+	jobs, err := client.GetPipelineJobs(projectID, latestPipeline.ID)
+	if err != nil {
+		status.Error = err
+		return pipelineStatusMsg{status: status, err: err}
+	}
+
+	// Count jobs by status (filtered by regex when set, otherwise all jobs)
+	for _, job := range jobs {
+		if pipelineRegex != nil && !pipelineRegex.MatchString(job.Name) {
+			continue
 		}
 
-		// Determine stage based on job statuses
-		if status.TotalJobs == 0 {
-			// No relevant jobs found yet - waiting for pipeline to start properly
-			status.Stage = PipelineStageWaitingForStart
-		} else if status.CompletedJobs == 0 && status.FailedJobs == 0 && status.RunningJobs == 0 {
-			// All relevant jobs are manual (not triggered yet) - waiting for start
-			status.Stage = PipelineStageWaitingForStart
-		} else if status.FailedJobs > 0 {
-			// Any failed job means pipeline failed
-			status.Stage = PipelineStageFailed
-		} else if status.CompletedJobs == status.TotalJobs {
-			// All jobs completed successfully
-			status.Stage = PipelineStageCompleted
-		} else {
-			// Jobs still running
-			status.Stage = PipelineStageRunning
+		status.TotalJobs++
+
+		switch job.Status {
+		case "success":
+			status.CompletedJobs++
+		case "failed", "canceled":
+			status.FailedJobs++
+		case "pending", "running", "preparing", "waiting_for_resource":
+			status.RunningJobs++
+		case "created", "manual":
+			// "created" = job exists but not yet scheduled; "manual" = awaiting manual trigger
+			// Neither means the job is actively running
+		case "skipped":
+			// Skipped jobs don't count towards failure, but keep them in total
+			// to maintain consistent denominator (e.g., "1/2 failed" not "1/1")
 		}
+	}
 
-		return pipelineStatusMsg{status: status}
+	// Determine stage based on job statuses
+	if status.TotalJobs == 0 {
+		// No relevant jobs found yet - waiting for pipeline to start properly
+		status.Stage = PipelineStageWaitingForStart
+	} else if status.CompletedJobs == 0 && status.FailedJobs == 0 && status.RunningJobs == 0 {
+		// All relevant jobs are manual (not triggered yet) - waiting for start
+		status.Stage = PipelineStageWaitingForStart
+	} else if status.FailedJobs > 0 {
+		// Any failed job means pipeline failed
+		status.Stage = PipelineStageFailed
+	} else if status.CompletedJobs == status.TotalJobs {
+		// All jobs completed successfully
+		status.Stage = PipelineStageCompleted
+	} else {
+		// Jobs still running
+		status.Stage = PipelineStageRunning
 	}
+
+	return pipelineStatusMsg{status: status}
 }
 
 // sendPipelineNotification sends a macOS notification for pipeline completion
@@ -2109,9 +2391,10 @@ func (m *model) sendPipelineNotification(success bool) {
 
 // handlePipelineStatus processes the pipeline status update
 func (m *model) handlePipelineStatus(msg pipelineStatusMsg) (tea.Model, tea.Cmd) {
-	if !m.pipelineObserving {
+	if !m.pipelineObserving || msg.gen != m.pipelinePollGen {
 		return m, nil
 	}
+	m.pipelineCheckInFlight = false
 
 	// Update status even on error (to show check failed)
 	if msg.status != nil {
@@ -2186,6 +2469,11 @@ func (m *model) renderPipelineStatus() string {
 		line = m.spinner.View() + " " + failedStyle.Render(failText) + " " + loadingStyle.Render("(observing)")
 	}
 
+	// Badge of the pipeline state: an image where the terminal draws them (see graphics.go)
+	if status.Stage != PipelineStageLoading {
+		line = pipelineBadge(status.Stage) + " " + line
+	}
+
 	// Add error indicator if there was a check failure
 	if status.Error != nil && status.Stage != PipelineStageCompleted && status.Stage != PipelineStageFailed {
 		line += " " + loadingStyle.Render("(check failed)")
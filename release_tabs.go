@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Release tabs let several releases be prepared and watched at once, e.g. stage for one project
+// while prod for another waits on its pipeline. The active tab's releaseSession is embedded in the
+// model and the other tabs are parked in parkedTabs. Messages produced for a tab (command results,
+// output of its git commands) come back wrapped in a tabMsg; one for a parked tab is handled with
+// that tab swapped in, so a release keeps running while another tab is shown.
+
+const (
+	firstTabID = 1
+	maxTabs    = 9 // Switched to with Alt+1 … Alt+9
+)
+
+// tabMsg is a message for the release tab whose command produced it
+type tabMsg struct {
+	tab int
+	msg tea.Msg
+}
+
+// messageSender sends messages to the program from goroutines
+type messageSender interface {
+	Send(msg tea.Msg)
+}
+
+// tabSender sends messages to the program on behalf of a release tab
+type tabSender struct {
+	program *tea.Program
+	tab     int
+}
+
+// Send wraps msg for the tab
+func (s tabSender) Send(msg tea.Msg) {
+	s.program.Send(tabMsg{tab: s.tab, msg: msg})
+}
+
+// sender returns the sender for goroutines of the active tab, or nil without a program
+func (m model) sender() messageSender {
+	if m.program == nil {
+		return nil
+	}
+	return tabSender{program: m.program, tab: m.tabID}
+}
+
+// tabFileName returns the name of a tab's release file: the plain name for the first tab,
+// e.g. release-2.json for the second
+func tabFileName(name string, tab int) string {
+	if tab <= firstTabID {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), tab, ext)
+}
+
+// tabReleaseStates reads the release states of tabs other than the first, left by a crash
+func tabReleaseStates() map[int]*ReleaseState {
+	dir, err := getConfigDir()
+	if err != nil {
+		return nil
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "release-*.json"))
+	states := make(map[int]*ReleaseState)
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "release-"), ".json")
+		tab, err := strconv.Atoi(name)
+		if err != nil || tab <= firstTabID {
+			continue
+		}
+		if state, err := LoadReleaseState(tab); err == nil && state != nil {
+			states[tab] = state
+		}
+	}
+	return states
+}
+
+// isTabScoped reports whether msg belongs to the release flow of the tab that caused it.
+// Other messages (window size, spinner, polling, projects, history, settings) are app-wide.
+func isTabScoped(msg tea.Msg) bool {
+	switch msg.(type) {
+	case fetchMRsMsg, fetchMoreMRsMsg, fetchMRDetailsMsg, mrHookMsg, releaseVotesMsg, iterationMRsMsg, dependencyUpdatesMsg,
+		versionCheckTickMsg, versionTagCheckMsg, versionLatestMsg, versionHookMsg,
+		sourceBranchCheckMsg, envMergeCommitCountMsg, existingReleaseMsg,
+		releaseOutputMsg, releaseScreenMsg, releaseCommandStartMsg, releaseCommandEndMsg,
+		releaseStepCompleteMsg, releaseSubStepDoneMsg, releaseMRCreatedMsg, releaseNotesMsg, releaseNotifyMsg,
+		pipelineTickMsg, pipelineStatusMsg, remoteApprovalMsg, artifactJobsMsg, artifactDownloadMsg,
+		editTextMsg, releaseImpactMsg, deploymentRecordedMsg, envDeploymentsMsg, planWatchTickMsg, planWatchMsg,
+		backMergeCheckMsg, scheduleCollisionsMsg, rebaseCommitsMsg, rebaseDoneMsg, releaseEvidenceMsg, releaseLockMsg:
+		return true
+	}
+	return false
+}
+
+// wrapTabCmd wraps the tab-scoped messages produced by cmd, including those of batched commands,
+// so they are delivered to the tab
+func wrapTabCmd(tab int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			wrapped := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				wrapped[i] = wrapTabCmd(tab, c)
+			}
+			return wrapped
+		case tabMsg:
+			return msg
+		default:
+			if isTabScoped(msg) {
+				return tabMsg{tab: tab, msg: msg}
+			}
+			return msg
+		}
+	}
+}
+
+// asModel returns the model of an update result (handlers return either model or *model)
+func asModel(next tea.Model) model {
+	if m, ok := next.(*model); ok {
+		return *m
+	}
+	return next.(model)
+}
+
+// Update handles all messages. Messages of a parked tab are handled with that tab swapped in,
+// and the commands returned are tagged with the tab they were issued for.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tabMsg); ok {
+		if msg.tab != m.tabID {
+			i := m.parkedTabIndex(msg.tab)
+			if i < 0 {
+				return m, nil // The tab was closed meanwhile
+			}
+			cmd := m.inParkedTab(i, func(t *model) tea.Cmd {
+				next, cmd := t.Update(msg.msg)
+				*t = asModel(next)
+				return cmd
+			})
+			return m, cmd
+		}
+		return m.Update(msg.msg)
+	}
+
+	next, cmd := m.update(msg)
+	nm := asModel(next)
+	return nm, wrapTabCmd(nm.tabID, cmd)
+}
+
+// parkedTabIndex returns the index of a parked tab in parkedTabs, or -1
+func (m model) parkedTabIndex(tab int) int {
+	return slices.IndexFunc(m.parkedTabs, func(s releaseSession) bool { return s.tabID == tab })
+}
+
+// inParkedTab runs fn with a parked tab swapped in for the active one, and returns its commands
+// tagged with that tab. An error shown by fn names the tab, as another one is on screen.
+func (m *model) inParkedTab(i int, fn func(t *model) tea.Cmd) tea.Cmd {
+	active, screen := m.releaseSession, m.screen
+	errorShown := m.showErrorModal
+	parked := m.parkedTabs[i]
+	m.releaseSession, m.screen = parked, parked.tabScreen
+
+	cmd := fn(m)
+
+	if m.showErrorModal && !errorShown {
+		m.errorModalMsg = fmt.Sprintf("Tab %d (%s): %s", m.tabNumber(parked.tabID), m.releaseSession.tabLabel(), m.errorModalMsg)
+	}
+	parked = m.releaseSession
+	parked.tabScreen = m.screen
+	m.parkedTabs[i] = parked
+	m.releaseSession, m.screen = active, screen
+	return wrapTabCmd(parked.tabID, cmd)
+}
+
+// tabs returns all release tabs in tab bar order; the active one is the live session
+func (m model) tabs() []releaseSession {
+	tabs := append(slices.Clone(m.parkedTabs), m.releaseSession)
+	slices.SortFunc(tabs, func(a, b releaseSession) int { return a.tabID - b.tabID })
+	return tabs
+}
+
+// tabNumber returns the position of a tab in the tab bar, counted from 1
+func (m model) tabNumber(tab int) int {
+	return slices.IndexFunc(m.tabs(), func(s releaseSession) bool { return s.tabID == tab }) + 1
+}
+
+// parkedTabRunning reports whether a release step is running in a parked tab
+func (m model) parkedTabRunning() bool {
+	return slices.ContainsFunc(m.parkedTabs, func(s releaseSession) bool { return s.releaseRunning })
+}
+
+// parkedReleaseInProgress returns the number of a parked tab with an unfinished release, or 0
+func (m model) parkedReleaseInProgress() int {
+	for _, s := range m.parkedTabs {
+		if s.releaseState != nil {
+			return m.tabNumber(s.tabID)
+		}
+	}
+	return 0
+}
+
+// releaseWorkDirInUse returns the number of another tab releasing from workDir, or 0.
+// Releases share nothing but the working copy, so each needs its own (see project_dirs).
+func (m model) releaseWorkDirInUse(workDir string) int {
+	for _, s := range m.parkedTabs {
+		if s.releaseState != nil && s.releaseState.WorkDir == workDir {
+			return m.tabNumber(s.tabID)
+		}
+	}
+	return 0
+}
+
+// tabBarHeight returns the rows taken by the tab bar, shown while more than one tab is open
+func (m model) tabBarHeight() int {
+	if len(m.parkedTabs) == 0 {
+		return 0
+	}
+	return 1
+}
+
+// parkActive moves the active tab to parkedTabs, remembering its screen if it shows the release flow
+func (m *model) parkActive() {
+	session := m.releaseSession
+	if isReleaseFlowScreen(m.screen) {
+		session.tabScreen = m.screen
+	}
+	m.parkedTabs = append(slices.Clone(m.parkedTabs), session)
+	slices.SortFunc(m.parkedTabs, func(a, b releaseSession) int { return a.tabID - b.tabID })
+}
+
+// isReleaseFlowScreen reports whether s belongs to a tab rather than to the whole app
+func isReleaseFlowScreen(s screen) bool {
+	switch s {
+	case screenMain, screenEnvSelect, screenVersion, screenSourceBranch, screenEnvMerge,
+		screenRootMerge, screenRollout, screenConfirm, screenRelease:
+		return true
+	}
+	return false
+}
+
+// switchTab makes a parked tab the active one and shows its screen
+func (m *model) switchTab(tab int) tea.Cmd {
+	i := m.parkedTabIndex(tab)
+	if i < 0 {
+		return nil
+	}
+	next := m.parkedTabs[i]
+	m.parkActive()
+	m.parkedTabs = slices.DeleteFunc(m.parkedTabs, func(s releaseSession) bool { return s.tabID == tab })
+	m.releaseSession = next
+	m.closeAllModals()
+	m.screen = next.tabScreen
+	m.resize(m.width, m.termHeight)
+	m.activateRepoSettings()
+	return m.spinner.Tick
+}
+
+// openTab opens a release tab and lets the user pick its project
+func (m *model) openTab() tea.Cmd {
+	if len(m.parkedTabs)+1 >= maxTabs {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("At most %d release tabs can be open", maxTabs)
+		return nil
+	}
+	m.lastTabID = max(m.lastTabID, m.tabID) + 1
+	m.parkActive()
+	m.releaseSession = newReleaseSession(m.lastTabID)
+	m.activateRepoSettings()
+	m.closeAllModals()
+	m.initListScreen()
+	m.moveToScreen(screenMain)
+	m.resize(m.width, m.termHeight)
+
+	m.showProjectSelector = true
+	m.projectFilter = ""
+	m.filterProjects()
+	if !m.projectsLoaded {
+		return m.loadProjects()
+	}
+	return nil
+}
+
+// closeTab closes the active tab unless its release is unfinished, and switches to a neighbour
+func (m *model) closeTab() tea.Cmd {
+	m.closeAllModals()
+	if len(m.parkedTabs) == 0 {
+		m.showErrorModal = true
+		m.errorModalMsg = "The only release tab cannot be closed"
+		return nil
+	}
+	if m.releaseState != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "The release in this tab is not finished: complete or abort it before closing the tab"
+		return nil
+	}
+
+	// Prefer the tab on the left
+	next := m.parkedTabs[0]
+	for _, s := range m.parkedTabs {
+		if s.tabID < m.tabID {
+			next = s
+		}
+	}
+	m.parkedTabs = slices.DeleteFunc(slices.Clone(m.parkedTabs), func(s releaseSession) bool { return s.tabID == next.tabID })
+	m.releaseSession = next
+	if isReleaseFlowScreen(m.screen) {
+		m.screen = next.tabScreen
+	}
+	m.resize(m.width, m.termHeight)
+	m.activateRepoSettings()
+	return m.spinner.Tick
+}
+
+// closeParkedTabs drops the parked tabs (on logout)
+func (m *model) closeParkedTabs() {
+	m.parkedTabs = nil
+	m.resize(m.width, m.termHeight)
+}
+
+// restoreTabs reopens the tabs whose releases were left unfinished, each resumed in its own tab
+func (m *model) restoreTabs(states map[int]*ReleaseState) tea.Cmd {
+	var cmds []tea.Cmd
+	for tab, state := range states {
+		session := newReleaseSession(tab)
+		session.selectedProject = m.knownProject(state.ProjectID)
+		m.parkedTabs = append(m.parkedTabs, session)
+		m.lastTabID = max(m.lastTabID, tab)
+	}
+	slices.SortFunc(m.parkedTabs, func(a, b releaseSession) int { return a.tabID - b.tabID })
+	for i, s := range m.parkedTabs {
+		state := states[s.tabID]
+		cmds = append(cmds, m.inParkedTab(i, func(t *model) tea.Cmd {
+			t.initListScreen()
+			return t.resumeRelease(state)
+		}))
+	}
+	m.resize(m.width, m.termHeight)
+	return tea.Batch(cmds...)
+}
+
+// knownProject returns the project with the ID from the loaded or pinned projects, or one known
+// only by its ID
+func (m model) knownProject(id int) *Project {
+	for _, list := range [][]Project{m.projects, m.pinnedProjects} {
+		for _, p := range list {
+			if p.ID == id {
+				return &p
+			}
+		}
+	}
+	if m.selectedProject != nil && m.selectedProject.ID == id {
+		p := *m.selectedProject
+		return &p
+	}
+	return &Project{ID: id, Name: fmt.Sprintf("project %d", id)}
+}
+
+// handleTabKey switches, opens and closes tabs: Alt+1 … Alt+9, Alt+N and Alt+W
+func (m *model) handleTabKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "alt+n":
+		return m.openTab(), true
+	case "alt+w":
+		return m.closeTab(), true
+	}
+	if n, ok := strings.CutPrefix(key, "alt+"); ok && len(n) == 1 && n[0] >= '1' && n[0] <= '9' {
+		tabs := m.tabs()
+		i := int(n[0] - '1')
+		if i < len(tabs) && tabs[i].tabID != m.tabID {
+			return m.switchTab(tabs[i].tabID), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// tabLabel names a tab by its project and, once started, the release, e.g. "shop · prod 1.4.0"
+func (s releaseSession) tabLabel() string {
+	label := "new release"
+	if s.selectedProject != nil {
+		label = s.selectedProject.Name
+	}
+	if s.releaseState != nil {
+		label += " · " + s.releaseState.Environment.Name + " " + s.releaseState.Version
+	}
+	return label
+}
+
+// tabMark tells the state of a tab's release: a spinner while a step runs, ✗ after a failure,
+// ● when it waits for the user and ✓ once complete
+func (m model) tabMark(s releaseSession) string {
+	switch {
+	case s.releaseState == nil:
+		return ""
+	case s.releaseRunning:
+		return " " + m.spinner.View()
+	case s.releaseState.LastError != nil:
+		return " ✗"
+	case s.releaseState.CurrentStep == ReleaseStepComplete:
+		return " ✓"
+	default:
+		return " ●"
+	}
+}
+
+// renderTabBar renders the numbered tabs on one line, or "" while only one tab is open
+func (m model) renderTabBar() string {
+	if m.tabBarHeight() == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, s := range m.tabs() {
+		tab := fmt.Sprintf("%d %s%s", i+1, s.tabLabel(), m.tabMark(s))
+		if s.tabID == m.tabID {
+			b.WriteString(releaseTabActiveStyle.Render(tab))
+		} else {
+			b.WriteString(releaseTabStyle.Render(tab))
+		}
+	}
+	return ansi.Truncate(b.String(), m.width, "…")
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// Release timeline: "T" on the history list swaps the list for a row per environment with its
+// releases over time, so cadence drift and lower environments running ahead of higher ones stand
+// out. A release to an environment of a version no environment below got before (e.g. a hotfix
+// straight to prod) is marked as a hotfix. A stretch without releases longer than twice the
+// environment's usual interval is marked as a gap. Under the rows, each environment that released
+// versions the one above has not got yet is reported with how long it has been ahead.
+
+// timelineSpans are the periods the timeline shows, switched with "[" and "]"; 0 shows all history
+var timelineSpans = []time.Duration{30 * 24 * time.Hour, 90 * 24 * time.Hour, 365 * 24 * time.Hour, 0}
+
+// defaultTimelineSpan is the index of the period shown first
+const defaultTimelineSpan = 1
+
+// timelineGapFactor is how many usual intervals without a release make a gap
+const timelineGapFactor = 2
+
+// timelineDivergenceWarn is how long an environment may be ahead of the next one before it is
+// highlighted
+const timelineDivergenceWarn = 14 * 24 * time.Hour
+
+// timelineMark is what a timeline cell shows, in increasing precedence
+type timelineMark int
+
+const (
+	timelineEmpty timelineMark = iota
+	timelineGap
+	timelineAborted
+	timelineRelease
+	timelineHotfix
+	timelineRollback
+)
+
+// timelineRow is the timeline of an environment
+type timelineRow struct {
+	env      string
+	cells    []timelineMark
+	releases int           // Completed releases in the period
+	interval time.Duration // Median interval between completed releases; 0 with fewer than two
+	last     time.Time     // Latest completed release, zero without any
+}
+
+// timelineDivergence is an environment that released versions the next one has not got
+type timelineDivergence struct {
+	lower, higher string
+	versions      int       // Versions released to lower since the latest release to higher
+	since         time.Time // First of them
+}
+
+// timelineSpanLabel names a period of the timeline
+func timelineSpanLabel(span time.Duration) string {
+	switch {
+	case span == 0:
+		return "all history"
+	case span >= 365*24*time.Hour:
+		return "1 year"
+	}
+	return fmt.Sprintf("%d days", int(span.Hours()/24))
+}
+
+// timelineEnvs returns the environments of the timeline: the configured ones in order, then the
+// others found in the history
+func timelineEnvs(entries []HistoryIndexEntry) []string {
+	var names []string
+	for _, env := range getEnvironments() {
+		names = append(names, env.Name)
+	}
+	for _, e := range entries {
+		if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, e.Environment) }) {
+			names = append(names, e.Environment)
+		}
+	}
+	return names
+}
+
+// completedReleases returns the completed releases to an environment, oldest first; rollbacks
+// restore older content and are left out
+func completedReleases(entries []HistoryIndexEntry, env string) []HistoryIndexEntry {
+	var releases []HistoryIndexEntry
+	for _, e := range entries {
+		if strings.EqualFold(e.Environment, env) && e.Status == "completed" && e.RollbackOf == "" {
+			releases = append(releases, e)
+		}
+	}
+	slices.SortFunc(releases, func(a, b HistoryIndexEntry) int { return a.DateTime.Compare(b.DateTime) })
+	return releases
+}
+
+// medianInterval returns the median time between consecutive releases, 0 with fewer than two
+func medianInterval(releases []HistoryIndexEntry) time.Duration {
+	if len(releases) < 2 {
+		return 0
+	}
+	intervals := make([]time.Duration, len(releases)-1)
+	for i := range intervals {
+		intervals[i] = releases[i+1].DateTime.Sub(releases[i].DateTime)
+	}
+	slices.Sort(intervals)
+	return intervals[len(intervals)/2]
+}
+
+// isHotfix reports whether a completed release brought a version none of the lower environments
+// got before it
+func isHotfix(e HistoryIndexEntry, lower []HistoryIndexEntry) bool {
+	for _, l := range lower {
+		if l.Version == e.Version && !l.DateTime.After(e.DateTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildTimeline lays out the releases of each environment in cells columns ending at now. A span
+// of 0 starts at the oldest release.
+func buildTimeline(entries []HistoryIndexEntry, envs []string, now time.Time, span time.Duration, cells int) ([]timelineRow, time.Time) {
+	start := now.Add(-span)
+	if span == 0 {
+		start = now
+		for _, e := range entries {
+			if e.DateTime.Before(start) {
+				start = e.DateTime
+			}
+		}
+	}
+	bucket := max(now.Sub(start)/time.Duration(max(cells, 1)), time.Minute)
+	cell := func(t time.Time) int {
+		return min(int(t.Sub(start)/bucket), cells-1)
+	}
+
+	var rows []timelineRow
+	var lower []HistoryIndexEntry
+	for i, env := range envs {
+		releases := completedReleases(entries, env)
+		row := timelineRow{env: env, cells: make([]timelineMark, cells), interval: medianInterval(releases)}
+		if len(releases) > 0 {
+			row.last = releases[len(releases)-1].DateTime
+		}
+
+		// Gaps between releases, and since the latest one
+		if row.interval > 0 {
+			threshold := max(row.interval*timelineGapFactor, 24*time.Hour)
+			for j, r := range releases {
+				next := now
+				if j+1 < len(releases) {
+					next = releases[j+1].DateTime
+				}
+				if next.Sub(r.DateTime) <= threshold || next.Before(start) {
+					continue
+				}
+				for c := cell(maxTime(r.DateTime, start)); c <= cell(next); c++ {
+					row.cells[c] = timelineGap
+				}
+			}
+		}
+
+		for _, e := range entries {
+			if !strings.EqualFold(e.Environment, env) || e.DateTime.Before(start) {
+				continue
+			}
+			mark := timelineAborted
+			switch {
+			case e.Status != "completed":
+			case e.RollbackOf != "":
+				mark = timelineRollback
+			case i > 0 && isHotfix(e, lower):
+				mark = timelineHotfix
+				row.releases++
+			default:
+				mark = timelineRelease
+				row.releases++
+			}
+			c := cell(e.DateTime)
+			row.cells[c] = max(row.cells[c], mark)
+		}
+		rows = append(rows, row)
+		lower = append(lower, releases...)
+	}
+	return rows, start
+}
+
+// maxTime returns the later of two times
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// timelineDivergences finds the environments that released versions the next one has not got;
+// environments without releases are skipped
+func timelineDivergences(entries []HistoryIndexEntry, envs []string) []timelineDivergence {
+	envs = slices.DeleteFunc(slices.Clone(envs), func(env string) bool { return len(completedReleases(entries, env)) == 0 })
+	var divergences []timelineDivergence
+	for i := 0; i+1 < len(envs); i++ {
+		higher := completedReleases(entries, envs[i+1])
+		latest := higher[len(higher)-1]
+		released := make(map[string]bool)
+		for _, h := range higher {
+			released[h.Version] = true
+		}
+		d := timelineDivergence{lower: envs[i], higher: envs[i+1]}
+		seen := make(map[string]bool)
+		for _, l := range completedReleases(entries, envs[i]) {
+			if !l.DateTime.After(latest.DateTime) || released[l.Version] || seen[l.Version] {
+				continue
+			}
+			seen[l.Version] = true
+			if d.versions == 0 {
+				d.since = l.DateTime
+			}
+			d.versions++
+		}
+		if d.versions > 0 {
+			divergences = append(divergences, d)
+		}
+	}
+	return divergences
+}
+
+// formatInterval renders a duration in days, or hours below a day
+func formatInterval(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", max(int(d.Hours()), 1))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// viewHistoryTimeline renders the release timeline in place of the history list
+func (m model) viewHistoryTimeline(width int) string {
+	if len(m.historyEntries) == 0 {
+		return helpStyle.Render("  No releases yet")
+	}
+	envs := timelineEnvs(m.historyEntries)
+	labelW := 2
+	for _, env := range envs {
+		labelW = max(labelW, lipgloss.Width(env)+2)
+	}
+	cells := max(width-labelW-2, 10)
+	now := time.Now()
+	span := timelineSpans[m.historyTimelineSpan]
+	rows, start := buildTimeline(m.historyEntries, envs, now, span, cells)
+
+	emptyStyle := lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	gapStyle := lipgloss.NewStyle().Foreground(currentTheme.Warning)
+	hotfixStyle := lipgloss.NewStyle().Foreground(currentTheme.Warning).Bold(true)
+	rollbackStyle := lipgloss.NewStyle().Foreground(currentTheme.Error).Bold(true)
+
+	var b strings.Builder
+	for _, row := range rows {
+		envStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getEnvBranchColor(row.env)))
+		b.WriteString("  " + envStyle.Bold(true).Render(padColumn(row.env, labelW)))
+		for _, mark := range row.cells {
+			switch mark {
+			case timelineGap:
+				b.WriteString(gapStyle.Render("┈"))
+			case timelineAborted:
+				b.WriteString(historyStatusAbortedStyle.Render("✗"))
+			case timelineRelease:
+				b.WriteString(envStyle.Render("●"))
+			case timelineHotfix:
+				b.WriteString(hotfixStyle.Render("▲"))
+			case timelineRollback:
+				b.WriteString(rollbackStyle.Render("↺"))
+			default:
+				b.WriteString(emptyStyle.Render("─"))
+			}
+		}
+		b.WriteString("\n")
+
+		summary := fmt.Sprintf("%d %s", row.releases, plural(row.releases, "release", "releases"))
+		if row.interval > 0 {
+			summary += " · usually every " + formatInterval(row.interval)
+		}
+		if !row.last.IsZero() {
+			summary += " · last " + humanize.Time(row.last)
+		}
+		b.WriteString(strings.Repeat(" ", labelW+2) + helpStyle.Render(summary) + "\n")
+	}
+
+	// Dates under the first, middle and last columns
+	axis := []rune(strings.Repeat(" ", cells))
+	for _, tick := range []struct {
+		col int
+		t   time.Time
+	}{{0, start}, {cells / 2, start.Add(now.Sub(start) / 2)}, {cells, now}} {
+		label := []rune(formatDate(tick.t))
+		at := min(max(tick.col-len(label)/2, 0), cells-len(label))
+		if at >= 0 {
+			copy(axis[at:], label)
+		}
+	}
+	b.WriteString(strings.Repeat(" ", labelW+2) + helpStyle.Render(string(axis)) + "\n\n")
+
+	for _, d := range timelineDivergences(m.historyEntries, envs) {
+		line := fmt.Sprintf("%s is %d %s ahead of %s for %s", d.lower, d.versions, plural(d.versions, "version", "versions"), d.higher, formatInterval(now.Sub(d.since)))
+		if now.Sub(d.since) > timelineDivergenceWarn {
+			b.WriteString("  " + gapStyle.Render("⚠ "+line) + "\n")
+		} else {
+			b.WriteString("  " + helpStyle.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n  " + helpStyle.Render(fmt.Sprintf("%s · ● release  ▲ hotfix  ↺ rollback  ✗ aborted  ┈ gap", timelineSpanLabel(span))))
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Release variables tell the tooling around a release that relix drives it: the variables of the
+// environment's config and RELIX_* ones describing the release are set in the environment of the
+// release's git commands (so git hooks such as pre-push see them) and of its shell, and on GitLab
+// passed as CI variables of the pipelines started by the release pushes (git push -o ci.variable).
+
+// releaseVariables returns the variables of a release. The RELIX_* ones cannot be overridden.
+func releaseVariables(state *ReleaseState) map[string]string {
+	vars := maps.Clone(state.Environment.Variables)
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	vars["RELIX_RELEASE"] = "true"
+	vars["RELIX_ENVIRONMENT"] = state.Environment.Name
+	vars["RELIX_ENV_BRANCH"] = state.Environment.BranchName
+	vars["RELIX_VERSION"] = state.Version
+	vars["RELIX_SOURCE_BRANCH"] = state.SourceBranch
+	if state.TagName != "" {
+		vars["RELIX_TAG"] = state.TagName
+	}
+	return vars
+}
+
+// releaseEnviron returns the process environment with the release variables set
+func releaseEnviron(vars map[string]string) []string {
+	env := os.Environ()
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		env = append(env, key+"="+vars[key])
+	}
+	return env
+}
+
+// ciVariablePushOptions returns the git push options passing the variables to the pipeline the push
+// starts, with a leading space, or "" for forges without push options. The options name the
+// variables and the shell takes their values from the environment of the release's git commands
+// (see releaseEnviron), so the values are not in the commands shown, saved and audited. Names that
+// are no shell variables are left out; GitLab does not take them as CI variables either.
+func ciVariablePushOptions(forge string, vars map[string]string) string {
+	if forge != forgeGitLab || len(vars) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		if shellVariableName.MatchString(key) {
+			b.WriteString(fmt.Sprintf(` -o "ci.variable=%s=${%s}"`, key, key))
+		}
+	}
+	return b.String()
+}
+
+// shellVariableName matches the names the shell expands as variables
+var shellVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// shellQuote quotes s as a single sh word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Release votes: developers mark their MRs for the next release on GitLab, with an emoji award
+// (release_vote_emoji, e.g. "rocket") or a comment starting with a magic text
+// (release_vote_comment, e.g. "/next-release"). When the releaser opens the MR list, the voted MRs
+// are selected, unless they are drafts or the include_mr hook keeps them out, and marked as voted.
+// Unchecking a voted MR keeps it out until the list is opened again.
+
+// releaseVoteWorkers bounds the MRs whose votes are read at once
+const releaseVoteWorkers = 4
+
+// releaseVotesMsg carries the voted MRs of a project
+type releaseVotesMsg struct {
+	projectID int
+	iids      []int
+}
+
+// releaseVoteSettings returns the vote emoji and comment of the selected project, empty if votes
+// are not used
+func (m model) releaseVoteSettings() (emoji, comment string) {
+	config, err := LoadProjectConfig(m.selectedProjectID())
+	if err != nil {
+		return "", ""
+	}
+	return strings.Trim(strings.TrimSpace(config.ReleaseVoteEmoji), ":"), strings.TrimSpace(config.ReleaseVoteComment)
+}
+
+// loadReleaseVotes returns the command reading the votes of the listed MRs, once per list when all
+// its pages are fetched (not from a cached list). Votes are read on GitLab only.
+func (m *model) loadReleaseVotes(mrs []*MergeRequestDetails) tea.Cmd {
+	if m.releaseVotesLoaded || m.mrPage.HasMore || m.mrsCached || !m.mrsStaleAt.IsZero() || m.creds == nil || m.selectedProject == nil {
+		return nil
+	}
+	m.releaseVotesLoaded = true
+	emoji, comment := m.releaseVoteSettings()
+	client, ok := NewForge(*m.creds).(*GitLabClient)
+	if !ok || (emoji == "" && comment == "") {
+		return nil
+	}
+	projectID := m.selectedProject.ID
+	var candidates []*MergeRequestDetails
+	for _, mr := range mrs {
+		if !mr.Draft {
+			candidates = append(candidates, mr)
+		}
+	}
+
+	return func() tea.Msg {
+		voted := make([]bool, len(candidates))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, releaseVoteWorkers)
+		for i, mr := range candidates {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				voted[i] = hasReleaseVote(client, projectID, mr.IID, emoji, comment)
+			}()
+		}
+		wg.Wait()
+
+		// Voted MRs the hook script keeps out and blocked MRs are left unselected
+		hooks, err := loadScriptHooks(client, projectID, nil)
+		var iids []int
+		for i, mr := range candidates {
+			if !voted[i] {
+				continue
+			}
+			if _, blocked := blockedMRReason(projectID, mr.IID); blocked {
+				continue
+			}
+			if err == nil {
+				if ok, _, hookErr := hooks.includeMR(mr); hookErr == nil && !ok {
+					continue
+				}
+			}
+			iids = append(iids, mr.IID)
+		}
+		return releaseVotesMsg{projectID: projectID, iids: iids}
+	}
+}
+
+// hasReleaseVote reports whether an MR has the vote emoji or a vote comment. MRs whose awards or
+// comments cannot be read count as not voted.
+func hasReleaseVote(client *GitLabClient, projectID, mrIID int, emoji, comment string) bool {
+	if emoji != "" {
+		if awards, err := client.GetMergeRequestAwards(projectID, mrIID); err == nil {
+			for _, name := range awards {
+				if strings.EqualFold(name, emoji) {
+					return true
+				}
+			}
+		}
+	}
+	if comment != "" {
+		if bodies, err := client.GetMergeRequestComments(projectID, mrIID); err == nil {
+			for _, body := range bodies {
+				body = strings.TrimSpace(body)
+				if len(body) >= len(comment) && strings.EqualFold(body[:len(comment)], comment) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// handleReleaseVotes selects the voted MRs of the listed project
+func (m *model) handleReleaseVotes(msg releaseVotesMsg) {
+	if m.selectedProject == nil || m.selectedProject.ID != msg.projectID {
+		return
+	}
+	listed := make(map[int]bool, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok && !mr.MR().Draft {
+			listed[mr.MR().IID] = true
+		}
+	}
+	for _, iid := range msg.iids {
+		m.releaseVotes[iid] = true
+		if listed[iid] {
+			m.selectedMRs[iid] = true
+		}
+	}
+	m.saveSelection()
+}
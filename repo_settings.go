@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// Project-level release settings: a .restitcher.yaml on the default branch of the selected
+// repository sets the release policy of the project (environment branches, base branch, commit and
+// release MR templates, hook script, MR filters, release votes, version scheme and MR blocklist),
+// so it is versioned with the code and the same for everyone releasing it. It is read when the
+// project is selected, and by "relix release" and "relix serve" before a plan is resolved. Its
+// settings take precedence over the user config, which still provides everything the file leaves
+// out; ignore_repo_settings turns it off.
+
+const repoSettingsFileName = ".restitcher.yaml"
+
+// RepoSettings is the content of .restitcher.yaml
+type RepoSettings struct {
+	Environments          []RepoEnvironment `yaml:"environments"`
+	BaseBranch            string            `yaml:"base_branch"`
+	MergeCommitTemplate   string            `yaml:"merge_commit_template"`
+	SquashCommitTemplate  string            `yaml:"squash_commit_template"`
+	ReleaseMRTemplate     string            `yaml:"release_mr_template"`
+	ReleaseMRTemplateFile string            `yaml:"release_mr_template_file"` // Relative to the project directory
+	HooksScript           string            `yaml:"hooks_script"`             // Relative to the project root
+	MRTargetBranch        string            `yaml:"mr_target_branch"`
+	MRSourceBranchRegex   string            `yaml:"mr_source_branch_regex"`
+	ExcludePatterns       []string          `yaml:"exclude_patterns"`
+	ReleaseVoteEmoji      string            `yaml:"release_vote_emoji"`
+	ReleaseVoteComment    string            `yaml:"release_vote_comment"`
+	VersionScheme         string            `yaml:"version_scheme"`
+	VersionPattern        string            `yaml:"version_pattern"`
+	MRBlocklist           []BlockedMR       `yaml:"mr_blocklist"`
+}
+
+// RepoEnvironment maps an environment to its branch. Other environment settings (version format,
+// rollout, variables, ...) come from the environment of the same name in the user config.
+type RepoEnvironment struct {
+	Name   string `yaml:"name"`
+	Branch string `yaml:"branch"`
+}
+
+// repoSettingsCache holds the settings read for each project by ID; a project without the file
+// has empty settings
+var repoSettingsCache sync.Map
+
+// activeRepoProject is the project whose settings apply to the screens: the project selected in
+// the active tab, or the project of a headless release
+var activeRepoProject atomic.Int64
+
+// repoSettingsMsg carries the settings read for a project
+type repoSettingsMsg struct {
+	projectID int
+	settings  *RepoSettings
+	err       error
+}
+
+// parseRepoSettings parses and validates .restitcher.yaml; unknown keys are errors, as they are
+// most likely typos
+func parseRepoSettings(data []byte) (*RepoSettings, error) {
+	var settings RepoSettings
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", repoSettingsFileName, err)
+	}
+	for i, env := range settings.Environments {
+		if strings.TrimSpace(env.Name) == "" || strings.TrimSpace(env.Branch) == "" {
+			return nil, fmt.Errorf("%s: environment %d needs a name and a branch", repoSettingsFileName, i+1)
+		}
+	}
+	for i, blocked := range settings.MRBlocklist {
+		if blocked.IID <= 0 {
+			return nil, fmt.Errorf("%s: mr_blocklist entry %d needs an iid", repoSettingsFileName, i+1)
+		}
+	}
+	if expr := strings.TrimSpace(settings.MRSourceBranchRegex); expr != "" {
+		if _, err := regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("%s: invalid mr_source_branch_regex: %w", repoSettingsFileName, err)
+		}
+	}
+	if settings.VersionScheme != "" {
+		if _, err := newVersionScheme(settings.VersionScheme, settings.VersionPattern); err != nil {
+			return nil, fmt.Errorf("%s: %w", repoSettingsFileName, err)
+		}
+	}
+	// Files are read and run on the releaser's machine, so they must come from the project
+	for _, file := range []struct{ key, path string }{
+		{"release_mr_template_file", settings.ReleaseMRTemplateFile},
+		{"hooks_script", settings.HooksScript},
+	} {
+		if file.path != "" && (strings.HasPrefix(file.path, "~") || !filepath.IsLocal(file.path)) {
+			return nil, fmt.Errorf("%s: %s must be a relative path inside the project, got %q", repoSettingsFileName, file.key, file.path)
+		}
+	}
+	return &settings, nil
+}
+
+// projectRepoSettings returns the settings in effect for a project, nil if none were read or
+// ignore_repo_settings is set
+func projectRepoSettings(projectID int) *RepoSettings {
+	if config, err := LoadConfig(); err == nil && config.IgnoreRepoSettings {
+		return nil
+	}
+	return cachedRepoSettings(projectID)
+}
+
+// resolveProjectFile resolves a file a project's settings name against the project checkout,
+// following symlinks, and refuses one outside of it
+func resolveProjectFile(root, path string) (string, error) {
+	if strings.HasPrefix(path, "~") || !filepath.IsLocal(path) {
+		return "", fmt.Errorf("%s must be a relative path inside the project", path)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	full, err := filepath.EvalSymlinks(filepath.Join(realRoot, path))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(realRoot, full); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s leads outside the project", path)
+	}
+	return full, nil
+}
+
+// fetchRepoSettings reads the settings of a project from its default branch and caches them.
+// Nothing is read with ignore_repo_settings.
+func fetchRepoSettings(client Forge, projectID int) (*RepoSettings, error) {
+	if config, err := LoadConfig(); err == nil && config.IgnoreRepoSettings {
+		settings := &RepoSettings{}
+		repoSettingsCache.Store(projectID, settings)
+		return settings, nil
+	}
+	data, err := client.GetRepositoryFile(projectID, repoSettingsFileName)
+	if err != nil {
+		return nil, err
+	}
+	settings := &RepoSettings{}
+	if data != nil {
+		if settings, err = parseRepoSettings(data); err != nil {
+			return nil, err
+		}
+	}
+	repoSettingsCache.Store(projectID, settings)
+	return settings, nil
+}
+
+// useRepoSettings reads the settings of the project of a headless release and makes them apply
+func useRepoSettings(client Forge, projectID int) error {
+	activeRepoProject.Store(int64(projectID))
+	_, err := fetchRepoSettings(client, projectID)
+	return err
+}
+
+// cachedRepoSettings returns the settings read for a project, nil if none were read
+func cachedRepoSettings(projectID int) *RepoSettings {
+	if settings, ok := repoSettingsCache.Load(projectID); ok {
+		return settings.(*RepoSettings)
+	}
+	return nil
+}
+
+// LoadProjectConfig loads the user config with the settings of the project's .restitcher.yaml
+// applied. It is for reading the release policy only: saving it would copy the project settings
+// into the user config.
+func LoadProjectConfig(projectID int) (*AppConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if settings := cachedRepoSettings(projectID); settings != nil && !config.IgnoreRepoSettings {
+		settings.apply(config)
+	}
+	return config, nil
+}
+
+// loadActiveConfig loads the config with the settings of the active project applied
+func loadActiveConfig() (*AppConfig, error) {
+	return LoadProjectConfig(int(activeRepoProject.Load()))
+}
+
+// apply overrides the config with the settings set in the file
+func (s *RepoSettings) apply(config *AppConfig) {
+	if len(s.Environments) > 0 {
+		user := config.Environments
+		if len(user) == 0 {
+			user = defaultEnvironments()
+		}
+		envs := make([]EnvConfig, len(s.Environments))
+		for i, env := range s.Environments {
+			envs[i] = EnvConfig{Name: env.Name}
+			for _, u := range user {
+				if strings.EqualFold(u.Name, env.Name) {
+					envs[i] = u
+					break
+				}
+			}
+			envs[i].BranchName = env.Branch
+		}
+		config.Environments = envs
+	}
+	setIfNotEmpty(&config.BaseBranch, s.BaseBranch)
+	setIfNotEmpty(&config.MergeCommitTemplate, s.MergeCommitTemplate)
+	setIfNotEmpty(&config.SquashCommitTemplate, s.SquashCommitTemplate)
+	if s.ReleaseMRTemplate != "" || s.ReleaseMRTemplateFile != "" {
+		config.ReleaseMRTemplate, config.ReleaseMRTemplateFile = s.ReleaseMRTemplate, s.ReleaseMRTemplateFile
+	}
+	setIfNotEmpty(&config.HooksScript, s.HooksScript)
+	setIfNotEmpty(&config.MRTargetBranch, s.MRTargetBranch)
+	setIfNotEmpty(&config.MRSourceBranchRegex, s.MRSourceBranchRegex)
+	if len(s.ExcludePatterns) > 0 {
+		config.ExcludePatterns = strings.Join(s.ExcludePatterns, "\n")
+	}
+	setIfNotEmpty(&config.ReleaseVoteEmoji, s.ReleaseVoteEmoji)
+	setIfNotEmpty(&config.ReleaseVoteComment, s.ReleaseVoteComment)
+	if s.VersionScheme != "" {
+		config.VersionScheme, config.VersionPattern = s.VersionScheme, s.VersionPattern
+	}
+}
+
+// setIfNotEmpty sets the string if value is not empty
+func setIfNotEmpty(s *string, value string) {
+	if value != "" {
+		*s = value
+	}
+}
+
+// selectedProjectID returns the ID of the selected project, 0 without one
+func (m model) selectedProjectID() int {
+	if m.selectedProject == nil {
+		return 0
+	}
+	return m.selectedProject.ID
+}
+
+// activateRepoSettings makes the settings read for the selected project apply, e.g. after
+// switching tabs
+func (m *model) activateRepoSettings() {
+	activeRepoProject.Store(int64(m.selectedProjectID()))
+	m.refreshEnvironments()
+	m.refreshBlockedMRs()
+}
+
+// useProjectRepoSettings makes the settings of the selected project apply and returns the command
+// reading them anew from its default branch
+func (m *model) useProjectRepoSettings() tea.Cmd {
+	m.activateRepoSettings()
+	if m.selectedProject == nil || m.creds == nil {
+		return nil
+	}
+	projectID := m.selectedProject.ID
+	creds := *m.creds
+
+	return func() tea.Msg {
+		settings, err := fetchRepoSettings(NewForge(creds), projectID)
+		return repoSettingsMsg{projectID: projectID, settings: settings, err: err}
+	}
+}
+
+// handleRepoSettings applies the settings read for the selected project. An invalid file is
+// reported; while the forge is unreachable the settings read before stay in use.
+func (m *model) handleRepoSettings(msg repoSettingsMsg) {
+	if msg.err != nil {
+		if isNetworkError(msg.err) {
+			return
+		}
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Project settings not applied: " + msg.err.Error()
+		return
+	}
+	if m.selectedProject != nil && m.selectedProject.ID == msg.projectID {
+		m.refreshEnvironments()
+		m.refreshBlockedMRs()
+	}
+}
+
+// refreshEnvironments reloads the environments offered on the screens, keeping the cursor in range
+func (m *model) refreshEnvironments() {
+	m.environments = getEnvironments()
+	if m.envSelectIndex >= len(m.environments) {
+		m.envSelectIndex = 0
+	}
+}
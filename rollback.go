@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A completed release is rolled back from its history entry ("R" on the history detail screen).
+// The rollback is a release of the previous completed release of the same environment: the tag of
+// that release is checked out into a rollback source branch, whose content replaces the
+// environment's as in a squash release. It then goes through the release MR, the pipeline and the
+// tag push like any release, so nothing is force-pushed to the environment branch. The history
+// entry of the rollback links the release it rolled back ("rollback_of").
+
+// rollbackSourceBranch returns the source branch a rollback to version is released from
+func rollbackSourceBranch(version string) string {
+	return fmt.Sprintf("release/rpb-%s-rollback", version)
+}
+
+// releaseVNumber returns the v-number the release gets. A rollback releases an earlier version
+// again, whose tags up to the computed number may exist already, so it takes the first free one
+// (unless the environment's version format has no {n}, and every number gives the same tag).
+func releaseVNumber(state *ReleaseState) (int, error) {
+	n, err := GetNextVersionNumber(state.WorkDir, state.Environment.BranchName, state.Version)
+	if err != nil || state.RollbackTag == "" {
+		return n, err
+	}
+	for {
+		tag := state.tagName(n)
+		if tag == state.tagName(n+1) || !localTagExists(state.WorkDir, tag) {
+			return n, nil
+		}
+		n++
+	}
+}
+
+// localTagExists reports whether the tag exists in the working copy (tags are fetched by the first release step)
+func localTagExists(workDir, tag string) bool {
+	cmd := exec.Command("git", "rev-parse", "-q", "--verify", "refs/tags/"+tag)
+	cmd.Dir = workDir
+	return cmd.Run() == nil
+}
+
+// historyGitTag returns the git tag of a history entry: the formatted version if its environment
+// formats versions, otherwise {env}-{tag}
+func historyGitTag(e HistoryIndexEntry) string {
+	if env, ok := findEnvironment(e.Environment); ok && env.hasVersionFormat() {
+		return e.Tag
+	}
+	return historyFullTag(e)
+}
+
+// rollbackTarget returns the release a rollback of entry restores: the completed release of the
+// same project and environment before it. Only the latest completed release of an environment can
+// be rolled back, as a newer one is what the environment runs. index is newest first.
+func rollbackTarget(index []HistoryIndexEntry, entry HistoryIndexEntry) (*HistoryIndexEntry, error) {
+	if entry.Status != "completed" {
+		return nil, fmt.Errorf("only completed releases can be rolled back")
+	}
+	found := false
+	for i, e := range index {
+		if e.Status != "completed" || e.ProjectID != entry.ProjectID || !strings.EqualFold(e.Environment, entry.Environment) {
+			continue
+		}
+		if !found {
+			if e.ID != entry.ID {
+				return nil, fmt.Errorf("only the latest release of %s can be rolled back; %s was released after it", entry.Environment, historyGitTag(e))
+			}
+			found = true
+			continue
+		}
+		return &index[i], nil
+	}
+	if !found {
+		return nil, fmt.Errorf("release %s is not in the history index", entry.ID)
+	}
+	return nil, fmt.Errorf("no earlier completed release of %s to roll back to", entry.Environment)
+}
+
+// askRollback asks to confirm rolling back the release shown in the history detail screen
+func (m *model) askRollback() {
+	m.closeAllModals()
+	entry := m.historySelected
+	if entry == nil {
+		return
+	}
+	fail := func(msg string) {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot roll back: " + msg
+	}
+	if m.releaseState != nil {
+		fail("this tab has an unfinished release. Finish it, or open a new tab with Alt+N.")
+		return
+	}
+	if m.creds == nil || m.selectedProject == nil {
+		fail("no project selected")
+		return
+	}
+	// The rollback is released from the selected project's checkout
+	if problem := m.historyProjectProblem(entry); problem != "" {
+		fail(problem)
+		return
+	}
+	if _, ok := findEnvironment(entry.Environment); !ok {
+		fail(fmt.Sprintf("environment %s is no longer configured", entry.Environment))
+		return
+	}
+	index, err := LoadHistoryIndex()
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	target, err := rollbackTarget(index, entry.HistoryIndexEntry)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	m.rollbackTarget = target
+	m.rollbackConfirmIndex = 1 // Cancel focused by default
+	m.showRollbackConfirm = true
+}
+
+// updateRollbackConfirm handles keys of the rollback confirmation
+func (m model) updateRollbackConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		return m.startRollback()
+	case "n", "N", "esc":
+		m.showRollbackConfirm = false
+		return m, nil
+	case "enter":
+		if m.rollbackConfirmIndex == 0 {
+			return m.startRollback()
+		}
+		m.showRollbackConfirm = false
+		return m, nil
+	case "tab", "left", "right", "h", "l":
+		m.rollbackConfirmIndex = 1 - m.rollbackConfirmIndex
+		return m, nil
+	}
+	return m, nil
+}
+
+// startRollback starts the release restoring the rollback target in this tab
+func (m model) startRollback() (tea.Model, tea.Cmd) {
+	m.showRollbackConfirm = false
+	entry, target := m.historySelected, m.rollbackTarget
+	if entry == nil || target == nil || m.selectedProject == nil || m.historyProjectProblem(entry) != "" {
+		return m, nil
+	}
+
+	env, ok := findEnvironment(entry.Environment)
+	var workDir string
+	var err error
+	if !ok {
+		err = fmt.Errorf("environment %s is no longer configured", entry.Environment)
+	} else if workDir, err = releaseWorkDirFor(m.selectedProject, env.Name); err == nil {
+		// Checked before the directory is prepared, which cleans up a release checkout
+		if n := m.releaseWorkDirInUse(workDir); n != 0 {
+			err = fmt.Errorf("tab %d is releasing from %s", n, workDir)
+		} else {
+			workDir, err = prepareReleaseWorkDir(m.selectedProject, env.Name)
+		}
+	}
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot roll back: " + err.Error()
+		return m, nil
+	}
+
+	badTag, goodTag := historyGitTag(entry.HistoryIndexEntry), historyGitTag(*target)
+	state := &ReleaseState{
+		Environment:     env,
+		Version:         target.Version,
+		BaseBranch:      getBaseBranch(),
+		SourceBranch:    rollbackSourceBranch(target.Version),
+		RootMerge:       false,
+		EnvMergeMode:    "squash",
+		ProjectID:       m.selectedProject.ID,
+		CurrentStep:     ReleaseStepGitFetch,
+		LastSuccessStep: ReleaseStepIdle,
+		MergedBranches:  []string{},
+		WorkDir:         workDir,
+		ReleaseNotes:    fmt.Sprintf("Rollback of %s: restores %s.", badTag, goodTag),
+		RollbackOf:      entry.ID,
+		RollbackTag:     goodTag,
+		VersionDate:     versionDate(time.Now()),
+	}
+
+	// The sidebar shows the rollback like a selection made on the screens
+	clear(m.selectedMRs)
+	m.selectedEnv = &env
+	m.versionInput.SetValue(state.Version)
+	m.sourceBranchInput.SetValue(state.SourceBranch)
+	m.envMergeSelection = 0
+	m.rootMergeSelection = false
+	m.rollbackTarget = nil
+
+	recordAudit(auditRollback, env.Name, fmt.Sprintf("%s -> %s", badTag, goodTag), nil)
+	cmd := m.beginRelease(state, releaseOrangeStyle.Render(fmt.Sprintf("Rolling back %s: %s -> %s", env.Name, badTag, goodTag)), "")
+	return m, cmd
+}
+
+// overlayRollbackConfirm renders the rollback confirmation modal
+func (m model) overlayRollbackConfirm(background string) string {
+	if m.historySelected == nil || m.rollbackTarget == nil {
+		return background
+	}
+	entry, target := m.historySelected, m.rollbackTarget
+
+	var sb strings.Builder
+	sb.WriteString(errorTitleStyle.Render("Roll Back " + strings.ToUpper(entry.Environment) + "?"))
+	sb.WriteString("\n\n")
+	fmt.Fprintf(&sb, "Restore %s (released %s) in place of %s.\n\n",
+		historyGitTag(*target), formatDateTime(target.DateTime), historyGitTag(entry.HistoryIndexEntry))
+	fmt.Fprintf(&sb, "Its content is released from %s through a release MR into %s, as in a squash release.\n\n",
+		rollbackSourceBranch(target.Version), entry.EnvBranch)
+
+	var rollbackBtn, cancelBtn string
+	if m.rollbackConfirmIndex == 0 {
+		rollbackBtn = buttonDangerStyle.Render("Roll back")
+		cancelBtn = buttonStyle.Render("Cancel")
+	} else {
+		rollbackBtn = buttonStyle.Render("Roll back")
+		cancelBtn = buttonActiveStyle.Render("Cancel")
+	}
+	sb.WriteString(fmt.Sprintf("     %s       %s", rollbackBtn, cancelBtn))
+
+	config := ModalConfig{
+		Width:    ModalWidth{Value: 60, Percent: false},
+		MinWidth: 40,
+		MaxWidth: 70,
+		Style:    errorBoxStyle,
+	}
+
+	modal := renderModal(sb.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
+
+// rolledBackBy returns the history entry of the rollback of the release with the given ID, if any
+func rolledBackBy(index []HistoryIndexEntry, id string) *HistoryIndexEntry {
+	for i, e := range index {
+		if e.RollbackOf == id {
+			return &index[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Releases to environments with "rollout" enabled get an extra step [7] before confirmation,
+// asking how the release is rolled out: the canary percentage and the feature flags toggled with
+// it. Both are optional. The rollout is kept with the release and its history entry, and shown in
+// release notes, the release MR description and notifications.
+
+// Rollout describes how a release is rolled out
+type Rollout struct {
+	CanaryPercent int      `json:"canary_percent,omitempty"` // Share of traffic or instances getting the release first; 0 for a full rollout
+	FeatureFlags  []string `json:"feature_flags,omitempty"`  // Flags toggled with the release, e.g. "+new-checkout", "-legacy-cart"
+}
+
+// String summarizes the rollout, e.g. "canary 10%; flags +new-checkout, -legacy-cart"
+func (r *Rollout) String() string {
+	if r == nil {
+		return ""
+	}
+	var parts []string
+	if r.CanaryPercent > 0 {
+		parts = append(parts, fmt.Sprintf("canary %d%%", r.CanaryPercent))
+	}
+	if len(r.FeatureFlags) > 0 {
+		parts = append(parts, "flags "+strings.Join(r.FeatureFlags, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// validate checks the canary percentage
+func (r *Rollout) validate() error {
+	if r != nil && (r.CanaryPercent < 0 || r.CanaryPercent > 100) {
+		return fmt.Errorf("canary percentage must be between 0 and 100, got %d", r.CanaryPercent)
+	}
+	return nil
+}
+
+// rolloutMarkdown renders the rollout for the confirmation screen, flags as code so that their
+// underscores stay literal
+func rolloutMarkdown(r *Rollout) string {
+	var parts []string
+	if r.CanaryPercent > 0 {
+		parts = append(parts, fmt.Sprintf("canary to **%d%%** first", r.CanaryPercent))
+	}
+	if len(r.FeatureFlags) > 0 {
+		flags := make([]string, len(r.FeatureFlags))
+		for i, flag := range r.FeatureFlags {
+			flags[i] = "`" + flag + "`"
+		}
+		parts = append(parts, "feature flags "+strings.Join(flags, ", "))
+	}
+	return "Rollout: " + strings.Join(parts, ", ")
+}
+
+// parseRollout reads the rollout step inputs: a percentage ("10" or "10%") and flags separated by
+// commas or spaces. It returns nil if both are empty.
+func parseRollout(canary, flags string) (*Rollout, error) {
+	r := &Rollout{}
+	if canary = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(canary), "%")); canary != "" {
+		n, err := strconv.Atoi(canary)
+		if err != nil || n < 1 || n > 100 {
+			return nil, fmt.Errorf("canary percentage must be a number between 1 and 100")
+		}
+		r.CanaryPercent = n
+	}
+	for _, flag := range strings.FieldsFunc(flags, func(c rune) bool { return c == ',' || c == ' ' }) {
+		if !slices.Contains(r.FeatureFlags, flag) {
+			r.FeatureFlags = append(r.FeatureFlags, flag)
+		}
+	}
+	if r.CanaryPercent == 0 && len(r.FeatureFlags) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// newRolloutInput creates an input of the rollout step, keeping the value it had
+func newRolloutInput(placeholder, value string, limit int) textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	ti.CharLimit = limit
+	ti.Width = 50
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(currentTheme.Foreground)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(currentTheme.Accent)
+	ti.SetValue(value)
+	return ti
+}
+
+// initRolloutInputs prepares the rollout step, keeping what was typed before going back
+func (m *model) initRolloutInputs() tea.Cmd {
+	m.rolloutCanaryInput = newRolloutInput("e.g. 10 (empty for a full rollout)", m.rolloutCanaryInput.Value(), 4)
+	m.rolloutFlagsInput = newRolloutInput("e.g. +new-checkout, -legacy-cart", m.rolloutFlagsInput.Value(), 500)
+	m.rolloutFocus = 0
+	m.rolloutError = ""
+	return m.rolloutCanaryInput.Focus()
+}
+
+// needsRollout reports whether the selected environment asks for rollout metadata
+func (m model) needsRollout() bool {
+	return m.selectedEnv != nil && m.selectedEnv.Rollout
+}
+
+// rollout returns the rollout typed in the rollout step, or nil if the step is skipped or empty
+func (m model) rollout() *Rollout {
+	if !m.needsRollout() {
+		return nil
+	}
+	r, _ := parseRollout(m.rolloutCanaryInput.Value(), m.rolloutFlagsInput.Value())
+	return r
+}
+
+// showConfirm opens the confirmation screen
+func (m *model) showConfirm() tea.Cmd {
+	m.moveToScreen(screenConfirm)
+	cmds := tea.Batch(m.loadReleaseImpact(), m.loadScheduleCollisions())
+	m.initConfirmViewport()
+	return cmds
+}
+
+// updateRollout handles key events on the rollout screen
+func (m model) updateRollout(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+q":
+		// Go back to root merge screen
+		m.goBack()
+		m.rolloutError = ""
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		m.rolloutFocus = 1 - m.rolloutFocus
+		if m.rolloutFocus == 0 {
+			m.rolloutFlagsInput.Blur()
+			return m, m.rolloutCanaryInput.Focus()
+		}
+		m.rolloutCanaryInput.Blur()
+		return m, m.rolloutFlagsInput.Focus()
+	case "enter":
+		if _, err := parseRollout(m.rolloutCanaryInput.Value(), m.rolloutFlagsInput.Value()); err != nil {
+			m.rolloutError = err.Error()
+			return m, nil
+		}
+		m.rolloutError = ""
+		cmd := m.showConfirm()
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	if m.rolloutFocus == 0 {
+		m.rolloutCanaryInput, cmd = m.rolloutCanaryInput.Update(msg)
+	} else {
+		m.rolloutFlagsInput, cmd = m.rolloutFlagsInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// viewRollout renders the rollout screen
+func (m model) viewRollout() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	sidebarW := sidebarWidth(m.width)
+	contentWidth := m.width - sidebarW - 4
+	contentHeight := m.height - 4
+	totalHeight := contentHeight + 2
+
+	sidebar := m.renderSixSidebar(sidebarW, totalHeight)
+
+	content := contentStyle.
+		Width(contentWidth).
+		Height(contentHeight).
+		Render(m.renderRolloutContent())
+
+	main := lipgloss.JoinHorizontal(lipgloss.Top, sidebar, content)
+
+	helpText := "tab: switch field • enter: confirm • C+q: back • /: commands • C+c: quit"
+	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
+
+	return lipgloss.JoinVertical(lipgloss.Left, main, help)
+}
+
+// renderRolloutContent renders the rollout inputs
+func (m model) renderRolloutContent() string {
+	var sb strings.Builder
+
+	sb.WriteString(envTitleStepStyle.Render("[7]") + envTitleStyle.Render(" Rollout "))
+	sb.WriteString("\n\n")
+
+	sb.WriteString(envPromptStyle.Render("How will this release be rolled out?"))
+	sb.WriteString("\n")
+	sub := "Both fields are optional. They are kept with the release and shown in release notes and notifications."
+	sb.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Notion).Render(sub))
+	sb.WriteString("\n\n")
+
+	sb.WriteString(versionInputStyle.Render("Canary percentage: "))
+	sb.WriteString(m.rolloutCanaryInput.View())
+	sb.WriteString("\n\n")
+	sb.WriteString(versionInputStyle.Render("Feature flags:     "))
+	sb.WriteString(m.rolloutFlagsInput.View())
+
+	if m.rolloutError != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(errorTitleStyle.Render(m.rolloutError))
+	}
+
+	return sb.String()
+}
@@ -12,7 +12,7 @@ func (m model) updateRootMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+q":
 		// Go back to env merge screen
-		m.screen = screenEnvMerge
+		m.goBack()
 		return m, nil
 	case "left", "h":
 		if m.rootMergeButtonIndex > 0 {
@@ -43,9 +43,12 @@ func (m model) updateRootMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		// Save selection and proceed to confirmation screen
 		m.rootMergeSelection = m.rootMergeButtonIndex == 0 // 0 = Yes, 1 = No
-		m.screen = screenConfirm
-		m.initConfirmViewport()
-		return m, nil
+		if m.needsRollout() {
+			m.moveToScreen(screenRollout)
+			return m, m.initRolloutInputs()
+		}
+		cmd := m.showConfirm()
+		return m, cmd
 	}
 
 	return m, nil
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// Safe mode: while the TUI runs, a session marker (~/.relix/session-{pid}.json) records when it
+// started. Quitting removes it, unless a release step is still running then. A marker of a process
+// no longer running found on the next start means the previous session panicked, was killed or
+// quit mid-step; while another relix runs, the releases left unfinished may be its own, so its
+// marker holds the safe mode back. When a crash
+// report was written since it started or releases were left unfinished, relix starts in safe
+// mode. Plugins, the update check, polling, the project and the release resume wait while a
+// summary of the crash and of the unfinished releases offers to resume them, to abort them and
+// clean up their branches, or to export the logs for a bug report; then the normal startup goes on.
+
+const exportsDirName = "exports"
+
+// previousSession is what the previous session left behind, when relix starts in safe mode
+var previousSession *safeModeInfo
+
+// currentSession is the marker of this session
+var currentSession sessionMarker
+
+// sessionMarker is the content of the session marker
+type sessionMarker struct {
+	PID     int        `json:"pid"`
+	Version string     `json:"version"`
+	Started time.Time  `json:"started"`
+	Ended   *time.Time `json:"ended,omitempty"` // Quit while a release step was running
+}
+
+// safeModeInfo describes how the previous session ended
+type safeModeInfo struct {
+	session     sessionMarker
+	markerPath  string                // Marker of the session, removed once the safe mode is left
+	crashReport string                // Path of the crash report written since it started
+	panic       string                // Panic line of the crash report
+	releases    map[int]*ReleaseState // Unfinished releases by tab
+}
+
+// safeModeExportMsg carries the archive the logs were exported to
+type safeModeExportMsg struct {
+	path string
+	err  error
+}
+
+// Actions of the safe mode screen
+const (
+	safeModeResume = "Resume the releases"
+	safeModeAbort  = "Abort them and clean up"
+	safeModeGoOn   = "Continue"
+	safeModeExport = "Export logs"
+)
+
+// getSessionMarkerPath returns the path of the session marker of the process pid
+func getSessionMarkerPath(pid int) (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("session-%d.json", pid)), nil
+}
+
+// processAlive reports whether the process pid runs
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// openSessionMarker reads the markers of earlier sessions, sets previousSession when the latest one
+// no longer running ended in a panic or left releases unfinished, and writes the marker of this one.
+// Nothing is inspected while another session runs.
+func openSessionMarker() {
+	dir, err := getConfigDir()
+	if err != nil {
+		return
+	}
+	// session.json is the marker of versions before the markers were kept per process
+	paths, _ := filepath.Glob(filepath.Join(dir, "session*.json"))
+	var ended []string
+	var latest sessionMarker
+	latestPath, running := "", false
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var marker sessionMarker
+		if json.Unmarshal(data, &marker) != nil {
+			continue
+		}
+		if marker.PID != os.Getpid() && processAlive(marker.PID) {
+			running = true
+			continue
+		}
+		ended = append(ended, path)
+		if latestPath == "" || marker.Started.After(latest.Started) {
+			latest, latestPath = marker, path
+		}
+	}
+	if !running && latestPath != "" {
+		previousSession = inspectPreviousSession(latest)
+		// The latest marker is kept for the log export until the safe mode is left
+		for _, path := range ended {
+			if previousSession == nil || path != latestPath {
+				os.Remove(path)
+			}
+		}
+		if previousSession != nil {
+			previousSession.markerPath = latestPath
+		}
+	}
+
+	path, err := getSessionMarkerPath(os.Getpid())
+	if err != nil {
+		return
+	}
+	currentSession = sessionMarker{PID: os.Getpid(), Version: AppVersion, Started: time.Now()}
+	if data, err := json.MarshalIndent(currentSession, "", "  "); err == nil {
+		os.WriteFile(path, data, 0o600)
+	}
+}
+
+// inspectPreviousSession looks for the crash report and the releases a session left behind; nil
+// when there are neither
+func inspectPreviousSession(marker sessionMarker) *safeModeInfo {
+	info := &safeModeInfo{session: marker, releases: tabReleaseStates()}
+	if state, err := LoadReleaseState(firstTabID); err == nil && state != nil {
+		info.releases[firstTabID] = state
+	}
+
+	if dir, err := getConfigDir(); err == nil {
+		reports, _ := filepath.Glob(filepath.Join(dir, crashesDirName, "crash-*.txt"))
+		var latest time.Time
+		for _, path := range reports {
+			if stat, err := os.Stat(path); err == nil && !stat.ModTime().Before(marker.Started) && stat.ModTime().After(latest) {
+				latest = stat.ModTime()
+				info.crashReport = path
+			}
+		}
+	}
+	if info.crashReport != "" {
+		info.panic = crashReportPanic(info.crashReport)
+	}
+
+	if info.crashReport == "" && len(info.releases) == 0 {
+		return nil
+	}
+	return info
+}
+
+// crashReportPanic returns the panic line of a crash report
+func crashReportPanic(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "panic: ") {
+			return line
+		}
+	}
+	return ""
+}
+
+// closeSessionMarker removes the session marker once the TUI quit, unless it panicked or quit while a
+// release step was running
+func closeSessionMarker(final tea.Model, runErr error) {
+	path, err := getSessionMarkerPath(os.Getpid())
+	if err != nil || runErr != nil {
+		return
+	}
+	if guard, ok := final.(crashGuard); ok {
+		if m := asModel(guard.model); m.releaseRunning || m.parkedTabRunning() {
+			now := time.Now()
+			currentSession.Ended = &now
+			if data, err := json.MarshalIndent(currentSession, "", "  "); err == nil {
+				os.WriteFile(path, data, 0o600)
+			}
+			return
+		}
+	}
+	os.Remove(path)
+}
+
+// openSafeMode shows the safe mode screen, holding the startup until an action is chosen
+func (m *model) openSafeMode(msg checkCredsMsg) {
+	m.safeModeCreds = &msg
+	m.safeModeIndex = 0
+	m.safeModeExported = ""
+	m.safeModeExportErr = nil
+	m.moveToScreen(screenSafeMode)
+}
+
+// safeModeActions returns the actions of the safe mode screen. Releases are resumed and aborted
+// only when signed in, as they are on a normal start.
+func (m model) safeModeActions() []string {
+	if len(m.safeMode.releases) > 0 && m.safeModeCreds.creds != nil {
+		return []string{safeModeResume, safeModeAbort, safeModeExport}
+	}
+	return []string{safeModeGoOn, safeModeExport}
+}
+
+// leaveSafeMode goes on with the startup held by the safe mode, loading what it skipped
+func (m *model) leaveSafeMode(msg checkCredsMsg) tea.Cmd {
+	os.Remove(m.safeMode.markerPath)
+	m.safeMode = nil
+	m.safeModeCreds = nil
+	return tea.Batch(m.startSession(msg), loadPlugins(), checkForUpdate())
+}
+
+// updateSafeMode handles keys of the safe mode screen
+func (m model) updateSafeMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	actions := m.safeModeActions()
+	switch msg.String() {
+	case "up", "k":
+		if m.safeModeIndex > 0 {
+			m.safeModeIndex--
+		}
+	case "down", "j":
+		if m.safeModeIndex < len(actions)-1 {
+			m.safeModeIndex++
+		}
+	case "enter":
+		pending := *m.safeModeCreds
+		switch actions[m.safeModeIndex] {
+		case safeModeResume, safeModeGoOn:
+			cmd := m.leaveSafeMode(pending)
+			return m, cmd
+		case safeModeAbort:
+			m.creds = pending.creds
+			aborted, abortCmd := m.abortUnfinishedReleases()
+			pending.releaseState = nil
+			pending.tabStates = nil
+			cmd := aborted.leaveSafeMode(pending)
+			return aborted, tea.Batch(abortCmd, cmd)
+		case safeModeExport:
+			if m.safeModeExporting {
+				return m, nil
+			}
+			m.safeModeExporting = true
+			m.safeModeExported = ""
+			m.safeModeExportErr = nil
+			return m, exportSafeModeLogs(*m.safeMode)
+		}
+	}
+	return m, nil
+}
+
+// abortUnfinishedReleases aborts the releases of the previous session as Abort on the release
+// screen does, each with its tab swapped in. The first tab goes last, so the post-mortem asked
+// for is that of its release.
+func (m model) abortUnfinishedReleases() (model, tea.Cmd) {
+	var cmds []tea.Cmd
+	session := m.releaseSession
+	tabs := slices.Sorted(maps.Keys(m.safeMode.releases))
+	slices.Reverse(tabs)
+	for _, tab := range tabs {
+		state := m.safeMode.releases[tab]
+		m.releaseSession = newReleaseSession(tab)
+		m.selectedProject = m.knownProject(state.ProjectID)
+		m.releaseState = state
+		m.setReleaseOutput(append([]string{}, state.TerminalOutput...))
+		next, cmd := m.abortRelease()
+		m = asModel(next)
+		cmds = append(cmds, cmd)
+	}
+	m.releaseSession = session
+	return m, tea.Batch(cmds...)
+}
+
+// exportSafeModeLogs returns the command writing the crash report, the session marker and the
+// release states and outputs left behind to ~/.relix/exports/relix-logs-{timestamp}.tar.gz.
+// Release states and outputs are redacted like crash reports are.
+func exportSafeModeLogs(info safeModeInfo) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := getConfigDir()
+		if err != nil {
+			return safeModeExportMsg{err: err}
+		}
+		var files []string
+		if info.crashReport != "" {
+			files = append(files, info.crashReport)
+		}
+		files = append(files, info.markerPath)
+		for _, pattern := range []string{"release*.json", "release-output*.log"} {
+			paths, _ := filepath.Glob(filepath.Join(dir, pattern))
+			files = append(files, paths...)
+		}
+
+		exportDir := filepath.Join(dir, exportsDirName)
+		if err := os.MkdirAll(exportDir, 0o755); err != nil {
+			return safeModeExportMsg{err: err}
+		}
+		path := filepath.Join(exportDir, "relix-logs-"+time.Now().Format("20060102-150405")+".tar.gz")
+		if err := writeLogsArchive(path, files); err != nil {
+			os.Remove(path)
+			return safeModeExportMsg{err: err}
+		}
+		return safeModeExportMsg{path: path}
+	}
+}
+
+// writeLogsArchive writes the files, redacted, to a gzipped tar; missing files are skipped
+func writeLogsArchive(path string, files []string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		data = []byte(redactSecrets(string(data)))
+		header := &tar.Header{Name: filepath.Base(file), Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// handleSafeModeExport reports where the logs were exported to
+func (m *model) handleSafeModeExport(msg safeModeExportMsg) {
+	m.safeModeExporting = false
+	m.safeModeExported = msg.path
+	m.safeModeExportErr = msg.err
+}
+
+// ending describes how the previous session ended
+func (info safeModeInfo) ending() string {
+	switch {
+	case info.crashReport != "":
+		return "The previous session crashed."
+	case info.session.Ended != nil:
+		return fmt.Sprintf("The previous session was closed %s while a release step was running.", humanize.Time(*info.session.Ended))
+	}
+	return fmt.Sprintf("The previous session, started %s, was stopped without quitting, e.g. killed or with its terminal closed.", humanize.Time(info.session.Started))
+}
+
+// viewSafeMode renders the summary of the previous session and the recovery actions
+func (m model) viewSafeMode() string {
+	var b strings.Builder
+	info := m.safeMode
+
+	b.WriteString(errorTitleStyle.Render("Safe mode"))
+	b.WriteString("\n\n")
+	b.WriteString(info.ending())
+	b.WriteString("\n")
+	if info.crashReport != "" {
+		if info.panic != "" {
+			b.WriteString(info.panic + "\n")
+		}
+		b.WriteString(helpStyle.Render("Crash report: " + info.crashReport))
+		b.WriteString("\n")
+	}
+
+	if len(info.releases) > 0 {
+		b.WriteString("\nUnfinished releases:\n")
+		for i, tab := range slices.Sorted(maps.Keys(info.releases)) {
+			state := info.releases[tab]
+			project := m.knownProject(state.ProjectID)
+			name := project.PathWithNamespace
+			if name == "" {
+				name = project.Name
+			}
+			envStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(getEnvBranchColor(state.Environment.Name))).Bold(true)
+			fmt.Fprintf(&b, "  Tab %d · %s · %s %s · %d MRs\n", i+1, name, envStyle.Render(state.Environment.Name), state.Version, len(state.MRBranches))
+			step := "    step " + releaseStepNames[state.CurrentStep]
+			if state.LastError != nil {
+				step += ", failed: " + state.LastError.Message
+			}
+			b.WriteString(helpStyle.Render(step))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Plugins, the update check, polling and the release resume wait until you choose."))
+	b.WriteString("\n\n")
+
+	for i, action := range m.safeModeActions() {
+		if i == m.safeModeIndex {
+			b.WriteString(commandItemSelectedStyle.Render("> " + action))
+		} else {
+			b.WriteString(commandItemStyle.Render("  " + action))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.safeModeExporting:
+		b.WriteString(helpStyle.Render("Exporting…"))
+		b.WriteString("\n\n")
+	case m.safeModeExportErr != nil:
+		b.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("Cannot export the logs: " + m.safeModeExportErr.Error()))
+		b.WriteString("\n\n")
+	case m.safeModeExported != "":
+		b.WriteString(lipgloss.NewStyle().Foreground(currentTheme.Success).Render("Logs exported to " + m.safeModeExported))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓: nav • enter: choose • C+c: quit"))
+
+	config := ErrorModalConfig()
+	config.MaxWidth = 90
+	content := renderModal(b.String(), config, m.width)
+
+	// Center the summary, like the error screen
+	padding := max(0, (m.width-lipgloss.Width(content))/2)
+	centered := lipgloss.NewStyle().PaddingLeft(padding).Render(content)
+	height := lipgloss.Height(centered)
+	top := max(0, (m.height-height)/2)
+	return strings.Repeat("\n", top) + centered + strings.Repeat("\n", max(0, m.height-height-top))
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The screenshot command writes the rendered screen to a file, for documentation and for bug
+// reports about rendering: as is with its ANSI escapes (view it with cat in a terminal of the same
+// size), or converted to an HTML page or an SVG image. Conversion keeps the SGR attributes relix
+// renders with (colors, bold, faint, italic, underline, strikethrough and reverse) and drops any
+// other escape sequence.
+
+// Screenshot formats (screenshot_format)
+const (
+	screenshotFormatANSI = "ansi"
+	screenshotFormatHTML = "html"
+	screenshotFormatSVG  = "svg"
+)
+
+// Cell metrics of SVG screenshots, in pixels
+const (
+	screenshotFontSize   = 14
+	screenshotCellWidth  = 8.4
+	screenshotLineHeight = 18
+	screenshotPadding    = 16
+)
+
+// screenshotMsg reports a written screenshot
+type screenshotMsg struct {
+	path string
+	err  error
+}
+
+// screenshotStyle is the SGR state of a run of text; colors are "#rrggbb", empty for the default
+type screenshotStyle struct {
+	fg, bg                                                 string
+	bold, faint, italic, underline, strikethrough, reverse bool
+}
+
+// screenshotSpan is a run of text with one style
+type screenshotSpan struct {
+	text  string
+	width int
+	style screenshotStyle
+}
+
+// screenshotSettings returns the configured screenshot format and directory
+// (default ANSI in ~/.relix/screenshots)
+func screenshotSettings() (string, string, error) {
+	format, dir := screenshotFormatANSI, ""
+	if config, err := LoadConfig(); err == nil {
+		if config.ScreenshotFormat != "" {
+			format = strings.ToLower(config.ScreenshotFormat)
+		}
+		if config.ScreenshotDir != "" {
+			expanded, err := expandHome(config.ScreenshotDir)
+			if err != nil {
+				return "", "", err
+			}
+			dir = expanded
+		}
+	}
+	switch format {
+	case screenshotFormatANSI, screenshotFormatHTML, screenshotFormatSVG:
+	default:
+		return "", "", fmt.Errorf("unknown screenshot_format %q (ansi, html or svg)", format)
+	}
+	if dir == "" {
+		configDir, err := getConfigDir()
+		if err != nil {
+			return "", "", err
+		}
+		dir = filepath.Join(configDir, "screenshots")
+	}
+	return format, dir, nil
+}
+
+// takeScreenshot returns a command writing the rendered view to the screenshot directory
+func (m model) takeScreenshot() tea.Cmd {
+	view := m.View()
+	background, foreground := screenshotColors()
+
+	return func() tea.Msg {
+		format, dir, err := screenshotSettings()
+		if err != nil {
+			return screenshotMsg{err: err}
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return screenshotMsg{err: err}
+		}
+
+		var content, ext string
+		switch format {
+		case screenshotFormatHTML:
+			content, ext = screenshotHTML(view, background, foreground), "html"
+		case screenshotFormatSVG:
+			content, ext = screenshotSVG(view, background, foreground), "svg"
+		default:
+			content, ext = view+"\n", "ans"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("relix-%s.%s", time.Now().Format("20060102-150405"), ext))
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return screenshotMsg{err: err}
+		}
+		return screenshotMsg{path: path}
+	}
+}
+
+// handleScreenshot reports where the screenshot was written
+func (m *model) handleScreenshot(msg screenshotMsg) {
+	m.closeAllModals()
+	if msg.err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("Screenshot failed: %v", msg.err)
+		return
+	}
+	m.showPluginResult = true
+	m.pluginResultTitle = "screenshot"
+	m.pluginResultText = "Saved to " + msg.path
+}
+
+// screenshotColors returns the default background and foreground of converted screenshots: the
+// theme's, or a dark terminal's if the theme leaves them to the terminal
+func screenshotColors() (string, string) {
+	background, foreground := "#1e1e1e", "#d4d4d4"
+	if currentTheme.HasBackground && strings.HasPrefix(string(currentTheme.Background), "#") {
+		background = string(currentTheme.Background)
+	}
+	if strings.HasPrefix(string(currentTheme.Foreground), "#") {
+		foreground = string(currentTheme.Foreground)
+	}
+	return background, foreground
+}
+
+// parseScreenshot splits a rendered view into lines of styled spans
+func parseScreenshot(view string) [][]screenshotSpan {
+	var lines [][]screenshotSpan
+	var line []screenshotSpan
+	var style screenshotStyle
+	var state byte
+	p := ansi.NewParser()
+
+	for len(view) > 0 {
+		seq, width, n, newState := ansi.DecodeSequence(view, state, p)
+		state = newState
+		view = view[n:]
+
+		switch {
+		case seq == "\n":
+			lines = append(lines, line)
+			line = nil
+		case ansi.HasCsiPrefix(seq):
+			if ansi.Cmd(p.Command()).Final() == 'm' {
+				style.apply(p.Params())
+			}
+		case seq == "" || seq[0] < ' ' || seq[0] == ansi.DEL || seq[0] == ansi.ESC:
+			// Other control characters and escape sequences don't draw anything
+		default:
+			if last := len(line) - 1; last >= 0 && line[last].style == style {
+				line[last].text += seq
+				line[last].width += width
+			} else {
+				line = append(line, screenshotSpan{text: seq, width: width, style: style})
+			}
+		}
+	}
+	return append(lines, line)
+}
+
+// apply updates the style with the parameters of an SGR sequence
+func (s *screenshotStyle) apply(params ansi.Params) {
+	if len(params) == 0 {
+		*s = screenshotStyle{}
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		code := params[i].Param(0)
+		switch {
+		case code == 0:
+			*s = screenshotStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.faint = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.reverse = true
+		case code == 9:
+			s.strikethrough = true
+		case code == 22:
+			s.bold, s.faint = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.reverse = false
+		case code == 29:
+			s.strikethrough = false
+		case code >= 30 && code <= 37:
+			s.fg = ansi256Hex(code - 30)
+		case code >= 90 && code <= 97:
+			s.fg = ansi256Hex(code - 90 + 8)
+		case code >= 40 && code <= 47:
+			s.bg = ansi256Hex(code - 40)
+		case code >= 100 && code <= 107:
+			s.bg = ansi256Hex(code - 100 + 8)
+		case code == 39:
+			s.fg = ""
+		case code == 49:
+			s.bg = ""
+		case code == 38 || code == 48:
+			color, used := extendedColor(params[i+1:])
+			i += used
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor reads a 256-color ("5;n") or true color ("2;r;g;b") argument and returns the
+// color with the number of parameters it took
+func extendedColor(params ansi.Params) (string, int) {
+	if len(params) == 0 {
+		return "", 0
+	}
+	switch params[0].Param(0) {
+	case 5:
+		if len(params) < 2 {
+			return "", len(params)
+		}
+		return ansi256Hex(params[1].Param(0)), 2
+	case 2:
+		if len(params) < 4 {
+			return "", len(params)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", params[1].Param(0)&0xff, params[2].Param(0)&0xff, params[3].Param(0)&0xff), 4
+	}
+	return "", 1
+}
+
+// ansiBaseColors are the 16 base colors, as xterm renders them
+var ansiBaseColors = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// ansi256Hex returns a color of the xterm 256-color palette as "#rrggbb"
+func ansi256Hex(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansiBaseColors[n]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		gray := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
+
+// colors returns the foreground and background of a span, with reverse applied
+func (s screenshotStyle) colors(background, foreground string) (string, string) {
+	fg, bg := s.fg, s.bg
+	if fg == "" {
+		fg = foreground
+	}
+	if bg == "" {
+		bg = background
+	}
+	if s.reverse {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// textDecoration returns the CSS text-decoration of the style, empty for none
+func (s screenshotStyle) textDecoration() string {
+	var decorations []string
+	if s.underline {
+		decorations = append(decorations, "underline")
+	}
+	if s.strikethrough {
+		decorations = append(decorations, "line-through")
+	}
+	return strings.Join(decorations, " ")
+}
+
+// screenshotHTML converts a rendered view to an HTML page
+func screenshotHTML(view, background, foreground string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>relix</title>\n</head>\n")
+	fmt.Fprintf(&b, "<body style=\"margin:0;background:%s\">\n", background)
+	fmt.Fprintf(&b, "<pre style=\"margin:0;padding:%dpx;color:%s;background:%s;font-family:Menlo,Consolas,'DejaVu Sans Mono',monospace;font-size:%dpx;line-height:%dpx\">",
+		screenshotPadding, foreground, background, screenshotFontSize, screenshotLineHeight)
+
+	for i, line := range parseScreenshot(view) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, span := range line {
+			if span.style == (screenshotStyle{}) {
+				b.WriteString(html.EscapeString(span.text))
+				continue
+			}
+			fg, bg := span.style.colors(background, foreground)
+			css := []string{"color:" + fg}
+			if bg != background {
+				css = append(css, "background:"+bg)
+			}
+			if span.style.bold {
+				css = append(css, "font-weight:bold")
+			}
+			if span.style.faint {
+				css = append(css, "opacity:0.6")
+			}
+			if span.style.italic {
+				css = append(css, "font-style:italic")
+			}
+			if decoration := span.style.textDecoration(); decoration != "" {
+				css = append(css, "text-decoration:"+decoration)
+			}
+			fmt.Fprintf(&b, "<span style=\"%s\">%s</span>", strings.Join(css, ";"), html.EscapeString(span.text))
+		}
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// screenshotSVG converts a rendered view to an SVG image with a fixed cell size. Each span is
+// stretched to its cell width, so wide and missing glyphs don't shift the rest of the line.
+func screenshotSVG(view, background, foreground string) string {
+	lines := parseScreenshot(view)
+	columns := 0
+	for _, line := range lines {
+		width := 0
+		for _, span := range line {
+			width += span.width
+		}
+		columns = max(columns, width)
+	}
+	width := float64(columns)*screenshotCellWidth + 2*screenshotPadding
+	height := len(lines)*screenshotLineHeight + 2*screenshotPadding
+
+	var rects, texts strings.Builder
+	for row, line := range lines {
+		y := screenshotPadding + row*screenshotLineHeight
+		column := 0
+		for _, span := range line {
+			x := screenshotPadding + float64(column)*screenshotCellWidth
+			spanWidth := float64(span.width) * screenshotCellWidth
+			column += span.width
+			fg, bg := span.style.colors(background, foreground)
+			if bg != background {
+				fmt.Fprintf(&rects, "<rect x=\"%.1f\" y=\"%d\" width=\"%.1f\" height=\"%d\" fill=\"%s\"/>\n", x, y, spanWidth, screenshotLineHeight, bg)
+			}
+			if strings.TrimSpace(span.text) == "" && span.style.textDecoration() == "" {
+				continue
+			}
+			attrs := fmt.Sprintf("x=\"%.1f\" y=\"%d\" fill=\"%s\" textLength=\"%.1f\" lengthAdjust=\"spacingAndGlyphs\"", x, y+screenshotFontSize, fg, spanWidth)
+			if span.style.bold {
+				attrs += " font-weight=\"bold\""
+			}
+			if span.style.faint {
+				attrs += " opacity=\"0.6\""
+			}
+			if span.style.italic {
+				attrs += " font-style=\"italic\""
+			}
+			if decoration := span.style.textDecoration(); decoration != "" {
+				attrs += fmt.Sprintf(" text-decoration=\"%s\"", decoration)
+			}
+			fmt.Fprintf(&texts, "<text %s>%s</text>\n", attrs, html.EscapeString(span.text))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.1f\" height=\"%d\" viewBox=\"0 0 %.1f %d\">\n", width, height, width, height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", background)
+	b.WriteString(rects.String())
+	fmt.Fprintf(&b, "<g font-family=\"Menlo, Consolas, 'DejaVu Sans Mono', monospace\" font-size=\"%d\" xml:space=\"preserve\">\n", screenshotFontSize)
+	b.WriteString(texts.String())
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The hook script (~/.relix/hooks.lua, or hooks_script in the config) customizes releases with
+// Lua functions that relix calls when they are defined:
+//
+//	include_mr(mr)           return false, "reason" to keep an MR out of releases
+//	plan(plan)               change the release plan, or return a new one
+//	version(ctx)             return the version when none was entered
+//
+// The script runs in a fresh sandboxed interpreter for every call. Besides the Lua library subset
+// it can only use the relix table: merge_request, pipelines, history, last_version and bump.
+const defaultHooksScript = "hooks.lua"
+
+// scriptHooks runs the functions of the hook script. A nil *scriptHooks has no hooks.
+type scriptHooks struct {
+	path      string
+	src       string
+	forge     Forge
+	projectID int
+	print     func(line string) // Receives print() output; nil discards it
+}
+
+// loadScriptHooks reads and checks the hook script. It returns nil if there is none.
+func loadScriptHooks(forge Forge, projectID int, print func(line string)) (*scriptHooks, error) {
+	path, err := hooksScriptPath(projectID)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("hook script: %w", err)
+	}
+	if _, err := parseLua(filepath.Base(path), string(data)); err != nil {
+		return nil, fmt.Errorf("hook script: %w", err)
+	}
+	return &scriptHooks{path: path, src: string(data), forge: forge, projectID: projectID, print: print}, nil
+}
+
+// hooksScriptPath returns the configured script, relative paths being resolved against the
+// project root, or ~/.relix/hooks.lua if it exists. The project's settings may set a script of
+// the project only.
+func hooksScriptPath(projectID int) (string, error) {
+	if settings := projectRepoSettings(projectID); settings != nil && settings.HooksScript != "" {
+		root, err := FindProjectRoot()
+		if err != nil {
+			return "", err
+		}
+		path, err := resolveProjectFile(root, settings.HooksScript)
+		if err != nil {
+			return "", fmt.Errorf("hook script: %w", err)
+		}
+		return path, nil
+	}
+	if config, err := LoadProjectConfig(projectID); err == nil && config.HooksScript != "" {
+		path, err := expandHome(config.HooksScript)
+		if err != nil || filepath.IsAbs(path) {
+			return path, err
+		}
+		root, err := FindProjectRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, path), nil
+	}
+
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, defaultHooksScript)
+	if _, err := os.Stat(path); err != nil {
+		return "", nil
+	}
+	return path, nil
+}
+
+// call runs the script and calls a hook function; found is false if the script does not define it
+func (h *scriptHooks) call(name string, args ...luaValue) (results []luaValue, found bool, err error) {
+	if h == nil {
+		return nil, false, nil
+	}
+	in := newLuaInterp(filepath.Base(h.path), h.print)
+	in.globals.set("relix", h.api())
+	if err := in.run(h.src); err != nil {
+		return nil, false, err
+	}
+	return in.callGlobal(name, args...)
+}
+
+// includeMR asks the include_mr hook whether an MR may be released; reason explains a veto
+func (h *scriptHooks) includeMR(mr *MergeRequestDetails) (ok bool, reason string, err error) {
+	results, found, err := h.call("include_mr", luaFromGo(mr))
+	if err != nil || !found || len(results) == 0 || results[0] != false {
+		return err == nil, "", err
+	}
+	reason = "rejected by include_mr"
+	if len(results) > 1 && results[1] != nil {
+		reason = luaToString(results[1])
+	}
+	return false, reason, nil
+}
+
+// applyPlan passes the plan through the plan hook
+func (h *scriptHooks) applyPlan(plan ReleasePlan) (ReleasePlan, error) {
+	table, ok := luaFromGo(plan).(*luaTable)
+	if !ok {
+		return plan, nil
+	}
+	results, found, err := h.call("plan", table)
+	if err != nil || !found {
+		return plan, err
+	}
+	if len(results) > 0 && results[0] != nil {
+		if table, ok = results[0].(*luaTable); !ok {
+			return plan, fmt.Errorf("plan hook returned a %s, expected a table", luaTypeName(results[0]))
+		}
+	}
+
+	var updated ReleasePlan
+	if err := luaToStruct(table, &updated); err != nil {
+		return plan, fmt.Errorf("plan hook: %w", err)
+	}
+	return updated, nil
+}
+
+// version asks the version hook for the version of a release; "" means the hook is not defined
+func (h *scriptHooks) version(env Environment, mrs []*MergeRequestDetails) (string, error) {
+	ctx := newLuaTable()
+	ctx.set("environment", env.Name)
+	ctx.set("env_branch", env.BranchName)
+	ctx.set("mrs", luaFromGo(mrs))
+	if last := lastReleasedVersion(env.Name); last != "" {
+		ctx.set("last_version", last)
+	}
+	results, found, err := h.call("version", ctx)
+	if err != nil || !found {
+		return "", err
+	}
+	version, ok := luaArg(results, 0).(string)
+	if !ok {
+		return "", fmt.Errorf("version hook returned a %s, expected a string", luaTypeName(luaArg(results, 0)))
+	}
+	if !validateVersion(version) {
+		return "", fmt.Errorf("version hook returned invalid version %q", version)
+	}
+	return version, nil
+}
+
+// api builds the relix table: read-only access to the forge and the release history
+func (h *scriptHooks) api() *luaTable {
+	return luaLib("relix", map[string]func(*luaInterp, []luaValue) []luaValue{
+		"merge_request": func(in *luaInterp, args []luaValue) []luaValue {
+			mr, err := h.forge.GetMergeRequestByIID(h.projectID, in.checkInt(args, 0, "merge_request"))
+			if err != nil {
+				in.raise(in.line, "%v", err)
+			}
+			return []luaValue{luaFromGo(mr)}
+		},
+		"pipelines": func(in *luaInterp, args []luaValue) []luaValue {
+			pipelines, err := h.forge.GetMergeRequestPipelines(h.projectID, in.checkInt(args, 0, "pipelines"))
+			if err != nil {
+				in.raise(in.line, "%v", err)
+			}
+			return []luaValue{luaFromGo(pipelines)}
+		},
+		"history": func(in *luaInterp, args []luaValue) []luaValue {
+			var env string
+			if luaArg(args, 0) != nil {
+				env = in.checkString(args, 0, "history")
+			}
+			return []luaValue{luaFromGo(releaseHistoryFor(env))}
+		},
+		"last_version": func(in *luaInterp, args []luaValue) []luaValue {
+			if last := lastReleasedVersion(in.checkString(args, 0, "last_version")); last != "" {
+				return []luaValue{last}
+			}
+			return []luaValue{nil}
+		},
+		"bump": func(in *luaInterp, args []luaValue) []luaValue {
+			version, err := bumpVersion(in.checkString(args, 0, "bump"), in.checkString(args, 1, "bump"))
+			if err != nil {
+				in.raise(in.line, "%v", err)
+			}
+			return []luaValue{version}
+		},
+	})
+}
+
+// releaseHistoryFor returns the releases to an environment (all if env is ""), newest first
+func releaseHistoryFor(env string) []HistoryIndexEntry {
+	entries, _ := LoadHistoryIndex()
+	var result []HistoryIndexEntry
+	for _, e := range entries {
+		if env == "" || strings.EqualFold(e.Environment, env) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// lastReleasedVersion returns the version of the newest completed release to an environment
+func lastReleasedVersion(env string) string {
+	for _, e := range releaseHistoryFor(env) {
+		if e.Status == "completed" {
+			return e.Version
+		}
+	}
+	return ""
+}
+
+// bumpVersion increments the major, minor, patch or build part of a version and resets the parts
+// after it, e.g. bumpVersion("1.2.3", "minor") is "1.3.0"
+func bumpVersion(version, part string) (string, error) {
+	index, ok := map[string]int{"major": 0, "minor": 1, "patch": 2, "build": 3}[part]
+	if !ok {
+		return "", fmt.Errorf("unknown version part %q (use major, minor, patch or build)", part)
+	}
+	if !versionRegex.MatchString(version) {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	parts := strings.Split(version, ".")
+	for len(parts) <= index {
+		parts = append(parts, "0")
+	}
+	for i := index; i < len(parts); i++ {
+		if i == index {
+			n, _ := strconv.Atoi(parts[i])
+			parts[i] = strconv.Itoa(n + 1)
+		} else {
+			parts[i] = "0"
+		}
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// luaFromGo converts a value to Lua through its JSON form, so scripts see the same field names
+// as the API and webhooks
+func luaFromGo(v any) luaValue {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return luaFromJSON(decoded)
+}
+
+func luaFromJSON(v any) luaValue {
+	switch v := v.(type) {
+	case map[string]any:
+		table := newLuaTable()
+		for key, value := range v {
+			table.set(key, luaFromJSON(value))
+		}
+		return table
+	case []any:
+		table := newLuaTable()
+		for i, value := range v {
+			table.set(float64(i+1), luaFromJSON(value))
+		}
+		return table
+	case nil, bool, float64, string:
+		return v
+	}
+	return nil
+}
+
+// luaToStruct decodes a Lua table into a Go struct through JSON
+func luaToStruct(table *luaTable, out any) error {
+	data, err := json.Marshal(luaToJSON(table))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// luaToJSON converts a Lua value for JSON encoding. Sequences become arrays, other tables
+// objects; empty tables become null, which decodes as an empty slice or struct.
+func luaToJSON(v luaValue) any {
+	switch v := v.(type) {
+	case *luaTable:
+		if len(v.items) == 0 {
+			return nil
+		}
+		if n := v.length(); n == len(v.items) {
+			array := make([]any, n)
+			for i := range array {
+				array[i] = luaToJSON(v.get(float64(i + 1)))
+			}
+			return array
+		}
+		object := map[string]any{}
+		for key, value := range v.items {
+			object[luaToString(key)] = luaToJSON(value)
+		}
+		return object
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil
+		}
+		return v
+	case nil, bool, string:
+		return v
+	}
+	return nil
+}
+
+// hookOutput prints the output of hook scripts run outside the TUI
+func hookOutput(line string) {
+	fmt.Fprintln(os.Stderr, "hooks: "+line)
+}
+
+// mrHookMsg carries the include_mr decision for an MR being selected
+type mrHookMsg struct {
+	iid    int
+	reason string
+	err    error
+}
+
+// selectMRWithHooks selects an MR unless the include_mr hook vetoes it
+func (m model) selectMRWithHooks(mr *MergeRequestDetails) tea.Cmd {
+	forge := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	return func() tea.Msg {
+		hooks, err := loadScriptHooks(forge, projectID, nil)
+		if err != nil {
+			return mrHookMsg{iid: mr.IID, err: err}
+		}
+		ok, reason, err := hooks.includeMR(mr)
+		if !ok && err == nil {
+			return mrHookMsg{iid: mr.IID, reason: reason}
+		}
+		return mrHookMsg{iid: mr.IID, err: err}
+	}
+}
+
+// handleMRHook selects the MR, or explains why the hook script or the blocklist keeps it out
+func (m *model) handleMRHook(msg mrHookMsg) {
+	blockReason, blocked := m.blockedMRs[msg.iid]
+	switch {
+	case msg.err != nil:
+		m.showErrorModal = true
+		m.errorModalMsg = "Hook script failed: " + msg.err.Error()
+	case msg.reason != "":
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("MR !%d cannot be released: %s", msg.iid, msg.reason)
+	case blocked:
+		// Blocked while the hook script ran
+		m.showErrorModal = true
+		m.errorModalMsg = blockedMRError(msg.iid, blockReason).Error()
+	default:
+		m.selectedMRs[msg.iid] = true
+		m.saveSelection()
+	}
+}
+
+// versionHookMsg carries the version computed by the version hook
+type versionHookMsg struct {
+	version string
+	err     error
+}
+
+// suggestVersion asks the version hook for the version of the release being prepared
+func (m model) suggestVersion() tea.Cmd {
+	if m.creds == nil || m.selectedProject == nil || m.selectedEnv == nil {
+		return nil
+	}
+	forge := NewForge(*m.creds)
+	projectID := m.selectedProject.ID
+	env := *m.selectedEnv
+	mrs := m.selectedMRDetails()
+	return func() tea.Msg {
+		hooks, err := loadScriptHooks(forge, projectID, nil)
+		if err != nil {
+			return versionHookMsg{err: err}
+		}
+		version, err := hooks.version(env, mrs)
+		return versionHookMsg{version: version, err: err}
+	}
+}
+
+// handleVersionHook fills in the suggested version unless one was typed meanwhile
+func (m *model) handleVersionHook(msg versionHookMsg) {
+	if msg.err != nil {
+		m.versionError = "Version hook: " + msg.err.Error()
+		return
+	}
+	if msg.version != "" && m.versionInput.Value() == "" {
+		m.versionInput.SetValue(msg.version)
+		m.versionInput.CursorEnd()
+	}
+}
+
+// loadedMRs returns the MRs of the list with the given IIDs, in that order
+func (m model) loadedMRs(iids []int) ([]*MergeRequestDetails, error) {
+	if len(iids) == 0 {
+		return nil, fmt.Errorf("plan hook: no merge requests selected")
+	}
+	byIID := make(map[int]*MergeRequestDetails)
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok {
+			byIID[mr.MR().IID] = mr.MR()
+		}
+	}
+	var mrs []*MergeRequestDetails
+	for _, iid := range iids {
+		mr, ok := byIID[iid]
+		if !ok {
+			return nil, fmt.Errorf("plan hook: MR !%d is not in the list of open MRs", iid)
+		}
+		mrs = append(mrs, mr)
+	}
+	return mrs, nil
+}
+
+// selectedMRDetails returns the selected MRs in list order
+func (m model) selectedMRDetails() []*MergeRequestDetails {
+	var mrs []*MergeRequestDetails
+	for _, item := range m.list.Items() {
+		if mr, ok := item.(mrListItem); ok && m.selectedMRs[mr.MR().IID] {
+			mrs = append(mrs, mr.MR())
+		}
+	}
+	return mrs
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Selection drafts: the MRs checked on the MR list are saved per project in ~/.relix/selections.json,
+// so a release candidate list built up over several days survives restarts. The draft is restored
+// when the project's MRs are listed again; MRs merged or closed meanwhile drop out of it. A
+// completed release clears the draft, an aborted one keeps it.
+
+const selectionsFileName = "selections.json"
+
+// selectionDraft is the saved MR selection of a project
+type selectionDraft struct {
+	IIDs      []int     `json:"iids"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// selectionsMu serializes access to the selections file (release tabs save their own drafts)
+var selectionsMu sync.Mutex
+
+// selectionDraftKey identifies a project's draft; project IDs are only unique per forge
+func selectionDraftKey(gitlabURL string, projectID int) string {
+	return fmt.Sprintf("%s#%d", strings.TrimRight(gitlabURL, "/"), projectID)
+}
+
+// getSelectionsPath returns the path of the selections file
+func getSelectionsPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, selectionsFileName), nil
+}
+
+// readSelectionDrafts returns the saved drafts by key; the caller holds selectionsMu
+func readSelectionDrafts() (map[string]selectionDraft, error) {
+	path, err := getSelectionsPath()
+	if err != nil {
+		return nil, err
+	}
+	drafts := make(map[string]selectionDraft)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return drafts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// loadSelectionDraft returns the IIDs of the MRs saved as selected for the project
+func loadSelectionDraft(gitlabURL string, projectID int) []int {
+	selectionsMu.Lock()
+	defer selectionsMu.Unlock()
+	drafts, err := readSelectionDrafts()
+	if err != nil {
+		return nil
+	}
+	return drafts[selectionDraftKey(gitlabURL, projectID)].IIDs
+}
+
+// saveSelectionDraft replaces the project's draft; an empty selection removes it
+func saveSelectionDraft(gitlabURL string, projectID int, iids []int) error {
+	selectionsMu.Lock()
+	defer selectionsMu.Unlock()
+	drafts, err := readSelectionDrafts()
+	if err != nil {
+		drafts = make(map[string]selectionDraft) // A broken file is replaced
+	}
+	key := selectionDraftKey(gitlabURL, projectID)
+	if len(iids) == 0 {
+		delete(drafts, key)
+	} else {
+		drafts[key] = selectionDraft{IIDs: iids, UpdatedAt: time.Now()}
+	}
+
+	path, err := getSelectionsPath()
+	if err != nil {
+		return err
+	}
+	if len(drafts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// applySelectionDraft restores the project's draft on the listed MRs. It is loaded once per
+// project; saved MRs not listed yet stay pending until a later page lists them or the complete,
+// fresh list shows they are no longer open.
+func (m *model) applySelectionDraft(listed []*MergeRequestDetails) {
+	if m.creds == nil || m.selectedProject == nil {
+		return
+	}
+	if !m.selectionDraftLoaded {
+		m.selectionDraftLoaded = true
+		m.selectionDraftPending = make(map[int]bool)
+		m.selectionDraftSaved = loadSelectionDraft(m.creds.GitLabURL, m.selectedProject.ID)
+		slices.Sort(m.selectionDraftSaved)
+		for _, iid := range m.selectionDraftSaved {
+			m.selectionDraftPending[iid] = true
+		}
+	}
+	if len(m.selectionDraftPending) == 0 {
+		return
+	}
+
+	for _, mr := range listed {
+		if m.selectionDraftPending[mr.IID] {
+			delete(m.selectionDraftPending, mr.IID)
+			if _, blocked := m.blockedMRs[mr.IID]; !mr.Draft && !blocked {
+				m.selectedMRs[mr.IID] = true
+			}
+		}
+	}
+
+	for _, mr := range m.mrsAll {
+		if m.selectionDraftPending[mr.IID] && mr.Draft {
+			delete(m.selectionDraftPending, mr.IID)
+		}
+	}
+	// Saved MRs missing from the complete, fresh list were merged or closed. MRs hidden by the
+	// filters of the config are still open.
+	if !m.mrPage.HasMore && !m.mrsCached && m.mrsStaleAt.IsZero() {
+		open := make(map[int]bool, len(m.mrsAll))
+		for _, mr := range m.mrsAll {
+			open[mr.IID] = true
+		}
+		for iid := range m.selectionDraftPending {
+			if !open[iid] {
+				delete(m.selectionDraftPending, iid)
+			}
+		}
+	}
+}
+
+// saveSelection saves the current selection with the draft MRs still pending, if it changed
+func (m *model) saveSelection() {
+	if m.creds == nil || m.selectedProject == nil || !m.selectionDraftLoaded {
+		return
+	}
+	iids := make([]int, 0, len(m.selectedMRs)+len(m.selectionDraftPending))
+	for iid := range m.selectedMRs {
+		iids = append(iids, iid)
+	}
+	for iid := range m.selectionDraftPending {
+		if !m.selectedMRs[iid] {
+			iids = append(iids, iid)
+		}
+	}
+	slices.Sort(iids)
+	if slices.Equal(iids, m.selectionDraftSaved) {
+		return
+	}
+	if err := saveSelectionDraft(m.creds.GitLabURL, m.selectedProject.ID, iids); err == nil {
+		m.selectionDraftSaved = iids
+	}
+}
+
+// clearSelectionDraft drops the project's draft once its MRs were released
+func (m *model) clearSelectionDraft() {
+	m.selectionDraftPending = nil
+	m.selectionDraftSaved = nil
+	if m.creds != nil && m.selectedProject != nil {
+		saveSelectionDraft(m.creds.GitLabURL, m.selectedProject.ID, nil)
+	}
+}
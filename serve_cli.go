@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// serveCommand starts the HTTP API server
+var serveCommand = &cliCommand{
+	Name:        "serve",
+	Summary:     "Serve an authenticated HTTP API for MRs, releases and history",
+	Description: "Starts an HTTP API that lists open MRs, starts releases from a JSON plan, streams their progress as server-sent events and serves the release history. A read-only spectator token lets teammates follow the release with relix spectate, and a sync token lets their relix push releases to the shared history. Every request must carry the token as \"Authorization: Bearer <token>\" or an access_token query parameter. With a webhook secret, signed Slack slash commands, GitLab MR comments and generic webhooks can start the pre-approved release plans from webhook_plans in the config and get progress posted back.",
+	Usage:       "[options]",
+	Examples: []string{
+		"RELIX_SERVE_TOKEN=secret relix serve",
+		"relix serve --addr :9000 --token secret --project-directory /path/to/project",
+		"curl -H 'Authorization: Bearer secret' localhost:8080/api/mrs",
+		"relix serve --token secret --webhook-secret hook-secret --addr :8080",
+	},
+	Env: []cliEnvVar{
+		{Name: "RELIX_SERVE_TOKEN", Description: "API bearer token used when --token is not given"},
+		{Name: "RELIX_SPECTATOR_TOKEN", Description: "Read-only spectator token used when --spectator-token is not given"},
+		{Name: "RELIX_SYNC_TOKEN", Description: "History sync token used when --sync-token is not given"},
+		{Name: "RELIX_WEBHOOK_SECRET", Description: "Webhook signing secret used when --webhook-secret is not given"},
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		addr := fs.String("addr", "127.0.0.1:8080", "Listen `address`")
+		token := fs.String("token", "", "API bearer `token` (default $RELIX_SERVE_TOKEN)")
+		spectatorToken := fs.String("spectator-token", "", "Read-only `token` that can only follow the release, e.g. with relix spectate (default $RELIX_SPECTATOR_TOKEN)")
+		syncToken := fs.String("sync-token", "", "`token` that can only push releases to the history and read the team activity, for history_sync in teammates' configs (default $RELIX_SYNC_TOKEN)")
+		webhookSecret := fs.String("webhook-secret", "", "Signing `secret` that enables /api/webhooks/* (default $RELIX_WEBHOOK_SECRET)")
+		projectID := fs.Int("project", 0, "GitLab project `id` (default: project selected in the TUI)")
+		projectDir := fs.String("project-directory", "", "Project root directory `path`")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+
+			if *token == "" {
+				*token = os.Getenv("RELIX_SERVE_TOKEN")
+			}
+			if *token == "" {
+				return errors.New("an API token is required (--token or RELIX_SERVE_TOKEN)")
+			}
+			if *spectatorToken == "" {
+				*spectatorToken = os.Getenv("RELIX_SPECTATOR_TOKEN")
+			}
+			if *spectatorToken != "" && *spectatorToken == *token {
+				return errors.New("the spectator token must differ from the API token")
+			}
+			if *syncToken == "" {
+				*syncToken = os.Getenv("RELIX_SYNC_TOKEN")
+			}
+			if *syncToken != "" && (*syncToken == *token || *syncToken == *spectatorToken) {
+				return errors.New("the sync token must differ from the API and spectator tokens")
+			}
+			if *webhookSecret == "" {
+				*webhookSecret = os.Getenv("RELIX_WEBHOOK_SECRET")
+			}
+
+			if err := setProjectDirectory(*projectDir); err != nil {
+				return err
+			}
+
+			creds, project, err := loadCLISession(*projectID)
+			if err != nil {
+				return err
+			}
+			if err := useRepoSettings(NewForge(*creds), project); err != nil {
+				return fmt.Errorf("project settings: %w", err)
+			}
+
+			// Releases of the server hold off teammates releasing from the TUI
+			releaseLocksAlways = true
+
+			// Output styles are rebuilt from the configured theme, as in the TUI
+			loadThemeFromConfig()
+
+			server := newAPIServer(*token, *spectatorToken, *syncToken, *webhookSecret, creds, project)
+			fmt.Fprintf(os.Stderr, "Serving relix API for project %d on http://%s\n", project, *addr)
+			return http.ListenAndServe(*addr, server.handler())
+		}
+	},
+}
+
+// setProjectDirectory validates a project directory path and sets it as the global project directory
+func setProjectDirectory(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid project directory path: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("project directory does not exist: %s", absPath)
+		}
+		return fmt.Errorf("cannot access project directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("specified path is not a directory: %s", absPath)
+	}
+
+	projectDirectory = absPath
+	return nil
+}
@@ -13,13 +13,15 @@ import (
 )
 
 // Settings tabs
-var settingsTabs = []string{"Release", "Theme"}
+var settingsTabs = []string{"Release", "Theme", "Keyring"}
 
 // Number of focusable elements per tab
 // 0=base branch, 1=env1 name, 2=env1 branch, 3=env2 name, 4=env2 branch,
-// 5=env3 name, 6=env3 branch, 7=env4 name, 8=env4 branch, 9=textarea, 10=pipeline regex, 11=save button
-const settingsReleaseFieldCount = 12
-const settingsThemeFieldCount = 2 // theme list, save button
+// 5=env3 name, 6=env3 branch, 7=env4 name, 8=env4 branch, 9=textarea, 10=pipeline regex,
+// 11=MR target branch, 12=MR source branch regex, 13=save button
+const settingsReleaseFieldCount = 14
+const settingsThemeFieldCount = 2   // theme list, save button
+const settingsKeyringFieldCount = 2 // backend list, save button
 
 // Default regex matching Package/Deploy jobs for known apps and environments
 const defaultPipelineJobsRegex = `(?i)(Package|Deploy) Application (Main|Admin|JudgePersonal|Touch) (for |to )?(dev|test|stage|prod)01`
@@ -33,12 +35,17 @@ var (
 
 // updateSettings handles key events on the settings screen
 func (m model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.showSettingsDiff {
+		return m.updateSettingsDiff(msg)
+	}
+
 	switch msg.String() {
 	case "esc", "ctrl+q":
 		// Close without saving; revert any unsaved theme preview
 		loadThemeFromConfig()
 		(&m).updateTextareaTheme()
-		m.screen = m.settingsPreviousScreen
+		(&m).applySpinnerTheme()
+		m.goBack()
 		m.settingsBaseBranch.Blur()
 		for i := 0; i < 4; i++ {
 			m.settingsEnvNames[i].Blur()
@@ -46,6 +53,8 @@ func (m model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.settingsExcludePatterns.Blur()
 		m.settingsPipelineRegex.Blur()
+		m.settingsMRTarget.Blur()
+		m.settingsMRSourceRegex.Blur()
 		m.settingsError = ""
 		m.settingsFocusIndex = 0
 		return m, nil
@@ -72,13 +81,21 @@ func (m model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.settingsExcludePatterns.Blur()
 			m.settingsPipelineRegex.Blur()
+			m.settingsMRTarget.Blur()
+			m.settingsMRSourceRegex.Blur()
 			m.settingsTab++
 			m.settingsFocusIndex = 0
-			if m.settingsTab == 1 {
+			var cmd tea.Cmd
+			switch m.settingsTab {
+			case 1:
 				m.loadSettingsThemes()
+			case 2:
+				// Check the backends each time the tab is opened: a keyring may have been unlocked meanwhile
+				m.settingsKeyringChecks = nil
+				cmd = diagnoseKeyrings()
 			}
 			(&m).initSettingsViewport()
-			return m, nil
+			return m, cmd
 		}
 		return m, nil
 	}
@@ -91,6 +108,8 @@ func (m model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		ret, cmd = m.updateSettingsRelease(msg)
 	case 1:
 		ret, cmd = m.updateSettingsTheme(msg)
+	case 2:
+		ret, cmd = m.updateSettingsKeyring(msg)
 	default:
 		return m, nil
 	}
@@ -114,7 +133,7 @@ func (m model) updateSettingsRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "down":
 		// Jump to same column in next row (skip textarea internals)
-		// 0=base → 1=env1 name; 1→3→5→7→9; 2→4→6→8→9; 9→break; 10→11
+		// 0=base → 1=env1 name; 1→3→5→7→9; 2→4→6→8→9; 9→break; 10→11→12→13
 		if m.settingsFocusIndex == 9 {
 			// textarea: move focus to next field if cursor is on last line
 			if m.settingsExcludePatterns.Line() >= m.settingsExcludePatterns.LineCount()-1 {
@@ -134,9 +153,9 @@ func (m model) updateSettingsRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.settingsFocusIndex += 2
 		case m.settingsFocusIndex%2 == 0 && m.settingsFocusIndex < 9: // env branch → next env branch
 			m.settingsFocusIndex += 2
-		case m.settingsFocusIndex == 10: // pipeline regex → save button
-			m.settingsFocusIndex = 11
-		case m.settingsFocusIndex == 11:
+		case m.settingsFocusIndex >= 10 && m.settingsFocusIndex < 13: // pipeline regex → MR target → MR source regex → save button
+			m.settingsFocusIndex++
+		case m.settingsFocusIndex == 13:
 			return m, nil
 		}
 		return m.updateSettingsFocus()
@@ -160,8 +179,8 @@ func (m model) updateSettingsRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.settingsFocusIndex = 0
 		case m.settingsFocusIndex == 10: // pipeline regex → textarea
 			m.settingsFocusIndex = 9
-		case m.settingsFocusIndex == 11: // save → pipeline regex
-			m.settingsFocusIndex = 10
+		case m.settingsFocusIndex > 10: // save → MR source regex → MR target → pipeline regex
+			m.settingsFocusIndex--
 		case m.settingsFocusIndex%2 == 1: // env name → prev env name
 			m.settingsFocusIndex -= 2
 		case m.settingsFocusIndex%2 == 0: // env branch → prev env branch
@@ -170,20 +189,11 @@ func (m model) updateSettingsRelease(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.updateSettingsFocus()
 
 	case "enter":
-		// If on save button (index 11), validate and save
-		if m.settingsFocusIndex == 11 {
+		// If on save button (index 13), validate and save
+		if m.settingsFocusIndex == 13 {
 			m.settingsError = m.validateReleaseSettings()
 			if m.settingsError == "" {
-				m.saveAllSettings()
-				m.screen = m.settingsPreviousScreen
-				m.settingsExcludePatterns.Blur()
-				m.settingsPipelineRegex.Blur()
-				m.settingsBaseBranch.Blur()
-				for i := 0; i < 4; i++ {
-					m.settingsEnvNames[i].Blur()
-					m.settingsEnvBranches[i].Blur()
-				}
-				m.settingsFocusIndex = 0
+				return m.reviewSettings()
 			}
 			return m, nil
 		}
@@ -220,6 +230,7 @@ func (m model) updateSettingsTheme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.settingsThemeIndex < len(m.settingsThemes) {
 				applyTheme(m.settingsThemes[m.settingsThemeIndex])
 				(&m).updateTextareaTheme()
+				(&m).applySpinnerTheme()
 			}
 		}
 		return m, nil
@@ -229,6 +240,7 @@ func (m model) updateSettingsTheme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.settingsThemeIndex++
 			applyTheme(m.settingsThemes[m.settingsThemeIndex])
 			(&m).updateTextareaTheme()
+			(&m).applySpinnerTheme()
 		} else if m.settingsFocusIndex == 0 && m.settingsThemeIndex >= len(m.settingsThemes)-1 {
 			// Past last theme → focus save button
 			m.settingsFocusIndex = 1
@@ -240,9 +252,7 @@ func (m model) updateSettingsTheme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.settingsFocusIndex == 1 {
 			m.settingsError = m.validatePatterns()
 			if m.settingsError == "" {
-				m.saveAllSettings()
-				m.screen = m.settingsPreviousScreen
-				m.settingsFocusIndex = 0
+				return m.reviewSettings()
 			}
 			return m, nil
 		}
@@ -252,6 +262,113 @@ func (m model) updateSettingsTheme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateSettingsKeyring handles key events on the Keyring settings tab
+func (m model) updateSettingsKeyring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choices := keyringChoices()
+	switch msg.String() {
+	case "tab":
+		m.settingsFocusIndex = (m.settingsFocusIndex + 1) % settingsKeyringFieldCount
+		return m, nil
+
+	case "shift+tab":
+		m.settingsFocusIndex = (m.settingsFocusIndex - 1 + settingsKeyringFieldCount) % settingsKeyringFieldCount
+		return m, nil
+
+	case "up", "k":
+		if m.settingsFocusIndex == 1 {
+			// Save button → back to backend list
+			m.settingsFocusIndex = 0
+		} else if m.settingsKeyringIndex > 0 {
+			m.settingsKeyringIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.settingsFocusIndex == 0 && m.settingsKeyringIndex < len(choices)-1 {
+			m.settingsKeyringIndex++
+		} else if m.settingsFocusIndex == 0 {
+			// Past last backend → focus save button
+			m.settingsFocusIndex = 1
+		}
+		return m, nil
+
+	case "r":
+		// Check the backends again, e.g. after unlocking the keyring
+		m.settingsKeyringChecks = nil
+		return m, diagnoseKeyrings()
+
+	case "enter":
+		if m.settingsFocusIndex == 0 {
+			m.settingsFocusIndex = 1
+			return m, nil
+		}
+		m.settingsError = m.validatePatterns()
+		if m.settingsError == "" {
+			return m.reviewSettings()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// loadSettingsKeyring points the backend list at the backend selected in config
+func (m *model) loadSettingsKeyring() {
+	m.settingsKeyringIndex = 0
+	current := configuredKeyringBackend()
+	for i, choice := range keyringChoices() {
+		if choice == current {
+			m.settingsKeyringIndex = i
+		}
+	}
+}
+
+// openSettings opens the settings screen with the saved settings
+func (m *model) openSettings() tea.Cmd {
+	m.settingsPreviousScreen = m.screen
+	m.settingsTab = 0
+	m.settingsFocusIndex = 0
+	m.loadSettingsKeyring()
+	if config, err := LoadConfig(); err == nil {
+		m.settingsExcludePatterns.SetValue(config.ExcludePatterns)
+		pipelineRegex := config.PipelineJobsRegex
+		if pipelineRegex == "" {
+			pipelineRegex = defaultPipelineJobsRegex
+		}
+		m.settingsPipelineRegex.SetValue(pipelineRegex)
+		m.settingsMRTarget.SetValue(config.MRTargetBranch)
+		m.settingsMRSourceRegex.SetValue(config.MRSourceBranchRegex)
+		// Load base branch
+		baseBranch := config.BaseBranch
+		if baseBranch == "" {
+			baseBranch = "root"
+		}
+		m.settingsBaseBranch.SetValue(baseBranch)
+		// Load environment settings
+		envs := config.Environments
+		if len(envs) == 0 {
+			envs = defaultEnvironments()
+		}
+		for i := 0; i < 4 && i < len(envs); i++ {
+			m.settingsEnvNames[i].SetValue(strings.ToUpper(envs[i].Name))
+			m.settingsEnvBranches[i].SetValue(envs[i].BranchName)
+		}
+	}
+	m.updateTextareaTheme()
+	m.moveToScreen(screenSettings)
+	m.initSettingsViewport()
+	return m.settingsBaseBranch.Focus()
+}
+
+// settingsKeyringChoice returns the backend selected on the Keyring tab
+func (m model) settingsKeyringChoice() string {
+	choices := keyringChoices()
+	if m.settingsKeyringIndex < len(choices) {
+		return choices[m.settingsKeyringIndex]
+	}
+	return keyringAuto
+}
+
 // loadSettingsThemes reloads themes from config file
 func (m *model) loadSettingsThemes() {
 	config, err := LoadConfig()
@@ -315,6 +432,8 @@ func (m *model) updateTextareaTheme() {
 	}
 	updateInputTheme(&m.settingsBaseBranch)
 	updateInputTheme(&m.settingsPipelineRegex)
+	updateInputTheme(&m.settingsMRTarget)
+	updateInputTheme(&m.settingsMRSourceRegex)
 	for i := 0; i < 4; i++ {
 		updateInputTheme(&m.settingsEnvNames[i])
 		updateInputTheme(&m.settingsEnvBranches[i])
@@ -378,6 +497,17 @@ func (m model) updateSettingsReleaseInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.settingsError = ""
 		}
+	case 11: // MR target branch
+		m.settingsMRTarget, cmd = m.settingsMRTarget.Update(msg)
+	case 12: // MR source branch regex
+		m.settingsMRSourceRegex, cmd = m.settingsMRSourceRegex.Update(msg)
+		// Live regex validation
+		m.settingsError = ""
+		if regexStr := strings.TrimSpace(m.settingsMRSourceRegex.Value()); regexStr != "" {
+			if _, err := regexp.Compile(regexStr); err != nil {
+				m.settingsError = "Source branch regex: " + err.Error()
+			}
+		}
 	}
 	return m, cmd
 }
@@ -392,6 +522,8 @@ func (m model) updateSettingsFocus() (tea.Model, tea.Cmd) {
 	}
 	m.settingsExcludePatterns.Blur()
 	m.settingsPipelineRegex.Blur()
+	m.settingsMRTarget.Blur()
+	m.settingsMRSourceRegex.Blur()
 
 	// Focus the right input
 	switch m.settingsFocusIndex {
@@ -407,8 +539,12 @@ func (m model) updateSettingsFocus() (tea.Model, tea.Cmd) {
 		return m, m.settingsExcludePatterns.Focus()
 	case 10:
 		return m, m.settingsPipelineRegex.Focus()
+	case 11:
+		return m, m.settingsMRTarget.Focus()
+	case 12:
+		return m, m.settingsMRSourceRegex.Focus()
 	}
-	// Index 11 = save button, nothing to focus
+	// Index 13 = save button, nothing to focus
 	return m, nil
 }
 
@@ -520,37 +656,76 @@ func (m *model) validateReleaseSettings() string {
 		}
 	}
 
+	// Validate MR filters
+	if strings.Contains(strings.TrimSpace(m.settingsMRTarget.Value()), " ") {
+		return "Target branch cannot contain spaces"
+	}
+	if regexStr := strings.TrimSpace(m.settingsMRSourceRegex.Value()); regexStr != "" {
+		if _, err := regexp.Compile(regexStr); err != nil {
+			return "Source branch regex: " + err.Error()
+		}
+	}
+
 	return ""
 }
 
-// saveAllSettings saves all settings across tabs to config file
-func (m *model) saveAllSettings() {
-	config, err := LoadConfig()
-	if err != nil {
-		config = &AppConfig{}
+// applySettings sets the settings of all tabs in config. The credentials are moved to a newly
+// selected keyring when the config is written (see saveReviewedSettings).
+func (m model) applySettings(config *AppConfig) {
+	// Keyring tab: backend
+	if backend := m.settingsKeyringChoice(); backend != configuredKeyringBackend() {
+		config.KeyringBackend = backend
+		if backend == keyringAuto {
+			config.KeyringBackend = ""
+		}
 	}
 	// Release tab: base branch
 	config.BaseBranch = strings.TrimSpace(m.settingsBaseBranch.Value())
-	// Release tab: environments
+	// Release tab: environments (version format rules are edited in the config file and kept)
+	previous := config.Environments
 	config.Environments = make([]EnvConfig, 4)
 	for i := 0; i < 4; i++ {
-		config.Environments[i] = EnvConfig{
-			Name:       strings.TrimSpace(m.settingsEnvNames[i].Value()),
-			BranchName: strings.TrimSpace(m.settingsEnvBranches[i].Value()),
+		if i < len(previous) {
+			config.Environments[i] = previous[i]
 		}
+		config.Environments[i].Name = strings.TrimSpace(m.settingsEnvNames[i].Value())
+		config.Environments[i].BranchName = strings.TrimSpace(m.settingsEnvBranches[i].Value())
 	}
 	// Release tab: exclude patterns
 	config.ExcludePatterns = m.settingsExcludePatterns.Value()
 	// Release tab: pipeline jobs regex
 	config.PipelineJobsRegex = strings.TrimSpace(m.settingsPipelineRegex.Value())
+	// Release tab: MR filters
+	config.MRTargetBranch = strings.TrimSpace(m.settingsMRTarget.Value())
+	config.MRSourceBranchRegex = strings.TrimSpace(m.settingsMRSourceRegex.Value())
 	// Theme tab: selected theme
 	if m.settingsThemeIndex < len(m.settingsThemes) {
 		config.SelectedTheme = m.settingsThemes[m.settingsThemeIndex].Name
 	}
-	SaveConfig(config)
+}
 
+// closeSavedSettings leaves the settings screen once they were saved, reloading the MR list if
+// the config changed
+func (m model) closeSavedSettings(changed bool) (tea.Model, tea.Cmd) {
 	// Rebuild runtime environments from saved config
 	m.environments = getEnvironments()
+
+	m.goBack()
+	m.settingsBaseBranch.Blur()
+	for i := 0; i < 4; i++ {
+		m.settingsEnvNames[i].Blur()
+		m.settingsEnvBranches[i].Blur()
+	}
+	m.settingsExcludePatterns.Blur()
+	m.settingsPipelineRegex.Blur()
+	m.settingsMRTarget.Blur()
+	m.settingsMRSourceRegex.Blur()
+	m.settingsFocusIndex = 0
+	if changed && m.screen == screenMain {
+		// Apply changed MR filters to the list
+		return m, (&m).loadMRs()
+	}
+	return m, nil
 }
 
 // settingsContentWidth returns the usable content width inside the settings screen
@@ -591,10 +766,14 @@ func (m *model) refreshSettingsViewport() {
 	contentWidth := m.settingsContentWidth()
 	m.settingsBaseBranch.Width = contentWidth - 2
 	m.settingsPipelineRegex.Width = contentWidth - 2
+	m.settingsMRTarget.Width = contentWidth - 2
+	m.settingsMRSourceRegex.Width = contentWidth - 2
 	// Recompute visible text range for the new Width (textinput only
 	// recalculates offset/offsetRight during SetValue or SetCursor)
 	m.settingsBaseBranch.SetCursor(m.settingsBaseBranch.Position())
 	m.settingsPipelineRegex.SetCursor(m.settingsPipelineRegex.Position())
+	m.settingsMRTarget.SetCursor(m.settingsMRTarget.Position())
+	m.settingsMRSourceRegex.SetCursor(m.settingsMRSourceRegex.Position())
 
 	switch m.settingsTab {
 	case 0:
@@ -604,6 +783,8 @@ func (m *model) refreshSettingsViewport() {
 	case 1:
 		content := m.renderThemeSettings()
 		m.settingsViewport.SetContent(content)
+	case 2:
+		m.settingsViewport.SetContent(m.renderKeyringSettings())
 	}
 }
 
@@ -704,9 +885,12 @@ func (m model) viewSettings() string {
 
 	// Help footer
 	var helpText string
-	if m.settingsTab == 1 {
+	switch m.settingsTab {
+	case 1:
 		helpText = "j/k: nav • tab: focus • enter: save • H/L: switch tab • esc/C+q: back"
-	} else {
+	case 2:
+		helpText = "j/k: nav • r: recheck • tab: focus • enter: save • H/L: switch tab • esc/C+q: back"
+	default:
 		helpText = "tab: focus • enter: save • H/L: switch tab • esc/C+q: back"
 	}
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
@@ -796,6 +980,20 @@ func (m model) renderReleaseSettings() releaseSettingsResult {
 	fl[10] = [2]int{line, line} // pipeline regex input
 	write(m.settingsPipelineRegex.View())
 
+	// --- MR filters ---
+	write("\n\n")
+	write(settingsLabelStyle.Render("Merge request filters"))
+	write("\n")
+	desc3 := "Only list MRs targeting this branch, and whose source branch matches the regex (Go regexp syntax, e.g. ^feature/). Leave empty to list all open MRs."
+	write(helpStyle.Width(contentWidth).Render(desc3))
+	write("\n")
+
+	fl[11] = [2]int{line, line} // MR target branch input
+	write(m.settingsMRTarget.View())
+	write("\n")
+	fl[12] = [2]int{line, line} // MR source branch regex input
+	write(m.settingsMRSourceRegex.View())
+
 	// Error hint
 	if m.settingsError != "" {
 		write("\n")
@@ -804,10 +1002,10 @@ func (m model) renderReleaseSettings() releaseSettingsResult {
 
 	// Save button (centered)
 	write("\n\n")
-	fl[11] = [2]int{line, line} // save button
+	fl[13] = [2]int{line, line} // save button
 	buttonText := "Save and close"
 	var btnStyle lipgloss.Style
-	if m.settingsFocusIndex == 11 && m.settingsError == "" {
+	if m.settingsFocusIndex == 13 && m.settingsError == "" {
 		btnStyle = buttonActiveStyle
 	} else {
 		btnStyle = buttonStyle
@@ -980,6 +1178,82 @@ func (m model) renderThemeSettings() string {
 	return b.String()
 }
 
+// renderKeyringSettings renders the Keyring tab content
+func (m model) renderKeyringSettings() string {
+	var b strings.Builder
+
+	b.WriteString(settingsLabelStyle.Render("Credential storage"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Saved as keyring_backend in ~/.relix/config.json; stored credentials are moved on save"))
+	b.WriteString("\n\n")
+
+	current := configuredKeyringBackend()
+	detailStyle := lipgloss.NewStyle().Foreground(currentTheme.Notion)
+	for i, choice := range keyringChoices() {
+		name := keyringBackendLabel(choice)
+		if choice == current {
+			name += " (in use)"
+		}
+
+		status := "checking…"
+		if diagnosis, ok := m.settingsKeyringChecks[resolveKeyringBackend(choice)]; ok {
+			switch {
+			case diagnosis.err != nil:
+				status = "unavailable"
+			case diagnosis.hasCredentials:
+				status = "available, credentials stored"
+			default:
+				status = "available"
+			}
+		}
+
+		if i == m.settingsKeyringIndex {
+			prefix := lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render("> ")
+			b.WriteString(prefix + lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render(name))
+		} else {
+			b.WriteString("  " + lipgloss.NewStyle().Foreground(currentTheme.Foreground).Render(name))
+		}
+		b.WriteString(detailStyle.Render(" · " + status))
+		b.WriteString("\n")
+	}
+
+	// What is wrong with the highlighted backend and how to fix it
+	contentWidth := m.settingsContentWidth()
+	choice := resolveKeyringBackend(m.settingsKeyringChoice())
+	if diagnosis, ok := m.settingsKeyringChecks[choice]; ok && diagnosis.err != nil {
+		b.WriteString("\n")
+		b.WriteString(settingsErrorStyle.Width(contentWidth).Render(diagnosis.err.Error()))
+		b.WriteString("\n")
+	}
+	if choice == keyringFile {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Width(contentWidth).Render("The file backend keeps the token unencrypted in ~/.relix/" + credentialsFileName + ", readable only by you. Use it where no OS keyring is available."))
+		b.WriteString("\n")
+	}
+
+	if m.settingsError != "" {
+		b.WriteString("\n")
+		b.WriteString(settingsErrorStyle.Width(contentWidth).Render(m.settingsError))
+		b.WriteString("\n")
+	}
+
+	// Save button (centered)
+	b.WriteString("\n")
+	var btnStyle lipgloss.Style
+	if m.settingsFocusIndex == 1 {
+		btnStyle = buttonActiveStyle
+	} else {
+		btnStyle = buttonStyle
+	}
+	button := btnStyle.Render("Save and close")
+	if padding := (contentWidth - lipgloss.Width(button)) / 2; padding > 0 {
+		b.WriteString(strings.Repeat(" ", padding))
+	}
+	b.WriteString(button)
+
+	return b.String()
+}
+
 // renderColumnList renders entries in an adaptive multi-column layout.
 // If entries fit in targetRows as a single column, they are listed one per line.
 // Otherwise they wrap into multiple columns (filled top-to-bottom) with a vertical divider.
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Leaving the TUI: Ctrl+Z suspends relix like any other terminal program, and "!" on the release
+// screen (or "shell" in the command menu) opens a shell in the release's working copy, e.g. to
+// resolve a conflict or inspect branches by hand. Exiting the shell returns to the release.
+
+// shellExitedMsg reports the end of a shell opened from the TUI
+type shellExitedMsg struct {
+	dir string
+	err error
+}
+
+// userShell returns the user's login shell, or the platform default
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		if comspec := os.Getenv("COMSPEC"); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	return "sh"
+}
+
+// shellCommand runs a shell for tea.Exec, telling first how to get back to relix
+type shellCommand struct {
+	cmd    *exec.Cmd
+	banner string
+	wait   bool // Wait for Enter after the command, so its output can be read before relix returns
+}
+
+// SetStdin, SetStdout and SetStderr connect the shell to the terminal released by Bubble Tea
+func (c *shellCommand) SetStdin(r io.Reader)  { c.cmd.Stdin = r }
+func (c *shellCommand) SetStdout(w io.Writer) { c.cmd.Stdout = w }
+func (c *shellCommand) SetStderr(w io.Writer) { c.cmd.Stderr = w }
+
+// Run prints the banner and runs the shell until it exits
+func (c *shellCommand) Run() error {
+	if c.cmd.Stdout != nil {
+		fmt.Fprint(c.cmd.Stdout, c.banner)
+	}
+	err := c.cmd.Run()
+	if c.wait && c.cmd.Stdout != nil && c.cmd.Stdin != nil {
+		fmt.Fprint(c.cmd.Stdout, "\nrelix: press Enter to return to relix.")
+		bufio.NewReader(c.cmd.Stdin).ReadString('\n')
+	}
+	return err
+}
+
+// shellWorkDir returns the directory a shell opens in: the working copy of the release, or the
+// selected project's when no release is in progress
+func (m model) shellWorkDir() (string, error) {
+	if m.releaseState != nil && m.releaseState.WorkDir != "" {
+		return m.releaseState.WorkDir, nil
+	}
+	return projectWorkDir(m.selectedProject)
+}
+
+// openShell leaves the TUI for a shell in the release's working copy. Not while a step runs,
+// as its git commands would race the ones typed by hand.
+func (m *model) openShell() tea.Cmd {
+	m.closeAllModals()
+	if m.releaseRunning {
+		m.showErrorModal = true
+		m.errorModalMsg = "Wait for the running release step to finish before opening a shell"
+		return nil
+	}
+	dir, err := m.shellWorkDir()
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Cannot open a shell: " + err.Error()
+		return nil
+	}
+
+	cmd := exec.Command(userShell())
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "RELIX_SHELL=1") // Lets prompts show that relix is waiting
+	if m.releaseState != nil {
+		cmd.Env = append(releaseEnviron(releaseVariables(m.releaseState)), "RELIX_SHELL=1")
+	}
+	banner := fmt.Sprintf("relix: shell in %s. Exit it to return to relix.\n", dir)
+	return tea.Exec(&shellCommand{cmd: cmd, banner: banner}, func(err error) tea.Msg {
+		return shellExitedMsg{dir: dir, err: err}
+	})
+}
+
+// handleShellExited notes the shell in the release output, and shows why a shell could not start.
+// The exit status of the shell is that of the last command typed, so it is not an error.
+func (m *model) handleShellExited(msg shellExitedMsg) {
+	var exitErr *exec.ExitError
+	if msg.err != nil && !errors.As(msg.err, &exitErr) {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = "Shell failed: " + msg.err.Error()
+		return
+	}
+	if m.releaseState != nil {
+		m.appendReleaseOutput("")
+		m.appendReleaseOutput(commandLogStyle.Render("Returned from the shell in " + msg.dir))
+	}
+}
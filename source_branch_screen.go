@@ -62,7 +62,7 @@ func (m model) updateSourceBranch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+q":
 		// Go back to version input
-		m.screen = screenVersion
+		m.goBack()
 		m.sourceBranchError = ""
 		return m, nil
 	case "enter":
@@ -78,7 +78,7 @@ func (m model) updateSourceBranch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		// Branch name is valid - proceed to env merge screen
 		m.sourceBranchError = ""
-		m.screen = screenEnvMerge
+		m.moveToScreen(screenEnvMerge)
 		// Reset commit count so it recalculates if env/version/branch changed
 		m.envMergeCommitCount = 0
 		m.envMergeCountLoading = true
@@ -228,9 +228,10 @@ func (m model) renderSourceBranchStatus() string {
 
 // checkSourceBranchRemote performs an async check for the remote branch
 func (m *model) checkSourceBranchRemote(branchName string) tea.Cmd {
+	project := m.selectedProject
 	return func() tea.Msg {
-		// Get project directory (respects -d flag)
-		workDir, err := FindProjectRoot()
+		// Get project directory (respects project_dirs and the -d flag)
+		workDir, err := projectWorkDir(project)
 		if err != nil {
 			return sourceBranchCheckMsg{
 				branchName: branchName,
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// spectateCommand follows the release of a relix serve instance read-only
+var spectateCommand = &cliCommand{
+	Name:        "spectate",
+	Summary:     "Follow the release of a relix server read-only",
+	Description: "Attaches to the release running on a relix serve instance and prints its terminal output and step changes live, starting with the output so far. It only reads, so the spectator token of the server is enough; useful for pairing on risky production releases. Exits when the release finishes, with an error if it failed.",
+	Usage:       "[options]",
+	Examples: []string{
+		"relix spectate --server http://relix.internal:8080 --token viewer-secret",
+		"RELIX_SERVER=http://relix.internal:8080 RELIX_SPECTATOR_TOKEN=viewer-secret relix spectate",
+	},
+	Env: []cliEnvVar{
+		{Name: "RELIX_SERVER", Description: "Server URL used when --server is not given"},
+		{Name: "RELIX_SPECTATOR_TOKEN", Description: "Token used when --token is not given (the API token works too)"},
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		server := fs.String("server", "", "Server `url` (default $RELIX_SERVER or http://127.0.0.1:8080)")
+		token := fs.String("token", "", "Spectator or API `token` (default $RELIX_SPECTATOR_TOKEN)")
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			if *server == "" {
+				*server = os.Getenv("RELIX_SERVER")
+			}
+			if *server == "" {
+				*server = "http://127.0.0.1:8080"
+			}
+			if *token == "" {
+				*token = os.Getenv("RELIX_SPECTATOR_TOKEN")
+			}
+			if *token == "" {
+				return errors.New("a token is required (--token or RELIX_SPECTATOR_TOKEN)")
+			}
+			return spectateRelease(os.Stdout, strings.TrimSuffix(*server, "/"), *token)
+		}
+	},
+}
+
+// spectateRelease streams the release events of a server to w until the release finishes
+func spectateRelease(w io.Writer, server, token string) error {
+	req, err := http.NewRequest("GET", server+"/api/release/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "text/event-stream")
+
+	// No client timeout: the stream lasts as long as the release
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error != "" {
+			return fmt.Errorf("server: %s", apiErr.Error)
+		}
+		return fmt.Errorf("server error: status %d", resp.StatusCode)
+	}
+
+	var lastStep string
+	var final *releaseRunStatus
+	err = readSSE(resp.Body, func(event string, data []byte) {
+		switch event {
+		case "output":
+			var out struct {
+				Line string `json:"line"`
+			}
+			if json.Unmarshal(data, &out) == nil {
+				fmt.Fprintln(w, out.Line)
+			}
+		case "progress", "done":
+			var status releaseRunStatus
+			if json.Unmarshal(data, &status) != nil {
+				return
+			}
+			if status.Step != lastStep {
+				lastStep = status.Step
+				fmt.Fprintf(w, "» %s (%d/%d)\n", status.Step, status.Completed, status.Total)
+			}
+			if event == "done" {
+				final = &status
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("network error: %w", err)
+	}
+	if final == nil {
+		return errors.New("the server closed the stream before the release finished")
+	}
+
+	fmt.Fprintf(w, "Release %s: %s\n", final.ID, final.Status)
+	if final.Status == "failed" {
+		return fmt.Errorf("release failed: %s", final.Error)
+	}
+	return nil
+}
+
+// readSSE calls fn for each server-sent event read from r
+func readSSE(r io.Reader, fn func(event string, data []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20) // Output lines can be long
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				fn(event, []byte(strings.Join(data, "\n")))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return scanner.Err()
+}
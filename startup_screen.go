@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --history and --settings open the TUI on that screen instead of the home screen, for shell
+// aliases that always start in the same place. --project <path> selects the project with that
+// path and opens its MRs, in place of the project detected from the working directory; along with
+// --history or --settings it is selected without leaving that screen. A release in progress is
+// resumed regardless of them.
+
+// startupScreen is the screen the TUI opens on (set via --history or --settings): "history",
+// "settings" or "" for the home screen
+var startupScreen string
+
+// startupProjectPath is the path of the project to select on startup (set via --project)
+var startupProjectPath string
+
+// setStartupScreen validates and sets the screen and project of the startup flags
+func setStartupScreen(history, settings bool, project string) error {
+	if history && settings {
+		return fmt.Errorf("--history and --settings cannot be combined")
+	}
+	switch {
+	case history:
+		startupScreen = "history"
+	case settings:
+		startupScreen = "settings"
+	}
+	startupProjectPath = strings.Trim(project, "/")
+	return nil
+}
+
+// lookupStartupProject looks up the project given with --project
+func lookupStartupProject(creds Credentials, path string) tea.Cmd {
+	return func() tea.Msg {
+		forge := NewForge(creds)
+		if client, ok := forge.(*GitLabClient); ok {
+			project, err := client.GetProjectByPath(path)
+			return startupProjectMsg{project: project, requested: path, err: err}
+		}
+		projects, err := forge.GetProjects()
+		if err != nil {
+			return startupProjectMsg{requested: path, err: err}
+		}
+		for i := range projects {
+			if strings.EqualFold(projects[i].PathWithNamespace, path) {
+				return startupProjectMsg{project: &projects[i], requested: path}
+			}
+		}
+		return startupProjectMsg{requested: path, err: fmt.Errorf("no project found")}
+	}
+}
+
+// openStartupScreen opens the screen and project of the startup flags, once; without --project
+// the project of the working directory is detected
+func (m *model) openStartupScreen() tea.Cmd {
+	screen, path := startupScreen, startupProjectPath
+	startupScreen, startupProjectPath = "", ""
+
+	projectCmd := detectStartupProject(*m.creds)
+	if path != "" {
+		projectCmd = lookupStartupProject(*m.creds, path)
+	}
+	switch screen {
+	case "history":
+		return tea.Batch(m.openHistoryList(), projectCmd)
+	case "settings":
+		return tea.Batch(m.openSettings(), projectCmd)
+	}
+	return projectCmd
+}
+
+// handleRequestedProject selects the project given with --project, and opens its MRs unless
+// another screen was asked for
+func (m *model) handleRequestedProject(msg startupProjectMsg) tea.Cmd {
+	if m.releaseState != nil {
+		return nil
+	}
+	if msg.err != nil {
+		m.closeAllModals()
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("--project %s: %v", msg.requested, msg.err)
+		return nil
+	}
+	cmd := m.selectProject(*msg.project)
+	if m.screen == screenHome {
+		m.moveToScreen(screenMain)
+	}
+	return cmd
+}
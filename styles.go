@@ -118,6 +118,17 @@ var (
 	homeVersionStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("60"))
 
+	// Release tab bar styles
+	releaseTabActiveStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(defaultThemeColors.AccentForeground).
+				Background(lipgloss.Color("62")).
+				Padding(0, 1)
+
+	releaseTabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("60")).
+			Padding(0, 1)
+
 	// History screen styles
 	historyTabActiveStyle = lipgloss.NewStyle().
 				Bold(true).
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Telegram notifications can also confirm gated steps. When a release waits for "Create MR" or
+// "Push root branches", the waiting message carries an Approve button; the model long-polls the
+// bot for the button press and, if an allowed user pressed it, runs the step as if the button was
+// pressed in the TUI. Pressing the button locally (or aborting) stops the polling. Only bots with
+// approvers offer the button: anyone in a chat is not trusted with a release.
+const (
+	telegramPollTimeout  = 25 * time.Second // getUpdates long-poll timeout
+	telegramRetryDelay   = 5 * time.Second  // Wait after a failed poll
+	telegramApprovalData = "approve:"       // Callback data prefix, followed by the gate
+)
+
+// telegramAPIURL is the Bot API endpoint
+var telegramAPIURL = "https://api.telegram.org"
+
+// telegramClient outlives the long-poll timeout
+var telegramClient = &http.Client{Timeout: telegramPollTimeout + 15*time.Second}
+
+// telegramOffsets holds the next update to read per bot token, so later approvals skip
+// callbacks already handled
+var (
+	telegramOffsetsMu sync.Mutex
+	telegramOffsets   = map[string]int64{}
+)
+
+// remoteApproval is a gated step waiting for a Telegram approval
+type remoteApproval struct {
+	gate   string
+	step   ReleaseStep
+	cancel context.CancelFunc
+}
+
+// remoteApprovalMsg is sent when a gated step was approved remotely, or the bot could not be polled
+type remoteApprovalMsg struct {
+	gate string
+	by   string
+	err  error
+}
+
+// telegramUser is the sender of a callback
+type telegramUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// telegramUpdate is an update from getUpdates; only button presses are requested
+type telegramUpdate struct {
+	UpdateID      int64 `json:"update_id"`
+	CallbackQuery *struct {
+		ID      string       `json:"id"`
+		From    telegramUser `json:"from"`
+		Data    string       `json:"data"`
+		Message *struct {
+			MessageID int64 `json:"message_id"`
+			Chat      struct {
+				ID       int64  `json:"id"`
+				Username string `json:"username"`
+			} `json:"chat"`
+		} `json:"message"`
+	} `json:"callback_query"`
+}
+
+// telegramError is an error reported by the Bot API
+type telegramError struct {
+	method      string
+	code        int
+	description string
+}
+
+func (e *telegramError) Error() string {
+	return fmt.Sprintf("telegram %s: %s", e.method, e.description)
+}
+
+// permanent reports whether retrying cannot help (wrong token or chat)
+func (e *telegramError) permanent() bool {
+	return e.code == 400 || e.code == 401 || e.code == 403 || e.code == 404
+}
+
+// telegramCall calls a Bot API method with JSON params and decodes its result into result (may be nil)
+func telegramCall(ctx context.Context, token, method string, params, result interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", telegramAPIURL+"/bot"+token+"/"+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := telegramClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("telegram %s: status %d", method, resp.StatusCode)
+	}
+	if !body.OK {
+		return &telegramError{method: method, code: body.ErrorCode, description: body.Description}
+	}
+	if result != nil {
+		return json.Unmarshal(body.Result, result)
+	}
+	return nil
+}
+
+// telegramNotifier posts to a chat through a bot
+type telegramNotifier struct {
+	botToken  string
+	chatID    string
+	approvals bool // Waiting messages carry the Approve button (approvers are configured)
+}
+
+func (t telegramNotifier) send(event ReleaseEvent) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s <b>%s</b>\n", eventEmoji(event.Kind), html.EscapeString(event.title()))
+	if event.Reason != "" {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(event.Reason))
+	}
+	if event.Rollout != nil {
+		fmt.Fprintf(&b, "Rollout: %s\n", html.EscapeString(event.Rollout.String()))
+	}
+	fmt.Fprintf(&b, "MRs: %d", len(event.MRBranches))
+	for _, link := range event.links() {
+		fmt.Fprintf(&b, "\n• <a href=\"%s\">%s</a>", html.EscapeString(link[1]), html.EscapeString(link[0]))
+	}
+
+	params := map[string]interface{}{
+		"chat_id":                  t.chatID,
+		"text":                     b.String(),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	if event.Gate != "" && t.approvals {
+		params["reply_markup"] = map[string]interface{}{
+			"inline_keyboard": [][]map[string]string{{{
+				"text":          "✅ Approve: " + event.Reason,
+				"callback_data": telegramApprovalData + event.Gate,
+			}}},
+		}
+	}
+	return telegramCall(context.Background(), t.botToken, "sendMessage", params, nil)
+}
+
+// requestRemoteApproval announces that the release waits at step (described by action) and,
+// if a Telegram bot is configured for waiting events, listens for its approval.
+// Headless releases do not wait, so nothing is sent.
+func (m *model) requestRemoteApproval(step ReleaseStep, action string) tea.Cmd {
+	if m.headless || m.releaseState == nil {
+		return nil
+	}
+	m.cancelRemoteApproval()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gate := strconv.FormatInt(time.Now().UnixNano(), 36) // Callback data is limited to 64 bytes
+	m.remoteApproval = &remoteApproval{gate: gate, step: step, cancel: cancel}
+
+	event := m.releaseEvent(releaseEventWaiting, "Next step: "+action)
+	event.Gate = gate
+	return tea.Batch(sendReleaseEvent(event), listenRemoteApproval(ctx, event))
+}
+
+// cancelRemoteApproval stops listening for the approval of the current gate
+func (m *model) cancelRemoteApproval() {
+	if m.remoteApproval != nil {
+		m.remoteApproval.cancel()
+		m.remoteApproval = nil
+	}
+}
+
+// handleRemoteApproval runs the approved step if the release still waits at it
+func (m *model) handleRemoteApproval(msg remoteApprovalMsg) (tea.Model, tea.Cmd) {
+	if m.remoteApproval == nil || msg.gate != m.remoteApproval.gate || m.releaseState == nil {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.appendReleaseOutput(lipgloss.NewStyle().Foreground(currentTheme.Error).Render("WARNING: remote approval unavailable: " + msg.err.Error()))
+		m.cancelRemoteApproval()
+		return m, nil
+	}
+	step := m.remoteApproval.step
+	m.cancelRemoteApproval()
+	if m.releaseRunning || m.releaseState.LastError != nil || m.releaseState.CurrentStep != step {
+		return m, nil
+	}
+
+	m.appendReleaseOutput("")
+	m.appendReleaseOutput(fmt.Sprintf("Approved in Telegram by %s", msg.by))
+	m.remoteApprovedBy = msg.by
+	switch step {
+	case ReleaseStepWaitForMR:
+		return m.startCreateMR()
+	case ReleaseStepWaitForRootPush:
+		return m.startPushRootBranches()
+	}
+	return m, nil
+}
+
+// listenRemoteApproval returns a command that waits for an approval of the event's gate from any
+// Telegram bot configured for waiting events. It returns nil if there is none or ctx is canceled.
+func listenRemoteApproval(ctx context.Context, event ReleaseEvent) tea.Cmd {
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return nil
+		}
+		var bots []NotificationConfig
+		for _, nc := range config.Notifications {
+			if nc.Provider == "telegram" && nc.BotToken != "" && len(nc.Approvers) > 0 && nc.wants(releaseEventWaiting, event.Environment, event.EnvBranch) {
+				bots = append(bots, nc)
+			}
+		}
+		if len(bots) == 0 {
+			return nil
+		}
+
+		results := make(chan remoteApprovalMsg, len(bots))
+		for _, nc := range bots {
+			go func() {
+				by, err := waitTelegramApproval(ctx, nc, event.Gate)
+				results <- remoteApprovalMsg{gate: event.Gate, by: by, err: err}
+			}()
+		}
+		var failed remoteApprovalMsg
+		for range bots {
+			msg := <-results
+			if msg.err == nil {
+				return msg
+			}
+			failed = msg
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		return failed
+	}
+}
+
+// waitTelegramApproval long-polls the bot until an allowed user approves gate in the configured chat.
+// Network errors are retried; it fails on Bot API errors that retrying cannot fix and when ctx is canceled.
+func waitTelegramApproval(ctx context.Context, nc NotificationConfig, gate string) (string, error) {
+	for {
+		telegramOffsetsMu.Lock()
+		offset := telegramOffsets[nc.BotToken]
+		telegramOffsetsMu.Unlock()
+
+		var updates []telegramUpdate
+		err := telegramCall(ctx, nc.BotToken, "getUpdates", map[string]interface{}{
+			"offset":          offset,
+			"timeout":         int(telegramPollTimeout / time.Second),
+			"allowed_updates": []string{"callback_query"},
+		}, &updates)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			var tgErr *telegramError
+			if errors.As(err, &tgErr) && tgErr.permanent() {
+				return "", err
+			}
+			select {
+			case <-time.After(telegramRetryDelay):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			telegramOffsetsMu.Lock()
+			if u.UpdateID >= telegramOffsets[nc.BotToken] {
+				telegramOffsets[nc.BotToken] = u.UpdateID + 1
+			}
+			telegramOffsetsMu.Unlock()
+
+			cq := u.CallbackQuery
+			if cq == nil || cq.Message == nil || !strings.HasPrefix(cq.Data, telegramApprovalData) || !nc.inChat(cq.Message.Chat.ID, cq.Message.Chat.Username) {
+				continue
+			}
+			answer := func(text string) {
+				telegramCall(ctx, nc.BotToken, "answerCallbackQuery", map[string]interface{}{
+					"callback_query_id": cq.ID,
+					"text":              text,
+				}, nil)
+			}
+			if cq.Data != telegramApprovalData+gate {
+				answer("This step is no longer waiting")
+				continue
+			}
+			if !nc.approves(cq.From) {
+				answer("You are not allowed to approve releases")
+				continue
+			}
+
+			answer("Approved")
+			// Remove the button so the step cannot be approved twice
+			telegramCall(ctx, nc.BotToken, "editMessageReplyMarkup", map[string]interface{}{
+				"chat_id":      cq.Message.Chat.ID,
+				"message_id":   cq.Message.MessageID,
+				"reply_markup": map[string]interface{}{"inline_keyboard": [][]interface{}{}},
+			}, nil)
+			return cq.From.name(), nil
+		}
+	}
+}
+
+// inChat reports whether a message belongs to the configured chat (numeric ID or @channel name)
+func (nc NotificationConfig) inChat(id int64, username string) bool {
+	if nc.ChatID == strconv.FormatInt(id, 10) {
+		return true
+	}
+	return username != "" && strings.EqualFold(strings.TrimPrefix(nc.ChatID, "@"), username)
+}
+
+// approves reports whether the user may approve gated steps; without approvers no one may
+func (nc NotificationConfig) approves(u telegramUser) bool {
+	for _, a := range nc.Approvers {
+		a = strings.TrimPrefix(a, "@")
+		if a == strconv.FormatInt(u.ID, 10) || (u.Username != "" && strings.EqualFold(a, u.Username)) {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns @username, or the numeric ID for users without one
+func (u telegramUser) name() string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return strconv.FormatInt(u.ID, 10)
+}
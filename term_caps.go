@@ -0,0 +1,347 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Terminals differ in what they draw: a Linux console or a non-UTF-8 locale garbles unicode
+// glyphs, CJK locales draw East Asian ambiguous glyphs (box corners, bullets, arrows) two cells
+// wide while the layout counts one, 24-bit colors come out wrong on 256-color terminals, and the
+// system clipboard is the remote host's over SSH. At startup relix probes the terminal from its
+// environment (TERM, COLORTERM, TERM_PROGRAM, the locale, SSH and multiplexer variables) and the
+// "terminal" section of the config overrides each answer. The result is the render policy the
+// screens consult:
+//   - colors: the color profile the theme's colors are reduced to; the full background of a theme
+//     is painted on truecolor terminals only
+//   - glyphs: without unicode, every frame has its glyphs replaced by ASCII of the same width and
+//     the spinner is ASCII
+//   - clipboard: copying uses OSC 52 over SSH, and where the system clipboard fails
+//   - mouse: with terminal.mouse "on", the wheel scrolls like the arrow keys
+//
+// Inline images have a setting of their own (see graphics.go). "relix terminal" prints the probe.
+
+// Terminal settings (terminal in the config); "auto" or empty takes the probed answer
+const (
+	termAuto        = "auto"
+	termOn          = "on"
+	termOff         = "off"
+	clipboardSystem = "system"
+	clipboardOSC52  = "osc52"
+)
+
+// TerminalConfig overrides the capabilities probed at startup
+type TerminalConfig struct {
+	Colors    string `json:"colors,omitempty"`    // auto, truecolor, 256, 16 or none
+	Unicode   string `json:"unicode,omitempty"`   // auto, on or off
+	Clipboard string `json:"clipboard,omitempty"` // auto, system, osc52 or off
+	Mouse     string `json:"mouse,omitempty"`     // off (default) or on
+}
+
+// termCaps are the capabilities of the terminal, as probed from its environment
+type termCaps struct {
+	term    string          // TERM, with TERM_PROGRAM if set
+	colors  termenv.Profile // Color depth
+	unicode bool            // Draws unicode glyphs as wide as the layout counts them
+	osc52   bool            // Sets the clipboard on OSC 52
+	mouse   bool            // Reports mouse events
+	remote  bool            // Runs over SSH, where the system clipboard is the remote host's
+}
+
+// renderPolicy is how relix renders in the terminal: the probed capabilities with the terminal
+// settings of the config applied
+type renderPolicy struct {
+	colors    termenv.Profile
+	ascii     bool   // Glyphs are replaced by ASCII
+	clipboard string // termAuto, clipboardSystem, clipboardOSC52 or termOff
+	mouse     bool   // The wheel scrolls
+}
+
+// termProbe holds the capabilities probed at startup
+var termProbe = detectTermCaps(os.Getenv, lipgloss.ColorProfile())
+
+// termPolicy is the render policy. It follows the probe until the config is read (see
+// applyStartupConfig).
+var termPolicy = newRenderPolicy(termProbe, nil)
+
+// detectTermCaps probes the terminal from its environment; colors is the color depth detected by
+// the terminal library
+func detectTermCaps(getenv func(string) string, colors termenv.Profile) termCaps {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	caps := termCaps{
+		term:    term,
+		colors:  colors,
+		unicode: detectUnicode(getenv),
+		osc52:   detectOSC52(getenv),
+		mouse:   term != "dumb" && term != "linux" && !strings.HasPrefix(term, "vt"),
+		remote:  getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != "",
+	}
+	if caps.term == "" {
+		caps.term = "unknown"
+	}
+	if program != "" {
+		caps.term += " (" + program + ")"
+	}
+	return caps
+}
+
+// detectUnicode tells whether the terminal draws unicode glyphs one cell wide. It needs a UTF-8
+// locale and a terminal with the fonts for them, unlike the Linux console and the classic Windows
+// console. CJK locales draw East Asian ambiguous glyphs two cells wide.
+func detectUnicode(getenv func(string) string) bool {
+	term := getenv("TERM")
+	if term == "dumb" || term == "linux" || strings.HasPrefix(term, "vt") {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return getenv("WT_SESSION") != "" || getenv("TERM_PROGRAM") != ""
+	}
+	if getenv("RUNEWIDTH_EASTASIAN") == "1" {
+		return false
+	}
+	locale := ""
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = strings.ToLower(getenv(name)); locale != "" {
+			break
+		}
+	}
+	if locale == "" {
+		return true // Without a locale, e.g. in macOS apps, the terminal's own encoding is UTF-8
+	}
+	if !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8") {
+		return false
+	}
+	for _, cjk := range []string{"ja", "zh", "ko"} {
+		if strings.HasPrefix(locale, cjk) {
+			return false
+		}
+	}
+	return true
+}
+
+// detectOSC52 tells whether the terminal sets the clipboard on OSC 52. Multiplexers drop it
+// unless configured to pass it on.
+func detectOSC52(getenv func(string) string) bool {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		return false
+	}
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", getenv("WT_SESSION") != "", getenv("ALACRITTY_WINDOW_ID") != "":
+		return true
+	case program == "WezTerm", program == "iTerm.app", program == "ghostty":
+		return true
+	}
+	for _, name := range []string{"kitty", "ghostty", "alacritty", "foot", "wezterm", "contour"} {
+		if strings.Contains(term, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRenderPolicy applies the terminal settings of the config to the probed capabilities
+func newRenderPolicy(caps termCaps, config *TerminalConfig) renderPolicy {
+	if config == nil {
+		config = &TerminalConfig{}
+	}
+	policy := renderPolicy{colors: caps.colors, ascii: !caps.unicode, clipboard: termAuto}
+
+	switch strings.ToLower(strings.TrimSpace(config.Colors)) {
+	case "truecolor", "24bit":
+		policy.colors = termenv.TrueColor
+	case "256":
+		policy.colors = termenv.ANSI256
+	case "16":
+		policy.colors = termenv.ANSI
+	case "none":
+		policy.colors = termenv.Ascii
+	}
+	switch strings.ToLower(strings.TrimSpace(config.Unicode)) {
+	case termOn:
+		policy.ascii = false
+	case termOff:
+		policy.ascii = true
+	}
+	switch setting := strings.ToLower(strings.TrimSpace(config.Clipboard)); setting {
+	case clipboardSystem, clipboardOSC52, termOff:
+		policy.clipboard = setting
+	}
+	policy.mouse = caps.mouse && strings.EqualFold(strings.TrimSpace(config.Mouse), termOn)
+	return policy
+}
+
+// applyTermPolicy makes a render policy the one in use
+func applyTermPolicy(policy renderPolicy) {
+	termPolicy = policy
+	lipgloss.SetColorProfile(policy.colors)
+}
+
+// mouseModeCmd turns mouse reports on or off by the render policy
+func mouseModeCmd() tea.Cmd {
+	if termPolicy.mouse {
+		return tea.EnableMouseCellMotion
+	}
+	return tea.DisableMouse
+}
+
+// handleMouse turns the wheel into arrow keys, so it scrolls whatever the keys scroll
+func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return m.update(tea.KeyMsg{Type: tea.KeyUp})
+	case tea.MouseButtonWheelDown:
+		return m.update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	return m, nil
+}
+
+// asciiGlyphs replaces the glyphs relix draws with ASCII of the same width
+var asciiGlyphs = strings.NewReplacer(
+	"•", "*", "·", ".", "●", "*", "◌", "o", "○", "o",
+	"✓", "+", "✔", "+", "✗", "x", "✘", "x", "⚠", "!",
+	"▸", ">", "▹", ">", "▶", ">", "→", ">", "←", "<", "↑", "^", "↓", "v", "↔", "-", "»", ">",
+	"…", ".", "—", "-", "–", "-", "‑", "-",
+	"█", "#", "▓", "=", "▒", ":", "░", ".",
+	"─", "-", "━", "-", "│", "|", "┃", "|", "═", "=", "║", "|",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+", "┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+", "╔", "+", "╗", "+", "╚", "+", "╝", "+",
+	"✅", "+ ", "❌", "x ", "⏳", ". ",
+)
+
+// degrade adapts a rendered frame to the render policy
+func (p renderPolicy) degrade(view string) string {
+	if !p.ascii {
+		return view
+	}
+	return asciiGlyphs.Replace(view)
+}
+
+// fullBackground reports whether the theme background can be painted behind the whole screen,
+// which takes 24-bit colors
+func (p renderPolicy) fullBackground() bool {
+	return p.colors == termenv.TrueColor
+}
+
+// copyToClipboard copies text to the clipboard of the user's machine. By default the system
+// clipboard is used, and OSC 52 over SSH or where the system clipboard fails.
+func copyToClipboard(text string) error {
+	switch termPolicy.clipboard {
+	case termOff:
+		return errors.New("copying is turned off (terminal.clipboard)")
+	case clipboardOSC52:
+		termenv.Copy(text)
+		return nil
+	case clipboardSystem:
+		return clipboard.WriteAll(text)
+	}
+	if termProbe.remote && termProbe.osc52 {
+		termenv.Copy(text)
+		return nil
+	}
+	err := clipboard.WriteAll(text)
+	if err != nil && termProbe.osc52 {
+		termenv.Copy(text)
+		return nil
+	}
+	return err
+}
+
+// colorsName names a color profile
+func colorsName(profile termenv.Profile) string {
+	switch profile {
+	case termenv.TrueColor:
+		return "truecolor"
+	case termenv.ANSI256:
+		return "256"
+	case termenv.ANSI:
+		return "16"
+	}
+	return "none"
+}
+
+// termSummary describes the terminal and the render policy in one line, for error reports
+func termSummary() string {
+	glyphs := "unicode"
+	if termPolicy.ascii {
+		glyphs = "ascii"
+	}
+	return fmt.Sprintf("%s, colors %s, %s glyphs, graphics %s", termProbe.term, colorsName(termPolicy.colors), glyphs, termGraphics)
+}
+
+// writeTermReport writes the probed capabilities and the render policy
+func writeTermReport(w io.Writer, caps termCaps, policy renderPolicy, graphics string) {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	glyphs := "unicode"
+	if policy.ascii {
+		glyphs = "ascii"
+	}
+	clipboard := policy.clipboard
+	if clipboard == termAuto {
+		switch {
+		case caps.remote && caps.osc52:
+			clipboard = "osc52"
+		case caps.osc52:
+			clipboard = "system, osc52 if it fails"
+		default:
+			clipboard = "system"
+		}
+	}
+	mouse := "off"
+	if policy.mouse {
+		mouse = "wheel scrolls"
+	}
+
+	fmt.Fprintf(w, "Terminal:  %s\n", caps.term)
+	fmt.Fprintf(w, "Over SSH:  %s\n\n", yesNo(caps.remote))
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "", "Probed", "Used")
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "Colors", colorsName(caps.colors), colorsName(policy.colors))
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "Unicode", yesNo(caps.unicode), glyphs)
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "OSC 52", yesNo(caps.osc52), clipboard)
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "Mouse", yesNo(caps.mouse), mouse)
+	fmt.Fprintf(w, "%-10s %-10s %s\n", "Graphics", detectGraphics(os.Getenv), graphics)
+}
+
+// terminalCommand prints what relix detected about the terminal
+var terminalCommand = &cliCommand{
+	Name:        "terminal",
+	Summary:     "Show the detected terminal capabilities and how relix renders in them",
+	Description: "Probes the terminal the way the TUI does at startup (colors, unicode glyphs, OSC 52 clipboard, mouse and inline images) and prints each answer next to the one in use after the \"terminal\" and \"terminal_graphics\" settings of the config. Run it where relix looks garbled to see what to override.",
+	Examples:    []string{"relix terminal"},
+	Env: []cliEnvVar{
+		{Name: "TERM, COLORTERM, TERM_PROGRAM", Description: "Terminal type, color depth and program"},
+		{Name: "LC_ALL, LC_CTYPE, LANG", Description: "Locale; unicode glyphs need UTF-8"},
+	},
+	Setup: func(fs *flag.FlagSet) func(args []string) error {
+		return func(args []string) error {
+			if len(args) > 0 {
+				return errCLIUsage
+			}
+			policy, graphics := termPolicy, termGraphics
+			if config, err := LoadConfig(); err == nil {
+				policy = newRenderPolicy(termProbe, config.Terminal)
+				graphics = resolveGraphics(config.TerminalGraphics)
+			}
+			writeTermReport(os.Stdout, termProbe, policy, graphics)
+			return nil
+		}
+	},
+}
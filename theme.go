@@ -6,6 +6,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -35,6 +38,12 @@ type ThemeConfig struct {
 	EnvTest    string `json:"env_test,omitempty"`
 	EnvStage   string `json:"env_stage,omitempty"`
 	EnvProd    string `json:"env_prod,omitempty"`
+	// Optional animation settings (see progress.go)
+	Spinner        string `json:"spinner,omitempty"`         // Spinner variant: minidot (default), dot, line, jump, pulse, points, globe, moon, monkey, meter, hamburger, ellipsis
+	SpinnerFPS     int    `json:"spinner_fps,omitempty"`     // Animation rate in frames per second (default the variant's own)
+	ProgressFull   string `json:"progress_full,omitempty"`   // Progress bar character of completed steps (default "█")
+	ProgressEmpty  string `json:"progress_empty,omitempty"`  // Progress bar character of pending steps (default "░")
+	ProgressActive string `json:"progress_active,omitempty"` // Marker sweeping over the running step (default "▓")
 }
 
 // ThemeColors holds resolved lipgloss colors for the current theme
@@ -59,6 +68,11 @@ type ThemeColors struct {
 	EnvTest    lipgloss.Color
 	EnvStage   lipgloss.Color
 	EnvProd    lipgloss.Color
+	// Animation
+	Spinner        spinner.Spinner
+	ProgressFull   string
+	ProgressEmpty  string
+	ProgressActive string
 }
 
 // Default indigo theme colors (hardcoded fallback)
@@ -80,6 +94,10 @@ var defaultThemeColors = ThemeColors{
 	EnvTest:           lipgloss.Color("#FFD600"),
 	EnvStage:          lipgloss.Color("#00D588"),
 	EnvProd:           lipgloss.Color("#FF84A8"),
+	Spinner:           spinner.MiniDot,
+	ProgressFull:      defaultProgressFull,
+	ProgressEmpty:     defaultProgressEmpty,
+	ProgressActive:    defaultProgressActive,
 }
 
 // currentTheme holds the active theme colors
@@ -165,31 +183,35 @@ func themeFromConfig(tc ThemeConfig) ThemeColors {
 	colors.EnvTest = resolveColor(tc.EnvTest, colors.Warning)
 	colors.EnvStage = resolveColor(tc.EnvStage, colors.Success)
 	colors.EnvProd = resolveColor(tc.EnvProd, colors.Error)
+	colors.Spinner = resolveSpinner(tc.Spinner, tc.SpinnerFPS)
+	colors.ProgressFull = resolveProgressChar(tc.ProgressFull, defaultProgressFull)
+	colors.ProgressEmpty = resolveProgressChar(tc.ProgressEmpty, defaultProgressEmpty)
+	colors.ProgressActive = resolveProgressChar(tc.ProgressActive, defaultProgressActive)
 	return colors
 }
 
 // loadThemeFromConfig loads and applies the selected theme from config
 func loadThemeFromConfig() {
 	config, err := LoadConfig()
-	if err != nil || len(config.Themes) == 0 {
-		currentTheme = defaultThemeColors
-		rebuildStyles()
-		return
+	if err != nil {
+		config = nil
 	}
+	currentTheme = selectedThemeColors(config)
+	rebuildStyles()
+}
 
-	// Find selected theme
-	selectedName := config.SelectedTheme
+// selectedThemeColors returns the colors of the theme selected in config
+// (the first theme if the selected one is missing, the default theme without config)
+func selectedThemeColors(config *AppConfig) ThemeColors {
+	if config == nil || len(config.Themes) == 0 {
+		return defaultThemeColors
+	}
 	for _, tc := range config.Themes {
-		if tc.Name == selectedName {
-			currentTheme = themeFromConfig(tc)
-			rebuildStyles()
-			return
+		if tc.Name == config.SelectedTheme {
+			return themeFromConfig(tc)
 		}
 	}
-
-	// Selected theme not found, use first theme
-	currentTheme = themeFromConfig(config.Themes[0])
-	rebuildStyles()
+	return themeFromConfig(config.Themes[0])
 }
 
 // applyTheme applies a specific theme config and rebuilds all styles
@@ -232,6 +254,7 @@ func defaultThemeANSIMap() *ThemeANSIMap {
 // rebuildStyles reassigns all package-level style variables based on currentTheme
 func rebuildStyles() {
 	t := currentTheme
+	liveOutputPalette.Store(nil) // Command output colors follow the new theme (see output_colors.go)
 
 	// --- styles.go ---
 
@@ -342,6 +365,16 @@ func rebuildStyles() {
 	homeVersionStyle = lipgloss.NewStyle().
 		Foreground(t.Notion)
 
+	releaseTabActiveStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.AccentForeground).
+		Background(t.Accent).
+		Padding(0, 1)
+
+	releaseTabStyle = lipgloss.NewStyle().
+		Foreground(t.Notion).
+		Padding(0, 1)
+
 	historyTabActiveStyle = lipgloss.NewStyle().
 		Bold(true).
 		Foreground(t.AccentForeground).
@@ -513,6 +546,46 @@ func rebuildStyles() {
 	}
 }
 
+// markdownStyle returns the glamour style of the current theme: theme text on the theme background,
+// accent headings and links, muted code. Screens adjust it to their content.
+func markdownStyle() ansi.StyleConfig {
+	t := currentTheme
+	style := styles.DarkStyleConfig
+
+	style.Document.StylePrimitive.Color = stringPtr(string(t.Foreground))
+	if t.HasBackground {
+		style.Document.StylePrimitive.BackgroundColor = stringPtr(string(t.Background))
+	}
+	style.Heading.Color = stringPtr(string(t.Accent))
+	style.H1.Prefix = " "
+	style.H1.BackgroundColor = stringPtr(string(t.Accent))
+	style.H1.Color = stringPtr(string(t.AccentForeground))
+	for _, h := range []*ansi.StyleBlock{&style.H2, &style.H3, &style.H4, &style.H5, &style.H6} {
+		h.Prefix = ""
+		h.Color = stringPtr(string(t.Accent))
+	}
+	style.Strong.Color = stringPtr(string(t.Warning))
+	style.BlockQuote.Color = stringPtr(string(t.Notion))
+	style.HorizontalRule.Color = stringPtr(string(t.Notion))
+	style.Link.Color = stringPtr(string(t.Notion))
+	style.LinkText.Color = stringPtr(string(t.Accent))
+	style.Image.Color = stringPtr(string(t.Notion))
+	style.ImageText.Color = stringPtr(string(t.Notion))
+	style.Item.Color = stringPtr(string(t.Accent))
+	style.Enumeration.Color = stringPtr(string(t.Accent))
+
+	style.Code.BackgroundColor = stringPtr(string(t.Muted))
+	style.Code.Color = stringPtr(string(t.MutedForeground))
+	style.CodeBlock.Color = stringPtr(string(t.MutedForeground))
+	chroma := *style.CodeBlock.Chroma // Copied, not to change the dark style itself
+	chroma.Text.Color = stringPtr(string(t.Foreground))
+	chroma.Name.Color = stringPtr(string(t.Foreground))
+	chroma.Comment.Color = stringPtr(string(t.MutedForeground))
+	chroma.Background.BackgroundColor = stringPtr(string(t.Muted))
+	style.CodeBlock.Chroma = &chroma
+	return style
+}
+
 // parseHexColor parses a "#RRGGBB" hex string into r, g, b components.
 func parseHexColor(hex string) (r, g, b int) {
 	if len(hex) == 7 && hex[0] == '#' {
@@ -544,32 +617,36 @@ func sgrResetsBackground(params string) bool {
 // \033[m, \033[0m, or any sequence containing param 0 or 49).
 // It also pads lines to width and fills remaining height with background-colored
 // empty lines.
+// Lines are cached from one frame to the next, so during a spinner animation only the changed
+// lines are processed again.
 func applyFullBackground(view string, bg lipgloss.Color, width, height int) string {
 	r, g, b := parseHexColor(string(bg))
 	bgEsc := fmt.Sprintf("\033[48;2;%d;%d;%dm", r, g, b)
 
+	cache := &backgroundCache
+	if cache.bgEsc != bgEsc || cache.width != width {
+		*cache = backgroundLineCache{bgEsc: bgEsc, width: width}
+	}
+	next := make(map[string]string, len(cache.lines))
+
 	lines := strings.Split(view, "\n")
 	var result strings.Builder
+	result.Grow(len(view) + height*(len(bgEsc)+width+8))
 	for i, line := range lines {
-		// Inject bg escape at start and after every SGR that resets background
-		line = bgEsc + sgrResetBgRe.ReplaceAllStringFunc(line, func(match string) string {
-			params := sgrResetBgRe.FindStringSubmatch(match)
-			if len(params) > 1 && sgrResetsBackground(params[1]) {
-				return match + bgEsc
+		out, ok := next[line]
+		if !ok {
+			if out, ok = cache.lines[line]; !ok {
+				out = applyLineBackground(line, bgEsc, width)
 			}
-			return match
-		})
-		// Pad to full width
-		lineWidth := lipgloss.Width(line)
-		if lineWidth < width {
-			line += strings.Repeat(" ", width-lineWidth)
+			next[line] = out
 		}
-		line += "\033[0m"
 		if i > 0 {
 			result.WriteString("\n")
 		}
-		result.WriteString(line)
+		result.WriteString(out)
 	}
+	cache.lines = next
+
 	// Fill remaining height
 	emptyLine := bgEsc + strings.Repeat(" ", width) + "\033[0m"
 	for i := len(lines); i < height; i++ {
@@ -577,3 +654,32 @@ func applyFullBackground(view string, bg lipgloss.Color, width, height int) stri
 	}
 	return result.String()
 }
+
+// backgroundLineCache maps the lines of the previous frame to their processed form.
+// It only holds one frame, so it does not grow over time.
+type backgroundLineCache struct {
+	bgEsc string
+	width int
+	lines map[string]string
+}
+
+// backgroundCache is used from View only, which Bubble Tea calls from a single goroutine
+var backgroundCache backgroundLineCache
+
+// applyLineBackground applies the background to a single line and pads it to width
+func applyLineBackground(line, bgEsc string, width int) string {
+	// Inject bg escape at start and after every SGR that resets background
+	line = bgEsc + sgrResetBgRe.ReplaceAllStringFunc(line, func(match string) string {
+		params := sgrResetBgRe.FindStringSubmatch(match)
+		if len(params) > 1 && sgrResetsBackground(params[1]) {
+			return match + bgEsc
+		}
+		return match
+	})
+	// Pad to full width
+	lineWidth := lipgloss.Width(line)
+	if lineWidth < width {
+		line += strings.Repeat(" ", width-lineWidth)
+	}
+	return line + "\033[0m"
+}
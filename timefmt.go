@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dates and times shown on screens and written by the history and audit commands go through
+// formatDateTime and formatDate, so time_zone and date_format in the config apply everywhere.
+// Machine-readable output (JSON, CSV, iCalendar) keeps RFC 3339 and UTC.
+
+// dateLayout is a date format with the time of day that goes with it
+type dateLayout struct {
+	date  string
+	clock string
+}
+
+// defaultDateLayout is dd.MM.yyyy with a 24-hour clock
+var defaultDateLayout = dateLayout{date: "02.01.2006", clock: "15:04"}
+
+// dateLayouts are the date_format presets by locale (language, or language and region)
+var dateLayouts = map[string]dateLayout{
+	"iso":   {date: "2006-01-02", clock: "15:04"},
+	"ru":    defaultDateLayout,
+	"de":    defaultDateLayout,
+	"pl":    defaultDateLayout,
+	"uk":    defaultDateLayout,
+	"en":    {date: "01/02/2006", clock: "3:04 PM"},
+	"en-us": {date: "01/02/2006", clock: "3:04 PM"},
+	"en-gb": {date: "02/01/2006", clock: "15:04"},
+	"fr":    {date: "02/01/2006", clock: "15:04"},
+	"es":    {date: "02/01/2006", clock: "15:04"},
+	"it":    {date: "02/01/2006", clock: "15:04"},
+	"nl":    {date: "02-01-2006", clock: "15:04"},
+	"ja":    {date: "2006/01/02", clock: "15:04"},
+	"zh":    {date: "2006/01/02", clock: "15:04"},
+	"ko":    {date: "2006. 01. 02.", clock: "15:04"},
+}
+
+// timeDisplay is the parsed time_zone, audit_time_zone and date_format config
+type timeDisplay struct {
+	location      *time.Location
+	auditLocation *time.Location
+	layout        dateLayout
+}
+
+// timeSettings reads the time display config on first use; invalid values keep their default
+var timeSettings = sync.OnceValue(func() timeDisplay {
+	var cfg AppConfig
+	if config, err := LoadConfig(); err == nil {
+		cfg = *config
+	}
+	settings := timeDisplay{
+		location: resolveTimeZone(cfg.TimeZone, time.Local),
+		layout:   resolveDateLayout(cfg.DateFormat, os.Getenv),
+	}
+	settings.auditLocation = resolveTimeZone(cfg.AuditTimeZone, settings.location)
+	return settings
+})
+
+// resolveTimeZone returns the location of a time_zone value: "local", "UTC" or an IANA name
+// such as "Europe/Berlin"; empty or unknown names return def
+func resolveTimeZone(name string, def *time.Location) *time.Location {
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(name) {
+	case "":
+		return def
+	case "local":
+		return time.Local
+	case "utc":
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return def
+	}
+	return loc
+}
+
+// resolveDateLayout returns the layout of a date_format value: a locale preset ("iso", "en-US",
+// "de", ...), "locale" for the one of LC_ALL, LC_TIME or LANG, or a Go layout of date and time
+// such as "2006-01-02 15:04". Unknown locales fall back to their language, then to dd.MM.yyyy.
+func resolveDateLayout(format string, getenv func(string) string) dateLayout {
+	format = strings.TrimSpace(format)
+	if strings.ContainsAny(format, "0123456789") {
+		return dateLayout{date: format}
+	}
+	locale := strings.ToLower(format)
+	if locale == "locale" {
+		locale = ""
+		for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+			if v := getenv(env); v != "" {
+				locale = strings.ToLower(v)
+				break
+			}
+		}
+	}
+	// "en_US.UTF-8" -> "en-us"
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if layout, ok := dateLayouts[locale]; ok {
+		return layout
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	if layout, ok := dateLayouts[lang]; ok {
+		return layout
+	}
+	return defaultDateLayout
+}
+
+// formatDateTime formats t as date and time in the configured time zone
+func formatDateTime(t time.Time) string {
+	settings := timeSettings()
+	return formatLayout(t.In(settings.location), settings.layout)
+}
+
+// formatDate formats the date of t in the configured time zone. A custom layout has no separate
+// date part, so it is used as is.
+func formatDate(t time.Time) string {
+	settings := timeSettings()
+	return t.In(settings.location).Format(settings.layout.date)
+}
+
+// formatAuditTime formats the time of an audit entry in audit_time_zone, with seconds. Times
+// outside the local time zone name their zone, e.g. "UTC".
+func formatAuditTime(t time.Time) string {
+	settings := timeSettings()
+	layout := settings.layout
+	if layout.clock != "" {
+		layout.clock = strings.Replace(layout.clock, ":04", ":04:05", 1)
+	}
+	text := formatLayout(t.In(settings.auditLocation), layout)
+	if settings.auditLocation != time.Local {
+		text += " " + t.In(settings.auditLocation).Format("MST")
+	}
+	return text
+}
+
+// formatLayout formats t with the date and time of day of layout
+func formatLayout(t time.Time, layout dateLayout) string {
+	if layout.clock == "" {
+		return t.Format(layout.date)
+	}
+	return t.Format(layout.date + " " + layout.clock)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// toastDuration is how long a toast stays on screen
+const toastDuration = 4 * time.Second
+
+// toastExpiredMsg hides the toast it was scheduled for
+type toastExpiredMsg struct {
+	gen int
+}
+
+// showToast shows a short confirmation in the bottom right corner until it expires
+func (m *model) showToast(text string) tea.Cmd {
+	m.toast = text
+	m.toastGen++
+	gen := m.toastGen
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{gen: gen}
+	})
+}
+
+// handleToastExpired hides the toast unless a newer one replaced it
+func (m *model) handleToastExpired(msg toastExpiredMsg) {
+	if msg.gen == m.toastGen {
+		m.toast = ""
+	}
+}
+
+// overlayToast renders the toast in the bottom right corner
+func (m model) overlayToast(background string) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(currentTheme.Success).
+		Padding(0, 1)
+	toast := renderModal(m.toast, ModalConfig{Width: ModalWidth{Value: lipgloss.Width(m.toast) + style.GetHorizontalFrameSize()}, MaxWidth: 60, Style: style}, m.width)
+	return placeOverlay(m.width-lipgloss.Width(toast)-1, m.height-lipgloss.Height(toast)-1, toast, background)
+}
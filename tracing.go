@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Releases are traced when an OTLP endpoint is set through the standard OpenTelemetry variables
+// (OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS,
+// OTEL_SERVICE_NAME). Each release is one trace: a root span with a span per release step, and
+// spans per git command and forge API call below it. Spans are kept in memory and exported with
+// OTLP/HTTP in the JSON encoding when the release finishes, so no collector SDK is needed.
+
+// traceSpan is a finished or running span of the release trace
+type traceSpan struct {
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	kind     int // OTLP SpanKind: 1 internal, 3 client
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	err      string // Status message; empty means OK
+}
+
+// OTLP span kinds used by relix
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+)
+
+// releaseTrace holds the spans of the running release; only one release runs per process
+var releaseTrace struct {
+	mu    sync.Mutex
+	root  *traceSpan
+	step  *traceSpan
+	spans []*traceSpan // Finished spans
+}
+
+// tracingEndpoint returns the OTLP traces URL, or "" if tracing is off
+func tracingEndpoint() string {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+// randomHex returns n random bytes in hex, as used for trace and span IDs
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// startReleaseTrace starts the root span of a release, dropping any trace left unfinished
+func startReleaseTrace(state *ReleaseState) {
+	if tracingEndpoint() == "" {
+		return
+	}
+	releaseTrace.mu.Lock()
+	defer releaseTrace.mu.Unlock()
+	releaseTrace.root = &traceSpan{
+		traceID: randomHex(16),
+		spanID:  randomHex(8),
+		name:    "release",
+		kind:    spanKindInternal,
+		start:   time.Now(),
+		attrs: map[string]string{
+			"relix.environment": state.Environment.Name,
+			"relix.env_branch":  state.Environment.BranchName,
+			"relix.version":     state.Version,
+			"relix.mr_count":    strconv.Itoa(len(state.MRBranches)),
+		},
+	}
+	releaseTrace.step = nil
+	releaseTrace.spans = nil
+}
+
+// startTraceSpan starts a span under the running step, or under the release if no step runs.
+// It returns nil when no release is traced; the span methods accept nil.
+func startTraceSpan(name string, kind int, attrs map[string]string) *traceSpan {
+	releaseTrace.mu.Lock()
+	defer releaseTrace.mu.Unlock()
+	parent := releaseTrace.step
+	if parent == nil {
+		parent = releaseTrace.root
+	}
+	if parent == nil {
+		return nil
+	}
+	return &traceSpan{
+		traceID:  parent.traceID,
+		spanID:   randomHex(8),
+		parentID: parent.spanID,
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		attrs:    attrs,
+	}
+}
+
+// finish ends the span and adds it to the trace, unless the trace was finished meanwhile
+func (s *traceSpan) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+	releaseTrace.mu.Lock()
+	defer releaseTrace.mu.Unlock()
+	if releaseTrace.root != nil && releaseTrace.root.traceID == s.traceID {
+		releaseTrace.spans = append(releaseTrace.spans, s)
+	}
+}
+
+// startStepSpan starts the span of a release step. A release resumed after a restart gets a new
+// trace with its remaining steps.
+func startStepSpan(state *ReleaseState, step ReleaseStep) {
+	releaseTrace.mu.Lock()
+	resumed := releaseTrace.root == nil
+	releaseTrace.mu.Unlock()
+	if resumed {
+		startReleaseTrace(state)
+	}
+	span := startTraceSpan(releaseStepNames[step], spanKindInternal, nil)
+	releaseTrace.mu.Lock()
+	releaseTrace.step = span
+	releaseTrace.mu.Unlock()
+}
+
+// finishStepSpan ends the span of the running release step
+func finishStepSpan(err error) {
+	releaseTrace.mu.Lock()
+	span := releaseTrace.step
+	releaseTrace.step = nil
+	releaseTrace.mu.Unlock()
+	span.finish(err)
+}
+
+// finishReleaseTrace ends the release span with the given outcome (completed, failed or aborted)
+// and returns a command exporting the trace
+func finishReleaseTrace(outcome string) tea.Cmd {
+	releaseTrace.mu.Lock()
+	root, step := releaseTrace.root, releaseTrace.step
+	releaseTrace.mu.Unlock()
+	if root == nil {
+		return nil
+	}
+	step.finish(nil)
+
+	releaseTrace.mu.Lock()
+	root.end = time.Now()
+	root.attrs["relix.outcome"] = outcome
+	if outcome != "completed" {
+		root.err = "release " + outcome
+	}
+	spans := append(releaseTrace.spans, root)
+	releaseTrace.root = nil
+	releaseTrace.step = nil
+	releaseTrace.spans = nil
+	releaseTrace.mu.Unlock()
+
+	return func() tea.Msg {
+		if err := exportTrace(spans); err != nil {
+			return releaseNotifyMsg{errs: []error{fmt.Errorf("trace export: %w", err)}}
+		}
+		return nil
+	}
+}
+
+// exportTrace posts the spans to the OTLP endpoint
+func exportTrace(spans []*traceSpan) error {
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "relix"
+	}
+
+	otlpSpans := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		span := map[string]any{
+			"traceId":           s.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              s.kind,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+			"attributes":        otlpAttributes(s.attrs),
+			"status":            map[string]any{"code": 1},
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.err != "" {
+			span["status"] = map[string]any{"code": 2, "message": redactSecrets(s.err)}
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+	payload := map[string]any{
+		"resourceSpans": []map[string]any{{
+			"resource": map[string]any{
+				"attributes": otlpAttributes(map[string]string{"service.name": serviceName, "service.version": AppVersion}),
+			},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]any{"name": "relix"},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, tracingEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, header := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok {
+			continue
+		}
+		// Values are URL-encoded per the OpenTelemetry specification
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+			value = decoded
+		}
+		req.Header.Set(strings.TrimSpace(name), value)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// otlpAttributes converts string attributes to OTLP key-value pairs
+func otlpAttributes(attrs map[string]string) []map[string]any {
+	result := make([]map[string]any, 0, len(attrs))
+	for _, key := range sortedStringKeys(attrs) {
+		result = append(result, map[string]any{
+			"key":   key,
+			"value": map[string]any{"stringValue": redactSecrets(attrs[key])},
+		})
+	}
+	return result
+}
+
+// sortedStringKeys returns the keys of m in order
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commandSpanName names a git command span by its program and subcommand, e.g. "git merge"
+func commandSpanName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	return strings.Join(fields, " ")
+}
+
+// tracingTransport records a client span per forge API call of a traced release
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := startTraceSpan(req.Method+" "+metricsEndpoint(req.URL.Path), spanKindClient, map[string]string{
+		"http.request.method": req.Method,
+		"server.address":      req.URL.Host,
+		"url.path":            req.URL.Path,
+	})
+	resp, err := t.base.RoundTrip(req)
+	if span != nil {
+		spanErr := err
+		if err == nil {
+			span.attrs["http.response.status_code"] = strconv.Itoa(resp.StatusCode)
+			if resp.StatusCode >= 400 {
+				spanErr = errors.New(resp.Status)
+			}
+		}
+		span.finish(spanErr)
+	}
+	return resp, err
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,17 +22,38 @@ const (
 	screenSourceBranch
 	screenEnvMerge
 	screenRootMerge
+	screenRollout
 	screenConfirm
 	screenRelease
 	screenHistoryList
 	screenHistoryDetail
 	screenSettings
+	screenSafeMode
 )
 
 // Environment represents a deployment environment
 type Environment struct {
 	Name       string // Display name: DEVELOP, TEST, STAGE, PROD
 	BranchName string // Branch suffix: develop, testing, stable, master
+
+	// Version format rules (see FormatReleaseVersion); all empty keeps the "<env>-<version>-v<N>" tags
+	TagPrefix     string // e.g. "v"
+	VersionSuffix string // e.g. "-rc.{n}"
+	BuildMetadata string // e.g. "build.{date}", appended after "+"
+
+	ReleaseCandidates bool // Releases are candidates, promoted to the final tag (see release_candidates.go)
+
+	Rollout bool // Releases ask for rollout metadata (see rollout.go)
+
+	ConfirmPhrase string // Typed before a release starts (see confirm_phrase.go)
+
+	PushStrategy string // auto, push or mr (see push_strategy.go)
+
+	Variables map[string]string // Release variables (see release_vars.go)
+
+	DeployPipeline *DeployPipelineConfig // Pipeline the release waits for after tagging (see deploy_pipeline.go)
+
+	GitLabEnvironment string // Environment name in GitLab deployments (see deployments.go)
 }
 
 // Credentials stored in keyring
@@ -39,15 +61,34 @@ type Credentials struct {
 	GitLabURL string `json:"gitlab_url"`
 	Email     string `json:"email"`
 	Token     string `json:"token"`
+	Forge     string `json:"forge,omitempty"` // forgeGitLab, forgeGitHub, forgeGitea or forgeBitbucket, detected at login
 }
 
 // Messages for tea.Msg
 type authResultMsg struct {
-	err error
+	creds Credentials // Credentials signed in with
+	err   error
 }
 
 type checkCredsMsg struct {
-	creds *Credentials
+	creds        *Credentials
+	keyringErr   error                 // The keyring could not be read (not set when no credentials are stored)
+	releaseState *ReleaseState         // Release in progress, read along with the credentials
+	tabStates    map[int]*ReleaseState // Releases in progress in other tabs, by tab ID
+	project      *Project              // Project saved in config
+}
+
+// startupConfigMsg carries the theme and environments read from config at startup
+type startupConfigMsg struct {
+	theme          ThemeColors
+	environments   []Environment
+	releaseWindows []ReleaseWindow
+	pinnedProjects []Project
+	graphics       string // Graphics protocol of the terminal (see graphics.go)
+	terminal       *TerminalConfig
+	reduceMotion   bool
+	hideFieldHints bool
+	workspace      string
 }
 
 // ListItem represents a list item for the main screen
@@ -69,6 +110,7 @@ type MergeRequest struct {
 	SourceBranch string    `json:"source_branch"`
 	TargetBranch string    `json:"target_branch"`
 	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 	Draft        bool      `json:"draft"`
 	Author       struct {
 		ID       int    `json:"id"`
@@ -79,6 +121,8 @@ type MergeRequest struct {
 	UserNotesCount              int    `json:"user_notes_count"`
 	ChangesCount                string `json:"changes_count"`
 	HasConflicts                bool   `json:"has_conflicts"`
+	MergeStatus                 string `json:"merge_status"`          // can_be_merged, cannot_be_merged, checking, ...; empty if not reported
+	DetailedMergeStatus         string `json:"detailed_merge_status"` // mergeable, not_approved, ci_still_running, ...; GitLab 15.6+
 	BlockingDiscussionsResolved bool   `json:"blocking_discussions_resolved"`
 	SHA                         string `json:"sha"`              // HEAD commit of source branch
 	MergeCommitSHA              string `json:"merge_commit_sha"` // Commit SHA after merge
@@ -91,9 +135,17 @@ type MergeRequestDetails struct {
 		Additions int `json:"additions"`
 		Deletions int `json:"deletions"`
 	} `json:"diff_stats"`
-	CommitsCount        int `json:"-"`
-	DiscussionsTotal    int `json:"-"`
-	DiscussionsResolved int `json:"-"`
+	CommitsCount        int  `json:"-"`
+	DiscussionsTotal    int  `json:"-"`
+	DiscussionsResolved int  `json:"-"`
+	BehindCount         int  `json:"-"` // Commits on the target branch missing from the source branch
+	BehindKnown         bool `json:"-"` // False if the forge does not report BehindCount
+	DetailsLoaded       bool `json:"-"` // False until the counts above are fetched (lists load them lazily)
+
+	HeadPipeline   *Pipeline `json:"-"` // Latest pipeline of the source branch; nil if none or not reported
+	ApprovedBy     []string  `json:"-"` // Usernames of the approvers
+	ApprovalsLeft  int       `json:"-"` // Approvals still required to merge
+	ApprovalsKnown bool      `json:"-"` // False if the forge does not report approvals
 }
 
 // mrListItem represents a merge request in the list
@@ -104,15 +156,34 @@ type mrListItem struct {
 func (i mrListItem) Title() string { return i.mr.Title }
 func (i mrListItem) Description() string {
 	created := humanize.Time(i.mr.CreatedAt)
-	return "@" + i.mr.Author.Username + " • " + created
+	desc := "@" + i.mr.Author.Username + " • " + created
+	if i.mr.BehindKnown && i.mr.BehindCount > 0 {
+		desc += fmt.Sprintf(" • %d behind", i.mr.BehindCount)
+	}
+	return desc
 }
-func (i mrListItem) FilterValue() string      { return i.mr.Title }
+func (i mrListItem) FilterValue() string      { return normalizeSpaces(i.mr.Title) }
 func (i mrListItem) MR() *MergeRequestDetails { return i.mr }
 
 // fetchMRsMsg is sent when MRs are fetched
 type fetchMRsMsg struct {
-	mrs []*MergeRequestDetails
-	err error
+	projectID  int // 0 when MRs of all projects were fetched
+	mrs        []*MergeRequestDetails
+	err        error
+	background bool      // Periodic refresh: errors are not shown
+	pages      int       // Pages fetched
+	page       listPage  // Pagination of the last page fetched
+	syncedAt   time.Time // When the fetch started; the next delta refresh fetches MRs updated since
+	fullSync   bool      // The whole list was fetched rather than the MRs updated since the last fetch
+}
+
+// fetchMoreMRsMsg is sent when the next page of a project's MRs is fetched
+type fetchMoreMRsMsg struct {
+	projectID int
+	page      int
+	mrs       []*MergeRequestDetails
+	info      listPage
+	err       error
 }
 
 // Project represents a GitLab project
@@ -123,18 +194,126 @@ type Project struct {
 	Path              string `json:"path"`
 	PathWithNamespace string `json:"path_with_namespace"`
 	WebURL            string `json:"web_url"`
+	AvatarURL         string `json:"avatar_url,omitempty"` // Shown in the project selector where the terminal draws images
+}
+
+// fetchMRDetailsMsg is sent when details of a single MR are fetched
+type fetchMRDetailsMsg struct {
+	id      int // Global MR ID (IIDs repeat across projects)
+	details *MergeRequestDetails
+	err     error
 }
 
 // fetchProjectsMsg is sent when projects are fetched
 type fetchProjectsMsg struct {
+	query    projectQuery
+	projects []Project
+	page     listPage
+	err      error
+}
+
+// fetchMoreProjectsMsg is sent when the next page of projects is fetched
+type fetchMoreProjectsMsg struct {
+	query    projectQuery
+	page     int
 	projects []Project
+	info     listPage
 	err      error
 }
 
+// projectFilterMsg applies the project filter once typing pauses
+type projectFilterMsg struct {
+	seq int
+}
+
 // EnvConfig represents a configurable environment with its display name and git branch
 type EnvConfig struct {
-	Name       string `json:"name"`        // Display name (shown UPPERCASED in UI)
-	BranchName string `json:"branch_name"` // Git branch name
+	Name          string `json:"name"`                     // Display name (shown UPPERCASED in UI)
+	BranchName    string `json:"branch_name"`              // Git branch name
+	TagPrefix     string `json:"tag_prefix,omitempty"`     // Prefix of the released version, e.g. "v"
+	VersionSuffix string `json:"version_suffix,omitempty"` // Suffix of the released version, e.g. "-rc.{n}"
+	BuildMetadata string `json:"build_metadata,omitempty"` // Semver build metadata, e.g. "build.{date}"
+
+	// Releases are release candidates (version_suffix default "-rc.{n}"), promoted to the final tag from history
+	ReleaseCandidates bool `json:"release_candidates,omitempty"`
+
+	Rollout       bool   `json:"rollout,omitempty"`        // Ask for the canary percentage and feature flags before releasing
+	ConfirmPhrase string `json:"confirm_phrase,omitempty"` // Typed before releasing, e.g. "ship it" or "{project}"
+	PushStrategy  string `json:"push_strategy,omitempty"`  // How the base branch and develop are updated: auto, push or mr
+
+	// Variables set for the release's git commands and shell, and passed to its GitLab pipelines
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// Pipeline of a deployment project started after tagging; the release completes when it succeeds
+	DeployPipeline *DeployPipelineConfig `json:"deploy_pipeline,omitempty"`
+
+	// Environment name in GitLab deployments (default the name in lower case)
+	GitLabEnvironment string `json:"gitlab_environment,omitempty"`
+}
+
+// DeployPipelineConfig is a GitLab pipeline of a separate deployment project
+type DeployPipelineConfig struct {
+	Project      string `json:"project"`                 // Project path ("group/deploy") or ID
+	Ref          string `json:"ref,omitempty"`           // Branch or tag to run (default the project's default branch)
+	TriggerToken string `json:"trigger_token,omitempty"` // Pipeline trigger token (default create the pipeline with the login token)
+	Timeout      string `json:"timeout,omitempty"`       // e.g. "30m" (default "1h")
+
+	// Pipeline variables, in addition to the release variables
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// Variables asked for before the release starts, with the spec inputs of the project's
+	// .gitlab-ci.yml (see pipeline_inputs.go)
+	Inputs []PipelineInput `json:"inputs,omitempty"`
+}
+
+// NotificationConfig is a chat webhook notified about release events
+type NotificationConfig struct {
+	Provider     string   `json:"provider"` // "slack", "teams", "mattermost", "telegram", "webhook", "email", "mr_comment", "mr_announce" or a plugin name
+	WebhookURL   string   `json:"webhook_url,omitempty"`
+	BotToken     string   `json:"bot_token,omitempty"`    // Telegram bot token
+	ChatID       string   `json:"chat_id,omitempty"`      // Telegram chat ID or @channel
+	Secret       string   `json:"secret,omitempty"`       // Webhook HMAC signing secret
+	SMTP         string   `json:"smtp,omitempty"`         // Email: SMTP server host:port
+	Username     string   `json:"username,omitempty"`     // Email: SMTP login (default no authentication)
+	Password     string   `json:"password,omitempty"`     // Email: SMTP password
+	From         string   `json:"from,omitempty"`         // Email: sender address
+	To           []string `json:"to,omitempty"`           // Email: recipients, e.g. a distribution list
+	Approvers    []string `json:"approvers,omitempty"`    // Telegram users (@name or ID) who may approve gated steps (default anyone in the chat)
+	Environments []string `json:"environments,omitempty"` // Environment names or branches (default all)
+	Events       []string `json:"events,omitempty"`       // started, step, failed, suspended, waiting, completed, aborted (default all but step and failed for chats)
+
+	// Settings passed to a plugin provider (see plugins.go)
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ReleaseNotesConfig is a page that release notes are published to
+type ReleaseNotesConfig struct {
+	Provider     string   `json:"provider"`                // "confluence", "gitlab_wiki" or "forge_release"
+	Title        string   `json:"title,omitempty"`         // Page title template (default "Release {{.Name}} to {{.Environment}}")
+	Template     string   `json:"template,omitempty"`      // Page body template (default per provider)
+	TemplateFile string   `json:"template_file,omitempty"` // Read the body template from a file, relative to the project directory
+	Environments []string `json:"environments,omitempty"`  // Environment names or branches (default all)
+	URL          string   `json:"url,omitempty"`           // Confluence base URL, e.g. https://acme.atlassian.net/wiki
+	Space        string   `json:"space,omitempty"`         // Confluence space key
+	ParentID     string   `json:"parent_id,omitempty"`     // Confluence parent page ID
+	Email        string   `json:"email,omitempty"`         // Confluence Cloud account email (token is then an API token)
+	Token        string   `json:"token,omitempty"`         // Confluence API or personal access token
+	ProjectID    int      `json:"project_id,omitempty"`    // GitLab project of the wiki (default the released project)
+	Parent       string   `json:"parent,omitempty"`        // GitLab wiki directory, e.g. "releases"
+}
+
+// EvidenceConfig asks for a signed evidence bundle of each release (see release_evidence.go)
+type EvidenceConfig struct {
+	Environments    []string `json:"environments,omitempty"`      // Environment names or branches (default all)
+	SigningKey      string   `json:"signing_key,omitempty"`       // Ed25519 private key PEM file (default ~/.relix/evidence.key, created on first use)
+	AttachToRelease bool     `json:"attach_to_release,omitempty"` // Upload the bundle and link it from the GitLab Release of the tag
+}
+
+// HistorySyncConfig is a shared "relix serve" instance that saved releases are pushed to and the
+// team activity feed is read from (see history_sync.go)
+type HistorySyncConfig struct {
+	URL   string `json:"url"`   // Server URL, e.g. http://relix.internal:8080
+	Token string `json:"token"` // Sync token of the server (relix serve --sync-token), which cannot start releases
 }
 
 // AppConfig represents the application configuration saved to file
@@ -144,17 +323,195 @@ type AppConfig struct {
 	SelectedProjectName      string `json:"selected_project_name"`
 	SelectedProjectShortName string `json:"selected_project_short_name"`
 
+	// Projects shown on the home dashboard (pinned in the project selector)
+	PinnedProjects []Project `json:"pinned_projects,omitempty"`
+
+	// Local clones to release projects from, by path with namespace (default the project root
+	// found from the working directory); releases running at once in tabs need separate clones
+	ProjectDirs map[string]string `json:"project_dirs,omitempty"`
+
+	// Release from long-lived clones kept per project and environment in ~/.relix/checkouts,
+	// fetched incrementally, instead of from the project's clone (see release_checkouts.go)
+	ReleaseCheckouts bool `json:"release_checkouts,omitempty"`
+
 	// Release settings
 	BaseBranch        string      `json:"base_branch"`                       // Base branch for releases (default "root")
 	Environments      []EnvConfig `json:"environments,omitempty"`            // Customizable environment branches
 	ExcludePatterns   string      `json:"exclude_patterns"`                  // File patterns to exclude from release, one per line
 	PipelineJobsRegex string      `json:"pipeline_jobs_regex,omitempty"`     // Regex to match observable pipeline job names
 
+	// Project selector list (default all projects, archived ones hidden)
+	ProjectsScope           string `json:"projects_scope,omitempty"`            // "starred" or "owned"
+	ProjectsIncludeArchived bool   `json:"projects_include_archived,omitempty"` // List archived projects too
+
+	// Directories whose subdirectories are separate services in the release impact summary,
+	// e.g. "services" makes services/billing an area of its own (default top-level directories)
+	ServiceDirs []string `json:"service_dirs,omitempty"`
+
+	// MR list filters (default all open MRs)
+	MRTargetBranch      string `json:"mr_target_branch,omitempty"`       // Only MRs targeting this branch
+	MRSourceBranchRegex string `json:"mr_source_branch_regex,omitempty"` // Only MRs whose source branch matches
+
+	// Release votes (see release_votes.go): MRs with this emoji award or a comment starting with
+	// this text are selected when the MR list opens
+	ReleaseVoteEmoji   string `json:"release_vote_emoji,omitempty"`
+	ReleaseVoteComment string `json:"release_vote_comment,omitempty"`
+
+	// Release output kept in memory before older lines spill to disk (default 4096)
+	OutputMemoryLimitKB int `json:"output_memory_limit_kb,omitempty"`
+
+	// Signed evidence bundle written per release (see release_evidence.go)
+	Evidence *EvidenceConfig `json:"evidence,omitempty"`
+
+	// Regular expressions of secrets redacted from the release output, in addition to the built-in
+	// ones (see redaction.go); only a group named "secret" is replaced if there is one
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// Forge API timeouts (default 10s per request, see http_timeouts.go)
+	HTTPTimeouts HTTPTimeouts `json:"http_timeouts,omitempty"`
+
+	// Extra headers and SSO cookie for forges behind a reverse proxy (see http_headers.go)
+	HTTPHeaders      map[string]string `json:"http_headers,omitempty"`
+	SSOCookieCommand string            `json:"sso_cookie_command,omitempty"` // Shell command printing the cookie, e.g. "name=value"
+	SSOCookieTTL     string            `json:"sso_cookie_ttl,omitempty"`     // How long the printed cookie is reused (default 10m)
+
+	// Where credentials are stored: auto (default), secret-service, keychain, wincred or file (see keyring.go)
+	KeyringBackend string `json:"keyring_backend,omitempty"`
+
+	// Pre-approved release plans that signed webhooks may start, by name (see "relix serve")
+	WebhookPlans map[string]ReleasePlan `json:"webhook_plans,omitempty"`
+	// Slack users (name or ID) and GitLab usernames allowed to start webhook plans by command or
+	// MR comment; without any, commands and comments start nothing
+	WebhookUsers []string `json:"webhook_users,omitempty"`
+
+	// Chat webhooks notified about release events
+	Notifications []NotificationConfig `json:"notifications,omitempty"`
+
+	// Shared history of the team: saved releases are pushed to it and the home screen shows its
+	// activity feed (see history_sync.go)
+	HistorySync *HistorySyncConfig `json:"history_sync,omitempty"`
+
+	// Commit message templates (see commit_messages.go): merges of the MR branches into the source
+	// branch (default git's message) and the body of the squashed release commit (default the branch list)
+	MergeCommitTemplate  string `json:"merge_commit_template,omitempty"`
+	SquashCommitTemplate string `json:"squash_commit_template,omitempty"`
+
+	// Don't read project settings from .restitcher.yaml in the repository (see repo_settings.go)
+	IgnoreRepoSettings bool `json:"ignore_repo_settings,omitempty"`
+
+	// Lock the environment on origin while releasing it, so teammates don't release it at once (see release_lock.go)
+	ReleaseLock bool `json:"release_lock,omitempty"`
+
+	// Release MR description template (default a checklist of the stitched MRs, see release_mr.go)
+	ReleaseMRTemplate     string `json:"release_mr_template,omitempty"`
+	ReleaseMRTemplateFile string `json:"release_mr_template_file,omitempty"` // Read the template from a file, relative to the project directory
+
+	// Pages that release notes are published to after a release completes
+	ReleaseNotes []ReleaseNotesConfig `json:"release_notes,omitempty"`
+
+	// Recurring release windows, shown on the home screen and in the calendar feed
+	ReleaseWindows []ReleaseWindow `json:"release_windows,omitempty"`
+
+	// Record completed releases as GitLab deployments and show what each environment runs (see deployments.go)
+	GitLabDeployments bool `json:"gitlab_deployments,omitempty"`
+
+	// Directory pipeline job artifacts are downloaded to (default ~/Downloads)
+	ArtifactsDir string `json:"artifacts_dir,omitempty"`
+
+	// Files written by the screenshot command (see screenshot.go)
+	ScreenshotFormat string `json:"screenshot_format,omitempty"` // ansi (default), html or svg
+	ScreenshotDir    string `json:"screenshot_dir,omitempty"`    // Default ~/.relix/screenshots
+
+	// Sentry DSN crash reports are submitted to; reports are only written to disk without it
+	SentryDSN string `json:"sentry_dsn,omitempty"`
+
+	// Lua hook script, relative to the project root (default ~/.relix/hooks.lua if it exists)
+	HooksScript string `json:"hooks_script,omitempty"`
+
+	// Scheduled pipelines a release may collide with, e.g. a nightly deploy (see pipeline_schedules.go)
+	ScheduleWindow       int  `json:"schedule_window,omitempty"`         // Minutes before a scheduled pipeline a release warns about it (default 30)
+	DelayTagForSchedules bool `json:"delay_tag_for_schedules,omitempty"` // Push root branches waits until the scheduled pipelines passed
+
+	// Versions of the released projects (see version_scheme.go): semver (default), calver, build or
+	// regex; .restitcher.yaml can choose another scheme per project
+	VersionScheme  string `json:"version_scheme,omitempty"`
+	VersionPattern string `json:"version_pattern,omitempty"` // Pattern a version must match with the regex scheme
+
+	// Command menu entries running shell commands, e.g. team-specific release chores
+	Commands []CustomCommand `json:"commands,omitempty"`
+
+	// Named sets of projects, environments, notifications and theme, and the one in use (see workspaces.go)
+	Workspaces      []WorkspaceConfig `json:"workspaces,omitempty"`
+	ActiveWorkspace string            `json:"active_workspace,omitempty"`
+
+	// Images in the terminal: auto (default, detected), kitty, sixel or off (see graphics.go)
+	TerminalGraphics string `json:"terminal_graphics,omitempty"`
+
+	// Overrides of the terminal capabilities probed at startup (see term_caps.go)
+	Terminal *TerminalConfig `json:"terminal,omitempty"`
+
+	// Static text instead of spinners, the progress bar marker and blinking cursors (see progress.go)
+	ReduceMotion bool `json:"reduce_motion,omitempty"`
+
+	// No hints under the focused input of the auth, environment and version screens (see field_hints.go)
+	HideFieldHints bool `json:"hide_field_hints,omitempty"`
+
+	// No startup check for a newer relix release (see update_check.go)
+	DisableUpdateCheck bool `json:"disable_update_check,omitempty"`
+
+	// No startup selection of the project whose clone relix is launched in (see project_detect.go)
+	DisableProjectDetection bool `json:"disable_project_detection,omitempty"`
+
+	// Dates and times on screens, in history and audit output (see timefmt.go)
+	TimeZone      string `json:"time_zone,omitempty"`       // "local" (default), "UTC" or an IANA name, e.g. "Europe/Berlin"
+	AuditTimeZone string `json:"audit_time_zone,omitempty"` // Time zone of "relix audit" (default time_zone)
+	DateFormat    string `json:"date_format,omitempty"`     // Locale preset, "locale" or a Go layout (default dd.MM.yyyy HH:mm)
+
 	// Theme settings
 	SelectedTheme string        `json:"selected_theme,omitempty"` // Name of the active theme
 	Themes        []ThemeConfig `json:"themes,omitempty"`         // Available themes
 }
 
+// HTTPTimeouts are forge API timeouts as durations, e.g. "30s" or "2m"; "0" means no limit.
+// Each request gets the timeout of its class, or read if the class has none.
+type HTTPTimeouts struct {
+	Connect string `json:"connect,omitempty"` // Dialing and TLS handshake (default 10s)
+	Read    string `json:"read,omitempty"`    // Whole request including the response body (default 10s)
+	Listing string `json:"listing,omitempty"` // Paginated lists: projects, MRs, pipelines, jobs
+	Diff    string `json:"diff,omitempty"`    // MR changes, diffs and branch comparisons
+	Trace   string `json:"trace,omitempty"`   // Streamed job logs and artifact downloads (default no limit)
+}
+
+// ReleaseWindow is a recurring time slot in which releases to an environment are planned
+type ReleaseWindow struct {
+	Environment string   `json:"environment"`
+	Days        []string `json:"days"`               // Weekdays: "mon", "tue", ... (full names work too)
+	Time        string   `json:"time"`               // Local start time, "HH:MM"
+	Duration    string   `json:"duration,omitempty"` // e.g. "90m" (default "1h")
+	Title       string   `json:"title,omitempty"`    // Event title (default "{ENV} release window")
+}
+
+// CustomCommand is a command menu entry running a shell command (see custom_commands.go)
+type CustomCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Run         string `json:"run"`                   // Shell command template, e.g. "make changelog VERSION={{quote .Version}}"
+	Arg         string `json:"arg,omitempty"`         // Placeholder of an argument prompted for, e.g. "<ticket>" (.Arg in run)
+	ShowOutput  bool   `json:"show_output,omitempty"` // Run in the background and show the output in an overlay
+}
+
+// WorkspaceConfig is a named set of projects, environments, notification targets and theme,
+// swapped in at once by the workspace command (see workspaces.go)
+type WorkspaceConfig struct {
+	Name            string               `json:"name"`
+	PinnedProjects  []Project            `json:"pinned_projects,omitempty"`
+	SelectedProject *Project             `json:"selected_project,omitempty"`
+	Environments    []EnvConfig          `json:"environments,omitempty"`
+	BaseBranch      string               `json:"base_branch,omitempty"`
+	Notifications   []NotificationConfig `json:"notifications,omitempty"`
+	Theme           string               `json:"theme,omitempty"`
+}
+
 // ReleaseStep represents a step in the release process
 type ReleaseStep int
 
@@ -173,6 +530,8 @@ const (
 	ReleaseStepPushRootBranches            // Step 9: tag/push source branch, merge to root, push root, merge to develop, push develop
 	ReleaseStepSwitchToRoot                // Step 10: switch back to root branch
 	ReleaseStepComplete                    // Done
+	// Steps added later are appended: saved release states store steps by number
+	ReleaseStepDeployPipeline // Step 11: run the deployment pipeline, if configured, and wait for it
 )
 
 // ReleaseError holds error details for a failed step
@@ -182,6 +541,15 @@ type ReleaseError struct {
 	Message string      `json:"message"`
 }
 
+// ReleaseApproval records who let a release past a gated step, for its evidence bundle (see
+// release_evidence.go)
+type ReleaseApproval struct {
+	Gate string    `json:"gate"` // "Create MR" or "Push root branches"
+	By   string    `json:"by"`   // OS account of the operator, or the Telegram user
+	Via  string    `json:"via"`  // "tui", "telegram" or "headless"
+	Time time.Time `json:"time"`
+}
+
 // ReleaseState represents the persistent state of an in-progress release
 type ReleaseState struct {
 	// Selection info
@@ -189,6 +557,8 @@ type ReleaseState struct {
 	MRBranches           []string    `json:"mr_branches"`             // Source branches in merge order
 	MRURLs               []string    `json:"mr_urls,omitempty"`       // MR URLs corresponding to each branch
 	MRCommitSHAs         []string    `json:"mr_commit_shas,omitempty"` // Commit SHAs of branch heads at release time
+	MRTitles             []string    `json:"mr_titles,omitempty"`      // MR titles, for commit message templates
+	MRAuthors            []string    `json:"mr_authors,omitempty"`     // MR author usernames, for commit message templates
 	Environment          Environment `json:"environment"`
 	Version              string      `json:"version"`
 	BaseBranch           string      `json:"base_branch"`            // Base branch (e.g. "root") for crash-recovery
@@ -217,8 +587,45 @@ type ReleaseState struct {
 	CreatedMRURL string `json:"created_mr_url,omitempty"`
 	CreatedMRIID int    `json:"created_mr_iid,omitempty"`
 
+	// Texts written in the editor: the release MR description (replaces the template) and the
+	// release notes text (.Notes in release notes and MR templates)
+	MRDescription string `json:"mr_description,omitempty"`
+	ReleaseNotes  string `json:"release_notes,omitempty"`
+
+	Rollout *Rollout `json:"rollout,omitempty"` // Canary percentage and feature flags, if given
+
+	// Values of the deployment pipeline inputs, asked for before the release (see pipeline_inputs.go)
+	PipelineInputs []PipelineInputValue `json:"pipeline_inputs,omitempty"`
+
+	// Rollback (see rollback.go): the history ID of the release rolled back and the tag restored
+	RollbackOf  string `json:"rollback_of,omitempty"`
+	RollbackTag string `json:"rollback_tag,omitempty"`
+
+	// Release candidates (see release_candidates.go): the number of the candidate the release cuts,
+	// and the history ID of the candidate a promotion made final
+	Candidate    int    `json:"candidate,omitempty"`
+	PromotedFrom string `json:"promoted_from,omitempty"`
+
 	// Tag info (created during root push step)
-	TagName string `json:"tag_name,omitempty"`
+	TagName        string `json:"tag_name,omitempty"`
+	ReleaseVersion string `json:"release_version,omitempty"` // Version formatted by the environment's rules, set with TagName
+
+	// Deployment pipeline started by the release, so a resumed release waits for it instead of starting another
+	DeployPipelineID  int    `json:"deploy_pipeline_id,omitempty"`
+	DeployPipelineURL string `json:"deploy_pipeline_url,omitempty"`
+
+	// Merge trains and merged results pipelines of the project (see merge_trains.go), read when fetching
+	MergeProtection *MergeProtection `json:"merge_protection,omitempty"`
+
+	// Whether the release holds the lock of its environment (see release_lock.go)
+	// Day the release started, the {date} of its versions (YYYYMMDD; see FormatReleaseVersion)
+	VersionDate string `json:"version_date,omitempty"`
+
+	Locked     bool   `json:"locked,omitempty"`
+	LockCommit string `json:"lock_commit,omitempty"` // Lock commit pushed by the release, released only while the lock still points at it
+
+	// Gates passed so far, in order (see release_evidence.go)
+	Approvals []ReleaseApproval `json:"approvals,omitempty"`
 
 	// Working directory
 	WorkDir string `json:"work_dir"` // Project root path
@@ -254,9 +661,10 @@ type releaseCommandEndMsg struct {
 }
 
 type releaseStepCompleteMsg struct {
-	step   ReleaseStep
-	err    error
-	output string
+	step            ReleaseStep
+	err             error
+	output          string
+	mergeProtection *MergeProtection // Read by the fetch step, nil if the project uses neither
 }
 
 type existingReleaseMsg struct {
@@ -264,9 +672,11 @@ type existingReleaseMsg struct {
 }
 
 type releaseMRCreatedMsg struct {
-	url string
-	iid int
-	err error
+	url      string
+	iid      int
+	err      error
+	train    bool  // Whether the MR was added to the merge train
+	trainErr error // Why adding it to the merge train failed
 }
 
 type setProgramMsg struct {
@@ -306,6 +716,43 @@ type Pipeline struct {
 	ID     int    `json:"id"`
 	Status string `json:"status"`
 	WebURL string `json:"web_url"`
+	Ref    string `json:"ref,omitempty"` // Branch or tag the pipeline runs for
+}
+
+// Tag is a tag of a project's repository
+type Tag struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"` // SHA of the tagged commit
+}
+
+// PipelineSchedule represents a scheduled pipeline of a GitLab project (API response)
+type PipelineSchedule struct {
+	ID          int        `json:"id"`
+	Description string     `json:"description"`
+	Ref         string     `json:"ref"`
+	Cron        string     `json:"cron"`
+	NextRunAt   *time.Time `json:"next_run_at"`
+	Active      bool       `json:"active"`
+}
+
+// Iteration represents a GitLab iteration, a sprint of a group (API response)
+type Iteration struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"` // Empty for iterations of an automatic cadence
+	StartDate string `json:"start_date"`
+	DueDate   string `json:"due_date"`
+}
+
+// Deployment represents a GitLab deployment of a ref to an environment (API response)
+type Deployment struct {
+	ID        int       `json:"id"`
+	Ref       string    `json:"ref"`
+	SHA       string    `json:"sha"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Username string `json:"username"`
+	} `json:"user"`
 }
 
 // PipelineJob represents a GitLab pipeline job (API response)
@@ -315,6 +762,15 @@ type PipelineJob struct {
 	Status string `json:"status"`
 	Stage  string `json:"stage"`
 	WebURL string `json:"web_url"`
+
+	// Artifacts archive of the job; nil if it has none (or the forge does not report artifacts)
+	ArtifactsFile *JobArtifactsFile `json:"artifacts_file,omitempty"`
+}
+
+// JobArtifactsFile describes the artifacts archive of a GitLab job
+type JobArtifactsFile struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
 }
 
 // PipelineStatus represents the current state of the pipeline observer
@@ -333,14 +789,34 @@ type PipelineStatus struct {
 }
 
 // pipelineTickMsg triggers a pipeline status check
-type pipelineTickMsg struct{}
+type pipelineTickMsg struct {
+	gen int // Observer generation; ticks of a stopped or restarted observer are dropped
+}
 
 // pipelineStatusMsg contains the result of a pipeline status check
 type pipelineStatusMsg struct {
+	gen    int
 	status *PipelineStatus
 	err    error
 }
 
+// mrPollTickMsg triggers a background refresh of the open MR list
+type mrPollTickMsg struct {
+	gen int // Background poll generation; ticks from before a logout are dropped
+}
+
+// tokenPollTickMsg triggers a background check of the GitLab token
+type tokenPollTickMsg struct {
+	gen int
+}
+
+// tokenStatusMsg contains the result of a GitLab token check
+type tokenStatusMsg struct {
+	gen       int
+	expiresAt *time.Time // nil if the token never expires or GitLab does not report it
+	err       error
+}
+
 // HistoryIndexEntry represents a single entry in the history index (for quick list display)
 type HistoryIndexEntry struct {
 	ID          string    `json:"id"`
@@ -351,6 +827,14 @@ type HistoryIndexEntry struct {
 	MRCount     int       `json:"mr_count"`
 	Status      string    `json:"status"` // "completed" or "aborted"
 	Version     string    `json:"version"`
+	RollbackOf  string    `json:"rollback_of,omitempty"` // ID of the release this one rolled back (see rollback.go)
+	ProjectID   int       `json:"project_id,omitempty"`  // Released project; 0 for releases saved before it was recorded
+	ReleasedBy  string    `json:"released_by,omitempty"` // Forge account (login email) that ran the release; empty for releases saved before it was recorded
+
+	FailureCategory string `json:"failure_category,omitempty"` // Post-mortem category of an aborted release (see post_mortem.go)
+
+	Candidate    int    `json:"candidate,omitempty"`     // Number of the release candidate (see release_candidates.go)
+	PromotedFrom string `json:"promoted_from,omitempty"` // ID of the candidate this promotion made final
 }
 
 // ThemeANSIMap records the ANSI escape sequences lipgloss produced for each
@@ -367,16 +851,25 @@ type ThemeANSIMap struct {
 type ReleaseHistoryEntry struct {
 	HistoryIndexEntry
 	MRBranches     []string      `json:"mr_branches"`
-	MRURLs         []string      `json:"mr_urls,omitempty"`         // MR URLs corresponding to each branch
-	MRIIDs         []int         `json:"mr_iids,omitempty"`         // MR IIDs corresponding to each branch
-	MRCommitSHAs   []string      `json:"mr_commit_shas,omitempty"`  // Commit SHAs of branch heads at release time
+	MRURLs         []string      `json:"mr_urls,omitempty"`        // MR URLs corresponding to each branch
+	MRIIDs         []int         `json:"mr_iids,omitempty"`        // MR IIDs corresponding to each branch
+	MRCommitSHAs   []string      `json:"mr_commit_shas,omitempty"` // Commit SHAs of branch heads at release time
+	MRTitles       []string      `json:"mr_titles,omitempty"`      // MR titles corresponding to each branch
+	MRAuthors      []string      `json:"mr_authors,omitempty"`     // MR author usernames corresponding to each branch
+	Project        string        `json:"project,omitempty"`        // Path of the released project; empty for releases saved before it was recorded
 	SourceBranch   string        `json:"source_branch"`
 	EnvBranch      string        `json:"env_branch"`
 	RootMerge      bool          `json:"root_merge"`
 	EnvMergeMode   string        `json:"env_merge_mode,omitempty"` // "squash" or "regular"
 	CreatedMRURL   string        `json:"created_mr_url"`
-	TerminalOutput []string      `json:"terminal_output"`
+	TerminalOutput []string      `json:"terminal_output,omitempty"` // Releases saved before log files; newer ones use {id}.log(.zst)
+	LogFrames      []int64       `json:"log_frames,omitempty"`      // Offsets of the zstd frames of {id}.log.zst; none for a plain {id}.log
 	ThemeANSIMap   *ThemeANSIMap `json:"theme_ansi_map,omitempty"`
+	Annotation     string        `json:"annotation,omitempty"`  // Written afterwards in the history detail screen
+	PostMortem     string        `json:"post_mortem,omitempty"` // What went wrong, written when the release was aborted
+	Rollout        *Rollout      `json:"rollout,omitempty"`
+
+	PipelineInputs []PipelineInputValue `json:"pipeline_inputs,omitempty"` // Secret values are redacted
 }
 
 // fetchHistoryMsg is sent when history index is loaded
@@ -391,6 +884,14 @@ type loadHistoryDetailMsg struct {
 	err   error
 }
 
+// historyLogChunkMsg contains a chunk of a history log, read backwards from the end
+type historyLogChunkMsg struct {
+	id    string
+	lines []string
+	start int64 // Offset of the first line (or frame); 0 at the start of the file
+	err   error
+}
+
 // fetchHistoryMRMsg is sent when history MR details are fetched
 type fetchHistoryMRMsg struct {
 	details *MergeRequestDetails
@@ -411,5 +912,5 @@ type historyListItem struct {
 func (i historyListItem) Title() string       { return i.entry.Tag }
 func (i historyListItem) Description() string { return i.entry.Environment }
 func (i historyListItem) FilterValue() string {
-	return i.entry.Tag + " " + i.entry.Environment + " " + i.entry.Version
+	return i.entry.Tag + " " + i.entry.Environment + " " + i.entry.Version + " " + i.entry.FailureCategory
 }
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Update check: on startup the TUI looks up the latest release of relix on GitHub, at most once a
+// day, and the home screen shows a banner with a summary of its changes while it is newer than the
+// running version. The full release notes open in an overlay; dismissing the banner hides it until
+// the next release. disable_update_check turns the check off.
+
+const (
+	updateRepository    = "miraxsage/reStitcher"
+	updateCheckInterval = 24 * time.Hour
+	updateCheckFileName = "update_check.json"
+	updateSummaryLines  = 3 // Lines of the release notes shown on the banner
+)
+
+// updateAPIURL is the GitHub API endpoint of the latest release
+var updateAPIURL = "https://api.github.com/repos/" + updateRepository + "/releases/latest"
+
+// relixRelease is a release of relix
+type relixRelease struct {
+	Tag   string `json:"tag_name"`
+	Name  string `json:"name"`
+	Notes string `json:"body"`
+	URL   string `json:"html_url"`
+}
+
+// updateCheckState is the on-disk state of the update check
+type updateCheckState struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	Latest    *relixRelease `json:"latest,omitempty"`
+	Dismissed string        `json:"dismissed,omitempty"` // Tag of the release whose banner was dismissed
+}
+
+// updateAvailableMsg carries a release newer than the running version
+type updateAvailableMsg struct {
+	release *relixRelease
+}
+
+// getUpdateCheckPath returns the path of the update check state file
+func getUpdateCheckPath() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, updateCheckFileName), nil
+}
+
+// loadUpdateCheckState reads the update check state; empty if there is none yet
+func loadUpdateCheckState() updateCheckState {
+	var state updateCheckState
+	if path, err := getUpdateCheckPath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &state)
+		}
+	}
+	return state
+}
+
+// saveUpdateCheckState writes the update check state
+func saveUpdateCheckState(state updateCheckState) error {
+	path, err := getUpdateCheckPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// fetchLatestRelease looks up the latest release of relix on GitHub
+func fetchLatestRelease() (*relixRelease, error) {
+	req, err := http.NewRequest("GET", updateAPIURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "relix/"+AppVersion)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error: status %d", resp.StatusCode)
+	}
+
+	var release relixRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// checkForUpdate returns the command looking up a newer release, from the state file when the
+// last lookup is recent. Nothing is reported with disable_update_check, when the running version
+// is the latest or when the banner of the latest release was dismissed.
+func checkForUpdate() tea.Cmd {
+	return func() tea.Msg {
+		if config, err := LoadConfig(); err != nil || config.DisableUpdateCheck {
+			return nil
+		}
+		state := loadUpdateCheckState()
+		if time.Since(state.CheckedAt) >= updateCheckInterval {
+			release, err := fetchLatestRelease()
+			if err != nil {
+				return nil
+			}
+			state.CheckedAt, state.Latest = time.Now(), release
+			saveUpdateCheckState(state)
+		}
+		if state.Latest == nil || state.Latest.Tag == state.Dismissed || !newerVersion(state.Latest.Tag, AppVersion) {
+			return nil
+		}
+		return updateAvailableMsg{release: state.Latest}
+	}
+}
+
+// newerVersion reports whether version a, e.g. "v1.2.0", is newer than version b. Missing
+// components count as 0 and suffixes such as "-rc1" are ignored.
+func newerVersion(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// versionParts returns the numeric components of a version
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// title returns the name of the release, its tag if it has none
+func (r *relixRelease) title() string {
+	if name := strings.TrimSpace(r.Name); name != "" {
+		return name
+	}
+	return r.Tag
+}
+
+// summary returns the first lines of the release notes past headings, with markdown markers
+// stripped
+func (r *relixRelease) summary() []string {
+	markers := strings.NewReplacer("**", "", "__", "", "`", "")
+	var lines []string
+	for _, line := range strings.Split(r.Notes, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(markers.Replace(strings.TrimLeft(line, "*-+> ")))
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == updateSummaryLines {
+			break
+		}
+	}
+	return lines
+}
+
+// dismissUpdate hides the banner of the available release until the next one
+func (m *model) dismissUpdate() {
+	if m.availableUpdate == nil {
+		return
+	}
+	state := loadUpdateCheckState()
+	state.Dismissed = m.availableUpdate.Tag
+	saveUpdateCheckState(state)
+	m.availableUpdate = nil
+	m.showUpdateNotes = false
+}
+
+// updateBanner renders the banner of the available release for the home screen, empty without one
+func (m model) updateBanner(width int) string {
+	if m.availableUpdate == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Accent).Render(
+		fmt.Sprintf("relix %s is available (running v%s)", m.availableUpdate.Tag, AppVersion)))
+	for _, line := range m.availableUpdate.summary() {
+		b.WriteString("\n" + truncateWithEllipsis(line, max(width-4, 10)))
+	}
+	b.WriteString("\n" + helpStyle.Render("u: release notes • x: dismiss"))
+	return lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(b.String())
+}
+
+// updateUpdateNotes handles keys of the release notes overlay
+func (m model) updateUpdateNotes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		m.updateNotesOffset = max(m.updateNotesOffset-1, 0)
+	case "down", "j":
+		lines, visible := m.updateNotesLines()
+		m.updateNotesOffset = min(m.updateNotesOffset+1, max(len(lines)-visible, 0))
+	case "o":
+		if m.availableUpdate != nil && m.availableUpdate.URL != "" {
+			return m, openInBrowser(m.availableUpdate.URL)
+		}
+	case "x":
+		m.dismissUpdate()
+	case "u", "enter", "esc", "q", "ctrl+q":
+		m.showUpdateNotes = false
+	}
+	return m, nil
+}
+
+// updateNotesWidth returns the width of the release notes overlay
+func (m model) updateNotesWidth() int {
+	return min(max(m.width*70/100, 40), 100)
+}
+
+// updateNotesLines returns the rendered release notes and how many of their lines fit the overlay
+func (m model) updateNotesLines() ([]string, int) {
+	notes := m.availableUpdate.Notes
+	if strings.TrimSpace(notes) == "" {
+		notes = "_No release notes._"
+	}
+	if renderer, err := glamour.NewTermRenderer(glamour.WithStyles(markdownStyle()), glamour.WithColorProfile(termPolicy.colors), glamour.WithWordWrap(m.updateNotesWidth()-8)); err == nil {
+		if rendered, err := renderer.Render(notes); err == nil {
+			notes = strings.Trim(rendered, "\n")
+		}
+	}
+	return strings.Split(notes, "\n"), max(m.height-14, 5)
+}
+
+// overlayUpdateNotes renders the release notes of the available release, scrolled within the
+// screen height
+func (m model) overlayUpdateNotes(background string) string {
+	if m.availableUpdate == nil {
+		return background
+	}
+	config := CommandMenuModalConfig()
+	width := m.updateNotesWidth()
+	config.Width, config.MaxWidth = ModalWidth{Value: width}, width
+
+	lines, visible := m.updateNotesLines()
+	offset := min(m.updateNotesOffset, max(len(lines)-visible, 0))
+	lines = lines[offset:min(offset+visible, len(lines))]
+
+	var b strings.Builder
+	b.WriteString(commandMenuTitleStyle.Render("relix " + m.availableUpdate.title()))
+	b.WriteString("\n\n")
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("↑/↓: scroll • o: open on GitHub • x: dismiss • C+q: close"))
+
+	modal := renderModal(b.String(), config, m.width)
+	return placeOverlayCenter(modal, background, m.width, m.height)
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
@@ -88,6 +92,25 @@ func placeOverlayCenter(fg, bg string, width, height int) string {
 	return placeOverlay(x, y, fg, bg)
 }
 
+// outputScrollStep is how many columns "<" and ">" scroll truncated output lines sideways
+const outputScrollStep = 8
+
+// wrapLines hard-wraps lines wider than width; escape sequences do not count toward the width
+func wrapLines(lines []string, width int) []string {
+	if width <= 0 {
+		return lines
+	}
+	wrapped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if ansi.StringWidth(line) <= width {
+			wrapped = append(wrapped, line)
+			continue
+		}
+		wrapped = append(wrapped, strings.Split(ansi.Hardwrap(line, width, true), "\n")...)
+	}
+	return wrapped
+}
+
 // maxLineWidth returns the maximum width among all lines
 func maxLineWidth(lines []string) int {
 	max := 0
@@ -104,6 +127,18 @@ func stringPtr(s string) *string { return &s }
 
 func boolPtr(b bool) *bool { return &b }
 
+// expandHome replaces a leading ~ in a configured path with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
 // sidebarWidth returns sidebar width: max(32, terminalWidth/3)
 func sidebarWidth(terminalWidth int) int {
 	third := terminalWidth / 3
@@ -178,3 +213,23 @@ func openInSafariWithFallback(url string) tea.Cmd {
 		return nil
 	}
 }
+
+// fitPagination switches a list to numeric pagination ("3/250") when its page dots would not fit the width.
+// The list makes the same switch while rendering, but on a copy, so otherwise a dot for every page is
+// built and measured on each frame and long lists get slower to draw the more items they hold.
+// Call it after changing the items or the size of a list.
+func fitPagination(l *list.Model) {
+	if l.Paginator.TotalPages > l.Width() {
+		l.Paginator.Type = paginator.Arabic
+	} else {
+		l.Paginator.Type = paginator.Dots
+	}
+}
+
+// plural returns one for a count of 1, otherwise many
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The version scheme of a project decides which versions the version screen accepts and which it
+// suggests: semver (the default, X.Y.Z), calver (YYYY.MM.N, e.g. 2025.06.2), build (a build number
+// incremented with each release) or regex (any version matching version_pattern). It is chosen
+// with version_scheme in the user config, or per project in .restitcher.yaml.
+
+// Version validation regex of the semver scheme: matches versions like 1.0, 1.0.0, 1.0.0.0
+var versionRegex = regexp.MustCompile(`^\d+(\.\d+){1,3}$`)
+
+// versionScheme validates the versions of a project and suggests the next one
+type versionScheme struct {
+	name    string
+	pattern *regexp.Regexp
+	format  string                                    // Accepted format, for error messages
+	example string                                    // Placeholder of the version input
+	next    func(latest string, now time.Time) string // Version following latest ("" if none is released yet)
+}
+
+// newVersionScheme returns the scheme of a version_scheme setting; pattern is the version_pattern
+// of the regex scheme
+func newVersionScheme(name, pattern string) (*versionScheme, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "semver":
+		return &versionScheme{
+			name:    "semver",
+			pattern: versionRegex,
+			format:  "X.Y, X.Y.Z or X.Y.Z.W",
+			example: "e.g. 1.2.3",
+			next:    nextSemver,
+		}, nil
+	case "calver":
+		return &versionScheme{
+			name:    "calver",
+			pattern: regexp.MustCompile(`^\d{4}\.(0[1-9]|1[0-2])\.\d+$`),
+			format:  "YYYY.MM.N, e.g. 2025.06.2",
+			example: "e.g. " + time.Now().Format("2006.01") + ".1",
+			next:    nextCalver,
+		}, nil
+	case "build":
+		return &versionScheme{
+			name:    "build",
+			pattern: regexp.MustCompile(`^\d+$`),
+			format:  "a build number, e.g. 42",
+			example: "e.g. 42",
+			next:    nextBuild,
+		}, nil
+	case "regex":
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("version_scheme regex needs a version_pattern")
+		}
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version_pattern: %w", err)
+		}
+		scheme := &versionScheme{
+			name:    "regex",
+			pattern: re,
+			format:  "a version matching " + pattern,
+		}
+		scheme.next = func(latest string, _ time.Time) string {
+			// The last number of the latest version incremented, if the result still matches
+			if next := incrementLastNumber(latest); next != "" && re.MatchString(next) {
+				return next
+			}
+			return ""
+		}
+		return scheme, nil
+	}
+	return nil, fmt.Errorf("unknown version_scheme %q (use semver, calver, build or regex)", name)
+}
+
+// activeVersionScheme returns the version scheme of the active project
+func activeVersionScheme() (*versionScheme, error) {
+	config, err := loadActiveConfig()
+	if err != nil {
+		return newVersionScheme("", "")
+	}
+	return newVersionScheme(config.VersionScheme, config.VersionPattern)
+}
+
+// valid reports whether a version follows the scheme; empty is valid (no error shown yet)
+func (s *versionScheme) valid(version string) bool {
+	return version == "" || s.pattern.MatchString(version)
+}
+
+// suggest returns the version to suggest after latest, or "" if the scheme has no suggestion
+func (s *versionScheme) suggest(latest string) string {
+	if s.next == nil {
+		return ""
+	}
+	if latest != "" && !s.pattern.MatchString(latest) {
+		latest = "" // Released under another scheme
+	}
+	return s.next(latest, time.Now())
+}
+
+// nextSemver bumps the patch part, e.g. 1.2.3 -> 1.2.4
+func nextSemver(latest string, _ time.Time) string {
+	if latest == "" {
+		return "1.0.0"
+	}
+	next, err := bumpVersion(latest, "patch")
+	if err != nil {
+		return ""
+	}
+	return next
+}
+
+// nextCalver returns the next release of the current month: 2025.06.2 after 2025.06.1, and
+// 2025.07.1 once the month changed
+func nextCalver(latest string, now time.Time) string {
+	month := now.Format("2006.01")
+	if n, ok := strings.CutPrefix(latest, month+"."); ok {
+		if count, err := strconv.Atoi(n); err == nil {
+			return fmt.Sprintf("%s.%d", month, count+1)
+		}
+	}
+	return month + ".1"
+}
+
+// nextBuild increments the build number
+func nextBuild(latest string, _ time.Time) string {
+	n, err := strconv.Atoi(latest)
+	if err != nil {
+		return "1"
+	}
+	return strconv.Itoa(n + 1)
+}
+
+// lastNumberRegex matches the last run of digits of a version
+var lastNumberRegex = regexp.MustCompile(`\d+(\D*)$`)
+
+// incrementLastNumber increments the last number of a version keeping its width, e.g.
+// "r2025-09" -> "r2025-10"; "" if the version has no number
+func incrementLastNumber(version string) string {
+	loc := lastNumberRegex.FindStringSubmatchIndex(version)
+	if loc == nil {
+		return ""
+	}
+	digits := version[loc[0]:loc[2]]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return ""
+	}
+	next := fmt.Sprintf("%0*d", len(digits), n+1)
+	return version[:loc[0]] + next + version[loc[2]:]
+}
+
+// schemeVersionSuggestion returns the version the scheme of the active project suggests after the
+// latest release to the selected environment
+func (m model) schemeVersionSuggestion() string {
+	scheme, err := activeVersionScheme()
+	if err != nil || m.selectedEnv == nil {
+		return ""
+	}
+	var latest string
+	for _, release := range m.versionLatest {
+		if release.found && release.env.BranchName == m.selectedEnv.BranchName {
+			// The version of an open release candidate cycle gets its next candidate
+			if release.open {
+				return release.version
+			}
+			latest = release.version
+		}
+	}
+	return scheme.suggest(latest)
+}
@@ -2,17 +2,15 @@ package main
 
 import (
 	"fmt"
-	"regexp"
+	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Version validation regex: matches versions like 1.0, 1.0.0, 1.0.0.0
-var versionRegex = regexp.MustCompile(`^\d+(\.\d+){1,3}$`)
-
 // Styles for version input screen
 var (
 	versionInputStyle = lipgloss.NewStyle().
@@ -23,6 +21,9 @@ var (
 func initVersionInput() textinput.Model {
 	ti := textinput.New()
 	ti.Placeholder = "e.g. 1.2.3"
+	if scheme, err := activeVersionScheme(); err == nil && scheme.example != "" {
+		ti.Placeholder = scheme.example
+	}
 	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(currentTheme.Notion)
 	ti.Focus()
 	ti.CharLimit = 20
@@ -33,12 +34,11 @@ func initVersionInput() textinput.Model {
 	return ti
 }
 
-// validateVersion checks if the version string is valid
+// validateVersion checks if the version string follows the version scheme of the active project.
+// Empty is valid (no error shown yet).
 func validateVersion(version string) bool {
-	if version == "" {
-		return true // Empty is valid (no error shown yet)
-	}
-	return versionRegex.MatchString(version)
+	scheme, err := activeVersionScheme()
+	return err == nil && scheme.valid(version)
 }
 
 // updateVersion handles key events on the version input screen
@@ -55,9 +55,18 @@ func (m model) updateVersion(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
-		m.screen = screenEnvSelect
+		m.goBack()
 		m.versionError = ""
 		return m, nil
+	case "tab":
+		// Take the version suggested by the version scheme
+		if m.versionSuggestion == "" || m.versionInput.Value() == m.versionSuggestion {
+			return m, nil
+		}
+		m.versionInput.SetValue(m.versionSuggestion)
+		m.versionInput.CursorEnd()
+		m.versionEnterPending = false
+		return m, m.checkVersion()
 	case "enter":
 		// Validate and proceed
 		version := m.versionInput.Value()
@@ -65,53 +74,215 @@ func (m model) updateVersion(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.versionError = "Version is required"
 			return m, nil
 		}
-		if !validateVersion(version) {
-			m.versionError = "Invalid version format. Use: X.Y, X.Y.Z, or X.Y.Z.W"
+		if problem := m.versionProblem(); problem != "" {
+			m.versionError = problem
 			return m, nil
 		}
-		// Version is valid - proceed to source branch screen
-		m.versionError = ""
-		m.screen = screenSourceBranch
-		// Initialize source branch input if not done or if empty
-		if m.sourceBranchInput.CharLimit == 0 || m.sourceBranchInput.Value() == "" {
-			checkCmd := m.initSourceBranchInput()
-			return m, checkCmd
-		} else {
-			// Source branch already exists - check if we need to update version in it
-			currentBranch := m.sourceBranchInput.Value()
-			oldVersion := m.sourceBranchVersion
-			if oldVersion != "" && oldVersion != version && strings.Contains(currentBranch, oldVersion) {
-				// Replace old version with new version in the branch name
-				newBranch := strings.Replace(currentBranch, oldVersion, version, -1)
-				m.sourceBranchInput.SetValue(newBranch)
-				m.sourceBranchVersion = version
-				// Trigger new remote check for updated branch name
-				if m.validateSourceBranch(newBranch) {
-					m.sourceBranchRemoteStatus = "checking"
-					m.sourceBranchCheckedName = newBranch
-					checkCmd := m.checkSourceBranchRemote(newBranch)
-					m.sourceBranchInput.Focus()
-					return m, tea.Batch(checkCmd, m.spinner.Tick)
-				}
-			}
-			// Just focus the existing input
-			m.sourceBranchInput.Focus()
+		if m.versionCheckedFor != m.versionCheckKey() {
+			// Tag check still running - proceed once it reports the tag is free
+			m.versionEnterPending = true
+			m.versionCheckSeq++
+			return m, m.checkVersionTag()
 		}
-		return m, nil
+		if m.versionTagExists != "" {
+			return m, nil
+		}
+		return m.proceedFromVersion()
 	}
 
 	// Handle text input updates
 	var cmd tea.Cmd
+	before := m.versionInput.Value()
 	m.versionInput, cmd = m.versionInput.Update(msg)
+	if m.versionInput.Value() == before {
+		return m, cmd
+	}
+	m.versionEnterPending = false
+	checkCmd := m.checkVersion()
+	return m, tea.Batch(cmd, checkCmd)
+}
 
-	// Clear error when user types
-	if m.versionError != "" && validateVersion(m.versionInput.Value()) {
-		m.versionError = ""
-	} else if !validateVersion(m.versionInput.Value()) && m.versionInput.Value() != "" {
-		m.versionError = "Invalid version format. Use: X.Y, X.Y.Z, or X.Y.Z.W"
+// proceedFromVersion moves on to the source branch screen with the validated version
+func (m model) proceedFromVersion() (tea.Model, tea.Cmd) {
+	version := m.versionInput.Value()
+	m.versionError = ""
+	m.moveToScreen(screenSourceBranch)
+	// Initialize source branch input if not done or if empty
+	if m.sourceBranchInput.CharLimit == 0 || m.sourceBranchInput.Value() == "" {
+		checkCmd := m.initSourceBranchInput()
+		return m, checkCmd
+	} else {
+		// Source branch already exists - check if we need to update version in it
+		currentBranch := m.sourceBranchInput.Value()
+		oldVersion := m.sourceBranchVersion
+		if oldVersion != "" && oldVersion != version && strings.Contains(currentBranch, oldVersion) {
+			// Replace old version with new version in the branch name
+			newBranch := strings.Replace(currentBranch, oldVersion, version, -1)
+			m.sourceBranchInput.SetValue(newBranch)
+			m.sourceBranchVersion = version
+			// Trigger new remote check for updated branch name
+			if m.validateSourceBranch(newBranch) {
+				m.sourceBranchRemoteStatus = "checking"
+				m.sourceBranchCheckedName = newBranch
+				checkCmd := m.checkSourceBranchRemote(newBranch)
+				m.sourceBranchInput.Focus()
+				return m, tea.Batch(checkCmd, m.spinner.Tick)
+			}
+		}
+		// Just focus the existing input
+		m.sourceBranchInput.Focus()
+	}
+	return m, nil
+}
+
+// versionCheckTickMsg fires once typing in the version input pauses
+type versionCheckTickMsg struct {
+	seq int
+}
+
+// versionTagCheckMsg reports whether the release tag for a version already exists
+type versionTagCheckMsg struct {
+	key        string   // versionCheckKey the check was made for
+	tag        string   // Existing tag, empty if the tag is free
+	candidates []string // Tags of the candidates cut of the version so far (see release_candidates.go)
+}
+
+// envLatestRelease is the last released version found on an environment branch
+type envLatestRelease struct {
+	env     Environment
+	version string
+	vNum    int
+	found   bool
+	open    bool // A release candidate of version is not promoted yet
+}
+
+// versionLatestMsg carries the latest released version of each environment
+type versionLatestMsg struct {
+	releases []envLatestRelease
+}
+
+// versionCheckKey identifies the environment and version a tag check is made for
+func (m model) versionCheckKey() string {
+	if m.selectedEnv == nil {
+		return ""
+	}
+	return m.selectedEnv.BranchName + "|" + m.versionInput.Value()
+}
+
+// versionProblem returns why the entered version cannot be released, checking the format and
+// the git ref rules for the release tag and branch it produces; empty if there is no problem
+func (m model) versionProblem() string {
+	version := m.versionInput.Value()
+	if m.selectedEnv != nil && m.selectedEnv.TagPrefix != "" && strings.HasPrefix(version, m.selectedEnv.TagPrefix) {
+		return fmt.Sprintf("Enter the version without the %q prefix; %s adds it", m.selectedEnv.TagPrefix, m.selectedEnv.Name)
+	}
+	scheme, err := activeVersionScheme()
+	if err != nil {
+		return "Invalid version scheme: " + err.Error()
+	}
+	if !scheme.valid(version) {
+		return "Invalid version format. Use: " + scheme.format
+	}
+	if version == "" || m.selectedEnv == nil {
+		return ""
+	}
+	if err := checkRefFormat(ReleaseTagName(*m.selectedEnv, version, 1)); err != nil {
+		return "Invalid release tag: " + err.Error()
+	}
+	if err := checkRefFormat(fmt.Sprintf("release/rpb-%s-%s", version, m.selectedEnv.BranchName)); err != nil {
+		return "Invalid release branch: " + err.Error()
 	}
+	return ""
+}
+
+// checkVersion validates the entered version and schedules the debounced check of its tag
+func (m *model) checkVersion() tea.Cmd {
+	m.versionCheckSeq++
+	m.versionError = m.versionProblem()
+	if m.versionError != "" || m.versionInput.Value() == "" || m.selectedEnv == nil {
+		return nil
+	}
+	if m.versionCheckedFor == m.versionCheckKey() {
+		if m.versionTagExists != "" {
+			m.versionError = m.existingTagError(m.versionTagExists)
+		}
+		return nil
+	}
+	seq := m.versionCheckSeq
+	return tea.Tick(filterDebounce, func(time.Time) tea.Msg {
+		return versionCheckTickMsg{seq: seq}
+	})
+}
 
-	return m, cmd
+// checkVersionTag looks the release tag of the entered version up locally and on origin.
+// Errors (no network, no remote) count as the tag being free: the release itself will fail
+// loudly if the tag turns out to exist.
+func (m model) checkVersionTag() tea.Cmd {
+	if m.selectedEnv == nil {
+		return nil
+	}
+	key := m.versionCheckKey()
+	env := *m.selectedEnv
+	version := m.versionInput.Value()
+	project := m.selectedProject
+	return func() tea.Msg {
+		workDir, err := projectWorkDir(project)
+		if err != nil {
+			return versionTagCheckMsg{key: key}
+		}
+		vNumber, err := GetNextVersionNumber(workDir, env.BranchName, version)
+		if err != nil {
+			vNumber = 1
+		}
+		exists := func(tag string) bool {
+			if localTagExists(workDir, tag) {
+				return true
+			}
+			cmd := exec.Command("git", "ls-remote", "--tags", "origin", "refs/tags/"+tag)
+			cmd.Dir = workDir
+			output, err := cmd.Output()
+			return err == nil && strings.TrimSpace(string(output)) != ""
+		}
+
+		msg := versionTagCheckMsg{key: key}
+		if env.ReleaseCandidates {
+			// A promoted version gets no more candidates
+			msg.candidates = versionCandidates(workDir, env, version, vNumber)
+			if final := promotedTagName(env, version); exists(final) {
+				msg.tag = final
+				return msg
+			}
+		}
+		if tag := ReleaseTagName(env, version, vNumber); exists(tag) {
+			msg.tag = tag
+		}
+		return msg
+	}
+}
+
+// loadLatestVersions reads the latest released version of every environment from git log
+func (m model) loadLatestVersions() tea.Cmd {
+	envs := m.environments
+	project := m.selectedProject
+	return func() tea.Msg {
+		workDir, err := projectWorkDir(project)
+		if err != nil {
+			return versionLatestMsg{}
+		}
+		releases := make([]envLatestRelease, 0, len(envs))
+		for _, env := range envs {
+			version, vNum, found, err := LatestReleasedVersion(workDir, env.BranchName)
+			release := envLatestRelease{
+				env:     env,
+				version: version,
+				vNum:    vNum,
+				found:   found && err == nil,
+			}
+			release.open = release.found && env.ReleaseCandidates && !localTagExists(workDir, promotedTagName(env, version))
+			releases = append(releases, release)
+		}
+		return versionLatestMsg{releases: releases}
+	}
 }
 
 // viewVersion renders the version input screen
@@ -145,7 +316,7 @@ func (m model) viewVersion() string {
 	main := lipgloss.JoinHorizontal(lipgloss.Top, sidebar, content)
 
 	// Help footer
-	helpText := "enter: confirm • C+q: back • /: commands • C+c: quit"
+	helpText := "enter: confirm • tab: suggested version • C+q: back • " + m.fieldHintsHelp() + " • /: commands • C+c: quit"
 	help := helpStyle.Width(m.width).Align(lipgloss.Center).Render(helpText)
 
 	return lipgloss.JoinVertical(lipgloss.Left, main, help)
@@ -301,17 +472,35 @@ func (m model) renderVersionInput(width int) string {
 
 	// Prompt
 	prompt := "Input semantic number to version this release:"
+	if scheme, err := activeVersionScheme(); err == nil && scheme.name != "semver" {
+		prompt = fmt.Sprintf("Input %s version of this release:", scheme.name)
+	}
 	sb.WriteString(envPromptStyle.Render(prompt))
 	sb.WriteString("\n\n")
 
 	// Version input field
 	sb.WriteString(versionInputStyle.Render("Version: "))
 	sb.WriteString(m.versionInput.View())
+	if hint := m.renderFieldHint(versionFieldHint(), width); hint != "" {
+		sb.WriteString("\n")
+		sb.WriteString(hint)
+	}
+
+	// Next version of the version scheme, taken with tab
+	if m.versionSuggestion != "" && m.versionInput.Value() != m.versionSuggestion {
+		sb.WriteString("\n")
+		sb.WriteString(envHintBaseStyle.Render("Suggested: ") +
+			versionInputStyle.Render(m.versionSuggestion) +
+			envHintBaseStyle.Render(" (tab to use)"))
+	}
 
 	// Error message if any (uses same style as error modal)
 	if m.versionError != "" {
 		sb.WriteString("\n\n")
 		sb.WriteString(errorTitleStyle.Render(m.versionError))
+	} else if m.versionEnterPending {
+		sb.WriteString("\n\n")
+		sb.WriteString(envHintBaseStyle.Render("Checking existing tags…"))
 	}
 
 	sb.WriteString("\n\n")
@@ -338,5 +527,37 @@ func (m model) renderVersionInput(width int) string {
 		getEnvHintStyle(envName).Render(" "+envName+" ")
 	sb.WriteString(hint)
 
+	// Released version after the environment's format rules (v-number shown as 1, or the number
+	// of the next release candidate once known)
+	if m.selectedEnv != nil && m.selectedEnv.hasVersionFormat() {
+		n := 1
+		if m.selectedEnv.ReleaseCandidates && m.versionCheckedFor == m.versionCheckKey() {
+			n = len(m.versionCandidates) + 1
+		}
+		sb.WriteString("\n")
+		sb.WriteString(envHintBaseStyle.Render("Released as ") +
+			getEnvBranchStyle(envName).Render(FormatReleaseVersion(*m.selectedEnv, version, n)))
+		if len(m.versionCandidates) > 0 && m.versionCheckedFor == m.versionCheckKey() {
+			sb.WriteString("\n")
+			sb.WriteString(envHintBaseStyle.Render("Candidates so far: ") +
+				getEnvBranchStyle(envName).Render(strings.Join(m.versionCandidates, ", ")))
+		}
+	}
+
+	// Latest released version per environment, for reference
+	if len(m.versionLatest) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(envHintBaseStyle.Render("Latest releases:"))
+		for _, release := range m.versionLatest {
+			released := "none"
+			if release.found {
+				released = fmt.Sprintf("%s v%d", release.version, release.vNum)
+			}
+			sb.WriteString("\n")
+			sb.WriteString(getEnvHintStyle(release.env.Name).Render(" " + release.env.Name + " "))
+			sb.WriteString(envHintBaseStyle.Render(" " + released))
+		}
+	}
+
 	return sb.String()
 }
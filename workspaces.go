@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Workspaces bundle the settings of one team's releases (the pinned and selected projects,
+// environments, base branch, notification targets and theme) for people releasing for several
+// teams. "workspace <name>" in the command menu swaps them all at once: the settings in use are
+// saved to the workspace being left and those of the new one take their place, so editing them in
+// the settings screen edits the active workspace. A setting the workspace leaves out is reset to
+// its default rather than kept from the previous workspace. The settings in use before the first
+// switch are saved as the workspace "default".
+
+// defaultWorkspaceName is the workspace the settings in use before the first switch are saved to
+const defaultWorkspaceName = "default"
+
+// findWorkspace returns the index of the workspace with the given name, -1 if there is none
+func findWorkspace(workspaces []WorkspaceConfig, name string) int {
+	for i, w := range workspaces {
+		if strings.EqualFold(w.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// workspaceFromConfig returns the workspace settings in use in the config
+func workspaceFromConfig(config *AppConfig, name string) WorkspaceConfig {
+	w := WorkspaceConfig{
+		Name:           name,
+		PinnedProjects: config.PinnedProjects,
+		Environments:   config.Environments,
+		BaseBranch:     config.BaseBranch,
+		Notifications:  config.Notifications,
+		Theme:          config.SelectedTheme,
+	}
+	if config.SelectedProjectID != 0 {
+		w.SelectedProject = &Project{
+			ID:                config.SelectedProjectID,
+			Name:              config.SelectedProjectShortName,
+			PathWithNamespace: config.SelectedProjectPath,
+			NameWithNamespace: config.SelectedProjectName,
+		}
+	}
+	return w
+}
+
+// apply puts the settings of the workspace in use in the config
+func (w WorkspaceConfig) apply(config *AppConfig) {
+	config.ActiveWorkspace = w.Name
+	config.PinnedProjects = w.PinnedProjects
+	config.Environments = w.Environments
+	config.BaseBranch = w.BaseBranch
+	config.Notifications = w.Notifications
+	config.SelectedTheme = w.Theme
+	project := Project{}
+	if w.SelectedProject != nil {
+		project = *w.SelectedProject
+	}
+	config.SelectedProjectID = project.ID
+	config.SelectedProjectPath = project.PathWithNamespace
+	config.SelectedProjectName = project.NameWithNamespace
+	config.SelectedProjectShortName = project.Name
+}
+
+// SwitchWorkspace saves the settings in use to the active workspace, puts those of the named
+// workspace in their place and returns the updated config
+func SwitchWorkspace(name string) (*AppConfig, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	target := findWorkspace(config.Workspaces, name)
+	if target < 0 {
+		return nil, fmt.Errorf("no workspace named %q", name)
+	}
+	if strings.EqualFold(config.Workspaces[target].Name, config.ActiveWorkspace) {
+		return config, nil
+	}
+
+	current := config.ActiveWorkspace
+	if current == "" {
+		current = defaultWorkspaceName
+	}
+	if i := findWorkspace(config.Workspaces, current); i >= 0 {
+		if config.ActiveWorkspace != "" {
+			config.Workspaces[i] = workspaceFromConfig(config, config.Workspaces[i].Name)
+		}
+		// Before the first switch a workspace defined as "default" is kept as configured
+	} else {
+		config.Workspaces = append(config.Workspaces, workspaceFromConfig(config, current))
+	}
+
+	config.Workspaces[target].apply(config)
+	if err := SaveConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// workspaceSuggestions returns the workspace names for "workspace"
+func workspaceSuggestions(m model) []string {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(config.Workspaces))
+	for _, w := range config.Workspaces {
+		if !strings.EqualFold(w.Name, config.ActiveWorkspace) {
+			names = append(names, w.Name)
+		}
+	}
+	return names
+}
+
+// switchWorkspace switches to the named workspace and applies its settings to the screens. Not
+// while a release is in progress, as its notifications and environments would change midway.
+func (m model) switchWorkspace(name string) (tea.Model, tea.Cmd) {
+	m.closeAllModals()
+	if m.releaseState != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = "Complete or abort the release of this tab before switching workspace"
+		return m, nil
+	}
+	if n := m.parkedReleaseInProgress(); n != 0 {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("The release in tab %d is not finished: complete or abort it before switching workspace", n)
+		return m, nil
+	}
+	config, err := SwitchWorkspace(name)
+	if err != nil {
+		m.showErrorModal = true
+		m.errorModalMsg = fmt.Sprintf("workspace: %v", err)
+		return m, nil
+	}
+
+	currentTheme = selectedThemeColors(config)
+	rebuildStyles()
+	m.updateTextareaTheme()
+	m.applySpinnerTheme()
+	m.activeWorkspace = config.ActiveWorkspace
+	m.pinnedProjects = config.PinnedProjects
+	m.refreshEnvironments()
+
+	cmds := []tea.Cmd{m.showToast("Switched to workspace " + config.ActiveWorkspace)}
+	if config.SelectedProjectID != 0 && (m.selectedProject == nil || m.selectedProject.ID != config.SelectedProjectID) {
+		cmds = append(cmds, m.selectProject(Project{
+			ID:                config.SelectedProjectID,
+			Name:              config.SelectedProjectShortName,
+			PathWithNamespace: config.SelectedProjectPath,
+			NameWithNamespace: config.SelectedProjectName,
+		}))
+	}
+	if len(m.pinnedProjects) > 0 {
+		cmds = append(cmds, m.loadDashboard())
+	}
+	return m, tea.Batch(cmds...)
+}